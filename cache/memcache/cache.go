@@ -103,6 +103,23 @@ func (c *Cache) Set(ctx context.Context, key string, value interface{}, expirati
 	}
 }
 
+// SetNX 缓存不存在时设置缓存值，返回是否设置成功
+func (c *Cache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	err := c.opts.client.Add(&memcache.Item{
+		Key:        c.AddPrefix(key),
+		Value:      xconv.Bytes(value),
+		Expiration: int32(expiration / time.Second),
+	})
+	if err != nil {
+		if errors.Is(err, memcache.ErrNotStored) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
 // GetSet 获取设置缓存值
 func (c *Cache) GetSet(ctx context.Context, key string, fn cache.SetValueFunc) cache.Result {
 	key = c.AddPrefix(key)
@@ -2,8 +2,10 @@ package redis
 
 import (
 	"context"
+	xredis "github.com/dobyte/due/redis/v2"
 	"github.com/dobyte/due/v2/cache"
 	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/log"
 	"github.com/dobyte/due/v2/utils/xconv"
 	"github.com/dobyte/due/v2/utils/xrand"
 	"github.com/dobyte/due/v2/utils/xreflect"
@@ -13,8 +15,10 @@ import (
 )
 
 type Cache struct {
-	opts *options
-	sfg  singleflight.Group
+	opts       *options
+	builtin    bool
+	sfg        singleflight.Group
+	compressor *Compressor // 未开启压缩时为nil，读写缓存值时不做任何编解码
 }
 
 func NewCache(opts ...Option) *Cache {
@@ -24,27 +28,53 @@ func NewCache(opts ...Option) *Cache {
 	}
 
 	if o.client == nil {
+		o.client = xredis.GetSharedClient()
+	}
+
+	c := &Cache{}
+
+	if o.client == nil {
+		c.builtin = true
 		o.client = redis.NewUniversalClient(&redis.UniversalOptions{
-			Addrs:      o.addrs,
-			DB:         o.db,
-			Username:   o.username,
-			Password:   o.password,
-			MaxRetries: o.maxRetries,
+			Addrs:            o.addrs,
+			DB:               o.db,
+			Username:         o.username,
+			Password:         o.password,
+			MaxRetries:       o.maxRetries,
+			MasterName:       o.masterName,
+			SentinelPassword: o.sentinelPassword,
 		})
 	}
 
-	c := &Cache{}
+	if o.compress != CompressNone {
+		compressor, err := NewCompressor(o.compress, o.compressSize)
+		if err != nil {
+			log.Fatalf("create cache compressor failed: %v", err)
+		}
+
+		c.compressor = compressor
+	}
+
 	c.opts = o
 
 	return c
 }
 
+// Shutdown 关闭缓存，仅关闭内建客户端；共享客户端与外部客户端由调用方关闭
+func (c *Cache) Shutdown(ctx context.Context) error {
+	if c.builtin {
+		return c.opts.client.Close()
+	}
+
+	return nil
+}
+
 // Has 检测缓存是否存在
 func (c *Cache) Has(ctx context.Context, key string) (bool, error) {
 	key = c.AddPrefix(key)
 
 	val, err, _ := c.sfg.Do(key, func() (interface{}, error) {
-		return c.opts.client.Get(ctx, key).Result()
+		return c.get(ctx, key)
 	})
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
@@ -65,7 +95,7 @@ func (c *Cache) Get(ctx context.Context, key string, def ...interface{}) cache.R
 	key = c.AddPrefix(key)
 
 	val, err, _ := c.sfg.Do(key, func() (interface{}, error) {
-		return c.opts.client.Get(ctx, key).Result()
+		return c.get(ctx, key)
 	})
 	if err != nil && !errors.Is(err, redis.Nil) {
 		return cache.NewResult(nil, err)
@@ -85,10 +115,20 @@ func (c *Cache) Get(ctx context.Context, key string, def ...interface{}) cache.R
 // Set 设置缓存值
 func (c *Cache) Set(ctx context.Context, key string, value interface{}, expiration ...time.Duration) error {
 	if len(expiration) > 0 {
-		return c.opts.client.Set(ctx, c.AddPrefix(key), xconv.String(value), expiration[0]).Err()
+		return c.set(ctx, c.AddPrefix(key), value, expiration[0])
 	} else {
-		return c.opts.client.Set(ctx, c.AddPrefix(key), xconv.String(value), redis.KeepTTL).Err()
+		return c.set(ctx, c.AddPrefix(key), value, redis.KeepTTL)
+	}
+}
+
+// SetNX 缓存不存在时设置缓存值，返回是否设置成功
+func (c *Cache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	val, err := c.encode(value)
+	if err != nil {
+		return false, err
 	}
+
+	return c.opts.client.SetNX(ctx, c.AddPrefix(key), val, expiration).Result()
 }
 
 // GetSet 获取设置缓存值
@@ -96,7 +136,7 @@ func (c *Cache) GetSet(ctx context.Context, key string, fn cache.SetValueFunc) c
 	key = c.AddPrefix(key)
 
 	val, err, _ := c.sfg.Do(key, func() (interface{}, error) {
-		return c.opts.client.Get(ctx, key).Result()
+		return c.get(ctx, key)
 	})
 	if err != nil && !errors.Is(err, redis.Nil) {
 		return cache.NewResult(nil, err)
@@ -125,7 +165,7 @@ func (c *Cache) GetSet(ctx context.Context, key string, fn cache.SetValueFunc) c
 
 		expiration := time.Duration(xrand.Int64(int64(c.opts.minExpiration), int64(c.opts.maxExpiration)))
 
-		if err = c.opts.client.Set(ctx, key, xconv.String(val), expiration).Err(); err != nil {
+		if err = c.set(ctx, key, val, expiration); err != nil {
 			return cache.NewResult(nil, err), nil
 		}
 
@@ -172,6 +212,15 @@ func (c *Cache) DecrFloat(ctx context.Context, key string, value float64) (float
 	return c.opts.client.IncrByFloat(ctx, c.AddPrefix(key), -value).Result()
 }
 
+// CompressStat 获取压缩统计，可注册为调试组件的统计收集器
+func (c *Cache) CompressStat() CompressStat {
+	if c.compressor == nil {
+		return CompressStat{}
+	}
+
+	return c.compressor.Stat()
+}
+
 // AddPrefix 添加Key前缀
 func (c *Cache) AddPrefix(key string) string {
 	if c.opts.prefix == "" {
@@ -185,3 +234,36 @@ func (c *Cache) AddPrefix(key string) string {
 func (c *Cache) Client() interface{} {
 	return c.opts.client
 }
+
+// 获取并解压缓存值
+func (c *Cache) get(ctx context.Context, key string) (string, error) {
+	val, err := c.opts.client.Get(ctx, key).Result()
+	if err != nil {
+		return "", err
+	}
+
+	if c.compressor == nil {
+		return val, nil
+	}
+
+	return c.compressor.Decode(val)
+}
+
+// 压缩并设置缓存值
+func (c *Cache) set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	val, err := c.encode(value)
+	if err != nil {
+		return err
+	}
+
+	return c.opts.client.Set(ctx, key, val, expiration).Err()
+}
+
+// 压缩缓存值，未开启压缩时原样返回
+func (c *Cache) encode(value interface{}) (interface{}, error) {
+	if c.compressor == nil {
+		return value, nil
+	}
+
+	return c.compressor.Encode(xconv.String(value))
+}
@@ -0,0 +1,220 @@
+package redis
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	CompressNone = ""     // 不压缩
+	CompressGzip = "gzip" // gzip压缩
+	CompressZstd = "zstd" // zstd压缩
+)
+
+// 压缩标记
+// 缓存值以标记字节开头时，紧随其后的一个字节为压缩算法编号
+const compressMarker = 0x00
+
+const (
+	codecRaw  byte = iota // 未压缩，用于转义本身以标记字节开头的原始值
+	codecGzip             // gzip
+	codecZstd             // zstd
+)
+
+// CompressStat 压缩统计
+type CompressStat struct {
+	Compressed      int64         // 压缩次数
+	Skipped         int64         // 未达到阈值或压缩无收益而跳过的次数
+	Decompressed    int64         // 解压次数
+	RawBytes        int64         // 压缩前总字节数
+	CompressedBytes int64         // 压缩后总字节数
+	CompressTime    time.Duration // 压缩累计耗时
+	DecompressTime  time.Duration // 解压累计耗时
+}
+
+// Ratio 压缩率，即压缩后字节数与压缩前字节数之比
+func (s CompressStat) Ratio() float64 {
+	if s.RawBytes == 0 {
+		return 0
+	}
+
+	return float64(s.CompressedBytes) / float64(s.RawBytes)
+}
+
+// Compressor 缓存值压缩器
+// 超过阈值的缓存值将被压缩并添加标记字节，读取时根据标记字节判断是否需要解压，因此未压缩的历史数据仍可正常读取
+type Compressor struct {
+	codec     byte
+	threshold int
+
+	encoderOnce sync.Once
+	encoder     *zstd.Encoder
+	encoderErr  error
+	decoderOnce sync.Once
+	decoder     *zstd.Decoder
+	decoderErr  error
+
+	compressed      atomic.Int64
+	skipped         atomic.Int64
+	decompressed    atomic.Int64
+	rawBytes        atomic.Int64
+	compressedBytes atomic.Int64
+	compressTime    atomic.Int64
+	decompressTime  atomic.Int64
+}
+
+// NewCompressor 创建压缩器，codec为空时仅解压不压缩
+func NewCompressor(codec string, threshold int) (*Compressor, error) {
+	c := &Compressor{threshold: threshold}
+
+	switch codec {
+	case CompressNone:
+		c.codec = codecRaw
+	case CompressGzip:
+		c.codec = codecGzip
+	case CompressZstd:
+		c.codec = codecZstd
+	default:
+		return nil, errors.ErrInvalidCompression
+	}
+
+	return c, nil
+}
+
+// Encode 编码缓存值
+func (c *Compressor) Encode(val string) (string, error) {
+	if c.codec == codecRaw || len(val) < c.threshold {
+		c.skipped.Add(1)
+		return c.escape(val), nil
+	}
+
+	start := time.Now()
+
+	buf := bytes.NewBuffer(make([]byte, 0, len(val)/2+2))
+	buf.WriteByte(compressMarker)
+	buf.WriteByte(c.codec)
+
+	switch c.codec {
+	case codecGzip:
+		w := gzip.NewWriter(buf)
+		if _, err := io.WriteString(w, val); err != nil {
+			return "", err
+		}
+		if err := w.Close(); err != nil {
+			return "", err
+		}
+	case codecZstd:
+		encoder, err := c.zstdEncoder()
+		if err != nil {
+			return "", err
+		}
+		buf.Write(encoder.EncodeAll([]byte(val), nil))
+	}
+
+	c.compressTime.Add(int64(time.Since(start)))
+
+	if buf.Len() >= len(val) {
+		c.skipped.Add(1)
+		return c.escape(val), nil
+	}
+
+	c.compressed.Add(1)
+	c.rawBytes.Add(int64(len(val)))
+	c.compressedBytes.Add(int64(buf.Len()))
+
+	return buf.String(), nil
+}
+
+// Decode 解码缓存值
+func (c *Compressor) Decode(val string) (string, error) {
+	if len(val) < 2 || val[0] != compressMarker {
+		return val, nil
+	}
+
+	start := time.Now()
+	defer func() {
+		c.decompressTime.Add(int64(time.Since(start)))
+	}()
+
+	switch val[1] {
+	case codecRaw:
+		return val[2:], nil
+	case codecGzip:
+		r, err := gzip.NewReader(bytes.NewReader([]byte(val[2:])))
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return "", err
+		}
+
+		c.decompressed.Add(1)
+
+		return string(data), nil
+	case codecZstd:
+		decoder, err := c.zstdDecoder()
+		if err != nil {
+			return "", err
+		}
+
+		data, err := decoder.DecodeAll([]byte(val[2:]), nil)
+		if err != nil {
+			return "", err
+		}
+
+		c.decompressed.Add(1)
+
+		return string(data), nil
+	default:
+		return "", errors.ErrInvalidCompression
+	}
+}
+
+// Stat 获取压缩统计
+func (c *Compressor) Stat() CompressStat {
+	return CompressStat{
+		Compressed:      c.compressed.Load(),
+		Skipped:         c.skipped.Load(),
+		Decompressed:    c.decompressed.Load(),
+		RawBytes:        c.rawBytes.Load(),
+		CompressedBytes: c.compressedBytes.Load(),
+		CompressTime:    time.Duration(c.compressTime.Load()),
+		DecompressTime:  time.Duration(c.decompressTime.Load()),
+	}
+}
+
+// 转义以标记字节开头的原始值，避免读取时被误判为压缩值
+func (c *Compressor) escape(val string) string {
+	if len(val) == 0 || val[0] != compressMarker {
+		return val
+	}
+
+	return string([]byte{compressMarker, codecRaw}) + val
+}
+
+// 获取zstd编码器
+func (c *Compressor) zstdEncoder() (*zstd.Encoder, error) {
+	c.encoderOnce.Do(func() {
+		c.encoder, c.encoderErr = zstd.NewWriter(nil)
+	})
+
+	return c.encoder, c.encoderErr
+}
+
+// 获取zstd解码器
+func (c *Compressor) zstdDecoder() (*zstd.Decoder, error) {
+	c.decoderOnce.Do(func() {
+		c.decoder, c.decoderErr = zstd.NewReader(nil)
+	})
+
+	return c.decoder, c.decoderErr
+}
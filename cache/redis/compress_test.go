@@ -0,0 +1,67 @@
+package redis_test
+
+import (
+	"github.com/dobyte/due/cache/redis/v2"
+	"strings"
+	"testing"
+)
+
+func TestCompressor(t *testing.T) {
+	large := strings.Repeat(`{"id":1,"name":"due","level":10}`, 100)
+
+	for _, codec := range []string{redis.CompressGzip, redis.CompressZstd} {
+		c, err := redis.NewCompressor(codec, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, val := range []string{"", "short", "\x00raw", large} {
+			encoded, err := c.Encode(val)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			decoded, err := c.Decode(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if decoded != val {
+				t.Fatalf("%s: decoded value mismatch: %q", codec, decoded)
+			}
+		}
+
+		stat := c.Stat()
+		if stat.Compressed != 1 || stat.Skipped != 3 || stat.Decompressed != 1 {
+			t.Fatalf("%s: unexpected stat: %+v", codec, stat)
+		}
+
+		if ratio := stat.Ratio(); ratio <= 0 || ratio >= 0.5 {
+			t.Fatalf("%s: unexpected ratio: %v", codec, ratio)
+		}
+	}
+
+	if _, err := redis.NewCompressor("lz4", 64); err == nil {
+		t.Fatal("expect invalid compression error")
+	}
+}
+
+func TestCompressor_Switch(t *testing.T) {
+	val := strings.Repeat("due", 100)
+
+	gz, _ := redis.NewCompressor(redis.CompressGzip, 0)
+	none, _ := redis.NewCompressor(redis.CompressNone, 0)
+
+	encoded, err := gz.Encode(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded, err := none.Decode(encoded); err != nil || decoded != val {
+		t.Fatalf("decode compressed value without codec failed: %v", err)
+	}
+
+	if encoded, _ = none.Encode(val); encoded != val {
+		t.Fatal("value should not be compressed without codec")
+	}
+}
@@ -3,8 +3,10 @@ module github.com/dobyte/due/cache/redis/v2
 go 1.22.9
 
 require (
+	github.com/dobyte/due/redis/v2 v2.0.0
 	github.com/dobyte/due/v2 v2.2.4
 	github.com/go-redis/redis/v8 v8.11.5
+	github.com/klauspost/compress v1.16.7
 	golang.org/x/sync v0.11.0
 )
 
@@ -29,3 +31,5 @@ require (
 )
 
 replace github.com/dobyte/due/v2 => ../../
+
+replace github.com/dobyte/due/redis/v2 => ../../redis
@@ -15,19 +15,25 @@ const (
 	defaultNilExpiration = "10s"
 	defaultMinExpiration = "1h"
 	defaultMaxExpiration = "24h"
+	defaultCompress      = ""
+	defaultCompressSize  = 1024
 )
 
 const (
-	defaultAddrsKey         = "etc.cache.redis.addrs"
-	defaultDBKey            = "etc.cache.redis.db"
-	defaultMaxRetriesKey    = "etc.cache.redis.maxRetries"
-	defaultPrefixKey        = "etc.cache.redis.prefix"
-	defaultUsernameKey      = "etc.cache.redis.username"
-	defaultPasswordKey      = "etc.cache.redis.password"
-	defaultNilValueKey      = "etc.cache.redis.nilValue"
-	defaultNilExpirationKey = "etc.cache.redis.nilExpiration"
-	defaultMinExpirationKey = "etc.cache.redis.minExpiration"
-	defaultMaxExpirationKey = "etc.cache.redis.maxExpiration"
+	defaultAddrsKey            = "etc.cache.redis.addrs"
+	defaultDBKey               = "etc.cache.redis.db"
+	defaultMaxRetriesKey       = "etc.cache.redis.maxRetries"
+	defaultMasterNameKey       = "etc.cache.redis.masterName"
+	defaultSentinelPasswordKey = "etc.cache.redis.sentinelPassword"
+	defaultPrefixKey           = "etc.cache.redis.prefix"
+	defaultUsernameKey         = "etc.cache.redis.username"
+	defaultPasswordKey         = "etc.cache.redis.password"
+	defaultNilValueKey         = "etc.cache.redis.nilValue"
+	defaultNilExpirationKey    = "etc.cache.redis.nilExpiration"
+	defaultMinExpirationKey    = "etc.cache.redis.minExpiration"
+	defaultMaxExpirationKey    = "etc.cache.redis.maxExpiration"
+	defaultCompressKey         = "etc.cache.redis.compress"
+	defaultCompressSizeKey     = "etc.cache.redis.compressSize"
 )
 
 type Option func(o *options)
@@ -53,8 +59,16 @@ type options struct {
 	// 内建客户端配置，默认为3次
 	maxRetries int
 
+	// 哨兵模式主节点名称
+	// 内建客户端配置，设置后将以哨兵模式连接，此时addrs为哨兵节点地址，默认为空
+	masterName string
+
+	// 哨兵节点密码
+	// 内建客户端配置，仅哨兵模式下生效，默认为空
+	sentinelPassword string
+
 	// 客户端
-	// 外部客户端配置，存在外部客户端时，优先使用外部客户端，默认为nil
+	// 外部客户端配置，存在外部客户端时，优先使用外部客户端；其次使用共享客户端（xredis.SetSharedClient）；默认为nil
 	client redis.UniversalClient
 
 	// 前缀
@@ -72,20 +86,32 @@ type options struct {
 
 	// 最大过期时间，默认为24h
 	maxExpiration time.Duration
+
+	// 压缩算法
+	// 支持gzip、zstd，默认为空，即不压缩
+	compress string
+
+	// 压缩阈值
+	// 缓存值字节数达到阈值时才进行压缩，默认为1024
+	compressSize int
 }
 
 func defaultOptions() *options {
 	return &options{
-		addrs:         etc.Get(defaultAddrsKey, []string{defaultAddr}).Strings(),
-		db:            etc.Get(defaultDBKey, defaultDB).Int(),
-		maxRetries:    etc.Get(defaultMaxRetriesKey, defaultMaxRetries).Int(),
-		prefix:        etc.Get(defaultPrefixKey, defaultPrefix).String(),
-		username:      etc.Get(defaultUsernameKey).String(),
-		password:      etc.Get(defaultPasswordKey).String(),
-		nilValue:      etc.Get(defaultNilValueKey, defaultNilValue).String(),
-		nilExpiration: etc.Get(defaultNilExpirationKey, defaultNilExpiration).Duration(),
-		minExpiration: etc.Get(defaultMinExpirationKey, defaultMinExpiration).Duration(),
-		maxExpiration: etc.Get(defaultMaxExpirationKey, defaultMaxExpiration).Duration(),
+		addrs:            etc.Get(defaultAddrsKey, []string{defaultAddr}).Strings(),
+		db:               etc.Get(defaultDBKey, defaultDB).Int(),
+		maxRetries:       etc.Get(defaultMaxRetriesKey, defaultMaxRetries).Int(),
+		masterName:       etc.Get(defaultMasterNameKey).String(),
+		sentinelPassword: etc.Get(defaultSentinelPasswordKey).String(),
+		prefix:           etc.Get(defaultPrefixKey, defaultPrefix).String(),
+		username:         etc.Get(defaultUsernameKey).String(),
+		password:         etc.Get(defaultPasswordKey).String(),
+		nilValue:         etc.Get(defaultNilValueKey, defaultNilValue).String(),
+		nilExpiration:    etc.Get(defaultNilExpirationKey, defaultNilExpiration).Duration(),
+		minExpiration:    etc.Get(defaultMinExpirationKey, defaultMinExpiration).Duration(),
+		maxExpiration:    etc.Get(defaultMaxExpirationKey, defaultMaxExpiration).Duration(),
+		compress:         etc.Get(defaultCompressKey, defaultCompress).String(),
+		compressSize:     etc.Get(defaultCompressSizeKey, defaultCompressSize).Int(),
 	}
 }
 
@@ -114,6 +140,16 @@ func WithMaxRetries(maxRetries int) Option {
 	return func(o *options) { o.maxRetries = maxRetries }
 }
 
+// WithMasterName 设置哨兵模式主节点名称，设置后将以哨兵模式连接，此时连接地址为哨兵节点地址
+func WithMasterName(masterName string) Option {
+	return func(o *options) { o.masterName = masterName }
+}
+
+// WithSentinelPassword 设置哨兵节点密码
+func WithSentinelPassword(password string) Option {
+	return func(o *options) { o.sentinelPassword = password }
+}
+
 // WithClient 设置外部客户端
 func WithClient(client redis.UniversalClient) Option {
 	return func(o *options) { o.client = client }
@@ -143,3 +179,13 @@ func WithMinExpiration(minExpiration time.Duration) Option {
 func WithMaxExpiration(maxExpiration time.Duration) Option {
 	return func(o *options) { o.maxExpiration = maxExpiration }
 }
+
+// WithCompress 设置压缩算法，支持gzip、zstd；为空时不压缩，读写缓存值均不做任何编解码，因此关闭压缩前应确保缓存中已无压缩值
+func WithCompress(compress string) Option {
+	return func(o *options) { o.compress = compress }
+}
+
+// WithCompressSize 设置压缩阈值，缓存值字节数达到阈值时才进行压缩
+func WithCompressSize(size int) Option {
+	return func(o *options) { o.compressSize = size }
+}
@@ -1,7 +1,9 @@
 package cluster
 
 import (
+	"github.com/dobyte/due/v2/encoding"
 	"github.com/dobyte/due/v2/session"
+	"time"
 )
 
 const (
@@ -28,10 +30,11 @@ func (k Kind) String() string {
 }
 
 const (
-	Shut State = iota // 关闭（节点已经关闭，无法正常访问该节点）
-	Work              // 工作（节点正常工作，可以分配更多玩家到该节点）
-	Busy              // 繁忙（节点资源紧张，不建议分配更多玩家到该节点上）
-	Hang              // 挂起（节点即将销毁，正处于资源回收中）
+	Shut  State = iota // 关闭（节点已经关闭，无法正常访问该节点）
+	Work               // 工作（节点正常工作，可以分配更多玩家到该节点）
+	Busy               // 繁忙（节点资源紧张，不建议分配更多玩家到该节点上）
+	Hang               // 挂起（节点即将销毁，正处于资源回收中）
+	Drain              // 排空（节点不再接受新的有状态会话，正在将已绑定的用户迁移至其他节点）
 )
 
 // State 集群实例状态
@@ -45,6 +48,8 @@ func (s State) String() string {
 		return "busy"
 	case Hang:
 		return "hang"
+	case Drain:
+		return "drain"
 	default:
 		return "shut"
 	}
@@ -95,6 +100,28 @@ func (h Hook) String() string {
 	}
 }
 
+const (
+	CloseShutdown    CloseReason = iota + 1 // 停服
+	CloseMaintenance                        // 维护
+	CloseOverload                           // 过载
+)
+
+// CloseReason 实例关闭原因，实例解注册时随关闭通知下发给已连接的客户端
+type CloseReason uint16
+
+func (r CloseReason) String() string {
+	switch r {
+	case CloseShutdown:
+		return "shutdown"
+	case CloseMaintenance:
+		return "maintenance"
+	case CloseOverload:
+		return "overload"
+	default:
+		return "unknown"
+	}
+}
+
 type GetIPArgs struct {
 	GID    string       // 网关ID，会话类型为用户时可忽略此参数
 	Kind   session.Kind // 会话类型，session.Conn 或 session.User
@@ -112,6 +139,14 @@ type PushArgs struct {
 	Kind    session.Kind // 会话类型，session.Conn 或 session.User
 	Target  int64        // 会话目标，CID 或 UID
 	Message *Message     // 消息
+	Header  *PushHeader  // 推送头信息，可选
+}
+
+// PushHeader 推送头信息，供网关推送队列进行优先级调度与过期丢弃
+type PushHeader struct {
+	Priority uint8         // 优先级，0为低优先级，1为高优先级
+	TTL      time.Duration // 有效期，超时仍未下发的消息将被丢弃，为0时永不过期，精度为毫秒
+	Type     uint8         // 消息类型，由业务自定义
 }
 
 type MulticastArgs struct {
@@ -145,8 +180,30 @@ type DisconnectArgs struct {
 	Force  bool         // 是否强制断开
 }
 
+type KickArgs struct {
+	GID     string           // 用户所在网关，为空时定位用户所在网关
+	UID     int64            // 用户ID
+	Message *Message         // 踢下线通知消息，可携带踢下线原因码；为空时不发送通知直接断开连接
+	Codecs  []encoding.Codec // 客户端可能协商的编解码器，通知消息另以各编解码器打包，网关按连接协商的编解码器选择；为空时仅以默认编解码器打包
+}
+
 type DeliverArgs struct {
 	NID     string   // 接收节点。存在接收节点时，消息会直接投递给接收节点；不存在接收节点时，系统定位用户所在节点，然后投递。
 	UID     int64    // 用户ID
 	Message *Message // 消息
 }
+
+type HedgingRoute struct {
+	Route      int32         // 路由ID
+	Enable     bool          // 是否启用请求对冲
+	Idempotent bool          // 是否为幂等路由，仅幂等路由才会进行请求对冲，避免重复处理产生副作用
+	Delay      time.Duration // 对冲延迟阈值，首个请求超过该阈值仍未返回时，向其他节点发送对冲请求
+}
+
+// AuditStat 传输层序列号审计统计
+type AuditStat struct {
+	Outstanding int64 `json:"outstanding"` // 等待响应的序列号数
+	Duplicates  int64 `json:"duplicates"`  // 重复响应数
+	Unknowns    int64 `json:"unknowns"`    // 未知序列号响应数
+	Missings    int64 `json:"missings"`    // 审计窗口内未收到响应的序列号数
+}
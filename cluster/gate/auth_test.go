@@ -0,0 +1,112 @@
+package gate_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dobyte/due/v2/cluster/gate"
+	"github.com/dobyte/due/v2/codes"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/network"
+	"github.com/dobyte/due/v2/packet"
+	"testing"
+	"time"
+)
+
+func TestGate_Authenticate(t *testing.T) {
+	c := newTestCluster()
+	n := c.startNode(t, 1)
+
+	c.startGate(t, gate.WithAuthenticator(func(ctx context.Context, conn network.Conn, data []byte) (int64, error) {
+		message, err := packet.UnpackMessage(data)
+		if err != nil {
+			return 0, err
+		}
+
+		if string(message.Buffer) != "token" {
+			return 0, errors.ErrInvalidArgument
+		}
+
+		return 10, nil
+	}))
+
+	t.Run("failed", func(t *testing.T) {
+		conn := c.server.connect(1)
+
+		data, err := packet.PackMessage(&packet.Message{Seq: 3, Route: 1, Buffer: []byte("invalid")})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c.server.receive(conn, data)
+
+		select {
+		case msg := <-conn.pushed:
+			message, err := packet.UnpackMessage(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if message.Seq != 3 || message.Route != 1 {
+				t.Fatalf("unexpected reply: seq = %d route = %d", message.Seq, message.Route)
+			}
+
+			reply := &codes.Reply{}
+			if err = json.Unmarshal(message.Buffer, reply); err != nil {
+				t.Fatal(err)
+			}
+
+			if reply.Code != codes.Unauthorized.Code() {
+				t.Fatalf("unexpected reply code: %d", reply.Code)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("auth failed reply not received")
+		}
+
+		select {
+		case <-conn.closed:
+		default:
+			t.Fatal("connection not closed")
+		}
+
+		c.server.disconnect(conn)
+
+		n.expectNoDeliver(t)
+	})
+
+	t.Run("succeeded", func(t *testing.T) {
+		conn := c.server.connect(2)
+
+		data, err := packet.PackMessage(&packet.Message{Seq: 1, Route: 1, Buffer: []byte("token")})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c.server.receive(conn, data)
+
+		if conn.UID() != 10 {
+			t.Fatalf("connection not bound: %d", conn.UID())
+		}
+
+		n.expectNoDeliver(t)
+
+		select {
+		case <-conn.closed:
+			t.Fatal("connection closed")
+		default:
+		}
+
+		// 认证通过后的数据包正常分发
+		data, err = packet.PackMessage(&packet.Message{Seq: 2, Route: 1, Buffer: []byte("hello")})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c.server.receive(conn, data)
+
+		if d := n.expectDeliver(t); d.cid != 2 || d.uid != 10 {
+			t.Fatalf("unexpected delivery: %+v", d)
+		}
+
+		c.server.disconnect(conn)
+	})
+}
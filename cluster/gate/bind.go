@@ -0,0 +1,128 @@
+package gate
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/internal/link"
+	"github.com/dobyte/due/v2/locate"
+	"github.com/dobyte/due/v2/log"
+	"github.com/dobyte/due/v2/network"
+	"github.com/dobyte/due/v2/session"
+)
+
+const (
+	BindUnbind   BindPolicy = "unbind"   // 解除旧连接的绑定，不断开旧连接
+	BindTakeover BindPolicy = "takeover" // 踢掉旧连接，由新连接接管
+	BindReject   BindPolicy = "reject"   // 拒绝新连接的绑定
+)
+
+// 用户绑定分段锁的分段数
+const bindLockShards = 64
+
+// BindPolicy 重复绑定策略，用户已在其他连接上绑定时新连接的处理方式
+type BindPolicy string
+
+// 检测重复绑定策略是否有效
+func (p BindPolicy) valid() bool {
+	switch p {
+	case BindUnbind, BindTakeover, BindReject:
+		return true
+	default:
+		return false
+	}
+}
+
+// DuplicateBind 重复绑定事件
+type DuplicateBind struct {
+	UID    int64      // 用户ID
+	CID    int64      // 新连接ID
+	OldGID string     // 旧连接所在的网关ID
+	OldCID int64      // 旧连接ID，旧连接位于其他网关时为0
+	Policy BindPolicy // 执行的重复绑定策略
+}
+
+// DuplicateBindHandler 重复绑定处理器，在执行重复绑定策略前调用，可用于审计重复登录
+type DuplicateBindHandler func(ctx context.Context, bind *DuplicateBind)
+
+// 绑定用户与连接，同一用户的重复绑定检测与绑定在同一分段锁内完成，避免并发绑定均通过检测
+// 当前网关上的旧连接直接检测；其他网关上的旧连接需定位器实现locate.Presence，未实现时不检测
+func (g *Gate) bind(ctx context.Context, cid, uid int64) error {
+	mu := &g.binds[uint64(uid)%bindLockShards]
+	mu.Lock()
+	defer mu.Unlock()
+
+	bind, conn, err := g.detectDuplicateBind(ctx, cid, uid)
+	if err != nil {
+		return err
+	}
+
+	if bind != nil {
+		log.Warnf("duplicate bind, uid: %d, cid: %d, old gid: %s, old cid: %d, policy: %s", uid, cid, bind.OldGID, bind.OldCID, bind.Policy)
+
+		if g.opts.duplicateBindHandler != nil {
+			g.opts.duplicateBindHandler(ctx, bind)
+		}
+
+		if bind.Policy == BindReject {
+			return errors.ErrDuplicateBind
+		}
+
+		// 其他网关上的旧连接需在绑定定位器前踢下线，绑定后用户将被定位到当前网关
+		if bind.Policy == BindTakeover && conn == nil {
+			if _, err = g.proxy.kick(ctx, &link.KickArgs{GID: bind.OldGID, UID: uid}); err != nil {
+				log.Warnf("duplicate bind kick failed, uid: %d, old gid: %s, err: %v", uid, bind.OldGID, err)
+			}
+		}
+	}
+
+	// 绑定时解除当前网关上旧连接的绑定，避免旧连接断开时解绑新连接在定位器中的绑定关系
+	if err = g.session.Bind(cid, uid); err != nil {
+		return err
+	}
+
+	if conn != nil {
+		g.unbindSession(ctx, bind.OldCID, uid)
+	}
+
+	if err = g.proxy.bindGate(ctx, cid, uid); err != nil {
+		_, _ = g.session.Unbind(uid)
+		return err
+	}
+
+	g.saveSession(ctx, cid, uid)
+
+	if conn != nil && bind.Policy == BindTakeover {
+		if err = kickConn(conn, nil); err != nil && !errors.Is(err, errors.ErrNotFoundSession) {
+			log.Warnf("duplicate bind kick failed, uid: %d, old cid: %d, err: %v", uid, bind.OldCID, err)
+		}
+	}
+
+	return nil
+}
+
+// 检测重复绑定，旧连接位于当前网关时一并返回旧连接
+func (g *Gate) detectDuplicateBind(ctx context.Context, cid, uid int64) (*DuplicateBind, network.Conn, error) {
+	if conn, err := g.session.Conn(session.User, uid); err == nil {
+		if conn.ID() == cid {
+			return nil, nil, nil
+		}
+
+		return &DuplicateBind{UID: uid, CID: cid, OldGID: g.opts.id, OldCID: conn.ID(), Policy: g.opts.bindPolicy}, conn, nil
+	}
+
+	presence, ok := g.opts.locator.(locate.Presence)
+	if !ok {
+		return nil, nil, nil
+	}
+
+	gid, _, online, err := presence.Locate(ctx, uid, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !online || gid == "" || gid == g.opts.id {
+		return nil, nil, nil
+	}
+
+	return &DuplicateBind{UID: uid, CID: cid, OldGID: gid, Policy: g.opts.bindPolicy}, nil, nil
+}
@@ -0,0 +1,191 @@
+package gate_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster/gate"
+	"github.com/dobyte/due/v2/errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGate_BindPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unbind", func(t *testing.T) {
+		c := newTestCluster()
+		binds := make(chan *gate.DuplicateBind, 1)
+		c.startGate(t, gate.WithDuplicateBindHandler(func(ctx context.Context, bind *gate.DuplicateBind) {
+			binds <- bind
+		}))
+
+		client := c.gateClient(t)
+		old, conn := c.server.connect(1), c.server.connect(2)
+
+		if _, err := client.Bind(ctx, 1, 10); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := client.Bind(ctx, 2, 10); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case bind := <-binds:
+			if bind.UID != 10 || bind.CID != 2 || bind.OldGID != "gate-1" || bind.OldCID != 1 || bind.Policy != gate.BindUnbind {
+				t.Fatalf("unexpected duplicate bind: %+v", bind)
+			}
+		default:
+			t.Fatal("duplicate bind handler not called")
+		}
+
+		if old.UID() != 0 || conn.UID() != 10 {
+			t.Fatalf("unexpected binding, old: %d new: %d", old.UID(), conn.UID())
+		}
+
+		select {
+		case <-old.closed:
+			t.Fatal("old connection closed")
+		default:
+		}
+
+		c.server.disconnect(old)
+
+		if gid, _ := c.locator.LocateGate(ctx, 10); gid != "gate-1" {
+			t.Fatalf("binding released by old connection: %q", gid)
+		}
+
+		c.server.disconnect(conn)
+	})
+
+	t.Run("takeover", func(t *testing.T) {
+		c := newTestCluster()
+		c.startGate(t, gate.WithBindPolicy(gate.BindTakeover))
+
+		client := c.gateClient(t)
+		old, conn := c.server.connect(1), c.server.connect(2)
+
+		if _, err := client.Bind(ctx, 1, 10); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := client.Bind(ctx, 2, 10); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case <-old.closed:
+		default:
+			t.Fatal("old connection not kicked")
+		}
+
+		if conn.UID() != 10 {
+			t.Fatalf("new connection not bound: %d", conn.UID())
+		}
+
+		c.server.disconnect(old)
+		c.server.disconnect(conn)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		c := newTestCluster()
+		c.startGate(t, gate.WithBindPolicy(gate.BindReject))
+
+		client := c.gateClient(t)
+		old, conn := c.server.connect(1), c.server.connect(2)
+
+		if _, err := client.Bind(ctx, 1, 10); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := client.Bind(ctx, 2, 10); !errors.Is(err, errors.ErrDuplicateBind) {
+			t.Fatalf("expected duplicate bind error, got: %v", err)
+		}
+
+		if old.UID() != 10 || conn.UID() != 0 {
+			t.Fatalf("unexpected binding, old: %d new: %d", old.UID(), conn.UID())
+		}
+
+		c.server.disconnect(old)
+		c.server.disconnect(conn)
+	})
+}
+
+func TestGate_BindRejectConcurrent(t *testing.T) {
+	const total = 20
+
+	var (
+		ctx       = context.Background()
+		c         = newTestCluster()
+		wg        sync.WaitGroup
+		succeeded atomic.Int32
+	)
+
+	c.startGate(t, gate.WithBindPolicy(gate.BindReject))
+
+	client := c.gateClient(t)
+
+	conns := make([]*mockConn, total)
+	for i := range conns {
+		conns[i] = c.server.connect(int64(i + 1))
+	}
+
+	for i := range conns {
+		wg.Add(1)
+		go func(cid int64) {
+			defer wg.Done()
+
+			if _, err := client.Bind(ctx, cid, 10); err == nil {
+				succeeded.Add(1)
+			}
+		}(int64(i + 1))
+	}
+
+	wg.Wait()
+
+	if n := succeeded.Load(); n != 1 {
+		t.Fatalf("succeeded binds = %d, want 1", n)
+	}
+
+	for _, conn := range conns {
+		c.server.disconnect(conn)
+	}
+}
+
+func TestGate_BindTakeoverRemote(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		c       = newTestCluster()
+		locator = &presenceLocator{Locator: c.locator}
+		remote  = &mockServer{}
+	)
+
+	c.startGate(t, gate.WithBindPolicy(gate.BindTakeover), gate.WithLocator(locator))
+	c.startGate(t, gate.WithID("gate-2"), gate.WithServer(remote), gate.WithLocator(locator))
+
+	old := remote.connect(1)
+
+	if _, err := c.gateClientOf(t, "gate-2").Bind(ctx, 1, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	conn := c.server.connect(2)
+
+	if _, err := c.gateClient(t).Bind(ctx, 2, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-old.closed:
+	case <-time.After(3 * time.Second):
+		t.Fatal("remote connection not kicked")
+	}
+
+	if gid, _ := c.locator.LocateGate(ctx, 10); gid != "gate-1" {
+		t.Fatalf("unexpected gate binding: %q", gid)
+	}
+
+	remote.disconnect(old)
+	c.server.disconnect(conn)
+}
@@ -0,0 +1,34 @@
+package gate_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster/gate"
+	"github.com/dobyte/due/v2/session"
+	"testing"
+)
+
+func TestGate_BroadcastCodecs(t *testing.T) {
+	for _, size := range []int{0, 4} {
+		c := newTestCluster()
+		c.startGate(t, gate.WithPushQueueSize(size))
+
+		var (
+			plain  = c.server.connect(1)
+			coded  = c.server.connectCodec(2, "proto")
+			client = c.gateClient(t)
+		)
+
+		t.Cleanup(func() {
+			c.server.disconnect(plain)
+			c.server.disconnect(coded)
+		})
+
+		// 启用推送队列时同样按连接协商的编解码器选择消息
+		if err := client.BroadcastCodecs(context.Background(), session.Conn, map[string][]byte{"": []byte("json"), "proto": []byte("proto")}); err != nil {
+			t.Fatal(err)
+		}
+
+		expectPushed(t, plain, "json")
+		expectPushed(t, coded, "proto")
+	}
+}
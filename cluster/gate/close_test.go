@@ -0,0 +1,95 @@
+package gate_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/cluster/gate"
+	"github.com/dobyte/due/v2/codes"
+	"github.com/dobyte/due/v2/encoding/json"
+	"github.com/dobyte/due/v2/packet"
+	"testing"
+	"time"
+)
+
+func TestGate_CloseNotice(t *testing.T) {
+	c := newTestCluster()
+	g := c.startGate(t, gate.WithCloseRoute(99))
+	g.SetCloseReason(cluster.CloseMaintenance)
+
+	conn := c.server.connect(1)
+
+	closed := make(chan struct{})
+	go func() {
+		g.Close()
+		close(closed)
+	}()
+
+	select {
+	case buf := <-conn.pushed:
+		msg, err := packet.UnpackMessage(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		reply := &codes.Reply{}
+		if err = json.Unmarshal(msg.Buffer, reply); err != nil {
+			t.Fatal(err)
+		}
+
+		if msg.Route != 99 || reply.Code != int(cluster.CloseMaintenance) {
+			t.Fatalf("unexpected close message, route: %d reply: %+v", msg.Route, reply)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("close message not pushed")
+	}
+
+	c.server.disconnect(conn)
+
+	select {
+	case <-closed:
+	case <-time.After(3 * time.Second):
+		t.Fatal("gate not closed")
+	}
+}
+
+func TestGate_CloseReasonHandler(t *testing.T) {
+	c := newTestCluster()
+	n := c.startNode(t, 1)
+
+	reasons := make(chan cluster.CloseReason, 1)
+	c.startGate(t, gate.WithCloseReasonHandler(func(addr string, reason cluster.CloseReason) {
+		select {
+		case reasons <- reason:
+		default:
+		}
+	}))
+
+	conn := c.server.connect(1)
+	defer c.server.disconnect(conn)
+
+	if _, err := c.gateClient(t).Bind(context.Background(), 1, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := packet.PackMessage(&packet.Message{Seq: 1, Route: 1, Buffer: []byte("hello")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.server.receive(conn, data)
+
+	n.expectDeliver(t)
+
+	if err = n.server.StopWithReason(cluster.CloseOverload); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case reason := <-reasons:
+		if reason != cluster.CloseOverload {
+			t.Fatalf("unexpected close reason: %s", reason)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("close reason handler not called")
+	}
+}
@@ -11,16 +11,24 @@ import (
 	"context"
 	"fmt"
 	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/codes"
 	"github.com/dobyte/due/v2/component"
 	"github.com/dobyte/due/v2/core/info"
 	"github.com/dobyte/due/v2/core/net"
+	"github.com/dobyte/due/v2/encoding"
+	"github.com/dobyte/due/v2/encoding/json"
+	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/internal/transporter/gate"
+	"github.com/dobyte/due/v2/locate"
 	"github.com/dobyte/due/v2/log"
 	"github.com/dobyte/due/v2/network"
+	"github.com/dobyte/due/v2/packet"
 	"github.com/dobyte/due/v2/registry"
 	"github.com/dobyte/due/v2/session"
+	"strconv"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 type Gate struct {
@@ -29,11 +37,14 @@ type Gate struct {
 	ctx      context.Context
 	cancel   context.CancelFunc
 	state    atomic.Int32
+	reason   atomic.Uint32 // 关闭原因
 	proxy    *proxy
 	instance *registry.ServiceInstance
 	session  *session.Session
 	linker   *gate.Server
 	wg       *sync.WaitGroup
+	queues   sync.Map
+	binds    [bindLockShards]sync.Mutex // 用户绑定分段锁
 }
 
 func NewGate(opts ...Option) *Gate {
@@ -75,6 +86,14 @@ func (g *Gate) Init() {
 	if g.opts.registry == nil {
 		log.Fatal("registry component is not injected")
 	}
+
+	if g.opts.sessionStore != nil && g.opts.randomID {
+		log.Fatal("session store requires a stable instance id")
+	}
+
+	if !g.opts.bindPolicy.valid() {
+		log.Fatalf("invalid bind policy: %s", g.opts.bindPolicy)
+	}
 }
 
 // Start 启动组件
@@ -83,6 +102,8 @@ func (g *Gate) Start() {
 		return
 	}
 
+	g.recoverSessions()
+
 	g.startNetworkServer()
 
 	g.startLinkerServer()
@@ -91,6 +112,8 @@ func (g *Gate) Start() {
 
 	g.proxy.watch()
 
+	g.refreshPresence()
+
 	g.printInfo()
 }
 
@@ -104,6 +127,8 @@ func (g *Gate) Close() {
 
 	g.refreshServiceInstance()
 
+	g.noticeClose(g.closeReason())
+
 	g.wg.Wait()
 }
 
@@ -113,15 +138,139 @@ func (g *Gate) Destroy() {
 		return
 	}
 
-	g.deregisterServiceInstance()
+	reason := g.closeReason()
+
+	g.deregisterServiceInstance(reason)
 
 	g.stopNetworkServer()
 
-	g.stopLinkerServer()
+	g.stopLinkerServer(reason)
 
 	g.cancel()
 }
 
+// Ready 检测网关是否就绪，可作为健康检测组件的就绪检测器
+func (g *Gate) Ready(ctx context.Context) error {
+	switch g.getState() {
+	case cluster.Work, cluster.Busy:
+		if g.instance == nil {
+			return errors.ErrNotReady
+		}
+
+		return nil
+	default:
+		return errors.ErrNotReady
+	}
+}
+
+// SetCloseReason 设置关闭原因，需在关闭网关前设置，未设置时为cluster.CloseShutdown
+// 网关关闭时随关闭通知推送给已连接的客户端，销毁时随传输层关闭通知下发给已连接的节点
+func (g *Gate) SetCloseReason(reason cluster.CloseReason) {
+	g.reason.Store(uint32(reason))
+}
+
+// 获取关闭原因
+func (g *Gate) closeReason() cluster.CloseReason {
+	if reason := cluster.CloseReason(g.reason.Load()); reason != 0 {
+		return reason
+	}
+
+	return cluster.CloseShutdown
+}
+
+// 向所有连接推送关闭通知
+func (g *Gate) noticeClose(reason cluster.CloseReason) {
+	var message *packet.Message
+
+	if g.opts.closeNoticeHandler != nil {
+		message = g.opts.closeNoticeHandler(reason)
+	} else if g.opts.closeRoute != 0 {
+		message = defaultCloseMessage(g.opts.closeRoute, reason)
+	}
+
+	if message == nil {
+		return
+	}
+
+	msg, err := packet.PackMessage(message)
+	if err != nil {
+		log.Warnf("pack close message failed, reason = %s err = %v", reason, err)
+		return
+	}
+
+	if _, err = g.session.Broadcast(session.Conn, msg); err != nil {
+		log.Warnf("push close message failed, reason = %s err = %v", reason, err)
+	}
+}
+
+// 默认的关闭通知消息，消息体为json编码的codes.Reply，code为关闭原因
+func defaultCloseMessage(route int32, reason cluster.CloseReason) *packet.Message {
+	buf, err := encoding.Invoke(json.Name).Marshal(&codes.Reply{Code: int(reason), Message: reason.String()})
+	if err != nil {
+		return nil
+	}
+
+	return &packet.Message{Route: route, Buffer: buf}
+}
+
+// ConnCount 获取当前连接数
+func (g *Gate) ConnCount() int64 {
+	count, _ := g.session.Stat(session.Conn)
+	return count
+}
+
+// InflightStats 获取各节点地址进行中的RPC调用数，可注册为调试组件的统计收集器
+func (g *Gate) InflightStats() map[string]int64 {
+	return g.proxy.nodeLinker.InflightStats()
+}
+
+// AuditStats 获取各节点地址的传输层序列号审计统计，可注册为调试组件的统计收集器
+func (g *Gate) AuditStats() map[string]cluster.AuditStat {
+	return g.proxy.nodeLinker.AuditStats()
+}
+
+// 定时刷新用户在线状态
+func (g *Gate) refreshPresence() {
+	presence, ok := g.opts.locator.(locate.Presence)
+	if !ok || g.opts.presence <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(g.opts.presence)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-g.ctx.Done():
+				return
+			case <-ticker.C:
+				uids := g.session.UIDs()
+				if len(uids) == 0 {
+					continue
+				}
+
+				ctx, cancel := context.WithTimeout(g.ctx, g.opts.timeout)
+				if err := presence.Refresh(ctx, uids...); err != nil {
+					log.Errorf("user presence refresh failed: %v", err)
+				}
+				cancel()
+			}
+		}
+	}()
+}
+
+// 离开用户所在的所有房间
+func (g *Gate) leaveRooms(ctx context.Context, uid int64) {
+	if g.opts.roomManager == nil {
+		return
+	}
+
+	if err := g.opts.roomManager.LeaveAll(ctx, uid); err != nil {
+		log.Errorf("user leave rooms failed, uid: %d, err: %v", uid, err)
+	}
+}
+
 // 启动网络服务器
 func (g *Gate) startNetworkServer() {
 	g.opts.server.OnConnect(g.handleConnect)
@@ -144,7 +293,13 @@ func (g *Gate) stopNetworkServer() {
 func (g *Gate) handleConnect(conn network.Conn) {
 	g.wg.Add(1)
 
-	g.session.AddConn(conn)
+	if g.opts.pushQueueSize > 0 {
+		q := newPushQueue(g, conn)
+		g.queues.Store(conn.ID(), q)
+		g.session.AddConn(q)
+	} else {
+		g.session.AddConn(conn)
+	}
 
 	cid, uid := conn.ID(), conn.UID()
 
@@ -157,11 +312,18 @@ func (g *Gate) handleConnect(conn network.Conn) {
 func (g *Gate) handleDisconnect(conn network.Conn) {
 	g.session.RemConn(conn)
 
+	if q, ok := g.queues.LoadAndDelete(conn.ID()); ok {
+		q.(*pushQueue).close()
+	}
+
 	if cid, uid := conn.ID(), conn.UID(); uid != 0 {
 		ctx, cancel := context.WithTimeout(g.ctx, g.opts.timeout)
 		_ = g.proxy.unbindGate(ctx, cid, uid)
+		g.deleteSession(ctx, cid, uid)
+		g.leaveRooms(ctx, uid)
 		g.proxy.trigger(ctx, cluster.Disconnect, cid, uid)
 		cancel()
+		g.releaseBalancer(uid)
 	} else {
 		ctx, cancel := context.WithTimeout(g.ctx, g.opts.timeout)
 		g.proxy.trigger(ctx, cluster.Disconnect, cid, uid)
@@ -171,17 +333,112 @@ func (g *Gate) handleDisconnect(conn network.Conn) {
 	g.wg.Done()
 }
 
+// 释放负载均衡器中用户的粘性状态
+func (g *Gate) releaseBalancer(uid int64) {
+	if releaser, ok := g.opts.balancer.(registry.Releaser); ok {
+		releaser.Release(strconv.FormatInt(uid, 10))
+	}
+}
+
 // 处理接收到的消息
 func (g *Gate) handleReceive(conn network.Conn, data []byte) {
 	cid, uid := conn.ID(), conn.UID()
 	ctx, cancel := context.WithTimeout(g.ctx, g.opts.timeout)
-	g.proxy.deliver(ctx, cid, uid, data)
-	cancel()
+	defer cancel()
+
+	if g.opts.authenticator != nil && uid == 0 {
+		var err error
+
+		if _, err = g.authenticate(ctx, conn, data); err != nil {
+			log.Warnf("connection authenticate failed, cid = %d code = %d err = %v", cid, codes.Unauthorized.Code(), err)
+			g.replyAuthFailed(conn, data)
+			_ = conn.Close(true)
+		}
+
+		// 认证数据包仅用于认证，不分发到节点
+		return
+	}
+
+	var codec string
+	if c, ok := conn.(network.CodecConn); ok {
+		codec = c.Codec()
+	}
+
+	g.proxy.deliver(ctx, cid, uid, codec, data)
+}
+
+// 认证连接，认证成功后绑定用户
+func (g *Gate) authenticate(ctx context.Context, conn network.Conn, data []byte) (int64, error) {
+	uid, err := g.opts.authenticator(ctx, conn, data)
+	if err != nil {
+		return 0, err
+	}
+
+	if uid <= 0 {
+		return 0, errors.ErrInvalidArgument
+	}
+
+	if err = (&provider{gate: g}).Bind(ctx, conn.ID(), uid); err != nil {
+		return 0, err
+	}
+
+	return uid, nil
+}
+
+// 向客户端下发认证失败消息
+func (g *Gate) replyAuthFailed(conn network.Conn, data []byte) {
+	var message *packet.Message
+
+	if g.opts.authFailedHandler != nil {
+		message = g.opts.authFailedHandler(conn, data, codes.Unauthorized)
+	} else {
+		message = defaultAuthFailedMessage(conn, data)
+	}
+
+	if message == nil {
+		return
+	}
+
+	msg, err := packet.PackMessage(message)
+	if err != nil {
+		log.Warnf("pack auth failed message failed, cid = %d err = %v", conn.ID(), err)
+		return
+	}
+
+	if err = conn.Send(msg); err != nil {
+		log.Warnf("send auth failed message failed, cid = %d err = %v", conn.ID(), err)
+	}
+}
+
+// 默认的认证失败消息，沿用认证数据包的路由及序列号，以连接协商的编解码器编码，未协商时使用json
+func defaultAuthFailedMessage(conn network.Conn, data []byte) *packet.Message {
+	message, err := packet.UnpackMessage(data)
+	if err != nil {
+		return nil
+	}
+
+	codec := encoding.Invoke(json.Name)
+	if c, ok := conn.(network.CodecConn); ok && c.Codec() != "" {
+		if v, ok := encoding.Lookup(c.Codec()); ok {
+			codec = v
+		}
+	}
+
+	buf, err := codec.Marshal(codes.Unauthorized.Reply())
+	if err != nil {
+		return nil
+	}
+
+	return &packet.Message{Seq: message.Seq, Route: message.Route, Buffer: buf}
 }
 
 // 启动传输服务器
 func (g *Gate) startLinkerServer() {
-	transporter, err := gate.NewServer(g.opts.addr, &provider{gate: g})
+	transporter, err := gate.NewServer(&gate.ServerOptions{
+		Addr:         g.opts.addr,
+		DrainOnClose: g.opts.drainOnClose,
+		RecordWriter: g.opts.recordWriter,
+	}, &provider{gate: g})
 	if err != nil {
 		log.Fatalf("link server create failed: %v", err)
 	}
@@ -196,8 +453,8 @@ func (g *Gate) startLinkerServer() {
 }
 
 // 停止传输服务器
-func (g *Gate) stopLinkerServer() {
-	if err := g.linker.Stop(); err != nil {
+func (g *Gate) stopLinkerServer(reason cluster.CloseReason) {
+	if err := g.linker.StopWithReason(reason); err != nil {
 		log.Errorf("link server stop failed: %v", err)
 	}
 }
@@ -205,13 +462,15 @@ func (g *Gate) stopLinkerServer() {
 // 注册服务实例
 func (g *Gate) registerServiceInstance() {
 	g.instance = &registry.ServiceInstance{
-		ID:       g.opts.id,
-		Name:     cluster.Gate.String(),
-		Kind:     cluster.Gate.String(),
-		Alias:    g.opts.name,
-		State:    g.getState().String(),
-		Weight:   g.opts.weight,
-		Endpoint: g.linker.Endpoint().String(),
+		ID:        g.opts.id,
+		Name:      cluster.Gate.String(),
+		Kind:      cluster.Gate.String(),
+		Alias:     g.opts.name,
+		State:     g.getState().String(),
+		Weight:    g.opts.weight,
+		Endpoint:  g.linker.Endpoint().String(),
+		Endpoints: g.opts.endpoints,
+		Tags:      g.opts.tags,
 	}
 
 	ctx, cancel := context.WithTimeout(g.ctx, defaultTimeout)
@@ -239,13 +498,16 @@ func (g *Gate) refreshServiceInstance() {
 }
 
 // 解注册服务实例
-func (g *Gate) deregisterServiceInstance() {
+func (g *Gate) deregisterServiceInstance(reason cluster.CloseReason) {
 	ctx, cancel := context.WithTimeout(g.ctx, defaultTimeout)
 	defer cancel()
 
 	if err := g.opts.registry.Deregister(ctx, g.instance); err != nil {
-		log.Errorf("deregister cluster instance failed: %v", err)
+		log.Errorf("deregister cluster instance failed, reason: %s err: %v", reason, err)
+		return
 	}
+
+	log.Infof("cluster instance deregistered, reason: %s", reason)
 }
 
 // 获取状态
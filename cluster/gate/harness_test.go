@@ -0,0 +1,327 @@
+package gate_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/cluster/gate"
+	"github.com/dobyte/due/v2/core/endpoint"
+	"github.com/dobyte/due/v2/errors"
+	tgate "github.com/dobyte/due/v2/internal/transporter/gate"
+	tnode "github.com/dobyte/due/v2/internal/transporter/node"
+	lmock "github.com/dobyte/due/v2/locate/mock"
+	"github.com/dobyte/due/v2/network"
+	"github.com/dobyte/due/v2/registry"
+	"github.com/dobyte/due/v2/registry/mock"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 支持在线状态的内存定位器
+type presenceLocator struct {
+	*lmock.Locator
+	refreshes chan []int64 // 刷新在线状态的用户，为nil时不记录
+}
+
+func (l *presenceLocator) Refresh(ctx context.Context, uids ...int64) error {
+	if l.refreshes != nil {
+		select {
+		case l.refreshes <- uids:
+		default:
+		}
+	}
+
+	return nil
+}
+
+func (l *presenceLocator) Locate(ctx context.Context, uid int64, name string) (string, string, bool, error) {
+	gid, err := l.LocateGate(ctx, uid)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	nid, err := l.LocateNode(ctx, uid, name)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	return gid, nid, gid != "", nil
+}
+
+func (l *presenceLocator) CountOnline(ctx context.Context) (int64, error) {
+	return 0, errors.ErrNotSupported
+}
+
+// 模拟网络服务器，由测试主动触发连接事件
+type mockServer struct {
+	connectHandler    network.ConnectHandler
+	disconnectHandler network.DisconnectHandler
+	receiveHandler    network.ReceiveHandler
+}
+
+func (s *mockServer) Addr() string                                   { return "127.0.0.1:0" }
+func (s *mockServer) Start() error                                   { return nil }
+func (s *mockServer) Stop() error                                    { return nil }
+func (s *mockServer) Protocol() string                               { return "mock" }
+func (s *mockServer) OnStart(handler network.StartHandler)           {}
+func (s *mockServer) OnStop(handler network.CloseHandler)            {}
+func (s *mockServer) OnConnect(handler network.ConnectHandler)       { s.connectHandler = handler }
+func (s *mockServer) OnReceive(handler network.ReceiveHandler)       { s.receiveHandler = handler }
+func (s *mockServer) OnDisconnect(handler network.DisconnectHandler) { s.disconnectHandler = handler }
+
+// 建立连接
+func (s *mockServer) connect(cid int64) *mockConn {
+	conn := &mockConn{id: cid, pushed: make(chan []byte, 64), closed: make(chan struct{})}
+	s.connectHandler(conn)
+
+	return conn
+}
+
+// 建立连接，下发消息在记录后阻塞至关闭hold
+func (s *mockServer) connectHeld(cid int64, hold chan struct{}) *mockConn {
+	conn := &mockConn{id: cid, pushed: make(chan []byte, 64), closed: make(chan struct{}), hold: hold}
+	s.connectHandler(conn)
+
+	return conn
+}
+
+// 建立已协商编解码器的连接
+func (s *mockServer) connectCodec(cid int64, codec string) *mockConn {
+	conn := &mockConn{id: cid, pushed: make(chan []byte, 64), closed: make(chan struct{}), codec: codec}
+	s.connectHandler(conn)
+
+	return conn
+}
+
+// 断开连接
+func (s *mockServer) disconnect(conn *mockConn) {
+	conn.state.Store(int32(network.ConnClosed))
+	s.disconnectHandler(conn)
+}
+
+// 接收消息
+func (s *mockServer) receive(conn *mockConn, data []byte) {
+	s.receiveHandler(conn, data)
+}
+
+// 模拟客户端连接，记录下发的消息
+type mockConn struct {
+	id     int64
+	uid    atomic.Int64
+	state  atomic.Int32
+	pushed chan []byte
+	once   sync.Once
+	closed chan struct{}
+	hold   chan struct{} // 不为nil时下发消息阻塞至关闭该通道
+	codec  string        // 协商的编解码器
+}
+
+func (c *mockConn) ID() int64      { return c.id }
+func (c *mockConn) UID() int64     { return c.uid.Load() }
+func (c *mockConn) Bind(uid int64) { c.uid.Store(uid) }
+func (c *mockConn) Unbind()        { c.uid.Store(0) }
+func (c *mockConn) Codec() string  { return c.codec }
+
+func (c *mockConn) Send(msg []byte) error {
+	return c.Push(msg)
+}
+
+func (c *mockConn) Push(msg []byte) error {
+	c.pushed <- append([]byte(nil), msg...)
+
+	if c.hold != nil {
+		<-c.hold
+	}
+
+	return nil
+}
+
+func (c *mockConn) State() network.ConnState {
+	if state := network.ConnState(c.state.Load()); state != 0 {
+		return state
+	}
+
+	return network.ConnOpened
+}
+
+func (c *mockConn) Close(force ...bool) error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *mockConn) LocalIP() (string, error) { return "127.0.0.1", nil }
+func (c *mockConn) LocalAddr() (net.Addr, error) {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}, nil
+}
+func (c *mockConn) RemoteIP() (string, error) { return "127.0.0.1", nil }
+func (c *mockConn) RemoteAddr() (net.Addr, error) {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}, nil
+}
+
+// 投递到节点的消息
+type delivered struct {
+	cid     int64
+	uid     int64
+	message []byte
+}
+
+// 模拟节点，记录网关投递的消息
+type mockNode struct {
+	id         string
+	server     *tnode.Server
+	deliveries chan *delivered
+}
+
+func (n *mockNode) Trigger(ctx context.Context, gid string, cid, uid int64, event cluster.Event) error {
+	return nil
+}
+
+func (n *mockNode) Deliver(ctx context.Context, gid, nid string, cid, uid int64, codec string, message []byte) error {
+	n.deliveries <- &delivered{cid: cid, uid: uid, message: append([]byte(nil), message...)}
+	return nil
+}
+
+func (n *mockNode) GetState() (cluster.State, error) {
+	return cluster.Work, nil
+}
+
+func (n *mockNode) SetState(state cluster.State) error {
+	return nil
+}
+
+func (n *mockNode) Drain(timeout time.Duration, progress func(remaining int)) (int, error) {
+	return 0, nil
+}
+
+// 等待投递消息
+func (n *mockNode) expectDeliver(t *testing.T) *delivered {
+	t.Helper()
+
+	select {
+	case d := <-n.deliveries:
+		return d
+	case <-time.After(3 * time.Second):
+		t.Fatal("message not delivered")
+		return nil
+	}
+}
+
+// 确认未收到投递消息
+func (n *mockNode) expectNoDeliver(t *testing.T) {
+	t.Helper()
+
+	select {
+	case d := <-n.deliveries:
+		t.Fatalf("unexpected delivery: %+v", d)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// 测试集群，包含内存注册中心、内存定位器及模拟网络服务器
+type testCluster struct {
+	registry *mock.Registry
+	locator  *lmock.Locator
+	server   *mockServer
+}
+
+func newTestCluster() *testCluster {
+	return &testCluster{registry: mock.NewRegistry(), locator: lmock.NewLocator(), server: &mockServer{}}
+}
+
+// 启动网关
+func (c *testCluster) startGate(t *testing.T, opts ...gate.Option) *gate.Gate {
+	opts = append([]gate.Option{
+		gate.WithID("gate-1"),
+		gate.WithAddr("127.0.0.1:0"),
+		gate.WithServer(c.server),
+		gate.WithRegistry(c.registry),
+		gate.WithLocator(c.locator),
+	}, opts...)
+
+	g := gate.NewGate(opts...)
+	g.Init()
+	g.Start()
+
+	t.Cleanup(func() {
+		g.Close()
+		g.Destroy()
+	})
+
+	return g
+}
+
+// 启动模拟节点，并以给定的路由注册到注册中心
+func (c *testCluster) startNode(t *testing.T, routes ...int32) *mockNode {
+	n := &mockNode{id: "node-1", deliveries: make(chan *delivered, 64)}
+
+	server, err := tnode.NewServer(&tnode.ServerOptions{Addr: "127.0.0.1:0"}, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n.server = server
+
+	go server.Start()
+
+	t.Cleanup(func() { _ = server.Stop() })
+
+	ins := &registry.ServiceInstance{
+		ID:       n.id,
+		Name:     cluster.Node.String(),
+		Kind:     cluster.Node.String(),
+		State:    cluster.Work.String(),
+		Endpoint: server.Endpoint().String(),
+	}
+
+	for _, route := range routes {
+		ins.Routes = append(ins.Routes, registry.Route{ID: route})
+	}
+
+	if err = c.registry.Register(context.Background(), ins); err != nil {
+		t.Fatal(err)
+	}
+
+	return n
+}
+
+// 模拟节点连接网关的传输层客户端
+func (c *testCluster) gateClient(t *testing.T) *tgate.Client {
+	t.Helper()
+
+	return c.gateClientOf(t, "gate-1")
+}
+
+// 模拟节点连接指定网关的传输层客户端
+func (c *testCluster) gateClientOf(t *testing.T, gid string) *tgate.Client {
+	t.Helper()
+
+	services, err := c.registry.Services(context.Background(), cluster.Gate.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ins *registry.ServiceInstance
+	for _, service := range services {
+		if service.ID == gid {
+			ins = service
+		}
+	}
+
+	if ins == nil {
+		t.Fatalf("gate not registered: %s", gid)
+	}
+
+	ep, err := endpoint.ParseEndpoint(ins.Endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := tgate.NewBuilder(&tgate.Options{InsID: "node-1", InsKind: cluster.Node}).Build(ep.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
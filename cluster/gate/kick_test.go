@@ -0,0 +1,73 @@
+package gate_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster/gate"
+	"testing"
+	"time"
+)
+
+func TestGate_Kick(t *testing.T) {
+	ctx := context.Background()
+
+	for _, size := range []int{0, 4} {
+		c := newTestCluster()
+		c.startGate(t, gate.WithPushQueueSize(size))
+
+		var (
+			conn   = c.server.connectCodec(1, "proto")
+			client = c.gateClient(t)
+		)
+
+		t.Cleanup(func() { c.server.disconnect(conn) })
+
+		if _, err := client.Bind(ctx, 1, 10); err != nil {
+			t.Fatal(err)
+		}
+
+		// 按连接协商的编解码器选择踢下线通知消息
+		if _, err := client.Kick(ctx, 10, map[string][]byte{"": []byte("json"), "proto": []byte("proto")}); err != nil {
+			t.Fatal(err)
+		}
+
+		expectPushed(t, conn, "proto")
+		expectClosed(t, conn)
+	}
+}
+
+func TestGate_KickFullPushQueue(t *testing.T) {
+	var (
+		ctx = context.Background()
+		c   = newTestCluster()
+		g   = c.startGate(t, gate.WithPushQueueSize(2))
+	)
+
+	conn, hold := fillPushQueue(t, c, g, "m1", "m2", "m3")
+
+	client := c.gateClient(t)
+
+	if _, err := client.Bind(ctx, conn.ID(), 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// 推送队列已满时踢下线通知消息仍优先于队列中的消息下发
+	if _, err := client.Kick(ctx, 10, map[string][]byte{"": []byte("kick")}); err != nil {
+		t.Fatal(err)
+	}
+
+	close(hold)
+
+	expectPushed(t, conn, "kick")
+	expectClosed(t, conn)
+}
+
+// 等待连接关闭
+func expectClosed(t *testing.T, conn *mockConn) {
+	t.Helper()
+
+	select {
+	case <-conn.closed:
+	case <-time.After(3 * time.Second):
+		t.Fatal("connection not closed")
+	}
+}
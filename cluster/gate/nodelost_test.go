@@ -0,0 +1,106 @@
+package gate_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/cluster/gate"
+	"github.com/dobyte/due/v2/packet"
+	"github.com/dobyte/due/v2/registry"
+	"testing"
+	"time"
+)
+
+func TestGate_NodeLost(t *testing.T) {
+	var (
+		ctx = context.Background()
+		ins = &registry.ServiceInstance{
+			ID:       "node-1",
+			Name:     cluster.Node.String(),
+			Kind:     cluster.Node.String(),
+			State:    cluster.Work.String(),
+			Endpoint: "grpc://127.0.0.1:1",
+			Routes:   []registry.Route{{ID: 1, Stateful: true}},
+		}
+	)
+
+	c := newTestCluster()
+
+	if err := c.registry.Register(ctx, ins); err != nil {
+		t.Fatal(err)
+	}
+
+	// 绑定发生在网关启动前，网关的来源缓存中不存在该用户
+	if err := c.locator.BindNode(ctx, 10, ins.Name, ins.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	losts := make(chan int64, 1)
+	c.startGate(t, gate.WithNodeLostGrace(300*time.Millisecond), gate.WithNodeLostHandler(func(lost *registry.ServiceInstance, uid int64) *packet.Message {
+		losts <- uid
+		return &packet.Message{Route: 2, Buffer: []byte(lost.ID)}
+	}))
+
+	conn := c.server.connect(1)
+	defer c.server.disconnect(conn)
+
+	if _, err := c.gateClient(t).Bind(ctx, 1, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	t.Run("flap", func(t *testing.T) {
+		if err := c.registry.Deregister(ctx, ins); err != nil {
+			t.Fatal(err)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+
+		if err := c.registry.Register(ctx, ins); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case uid := <-losts:
+			t.Fatalf("flapping node treated as lost, uid: %d", uid)
+		case <-time.After(600 * time.Millisecond):
+		}
+
+		if nid, _ := c.locator.LocateNode(ctx, 10, ins.Name); nid != ins.ID {
+			t.Fatalf("binding released by flapping node: %q", nid)
+		}
+	})
+
+	t.Run("lost", func(t *testing.T) {
+		if err := c.registry.Deregister(ctx, ins); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case uid := <-losts:
+			if uid != 10 {
+				t.Fatalf("unexpected lost uid: %d", uid)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatal("node lost handler not called")
+		}
+
+		select {
+		case buf := <-conn.pushed:
+			msg, err := packet.UnpackMessage(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if msg.Route != 2 || string(msg.Buffer) != ins.ID {
+				t.Fatalf("unexpected node lost message: %+v", msg)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatal("node lost message not pushed")
+		}
+
+		if nid, _ := c.locator.LocateNode(ctx, 10, ins.Name); nid != "" {
+			t.Fatalf("binding not released: %q", nid)
+		}
+	})
+}
@@ -9,13 +9,21 @@ package gate
 
 import (
 	"context"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/codes"
+	"github.com/dobyte/due/v2/core/breaker"
+	"github.com/dobyte/due/v2/core/id"
 	"github.com/dobyte/due/v2/etc"
 	"github.com/dobyte/due/v2/locate"
+	"github.com/dobyte/due/v2/packet"
 	"github.com/dobyte/due/v2/utils/xuuid"
+	"io"
 	"time"
 
 	"github.com/dobyte/due/v2/network"
 	"github.com/dobyte/due/v2/registry"
+	"github.com/dobyte/due/v2/room"
+	"github.com/dobyte/due/v2/session"
 )
 
 const (
@@ -23,6 +31,10 @@ const (
 	defaultAddr    = ":0"            // 连接器监听地址
 	defaultTimeout = 3 * time.Second // 默认超时时间
 	defaultWeight  = 1               // 默认权重
+
+	defaultPresenceInterval = 20 * time.Second // 默认在线状态刷新间隔
+	defaultPushQueueSize    = 0                // 默认推送队列容量
+	defaultNodeLostGrace    = 10 * time.Second // 默认有状态节点丢失宽限期
 )
 
 const (
@@ -31,22 +43,71 @@ const (
 	defaultAddrKey    = "etc.cluster.gate.addr"
 	defaultTimeoutKey = "etc.cluster.gate.timeout"
 	defaultWeightKey  = "etc.cluster.gate.weight"
+
+	defaultPresenceIntervalKey = "etc.cluster.gate.presenceInterval"
+	defaultPushQueueSizeKey    = "etc.cluster.gate.pushQueueSize"
+	defaultNodeLostGraceKey    = "etc.cluster.gate.nodeLostGrace"
+	defaultCloseRouteKey       = "etc.cluster.gate.closeRoute"
+	defaultAuditWindowKey      = "etc.cluster.gate.auditWindow"
+	defaultBindPolicyKey       = "etc.cluster.gate.bindPolicy"
+	defaultDrainOnCloseKey     = "etc.cluster.gate.drainOnClose"
 )
 
 type Option func(o *options)
 
 type options struct {
-	ctx      context.Context   // 上下文
-	id       string            // 实例ID
-	name     string            // 实例名称
-	addr     string            // 监听地址
-	timeout  time.Duration     // RPC调用超时时间
-	weight   int               // 权重
-	server   network.Server    // 网关服务器
-	locator  locate.Locator    // 用户定位器
-	registry registry.Registry // 服务注册器
+	ctx                  context.Context        // 上下文
+	id                   string                 // 实例ID
+	name                 string                 // 实例名称
+	addr                 string                 // 监听地址
+	timeout              time.Duration          // RPC调用超时时间
+	weight               int                    // 权重
+	server               network.Server         // 网关服务器
+	locator              locate.Locator         // 用户定位器
+	registry             registry.Registry      // 服务注册器
+	hedgingRoutes        []cluster.HedgingRoute // 请求对冲路由
+	breaker              *breaker.Group         // 熔断器组
+	roomManager          room.Manager           // 房间管理器
+	presence             time.Duration          // 在线状态刷新间隔
+	pushQueueSize        int                    // 推送队列容量（单优先级），为0时不启用推送队列
+	pushPolicies         [2]OverflowPolicy      // 推送队列溢出策略（按优先级划分）
+	highPriorityRoutes   map[int32]struct{}     // 高优先级路由
+	endpoints            map[string]string      // 命名端口
+	tags                 []string               // 自定义标签
+	authenticator        AuthenticateHandler    // 连接认证处理器
+	authFailedHandler    AuthFailedHandler      // 连接认证失败处理器
+	nodeLostHandler      NodeLostHandler        // 有状态节点丢失处理器
+	nodeLostGrace        time.Duration          // 有状态节点丢失宽限期
+	closeRoute           int32                  // 关闭通知路由，为0时不下发默认的关闭通知
+	closeNoticeHandler   CloseNoticeHandler     // 关闭通知处理器
+	closeReasonHandler   CloseReasonHandler     // 节点关闭通知处理器
+	balancer             registry.Balancer      // 负载均衡器
+	auditWindow          time.Duration          // 传输层序列号审计窗口，为0时不开启审计
+	seqGenerator         id.Generator           // 传输层序列号ID生成器，为nil时使用单调递增的序列号
+	sessionStore         session.Store          // 会话存储，为nil时不持久化会话
+	randomID             bool                   // 实例ID是否为随机生成
+	bindPolicy           BindPolicy             // 重复绑定策略
+	duplicateBindHandler DuplicateBindHandler   // 重复绑定处理器
+	drainOnClose         bool                   // 传输层连接关闭时是否处理已读取但尚未分发的消息
+	recordWriter         io.Writer              // 传输层消息录制输出，为nil时不录制
 }
 
+// AuthenticateHandler 连接认证处理器，data为连接认证前收到的首个数据包，认证成功后返回用户ID
+type AuthenticateHandler func(ctx context.Context, conn network.Conn, data []byte) (uid int64, err error)
+
+// AuthFailedHandler 连接认证失败处理器，返回关闭连接前下发给客户端的消息，返回nil时不下发，code固定为codes.Unauthorized
+type AuthFailedHandler func(conn network.Conn, data []byte, code *codes.Code) *packet.Message
+
+// NodeLostHandler 有状态节点丢失处理器，uid为当前网关上绑定到该节点的在线用户
+// 返回需推送给用户的消息（如重新绑定通知），由应用层决定重新绑定策略；返回nil时不推送
+type NodeLostHandler func(ins *registry.ServiceInstance, uid int64) *packet.Message
+
+// CloseNoticeHandler 关闭通知处理器，网关关闭时调用，返回需推送给所有连接的消息；返回nil时不推送
+type CloseNoticeHandler func(reason cluster.CloseReason) *packet.Message
+
+// CloseReasonHandler 节点关闭通知处理器，已连接的节点关闭前下发关闭原因时调用，addr为节点的传输层地址
+type CloseReasonHandler func(addr string, reason cluster.CloseReason)
+
 func defaultOptions() *options {
 	opts := &options{
 		ctx:     context.Background(),
@@ -56,10 +117,21 @@ func defaultOptions() *options {
 		weight:  defaultWeight,
 	}
 
+	opts.presence = etc.Get(defaultPresenceIntervalKey, defaultPresenceInterval).Duration()
+	opts.pushQueueSize = etc.Get(defaultPushQueueSizeKey, defaultPushQueueSize).Int()
+	opts.nodeLostGrace = etc.Get(defaultNodeLostGraceKey, defaultNodeLostGrace).Duration()
+	opts.closeRoute = etc.Get(defaultCloseRouteKey).Int32()
+	opts.auditWindow = etc.Get(defaultAuditWindowKey).Duration()
+	opts.bindPolicy = BindPolicy(etc.Get(defaultBindPolicyKey, BindUnbind).String())
+	opts.drainOnClose = etc.Get(defaultDrainOnCloseKey).Bool()
+	opts.pushPolicies = [2]OverflowPolicy{DropOldest, DropOldest}
+	opts.highPriorityRoutes = make(map[int32]struct{})
+
 	if id := etc.Get(defaultIDKey).String(); id != "" {
 		opts.id = id
 	} else {
 		opts.id = xuuid.UUID()
+		opts.randomID = true
 	}
 
 	if name := etc.Get(defaultNameKey).String(); name != "" {
@@ -83,7 +155,7 @@ func defaultOptions() *options {
 
 // WithID 设置实例ID
 func WithID(id string) Option {
-	return func(o *options) { o.id = id }
+	return func(o *options) { o.id, o.randomID = id, false }
 }
 
 // WithName 设置实例名称
@@ -91,6 +163,11 @@ func WithName(name string) Option {
 	return func(o *options) { o.name = name }
 }
 
+// WithAddr 设置连接地址
+func WithAddr(addr string) Option {
+	return func(o *options) { o.addr = addr }
+}
+
 // WithContext 设置上下文
 func WithContext(ctx context.Context) Option {
 	return func(o *options) { o.ctx = ctx }
@@ -120,3 +197,139 @@ func WithRegistry(r registry.Registry) Option {
 func WithWeight(weight int) Option {
 	return func(o *options) { o.weight = weight }
 }
+
+// WithHedgingRoutes 设置请求对冲路由，仅对幂等的无状态路由生效
+func WithHedgingRoutes(routes ...cluster.HedgingRoute) Option {
+	return func(o *options) { o.hedgingRoutes = append(o.hedgingRoutes, routes...) }
+}
+
+// WithBreaker 设置熔断器组，开启后按目标节点进行熔断
+func WithBreaker(b *breaker.Group) Option {
+	return func(o *options) { o.breaker = b }
+}
+
+// WithPresenceInterval 设置在线状态刷新间隔，定位器实现了locate.Presence时生效
+func WithPresenceInterval(interval time.Duration) Option {
+	return func(o *options) { o.presence = interval }
+}
+
+// WithRoomManager 设置房间管理器
+func WithRoomManager(manager room.Manager) Option {
+	return func(o *options) { o.roomManager = manager }
+}
+
+// WithPushQueueSize 设置推送队列容量（单优先级），为0时不启用推送队列
+func WithPushQueueSize(size int) Option {
+	return func(o *options) { o.pushQueueSize = size }
+}
+
+// WithPushOverflowPolicy 设置推送队列溢出策略
+func WithPushOverflowPolicy(priority Priority, policy OverflowPolicy) Option {
+	return func(o *options) { o.pushPolicies[priority] = policy }
+}
+
+// WithHighPriorityRoutes 设置高优先级路由，高优先级消息将优先于低优先级消息发送
+func WithHighPriorityRoutes(routes ...int32) Option {
+	return func(o *options) {
+		for _, route := range routes {
+			o.highPriorityRoutes[route] = struct{}{}
+		}
+	}
+}
+
+// WithEndpoint 设置命名端口，注册实例时一并注册到服务注册中心
+func WithEndpoint(name, endpoint string) Option {
+	return func(o *options) {
+		if o.endpoints == nil {
+			o.endpoints = make(map[string]string)
+		}
+		o.endpoints[name] = endpoint
+	}
+}
+
+// WithTags 设置自定义标签，注册实例时一并注册到服务注册中心，可用于按区域或版本过滤服务实例
+func WithTags(tags ...string) Option {
+	return func(o *options) { o.tags = append(o.tags, tags...) }
+}
+
+// WithAuthenticator 设置连接认证处理器，连接在认证通过前不会进行路由分发，认证数据包仅用于认证，不会分发到节点
+// 认证失败时先向客户端下发认证失败消息再关闭连接
+func WithAuthenticator(handler AuthenticateHandler) Option {
+	return func(o *options) { o.authenticator = handler }
+}
+
+// WithAuthFailedHandler 设置连接认证失败处理器，默认以认证数据包的路由及序列号下发编码后的codes.Unauthorized响应
+func WithAuthFailedHandler(handler AuthFailedHandler) Option {
+	return func(o *options) { o.authFailedHandler = handler }
+}
+
+// WithNodeLostHandler 设置有状态节点丢失处理器
+// 有状态节点从注册中心移除后，网关会解除在线用户与该节点的绑定关系，并通过处理器通知用户重新建立状态
+func WithNodeLostHandler(handler NodeLostHandler) Option {
+	return func(o *options) { o.nodeLostHandler = handler }
+}
+
+// WithNodeLostGrace 设置有状态节点丢失宽限期，默认为10s
+// 节点从注册中心移除后等待宽限期，期间重新上线视为抖动；宽限期结束后再次向注册中心确认节点已下线才解除用户绑定
+func WithNodeLostGrace(grace time.Duration) Option {
+	return func(o *options) { o.nodeLostGrace = grace }
+}
+
+// WithCloseRoute 设置关闭通知路由，网关关闭时以该路由向所有连接推送json编码的codes.Reply消息，code为关闭原因；默认为0，不推送
+func WithCloseRoute(route int32) Option {
+	return func(o *options) { o.closeRoute = route }
+}
+
+// WithCloseNoticeHandler 设置关闭通知处理器，设置后优先于关闭通知路由，由应用层决定推送给客户端的关闭通知
+func WithCloseNoticeHandler(handler CloseNoticeHandler) Option {
+	return func(o *options) { o.closeNoticeHandler = handler }
+}
+
+// WithCloseReasonHandler 设置节点关闭通知处理器，未设置时仅打印日志
+func WithCloseReasonHandler(handler CloseReasonHandler) Option {
+	return func(o *options) { o.closeReasonHandler = handler }
+}
+
+// WithBalancer 设置负载均衡器，用于无状态路由选择目标节点及为未绑定的用户选择有状态路由的绑定节点，选择依据为用户ID
+// 内置registry.NewRandomBalancer、registry.NewRoundRobinBalancer、registry.NewLeastConnBalancer、registry.NewLeastLoadBalancer
+// 使用registry.NewCanaryBalancer时按百分比将用户分流到金丝雀节点，分流状态在用户断开连接后释放
+func WithBalancer(balancer registry.Balancer) Option {
+	return func(o *options) { o.balancer = balancer }
+}
+
+// WithAuditWindow 设置传输层序列号审计窗口，开启后检测重复响应、未知序列号响应及窗口内未响应的请求；审计存在额外的跟踪开销，默认关闭
+func WithAuditWindow(window time.Duration) Option {
+	return func(o *options) { o.auditWindow = window }
+}
+
+// WithSeqGenerator 设置传输层序列号ID生成器，设置为id.Snowflake时序列号在重连及重建客户端后仍全局唯一，避免与旧连接上未完成的响应冲突
+func WithSeqGenerator(generator id.Generator) Option {
+	return func(o *options) { o.seqGenerator = generator }
+}
+
+// WithSessionStore 设置会话存储，用户绑定时持久化会话，网关重启后据此清理残留的用户定位
+// 网关需配置固定的实例ID，否则重启后无法识别属于自身的会话
+func WithSessionStore(store session.Store) Option {
+	return func(o *options) { o.sessionStore = store }
+}
+
+// WithBindPolicy 设置重复绑定策略，用户已在其他连接上绑定时，BindUnbind仅解除旧连接的绑定，BindTakeover踢掉旧连接由新连接接管，BindReject拒绝新连接的绑定；默认为BindUnbind
+// 检测其他网关上的旧连接需定位器实现locate.Presence
+func WithBindPolicy(policy BindPolicy) Option {
+	return func(o *options) { o.bindPolicy = policy }
+}
+
+// WithDuplicateBindHandler 设置重复绑定处理器，发生重复绑定时在执行重复绑定策略前调用，可用于审计重复登录
+func WithDuplicateBindHandler(handler DuplicateBindHandler) Option {
+	return func(o *options) { o.duplicateBindHandler = handler }
+}
+
+// WithRecordWriter 设置传输层消息录制输出，设置后录制内建RPC服务器连接上读取到的原始消息，用于调试时复现协议问题；录制存在额外的写入开销，仅建议调试时开启
+func WithRecordWriter(w io.Writer) Option {
+	return func(o *options) { o.recordWriter = w }
+}
+
+// WithDrainOnClose 设置传输层连接关闭时是否在关闭写入前处理已读取但尚未分发的消息，默认丢弃
+func WithDrainOnClose(drain bool) Option {
+	return func(o *options) { o.drainOnClose = drain }
+}
@@ -0,0 +1,83 @@
+package gate_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster/gate"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGate_RefreshPresence(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		c       = newTestCluster()
+		locator = &presenceLocator{Locator: c.locator, refreshes: make(chan []int64, 1)}
+	)
+
+	c.startGate(t, gate.WithLocator(locator), gate.WithPresenceInterval(20*time.Millisecond))
+
+	client := c.gateClient(t)
+	conn1, conn2 := c.server.connect(1), c.server.connect(2)
+
+	if _, err := client.Bind(ctx, 1, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Bind(ctx, 2, 11); err != nil {
+		t.Fatal(err)
+	}
+
+	expectRefresh(t, locator.refreshes, []int64{10, 11})
+
+	// 断开连接的用户不再刷新在线状态
+	c.server.disconnect(conn2)
+
+	expectRefresh(t, locator.refreshes, []int64{10})
+
+	c.server.disconnect(conn1)
+}
+
+func TestGate_RefreshPresenceDisabled(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		c       = newTestCluster()
+		locator = &presenceLocator{Locator: c.locator, refreshes: make(chan []int64, 1)}
+	)
+
+	c.startGate(t, gate.WithLocator(locator), gate.WithPresenceInterval(0))
+
+	conn := c.server.connect(1)
+
+	if _, err := c.gateClient(t).Bind(ctx, 1, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case uids := <-locator.refreshes:
+		t.Fatalf("unexpected refresh: %v", uids)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	c.server.disconnect(conn)
+}
+
+// 等待在线状态刷新的用户与期望一致
+func expectRefresh(t *testing.T, refreshes chan []int64, expected []int64) {
+	t.Helper()
+
+	timeout := time.After(3 * time.Second)
+
+	for {
+		select {
+		case uids := <-refreshes:
+			slices.Sort(uids)
+
+			if slices.Equal(uids, expected) {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("expected refresh of %v", expected)
+		}
+	}
+}
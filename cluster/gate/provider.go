@@ -5,6 +5,7 @@ import (
 	"github.com/dobyte/due/v2/cluster"
 	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/log"
+	"github.com/dobyte/due/v2/network"
 	"github.com/dobyte/due/v2/session"
 	"github.com/dobyte/due/v2/utils/xcall"
 )
@@ -19,17 +20,7 @@ func (p *provider) Bind(ctx context.Context, cid, uid int64) error {
 		return errors.ErrInvalidArgument
 	}
 
-	err := p.gate.session.Bind(cid, uid)
-	if err != nil {
-		return err
-	}
-
-	err = p.gate.proxy.bindGate(ctx, cid, uid)
-	if err != nil {
-		_, _ = p.gate.session.Unbind(uid)
-	}
-
-	return err
+	return p.gate.bind(ctx, cid, uid)
 }
 
 // Unbind 解绑用户与网关间的关系
@@ -43,6 +34,8 @@ func (p *provider) Unbind(ctx context.Context, uid int64) error {
 		return err
 	}
 
+	p.gate.unbindSession(ctx, cid, uid)
+
 	return p.gate.proxy.unbindGate(ctx, cid, uid)
 }
 
@@ -81,6 +74,68 @@ func (p *provider) Push(ctx context.Context, kind session.Kind, target int64, me
 	return err
 }
 
+// PushWithHeader 发送携带推送头信息的消息，启用推送队列时按头信息进行优先级调度与过期丢弃
+func (p *provider) PushWithHeader(ctx context.Context, kind session.Kind, target int64, header *cluster.PushHeader, message []byte) error {
+	conn, err := p.gate.session.Conn(kind, target)
+	if err != nil {
+		return p.Push(ctx, kind, target, message)
+	}
+
+	if q, ok := conn.(*pushQueue); ok {
+		return q.pushWithHeader(message, header)
+	}
+
+	return conn.Push(message)
+}
+
+// Kick 踢用户下线，下发与连接协商的编解码器对应的踢下线通知消息后优雅关闭连接
+// 用户并发断开连接时视为未找到会话
+func (p *provider) Kick(ctx context.Context, uid int64, messages map[string][]byte) error {
+	conn, err := p.gate.session.Conn(session.User, uid)
+	if err != nil {
+		return err
+	}
+
+	return kickConn(conn, codecMessage(conn, messages))
+}
+
+// 下发踢下线通知消息后优雅关闭连接，连接已断开时返回errors.ErrNotFoundSession
+// 启用推送队列时通知消息作为高优先级消息入队，由推送队列下发后关闭连接
+func kickConn(conn network.Conn, message []byte) (err error) {
+	if q, ok := conn.(*pushQueue); ok {
+		return q.kick(message)
+	}
+
+	if len(message) > 0 {
+		if err = conn.Send(message); err != nil {
+			if isConnectionGone(err) {
+				return errors.ErrNotFoundSession
+			}
+			return err
+		}
+	}
+
+	if err = conn.Close(); err != nil && isConnectionGone(err) {
+		return errors.ErrNotFoundSession
+	}
+
+	return nil
+}
+
+// 选择与连接协商的编解码器对应的消息，未协商或无对应消息时使用编解码器名称为空的消息
+func codecMessage(conn network.Conn, messages map[string][]byte) []byte {
+	var codec string
+	if c, ok := conn.(network.CodecConn); ok {
+		codec = c.Codec()
+	}
+
+	if message, ok := messages[codec]; ok {
+		return message
+	}
+
+	return messages[""]
+}
+
 // Multicast 推送组播消息
 func (p *provider) Multicast(ctx context.Context, kind session.Kind, targets []int64, message []byte) (int64, error) {
 	return p.gate.session.Multicast(kind, targets, message)
@@ -91,6 +146,11 @@ func (p *provider) Broadcast(ctx context.Context, kind session.Kind, message []b
 	return p.gate.session.Broadcast(kind, message)
 }
 
+// BroadcastCodecs 按连接协商的编解码器推送广播消息
+func (p *provider) BroadcastCodecs(ctx context.Context, kind session.Kind, messages map[string][]byte) (int64, error) {
+	return p.gate.session.BroadcastCodecs(kind, messages)
+}
+
 // GetState 获取状态
 func (p *provider) GetState() (cluster.State, error) {
 	return cluster.Work, nil
@@ -100,3 +160,8 @@ func (p *provider) GetState() (cluster.State, error) {
 func (p *provider) SetState(state cluster.State) error {
 	return nil
 }
+
+// 连接是否已断开或正在断开
+func isConnectionGone(err error) bool {
+	return errors.Is(err, errors.ErrConnectionHanged) || errors.Is(err, errors.ErrConnectionClosed) || errors.Is(err, errors.ErrConnectionNotOpened)
+}
@@ -8,20 +8,43 @@ import (
 	"github.com/dobyte/due/v2/log"
 	"github.com/dobyte/due/v2/mode"
 	"github.com/dobyte/due/v2/packet"
+	"github.com/dobyte/due/v2/registry"
+	"github.com/dobyte/due/v2/session"
+	"github.com/dobyte/due/v2/utils/xcall"
 )
 
 type proxy struct {
 	gate       *Gate            // 网关服
 	nodeLinker *link.NodeLinker // 节点链接器
+	gateLinker *link.GateLinker // 网关链接器，整个网关共用，用于重复绑定时踢掉其他网关上的旧连接
 }
 
 func newProxy(gate *Gate) *proxy {
-	return &proxy{gate: gate, nodeLinker: link.NewNodeLinker(gate.ctx, &link.Options{
-		InsID:    gate.opts.id,
-		InsKind:  cluster.Gate,
-		Locator:  gate.opts.locator,
-		Registry: gate.opts.registry,
-	})}
+	p := &proxy{gate: gate}
+	p.nodeLinker = link.NewNodeLinker(gate.ctx, &link.Options{
+		InsID:              gate.opts.id,
+		InsKind:            cluster.Gate,
+		Locator:            gate.opts.locator,
+		Registry:           gate.opts.registry,
+		HedgingRoutes:      gate.opts.hedgingRoutes,
+		Breaker:            gate.opts.breaker,
+		NodeLostHandler:    p.nodeLost,
+		NodeLostGrace:      gate.opts.nodeLostGrace,
+		CloseReasonHandler: link.CloseReasonHandler(gate.opts.closeReasonHandler),
+		Balancer:           gate.opts.balancer,
+		AuditWindow:        gate.opts.auditWindow,
+		SeqGenerator:       gate.opts.seqGenerator,
+	})
+	p.gateLinker = link.NewGateLinker(gate.ctx, &link.Options{
+		InsID:        gate.opts.id,
+		InsKind:      cluster.Gate,
+		Locator:      gate.opts.locator,
+		Registry:     gate.opts.registry,
+		AuditWindow:  gate.opts.auditWindow,
+		SeqGenerator: gate.opts.seqGenerator,
+	})
+
+	return p
 }
 
 // 绑定用户与网关间的关系
@@ -46,6 +69,11 @@ func (p *proxy) unbindGate(ctx context.Context, cid, uid int64) error {
 	return err
 }
 
+// 踢掉其他网关上的用户连接，args.GID需指定旧连接所在的网关
+func (p *proxy) kick(ctx context.Context, args *link.KickArgs) (bool, error) {
+	return p.gateLinker.Kick(ctx, args)
+}
+
 // 触发事件
 func (p *proxy) trigger(ctx context.Context, event cluster.Event, cid, uid int64) {
 	if mode.IsDebugMode() {
@@ -67,7 +95,7 @@ func (p *proxy) trigger(ctx context.Context, event cluster.Event, cid, uid int64
 }
 
 // 投递消息
-func (p *proxy) deliver(ctx context.Context, cid, uid int64, message []byte) {
+func (p *proxy) deliver(ctx context.Context, cid, uid int64, codec string, message []byte) {
 	msg, err := packet.UnpackMessage(message)
 	if err != nil {
 		log.Errorf("unpack message failed: %v", err)
@@ -82,6 +110,7 @@ func (p *proxy) deliver(ctx context.Context, cid, uid int64, message []byte) {
 		CID:     cid,
 		UID:     uid,
 		Route:   msg.Route,
+		Codec:   codec,
 		Message: message,
 	}); err != nil {
 		switch {
@@ -93,9 +122,70 @@ func (p *proxy) deliver(ctx context.Context, cid, uid int64, message []byte) {
 	}
 }
 
+// 处理有状态节点丢失，解除当前网关在线用户与该节点的绑定关系并通知用户
+// 来源缓存仅包含经过当前网关访问过该节点的用户，其余在线用户需通过定位器确认绑定关系
+func (p *proxy) nodeLost(ins *registry.ServiceInstance, uids []int64) {
+	log.Warnf("stateful node lost, nid: %s name: %s users: %d", ins.ID, ins.Name, len(uids))
+
+	xcall.Go(func() {
+		cached := make(map[int64]struct{}, len(uids))
+		for _, uid := range uids {
+			cached[uid] = struct{}{}
+		}
+
+		for _, uid := range p.gate.session.UIDs() {
+			if _, ok := cached[uid]; !ok && !p.boundNode(uid, ins) {
+				continue
+			}
+
+			ctx, cancel := context.WithTimeout(p.gate.ctx, p.gate.opts.timeout)
+			err := p.gate.opts.locator.UnbindNode(ctx, uid, ins.Name, ins.ID)
+			cancel()
+			if err != nil {
+				log.Warnf("unbind lost node failed, uid: %d nid: %s err: %v", uid, ins.ID, err)
+			}
+
+			if p.gate.opts.nodeLostHandler == nil {
+				continue
+			}
+
+			message := p.gate.opts.nodeLostHandler(ins, uid)
+			if message == nil {
+				continue
+			}
+
+			msg, err := packet.PackMessage(message)
+			if err != nil {
+				log.Errorf("pack message failed: %v", err)
+				continue
+			}
+
+			if err = p.gate.session.Push(session.User, uid, msg); err != nil {
+				log.Warnf("push node lost message failed, uid: %d nid: %s err: %v", uid, ins.ID, err)
+			}
+		}
+	})
+}
+
+// 通过定位器检测用户是否绑定到指定节点
+func (p *proxy) boundNode(uid int64, ins *registry.ServiceInstance) bool {
+	ctx, cancel := context.WithTimeout(p.gate.ctx, p.gate.opts.timeout)
+	defer cancel()
+
+	nid, err := p.gate.opts.locator.LocateNode(ctx, uid, ins.Name)
+	if err != nil {
+		return false
+	}
+
+	return nid == ins.ID
+}
+
 // 开始监听
 func (p *proxy) watch() {
 	p.nodeLinker.WatchUserLocate()
 
 	p.nodeLinker.WatchClusterInstance()
+
+	// 网关链接器仅用于按网关ID直接踢掉旧连接，无需监听用户定位
+	p.gateLinker.WatchClusterInstance()
 }
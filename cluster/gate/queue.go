@@ -0,0 +1,263 @@
+package gate
+
+import (
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/log"
+	"github.com/dobyte/due/v2/network"
+	"github.com/dobyte/due/v2/packet"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DropOldest OverflowPolicy = iota // 丢弃最旧的消息
+	DropNewest                       // 丢弃最新的消息
+	Disconnect                       // 断开连接
+)
+
+// OverflowPolicy 推送队列溢出策略
+type OverflowPolicy int
+
+const (
+	LowPriority  Priority = iota // 低优先级
+	HighPriority                 // 高优先级
+)
+
+// Priority 推送消息优先级
+type Priority int
+
+// PushQueueStat 推送队列统计
+type PushQueueStat struct {
+	CID   int64 // 连接ID
+	UID   int64 // 用户ID
+	Depth int   // 队列深度
+	Drops int64 // 丢弃消息数
+}
+
+type pushQueue struct {
+	network.Conn
+	gate   *Gate
+	mu     sync.Mutex
+	lanes  [2][]pushItem // 消息队列（按优先级划分）
+	notify chan struct{} // 通知信号
+	done   chan struct{} // 关闭信号
+	once   sync.Once
+	drops  atomic.Int64 // 丢弃消息数
+}
+
+type pushItem struct {
+	msg      []byte    // 消息
+	deadline time.Time // 过期时间，为零值时永不过期
+	close    bool      // 下发后是否优雅关闭连接
+}
+
+func newPushQueue(gate *Gate, conn network.Conn) *pushQueue {
+	q := &pushQueue{
+		Conn:   conn,
+		gate:   gate,
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+
+	go q.drain()
+
+	return q
+}
+
+// Push 发送消息（异步），消息将进入有界推送队列
+func (q *pushQueue) Push(msg []byte) error {
+	return q.enqueue(pushItem{msg: msg}, q.gate.priority(msg))
+}
+
+// Codec 获取被包装连接协商的编解码器名称，被包装连接不支持协商时返回空字符串
+func (q *pushQueue) Codec() string {
+	if c, ok := q.Conn.(network.CodecConn); ok {
+		return c.Codec()
+	}
+
+	return ""
+}
+
+// 根据推送头信息发送消息（异步），推送头信息中的优先级将覆盖路由优先级
+func (q *pushQueue) pushWithHeader(msg []byte, header *cluster.PushHeader) error {
+	item := pushItem{msg: msg}
+
+	if header.TTL > 0 {
+		item.deadline = time.Now().Add(header.TTL)
+	}
+
+	priority := LowPriority
+	if header.Priority > 0 {
+		priority = HighPriority
+	}
+
+	return q.enqueue(item, priority)
+}
+
+// 消息入队
+func (q *pushQueue) enqueue(item pushItem, priority Priority) error {
+	policy := q.gate.opts.pushPolicies[priority]
+
+	q.mu.Lock()
+
+	lane := q.lanes[priority]
+
+	if len(lane) >= q.gate.opts.pushQueueSize {
+		switch policy {
+		case DropNewest:
+			q.mu.Unlock()
+			q.drops.Add(1)
+			return nil
+		case Disconnect:
+			q.mu.Unlock()
+			q.drops.Add(1)
+			log.Warnf("push queue overflow, connection will be closed, cid: %d uid: %d", q.ID(), q.UID())
+			return q.Conn.Close(true)
+		default:
+			lane[0] = pushItem{}
+			lane = lane[1:]
+			q.drops.Add(1)
+		}
+	}
+
+	q.lanes[priority] = append(lane, item)
+
+	q.mu.Unlock()
+
+	q.signal()
+
+	return nil
+}
+
+// 下发踢下线通知消息后优雅关闭连接，连接已断开时返回errors.ErrNotFoundSession
+// 通知消息作为高优先级消息入队，不受队列容量及溢出策略限制；关闭连接后队列中剩余的消息将被丢弃
+func (q *pushQueue) kick(msg []byte) error {
+	if q.Conn.State() == network.ConnClosed {
+		return errors.ErrNotFoundSession
+	}
+
+	q.mu.Lock()
+	q.lanes[HighPriority] = append(q.lanes[HighPriority], pushItem{msg: msg, close: true})
+	q.mu.Unlock()
+
+	q.signal()
+
+	return nil
+}
+
+// 通知排空队列
+func (q *pushQueue) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// 统计队列
+func (q *pushQueue) stat() PushQueueStat {
+	q.mu.Lock()
+	depth := len(q.lanes[LowPriority]) + len(q.lanes[HighPriority])
+	q.mu.Unlock()
+
+	return PushQueueStat{CID: q.ID(), UID: q.UID(), Depth: depth, Drops: q.drops.Load()}
+}
+
+// 关闭队列
+func (q *pushQueue) close() {
+	q.once.Do(func() { close(q.done) })
+}
+
+// 排空队列，高优先级消息优先发送
+func (q *pushQueue) drain() {
+	for {
+		item, ok := q.pop()
+		if !ok {
+			select {
+			case <-q.notify:
+				continue
+			case <-q.done:
+				return
+			}
+		}
+
+		if len(item.msg) > 0 {
+			if err := q.Conn.Push(item.msg); err != nil {
+				if errors.Is(err, errors.ErrConnectionClosed) {
+					return
+				}
+
+				log.Warnf("push message failed, cid: %d uid: %d err: %v", q.ID(), q.UID(), err)
+			}
+		}
+
+		if item.close {
+			if err := q.Conn.Close(); err != nil && !isConnectionGone(err) {
+				log.Warnf("kick connection close failed, cid: %d uid: %d err: %v", q.ID(), q.UID(), err)
+			}
+			return
+		}
+	}
+}
+
+// 弹出消息，已过期的消息将被丢弃
+func (q *pushQueue) pop() (pushItem, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var now time.Time
+
+	for _, priority := range []Priority{HighPriority, LowPriority} {
+		for lane := q.lanes[priority]; len(lane) > 0; lane = q.lanes[priority] {
+			item := lane[0]
+			lane[0] = pushItem{}
+			q.lanes[priority] = lane[1:]
+
+			if !item.deadline.IsZero() {
+				if now.IsZero() {
+					now = time.Now()
+				}
+
+				if now.After(item.deadline) {
+					q.drops.Add(1)
+					continue
+				}
+			}
+
+			return item, true
+		}
+	}
+
+	return pushItem{}, false
+}
+
+// 获取消息优先级
+func (g *Gate) priority(msg []byte) Priority {
+	if len(g.opts.highPriorityRoutes) == 0 {
+		return LowPriority
+	}
+
+	message, err := packet.UnpackMessage(msg)
+	if err != nil {
+		return LowPriority
+	}
+
+	if _, ok := g.opts.highPriorityRoutes[message.Route]; ok {
+		return HighPriority
+	}
+
+	return LowPriority
+}
+
+// PushQueueStats 获取所有连接的推送队列统计
+func (g *Gate) PushQueueStats() []PushQueueStat {
+	stats := make([]PushQueueStat, 0)
+
+	g.queues.Range(func(_, value any) bool {
+		stats = append(stats, value.(*pushQueue).stat())
+		return true
+	})
+
+	return stats
+}
@@ -0,0 +1,134 @@
+package gate_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster/gate"
+	"github.com/dobyte/due/v2/core/buffer"
+	tgate "github.com/dobyte/due/v2/internal/transporter/gate"
+	"github.com/dobyte/due/v2/packet"
+	"github.com/dobyte/due/v2/session"
+	"testing"
+	"time"
+)
+
+func TestGate_PushQueueOverflow(t *testing.T) {
+	t.Run("drop oldest", func(t *testing.T) {
+		c := newTestCluster()
+		g := c.startGate(t, gate.WithPushQueueSize(2))
+
+		conn, hold := fillPushQueue(t, c, g, "m1", "m2", "m3", "m4")
+		close(hold)
+
+		expectPushed(t, conn, "m3", "m4")
+	})
+
+	t.Run("drop newest", func(t *testing.T) {
+		c := newTestCluster()
+		g := c.startGate(t, gate.WithPushQueueSize(2), gate.WithPushOverflowPolicy(gate.LowPriority, gate.DropNewest))
+
+		conn, hold := fillPushQueue(t, c, g, "m1", "m2", "m3", "m4")
+		close(hold)
+
+		expectPushed(t, conn, "m2", "m3")
+	})
+
+	t.Run("disconnect", func(t *testing.T) {
+		c := newTestCluster()
+		g := c.startGate(t, gate.WithPushQueueSize(2), gate.WithPushOverflowPolicy(gate.LowPriority, gate.Disconnect))
+
+		conn, hold := fillPushQueue(t, c, g, "m1", "m2", "m3", "m4")
+		defer close(hold)
+
+		select {
+		case <-conn.closed:
+		case <-time.After(3 * time.Second):
+			t.Fatal("connection not closed on overflow")
+		}
+	})
+}
+
+func TestGate_PushQueuePriority(t *testing.T) {
+	c := newTestCluster()
+	g := c.startGate(t, gate.WithPushQueueSize(4), gate.WithHighPriorityRoutes(9))
+
+	pack := func(route int32) []byte {
+		data, err := packet.PackMessage(&packet.Message{Route: route})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	low1, low2, high := string(pack(1)), string(pack(2)), string(pack(9))
+
+	conn, hold := fillPushQueue(t, c, g, low1, low2, high)
+	close(hold)
+
+	// 高优先级消息优先于先入队的低优先级消息下发
+	expectPushed(t, conn, high, low2)
+}
+
+// 建立阻塞下发的连接并依次推送消息，首个消息下发阻塞后等待其余消息全部入队或丢弃
+func fillPushQueue(t *testing.T, c *testCluster, g *gate.Gate, messages ...string) (*mockConn, chan struct{}) {
+	t.Helper()
+
+	var (
+		ctx    = context.Background()
+		hold   = make(chan struct{})
+		conn   = c.server.connectHeld(1, hold)
+		client = c.gateClient(t)
+	)
+
+	push := func(client *tgate.Client, message string) {
+		if err := client.Push(ctx, session.Conn, conn.ID(), buffer.NewNocopyBuffer([]byte(message))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 先于网关关闭断开连接
+	t.Cleanup(func() { c.server.disconnect(conn) })
+
+	push(client, messages[0])
+
+	expectPushed(t, conn, messages[0])
+
+	for _, message := range messages[1:] {
+		push(client, message)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+
+	for {
+		var handled int
+
+		for _, stat := range g.PushQueueStats() {
+			handled += stat.Depth + int(stat.Drops)
+		}
+
+		if handled == len(messages)-1 {
+			return conn, hold
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("messages not enqueued, stats: %+v", g.PushQueueStats())
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// 等待连接依次下发期望的消息
+func expectPushed(t *testing.T, conn *mockConn, messages ...string) {
+	t.Helper()
+
+	for _, message := range messages {
+		select {
+		case msg := <-conn.pushed:
+			if string(msg) != message {
+				t.Fatalf("unexpected message: %q, expected: %q", msg, message)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("expected message %q, got none", message)
+		}
+	}
+}
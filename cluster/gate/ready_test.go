@@ -0,0 +1,24 @@
+package gate_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/errors"
+	"testing"
+)
+
+func TestGate_Ready(t *testing.T) {
+	c := newTestCluster()
+
+	g := c.startGate(t)
+
+	if err := g.Ready(context.Background()); err != nil {
+		t.Fatalf("expected ready, got %v", err)
+	}
+
+	// 关闭后不再就绪
+	g.Close()
+
+	if err := g.Ready(context.Background()); !errors.Is(err, errors.ErrNotReady) {
+		t.Fatalf("expected ErrNotReady, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package gate
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/log"
+	"github.com/dobyte/due/v2/session"
+	"strconv"
+)
+
+// 保存会话，保留会话中已有的自定义数据
+func (g *Gate) saveSession(ctx context.Context, cid, uid int64) {
+	if g.opts.sessionStore == nil {
+		return
+	}
+
+	id := strconv.FormatInt(uid, 10)
+
+	values, err := g.opts.sessionStore.Load(ctx, id)
+	if err != nil {
+		if !errors.Is(err, errors.ErrNotFoundSession) {
+			log.Warnf("load session failed, uid = %d err = %v", uid, err)
+		}
+		values = make(map[string]string)
+	}
+
+	values[session.StoreKeyUID] = id
+	values[session.StoreKeyCID] = strconv.FormatInt(cid, 10)
+	values[session.StoreKeyGID] = g.opts.id
+
+	if err = g.opts.sessionStore.Save(ctx, id, values); err != nil {
+		log.Warnf("save session failed, uid = %d err = %v", uid, err)
+	}
+}
+
+// 解除会话与当前连接的绑定，保留会话中的自定义数据，待用户重新绑定时继续使用
+func (g *Gate) unbindSession(ctx context.Context, cid, uid int64) {
+	values, ok := g.ownedSession(ctx, cid, uid)
+	if !ok {
+		return
+	}
+
+	delete(values, session.StoreKeyCID)
+	delete(values, session.StoreKeyGID)
+
+	if err := g.opts.sessionStore.Save(ctx, strconv.FormatInt(uid, 10), values); err != nil {
+		log.Warnf("save session failed, uid = %d err = %v", uid, err)
+	}
+}
+
+// 删除会话，连接断开后会话随之失效
+func (g *Gate) deleteSession(ctx context.Context, cid, uid int64) {
+	if _, ok := g.ownedSession(ctx, cid, uid); !ok {
+		return
+	}
+
+	if err := g.opts.sessionStore.Delete(ctx, strconv.FormatInt(uid, 10)); err != nil {
+		log.Warnf("delete session failed, uid = %d err = %v", uid, err)
+	}
+}
+
+// 加载属于当前网关指定连接的会话，用户已在其他网关或连接上重新绑定时返回false，避免误删新会话
+func (g *Gate) ownedSession(ctx context.Context, cid, uid int64) (map[string]string, bool) {
+	if g.opts.sessionStore == nil {
+		return nil, false
+	}
+
+	values, err := g.opts.sessionStore.Load(ctx, strconv.FormatInt(uid, 10))
+	if err != nil {
+		if !errors.Is(err, errors.ErrNotFoundSession) {
+			log.Warnf("load session failed, uid = %d err = %v", uid, err)
+		}
+		return nil, false
+	}
+
+	if values[session.StoreKeyGID] != g.opts.id || values[session.StoreKeyCID] != strconv.FormatInt(cid, 10) {
+		return nil, false
+	}
+
+	return values, true
+}
+
+// 恢复会话
+// 网关重启后原有连接均已断开，遍历属于当前网关的会话，解除定位器中残留的网关绑定并清除连接信息，会话中的自定义数据予以保留，待用户重新绑定时继续使用
+func (g *Gate) recoverSessions() {
+	if g.opts.sessionStore == nil {
+		return
+	}
+
+	type stale struct {
+		uid    int64
+		values map[string]string
+	}
+
+	var sessions []stale
+
+	if err := g.opts.sessionStore.Iterate(g.ctx, func(id string, values map[string]string) bool {
+		if values[session.StoreKeyGID] != g.opts.id || values[session.StoreKeyCID] == "" {
+			return true
+		}
+
+		if uid, err := strconv.ParseInt(id, 10, 64); err == nil {
+			sessions = append(sessions, stale{uid: uid, values: values})
+		}
+
+		return true
+	}); err != nil {
+		log.Warnf("iterate sessions failed: %v", err)
+		return
+	}
+
+	for _, s := range sessions {
+		ctx, cancel := context.WithTimeout(g.ctx, g.opts.timeout)
+
+		if err := g.opts.locator.UnbindGate(ctx, s.uid, g.opts.id); err != nil {
+			log.Warnf("unbind stale gate failed, uid = %d gid = %s err = %v", s.uid, g.opts.id, err)
+		}
+
+		delete(s.values, session.StoreKeyCID)
+		delete(s.values, session.StoreKeyGID)
+
+		if err := g.opts.sessionStore.Save(ctx, strconv.FormatInt(s.uid, 10), s.values); err != nil {
+			log.Warnf("save session failed, uid = %d err = %v", s.uid, err)
+		}
+
+		cancel()
+	}
+
+	if len(sessions) > 0 {
+		log.Infof("%d stale sessions recovered", len(sessions))
+	}
+}
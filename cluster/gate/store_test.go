@@ -0,0 +1,83 @@
+package gate_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster/gate"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/session"
+	"testing"
+)
+
+func TestGate_SessionStore(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = session.NewMemoryStore()
+		c     = newTestCluster()
+	)
+
+	// 模拟网关崩溃前残留的会话及用户定位
+	_ = store.Save(ctx, "1", map[string]string{
+		session.StoreKeyUID: "1",
+		session.StoreKeyCID: "5",
+		session.StoreKeyGID: "gate-1",
+		"level":             "10",
+	})
+	_ = store.Save(ctx, "2", map[string]string{
+		session.StoreKeyUID: "2",
+		session.StoreKeyCID: "6",
+		session.StoreKeyGID: "gate-2",
+	})
+	_ = c.locator.BindGate(ctx, 1, "gate-1")
+	_ = c.locator.BindGate(ctx, 2, "gate-2")
+
+	c.startGate(t, gate.WithSessionStore(store))
+
+	if gid, _ := c.locator.LocateGate(ctx, 1); gid != "" {
+		t.Fatalf("stale gate binding not released: %s", gid)
+	}
+
+	if gid, _ := c.locator.LocateGate(ctx, 2); gid != "gate-2" {
+		t.Fatalf("other gate binding released: %s", gid)
+	}
+
+	values, err := store.Load(ctx, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if values["level"] != "10" || values[session.StoreKeyCID] != "" || values[session.StoreKeyGID] != "" {
+		t.Fatalf("unexpected recovered session: %v", values)
+	}
+
+	// 用户重新连接并绑定，沿用恢复的自定义数据
+	conn := c.server.connect(7)
+	client := c.gateClient(t)
+
+	if _, err = client.Bind(ctx, 7, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if values, _ = store.Load(ctx, "1"); values[session.StoreKeyCID] != "7" || values[session.StoreKeyGID] != "gate-1" || values["level"] != "10" {
+		t.Fatalf("unexpected bound session: %v", values)
+	}
+
+	// 解绑时保留自定义数据
+	if _, err = client.Unbind(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if values, _ = store.Load(ctx, "1"); values[session.StoreKeyCID] != "" || values["level"] != "10" {
+		t.Fatalf("unexpected unbound session: %v", values)
+	}
+
+	// 断开连接时删除会话
+	if _, err = client.Bind(ctx, 7, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	c.server.disconnect(conn)
+
+	if _, err = store.Load(ctx, "1"); !errors.Is(err, errors.ErrNotFoundSession) {
+		t.Fatalf("session not deleted on disconnect: %v", err)
+	}
+}
@@ -0,0 +1,317 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/component"
+	"github.com/dobyte/due/v2/core/endpoint"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/internal/transporter/node"
+	"github.com/dobyte/due/v2/lock"
+	"github.com/dobyte/due/v2/log"
+	"github.com/dobyte/due/v2/registry"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LeaderChangeHandler 主节点变更处理器
+type LeaderChangeHandler func(isLeader bool)
+
+// 可通知锁丢失的选举锁
+type lostNotifier interface {
+	OnLost(fn func())
+}
+
+// 可查询锁剩余生存时间的选举锁
+type ttlQuerier interface {
+	TTL(ctx context.Context) (time.Duration, error)
+}
+
+type Master struct {
+	component.Base
+	opts     *options
+	ctx      context.Context
+	cancel   context.CancelFunc
+	locker   lock.Locker
+	lost     chan struct{}
+	done     chan struct{}
+	leader   atomic.Bool
+	rw       sync.RWMutex
+	handlers []LeaderChangeHandler
+	builder  *node.Builder
+}
+
+func NewMaster(opts ...Option) *Master {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	m := &Master{}
+	m.opts = o
+	m.handlers = make([]LeaderChangeHandler, 0)
+	m.builder = node.NewBuilder(&node.Options{InsID: o.id, InsKind: cluster.Master})
+	m.ctx, m.cancel = context.WithCancel(o.ctx)
+
+	return m
+}
+
+// Name 组件名称
+func (m *Master) Name() string {
+	return m.opts.name
+}
+
+// Init 初始化
+func (m *Master) Init() {
+	if m.opts.maker == nil {
+		log.Fatal("lock maker component is not injected")
+	}
+
+	if m.opts.eventbus == nil {
+		log.Fatal("eventbus component is not injected")
+	}
+}
+
+// Start 启动
+func (m *Master) Start() {
+	m.locker = m.opts.maker.Make(m.opts.lockName)
+	m.lost = make(chan struct{}, 1)
+	m.done = make(chan struct{})
+
+	if notifier, ok := m.locker.(lostNotifier); ok {
+		notifier.OnLost(func() {
+			select {
+			case m.lost <- struct{}{}:
+			default:
+			}
+		})
+	}
+
+	go m.campaign()
+
+	log.Infof("master server startup successful, id: %s, name: %s", m.opts.id, m.opts.name)
+}
+
+// Close 关闭
+func (m *Master) Close() {
+	m.cancel()
+
+	if m.done != nil {
+		<-m.done
+	}
+
+	m.resign()
+}
+
+// ID 获取实例ID
+func (m *Master) ID() string {
+	return m.opts.id
+}
+
+// IsLeader 是否为主节点
+func (m *Master) IsLeader() bool {
+	return m.leader.Load()
+}
+
+// OnLeaderChange 监听主节点变更
+func (m *Master) OnLeaderChange(handler LeaderChangeHandler) {
+	m.rw.Lock()
+	m.handlers = append(m.handlers, handler)
+	m.rw.Unlock()
+}
+
+// Broadcast 广播配置或指令，仅主节点可广播
+func (m *Master) Broadcast(ctx context.Context, topic string, message interface{}) error {
+	if !m.IsLeader() {
+		return errors.ErrNotLeader
+	}
+
+	return m.opts.eventbus.Publish(ctx, topic, message)
+}
+
+// DrainNodes 依次排空节点，用于滚动发布，仅主节点可调用
+// 每个节点排空完成（已绑定的用户全部迁移）或超时后才会排空下一个节点；nids为空时依次排空所有处于工作或繁忙状态的节点
+// 任一节点排空失败时停止后续操作并返回错误，超时返回errors.ErrDrainTimeout
+func (m *Master) DrainNodes(ctx context.Context, timeout time.Duration, nids ...string) error {
+	if !m.IsLeader() {
+		return errors.ErrNotLeader
+	}
+
+	if m.opts.registry == nil {
+		return errors.ErrMissRegistry
+	}
+
+	services, err := m.opts.registry.Services(ctx, cluster.Node.String())
+	if err != nil {
+		return err
+	}
+
+	instances := make(map[string]*registry.ServiceInstance, len(services))
+	for _, ins := range services {
+		instances[ins.ID] = ins
+
+		if len(nids) == 0 && (ins.State == cluster.Work.String() || ins.State == cluster.Busy.String()) {
+			nids = append(nids, ins.ID)
+		}
+	}
+
+	for _, nid := range nids {
+		ins, ok := instances[nid]
+		if !ok {
+			return errors.NewError(fmt.Sprintf("node %s", nid), errors.ErrNotFoundEndpoint)
+		}
+
+		ep, err := endpoint.ParseEndpoint(ins.Endpoint)
+		if err != nil {
+			return err
+		}
+
+		client, err := m.builder.Build(ep.Address())
+		if err != nil {
+			return err
+		}
+
+		log.Infof("node draining, nid: %s", nid)
+
+		remaining, err := client.Drain(ctx, timeout, func(remaining int) {
+			log.Infof("node draining, nid: %s remaining: %d", nid, remaining)
+		})
+		if err != nil {
+			log.Errorf("node drain failed, nid: %s remaining: %d err: %v", nid, remaining, err)
+			return err
+		}
+
+		log.Infof("node drained, nid: %s", nid)
+	}
+
+	return nil
+}
+
+// 竞选主节点
+// 获取选举锁后成为主节点，并持续检测选举锁是否仍被持有；选举锁丢失（续租失败或被其他实例占用）时卸任并重新竞选
+func (m *Master) campaign() {
+	defer close(m.done)
+
+	for {
+		select {
+		case <-m.lost:
+		default:
+		}
+
+		if err := m.locker.Acquire(m.ctx); err != nil {
+			if m.ctx.Err() != nil {
+				return
+			}
+
+			log.Errorf("master elect failed: %v", err)
+
+			if !m.wait() {
+				return
+			}
+
+			continue
+		}
+
+		if m.ctx.Err() != nil {
+			m.release()
+			return
+		}
+
+		m.setLeader(true)
+
+		log.Infof("master elected, id: %s", m.opts.id)
+
+		if !m.hold() {
+			return
+		}
+
+		m.setLeader(false)
+
+		log.Warnf("master lock lost, step down, id: %s", m.opts.id)
+
+		if !m.wait() {
+			return
+		}
+	}
+}
+
+// 持有选举锁，直至选举锁丢失或组件关闭，组件关闭时返回false
+// 选举锁实现了OnLost时通过回调感知丢失；实现了TTL时按竞选间隔检测是否仍被持有
+func (m *Master) hold() bool {
+	querier, _ := m.locker.(ttlQuerier)
+
+	ticker := time.NewTicker(m.opts.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return false
+		case <-m.lost:
+			return true
+		case <-ticker.C:
+			if querier == nil {
+				continue
+			}
+
+			ctx, cancel := context.WithTimeout(m.ctx, m.opts.timeout)
+			_, err := querier.TTL(ctx)
+			cancel()
+
+			if errors.Is(err, errors.ErrLockNotHeld) {
+				return true
+			}
+		}
+	}
+}
+
+// 等待竞选间隔，组件关闭时返回false
+func (m *Master) wait() bool {
+	timer := time.NewTimer(m.opts.interval)
+	defer timer.Stop()
+
+	select {
+	case <-m.ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// 卸任主节点
+func (m *Master) resign() {
+	if !m.IsLeader() {
+		return
+	}
+
+	m.release()
+
+	m.setLeader(false)
+}
+
+// 释放选举锁
+func (m *Master) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), m.opts.timeout)
+	defer cancel()
+
+	if err := m.locker.Release(ctx); err != nil {
+		log.Errorf("master lock release failed: %v", err)
+	}
+}
+
+// 设置主节点状态
+func (m *Master) setLeader(isLeader bool) {
+	if m.leader.Swap(isLeader) == isLeader {
+		return
+	}
+
+	m.rw.RLock()
+	handlers := m.handlers[:]
+	m.rw.RUnlock()
+
+	for _, handler := range handlers {
+		handler(isLeader)
+	}
+}
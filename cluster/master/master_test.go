@@ -0,0 +1,171 @@
+package master_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster/master"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/lock"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeMaker struct {
+	mu    sync.Mutex
+	owner *fakeLocker
+}
+
+func (m *fakeMaker) Make(name string) lock.Locker {
+	return &fakeLocker{maker: m}
+}
+
+func (m *fakeMaker) Close() error {
+	return nil
+}
+
+// 模拟锁被其他持有者占用，notify为true时通知原持有者
+func (m *fakeMaker) steal(notify bool) {
+	m.mu.Lock()
+	owner := m.owner
+	m.owner = &fakeLocker{maker: m}
+	m.mu.Unlock()
+
+	if notify && owner != nil && owner.onLost != nil {
+		owner.onLost()
+	}
+}
+
+// 模拟其他持有者释放锁
+func (m *fakeMaker) free() {
+	m.mu.Lock()
+	m.owner = nil
+	m.mu.Unlock()
+}
+
+type fakeLocker struct {
+	maker  *fakeMaker
+	onLost func()
+}
+
+func (l *fakeLocker) Acquire(ctx context.Context) error {
+	for {
+		if err := l.TryAcquire(ctx); err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func (l *fakeLocker) TryAcquire(ctx context.Context, expiration ...time.Duration) error {
+	l.maker.mu.Lock()
+	defer l.maker.mu.Unlock()
+
+	if l.maker.owner != nil {
+		return errors.ErrIllegalOperation
+	}
+
+	l.maker.owner = l
+
+	return nil
+}
+
+func (l *fakeLocker) Release(ctx context.Context) error {
+	l.maker.mu.Lock()
+	defer l.maker.mu.Unlock()
+
+	if l.maker.owner != l {
+		return errors.ErrIllegalOperation
+	}
+
+	l.maker.owner = nil
+
+	return nil
+}
+
+func (l *fakeLocker) OnLost(fn func()) {
+	l.onLost = fn
+}
+
+func (l *fakeLocker) TTL(ctx context.Context) (time.Duration, error) {
+	l.maker.mu.Lock()
+	defer l.maker.mu.Unlock()
+
+	if l.maker.owner != l {
+		return 0, errors.ErrLockNotHeld
+	}
+
+	return time.Second, nil
+}
+
+func newMaster(maker lock.Maker) (*master.Master, chan bool) {
+	m := master.NewMaster(master.WithLockMaker(maker), master.WithInterval(10*time.Millisecond))
+	changes := make(chan bool, 10)
+	m.OnLeaderChange(func(isLeader bool) { changes <- isLeader })
+
+	return m, changes
+}
+
+func expectChange(t *testing.T, changes chan bool, isLeader bool) {
+	t.Helper()
+
+	select {
+	case v := <-changes:
+		if v != isLeader {
+			t.Fatalf("unexpected leader change: %v", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("leader change %v not observed", isLeader)
+	}
+}
+
+func TestMaster_Reelect(t *testing.T) {
+	maker := &fakeMaker{}
+	m, changes := newMaster(maker)
+	m.Start()
+	defer m.Close()
+
+	expectChange(t, changes, true)
+
+	// 续租失败，通过OnLost感知丢失
+	maker.steal(true)
+	expectChange(t, changes, false)
+
+	maker.free()
+	expectChange(t, changes, true)
+
+	// 未收到通知，通过TTL检测感知丢失
+	maker.steal(false)
+	expectChange(t, changes, false)
+
+	if m.IsLeader() {
+		t.Fatal("deposed master still acts as leader")
+	}
+
+	maker.free()
+	expectChange(t, changes, true)
+}
+
+func TestMaster_Takeover(t *testing.T) {
+	maker := &fakeMaker{}
+
+	m1, changes1 := newMaster(maker)
+	m1.Start()
+	expectChange(t, changes1, true)
+
+	m2, changes2 := newMaster(maker)
+	m2.Start()
+	defer m2.Close()
+
+	if m2.IsLeader() {
+		t.Fatal("standby should not be leader")
+	}
+
+	m1.Close()
+	expectChange(t, changes1, false)
+	expectChange(t, changes2, true)
+}
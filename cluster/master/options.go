@@ -0,0 +1,121 @@
+package master
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/etc"
+	"github.com/dobyte/due/v2/eventbus"
+	"github.com/dobyte/due/v2/lock"
+	"github.com/dobyte/due/v2/registry"
+	"github.com/dobyte/due/v2/utils/xuuid"
+	"time"
+)
+
+const (
+	defaultName     = "master"         // 默认名称
+	defaultLockName = "cluster:master" // 默认选举锁名称
+	defaultTimeout  = 3 * time.Second  // 默认超时时间
+	defaultInterval = time.Second      // 默认竞选间隔时间
+)
+
+const (
+	defaultIDKey       = "etc.cluster.master.id"
+	defaultNameKey     = "etc.cluster.master.name"
+	defaultLockNameKey = "etc.cluster.master.lockName"
+	defaultTimeoutKey  = "etc.cluster.master.timeout"
+	defaultIntervalKey = "etc.cluster.master.interval"
+)
+
+type Option func(o *options)
+
+type options struct {
+	ctx      context.Context   // 上下文
+	id       string            // 实例ID
+	name     string            // 实例名称
+	lockName string            // 选举锁名称
+	timeout  time.Duration     // 调用超时时间
+	interval time.Duration     // 竞选间隔时间，竞选失败时的重试间隔及持有选举锁期间的检测间隔
+	maker    lock.Maker        // 分布式锁制造商
+	eventbus eventbus.Eventbus // 事件总线
+	registry registry.Registry // 服务注册发现组件，排空节点时用于查找节点
+}
+
+func defaultOptions() *options {
+	opts := &options{
+		ctx:      context.Background(),
+		name:     defaultName,
+		lockName: defaultLockName,
+		timeout:  defaultTimeout,
+		interval: defaultInterval,
+		maker:    lock.GetMaker(),
+		eventbus: eventbus.GetEventbus(),
+	}
+
+	if id := etc.Get(defaultIDKey).String(); id != "" {
+		opts.id = id
+	} else {
+		opts.id = xuuid.UUID()
+	}
+
+	if name := etc.Get(defaultNameKey).String(); name != "" {
+		opts.name = name
+	}
+
+	if lockName := etc.Get(defaultLockNameKey).String(); lockName != "" {
+		opts.lockName = lockName
+	}
+
+	if timeout := etc.Get(defaultTimeoutKey).Duration(); timeout > 0 {
+		opts.timeout = timeout
+	}
+
+	if interval := etc.Get(defaultIntervalKey).Duration(); interval > 0 {
+		opts.interval = interval
+	}
+
+	return opts
+}
+
+// WithID 设置实例ID
+func WithID(id string) Option {
+	return func(o *options) { o.id = id }
+}
+
+// WithName 设置实例名称
+func WithName(name string) Option {
+	return func(o *options) { o.name = name }
+}
+
+// WithContext 设置上下文
+func WithContext(ctx context.Context) Option {
+	return func(o *options) { o.ctx = ctx }
+}
+
+// WithLockName 设置选举锁名称
+func WithLockName(lockName string) Option {
+	return func(o *options) { o.lockName = lockName }
+}
+
+// WithTimeout 设置调用超时时间
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.timeout = timeout }
+}
+
+// WithInterval 设置竞选间隔时间
+func WithInterval(interval time.Duration) Option {
+	return func(o *options) { o.interval = interval }
+}
+
+// WithLockMaker 设置分布式锁制造商
+func WithLockMaker(maker lock.Maker) Option {
+	return func(o *options) { o.maker = maker }
+}
+
+// WithEventbus 设置事件总线
+func WithEventbus(eb eventbus.Eventbus) Option {
+	return func(o *options) { o.eventbus = eb }
+}
+
+// WithRegistry 设置服务注册发现组件，排空节点时用于查找节点
+func WithRegistry(r registry.Registry) Option {
+	return func(o *options) { o.registry = r }
+}
@@ -40,6 +40,7 @@ type options struct {
 	registry    registry.Registry     // 服务注册器
 	encryptor   crypto.Encryptor      // 消息加密器
 	transporter transport.Transporter // 消息传输器
+	balancer    registry.Balancer     // 负载均衡器
 }
 
 func defaultOptions() *options {
@@ -120,3 +121,9 @@ func WithTransporter(transporter transport.Transporter) Option {
 func WithWeight(weight int) Option {
 	return func(o *options) { o.weight = weight }
 }
+
+// WithBalancer 设置负载均衡器，用于无状态路由选择目标节点及为未绑定的用户选择有状态路由的绑定节点，选择依据为用户ID
+// 内置registry.NewRandomBalancer、registry.NewRoundRobinBalancer、registry.NewLeastConnBalancer、registry.NewLeastLoadBalancer
+func WithBalancer(balancer registry.Balancer) Option {
+	return func(o *options) { o.balancer = balancer }
+}
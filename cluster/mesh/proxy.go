@@ -23,6 +23,7 @@ func newProxy(mesh *Mesh) *Proxy {
 		Locator:   mesh.opts.locator,
 		Registry:  mesh.opts.registry,
 		Encryptor: mesh.opts.encryptor,
+		Balancer:  mesh.opts.balancer,
 	}
 
 	return &Proxy{
@@ -150,6 +151,12 @@ func (p *Proxy) Disconnect(ctx context.Context, args *cluster.DisconnectArgs) er
 	return p.gateLinker.Disconnect(ctx, args)
 }
 
+// Kick 踢用户下线，无论用户位于哪个网关，均由其所在网关下发踢下线通知消息后断开连接
+// 返回值为true时表示已找到用户并踢下线，用户不在线或并发断开连接时返回false
+func (p *Proxy) Kick(ctx context.Context, args *cluster.KickArgs) (bool, error) {
+	return p.gateLinker.Kick(ctx, args)
+}
+
 // Push 推送消息
 func (p *Proxy) Push(ctx context.Context, args *cluster.PushArgs) error {
 	return p.gateLinker.Push(ctx, args)
@@ -175,6 +182,11 @@ func (p *Proxy) Deliver(ctx context.Context, args *cluster.DeliverArgs) error {
 	})
 }
 
+// InflightStats 获取各节点地址进行中的RPC调用数，可注册为调试组件的统计收集器
+func (p *Proxy) InflightStats() map[string]int64 {
+	return p.nodeLinker.InflightStats()
+}
+
 // 开始监听
 func (p *Proxy) watch() {
 	p.gateLinker.WatchUserLocate()
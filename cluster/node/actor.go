@@ -2,6 +2,7 @@ package node
 
 import (
 	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/session"
 	"github.com/dobyte/due/v2/utils/xcall"
 	"sync"
 	"sync/atomic"
@@ -183,12 +184,20 @@ func (a *Actor) Deliver(uid int64, message *cluster.Message) error {
 
 // Push 推送消息到本地Node队列上进行处理
 func (a *Actor) Push(uid int64, message *cluster.Message) error {
-	buf, err := a.scheduler.node.proxy.PackBuffer(message.Data)
+	node := a.scheduler.node
+	codec := node.codecs.lookup(session.User, "", uid)
+
+	msg, err := node.proxy.encodeMessage(codec, message)
+	if err != nil {
+		return err
+	}
+
+	buf, err := node.proxy.PackBuffer(msg.Data)
 	if err != nil {
 		return err
 	}
 
-	a.scheduler.node.router.deliver("", a.scheduler.node.opts.id, a.PID(), 0, uid, message.Seq, message.Route, buf)
+	node.router.deliver("", node.opts.id, a.PID(), 0, uid, message.Seq, message.Route, buf, codec)
 
 	return nil
 }
@@ -0,0 +1,94 @@
+package node
+
+import (
+	"github.com/dobyte/due/v2/encoding"
+	"github.com/dobyte/due/v2/session"
+	"sync"
+)
+
+type connKey struct {
+	gid string
+	cid int64
+}
+
+// 客户端连接协商的编解码器
+// 网关投递消息时携带连接协商的编解码器，节点据此记录连接及用户的编解码器，推送消息时使用对应的编解码器编码
+type codecs struct {
+	conns sync.Map // connKey -> encoding.Codec
+	users sync.Map // uid -> encoding.Codec
+	names sync.Map // 出现过的编解码器名称 -> encoding.Codec
+}
+
+// 记录连接协商的编解码器，codec为nil时表示使用默认的编解码器
+func (c *codecs) record(gid string, cid, uid int64, codec encoding.Codec) {
+	if codec == nil {
+		c.forget(gid, cid, uid)
+		return
+	}
+
+	if gid != "" && cid != 0 {
+		c.conns.Store(connKey{gid: gid, cid: cid}, codec)
+	}
+
+	if uid != 0 {
+		c.users.Store(uid, codec)
+	}
+
+	c.names.Store(codec.Name(), codec)
+}
+
+// 移除连接协商的编解码器
+func (c *codecs) forget(gid string, cid, uid int64) {
+	if gid != "" && cid != 0 {
+		c.conns.Delete(connKey{gid: gid, cid: cid})
+	}
+
+	if uid != 0 {
+		c.users.Delete(uid)
+	}
+}
+
+// 查找连接协商的编解码器，未协商时返回nil
+func (c *codecs) lookup(kind session.Kind, gid string, target int64) encoding.Codec {
+	var (
+		v  any
+		ok bool
+	)
+
+	switch kind {
+	case session.Conn:
+		v, ok = c.conns.Load(connKey{gid: gid, cid: target})
+	case session.User:
+		v, ok = c.users.Load(target)
+	}
+
+	if !ok {
+		return nil
+	}
+
+	return v.(encoding.Codec)
+}
+
+// 按协商的编解码器对推送目标分组，未协商的目标位于键为nil的分组
+func (c *codecs) group(kind session.Kind, gid string, targets []int64) map[encoding.Codec][]int64 {
+	groups := make(map[encoding.Codec][]int64, 1)
+
+	for _, target := range targets {
+		codec := c.lookup(kind, gid, target)
+		groups[codec] = append(groups[codec], target)
+	}
+
+	return groups
+}
+
+// 获取出现过的全部编解码器
+func (c *codecs) all() []encoding.Codec {
+	var list []encoding.Codec
+
+	c.names.Range(func(_, value any) bool {
+		list = append(list, value.(encoding.Codec))
+		return true
+	})
+
+	return list
+}
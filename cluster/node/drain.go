@@ -0,0 +1,124 @@
+package node
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/log"
+	"github.com/dobyte/due/v2/utils/xcall"
+	"time"
+)
+
+const drainCheckInterval = 100 * time.Millisecond
+
+// RebindHandler 重新绑定处理器，排空节点时对每个绑定到当前节点的用户调用
+// 处理器应在保存用户状态后解绑当前节点，并通知客户端重新进入以绑定到其他节点
+type RebindHandler func(proxy *Proxy, uid int64)
+
+// 排空节点，将节点状态变更为cluster.Drain后拒绝新的绑定，并向已绑定的用户发出重新绑定信号
+// 已绑定的用户包括通过当前节点绑定的用户、经定位器监听到绑定到当前节点的用户，以及经定位器路由到当前节点的有状态消息的用户
+// 阻塞至所有用户解绑当前节点或超时，返回未完成迁移的用户数；progress不为nil时在剩余用户数变化时调用
+func (n *Node) drain(timeout time.Duration, progress func(remaining int)) (int, error) {
+	if !n.state.CompareAndSwap(int32(cluster.Work), int32(cluster.Drain)) {
+		if !n.state.CompareAndSwap(int32(cluster.Busy), int32(cluster.Drain)) {
+			if n.getState() != cluster.Drain {
+				return n.countSessions(), errors.ErrIllegalOperation
+			}
+		}
+	}
+
+	n.refreshServiceInstances()
+
+	uids := make([]int64, 0)
+	n.sessions.Range(func(uid, _ any) bool {
+		uids = append(uids, uid.(int64))
+		return true
+	})
+
+	log.Infof("node draining, id: %s users: %d timeout: %v", n.opts.id, len(uids), timeout)
+
+	for _, uid := range uids {
+		n.rebind(uid)
+	}
+
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+
+	last := len(uids)
+
+	for {
+		remaining := n.countSessions()
+		if remaining == 0 {
+			log.Infof("node drained, id: %s", n.opts.id)
+			return 0, nil
+		}
+
+		if progress != nil && remaining != last {
+			last = remaining
+			progress(remaining)
+		}
+
+		select {
+		case <-n.ctx.Done():
+			return remaining, n.ctx.Err()
+		case <-deadline:
+			log.Warnf("node drain timeout, id: %s remaining: %d", n.opts.id, remaining)
+			return remaining, errors.ErrDrainTimeout
+		case <-ticker.C:
+		}
+	}
+}
+
+// 向用户发出重新绑定信号，未设置重新绑定处理器时直接解绑当前节点
+func (n *Node) rebind(uid int64) {
+	if n.opts.rebindHandler != nil {
+		xcall.Call(func() {
+			n.opts.rebindHandler(n.proxy, uid)
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(n.ctx, defaultTimeout)
+	defer cancel()
+
+	if err := n.proxy.UnbindNode(ctx, uid); err != nil {
+		log.Warnf("unbind draining node failed, uid: %d err: %v", uid, err)
+	}
+}
+
+// 记录绑定到当前节点的用户，wait为true时节点关闭前等待用户解绑
+// 同一用户仅计入一次等待，避免重复绑定或经定位器同步的绑定事件导致计数失衡
+func (n *Node) storeSession(uid int64, wait bool) {
+	if v, loaded := n.sessions.LoadOrStore(uid, wait); !loaded {
+		if wait {
+			n.addWait()
+		}
+	} else if wait && !v.(bool) && n.sessions.CompareAndSwap(uid, false, true) {
+		n.addWait()
+	}
+}
+
+// 移除绑定到当前节点的用户
+func (n *Node) deleteSession(uid int64) {
+	if v, loaded := n.sessions.LoadAndDelete(uid); loaded && v.(bool) {
+		n.doneWait()
+	}
+}
+
+// 统计绑定到当前节点的用户数
+func (n *Node) countSessions() int {
+	count := 0
+	n.sessions.Range(func(_, _ any) bool {
+		count++
+		return true
+	})
+
+	return count
+}
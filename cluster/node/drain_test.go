@@ -0,0 +1,57 @@
+package node_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster/node"
+	"github.com/dobyte/due/v2/errors"
+	"testing"
+	"time"
+)
+
+func TestNode_Drain(t *testing.T) {
+	c := newTestCluster(t)
+
+	handled := make(chan int64, 1)
+	n := c.startNode(t, nil, node.WithRebindHandler(func(proxy *node.Proxy, uid int64) {
+		handled <- uid
+		_ = proxy.UnbindNode(context.Background(), uid)
+	}))
+
+	// 模拟其他节点将用户绑定到当前节点
+	if err := c.locator.BindNode(context.Background(), 1, "test", "node-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if err := n.Proxy().Drain(3 * time.Second); err != nil {
+		t.Fatalf("drain failed: %v", err)
+	}
+
+	select {
+	case uid := <-handled:
+		if uid != 1 {
+			t.Fatalf("unexpected rebind uid: %d", uid)
+		}
+	default:
+		t.Fatal("rebind handler not called")
+	}
+}
+
+func TestNode_DrainTimeout(t *testing.T) {
+	c := newTestCluster(t)
+
+	n := c.startNode(t, nil, node.WithRebindHandler(func(proxy *node.Proxy, uid int64) {}))
+
+	if err := c.locator.BindNode(context.Background(), 1, "test", "node-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if err := n.Proxy().Drain(300 * time.Millisecond); err != errors.ErrDrainTimeout {
+		t.Fatalf("expected drain timeout, got: %v", err)
+	}
+
+	_ = c.locator.UnbindNode(context.Background(), 1, "test", "node-1")
+}
@@ -0,0 +1,245 @@
+package node_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/cluster/node"
+	"github.com/dobyte/due/v2/core/endpoint"
+	"github.com/dobyte/due/v2/encoding/json"
+	"github.com/dobyte/due/v2/internal/transporter/gate"
+	tnode "github.com/dobyte/due/v2/internal/transporter/node"
+	lmock "github.com/dobyte/due/v2/locate/mock"
+	"github.com/dobyte/due/v2/packet"
+	"github.com/dobyte/due/v2/registry"
+	"github.com/dobyte/due/v2/registry/mock"
+	"github.com/dobyte/due/v2/session"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 记录注册次数的内存服务注册中心
+type countingRegistry struct {
+	*mock.Registry
+	rw        sync.RWMutex
+	registers map[string]int // 服务名 -> 注册次数
+}
+
+func newCountingRegistry() *countingRegistry {
+	return &countingRegistry{Registry: mock.NewRegistry(), registers: make(map[string]int)}
+}
+
+func (r *countingRegistry) Register(ctx context.Context, ins *registry.ServiceInstance) error {
+	r.rw.Lock()
+	r.registers[ins.Name]++
+	r.rw.Unlock()
+
+	return r.Registry.Register(ctx, ins)
+}
+
+// 获取服务的注册次数
+func (r *countingRegistry) registerCount(serviceName string) int {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+
+	return r.registers[serviceName]
+}
+
+// 推送到网关的消息
+type pushed struct {
+	kind    session.Kind
+	target  int64
+	message *packet.Message
+}
+
+// 模拟网关，记录推送及断开连接的请求
+type mockGate struct {
+	id          string
+	server      *gate.Server
+	pushes      chan *pushed
+	disconnects chan int64
+}
+
+func newMockGate(t *testing.T, reg registry.Registry) *mockGate {
+	g := &mockGate{id: "gate-1", pushes: make(chan *pushed, 64), disconnects: make(chan int64, 64)}
+
+	server, err := gate.NewServer(&gate.ServerOptions{Addr: "127.0.0.1:0"}, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g.server = server
+
+	go server.Start()
+
+	t.Cleanup(func() { _ = server.Stop() })
+
+	if err = reg.Register(context.Background(), &registry.ServiceInstance{
+		ID:       g.id,
+		Name:     cluster.Gate.String(),
+		Kind:     cluster.Gate.String(),
+		State:    cluster.Work.String(),
+		Endpoint: server.Endpoint().String(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	return g
+}
+
+// 等待推送消息
+func (g *mockGate) expectPush(t *testing.T) *pushed {
+	t.Helper()
+
+	select {
+	case p := <-g.pushes:
+		return p
+	case <-time.After(3 * time.Second):
+		t.Fatal("push not received")
+		return nil
+	}
+}
+
+// 确认未收到推送消息
+func (g *mockGate) expectNoPush(t *testing.T) {
+	t.Helper()
+
+	select {
+	case p := <-g.pushes:
+		t.Fatalf("unexpected push: %+v", p.message)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func (g *mockGate) Bind(ctx context.Context, cid, uid int64) error {
+	return nil
+}
+
+func (g *mockGate) Unbind(ctx context.Context, uid int64) error {
+	return nil
+}
+
+func (g *mockGate) GetIP(ctx context.Context, kind session.Kind, target int64) (string, error) {
+	return "127.0.0.1", nil
+}
+
+func (g *mockGate) IsOnline(ctx context.Context, kind session.Kind, target int64) (bool, error) {
+	return true, nil
+}
+
+func (g *mockGate) Stat(ctx context.Context, kind session.Kind) (int64, error) {
+	return 0, nil
+}
+
+func (g *mockGate) Disconnect(ctx context.Context, kind session.Kind, target int64, force bool) error {
+	g.disconnects <- target
+	return nil
+}
+
+func (g *mockGate) Push(ctx context.Context, kind session.Kind, target int64, message []byte) error {
+	msg, err := packet.UnpackMessage(message)
+	if err != nil {
+		return err
+	}
+
+	g.pushes <- &pushed{kind: kind, target: target, message: msg}
+
+	return nil
+}
+
+func (g *mockGate) Multicast(ctx context.Context, kind session.Kind, targets []int64, message []byte) (int64, error) {
+	return 0, nil
+}
+
+func (g *mockGate) Broadcast(ctx context.Context, kind session.Kind, message []byte) (int64, error) {
+	return 0, nil
+}
+
+func (g *mockGate) Kick(ctx context.Context, uid int64, messages map[string][]byte) error {
+	return nil
+}
+
+func (g *mockGate) GetState() (cluster.State, error) {
+	return cluster.Work, nil
+}
+
+func (g *mockGate) SetState(state cluster.State) error {
+	return nil
+}
+
+// 测试集群，包含内存注册中心、内存定位器及模拟网关
+type testCluster struct {
+	registry *countingRegistry
+	locator  *lmock.Locator
+	gate     *mockGate
+}
+
+func newTestCluster(t *testing.T) *testCluster {
+	reg := newCountingRegistry()
+
+	return &testCluster{registry: reg, locator: lmock.NewLocator(), gate: newMockGate(t, reg)}
+}
+
+// 启动节点，setup用于在启动前注册路由
+func (c *testCluster) startNode(t *testing.T, setup func(n *node.Node), opts ...node.Option) *node.Node {
+	opts = append([]node.Option{
+		node.WithID("node-1"),
+		node.WithName("test"),
+		node.WithAddr("127.0.0.1:0"),
+		node.WithCodec(json.DefaultCodec),
+		node.WithRegistry(c.registry),
+		node.WithLocator(c.locator),
+	}, opts...)
+
+	n := node.NewNode(opts...)
+
+	if setup != nil {
+		setup(n)
+	}
+
+	n.Init()
+	n.Start()
+
+	t.Cleanup(func() {
+		n.Close()
+		n.Destroy()
+	})
+
+	return n
+}
+
+// 模拟网关向节点投递客户端消息
+func (c *testCluster) deliver(t *testing.T, cid, uid int64, message *packet.Message) {
+	t.Helper()
+
+	c.deliverCodec(t, cid, uid, "", message)
+}
+
+// 模拟网关向节点投递已协商编解码器的客户端消息
+func (c *testCluster) deliverCodec(t *testing.T, cid, uid int64, codec string, message *packet.Message) {
+	t.Helper()
+
+	services, err := c.registry.Services(context.Background(), cluster.Node.String())
+	if err != nil || len(services) == 0 {
+		t.Fatalf("node not registered: %v", err)
+	}
+
+	ep, err := endpoint.ParseEndpoint(services[0].Endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := tnode.NewBuilder(&tnode.Options{InsID: c.gate.id, InsKind: cluster.Gate}).Build(ep.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := packet.PackMessage(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = client.Deliver(context.Background(), cid, uid, codec, data); err != nil {
+		t.Fatal(err)
+	}
+}
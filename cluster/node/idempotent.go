@@ -0,0 +1,239 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/dobyte/due/v2/cache"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/encoding"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/log"
+	"time"
+)
+
+const (
+	defaultIdempotentTTL     = 30 * time.Second         // 默认幂等响应缓存时间
+	defaultIdempotentTimeout = 3 * time.Second          // 默认缓存读写超时时间
+	defaultIdempotentWait    = 3 * time.Second          // 默认重复请求等待处理中请求响应的时间
+	idempotentPollInterval   = 50 * time.Millisecond    // 重复请求轮询响应的间隔
+	idempotentKey            = "idempotent:%d:%d:%d:%s" // 幂等响应缓存key（用户ID:路由:序列号:编解码器）
+	idempotentPending        = "pending"                // 处理中占位值
+)
+
+// 已缓存响应的前缀，用于区分处理中占位值
+var idempotentReplyPrefix = []byte("reply:")
+
+type idempotentOptions struct {
+	ttl     time.Duration // 响应缓存时间
+	timeout time.Duration // 缓存读写超时时间
+	wait    time.Duration // 重复请求等待处理中请求响应的时间
+}
+
+type IdempotentOption func(o *idempotentOptions)
+
+// WithIdempotentTTL 设置幂等响应缓存时间，默认30s
+func WithIdempotentTTL(ttl time.Duration) IdempotentOption {
+	return func(o *idempotentOptions) { o.ttl = ttl }
+}
+
+// WithIdempotentTimeout 设置缓存读写超时时间，默认3s
+func WithIdempotentTimeout(timeout time.Duration) IdempotentOption {
+	return func(o *idempotentOptions) { o.timeout = timeout }
+}
+
+// WithIdempotentWait 设置重复请求等待处理中请求响应的时间，超时后丢弃重复请求，默认3s
+func WithIdempotentWait(wait time.Duration) IdempotentOption {
+	return func(o *idempotentOptions) { o.wait = wait }
+}
+
+// Idempotent 幂等中间件，以用户ID、路由及客户端消息序列号作为请求ID
+// 首次请求先以SetNX抢占请求ID并写入处理中占位值，抢占成功后才会执行路由处理器，响应按客户端协商的编解码器编码后缓存
+// 重复请求将直接回复首次处理时缓存的响应；首次请求仍在处理中时，重复请求异步等待其响应，等待超时则丢弃
+// 仅对已绑定用户且序列号非0的消息生效，客户端需保证同一用户的消息序列号在重连后不会被重复使用
+// 缓存时间越长，可覆盖的重试窗口越大，但占用的存储也越多；过短的缓存时间则可能导致延迟到达的重试请求被重复处理，默认缓存30s
+// 缓存实现SetNX时使用SetNX抢占，否则以IncrInt抢占后再设置过期时间
+func Idempotent(c cache.Cache, opts ...IdempotentOption) MiddlewareHandler {
+	o := &idempotentOptions{ttl: defaultIdempotentTTL, timeout: defaultIdempotentTimeout, wait: defaultIdempotentWait}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(middleware *Middleware, ctx Context) {
+		if ctx.UID() == 0 || ctx.Seq() == 0 {
+			middleware.Next(ctx)
+			return
+		}
+
+		codec := contextCodec(ctx)
+		key := fmt.Sprintf(idempotentKey, ctx.UID(), ctx.Route(), ctx.Seq(), codec.Name())
+		next := &idempotentContext{requestContext: ctx, cache: c, key: key, codec: codec, opts: o}
+
+		data, ok, err := o.load(c, key)
+		if err != nil {
+			log.Warnf("idempotent response load failed: %v", err)
+			middleware.Next(next)
+			return
+		}
+
+		if ok {
+			if data != nil {
+				replayIdempotent(ctx, data)
+				return
+			}
+		} else if claimed, err := o.claim(c, key); err != nil {
+			log.Warnf("idempotent request claim failed: %v", err)
+			middleware.Next(next)
+			return
+		} else if claimed {
+			middleware.Next(next)
+			return
+		}
+
+		// 首次请求仍在处理中，异步等待其响应
+		ctx.Task(func(ctx Context) {
+			if data, ok := o.await(c, key); ok {
+				replayIdempotent(ctx, data)
+			} else {
+				log.Warnf("idempotent request in flight, duplicate dropped, uid: %d route: %d seq: %d", ctx.UID(), ctx.Route(), ctx.Seq())
+			}
+		})
+	}
+}
+
+// 加载缓存的响应，ok为true且data为nil时表示请求处理中
+func (o *idempotentOptions) load(c cache.Cache, key string) (data []byte, ok bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+	defer cancel()
+
+	val, err := c.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, errors.ErrNil) {
+			err = nil
+		}
+		return
+	}
+
+	if bytes.HasPrefix(val, idempotentReplyPrefix) {
+		return val[len(idempotentReplyPrefix):], true, nil
+	}
+
+	return nil, true, nil
+}
+
+// 抢占请求ID
+func (o *idempotentOptions) claim(c cache.Cache, key string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+	defer cancel()
+
+	if s, ok := c.(interface {
+		SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+	}); ok {
+		return s.SetNX(ctx, key, idempotentPending, o.ttl)
+	}
+
+	n, err := c.IncrInt(ctx, key, 1)
+	if err != nil || n != 1 {
+		return false, err
+	}
+
+	if err = c.Set(ctx, key, idempotentPending, o.ttl); err != nil {
+		log.Warnf("idempotent claim expire failed, key: %s err: %v", key, err)
+	}
+
+	return true, nil
+}
+
+// 等待处理中请求的响应
+func (o *idempotentOptions) await(c cache.Cache, key string) ([]byte, bool) {
+	deadline := time.Now().Add(o.wait)
+
+	for {
+		if data, ok, err := o.load(c, key); err != nil {
+			log.Warnf("idempotent response load failed: %v", err)
+		} else if ok && data != nil {
+			return data, true
+		}
+
+		if time.Now().Add(idempotentPollInterval).After(deadline) {
+			return nil, false
+		}
+
+		time.Sleep(idempotentPollInterval)
+	}
+}
+
+// 回复缓存的响应
+func replayIdempotent(ctx Context, data []byte) {
+	if err := ctx.Reply(&cluster.Message{Route: ctx.Route(), Seq: ctx.Seq(), Data: data}); err != nil {
+		log.Errorf("idempotent response reply failed: %v", err)
+	}
+}
+
+// 获取请求回复时使用的编解码器
+func contextCodec(ctx Context) encoding.Codec {
+	for {
+		switch c := ctx.(type) {
+		case *request:
+			return c.getCodec()
+		case interface{ unwrap() Context }:
+			ctx = c.unwrap()
+		default:
+			return ctx.Proxy().node.opts.codec
+		}
+	}
+}
+
+type requestContext = Context
+
+type idempotentContext struct {
+	requestContext
+	cache cache.Cache        // 缓存
+	key   string             // 缓存key
+	codec encoding.Codec     // 响应编解码器
+	opts  *idempotentOptions // 配置项
+}
+
+// Reply 回复消息，并缓存按请求编解码器编码后的响应
+func (c *idempotentContext) Reply(message *cluster.Message) error {
+	if message != nil && message.Route == c.Route() && message.Seq == c.Seq() {
+		if data, err := c.Proxy().gateLinker.PackBufferWithCodec(c.codec, message.Data, true); err != nil {
+			log.Warnf("idempotent response pack failed: %v", err)
+		} else {
+			ctx, cancel := context.WithTimeout(context.Background(), c.opts.timeout)
+			if err = c.cache.Set(ctx, c.key, append(append([]byte(nil), idempotentReplyPrefix...), data...), c.opts.ttl); err != nil {
+				log.Warnf("idempotent response store failed: %v", err)
+			}
+			cancel()
+
+			message = &cluster.Message{Route: message.Route, Seq: message.Seq, Data: data}
+		}
+	}
+
+	return c.requestContext.Reply(message)
+}
+
+// Response 响应消息，并缓存响应
+func (c *idempotentContext) Response(message interface{}) error {
+	return c.Reply(&cluster.Message{Route: c.Route(), Seq: c.Seq(), Data: message})
+}
+
+// Clone 克隆Context
+func (c *idempotentContext) Clone() Context {
+	return c.wrap(c.requestContext.Clone())
+}
+
+// Task 投递任务，任务中的回复同样会被缓存
+func (c *idempotentContext) Task(fn func(ctx Context)) {
+	c.requestContext.Task(func(ctx Context) { fn(c.wrap(ctx)) })
+}
+
+// 包装Context
+func (c *idempotentContext) wrap(ctx Context) Context {
+	return &idempotentContext{requestContext: ctx, cache: c.cache, key: c.key, codec: c.codec, opts: c.opts}
+}
+
+// 获取被包装的Context
+func (c *idempotentContext) unwrap() Context {
+	return c.requestContext
+}
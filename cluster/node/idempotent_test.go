@@ -0,0 +1,182 @@
+package node_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cache"
+	"github.com/dobyte/due/v2/cluster/node"
+	"github.com/dobyte/due/v2/encoding/msgpack"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/packet"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 内存缓存，仅实现幂等中间件使用的读写及抢占方法
+type memCache struct {
+	cache.Cache
+	rw    sync.RWMutex
+	items map[string]interface{}
+}
+
+func newMemCache() *memCache {
+	return &memCache{items: make(map[string]interface{})}
+}
+
+func (c *memCache) Get(ctx context.Context, key string, def ...interface{}) cache.Result {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+
+	if val, ok := c.items[key]; ok {
+		return cache.NewResult(val)
+	}
+
+	return cache.NewResult(nil, errors.ErrNil)
+}
+
+func (c *memCache) Set(ctx context.Context, key string, value interface{}, expiration ...time.Duration) error {
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	c.items[key] = value
+
+	return nil
+}
+
+func (c *memCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	if _, ok := c.items[key]; ok {
+		return false, nil
+	}
+
+	c.items[key] = value
+
+	return true, nil
+}
+
+func TestIdempotent(t *testing.T) {
+	var (
+		c     = newTestCluster(t)
+		calls atomic.Int32
+	)
+
+	c.startNode(t, func(n *node.Node) {
+		n.Proxy().Router().AddRouteHandler(1, false, func(ctx node.Context) {
+			_ = ctx.Response(calls.Add(1))
+		}, node.Idempotent(newMemCache()))
+	})
+
+	// 重复请求回复首次处理时缓存的响应
+	t.Run("duplicate", func(t *testing.T) {
+		c.deliver(t, 1, 10, &packet.Message{Seq: 1, Route: 1})
+		first := c.gate.expectPush(t)
+
+		c.deliver(t, 1, 10, &packet.Message{Seq: 1, Route: 1})
+		second := c.gate.expectPush(t)
+
+		if string(first.message.Buffer) != "1" || string(second.message.Buffer) != "1" || second.message.Seq != 1 {
+			t.Fatalf("unexpected replies: %s %s", first.message.Buffer, second.message.Buffer)
+		}
+
+		if calls.Load() != 1 {
+			t.Fatalf("unexpected calls: %d", calls.Load())
+		}
+	})
+
+	t.Run("another seq", func(t *testing.T) {
+		c.deliver(t, 1, 10, &packet.Message{Seq: 2, Route: 1})
+
+		if p := c.gate.expectPush(t); string(p.message.Buffer) != "2" {
+			t.Fatalf("unexpected reply: %s", p.message.Buffer)
+		}
+	})
+
+	t.Run("another user", func(t *testing.T) {
+		c.deliver(t, 2, 11, &packet.Message{Seq: 1, Route: 1})
+
+		if p := c.gate.expectPush(t); string(p.message.Buffer) != "3" {
+			t.Fatalf("unexpected reply: %s", p.message.Buffer)
+		}
+	})
+
+	// 未绑定用户或序列号为0的消息不做幂等处理
+	t.Run("unbound", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			c.deliver(t, 3, 0, &packet.Message{Seq: 1, Route: 1})
+			c.gate.expectPush(t)
+		}
+
+		if calls.Load() != 5 {
+			t.Fatalf("unexpected calls: %d", calls.Load())
+		}
+	})
+}
+
+func TestIdempotent_InFlight(t *testing.T) {
+	var (
+		c       = newTestCluster(t)
+		calls   atomic.Int32
+		release = make(chan struct{})
+	)
+
+	c.startNode(t, func(n *node.Node) {
+		n.Proxy().Router().AddRouteHandler(1, false, func(ctx node.Context) {
+			calls.Add(1)
+
+			ctx.Task(func(ctx node.Context) {
+				<-release
+				_ = ctx.Response("done")
+			})
+		}, node.Idempotent(newMemCache(), node.WithIdempotentWait(3*time.Second)))
+	})
+
+	// 首次请求处理中时，重复请求等待其响应而不会再次执行路由处理器
+	c.deliver(t, 1, 10, &packet.Message{Seq: 1, Route: 1})
+	c.deliver(t, 1, 10, &packet.Message{Seq: 1, Route: 1})
+
+	c.gate.expectNoPush(t)
+	close(release)
+
+	for i := 0; i < 2; i++ {
+		if p := c.gate.expectPush(t); string(p.message.Buffer) != `"done"` {
+			t.Fatalf("unexpected reply: %s", p.message.Buffer)
+		}
+	}
+
+	if calls.Load() != 1 {
+		t.Fatalf("unexpected calls: %d", calls.Load())
+	}
+}
+
+func TestIdempotent_Codec(t *testing.T) {
+	c := newTestCluster(t)
+
+	c.startNode(t, func(n *node.Node) {
+		n.Proxy().Router().AddRouteHandler(1, false, func(ctx node.Context) {
+			_ = ctx.Response(map[string]string{"name": "due"})
+		}, node.Idempotent(newMemCache()))
+	})
+
+	expected, err := msgpack.Marshal(map[string]string{"name": "due"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 重复请求回复以客户端协商的编解码器编码的缓存响应
+	for i := 0; i < 2; i++ {
+		c.deliverCodec(t, 1, 10, msgpack.Name, &packet.Message{Seq: 1, Route: 1})
+
+		if p := c.gate.expectPush(t); string(p.message.Buffer) != string(expected) {
+			t.Fatalf("unexpected reply: %q", p.message.Buffer)
+		}
+	}
+
+	c.deliver(t, 1, 10, &packet.Message{Seq: 1, Route: 1})
+
+	if p := c.gate.expectPush(t); string(p.message.Buffer) != `{"name":"due"}` {
+		t.Fatalf("unexpected reply: %q", p.message.Buffer)
+	}
+}
@@ -0,0 +1,77 @@
+package node
+
+import (
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/log"
+	"github.com/dobyte/due/v2/registry"
+	"time"
+)
+
+// LoadSource 负载来源，返回节点当前的负载
+type LoadSource func() registry.Load
+
+// 定期上报负载至注册中心，负载未发生变化时不进行上报
+func (n *Node) reportLoad() {
+	if n.opts.loadInterval <= 0 {
+		return
+	}
+
+	source := n.opts.loadSource
+	if source == nil {
+		source = n.defaultLoad
+	}
+
+	ticker := time.NewTicker(n.opts.loadInterval)
+	defer ticker.Stop()
+
+	var last registry.Load
+
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-ticker.C:
+			if state := n.getState(); state != cluster.Work && state != cluster.Busy {
+				continue
+			}
+
+			load := source()
+			if load == last {
+				continue
+			}
+			last = load
+
+			// 负载仅用于节点的负载均衡，只上报节点服务实例
+			instances := n.updateServiceInstances(func(ins *registry.ServiceInstance) bool {
+				if ins.Kind != cluster.Node.String() {
+					return false
+				}
+
+				l := load
+				ins.Load = &l
+
+				return true
+			})
+
+			if err := n.doRegisterServiceInstances(instances); err != nil {
+				log.Errorf("report cluster instances load failed: %v", err)
+			}
+		}
+	}
+}
+
+// 默认负载来源，活跃数为Actor数，负载评分为请求队列的积压比例
+func (n *Node) defaultLoad() registry.Load {
+	load := registry.Load{}
+
+	n.scheduler.actors.Range(func(_, _ any) bool {
+		load.Active++
+		return true
+	})
+
+	if c := cap(n.router.reqChan); c > 0 {
+		load.Score = float64(len(n.router.reqChan)) / float64(c)
+	}
+
+	return load
+}
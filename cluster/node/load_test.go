@@ -0,0 +1,117 @@
+package node_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/cluster/node"
+	"github.com/dobyte/due/v2/core/endpoint"
+	"github.com/dobyte/due/v2/registry"
+	"github.com/dobyte/due/v2/transport"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeTransporter struct{}
+
+func (t *fakeTransporter) Name() string { return "fake" }
+
+func (t *fakeTransporter) NewServer() (transport.Server, error) { return &fakeTransportServer{}, nil }
+
+func (t *fakeTransporter) NewClient(target string) (transport.Client, error) { return nil, nil }
+
+func (t *fakeTransporter) SetDefaultDiscovery(discovery registry.Discovery) {}
+
+type fakeTransportServer struct{}
+
+func (s *fakeTransportServer) Start() error { return nil }
+
+func (s *fakeTransportServer) Stop() error { return nil }
+
+func (s *fakeTransportServer) Addr() string { return "127.0.0.1:1" }
+
+func (s *fakeTransportServer) Scheme() string { return "fake" }
+
+func (s *fakeTransportServer) Endpoint() *endpoint.Endpoint {
+	return endpoint.NewEndpoint("fake", "127.0.0.1:1", false)
+}
+
+func (s *fakeTransportServer) RegisterService(desc, service interface{}) error { return nil }
+
+func TestNode_ReportLoad(t *testing.T) {
+	var (
+		c      = newTestCluster(t)
+		active atomic.Int64
+	)
+
+	n := c.startNode(t, func(n *node.Node) {
+		n.Proxy().AddServiceProvider("greeter", nil, nil)
+	},
+		node.WithTransporter(&fakeTransporter{}),
+		node.WithLoadInterval(10*time.Millisecond),
+		node.WithLoadSource(func() registry.Load {
+			return registry.Load{Active: active.Load()}
+		}),
+	)
+
+	// 上报负载期间并发读取服务实例
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				_ = n.Ready(context.Background())
+				_, _ = n.Routes(context.Background())
+			}
+		}
+	}()
+
+	waitLoad := func(want int64) {
+		t.Helper()
+
+		deadline := time.Now().Add(3 * time.Second)
+		for {
+			services, _ := c.registry.Services(context.Background(), cluster.Node.String())
+			if len(services) == 1 && services[0].Load != nil && services[0].Load.Active == want {
+				return
+			}
+
+			if time.Now().After(deadline) {
+				t.Fatalf("load %d not reported", want)
+			}
+
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	active.Store(1)
+	waitLoad(1)
+
+	meshes := c.registry.registerCount(cluster.Mesh.String())
+
+	active.Store(2)
+	waitLoad(2)
+
+	// 负载变化仅重新注册节点服务实例
+	if n := c.registry.registerCount(cluster.Mesh.String()); n != meshes {
+		t.Fatalf("mesh instance registered %d times, want %d", n, meshes)
+	}
+
+	services, _ := c.registry.Services(context.Background(), cluster.Mesh.String())
+	if len(services) != 1 || services[0].Load != nil {
+		t.Fatalf("unexpected mesh instances: %+v", services)
+	}
+
+	// 负载未变化时不重新上报
+	nodes := c.registry.registerCount(cluster.Node.String())
+
+	time.Sleep(50 * time.Millisecond)
+
+	if n := c.registry.registerCount(cluster.Node.String()); n != nodes {
+		t.Fatalf("unchanged load reported, registers = %d want %d", n, nodes)
+	}
+}
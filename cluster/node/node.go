@@ -6,6 +6,7 @@ import (
 	"github.com/dobyte/due/v2/cluster"
 	"github.com/dobyte/due/v2/component"
 	"github.com/dobyte/due/v2/core/info"
+	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/internal/transporter/node"
 	"github.com/dobyte/due/v2/log"
 	"github.com/dobyte/due/v2/registry"
@@ -30,12 +31,14 @@ type Node struct {
 	ctx         context.Context
 	cancel      context.CancelFunc
 	state       atomic.Int32
+	reason      atomic.Uint32 // 关闭原因
 	evtPool     *sync.Pool
 	reqPool     *sync.Pool
 	router      *Router
 	trigger     *Trigger
 	proxy       *Proxy
 	services    []*serviceEntity
+	insRW       sync.RWMutex // 服务实例读写锁，服务实例发布后不再修改，更新时替换为新的副本
 	instances   []*registry.ServiceInstance
 	linker      *node.Server
 	fnChan      chan func()
@@ -44,6 +47,8 @@ type Node struct {
 	wg          *sync.WaitGroup
 	rw          sync.RWMutex
 	hooks       map[cluster.Hook][]HookHandler
+	sessions    sync.Map // 绑定到当前节点的用户（含经定位器绑定或路由到当前节点的用户） -> 节点关闭前是否等待其解绑
+	codecs      codecs   // 客户端连接协商的编解码器
 }
 
 func NewNode(opts ...Option) *Node {
@@ -122,6 +127,10 @@ func (n *Node) Start() {
 		return
 	}
 
+	if err := n.router.validate(); err != nil {
+		log.Fatalf("route validate failed: %v", err)
+	}
+
 	n.startLinkServer()
 
 	n.startTransportServer()
@@ -132,6 +141,8 @@ func (n *Node) Start() {
 
 	go n.dispatch()
 
+	go n.reportLoad()
+
 	n.printInfo()
 
 	n.runHookFunc(cluster.Start)
@@ -141,7 +152,9 @@ func (n *Node) Start() {
 func (n *Node) Close() {
 	if !n.state.CompareAndSwap(int32(cluster.Work), int32(cluster.Hang)) {
 		if !n.state.CompareAndSwap(int32(cluster.Busy), int32(cluster.Hang)) {
-			return
+			if !n.state.CompareAndSwap(int32(cluster.Drain), int32(cluster.Hang)) {
+				return
+			}
 		}
 	}
 
@@ -160,9 +173,11 @@ func (n *Node) Destroy() {
 
 	n.runHookFunc(cluster.Destroy)
 
-	n.deregisterServiceInstances()
+	reason := n.closeReason()
+
+	n.deregisterServiceInstances(reason)
 
-	n.stopLinkServer()
+	n.stopLinkServer(reason)
 
 	n.stopTransportServer()
 
@@ -180,6 +195,102 @@ func (n *Node) Proxy() *Proxy {
 	return n.proxy
 }
 
+// Ready 检测节点是否就绪，可作为健康检测组件的就绪检测器
+func (n *Node) Ready(ctx context.Context) error {
+	switch n.getState() {
+	case cluster.Work, cluster.Busy:
+		if len(n.serviceInstances()) == 0 {
+			return errors.ErrNotReady
+		}
+
+		return nil
+	default:
+		return errors.ErrNotReady
+	}
+}
+
+// CloseReasonHandler 关闭通知处理器，addr为下发关闭通知的网关或节点的传输层地址
+type CloseReasonHandler func(addr string, reason cluster.CloseReason)
+
+// SetCloseReason 设置关闭原因，节点销毁时随关闭通知下发给已连接的客户端，未设置时为cluster.CloseShutdown
+func (n *Node) SetCloseReason(reason cluster.CloseReason) {
+	n.reason.Store(uint32(reason))
+}
+
+// 获取关闭原因
+func (n *Node) closeReason() cluster.CloseReason {
+	if reason := cluster.CloseReason(n.reason.Load()); reason != 0 {
+		return reason
+	}
+
+	return cluster.CloseShutdown
+}
+
+// Routes 获取全部路由的注册信息，可用于管理后台展示节点声明及已注册的路由
+func (n *Node) Routes(ctx context.Context) ([]RouteInfo, error) {
+	return n.router.Routes(ctx)
+}
+
+// UnknownRouteStat 获取未知路由统计，可注册为调试组件的统计收集器
+func (n *Node) UnknownRouteStat() UnknownRouteStat {
+	return n.router.UnknownRouteStat()
+}
+
+// 查询服务注册中心中当前节点实例的路由，未设置注册器或节点实例未注册时返回空
+func (n *Node) registeredRoutes(ctx context.Context) (map[int32]registry.Route, error) {
+	routes := make(map[int32]registry.Route)
+
+	if n.opts.registry == nil {
+		return routes, nil
+	}
+
+	services, err := n.opts.registry.Services(ctx, cluster.Node.String())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ins := range services {
+		if ins.ID != n.opts.id {
+			continue
+		}
+
+		for _, route := range ins.Routes {
+			routes[route.ID] = route
+		}
+
+		break
+	}
+
+	return routes, nil
+}
+
+// 获取服务实例快照
+func (n *Node) serviceInstances() []*registry.ServiceInstance {
+	n.insRW.RLock()
+	defer n.insRW.RUnlock()
+
+	return append([]*registry.ServiceInstance(nil), n.instances...)
+}
+
+// 更新服务实例，fn作用于服务实例的副本，返回更新后的服务实例快照
+func (n *Node) updateServiceInstances(fn func(ins *registry.ServiceInstance) bool) []*registry.ServiceInstance {
+	n.insRW.Lock()
+	defer n.insRW.Unlock()
+
+	updated := make([]*registry.ServiceInstance, 0, len(n.instances))
+
+	for i, ins := range n.instances {
+		cp := *ins
+
+		if fn(&cp) {
+			n.instances[i] = &cp
+			updated = append(updated, &cp)
+		}
+	}
+
+	return updated
+}
+
 // 分发处理消息
 func (n *Node) dispatch() {
 	for {
@@ -212,7 +323,11 @@ func (n *Node) dispatch() {
 
 // 启动连接服务器
 func (n *Node) startLinkServer() {
-	linker, err := node.NewServer(n.opts.addr, &provider{node: n})
+	linker, err := node.NewServer(&node.ServerOptions{
+		Addr:         n.opts.addr,
+		DrainOnClose: n.opts.drainOnClose,
+		RecordWriter: n.opts.recordWriter,
+	}, &provider{node: n})
 	if err != nil {
 		log.Fatalf("link server create failed: %v", err)
 	}
@@ -227,8 +342,8 @@ func (n *Node) startLinkServer() {
 }
 
 // 停止连接服务器
-func (n *Node) stopLinkServer() {
-	if err := n.linker.Stop(); err != nil {
+func (n *Node) stopLinkServer(reason cluster.CloseReason) {
+	if err := n.linker.StopWithReason(reason); err != nil {
 		log.Errorf("link server stop failed: %v", err)
 	}
 }
@@ -278,31 +393,27 @@ func (n *Node) stopTransportServer() {
 
 // 注册服务实例
 func (n *Node) registerServiceInstances() {
-	routes := make([]registry.Route, 0, len(n.router.routes))
+	routes := n.router.registryRoutes()
 	events := make([]int, 0, len(n.trigger.events))
 
-	for _, entity := range n.router.routes {
-		routes = append(routes, registry.Route{
-			ID:       entity.route,
-			Stateful: entity.stateful,
-			Internal: entity.internal,
-		})
-	}
-
 	for evt := range n.trigger.events {
 		events = append(events, int(evt))
 	}
 
-	n.instances = append(n.instances, &registry.ServiceInstance{
-		ID:       n.opts.id,
-		Name:     cluster.Node.String(),
-		Kind:     cluster.Node.String(),
-		Alias:    n.opts.name,
-		State:    n.getState().String(),
-		Routes:   routes,
-		Events:   events,
-		Endpoint: n.linker.Endpoint().String(),
-		Weight:   n.opts.weight,
+	instances := make([]*registry.ServiceInstance, 0, 2)
+
+	instances = append(instances, &registry.ServiceInstance{
+		ID:        n.opts.id,
+		Name:      cluster.Node.String(),
+		Kind:      cluster.Node.String(),
+		Alias:     n.opts.name,
+		State:     n.getState().String(),
+		Routes:    routes,
+		Events:    events,
+		Endpoint:  n.linker.Endpoint().String(),
+		Endpoints: n.opts.endpoints,
+		Weight:    n.opts.weight,
+		Tags:      n.opts.tags,
 	})
 
 	if n.transporter != nil {
@@ -311,7 +422,7 @@ func (n *Node) registerServiceInstances() {
 			services = append(services, item.name)
 		}
 
-		n.instances = append(n.instances, &registry.ServiceInstance{
+		instances = append(instances, &registry.ServiceInstance{
 			ID:       n.opts.id,
 			Name:     cluster.Mesh.String(),
 			Kind:     cluster.Mesh.String(),
@@ -323,7 +434,11 @@ func (n *Node) registerServiceInstances() {
 		})
 	}
 
-	if err := n.doRegisterServiceInstances(); err != nil {
+	n.insRW.Lock()
+	n.instances = append(n.instances, instances...)
+	n.insRW.Unlock()
+
+	if err := n.doRegisterServiceInstances(instances); err != nil {
 		log.Fatalf("register cluster instances failed: %v", err)
 	}
 }
@@ -336,10 +451,9 @@ func (n *Node) refreshServiceInstances() {
 }
 
 // 解注册服务实例
-func (n *Node) deregisterServiceInstances() {
+func (n *Node) deregisterServiceInstances(reason cluster.CloseReason) {
 	eg, ctx := errgroup.WithContext(n.ctx)
-	for i := range n.instances {
-		instance := n.instances[i]
+	for _, instance := range n.serviceInstances() {
 		eg.Go(func() error {
 			ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
 			defer cancel()
@@ -348,16 +462,18 @@ func (n *Node) deregisterServiceInstances() {
 	}
 
 	if err := eg.Wait(); err != nil {
-		log.Errorf("deregister cluster instances failed: %v", err)
+		log.Errorf("deregister cluster instances failed, reason: %s err: %v", reason, err)
+		return
 	}
+
+	log.Infof("cluster instances deregistered, reason: %s", reason)
 }
 
 // 执行注册操作
-func (n *Node) doRegisterServiceInstances() error {
+func (n *Node) doRegisterServiceInstances(instances []*registry.ServiceInstance) error {
 	eg, ctx := errgroup.WithContext(n.ctx)
 
-	for i := range n.instances {
-		instance := n.instances[i]
+	for _, instance := range instances {
 		eg.Go(func() error {
 			ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
 			defer cancel()
@@ -370,11 +486,12 @@ func (n *Node) doRegisterServiceInstances() error {
 
 // 执行刷新实例状态操作
 func (n *Node) doRefreshServiceInstances() error {
-	for _, instance := range n.instances {
-		instance.State = n.getState().String()
-	}
+	state := n.getState().String()
 
-	return n.doRegisterServiceInstances()
+	return n.doRegisterServiceInstances(n.updateServiceInstances(func(ins *registry.ServiceInstance) bool {
+		ins.State = state
+		return true
+	}))
 }
 
 // 获取状态
@@ -2,13 +2,18 @@ package node
 
 import (
 	"context"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/core/breaker"
+	"github.com/dobyte/due/v2/core/id"
 	"github.com/dobyte/due/v2/crypto"
 	"github.com/dobyte/due/v2/encoding"
 	"github.com/dobyte/due/v2/etc"
 	"github.com/dobyte/due/v2/locate"
 	"github.com/dobyte/due/v2/registry"
+	"github.com/dobyte/due/v2/room"
 	"github.com/dobyte/due/v2/transport"
 	"github.com/dobyte/due/v2/utils/xuuid"
+	"io"
 	"time"
 )
 
@@ -18,6 +23,8 @@ const (
 	defaultCodec   = "proto"         // 默认编解码器名称
 	defaultTimeout = 3 * time.Second // 默认超时时间
 	defaultWeight  = 1               // 默认权重
+
+	defaultRoomPageSize = 500 // 默认房间成员分页大小
 )
 
 const (
@@ -27,6 +34,12 @@ const (
 	defaultCodecKey   = "etc.cluster.node.codec"
 	defaultTimeoutKey = "etc.cluster.node.timeout"
 	defaultWeightKey  = "etc.cluster.node.weight"
+
+	defaultLoadIntervalKey = "etc.cluster.node.loadInterval"
+	defaultAuditWindowKey  = "etc.cluster.node.auditWindow"
+	defaultDrainOnCloseKey = "etc.cluster.node.drainOnClose"
+
+	defaultUnknownRoutePolicyKey = "etc.cluster.node.unknownRoutePolicy"
 )
 
 // SchedulingModel 调度模型
@@ -35,17 +48,37 @@ type SchedulingModel string
 type Option func(o *options)
 
 type options struct {
-	ctx         context.Context       // 上下文
-	id          string                // 实例ID
-	name        string                // 实例名称；相同实例名称的节点，用户只能绑定其中一个
-	addr        string                // 监听地址
-	codec       encoding.Codec        // 编解码器
-	timeout     time.Duration         // RPC调用超时时间
-	locator     locate.Locator        // 用户定位器
-	registry    registry.Registry     // 服务注册器
-	encryptor   crypto.Encryptor      // 消息加密器
-	transporter transport.Transporter // 消息传输器
-	weight      int                   // 权重
+	ctx           context.Context        // 上下文
+	id            string                 // 实例ID
+	name          string                 // 实例名称；相同实例名称的节点，用户只能绑定其中一个
+	addr          string                 // 监听地址
+	codec         encoding.Codec         // 编解码器
+	timeout       time.Duration          // RPC调用超时时间
+	locator       locate.Locator         // 用户定位器
+	registry      registry.Registry      // 服务注册器
+	encryptor     crypto.Encryptor       // 消息加密器
+	transporter   transport.Transporter  // 消息传输器
+	weight        int                    // 权重
+	hedgingRoutes []cluster.HedgingRoute // 请求对冲路由
+	breaker       *breaker.Group         // 熔断器组
+	roomManager   room.Manager           // 房间管理器
+	endpoints     map[string]string      // 命名端口
+	tags          []string               // 自定义标签
+	permission    PermissionChecker      // 路由权限检测器
+	forbidden     ForbiddenHandler       // 路由无权限处理器
+	redactor      Redactor               // 路由采样日志脱敏处理器
+	balancer      registry.Balancer      // 负载均衡器
+	loadInterval  time.Duration          // 负载上报间隔时间，为0时不上报
+	loadSource    LoadSource             // 负载来源
+	rebindHandler RebindHandler          // 重新绑定处理器
+	closeReason   CloseReasonHandler     // 关闭通知处理器
+	auditWindow   time.Duration          // 传输层序列号审计窗口，为0时不开启审计
+	seqGenerator  id.Generator           // 传输层序列号ID生成器，为nil时使用单调递增的序列号
+	drainOnClose  bool                   // 传输层连接关闭时是否处理已读取但尚未分发的消息
+	recordWriter  io.Writer              // 传输层消息录制输出，为nil时不录制
+
+	unknownRoutePolicy  UnknownRoutePolicy  // 未知路由处理策略
+	unknownRouteHandler UnknownRouteHandler // 未知路由处理器
 }
 
 func defaultOptions() *options {
@@ -84,6 +117,17 @@ func defaultOptions() *options {
 		opts.weight = weight
 	}
 
+	if loadInterval := etc.Get(defaultLoadIntervalKey).Duration(); loadInterval > 0 {
+		opts.loadInterval = loadInterval
+	}
+
+	if auditWindow := etc.Get(defaultAuditWindowKey).Duration(); auditWindow > 0 {
+		opts.auditWindow = auditWindow
+	}
+
+	opts.drainOnClose = etc.Get(defaultDrainOnCloseKey).Bool()
+	opts.unknownRoutePolicy = UnknownRoutePolicy(etc.Get(defaultUnknownRoutePolicyKey, UnknownRouteIgnore).String())
+
 	return opts
 }
 
@@ -141,3 +185,105 @@ func WithTransporter(transporter transport.Transporter) Option {
 func WithWeight(weight int) Option {
 	return func(o *options) { o.weight = weight }
 }
+
+// WithHedgingRoutes 设置请求对冲路由，仅对幂等的无状态路由生效
+func WithHedgingRoutes(routes ...cluster.HedgingRoute) Option {
+	return func(o *options) { o.hedgingRoutes = append(o.hedgingRoutes, routes...) }
+}
+
+// WithBreaker 设置熔断器组，开启后按目标节点进行熔断
+func WithBreaker(b *breaker.Group) Option {
+	return func(o *options) { o.breaker = b }
+}
+
+// WithRoomManager 设置房间管理器
+func WithRoomManager(manager room.Manager) Option {
+	return func(o *options) { o.roomManager = manager }
+}
+
+// WithEndpoint 设置命名端口，注册实例时一并注册到服务注册中心
+func WithEndpoint(name, endpoint string) Option {
+	return func(o *options) {
+		if o.endpoints == nil {
+			o.endpoints = make(map[string]string)
+		}
+		o.endpoints[name] = endpoint
+	}
+}
+
+// WithTags 设置自定义标签，注册实例时一并注册到服务注册中心，可用于按区域或版本过滤服务实例
+func WithTags(tags ...string) Option {
+	return func(o *options) { o.tags = append(o.tags, tags...) }
+}
+
+// WithPermissionChecker 设置路由权限检测器，配合RequirePermission中间件使用
+func WithPermissionChecker(checker PermissionChecker) Option {
+	return func(o *options) { o.permission = checker }
+}
+
+// WithForbiddenHandler 设置路由无权限处理器，可用于自定义无权限时的响应；未设置时请求类消息默认响应codes.Forbidden
+func WithForbiddenHandler(handler ForbiddenHandler) Option {
+	return func(o *options) { o.forbidden = handler }
+}
+
+// WithRedactor 设置路由采样日志脱敏处理器
+func WithRedactor(redactor Redactor) Option {
+	return func(o *options) { o.redactor = redactor }
+}
+
+// WithBalancer 设置负载均衡器，用于无状态路由选择目标节点及为未绑定的用户选择有状态路由的绑定节点，选择依据为用户ID
+// 内置registry.NewRandomBalancer、registry.NewRoundRobinBalancer、registry.NewLeastConnBalancer、registry.NewLeastLoadBalancer
+func WithBalancer(balancer registry.Balancer) Option {
+	return func(o *options) { o.balancer = balancer }
+}
+
+// WithLoadInterval 设置负载上报间隔时间，配合registry.NewLeastLoadBalancer使用可避开繁忙的节点
+func WithLoadInterval(interval time.Duration) Option {
+	return func(o *options) { o.loadInterval = interval }
+}
+
+// WithLoadSource 设置负载来源，默认以Actor数作为活跃数，以请求队列的积压比例作为负载评分
+func WithLoadSource(source LoadSource) Option {
+	return func(o *options) { o.loadSource = source }
+}
+
+// WithCloseReasonHandler 设置关闭通知处理器，已连接的网关或节点关闭前下发关闭原因时调用，未设置时仅打印日志
+func WithCloseReasonHandler(handler CloseReasonHandler) Option {
+	return func(o *options) { o.closeReason = handler }
+}
+
+// WithRebindHandler 设置重新绑定处理器，排空节点时对每个绑定到当前节点的用户调用
+// 未设置时排空节点将直接解绑用户，用户的下一个有状态请求需由客户端重新进入后绑定到其他节点
+func WithRebindHandler(handler RebindHandler) Option {
+	return func(o *options) { o.rebindHandler = handler }
+}
+
+// WithAuditWindow 设置传输层序列号审计窗口，开启后检测重复响应、未知序列号响应及窗口内未响应的请求；审计存在额外的跟踪开销，默认关闭
+func WithAuditWindow(window time.Duration) Option {
+	return func(o *options) { o.auditWindow = window }
+}
+
+// WithSeqGenerator 设置传输层序列号ID生成器，设置为id.Snowflake时序列号在重连及重建客户端后仍全局唯一，避免与旧连接上未完成的响应冲突
+func WithSeqGenerator(generator id.Generator) Option {
+	return func(o *options) { o.seqGenerator = generator }
+}
+
+// WithRecordWriter 设置传输层消息录制输出，设置后录制内建RPC服务器连接上读取到的原始消息，用于调试时复现协议问题；录制存在额外的写入开销，仅建议调试时开启
+func WithRecordWriter(w io.Writer) Option {
+	return func(o *options) { o.recordWriter = w }
+}
+
+// WithDrainOnClose 设置传输层连接关闭时是否在关闭写入前处理已读取但尚未分发的消息，默认丢弃
+func WithDrainOnClose(drain bool) Option {
+	return func(o *options) { o.drainOnClose = drain }
+}
+
+// WithUnknownRoutePolicy 设置未知路由处理策略，默认为UnknownRouteIgnore；设置默认路由处理器后不再存在未知路由
+func WithUnknownRoutePolicy(policy UnknownRoutePolicy) Option {
+	return func(o *options) { o.unknownRoutePolicy = policy }
+}
+
+// WithUnknownRouteHandler 设置未知路由处理器，策略为UnknownRouteRespond时对请求类消息调用，可用于自定义响应；未设置时默认响应codes.NotFound
+func WithUnknownRouteHandler(handler UnknownRouteHandler) Option {
+	return func(o *options) { o.unknownRouteHandler = handler }
+}
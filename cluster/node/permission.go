@@ -0,0 +1,41 @@
+package node
+
+import (
+	"github.com/dobyte/due/v2/codes"
+	"github.com/dobyte/due/v2/log"
+)
+
+// PermissionChecker 路由权限检测器，由业务自行定义权限模型
+// 可根据ctx.UID()获取认证时绑定的用户，并校验其是否拥有指定权限
+type PermissionChecker func(ctx Context, permission string) bool
+
+// ForbiddenHandler 路由无权限处理器，code固定为codes.Forbidden
+type ForbiddenHandler func(ctx Context, code *codes.Code)
+
+// RequirePermission 路由权限中间件，声明路由所需的权限，校验通过后才会执行路由处理器
+// 未绑定用户或未设置权限检测器时均视为无权限；未设置无权限处理器时，请求类消息（序列号不为0）默认响应codes.Forbidden
+func RequirePermission(permission string) MiddlewareHandler {
+	return func(middleware *Middleware, ctx Context) {
+		opts := ctx.Proxy().node.opts
+
+		if ctx.UID() != 0 && opts.permission != nil && opts.permission(ctx, permission) {
+			middleware.Next(ctx)
+			return
+		}
+
+		if opts.forbidden != nil {
+			opts.forbidden(ctx, codes.Forbidden)
+			return
+		}
+
+		log.Warnf("route permission denied, uid: %d route: %d permission: %s", ctx.UID(), ctx.Route(), permission)
+
+		if ctx.Seq() == 0 {
+			return
+		}
+
+		if err := ctx.Response(codes.Forbidden.Reply()); err != nil {
+			log.Errorf("response forbidden failed, uid: %d route: %d err: %v", ctx.UID(), ctx.Route(), err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package node_test
+
+import (
+	"encoding/json"
+	"github.com/dobyte/due/v2/cluster/node"
+	"github.com/dobyte/due/v2/codes"
+	"github.com/dobyte/due/v2/packet"
+	"testing"
+	"time"
+)
+
+func TestRequirePermission(t *testing.T) {
+	c := newTestCluster(t)
+
+	c.startNode(t, func(n *node.Node) {
+		handler := func(ctx node.Context) {
+			_ = ctx.Response("ok")
+		}
+
+		n.Proxy().Router().AddRouteHandler(1, false, handler, node.RequirePermission("admin"))
+		n.Proxy().Router().AddRouteHandler(2, false, handler, node.RequirePermission("player"))
+	}, node.WithPermissionChecker(func(ctx node.Context, permission string) bool {
+		return permission == "player"
+	}))
+
+	t.Run("forbidden", func(t *testing.T) {
+		c.deliver(t, 1, 10, &packet.Message{Seq: 1, Route: 1})
+
+		p := c.gate.expectPush(t)
+
+		if p.message.Seq != 1 || p.message.Route != 1 {
+			t.Fatalf("unexpected reply: seq = %d route = %d", p.message.Seq, p.message.Route)
+		}
+
+		reply := &codes.Reply{}
+		if err := json.Unmarshal(p.message.Buffer, reply); err != nil {
+			t.Fatal(err)
+		}
+
+		if reply.Code != codes.Forbidden.Code() {
+			t.Fatalf("unexpected reply code: %d", reply.Code)
+		}
+	})
+
+	t.Run("unbound", func(t *testing.T) {
+		c.deliver(t, 1, 0, &packet.Message{Seq: 2, Route: 2})
+
+		reply := &codes.Reply{}
+		if err := json.Unmarshal(c.gate.expectPush(t).message.Buffer, reply); err != nil {
+			t.Fatal(err)
+		}
+
+		if reply.Code != codes.Forbidden.Code() {
+			t.Fatalf("unexpected reply code: %d", reply.Code)
+		}
+	})
+
+	t.Run("notify", func(t *testing.T) {
+		c.deliver(t, 1, 10, &packet.Message{Seq: 0, Route: 1})
+
+		c.gate.expectNoPush(t)
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		c.deliver(t, 1, 10, &packet.Message{Seq: 3, Route: 2})
+
+		var data string
+		if err := json.Unmarshal(c.gate.expectPush(t).message.Buffer, &data); err != nil {
+			t.Fatal(err)
+		}
+
+		if data != "ok" {
+			t.Fatalf("unexpected reply: %s", data)
+		}
+	})
+}
+
+func TestRequirePermission_ForbiddenHandler(t *testing.T) {
+	c := newTestCluster(t)
+
+	forbidden := make(chan int, 1)
+
+	c.startNode(t, func(n *node.Node) {
+		n.Proxy().Router().AddRouteHandler(1, false, func(ctx node.Context) {}, node.RequirePermission("admin"))
+	}, node.WithForbiddenHandler(func(ctx node.Context, code *codes.Code) {
+		forbidden <- code.Code()
+	}))
+
+	c.deliver(t, 1, 10, &packet.Message{Seq: 1, Route: 1})
+
+	select {
+	case code := <-forbidden:
+		if code != codes.Forbidden.Code() {
+			t.Fatalf("unexpected forbidden code: %d", code)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("forbidden handler not called")
+	}
+
+	c.gate.expectNoPush(t)
+}
@@ -3,8 +3,11 @@ package node
 import (
 	"context"
 	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/encoding"
 	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/log"
 	"github.com/dobyte/due/v2/packet"
+	"time"
 )
 
 type provider struct {
@@ -13,24 +16,24 @@ type provider struct {
 
 // Trigger 触发事件
 func (p *provider) Trigger(ctx context.Context, gid string, cid, uid int64, event cluster.Event) error {
+	if event == cluster.Disconnect {
+		p.node.codecs.forget(gid, cid, uid)
+	}
+
 	p.node.trigger.trigger(event, gid, cid, uid)
 
 	return nil
 }
 
 // Deliver 投递消息
-func (p *provider) Deliver(ctx context.Context, gid, nid string, cid, uid int64, message []byte) error {
+func (p *provider) Deliver(ctx context.Context, gid, nid string, cid, uid int64, codec string, message []byte) error {
 	msg, err := packet.UnpackMessage(message)
 	if err != nil {
 		return err
 	}
 
+	// 未知路由消息同样投递给路由器，由未知路由处理策略处理
 	stateful, ok := p.node.router.CheckRouteStateful(msg.Route)
-	if !ok {
-		if ok = p.node.router.HasDefaultRouteHandler(); !ok {
-			return nil
-		}
-	}
 
 	if stateful {
 		if uid == 0 {
@@ -45,9 +48,23 @@ func (p *provider) Deliver(ctx context.Context, gid, nid string, cid, uid int64,
 		if !ok {
 			return errors.ErrNotFoundSession
 		}
+
+		// 经定位器路由到当前节点的用户同样计入绑定用户，以便排空节点时迁移
+		p.node.storeSession(uid, false)
+	}
+
+	var c encoding.Codec
+	if codec != "" {
+		if c, ok = encoding.Lookup(codec); !ok {
+			log.Warnf("codec not registered, cid: %d uid: %d codec: %s", cid, uid, codec)
+		}
+	}
+
+	if gid != "" {
+		p.node.codecs.record(gid, cid, uid, c)
 	}
 
-	p.node.router.deliver(gid, nid, "", cid, uid, msg.Seq, msg.Route, msg.Buffer)
+	p.node.router.deliver(gid, nid, "", cid, uid, msg.Seq, msg.Route, msg.Buffer, c)
 
 	return nil
 }
@@ -61,3 +78,8 @@ func (p *provider) GetState() (cluster.State, error) {
 func (p *provider) SetState(state cluster.State) error {
 	return p.node.setState(state)
 }
+
+// Drain 排空节点
+func (p *provider) Drain(timeout time.Duration, progress func(remaining int)) (int, error) {
+	return p.node.drain(timeout, progress)
+}
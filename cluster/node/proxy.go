@@ -3,6 +3,7 @@ package node
 import (
 	"context"
 	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/encoding"
 	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/internal/link"
 	"github.com/dobyte/due/v2/registry"
@@ -19,12 +20,31 @@ type Proxy struct {
 
 func newProxy(node *Node) *Proxy {
 	opts := &link.Options{
-		InsID:     node.opts.id,
-		InsKind:   cluster.Node,
-		Codec:     node.opts.codec,
-		Locator:   node.opts.locator,
-		Registry:  node.opts.registry,
-		Encryptor: node.opts.encryptor,
+		InsID:         node.opts.id,
+		InsKind:       cluster.Node,
+		Codec:         node.opts.codec,
+		Locator:       node.opts.locator,
+		Registry:      node.opts.registry,
+		Encryptor:     node.opts.encryptor,
+		HedgingRoutes: node.opts.hedgingRoutes,
+		Breaker:       node.opts.breaker,
+		Balancer:      node.opts.balancer,
+		AuditWindow:   node.opts.auditWindow,
+		SeqGenerator:  node.opts.seqGenerator,
+
+		CloseReasonHandler: link.CloseReasonHandler(node.opts.closeReason),
+	}
+
+	opts.NodeBindHandler = func(uid int64, _, nid string, bound bool) {
+		if nid != node.opts.id {
+			return
+		}
+
+		if bound {
+			node.storeSession(uid, false)
+		} else {
+			node.deleteSession(uid)
+		}
 	}
 
 	return &Proxy{
@@ -122,6 +142,14 @@ func (p *Proxy) UnbindGate(ctx context.Context, uid int64) error {
 	return p.gateLinker.Unbind(ctx, uid)
 }
 
+// Drain 排空当前节点，用于滚动发布
+// 节点状态变更为cluster.Drain后不再分配新的请求且拒绝新的绑定，并通过重新绑定处理器通知已绑定的用户迁移至其他节点
+// 阻塞至所有用户解绑当前节点或超时，超时返回errors.ErrDrainTimeout
+func (p *Proxy) Drain(timeout time.Duration) error {
+	_, err := p.node.drain(timeout, nil)
+	return err
+}
+
 // BindNode 绑定节点
 // 单个用户可以绑定到多个节点服务器上，相同名称的节点服务器只能绑定一个，多次绑定会到相同名称的节点服务器会覆盖之前的绑定。
 // 绑定操作会通过发布订阅方式同步到网关服务器和其他相关节点服务器上。
@@ -132,12 +160,16 @@ func (p *Proxy) BindNode(ctx context.Context, uid int64, nameAndNID ...string) e
 		name, nid = nameAndNID[0], nameAndNID[1]
 	}
 
+	if nid == p.node.opts.id && p.node.getState() == cluster.Drain {
+		return errors.ErrNodeDraining
+	}
+
 	if err := p.nodeLinker.Bind(ctx, uid, name, nid); err != nil {
 		return err
 	}
 
 	if nid == p.node.opts.id {
-		p.node.addWait()
+		p.node.storeSession(uid, true)
 	}
 
 	return nil
@@ -158,7 +190,7 @@ func (p *Proxy) UnbindNode(ctx context.Context, uid int64, nameAndNID ...string)
 	}
 
 	if nid == p.node.opts.id {
-		p.node.doneWait()
+		p.node.deleteSession(uid)
 	}
 
 	return nil
@@ -229,6 +261,20 @@ func (p *Proxy) PackBuffer(message any) ([]byte, error) {
 	return p.gateLinker.PackBuffer(message, true)
 }
 
+// 使用指定的编解码器编码消息，codec为nil时不编码
+func (p *Proxy) encodeMessage(codec encoding.Codec, message *cluster.Message) (*cluster.Message, error) {
+	if codec == nil {
+		return message, nil
+	}
+
+	data, err := p.gateLinker.PackBufferWithCodec(codec, message.Data, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cluster.Message{Seq: message.Seq, Route: message.Route, Data: data}, nil
+}
+
 // GetIP 获取客户端IP
 func (p *Proxy) GetIP(ctx context.Context, args *cluster.GetIPArgs) (string, error) {
 	return p.gateLinker.GetIP(ctx, args)
@@ -249,19 +295,115 @@ func (p *Proxy) Disconnect(ctx context.Context, args *cluster.DisconnectArgs) er
 	return p.gateLinker.Disconnect(ctx, args)
 }
 
-// Push 推送消息
+// Kick 踢用户下线，无论用户位于哪个网关，均由其所在网关下发踢下线通知消息后断开连接
+// 返回值为true时表示已找到用户并踢下线，用户不在线或并发断开连接时返回false
+func (p *Proxy) Kick(ctx context.Context, args *cluster.KickArgs) (bool, error) {
+	return p.gateLinker.Kick(ctx, args)
+}
+
+// Push 推送消息，消息使用接收方连接协商的编解码器编码
 func (p *Proxy) Push(ctx context.Context, args *cluster.PushArgs) error {
-	return p.gateLinker.Push(ctx, args)
+	codec := p.node.codecs.lookup(args.Kind, args.GID, args.Target)
+	if codec == nil {
+		return p.gateLinker.Push(ctx, args)
+	}
+
+	message, err := p.encodeMessage(codec, args.Message)
+	if err != nil {
+		return err
+	}
+
+	return p.gateLinker.Push(ctx, &cluster.PushArgs{
+		GID:     args.GID,
+		Kind:    args.Kind,
+		Target:  args.Target,
+		Header:  args.Header,
+		Message: message,
+	})
 }
 
-// Multicast 推送组播消息
+// Multicast 推送组播消息，接收方按连接协商的编解码器分组后分别编码推送
 func (p *Proxy) Multicast(ctx context.Context, args *cluster.MulticastArgs) error {
-	return p.gateLinker.Multicast(ctx, args)
+	groups := p.node.codecs.group(args.Kind, args.GID, args.Targets)
+	if targets, ok := groups[nil]; ok && len(groups) == 1 {
+		return p.gateLinker.Multicast(ctx, &cluster.MulticastArgs{
+			GID:     args.GID,
+			Kind:    args.Kind,
+			Targets: targets,
+			Message: args.Message,
+		})
+	}
+
+	var err error
+
+	for codec, targets := range groups {
+		message, e := p.encodeMessage(codec, args.Message)
+		if e == nil {
+			e = p.gateLinker.Multicast(ctx, &cluster.MulticastArgs{
+				GID:     args.GID,
+				Kind:    args.Kind,
+				Targets: targets,
+				Message: message,
+			})
+		}
+
+		if e != nil && err == nil {
+			err = e
+		}
+	}
+
+	return err
 }
 
-// Broadcast 推送广播消息
+// PushToRoom 推送房间消息，分页迭代房间成员后推送给成员所在的网关，每个成员仅推送一次
+func (p *Proxy) PushToRoom(ctx context.Context, roomID string, message *cluster.Message) error {
+	if p.node.opts.roomManager == nil {
+		return errors.ErrNotFoundRoomManager
+	}
+
+	var (
+		cursor uint64
+		pushed = make(map[int64]struct{})
+	)
+
+	for {
+		uids, next, err := p.node.opts.roomManager.Members(ctx, roomID, cursor, defaultRoomPageSize)
+		if err != nil {
+			return err
+		}
+
+		// 迭代期间房间成员变动时同一成员可能被重复返回，跳过已推送的成员
+		targets := uids[:0]
+		for _, uid := range uids {
+			if _, ok := pushed[uid]; ok {
+				continue
+			}
+
+			pushed[uid] = struct{}{}
+			targets = append(targets, uid)
+		}
+
+		if len(targets) > 0 {
+			if err = p.Multicast(ctx, &cluster.MulticastArgs{
+				Kind:    session.User,
+				Targets: targets,
+				Message: message,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if next == 0 {
+			return nil
+		}
+
+		cursor = next
+	}
+}
+
+// Broadcast 推送广播消息，同时推送以客户端连接协商的编解码器编码的消息，由网关按连接选择
 func (p *Proxy) Broadcast(ctx context.Context, args *cluster.BroadcastArgs) error {
-	return p.gateLinker.Broadcast(ctx, args)
+	return p.gateLinker.BroadcastCodecs(ctx, args, p.node.codecs.all())
 }
 
 // Deliver 投递消息给节点处理
@@ -322,6 +464,16 @@ func (p *Proxy) Actor(kind, id string) (*Actor, bool) {
 	return p.node.scheduler.load(kind, id)
 }
 
+// InflightStats 获取各节点地址进行中的RPC调用数，可注册为调试组件的统计收集器
+func (p *Proxy) InflightStats() map[string]int64 {
+	return p.nodeLinker.InflightStats()
+}
+
+// AuditStats 获取各节点地址的传输层序列号审计统计，可注册为调试组件的统计收集器
+func (p *Proxy) AuditStats() map[string]cluster.AuditStat {
+	return p.nodeLinker.AuditStats()
+}
+
 // 开始监听
 func (p *Proxy) watch() {
 	p.gateLinker.WatchUserLocate()
@@ -0,0 +1,24 @@
+package node_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/errors"
+	"testing"
+)
+
+func TestNode_Ready(t *testing.T) {
+	c := newTestCluster(t)
+
+	n := c.startNode(t, nil)
+
+	if err := n.Ready(context.Background()); err != nil {
+		t.Fatalf("expected ready, got %v", err)
+	}
+
+	// 关闭后不再就绪
+	n.Close()
+
+	if err := n.Ready(context.Background()); !errors.Is(err, errors.ErrNotReady) {
+		t.Fatalf("expected ErrNotReady, got %v", err)
+	}
+}
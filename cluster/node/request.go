@@ -11,6 +11,7 @@ import (
 	"context"
 	"github.com/dobyte/due/v2/cluster"
 	"github.com/dobyte/due/v2/core/chains"
+	"github.com/dobyte/due/v2/encoding"
 	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/session"
 	"github.com/dobyte/due/v2/task"
@@ -32,6 +33,7 @@ type request struct {
 	version atomic.Int32     // 版本号
 	chain   *chains.Chain    // 调用链
 	actor   atomic.Value     // 当前Actor
+	codec   encoding.Codec   // 客户端连接协商的编解码器，为空时使用节点默认的编解码器
 }
 
 // GID 获取网关ID
@@ -93,10 +95,10 @@ func (r *request) Parse(v interface{}) error {
 			return err
 		}
 
-		return r.node.opts.codec.Unmarshal(data, v)
+		msg = data
 	}
 
-	return r.node.opts.codec.Unmarshal(msg, v)
+	return r.getCodec().Unmarshal(msg, v)
 }
 
 // Defer 添加defer延迟调用栈
@@ -134,12 +136,13 @@ func (r *request) compareVersionExecDefer(version int32) {
 // Clone 克隆Context
 func (r *request) Clone() Context {
 	return &request{
-		node: r.node,
-		gid:  r.gid,
-		nid:  r.nid,
-		cid:  r.cid,
-		uid:  r.uid,
-		ctx:  context.Background(),
+		node:  r.node,
+		gid:   r.gid,
+		nid:   r.nid,
+		cid:   r.cid,
+		uid:   r.uid,
+		ctx:   context.Background(),
+		codec: r.codec,
 		message: &cluster.Message{
 			Seq:   r.message.Seq,
 			Route: r.message.Route,
@@ -313,6 +316,12 @@ func (r *request) Deliver(args *cluster.DeliverArgs) error {
 func (r *request) Reply(message *cluster.Message) error {
 	switch {
 	case r.gid != "": // 来源于网关
+		if msg, err := r.node.proxy.encodeMessage(r.codec, message); err != nil {
+			return err
+		} else {
+			message = msg
+		}
+
 		return r.node.proxy.Push(r.ctx, &cluster.PushArgs{
 			GID:     r.gid,
 			Kind:    session.Conn,
@@ -372,6 +381,15 @@ func (r *request) NewMeshClient(target string) (transport.Client, error) {
 	return r.node.proxy.NewMeshClient(target)
 }
 
+// 获取编解码器
+func (r *request) getCodec() encoding.Codec {
+	if r.codec != nil {
+		return r.codec
+	}
+
+	return r.node.opts.codec
+}
+
 // 保存当前Actor
 func (r *request) storeActor(actor *Actor) {
 	r.actor.Store(actor)
@@ -399,6 +417,8 @@ func (r *request) compareVersionRecycle(version int32) {
 func (r *request) reset() {
 	r.message.Data = nil
 
+	r.codec = nil
+
 	r.actor.Store((*Actor)(nil))
 
 	if r.chain != nil {
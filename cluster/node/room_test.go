@@ -0,0 +1,84 @@
+package node_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/cluster/node"
+	"github.com/dobyte/due/v2/packet"
+	"testing"
+)
+
+func TestProxy_PushToRoom(t *testing.T) {
+	c := newTestCluster(t)
+
+	// 模拟SSCAN在迭代期间重复返回成员
+	rooms := &pagedRoomManager{pages: [][]int64{{1, 2}, {2, 3}, {1, 4, 4}}}
+
+	n := c.startNode(t, nil, node.WithRoomManager(rooms))
+
+	// 等待节点启动并发现网关
+	c.deliver(t, 1, 0, &packet.Message{Route: 1})
+
+	for uid := int64(1); uid <= 4; uid++ {
+		if err := c.locator.BindGate(context.Background(), uid, c.gate.id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := n.Proxy().PushToRoom(context.Background(), "room-1", &cluster.Message{Route: 2, Data: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	targets := make(map[int64]int)
+
+	for i := 0; i < 4; i++ {
+		targets[c.gate.expectPush(t).target]++
+	}
+
+	c.gate.expectNoPush(t)
+
+	for uid := int64(1); uid <= 4; uid++ {
+		if targets[uid] != 1 {
+			t.Fatalf("uid %d pushed %d times", uid, targets[uid])
+		}
+	}
+}
+
+// 按页返回房间成员的房间管理器
+type pagedRoomManager struct {
+	pages [][]int64
+}
+
+func (m *pagedRoomManager) Name() string {
+	return "paged"
+}
+
+func (m *pagedRoomManager) Join(ctx context.Context, roomID string, uids ...int64) error {
+	return nil
+}
+
+func (m *pagedRoomManager) Leave(ctx context.Context, roomID string, uids ...int64) error {
+	return nil
+}
+
+func (m *pagedRoomManager) LeaveAll(ctx context.Context, uid int64) error {
+	return nil
+}
+
+func (m *pagedRoomManager) Rooms(ctx context.Context, uid int64) ([]string, error) {
+	return nil, nil
+}
+
+func (m *pagedRoomManager) Count(ctx context.Context, roomID string) (int64, error) {
+	return 0, nil
+}
+
+func (m *pagedRoomManager) Members(ctx context.Context, roomID string, cursor uint64, count int64) ([]int64, uint64, error) {
+	uids := append([]int64(nil), m.pages[cursor]...)
+
+	if next := cursor + 1; next < uint64(len(m.pages)) {
+		return uids, next, nil
+	}
+
+	return uids, 0, nil
+}
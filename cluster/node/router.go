@@ -1,8 +1,18 @@
 package node
 
 import (
+	"context"
+	"fmt"
 	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/encoding"
+	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/log"
+	"github.com/dobyte/due/v2/packet"
+	"github.com/dobyte/due/v2/registry"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
 type RouteHandler func(ctx Context)
@@ -12,6 +22,8 @@ type Router struct {
 	routes              map[int32]*routeEntity
 	defaultRouteHandler RouteHandler
 	reqChan             chan *request
+	duplicates          []int32
+	unknowns            *unknownRoutes // 未知路由统计
 }
 
 type routeEntity struct {
@@ -20,6 +32,27 @@ type routeEntity struct {
 	internal    bool                // 是否内部路由
 	handler     RouteHandler        // 路由处理器
 	middlewares []MiddlewareHandler // 路由中间件
+	calls       atomic.Int64        // 调用次数
+	elapsed     atomic.Int64        // 同步处理总耗时（纳秒）
+	sampling    atomic.Uint64       // 请求与响应日志采样率（float64位模式）
+}
+
+// RouteStat 路由处理统计
+type RouteStat struct {
+	Route    int32         `json:"route"`    // 路由
+	Calls    int64         `json:"calls"`    // 调用次数
+	Elapsed  time.Duration `json:"elapsed"`  // 同步处理总耗时（不包含异步任务）
+	Internal bool          `json:"internal"` // 是否内部路由
+}
+
+// RouteInfo 路由注册信息
+type RouteInfo struct {
+	Route      int32              `json:"route"`      // 路由
+	Stateful   bool               `json:"stateful"`   // 是否有状态
+	Internal   bool               `json:"internal"`   // 是否内部路由
+	Attrs      registry.RouteAttr `json:"attrs"`      // 路由属性位域，与注册中心编码的属性一致
+	Handler    bool               `json:"handler"`    // 是否设置了路由处理器
+	Registered bool               `json:"registered"` // 是否已注册到服务注册中心
 }
 
 type RouteOptions struct {
@@ -45,9 +78,10 @@ type RouteOptions struct {
 
 func newRouter(node *Node) *Router {
 	return &Router{
-		node:    node,
-		routes:  make(map[int32]*routeEntity),
-		reqChan: make(chan *request, 10240),
+		node:     node,
+		routes:   make(map[int32]*routeEntity),
+		reqChan:  make(chan *request, 10240),
+		unknowns: newUnknownRoutes(),
 	}
 }
 
@@ -58,12 +92,12 @@ func (r *Router) AddRouteHandler(route int32, stateful bool, handler RouteHandle
 		return
 	}
 
-	r.routes[route] = &routeEntity{
+	r.addRoute(&routeEntity{
 		route:       route,
 		stateful:    stateful,
 		handler:     handler,
 		middlewares: middlewares[:],
-	}
+	})
 }
 
 // AddInternalRouteHandler 添加内部路由处理器（node节点间路由消息处理）
@@ -73,13 +107,52 @@ func (r *Router) AddInternalRouteHandler(route int32, stateful bool, handler Rou
 		return
 	}
 
-	r.routes[route] = &routeEntity{
+	r.addRoute(&routeEntity{
 		route:       route,
 		stateful:    stateful,
 		internal:    true,
 		handler:     handler,
 		middlewares: middlewares[:],
+	})
+}
+
+// 添加路由，重复注册的路由将被记录并在启动时校验
+func (r *Router) addRoute(entity *routeEntity) {
+	if _, ok := r.routes[entity.route]; ok {
+		r.duplicates = append(r.duplicates, entity.route)
+	}
+
+	r.routes[entity.route] = entity
+}
+
+// 校验路由注册，返回所有重复注册及超出打包器范围的路由
+func (r *Router) validate() error {
+	conflicts := make([]string, 0, len(r.duplicates))
+
+	for _, route := range r.duplicates {
+		conflicts = append(conflicts, fmt.Sprintf("route %d is registered repeatedly", route))
+	}
+
+	routes := make([]int32, 0, len(r.routes))
+	for route, entity := range r.routes {
+		if !entity.internal {
+			routes = append(routes, route)
+		}
 	}
+
+	sort.Slice(routes, func(i, j int) bool { return routes[i] < routes[j] })
+
+	for _, route := range routes {
+		if err := packet.CheckRoute(route); err != nil {
+			conflicts = append(conflicts, fmt.Sprintf("route %d is out of range", route))
+		}
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	return errors.NewError(strings.Join(conflicts, "; "), errors.ErrInvalidRoute)
 }
 
 // SetDefaultRouteHandler 设置默认路由处理器，所有未注册的路由均走默认路由处理器
@@ -119,7 +192,7 @@ func (r *Router) Group(groups ...func(group *RouterGroup)) *RouterGroup {
 	return group
 }
 
-func (r *Router) deliver(gid, nid, pid string, cid, uid int64, seq, route int32, data interface{}) {
+func (r *Router) deliver(gid, nid, pid string, cid, uid int64, seq, route int32, data interface{}, codec encoding.Codec) {
 	req := r.node.reqPool.Get().(*request)
 	req.gid = gid
 	req.nid = nid
@@ -129,6 +202,7 @@ func (r *Router) deliver(gid, nid, pid string, cid, uid int64, seq, route int32,
 	req.message.Seq = seq
 	req.message.Route = route
 	req.message.Data = data
+	req.codec = codec
 	r.reqChan <- req
 }
 
@@ -145,12 +219,14 @@ func (r *Router) handle(req *request) {
 
 	route, ok := r.routes[req.message.Route]
 	if !ok && r.defaultRouteHandler == nil {
+		r.handleUnknown(req)
 		req.compareVersionRecycle(version)
-		log.Warnf("message routing does not register handler function, route: %v", req.message.Route)
 		return
 	}
 
 	if ok {
+		start := time.Now()
+
 		if len(route.middlewares) > 0 {
 			middleware := &Middleware{
 				index:        -1,
@@ -158,9 +234,11 @@ func (r *Router) handle(req *request) {
 				routeHandler: route.handler,
 			}
 			middleware.Next(req)
+			route.record(start)
 			return
 		} else {
 			route.handler(req)
+			route.record(start)
 		}
 	} else {
 		r.defaultRouteHandler(req)
@@ -171,6 +249,76 @@ func (r *Router) handle(req *request) {
 	req.compareVersionRecycle(version)
 }
 
+// UnknownRouteStat 获取未知路由统计
+func (r *Router) UnknownRouteStat() UnknownRouteStat {
+	return r.unknowns.stat()
+}
+
+// Stats 获取路由处理统计
+func (r *Router) Stats() []RouteStat {
+	stats := make([]RouteStat, 0, len(r.routes))
+	for _, entity := range r.routes {
+		stats = append(stats, RouteStat{
+			Route:    entity.route,
+			Calls:    entity.calls.Load(),
+			Elapsed:  time.Duration(entity.elapsed.Load()),
+			Internal: entity.internal,
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Route < stats[j].Route })
+
+	return stats
+}
+
+// Routes 获取全部路由的注册信息，按路由升序排列
+// 声明的路由与服务注册中心中当前节点实例的路由进行比对，可用于管理后台核对两者是否一致
+func (r *Router) Routes(ctx context.Context) ([]RouteInfo, error) {
+	registered, err := r.node.registeredRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := r.registryRoutes()
+	infos := make([]RouteInfo, 0, len(routes))
+	for _, route := range routes {
+		reg, ok := registered[route.ID]
+
+		infos = append(infos, RouteInfo{
+			Route:      route.ID,
+			Stateful:   route.Stateful,
+			Internal:   route.Internal,
+			Attrs:      route.Attrs(),
+			Handler:    r.routes[route.ID].handler != nil,
+			Registered: ok && reg.Attrs() == route.Attrs(),
+		})
+	}
+
+	return infos, nil
+}
+
+// 生成注册到服务注册中心的路由列表，按路由升序排列
+func (r *Router) registryRoutes() []registry.Route {
+	routes := make([]registry.Route, 0, len(r.routes))
+	for _, entity := range r.routes {
+		routes = append(routes, registry.Route{
+			ID:       entity.route,
+			Stateful: entity.stateful,
+			Internal: entity.internal,
+		})
+	}
+
+	sort.Slice(routes, func(i, j int) bool { return routes[i].ID < routes[j].ID })
+
+	return routes
+}
+
+// 记录路由处理统计
+func (e *routeEntity) record(start time.Time) {
+	e.calls.Add(1)
+	e.elapsed.Add(int64(time.Since(start)))
+}
+
 type RouterGroup struct {
 	router      *Router
 	middlewares []MiddlewareHandler
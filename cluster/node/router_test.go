@@ -0,0 +1,82 @@
+package node_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/cluster/node"
+	"github.com/dobyte/due/v2/packet"
+	"github.com/dobyte/due/v2/registry"
+	"testing"
+)
+
+func TestNode_Routes(t *testing.T) {
+	n := node.NewNode()
+
+	n.Proxy().Router().AddRouteHandler(2, true, func(ctx node.Context) {})
+	n.Proxy().Router().AddInternalRouteHandler(1, false, nil)
+
+	routes, err := n.Routes(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(routes) != 2 {
+		t.Fatalf("unexpected routes: %+v", routes)
+	}
+
+	if r := routes[0]; r.Route != 1 || !r.Internal || r.Stateful || r.Handler || r.Attrs != registry.RouteAttrInternal || r.Registered {
+		t.Fatalf("unexpected route: %+v", r)
+	}
+
+	if r := routes[1]; r.Route != 2 || r.Internal || !r.Stateful || !r.Handler || r.Attrs != registry.RouteAttrStateful || r.Registered {
+		t.Fatalf("unexpected route: %+v", r)
+	}
+}
+
+func TestNode_RoutesRegistered(t *testing.T) {
+	var (
+		ctx = context.Background()
+		c   = newTestCluster(t)
+	)
+
+	n := c.startNode(t, func(n *node.Node) {
+		n.Proxy().Router().AddRouteHandler(1, false, func(ctx node.Context) {})
+		n.Proxy().Router().AddRouteHandler(2, true, func(ctx node.Context) {})
+	})
+
+	// 等待传输服务器就绪
+	c.deliver(t, 1, 0, &packet.Message{Route: 1})
+
+	routes, err := n.Routes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range routes {
+		if !r.Registered {
+			t.Fatalf("route not registered: %+v", r)
+		}
+	}
+
+	// 注册中心中的实例记录与声明的路由不一致
+	services, err := c.registry.Services(ctx, cluster.Node.String())
+	if err != nil || len(services) != 1 {
+		t.Fatalf("node not registered: %v", err)
+	}
+
+	ins := services[0]
+	ins.Routes = []registry.Route{{ID: 1}, {ID: 2}}
+
+	if err = c.registry.Register(ctx, ins); err != nil {
+		t.Fatal(err)
+	}
+
+	routes, err = n.Routes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !routes[0].Registered || routes[1].Registered {
+		t.Fatalf("unexpected routes: %+v", routes)
+	}
+}
@@ -0,0 +1,140 @@
+package node
+
+import (
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/log"
+	"github.com/dobyte/due/v2/utils/xconv"
+	"github.com/dobyte/due/v2/utils/xrand"
+	"math"
+	"net/http"
+)
+
+// Redactor 脱敏处理器，用于在采样日志输出前隐藏敏感字段
+type Redactor func(route int32, v any) any
+
+// SetRouteSampling 设置路由请求与响应日志的采样率，取值范围[0,1]，0表示关闭，仅对注册了Sampling中间件的路由生效
+// 可在节点运行期间调用
+func (r *Router) SetRouteSampling(route int32, rate float64) error {
+	entity, ok := r.routes[route]
+	if !ok {
+		return errors.ErrNotFoundRoute
+	}
+
+	if rate < 0 || rate > 1 || math.IsNaN(rate) {
+		return errors.ErrInvalidArgument
+	}
+
+	entity.sampling.Store(math.Float64bits(rate))
+
+	return nil
+}
+
+// RouteSampling 获取路由请求与响应日志的采样率
+func (r *Router) RouteSampling(route int32) float64 {
+	entity, ok := r.routes[route]
+	if !ok {
+		return 0
+	}
+
+	return math.Float64frombits(entity.sampling.Load())
+}
+
+// SamplingHandler 路由采样率设置处理器，可注册到调试组件中以便在运行时开启或关闭，仅支持POST请求
+// 例如：POST /debug/sampling?route=1&rate=0.1
+func (r *Router) SamplingHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := req.URL.Query()
+
+		if !query.Has("route") || !query.Has("rate") {
+			http.Error(w, errors.ErrInvalidArgument.Error(), http.StatusBadRequest)
+			return
+		}
+
+		route, rate := xconv.Int32(query.Get("route")), xconv.Float64(query.Get("rate"))
+
+		if err := r.SetRouteSampling(route, rate); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		log.Infof("route sampling changed, route: %d rate: %v", route, rate)
+
+		_, _ = w.Write([]byte("ok"))
+	}
+}
+
+// Sampling 路由日志采样中间件，按路由采样率记录解析后的请求与回复的响应，输出前经过脱敏处理器处理
+// 采样率默认为0即不记录，可通过SetRouteSampling或SamplingHandler在运行期间调整
+func Sampling() MiddlewareHandler {
+	return func(middleware *Middleware, ctx Context) {
+		entity, ok := ctx.Proxy().node.router.routes[ctx.Route()]
+		if !ok || !entity.sample() {
+			middleware.Next(ctx)
+			return
+		}
+
+		middleware.Next(&samplingContext{requestContext: ctx})
+	}
+}
+
+type samplingContext struct {
+	requestContext
+}
+
+// Parse 解析消息，并输出采样日志
+func (c *samplingContext) Parse(v interface{}) error {
+	if err := c.requestContext.Parse(v); err != nil {
+		return err
+	}
+
+	c.logSampled("request", v)
+
+	return nil
+}
+
+// Reply 回复消息，并输出采样日志
+func (c *samplingContext) Reply(message *cluster.Message) error {
+	if message != nil {
+		c.logSampled("response", message.Data)
+	}
+
+	return c.requestContext.Reply(message)
+}
+
+// Response 响应消息，并输出采样日志
+func (c *samplingContext) Response(message interface{}) error {
+	return c.Reply(&cluster.Message{Route: c.Route(), Seq: c.Seq(), Data: message})
+}
+
+// Clone 克隆Context
+func (c *samplingContext) Clone() Context {
+	return &samplingContext{requestContext: c.requestContext.Clone()}
+}
+
+// 获取被包装的Context
+func (c *samplingContext) unwrap() Context {
+	return c.requestContext
+}
+
+// 检测本次请求是否命中采样
+func (e *routeEntity) sample() bool {
+	rate := math.Float64frombits(e.sampling.Load())
+
+	return rate > 0 && xrand.Lucky(rate, 1)
+}
+
+// 输出采样日志
+func (c *samplingContext) logSampled(kind string, v any) {
+	if redactor := c.Proxy().node.opts.redactor; redactor != nil {
+		v = redactor(c.Route(), v)
+	}
+
+	log.Infof("route sampling, kind: %s route: %d seq: %d uid: %d gid: %s nid: %s data: %+v", kind, c.Route(), c.Seq(), c.UID(), c.GID(), c.NID(), v)
+}
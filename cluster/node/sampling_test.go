@@ -0,0 +1,153 @@
+package node_test
+
+import (
+	"github.com/dobyte/due/v2/cluster/node"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/packet"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type sampled struct {
+	route int32
+	data  any
+}
+
+func TestSampling(t *testing.T) {
+	c := newTestCluster(t)
+
+	records := make(chan sampled, 10)
+
+	n := c.startNode(t, func(n *node.Node) {
+		handler := func(ctx node.Context) {
+			req := make(map[string]string)
+			if err := ctx.Parse(&req); err != nil {
+				t.Error(err)
+				return
+			}
+
+			_ = ctx.Response(req["name"])
+		}
+
+		n.Proxy().Router().AddRouteHandler(1, false, handler, node.Sampling())
+		n.Proxy().Router().AddRouteHandler(2, false, handler)
+	}, node.WithRedactor(func(route int32, v any) any {
+		records <- sampled{route: route, data: v}
+		return "***"
+	}))
+
+	router := n.Proxy().Router()
+
+	if err := router.SetRouteSampling(3, 1); !errors.Is(err, errors.ErrNotFoundRoute) {
+		t.Fatalf("expected ErrNotFoundRoute, got %v", err)
+	}
+
+	if err := router.SetRouteSampling(1, 1.5); !errors.Is(err, errors.ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		c.deliver(t, 1, 10, &packet.Message{Seq: 1, Route: 1, Buffer: []byte(`{"name":"due"}`)})
+		c.gate.expectPush(t)
+
+		expectNoSampled(t, records)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		if err := router.SetRouteSampling(1, 1); err != nil {
+			t.Fatal(err)
+		}
+
+		c.deliver(t, 1, 10, &packet.Message{Seq: 2, Route: 1, Buffer: []byte(`{"name":"due"}`)})
+
+		// 脱敏处理器仅作用于日志，不影响实际响应
+		if p := c.gate.expectPush(t); string(p.message.Buffer) != `"due"` {
+			t.Fatalf("unexpected reply: %s", p.message.Buffer)
+		}
+
+		req := expectSampled(t, records)
+		if req.route != 1 || (*req.data.(*map[string]string))["name"] != "due" {
+			t.Fatalf("unexpected request record: %+v", req)
+		}
+
+		if res := expectSampled(t, records); res.route != 1 || res.data != "due" {
+			t.Fatalf("unexpected response record: %+v", res)
+		}
+	})
+
+	t.Run("without middleware", func(t *testing.T) {
+		if err := router.SetRouteSampling(2, 1); err != nil {
+			t.Fatal(err)
+		}
+
+		c.deliver(t, 1, 10, &packet.Message{Seq: 3, Route: 2, Buffer: []byte(`{"name":"due"}`)})
+		c.gate.expectPush(t)
+
+		expectNoSampled(t, records)
+	})
+}
+
+func TestRouter_SamplingHandler(t *testing.T) {
+	c := newTestCluster(t)
+
+	n := c.startNode(t, func(n *node.Node) {
+		n.Proxy().Router().AddRouteHandler(1, false, func(ctx node.Context) {}, node.Sampling())
+	})
+
+	router := n.Proxy().Router()
+	handler := router.SamplingHandler()
+
+	// 查询请求不修改采样率
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/debug/sampling?route=1&rate=0.5", nil))
+
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != http.MethodPost {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+
+	if rate := router.RouteSampling(1); rate != 0 {
+		t.Fatalf("unexpected rate: %v", rate)
+	}
+
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodPost, "/debug/sampling?route=1", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodPost, "/debug/sampling?route=1&rate=0.5", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+
+	if rate := router.RouteSampling(1); rate != 0.5 {
+		t.Fatalf("unexpected rate: %v", rate)
+	}
+}
+
+func expectSampled(t *testing.T, records chan sampled) sampled {
+	t.Helper()
+
+	select {
+	case r := <-records:
+		return r
+	case <-time.After(3 * time.Second):
+		t.Fatal("expected sampled record, got none")
+		return sampled{}
+	}
+}
+
+func expectNoSampled(t *testing.T, records chan sampled) {
+	t.Helper()
+
+	select {
+	case r := <-records:
+		t.Fatalf("unexpected sampled record: %+v", r)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,129 @@
+package node
+
+import (
+	"github.com/dobyte/due/v2/codes"
+	"github.com/dobyte/due/v2/core/limiter"
+	"github.com/dobyte/due/v2/log"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	UnknownRouteIgnore  UnknownRoutePolicy = "ignore"  // 忽略消息并打印日志
+	UnknownRouteRespond UnknownRoutePolicy = "respond" // 请求类消息（序列号不为0）响应codes.NotFound，可通过未知路由处理器自定义响应，推送类消息直接丢弃
+	UnknownRouteClose   UnknownRoutePolicy = "close"   // 关闭来自网关的客户端连接
+)
+
+// 未知路由统计的最大路由数，超出后新出现的路由仅计入总数，避免恶意路由号无限占用内存
+const maxUnknownRouteStats = 256
+
+const (
+	unknownRouteLogBurst = 10 // 未知路由日志的突发条数
+	unknownRouteLogRate  = 1  // 未知路由日志每秒打印的条数
+)
+
+// UnknownRoutePolicy 未知路由处理策略，节点收到未注册处理器且未设置默认路由处理器的路由消息时的处理方式
+type UnknownRoutePolicy string
+
+// UnknownRouteHandler 未知路由处理器，code固定为codes.NotFound，可用于自定义向客户端响应的未知路由错误
+type UnknownRouteHandler func(ctx Context, code *codes.Code)
+
+// UnknownRouteStat 未知路由统计
+type UnknownRouteStat struct {
+	Total  int64           `json:"total"`  // 收到未知路由消息的总次数
+	Routes map[int32]int64 `json:"routes"` // 各未知路由收到消息的次数
+}
+
+type unknownRoutes struct {
+	total      atomic.Int64
+	count      atomic.Int32
+	routes     sync.Map // route -> *atomic.Int64
+	limiter    *limiter.Limiter
+	suppressed atomic.Int64
+}
+
+func newUnknownRoutes() *unknownRoutes {
+	return &unknownRoutes{limiter: limiter.NewLimiter(unknownRouteLogBurst, unknownRouteLogRate)}
+}
+
+// 记录未知路由
+func (u *unknownRoutes) record(route int32) {
+	u.total.Add(1)
+
+	if v, ok := u.routes.Load(route); ok {
+		v.(*atomic.Int64).Add(1)
+		return
+	}
+
+	if u.count.Load() >= maxUnknownRouteStats {
+		return
+	}
+
+	v, loaded := u.routes.LoadOrStore(route, &atomic.Int64{})
+	if !loaded {
+		u.count.Add(1)
+	}
+
+	v.(*atomic.Int64).Add(1)
+}
+
+// 获取未知路由统计
+func (u *unknownRoutes) stat() UnknownRouteStat {
+	stat := UnknownRouteStat{Total: u.total.Load(), Routes: make(map[int32]int64)}
+
+	u.routes.Range(func(key, value any) bool {
+		stat.Routes[key.(int32)] = value.(*atomic.Int64).Load()
+		return true
+	})
+
+	return stat
+}
+
+// 限流打印未知路由日志，避免异常客户端持续发送未知路由时刷屏，被丢弃的日志条数在下次打印时附带
+func (u *unknownRoutes) warnf(format string, args ...any) {
+	if !u.limiter.Allow() {
+		u.suppressed.Add(1)
+		return
+	}
+
+	if n := u.suppressed.Swap(0); n > 0 {
+		log.Warnf(format+", suppressed: %d", append(args, n)...)
+	} else {
+		log.Warnf(format, args...)
+	}
+}
+
+// 处理未知路由消息
+func (r *Router) handleUnknown(req *request) {
+	r.unknowns.record(req.message.Route)
+
+	opts := r.node.opts
+
+	switch opts.unknownRoutePolicy {
+	case UnknownRouteRespond:
+		if req.message.Seq == 0 {
+			return
+		}
+
+		if opts.unknownRouteHandler != nil {
+			opts.unknownRouteHandler(req, codes.NotFound)
+			return
+		}
+
+		r.unknowns.warnf("message routing does not register handler function, respond not found, cid: %d uid: %d route: %v", req.cid, req.uid, req.message.Route)
+
+		if err := req.Response(codes.NotFound.Reply()); err != nil {
+			r.unknowns.warnf("respond unknown route failed, cid: %d uid: %d route: %v err: %v", req.cid, req.uid, req.message.Route, err)
+		}
+	case UnknownRouteClose:
+		r.unknowns.warnf("message routing does not register handler function, close connection, cid: %d uid: %d route: %v", req.cid, req.uid, req.message.Route)
+
+		if req.gid != "" {
+			if err := req.Disconnect(); err != nil {
+				log.Errorf("disconnect connection failed, cid: %d uid: %d err: %v", req.cid, req.uid, err)
+			}
+		}
+	default:
+		r.unknowns.warnf("message routing does not register handler function, route: %v", req.message.Route)
+	}
+}
@@ -0,0 +1,99 @@
+package node_test
+
+import (
+	"encoding/json"
+	"github.com/dobyte/due/v2/cluster/node"
+	"github.com/dobyte/due/v2/codes"
+	"github.com/dobyte/due/v2/packet"
+	"testing"
+	"time"
+)
+
+func TestNode_UnknownRouteRespond(t *testing.T) {
+	c := newTestCluster(t)
+
+	n := c.startNode(t, func(n *node.Node) {
+		n.Proxy().Router().AddRouteHandler(1, false, func(ctx node.Context) {})
+	}, node.WithUnknownRoutePolicy(node.UnknownRouteRespond))
+
+	c.deliver(t, 1, 10, &packet.Message{Seq: 1, Route: 100})
+
+	p := c.gate.expectPush(t)
+
+	if p.target != 1 || p.message.Seq != 1 || p.message.Route != 100 {
+		t.Fatalf("unexpected reply: target = %d seq = %d route = %d", p.target, p.message.Seq, p.message.Route)
+	}
+
+	reply := &codes.Reply{}
+	if err := json.Unmarshal(p.message.Buffer, reply); err != nil {
+		t.Fatal(err)
+	}
+
+	if reply.Code != codes.NotFound.Code() {
+		t.Fatalf("unexpected reply code: %d", reply.Code)
+	}
+
+	// 推送类消息不响应
+	c.deliver(t, 1, 10, &packet.Message{Seq: 0, Route: 100})
+
+	c.gate.expectNoPush(t)
+
+	if stat := n.Proxy().Router().UnknownRouteStat(); stat.Total != 2 || stat.Routes[100] != 2 {
+		t.Fatalf("unexpected unknown route stat: %+v", stat)
+	}
+}
+
+func TestNode_UnknownRouteHandler(t *testing.T) {
+	c := newTestCluster(t)
+
+	handled := make(chan int, 1)
+
+	c.startNode(t, nil,
+		node.WithUnknownRoutePolicy(node.UnknownRouteRespond),
+		node.WithUnknownRouteHandler(func(ctx node.Context, code *codes.Code) {
+			handled <- code.Code()
+		}),
+	)
+
+	c.deliver(t, 1, 10, &packet.Message{Seq: 1, Route: 100})
+
+	select {
+	case code := <-handled:
+		if code != codes.NotFound.Code() {
+			t.Fatalf("unexpected unknown route code: %d", code)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("unknown route handler not called")
+	}
+
+	c.gate.expectNoPush(t)
+}
+
+func TestNode_UnknownRouteClose(t *testing.T) {
+	c := newTestCluster(t)
+
+	c.startNode(t, nil, node.WithUnknownRoutePolicy(node.UnknownRouteClose))
+
+	c.deliver(t, 5, 10, &packet.Message{Seq: 1, Route: 100})
+
+	select {
+	case cid := <-c.gate.disconnects:
+		if cid != 5 {
+			t.Fatalf("unexpected disconnect target: %d", cid)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("connection not disconnected")
+	}
+
+	c.gate.expectNoPush(t)
+}
+
+func TestNode_UnknownRouteIgnore(t *testing.T) {
+	c := newTestCluster(t)
+
+	c.startNode(t, nil)
+
+	c.deliver(t, 1, 10, &packet.Message{Seq: 1, Route: 100})
+
+	c.gate.expectNoPush(t)
+}
@@ -19,6 +19,7 @@ var (
 	IllegalInvoke    = NewCode(8, "illegal invoke")
 	IllegalRequest   = NewCode(9, "illegal request")
 	TooManyRequests  = NewCode(10, "too many requests")
+	Forbidden        = NewCode(11, "forbidden")
 )
 
 type Code struct {
@@ -82,6 +83,11 @@ func (c *Code) Format(s fmt.State, verb rune) {
 	}
 }
 
+// Reply 转错误码响应消息
+func (c *Code) Reply() *Reply {
+	return &Reply{Code: c.code, Message: c.message}
+}
+
 // Err 转错误消息
 func (c *Code) Err() error {
 	if c.code == OK.Code() {
@@ -91,6 +97,12 @@ func (c *Code) Err() error {
 	return &Error{code: c}
 }
 
+// Reply 错误码响应消息，未自定义处理器时框架以此消息向客户端响应错误码
+type Reply struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
 type Error struct {
 	code *Code
 }
@@ -0,0 +1,211 @@
+package debug
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"github.com/dobyte/due/v2/component"
+	"github.com/dobyte/due/v2/core/info"
+	xnet "github.com/dobyte/due/v2/core/net"
+	"github.com/dobyte/due/v2/encoding/json"
+	"github.com/dobyte/due/v2/log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	rtdebug "runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ component.Component = &Debug{}
+
+// Collector 统计收集器，返回值将以JSON格式输出
+// 例如：gate.ConnCount、node.Proxy().Router().Stats
+type Collector func() any
+
+type Debug struct {
+	component.Base
+	opts       *options
+	server     *http.Server
+	rw         sync.RWMutex
+	collectors map[string]Collector
+	handlers   map[string]http.Handler
+}
+
+func NewDebug(opts ...Option) *Debug {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &Debug{opts: o, collectors: o.collectors, handlers: make(map[string]http.Handler)}
+}
+
+// Name 组件名称
+func (*Debug) Name() string {
+	return "debug"
+}
+
+// AddCollector 添加统计收集器
+func (d *Debug) AddCollector(name string, collector Collector) {
+	d.rw.Lock()
+	d.collectors[name] = collector
+	d.rw.Unlock()
+}
+
+// Handle 注册自定义调试处理器，需在组件启动前调用
+// 例如：node.Proxy().Router().SamplingHandler()
+func (d *Debug) Handle(pattern string, handler http.Handler) {
+	d.rw.Lock()
+	d.handlers[pattern] = handler
+	d.rw.Unlock()
+}
+
+// Start 启动组件
+func (d *Debug) Start() {
+	if d.opts.addr == "" {
+		return
+	}
+
+	addr := d.opts.addr
+
+	// 未设置访问令牌时仅监听本地回环地址，避免调试及日志级别设置接口暴露给外部网络
+	if d.opts.token == "" {
+		loopbackAddr, err := loopback(addr)
+		if err != nil {
+			log.Fatalf("debug addr parse failed: %v", err)
+		}
+
+		if loopbackAddr != addr {
+			log.Warnf("debug token is not set, debug server listens on loopback address only")
+		}
+
+		addr = loopbackAddr
+	}
+
+	listenAddr, exposeAddr, err := xnet.ParseAddr(addr)
+	if err != nil {
+		log.Fatalf("debug addr parse failed: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc(d.opts.statsPath, d.stats)
+	mux.HandleFunc(defaultLogPath, log.OutputHandler())
+
+	d.rw.RLock()
+	for pattern, handler := range d.handlers {
+		mux.Handle(pattern, handler)
+	}
+	d.rw.RUnlock()
+
+	d.server = &http.Server{Addr: listenAddr, Handler: d.auth(mux)}
+
+	go func() {
+		if err := d.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("debug server start failed: %v", err)
+		}
+	}()
+
+	info.PrintBoxInfo("Debug",
+		fmt.Sprintf("PProf: http://%s/debug/pprof/", exposeAddr),
+		fmt.Sprintf("Stats: http://%s%s", exposeAddr, d.opts.statsPath),
+		fmt.Sprintf("Log: http://%s%s", exposeAddr, defaultLogPath),
+	)
+}
+
+// Destroy 销毁组件
+func (d *Debug) Destroy() {
+	if d.server == nil {
+		return
+	}
+
+	if err := d.server.Close(); err != nil {
+		log.Warnf("debug server close failed: %v", err)
+	}
+}
+
+// 校验访问令牌，未设置访问令牌时服务仅监听本地回环地址，不做校验
+func (d *Debug) auth(next http.Handler) http.Handler {
+	if d.opts.token == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		}
+
+		if subtle.ConstantTimeCompare([]byte(token), []byte(d.opts.token)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte("unauthorized"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// 将监听地址转换为本地回环地址
+func loopback(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+
+	if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+		return addr, nil
+	}
+
+	return net.JoinHostPort("127.0.0.1", port), nil
+}
+
+// 输出运行时统计
+func (d *Debug) stats(w http.ResponseWriter, _ *http.Request) {
+	var (
+		gc  rtdebug.GCStats
+		mem runtime.MemStats
+	)
+
+	rtdebug.ReadGCStats(&gc)
+	runtime.ReadMemStats(&mem)
+
+	stats := map[string]any{
+		"goroutines": runtime.NumGoroutine(),
+		"gc": map[string]any{
+			"num":        gc.NumGC,
+			"pauseTotal": gc.PauseTotal.String(),
+			"lastGC":     gc.LastGC.Format(time.RFC3339),
+		},
+		"memory": map[string]any{
+			"alloc":       mem.Alloc,
+			"sys":         mem.Sys,
+			"heapAlloc":   mem.HeapAlloc,
+			"heapInuse":   mem.HeapInuse,
+			"heapObjects": mem.HeapObjects,
+		},
+	}
+
+	d.rw.RLock()
+	for name, collector := range d.collectors {
+		stats[name] = collector()
+	}
+	d.rw.RUnlock()
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
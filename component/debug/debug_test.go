@@ -0,0 +1,122 @@
+package debug
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebug_Auth(t *testing.T) {
+	addr := freeAddr(t)
+
+	d := NewDebug(WithAddr(addr), WithToken("secret"))
+	d.Start()
+	defer d.Destroy()
+
+	url := "http://" + addr + defaultStatsPath
+
+	if code, _ := request(t, url, ""); code != http.StatusUnauthorized {
+		t.Fatalf("unexpected code without token: %d", code)
+	}
+
+	if code, _ := request(t, url, "wrong"); code != http.StatusUnauthorized {
+		t.Fatalf("unexpected code with wrong token: %d", code)
+	}
+
+	if code, body := request(t, url, "secret"); code != http.StatusOK || !strings.Contains(body, "goroutines") {
+		t.Fatalf("unexpected response with token: %d %s", code, body)
+	}
+
+	if code, _ := request(t, url+"?token=secret", ""); code != http.StatusOK {
+		t.Fatalf("unexpected code with query token: %d", code)
+	}
+}
+
+func TestDebug_EmptyToken(t *testing.T) {
+	_, port, err := net.SplitHostPort(freeAddr(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 未设置访问令牌时仅监听本地回环地址
+	d := NewDebug(WithAddr(":" + port))
+	d.Start()
+	defer d.Destroy()
+
+	if d.server.Addr != net.JoinHostPort("127.0.0.1", port) {
+		t.Fatalf("unexpected listen addr: %s", d.server.Addr)
+	}
+
+	if code, _ := request(t, "http://127.0.0.1:"+port+defaultStatsPath, ""); code != http.StatusOK {
+		t.Fatalf("unexpected code: %d", code)
+	}
+}
+
+func TestLoopback(t *testing.T) {
+	tests := map[string]string{
+		"0.0.0.0:8080":   "127.0.0.1:8080",
+		"10.0.0.1:8080":  "127.0.0.1:8080",
+		"127.0.0.1:8080": "127.0.0.1:8080",
+		"localhost:8080": "localhost:8080",
+		"[::1]:8080":     "[::1]:8080",
+	}
+
+	for addr, expected := range tests {
+		if actual, err := loopback(addr); err != nil || actual != expected {
+			t.Fatalf("unexpected loopback addr of %s: %s %v", addr, actual, err)
+		}
+	}
+}
+
+// 获取空闲的本地地址
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	return addr
+}
+
+// 发起请求，等待服务启动
+func request(t *testing.T, url, token string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+
+	for {
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			if time.Now().After(deadline) {
+				t.Fatal(err)
+			}
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return resp.StatusCode, string(body)
+	}
+}
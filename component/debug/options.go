@@ -0,0 +1,61 @@
+package debug
+
+import (
+	"github.com/dobyte/due/v2/etc"
+)
+
+const (
+	defaultStatsPath = "/debug/stats" // 运行时统计路径
+	defaultLogPath   = "/debug/log"   // 日志级别设置路径
+)
+
+const (
+	defaultAddrKey      = "etc.debug.addr"
+	defaultTokenKey     = "etc.debug.token"
+	defaultStatsPathKey = "etc.debug.statsPath"
+)
+
+type Option func(o *options)
+
+type options struct {
+	addr       string               // 监听地址，为空时不启用
+	token      string               // 访问令牌，为空时不校验且仅监听本地回环地址
+	statsPath  string               // 运行时统计路径
+	collectors map[string]Collector // 统计收集器
+}
+
+func defaultOptions() *options {
+	opts := &options{
+		addr:       etc.Get(defaultAddrKey).String(),
+		token:      etc.Get(defaultTokenKey).String(),
+		statsPath:  defaultStatsPath,
+		collectors: make(map[string]Collector),
+	}
+
+	if path := etc.Get(defaultStatsPathKey).String(); path != "" {
+		opts.statsPath = path
+	}
+
+	return opts
+}
+
+// WithAddr 设置监听地址，为空时不启用
+func WithAddr(addr string) Option {
+	return func(o *options) { o.addr = addr }
+}
+
+// WithToken 设置访问令牌，请求需携带Authorization: Bearer <token>请求头或token查询参数
+// 未设置访问令牌时调试服务仅监听本地回环地址
+func WithToken(token string) Option {
+	return func(o *options) { o.token = token }
+}
+
+// WithStatsPath 设置运行时统计路径
+func WithStatsPath(path string) Option {
+	return func(o *options) { o.statsPath = path }
+}
+
+// WithCollector 设置统计收集器
+func WithCollector(name string, collector Collector) Option {
+	return func(o *options) { o.collectors[name] = collector }
+}
@@ -0,0 +1,140 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"github.com/dobyte/due/v2/component"
+	"github.com/dobyte/due/v2/core/info"
+	xnet "github.com/dobyte/due/v2/core/net"
+	"github.com/dobyte/due/v2/log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var _ component.Component = &Health{}
+
+// Checker 就绪检测器，返回错误时视为未就绪
+type Checker func(ctx context.Context) error
+
+type Health struct {
+	component.Base
+	opts     *options
+	ready    atomic.Bool
+	server   *http.Server
+	rw       sync.RWMutex
+	checkers map[string]Checker
+}
+
+func NewHealth(opts ...Option) *Health {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &Health{opts: o, checkers: o.checkers}
+}
+
+// Name 组件名称
+func (*Health) Name() string {
+	return "health"
+}
+
+// AddChecker 添加就绪检测器
+func (h *Health) AddChecker(name string, checker Checker) {
+	h.rw.Lock()
+	h.checkers[name] = checker
+	h.rw.Unlock()
+}
+
+// Start 启动组件
+func (h *Health) Start() {
+	listenAddr, exposeAddr, err := xnet.ParseAddr(h.opts.addr)
+	if err != nil {
+		log.Fatalf("health addr parse failed: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(h.opts.livenessPath, h.liveness)
+	mux.HandleFunc(h.opts.readinessPath, h.readiness)
+
+	h.server = &http.Server{Addr: listenAddr, Handler: mux}
+
+	go func() {
+		if err := h.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("health server start failed: %v", err)
+		}
+	}()
+
+	h.ready.Store(true)
+
+	info.PrintBoxInfo("Health",
+		fmt.Sprintf("Liveness: http://%s%s", exposeAddr, h.opts.livenessPath),
+		fmt.Sprintf("Readiness: http://%s%s", exposeAddr, h.opts.readinessPath),
+	)
+}
+
+// Close 关闭组件，就绪探针将立即返回失败
+func (h *Health) Close() {
+	h.ready.Store(false)
+}
+
+// Destroy 销毁组件
+func (h *Health) Destroy() {
+	if h.server == nil {
+		return
+	}
+
+	if err := h.server.Close(); err != nil {
+		log.Warnf("health server close failed: %v", err)
+	}
+}
+
+// 存活探针
+func (h *Health) liveness(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+// 就绪探针
+func (h *Health) readiness(w http.ResponseWriter, r *http.Request) {
+	if !h.ready.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("shutting down"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.opts.timeout)
+	defer cancel()
+
+	h.rw.RLock()
+	names := make([]string, 0, len(h.checkers))
+	for name := range h.checkers {
+		names = append(names, name)
+	}
+	h.rw.RUnlock()
+
+	sort.Strings(names)
+
+	failures := make([]string, 0)
+	for _, name := range names {
+		h.rw.RLock()
+		checker := h.checkers[name]
+		h.rw.RUnlock()
+
+		if err := checker(ctx); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(strings.Join(failures, "\n")))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
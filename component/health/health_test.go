@@ -0,0 +1,98 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"github.com/dobyte/due/v2/component/health"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealth(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	var failed atomic.Bool
+
+	h := health.NewHealth(
+		health.WithAddr(addr),
+		health.WithChecker("db", func(ctx context.Context) error {
+			if failed.Load() {
+				return errors.New("db unavailable")
+			}
+			return nil
+		}),
+	)
+	h.Init()
+	h.Start()
+	defer h.Destroy()
+
+	if code, _ := probe(t, "http://"+addr+"/healthz"); code != http.StatusOK {
+		t.Fatalf("unexpected liveness code: %d", code)
+	}
+
+	if code, _ := probe(t, "http://"+addr+"/readyz"); code != http.StatusOK {
+		t.Fatalf("unexpected readiness code: %d", code)
+	}
+
+	// 任一就绪检测器失败时未就绪
+	failed.Store(true)
+
+	if code, body := probe(t, "http://"+addr+"/readyz"); code != http.StatusServiceUnavailable || body != "db: db unavailable" {
+		t.Fatalf("unexpected readiness: %d %s", code, body)
+	}
+
+	failed.Store(false)
+
+	h.AddChecker("cache", func(ctx context.Context) error { return errors.New("cache unavailable") })
+
+	if code, body := probe(t, "http://"+addr+"/readyz"); code != http.StatusServiceUnavailable || body != "cache: cache unavailable" {
+		t.Fatalf("unexpected readiness: %d %s", code, body)
+	}
+
+	// 关闭后就绪探针立即失败，存活探针不受影响
+	h.Close()
+
+	if code, _ := probe(t, "http://"+addr+"/readyz"); code != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected readiness code after close: %d", code)
+	}
+
+	if code, _ := probe(t, "http://"+addr+"/healthz"); code != http.StatusOK {
+		t.Fatalf("unexpected liveness code after close: %d", code)
+	}
+}
+
+// 请求探针，等待服务启动
+func probe(t *testing.T, url string) (int, string) {
+	t.Helper()
+
+	deadline := time.Now().Add(3 * time.Second)
+
+	for {
+		resp, err := http.Get(url)
+		if err != nil {
+			if time.Now().After(deadline) {
+				t.Fatal(err)
+			}
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return resp.StatusCode, string(body)
+	}
+}
@@ -0,0 +1,83 @@
+package health
+
+import (
+	"github.com/dobyte/due/v2/etc"
+	"time"
+)
+
+const (
+	defaultAddr          = ":0"       // 监听地址
+	defaultLivenessPath  = "/healthz" // 存活探针路径
+	defaultReadinessPath = "/readyz"  // 就绪探针路径
+	defaultTimeout       = 3 * time.Second
+)
+
+const (
+	defaultAddrKey          = "etc.health.addr"
+	defaultLivenessPathKey  = "etc.health.livenessPath"
+	defaultReadinessPathKey = "etc.health.readinessPath"
+	defaultTimeoutKey       = "etc.health.timeout"
+)
+
+type Option func(o *options)
+
+type options struct {
+	addr          string             // 监听地址
+	livenessPath  string             // 存活探针路径
+	readinessPath string             // 就绪探针路径
+	timeout       time.Duration      // 就绪检测超时时间
+	checkers      map[string]Checker // 就绪检测器
+}
+
+func defaultOptions() *options {
+	opts := &options{
+		addr:          defaultAddr,
+		livenessPath:  defaultLivenessPath,
+		readinessPath: defaultReadinessPath,
+		timeout:       defaultTimeout,
+		checkers:      make(map[string]Checker),
+	}
+
+	if addr := etc.Get(defaultAddrKey).String(); addr != "" {
+		opts.addr = addr
+	}
+
+	if path := etc.Get(defaultLivenessPathKey).String(); path != "" {
+		opts.livenessPath = path
+	}
+
+	if path := etc.Get(defaultReadinessPathKey).String(); path != "" {
+		opts.readinessPath = path
+	}
+
+	if timeout := etc.Get(defaultTimeoutKey).Duration(); timeout > 0 {
+		opts.timeout = timeout
+	}
+
+	return opts
+}
+
+// WithAddr 设置监听地址
+func WithAddr(addr string) Option {
+	return func(o *options) { o.addr = addr }
+}
+
+// WithLivenessPath 设置存活探针路径
+func WithLivenessPath(path string) Option {
+	return func(o *options) { o.livenessPath = path }
+}
+
+// WithReadinessPath 设置就绪探针路径
+func WithReadinessPath(path string) Option {
+	return func(o *options) { o.readinessPath = path }
+}
+
+// WithTimeout 设置就绪检测超时时间
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.timeout = timeout }
+}
+
+// WithChecker 设置就绪检测器
+func WithChecker(name string, checker Checker) Option {
+	return func(o *options) { o.checkers[name] = checker }
+}
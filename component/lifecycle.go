@@ -0,0 +1,56 @@
+package component
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/log"
+	"time"
+)
+
+const defaultStopTimeout = 5 * time.Second
+
+type Lifecycle interface {
+	// Start 启动
+	Start(ctx context.Context) error
+	// Stop 停止
+	Stop(ctx context.Context) error
+}
+
+type lifecycle struct {
+	Base
+	name      string
+	lifecycle Lifecycle
+	timeout   time.Duration
+}
+
+// Wrap 将生命周期对象包装为组件，组件启动时调用Start，组件销毁时调用Stop
+func Wrap(name string, l Lifecycle, stopTimeout ...time.Duration) Component {
+	c := &lifecycle{name: name, lifecycle: l, timeout: defaultStopTimeout}
+
+	if len(stopTimeout) > 0 && stopTimeout[0] > 0 {
+		c.timeout = stopTimeout[0]
+	}
+
+	return c
+}
+
+// Name 组件名称
+func (c *lifecycle) Name() string {
+	return c.name
+}
+
+// Start 启动组件
+func (c *lifecycle) Start() {
+	if err := c.lifecycle.Start(context.Background()); err != nil {
+		log.Fatalf("%s component start failed: %v", c.name, err)
+	}
+}
+
+// Destroy 销毁组件
+func (c *lifecycle) Destroy() {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if err := c.lifecycle.Stop(ctx); err != nil {
+		log.Errorf("%s component stop failed: %v", c.name, err)
+	}
+}
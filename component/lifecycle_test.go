@@ -0,0 +1,58 @@
+package component_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/component"
+	"testing"
+	"time"
+)
+
+type lifecycle struct {
+	started  bool
+	stopped  bool
+	deadline time.Duration
+}
+
+func (l *lifecycle) Start(ctx context.Context) error {
+	l.started = true
+	return nil
+}
+
+func (l *lifecycle) Stop(ctx context.Context) error {
+	l.stopped = true
+
+	if deadline, ok := ctx.Deadline(); ok {
+		l.deadline = time.Until(deadline)
+	}
+
+	return nil
+}
+
+func TestWrap(t *testing.T) {
+	l := &lifecycle{}
+	c := component.Wrap("worker", l, time.Minute)
+
+	if c.Name() != "worker" {
+		t.Fatalf("unexpected name: %s", c.Name())
+	}
+
+	c.Init()
+	c.Start()
+
+	if !l.started || l.stopped {
+		t.Fatalf("unexpected state after start, started: %v stopped: %v", l.started, l.stopped)
+	}
+
+	// 关闭时不停止，销毁时以停止超时时间调用Stop
+	c.Close()
+
+	if l.stopped {
+		t.Fatal("stopped on close")
+	}
+
+	c.Destroy()
+
+	if !l.stopped || l.deadline <= 50*time.Second || l.deadline > time.Minute {
+		t.Fatalf("unexpected state after destroy, stopped: %v deadline: %v", l.stopped, l.deadline)
+	}
+}
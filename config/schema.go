@@ -0,0 +1,261 @@
+package config
+
+import (
+	"fmt"
+	"github.com/dobyte/due/v2/core/value"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/utils/xconv"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var zeroDuration = regexp.MustCompile(`^-?0+(\.0+)?(ns|us|µs|ms|s|m|h|d)?$`)
+
+type Type int
+
+const (
+	TypeAny      Type = iota // 任意类型
+	TypeString               // 字符串
+	TypeInt                  // 整数
+	TypeFloat                // 浮点数
+	TypeBool                 // 布尔值
+	TypeDuration             // 时间间隔，支持数字与1s、5m、1d等格式
+	TypeStrings              // 字符串数组
+	TypeMap                  // 键值对
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeString:
+		return "string"
+	case TypeInt:
+		return "int"
+	case TypeFloat:
+		return "float"
+	case TypeBool:
+		return "bool"
+	case TypeDuration:
+		return "duration"
+	case TypeStrings:
+		return "strings"
+	case TypeMap:
+		return "map"
+	default:
+		return "any"
+	}
+}
+
+// Field 配置项声明
+type Field struct {
+	Key      string      // 配置项完整路径，例如：etc.registry.consul.addr
+	Type     Type        // 配置项类型
+	Default  interface{} // 默认值，配置项缺失时由Schema.Get返回
+	Required bool        // 是否必填
+}
+
+// Schema 模块配置声明
+type Schema struct {
+	Name   string   // 模块名称
+	Prefix string   // 模块配置前缀，例如：etc.registry.consul
+	Strict bool     // 是否为严格模式，严格模式下前缀下出现未声明的配置项时视为无效配置，可用于发现拼写错误
+	Fields []*Field // 配置项
+}
+
+// Get 获取配置值，配置项缺失时返回声明的默认值
+func (s *Schema) Get(configurator Configurator, key string) value.Value {
+	for _, field := range s.Fields {
+		if field.Key == key {
+			return configurator.Get(key, field.Default)
+		}
+	}
+
+	return configurator.Get(key)
+}
+
+// Validate 校验配置
+func (s *Schema) Validate(configurator Configurator) []*Issue {
+	issues := make([]*Issue, 0)
+	declared := make(map[string]struct{}, len(s.Fields))
+
+	for _, field := range s.Fields {
+		declared[field.Key] = struct{}{}
+
+		if !configurator.Has(field.Key) {
+			if field.Required {
+				issues = append(issues, &Issue{Schema: s.Name, Key: field.Key, Reason: "missing required key"})
+			}
+			continue
+		}
+
+		if !checkType(field.Type, configurator.Get(field.Key).Value()) {
+			issues = append(issues, &Issue{Schema: s.Name, Key: field.Key, Reason: fmt.Sprintf("expect %s type", field.Type)})
+		}
+	}
+
+	if s.Strict && s.Prefix != "" {
+		keys := make([]string, 0)
+
+		for k := range configurator.Get(s.Prefix).Map() {
+			if _, ok := declared[s.Prefix+"."+k]; !ok {
+				keys = append(keys, s.Prefix+"."+k)
+			}
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			issues = append(issues, &Issue{Schema: s.Name, Key: key, Reason: "unknown key"})
+		}
+	}
+
+	return issues
+}
+
+// Issue 配置校验问题
+type Issue struct {
+	Schema string // 模块名称
+	Key    string // 配置项
+	Reason string // 原因
+}
+
+func (i *Issue) String() string {
+	return fmt.Sprintf("%s: %s (%s)", i.Key, i.Reason, i.Schema)
+}
+
+// ValidationError 配置校验错误，包含所有未通过校验的配置项
+type ValidationError struct {
+	Issues []*Issue
+}
+
+func (e *ValidationError) Error() string {
+	items := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		items = append(items, issue.String())
+	}
+
+	return errors.ErrInvalidConfig.Error() + ": " + strings.Join(items, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return errors.ErrInvalidConfig
+}
+
+var schemas struct {
+	rw    sync.RWMutex
+	items []*Schema
+}
+
+// RegisterSchema 注册模块配置声明，一般在模块的init函数中调用
+func RegisterSchema(schema ...*Schema) {
+	schemas.rw.Lock()
+	defer schemas.rw.Unlock()
+
+	schemas.items = append(schemas.items, schema...)
+}
+
+// Validate 使用所有已注册的配置声明校验配置，存在问题时返回*ValidationError
+func Validate(configurator Configurator) error {
+	schemas.rw.RLock()
+	defer schemas.rw.RUnlock()
+
+	issues := make([]*Issue, 0)
+	for _, schema := range schemas.items {
+		issues = append(issues, schema.Validate(configurator)...)
+	}
+
+	if len(issues) > 0 {
+		return &ValidationError{Issues: issues}
+	}
+
+	return nil
+}
+
+// 检测配置值类型
+func checkType(t Type, v interface{}) bool {
+	if v == nil {
+		return t == TypeAny
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch t {
+	case TypeString:
+		return isScalar(rv.Kind())
+	case TypeInt:
+		switch {
+		case isInt(rv.Kind()):
+			return true
+		case isFloat(rv.Kind()):
+			return rv.Float() == float64(int64(rv.Float()))
+		case rv.Kind() == reflect.String:
+			_, err := strconv.ParseInt(strings.TrimSpace(rv.String()), 10, 64)
+			return err == nil
+		}
+		return false
+	case TypeFloat:
+		switch {
+		case isInt(rv.Kind()), isFloat(rv.Kind()):
+			return true
+		case rv.Kind() == reflect.String:
+			_, err := strconv.ParseFloat(strings.TrimSpace(rv.String()), 64)
+			return err == nil
+		}
+		return false
+	case TypeBool:
+		switch rv.Kind() {
+		case reflect.Bool:
+			return true
+		case reflect.String:
+			_, err := strconv.ParseBool(strings.TrimSpace(rv.String()))
+			return err == nil
+		}
+		return false
+	case TypeDuration:
+		switch {
+		case isInt(rv.Kind()), isFloat(rv.Kind()):
+			return true
+		case rv.Kind() == reflect.String:
+			s := strings.TrimSpace(rv.String())
+			if _, err := strconv.ParseFloat(s, 64); err == nil {
+				return true
+			}
+			return xconv.Duration(s) != 0 || zeroDuration.MatchString(s)
+		}
+		return false
+	case TypeStrings:
+		if rv.Kind() == reflect.String {
+			return true
+		}
+
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return false
+		}
+
+		for i := 0; i < rv.Len(); i++ {
+			if item := rv.Index(i).Interface(); item == nil || !isScalar(reflect.ValueOf(item).Kind()) {
+				return false
+			}
+		}
+		return true
+	case TypeMap:
+		return rv.Kind() == reflect.Map
+	default:
+		return true
+	}
+}
+
+func isInt(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Uint64
+}
+
+func isFloat(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+func isScalar(kind reflect.Kind) bool {
+	return kind == reflect.String || kind == reflect.Bool || isInt(kind) || isFloat(kind)
+}
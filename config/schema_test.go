@@ -0,0 +1,52 @@
+package config_test
+
+import (
+	"github.com/dobyte/due/v2/config"
+	"github.com/dobyte/due/v2/errors"
+	"testing"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	configurator := config.NewConfigurator()
+	defer configurator.Close()
+
+	_ = configurator.Set("etc.registry.consul.adr", "127.0.0.1:8500")
+	_ = configurator.Set("etc.registry.consul.healthCheck", "yes")
+	_ = configurator.Set("etc.registry.consul.healthCheckInterval", 10)
+	_ = configurator.Set("etc.registry.consul.timeout", "3s")
+
+	schema := &config.Schema{
+		Name:   "consul registry",
+		Prefix: "etc.registry.consul",
+		Strict: true,
+		Fields: []*config.Field{
+			{Key: "etc.registry.consul.addr", Type: config.TypeString, Required: true},
+			{Key: "etc.registry.consul.healthCheck", Type: config.TypeBool, Default: true},
+			{Key: "etc.registry.consul.healthCheckInterval", Type: config.TypeInt, Default: 10},
+			{Key: "etc.registry.consul.timeout", Type: config.TypeDuration},
+		},
+	}
+
+	issues := schema.Validate(configurator)
+
+	for _, issue := range issues {
+		t.Log(issue)
+	}
+
+	if len(issues) != 3 {
+		t.Fatalf("expected 3 issues, got %d", len(issues))
+	}
+
+	if v := schema.Get(configurator, "etc.registry.consul.healthCheckInterval").Int(); v != 10 {
+		t.Fatalf("unexpected value: %d", v)
+	}
+
+	config.RegisterSchema(schema)
+
+	err := config.Validate(configurator)
+	if !errors.Is(err, errors.ErrInvalidConfig) {
+		t.Fatalf("expected invalid config, got %v", err)
+	}
+
+	t.Log(err)
+}
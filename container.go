@@ -1,7 +1,6 @@
 package due
 
 import (
-	"context"
 	"github.com/dobyte/due/v2/component"
 	"github.com/dobyte/due/v2/config"
 	"github.com/dobyte/due/v2/core/info"
@@ -15,17 +14,21 @@ import (
 	"os/signal"
 	"runtime"
 	"strconv"
+	"sync"
 	"syscall"
 	"time"
 )
 
 const (
 	defaultPIDKey                 = "etc.pid"                 // 进程文件路径
-	defaultShutdownMaxWaitTimeKey = "etc.shutdownMaxWaitTime" // 容器关闭最大等待时间
+	defaultShutdownMaxWaitTimeKey = "etc.shutdownMaxWaitTime" // 单个组件关闭最大等待时间
 )
 
+const defaultDestroyMaxWaitTime = 5 * time.Second // 默认单个组件销毁最大等待时间
+
 type Container struct {
 	components []component.Component
+	pending    sync.WaitGroup // 执行中的组件操作
 }
 
 // NewContainer 创建一个容器
@@ -44,6 +47,8 @@ func (c *Container) Serve() {
 
 	c.doPrintFrameworkInfo()
 
+	c.doValidateConfig()
+
 	c.doInitComponents()
 
 	c.doStartComponents()
@@ -57,6 +62,13 @@ func (c *Container) Serve() {
 	c.doClearModules()
 }
 
+// 校验启动配置，存在缺失或无效的配置项时立即退出
+func (c *Container) doValidateConfig() {
+	if err := config.Validate(etc.GetConfigurator()); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
+
 // 初始化所有组件
 func (c *Container) doInitComponents() {
 	for _, comp := range c.components {
@@ -71,31 +83,66 @@ func (c *Container) doStartComponents() {
 	}
 }
 
-// 关闭所有组件
+// 关闭所有组件，按照添加顺序的逆序依次关闭
 func (c *Container) doCloseComponents() {
-	g := xcall.NewGoroutines()
-
-	for _, comp := range c.components {
-		g.Add(comp.Close)
-	}
-
-	g.Run(context.Background(), etc.Get(defaultShutdownMaxWaitTimeKey).Duration())
+	c.doReverseRun(etc.Get(defaultShutdownMaxWaitTimeKey).Duration(), func(comp component.Component) { comp.Close() })
 }
 
-// 销毁所有组件
+// 销毁所有组件，按照添加顺序的逆序依次销毁
 func (c *Container) doDestroyComponents() {
-	g := xcall.NewGoroutines()
+	c.doReverseRun(defaultDestroyMaxWaitTime, func(comp component.Component) { comp.Destroy() })
+}
 
-	for _, comp := range c.components {
-		g.Add(comp.Destroy)
+// 逆序执行组件操作，每个组件的操作超过等待时间后放弃等待并继续执行剩余组件的操作；等待时间不大于0时无限等待
+func (c *Container) doReverseRun(timeout time.Duration, fn func(comp component.Component)) {
+	for i := len(c.components) - 1; i >= 0; i-- {
+		comp := c.components[i]
+		done := make(chan struct{})
+
+		c.pending.Add(1)
+
+		go func() {
+			defer c.pending.Done()
+			defer close(done)
+			xcall.Call(func() { fn(comp) })
+		}()
+
+		if timeout <= 0 {
+			<-done
+			continue
+		}
+
+		timer := time.NewTimer(timeout)
+
+		select {
+		case <-done:
+		case <-timer.C:
+			log.Warnf("container shutdown timeout, component %s is abandoned", comp.Name())
+		}
+
+		timer.Stop()
 	}
+}
+
+// 等待已放弃等待的组件操作结束，超过等待时间后不再等待
+func (c *Container) doWaitAbandoned(timeout time.Duration) {
+	done := make(chan struct{})
+
+	go func() {
+		c.pending.Wait()
+		close(done)
+	}()
 
-	g.Run(context.Background(), 5*time.Second)
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Warnf("container shutdown timeout, some components are still running")
+	}
 }
 
 // 等待系统信号
 func (c *Container) doWaitSystemSignal() {
-	sig := make(chan os.Signal)
+	sig := make(chan os.Signal, 1)
 
 	switch runtime.GOOS {
 	case `windows`:
@@ -111,8 +158,10 @@ func (c *Container) doWaitSystemSignal() {
 	log.Warnf("process got signal %v, container will close", s)
 }
 
-// 清理所有模块
+// 清理所有模块，等待已放弃等待的组件操作结束后再关闭日志
 func (c *Container) doClearModules() {
+	c.doWaitAbandoned(defaultDestroyMaxWaitTime)
+
 	if err := eventbus.Close(); err != nil {
 		log.Warnf("eventbus close failed: %v", err)
 	}
@@ -0,0 +1,102 @@
+package due
+
+import (
+	"github.com/dobyte/due/v2/component"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+type orderedComponent struct {
+	component.Base
+	name  string
+	block chan struct{} // 不为nil时销毁阻塞至关闭该通道
+	mu    *sync.Mutex
+	calls *[]string
+}
+
+func (c *orderedComponent) Name() string {
+	return c.name
+}
+
+func (c *orderedComponent) Close() {
+	c.record("close")
+}
+
+func (c *orderedComponent) Destroy() {
+	if c.block != nil {
+		<-c.block
+	}
+
+	c.record("destroy")
+}
+
+func (c *orderedComponent) record(op string) {
+	c.mu.Lock()
+	*c.calls = append(*c.calls, op+":"+c.name)
+	c.mu.Unlock()
+}
+
+func TestContainer_ReverseOrder(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+		c     = NewContainer()
+	)
+
+	for _, name := range []string{"a", "b", "c"} {
+		c.Add(&orderedComponent{name: name, mu: &mu, calls: &calls})
+	}
+
+	c.doCloseComponents()
+	c.doDestroyComponents()
+
+	expected := []string{"close:c", "close:b", "close:a", "destroy:c", "destroy:b", "destroy:a"}
+
+	if !slices.Equal(calls, expected) {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+}
+
+func TestContainer_ReverseRunTimeout(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+		block = make(chan struct{})
+		c     = NewContainer()
+	)
+
+	c.Add(
+		&orderedComponent{name: "a", mu: &mu, calls: &calls},
+		&orderedComponent{name: "b", mu: &mu, calls: &calls, block: block},
+		&orderedComponent{name: "c", mu: &mu, calls: &calls},
+	)
+
+	// 组件超过等待时间后放弃等待，继续执行剩余组件
+	start := time.Now()
+
+	c.doReverseRun(50*time.Millisecond, func(comp component.Component) { comp.Destroy() })
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("reverse run not timed out: %v", elapsed)
+	}
+
+	mu.Lock()
+	if !slices.Equal(calls, []string{"destroy:c", "destroy:a"}) {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+	mu.Unlock()
+
+	// 等待已放弃等待的组件结束
+	close(block)
+
+	c.doWaitAbandoned(time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !slices.Equal(calls, []string{"destroy:c", "destroy:a", "destroy:b"}) {
+		t.Fatalf("unexpected calls after abandoned component returned: %v", calls)
+	}
+}
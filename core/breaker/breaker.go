@@ -0,0 +1,254 @@
+package breaker
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/errors"
+	"sync"
+	"time"
+)
+
+const (
+	Closed   State = iota // 关闭（正常放行请求）
+	Open                  // 打开（快速失败）
+	HalfOpen              // 半开（放行探测请求）
+)
+
+// State 熔断器状态
+type State int
+
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// StateChangeHandler 状态变更处理器
+type StateChangeHandler func(target string, from, to State)
+
+// 状态变更
+type transition struct {
+	from State
+	to   State
+}
+
+// Breaker 熔断器实现
+type Breaker struct {
+	mu          sync.Mutex
+	opts        *options
+	target      string    // 目标
+	state       State     // 状态
+	requests    int       // 统计窗口内的请求数
+	failures    int       // 统计窗口内的失败数
+	probes      int       // 半开状态下已放行的探测请求数
+	windowStart time.Time // 统计窗口开始时间
+	openedAt    time.Time // 熔断器打开时间
+}
+
+func NewBreaker(target string, opts ...Option) *Breaker {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return newBreaker(target, o)
+}
+
+func newBreaker(target string, opts *options) *Breaker {
+	return &Breaker{
+		opts:        opts,
+		target:      target,
+		windowStart: time.Now(),
+	}
+}
+
+// State 获取熔断器状态
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// Allow 检测是否允许请求通过，不允许时返回errors.ErrCircuitOpen
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	t, err := b.doAllow(time.Now())
+	b.mu.Unlock()
+
+	b.notify(t)
+
+	return err
+}
+
+// Done 上报请求结果，被取消的请求不计入统计
+func (b *Breaker) Done(err error) {
+	b.mu.Lock()
+	t := b.doDone(err, time.Now())
+	b.mu.Unlock()
+
+	b.notify(t)
+}
+
+// Reset 重置熔断器
+func (b *Breaker) Reset() {
+	b.mu.Lock()
+	t := b.doChangeState(Closed, time.Now())
+	b.mu.Unlock()
+
+	b.notify(t)
+}
+
+// 检测是否允许请求通过
+func (b *Breaker) doAllow(now time.Time) (*transition, error) {
+	var t *transition
+
+	switch b.state {
+	case Open:
+		if now.Sub(b.openedAt) < b.opts.openTimeout {
+			return nil, errors.ErrCircuitOpen
+		}
+
+		t = b.doChangeState(HalfOpen, now)
+
+		fallthrough
+	case HalfOpen:
+		if b.probes >= b.opts.halfOpenProbes {
+			return t, errors.ErrCircuitOpen
+		}
+
+		b.probes++
+	default:
+		if now.Sub(b.windowStart) >= b.opts.window {
+			b.doResetWindow(now)
+		}
+	}
+
+	return t, nil
+}
+
+// 统计请求结果
+func (b *Breaker) doDone(err error, now time.Time) *transition {
+	if errors.Is(err, context.Canceled) {
+		if b.state == HalfOpen && b.probes > 0 {
+			b.probes--
+		}
+		return nil
+	}
+
+	switch b.state {
+	case HalfOpen:
+		if err != nil {
+			return b.doChangeState(Open, now)
+		} else {
+			return b.doChangeState(Closed, now)
+		}
+	case Closed:
+		b.requests++
+
+		if err != nil {
+			b.failures++
+		}
+
+		if b.requests >= b.opts.minRequests && float64(b.failures)/float64(b.requests) >= b.opts.failureRate {
+			return b.doChangeState(Open, now)
+		}
+	}
+
+	return nil
+}
+
+// 变更状态，返回需在释放锁后通知的状态变更，状态未变更时返回nil
+func (b *Breaker) doChangeState(state State, now time.Time) *transition {
+	prev := b.state
+
+	b.state = state
+	b.probes = 0
+	b.doResetWindow(now)
+
+	if state == Open {
+		b.openedAt = now
+	}
+
+	if prev == state {
+		return nil
+	}
+
+	return &transition{from: prev, to: state}
+}
+
+// 通知状态变更，须在释放锁后调用，避免状态变更处理器中访问熔断器时死锁
+func (b *Breaker) notify(t *transition) {
+	if t != nil && b.opts.stateHandler != nil {
+		b.opts.stateHandler(b.target, t.from, t.to)
+	}
+}
+
+// 重置统计窗口
+func (b *Breaker) doResetWindow(now time.Time) {
+	b.requests = 0
+	b.failures = 0
+	b.windowStart = now
+}
+
+// Group 熔断器组，按目标隔离熔断器
+type Group struct {
+	opts     *options
+	breakers sync.Map
+}
+
+func NewGroup(opts ...Option) *Group {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &Group{opts: o}
+}
+
+// Get 获取目标的熔断器
+func (g *Group) Get(target string) *Breaker {
+	if b, ok := g.breakers.Load(target); ok {
+		return b.(*Breaker)
+	}
+
+	b, _ := g.breakers.LoadOrStore(target, newBreaker(target, g.opts))
+
+	return b.(*Breaker)
+}
+
+// Do 通过目标的熔断器执行调用
+func (g *Group) Do(target string, fn func() error) error {
+	b := g.Get(target)
+
+	if err := b.Allow(); err != nil {
+		return err
+	}
+
+	err := fn()
+
+	b.Done(err)
+
+	return err
+}
+
+// Reset 重置目标的熔断器
+func (g *Group) Reset(target string) {
+	if b, ok := g.breakers.LoadAndDelete(target); ok {
+		b.(*Breaker).Reset()
+	}
+}
+
+// Retain 仅保留给定目标的熔断器，其余目标的熔断器将被重置
+func (g *Group) Retain(targets map[string]struct{}) {
+	g.breakers.Range(func(key, _ any) bool {
+		if _, ok := targets[key.(string)]; !ok {
+			g.Reset(key.(string))
+		}
+		return true
+	})
+}
@@ -0,0 +1,93 @@
+package breaker_test
+
+import (
+	"github.com/dobyte/due/v2/core/breaker"
+	"github.com/dobyte/due/v2/errors"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	b := breaker.NewBreaker("127.0.0.1:3553",
+		breaker.WithMinRequests(4),
+		breaker.WithFailureRate(0.5),
+		breaker.WithOpenTimeout(100*time.Millisecond),
+		breaker.WithStateChangeHandler(func(target string, from, to breaker.State) {
+			t.Logf("%s: %s -> %s", target, from, to)
+		}),
+	)
+
+	for i := 0; i < 4; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatal(err)
+		}
+
+		if i%2 == 0 {
+			b.Done(errors.ErrConnectionClosed)
+		} else {
+			b.Done(nil)
+		}
+	}
+
+	if err := b.Allow(); !errors.Is(err, errors.ErrCircuitOpen) {
+		t.Fatalf("expected circuit open, got %v", err)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+
+	if err := b.Allow(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.Allow(); !errors.Is(err, errors.ErrCircuitOpen) {
+		t.Fatalf("expected circuit open, got %v", err)
+	}
+
+	b.Done(nil)
+
+	if b.State() != breaker.Closed {
+		t.Fatalf("expected closed, got %s", b.State())
+	}
+}
+
+func TestBreaker_StateHandlerReentrant(t *testing.T) {
+	var (
+		b       *breaker.Breaker
+		changes = make(chan breaker.State, 4)
+	)
+
+	// 状态变更处理器中访问熔断器不会死锁
+	b = breaker.NewBreaker("127.0.0.1:3553",
+		breaker.WithMinRequests(1),
+		breaker.WithOpenTimeout(10*time.Millisecond),
+		breaker.WithStateChangeHandler(func(target string, from, to breaker.State) {
+			changes <- b.State()
+		}),
+	)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_ = b.Allow()
+		b.Done(errors.ErrConnectionClosed)
+
+		time.Sleep(20 * time.Millisecond)
+
+		_ = b.Allow()
+		b.Reset()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("breaker deadlocked in state handler")
+	}
+
+	for _, expected := range []breaker.State{breaker.Open, breaker.HalfOpen, breaker.Closed} {
+		if state := <-changes; state != expected {
+			t.Fatalf("unexpected state: %s, expected: %s", state, expected)
+		}
+	}
+}
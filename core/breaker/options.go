@@ -0,0 +1,62 @@
+package breaker
+
+import "time"
+
+const (
+	defaultWindow         = 10 * time.Second
+	defaultMinRequests    = 20
+	defaultFailureRate    = 0.5
+	defaultOpenTimeout    = 5 * time.Second
+	defaultHalfOpenProbes = 1
+)
+
+type Option func(o *options)
+
+type options struct {
+	window         time.Duration      // 统计窗口，默认10s
+	minRequests    int                // 统计窗口内的最少请求数，达到后才会计算失败率，默认20
+	failureRate    float64            // 失败率阈值，达到后熔断器打开，默认0.5
+	openTimeout    time.Duration      // 熔断器打开的持续时间，超时后转为半开状态，默认5s
+	halfOpenProbes int                // 半开状态下允许通过的探测请求数，默认1
+	stateHandler   StateChangeHandler // 状态变更处理器
+}
+
+func defaultOptions() *options {
+	return &options{
+		window:         defaultWindow,
+		minRequests:    defaultMinRequests,
+		failureRate:    defaultFailureRate,
+		openTimeout:    defaultOpenTimeout,
+		halfOpenProbes: defaultHalfOpenProbes,
+	}
+}
+
+// WithWindow 设置统计窗口
+func WithWindow(window time.Duration) Option {
+	return func(o *options) { o.window = window }
+}
+
+// WithMinRequests 设置统计窗口内的最少请求数
+func WithMinRequests(minRequests int) Option {
+	return func(o *options) { o.minRequests = minRequests }
+}
+
+// WithFailureRate 设置失败率阈值
+func WithFailureRate(failureRate float64) Option {
+	return func(o *options) { o.failureRate = failureRate }
+}
+
+// WithOpenTimeout 设置熔断器打开的持续时间
+func WithOpenTimeout(openTimeout time.Duration) Option {
+	return func(o *options) { o.openTimeout = openTimeout }
+}
+
+// WithHalfOpenProbes 设置半开状态下允许通过的探测请求数
+func WithHalfOpenProbes(halfOpenProbes int) Option {
+	return func(o *options) { o.halfOpenProbes = halfOpenProbes }
+}
+
+// WithStateChangeHandler 设置状态变更处理器
+func WithStateChangeHandler(handler StateChangeHandler) Option {
+	return func(o *options) { o.stateHandler = handler }
+}
@@ -130,3 +130,31 @@ func TestNocopyBuffer_Mount(t *testing.T) {
 
 	fmt.Println(buff1.Bytes())
 }
+
+func TestSharedBuffer(t *testing.T) {
+	buf := buffer.NewNocopyBuffer()
+	buf.Malloc(4).WriteInt32s(binary.BigEndian, 11)
+	buf.Mount([]byte("hello world"))
+
+	expected := buf.Bytes()
+
+	shared := buffer.NewSharedBuffer(buf)
+
+	refs := make([]*buffer.NocopyBuffer, 0, 3)
+	for i := 0; i < 3; i++ {
+		ref := buffer.NewNocopyBuffer()
+		ref.Malloc(1).WriteUint8s(uint8(i))
+		ref.Mount(shared.Ref())
+		refs = append(refs, ref)
+	}
+
+	shared.Release()
+
+	for i, ref := range refs {
+		if !bytes.Equal(ref.Bytes(), append([]byte{uint8(i)}, expected...)) {
+			t.Fatalf("unexpected shared bytes: %v", ref.Bytes())
+		}
+
+		ref.Release()
+	}
+}
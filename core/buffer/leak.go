@@ -0,0 +1,83 @@
+package buffer
+
+import (
+	"fmt"
+	"github.com/dobyte/due/v2/log"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+var (
+	leakDetection atomic.Bool  // 是否开启泄漏检测
+	leakCount     atomic.Int64 // 泄漏次数
+)
+
+// SetLeakDetection 设置是否开启对象池Writer泄漏检测，默认关闭
+// 开启后从对象池获取的Writer将记录获取时的调用栈，若Writer未放回对象池即被GC回收，将输出包含获取调用栈的告警日志
+// 检测依赖终结器与调用栈采集，存在额外开销，仅建议在开发与测试环境中开启；关闭时仅有一次原子读取的开销
+func SetLeakDetection(enable bool) {
+	leakDetection.Store(enable)
+}
+
+// LeakCount 获取检测到的Writer泄漏次数
+func LeakCount() int64 {
+	return leakCount.Load()
+}
+
+type leakTrace struct {
+	pcs []uintptr // 获取Writer时的调用栈
+}
+
+// 跟踪从对象池获取的Writer
+func trackWriter(w *Writer) {
+	if !leakDetection.Load() {
+		return
+	}
+
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+
+	if w.trace == nil {
+		runtime.SetFinalizer(w, finalizeWriter)
+	}
+
+	w.trace = &leakTrace{pcs: pcs[:n]}
+}
+
+// 取消跟踪放回对象池的Writer
+func untrackWriter(w *Writer) {
+	if w.trace != nil {
+		w.trace.pcs = nil
+	}
+}
+
+// Writer被GC回收时检测是否已放回对象池
+func finalizeWriter(w *Writer) {
+	if w.trace == nil || w.trace.pcs == nil {
+		return
+	}
+
+	leakCount.Add(1)
+
+	log.Warnf("pooled writer is garbage collected without being released, acquired at:\n%s", w.trace)
+}
+
+// String 格式化调用栈
+func (t *leakTrace) String() string {
+	var (
+		sb     strings.Builder
+		frames = runtime.CallersFrames(t.pcs)
+	)
+
+	for {
+		frame, more := frames.Next()
+		sb.WriteString(fmt.Sprintf("%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line))
+
+		if !more {
+			break
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,34 @@
+package buffer_test
+
+import (
+	"github.com/dobyte/due/v2/core/buffer"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSetLeakDetection(t *testing.T) {
+	buffer.SetLeakDetection(true)
+	defer buffer.SetLeakDetection(false)
+
+	pool := buffer.NewWriterPool([]int{64})
+	count := buffer.LeakCount()
+
+	released := pool.Get(64)
+	released.WriteString("released")
+	pool.Put(released)
+
+	func() {
+		leaked := pool.Get(64)
+		leaked.WriteString("leaked")
+	}()
+
+	for i := 0; i < 50 && buffer.LeakCount() == count; i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if n := buffer.LeakCount() - count; n != 1 {
+		t.Fatalf("expected 1 leak, got %d", n)
+	}
+}
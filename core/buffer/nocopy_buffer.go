@@ -2,6 +2,16 @@ package buffer
 
 var defaultWriterPool = NewWriterPool([]int{32, 64, 128, 256, 512, 1024, 2048, 4096, 10240})
 
+// WriterPoolStats 获取默认对象池统计信息，NocopyBuffer.Malloc分配的Writer均来自默认对象池
+func WriterPoolStats() []WriterPoolStat {
+	return defaultWriterPool.Stats()
+}
+
+// SetWriterPoolMaxCapacity 设置默认对象池回收Writer的最大容量，小于等于0时不限制，默认64KB
+func SetWriterPoolMaxCapacity(max int) {
+	defaultWriterPool.SetMaxCapacity(max)
+}
+
 type NocopyBuffer struct {
 	len  int
 	num  int
@@ -88,17 +98,28 @@ func (b *NocopyBuffer) Mount(block interface{}, whence ...Whence) {
 		} else {
 			b.addToTail(v)
 		}
+	case *SharedBuffer:
+		if len(whence) > 0 && whence[0] == Head {
+			b.addToHead(&NocopyNode{buf: v})
+		} else {
+			b.addToTail(&NocopyNode{buf: v})
+		}
 	}
 }
 
 // Malloc 分配一块内存给Writer
 func (b *NocopyBuffer) Malloc(cap int, whence ...Whence) *Writer {
-	writer := defaultWriterPool.Get(cap)
+	return b.MallocFrom(defaultWriterPool, cap, whence...)
+}
+
+// MallocFrom 从指定对象池分配Writer，释放时放回该对象池
+func (b *NocopyBuffer) MallocFrom(pool *WriterPool, cap int, whence ...Whence) *Writer {
+	writer := pool.Get(cap)
 
 	if len(whence) > 0 && whence[0] == Head {
-		b.addToHead(&NocopyNode{buf: writer, pool: defaultWriterPool})
+		b.addToHead(&NocopyNode{buf: writer, pool: pool})
 	} else {
-		b.addToTail(&NocopyNode{buf: writer, pool: defaultWriterPool})
+		b.addToTail(&NocopyNode{buf: writer, pool: pool})
 	}
 
 	return writer
@@ -20,6 +20,9 @@ func (n *NocopyNode) Release() {
 		if n.pool != nil {
 			n.pool.Put(b)
 		}
+	case *SharedBuffer:
+		n.buf = nil
+		b.Release()
 	}
 }
 
@@ -34,6 +37,8 @@ func (n *NocopyNode) Len() int {
 		return len(b)
 	case *Writer:
 		return b.Len()
+	case *SharedBuffer:
+		return b.Len()
 	default:
 		return 0
 	}
@@ -46,6 +51,8 @@ func (n *NocopyNode) Bytes() []byte {
 		return b
 	case *Writer:
 		return b.Bytes()
+	case *SharedBuffer:
+		return b.Bytes()
 	default:
 		return nil
 	}
@@ -5,6 +5,7 @@ import (
 	"github.com/dobyte/due/v2/errors"
 	"io"
 	"math"
+	"unicode/utf8"
 )
 
 type Reader struct {
@@ -386,6 +387,34 @@ func (r *Reader) ReadString(len int) (string, error) {
 	return string(buf), nil
 }
 
+// ReadVarString 读取变长字符串，max用于限制字符串最大字节数，默认为MaxVarStringLen
+// 长度超出限制时不会移动读取位置
+func (r *Reader) ReadVarString(order binary.ByteOrder, max ...int) (string, error) {
+	if r.off+b16 > len(r.buf) {
+		return "", errors.ErrUnexpectedEOF
+	}
+
+	n := int(order.Uint16(r.buf[r.off : r.off+b16]))
+
+	if len(max) > 0 && n > max[0] {
+		return "", errors.ErrStringTooLong
+	}
+
+	if r.off+b16+n > len(r.buf) {
+		return "", errors.ErrUnexpectedEOF
+	}
+
+	buf := r.buf[r.off+b16 : r.off+b16+n]
+
+	if !utf8.Valid(buf) {
+		return "", errors.ErrInvalidUTF8
+	}
+
+	r.off += b16 + n
+
+	return string(buf), nil
+}
+
 func (r *Reader) slice(b int) ([]byte, error) {
 	return r.slices(b, 1)
 }
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding/binary"
 	"github.com/dobyte/due/v2/core/buffer"
+	"github.com/dobyte/due/v2/errors"
 	"io"
+	"strings"
 	"testing"
 )
 
@@ -140,3 +142,52 @@ func BenchmarkBinaryRead(b *testing.B) {
 		reader.Seek(0, io.SeekStart)
 	}
 }
+
+func TestReader_ReadVarString(t *testing.T) {
+	long := strings.Repeat("a", buffer.MaxVarStringLen)
+	values := []string{"", "node-1", "你好，世界🌏", long}
+
+	writer := buffer.NewWriter(0)
+	for _, value := range values {
+		if err := writer.WriteVarString(binary.BigEndian, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reader := buffer.NewReader(writer.Bytes())
+	for _, value := range values {
+		str, err := reader.ReadVarString(binary.BigEndian)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if str != value {
+			t.Fatalf("read var string mismatch, expect len: %d, got len: %d", len(value), len(str))
+		}
+	}
+
+	if _, err := reader.ReadVarString(binary.BigEndian); err != errors.ErrUnexpectedEOF {
+		t.Fatalf("expect unexpected eof, got: %v", err)
+	}
+
+	if err := writer.WriteVarString(binary.BigEndian, long+"a"); err != errors.ErrStringTooLong {
+		t.Fatalf("expect string too long, got: %v", err)
+	}
+
+	if err := writer.WriteVarString(binary.BigEndian, "\xff"); err != errors.ErrInvalidUTF8 {
+		t.Fatalf("expect invalid utf8, got: %v", err)
+	}
+
+	reader = buffer.NewReader(writer.Bytes())
+	if str, err := reader.ReadVarString(binary.BigEndian, 0); err != nil || str != "" {
+		t.Fatalf("read guarded var string failed: %v", err)
+	}
+
+	if _, err := reader.ReadVarString(binary.BigEndian, 5); err != errors.ErrStringTooLong {
+		t.Fatalf("expect string too long, got: %v", err)
+	}
+
+	if str, err := reader.ReadVarString(binary.BigEndian, 6); err != nil || str != "node-1" {
+		t.Fatalf("read guarded var string failed: %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package buffer
+
+import "sync/atomic"
+
+// SharedBuffer 引用计数的共享Buffer
+// 数据仅合并一次到对象池分配的Writer中，可挂载到多个Buffer上被多个接收方共享，最后一个引用释放后Writer回收至对象池
+// 共享数据只读，适用于与接收方无关的数据（如广播消息帧）
+type SharedBuffer struct {
+	writer *Writer
+	refs   atomic.Int64
+}
+
+// NewSharedBuffer 将buf中的数据合并为一块共享数据，buf随后被释放；返回的共享Buffer初始引用计数为1，由创建者调用Release释放
+func NewSharedBuffer(buf Buffer) *SharedBuffer {
+	s := &SharedBuffer{writer: defaultWriterPool.Get(buf.Len())}
+	s.refs.Store(1)
+
+	buf.Range(func(node *NocopyNode) bool {
+		s.writer.WriteBytes(node.Bytes()...)
+		return true
+	})
+
+	buf.Release()
+
+	return s
+}
+
+// Len 获取字节长度
+func (s *SharedBuffer) Len() int {
+	return s.writer.Len()
+}
+
+// Bytes 获取共享数据，调用方不可修改
+func (s *SharedBuffer) Bytes() []byte {
+	return s.writer.Bytes()
+}
+
+// Ref 增加一个引用，返回挂载了共享数据的Buffer，该Buffer释放时减少一个引用
+func (s *SharedBuffer) Ref() *NocopyBuffer {
+	s.refs.Add(1)
+
+	return NewNocopyBuffer(s)
+}
+
+// Release 释放一个引用，引用计数归零时回收数据
+func (s *SharedBuffer) Release() {
+	if s.refs.Add(-1) == 0 {
+		defaultWriterPool.Put(s.writer)
+	}
+}
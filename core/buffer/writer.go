@@ -2,12 +2,18 @@ package buffer
 
 import (
 	"encoding/binary"
+	"github.com/dobyte/due/v2/errors"
 	"math"
+	"unicode/utf8"
 )
 
+// MaxVarStringLen 变长字符串最大字节数，受限于uint16长度前缀
+const MaxVarStringLen = math.MaxUint16
+
 type Writer struct {
-	buf []byte
-	off int
+	buf   []byte
+	off   int
+	trace *leakTrace // 泄漏检测调用栈，未开启泄漏检测时为nil
 }
 
 func NewWriter(cap ...int) *Writer {
@@ -42,10 +48,10 @@ func (w *Writer) Bytes() []byte {
 	return w.buf[:w.off]
 }
 
-// Reset 复位
+// Reset 复位，仅清空数据长度，保留已分配的容量
 func (w *Writer) Reset() {
 	w.off = 0
-	w.buf = w.buf[:0]
+	w.buf = w.buf[:cap(w.buf)]
 }
 
 // Grow 增长空间
@@ -173,6 +179,24 @@ func (w *Writer) WriteString(str string) {
 	w.WriteBytes([]byte(str)...)
 }
 
+// WriteVarString 写入变长字符串，以uint16长度前缀加UTF-8字节写入
+func (w *Writer) WriteVarString(order binary.ByteOrder, str string) error {
+	if len(str) > MaxVarStringLen {
+		return errors.ErrStringTooLong
+	}
+
+	if !utf8.ValidString(str) {
+		return errors.ErrInvalidUTF8
+	}
+
+	w.grow(b16 + len(str))
+	order.PutUint16(w.buf[w.off:w.off+b16], uint16(len(str)))
+	w.off += b16
+	w.off += copy(w.buf[w.off:], str)
+
+	return nil
+}
+
 // WriteBytes 写入字节序
 func (w *Writer) WriteBytes(values ...byte) {
 	w.grow(len(values))
@@ -182,7 +206,7 @@ func (w *Writer) WriteBytes(values ...byte) {
 
 // 执行扩容操作
 func (w *Writer) grow(n int) {
-	if w.off+n < len(w.buf) {
+	if w.off+n <= len(w.buf) {
 		return
 	}
 
@@ -2,20 +2,64 @@ package buffer
 
 import (
 	"sync"
+	"sync/atomic"
 )
 
+// 默认回收Writer的最大容量，超过该容量的Writer将被丢弃而不放回对象池
+const defaultWriterMaxCapacity = 64 * 1024
+
 type WriterPool struct {
-	pools      []*sync.Pool
-	capacities []int
+	pools       []*sync.Pool
+	frees       []chan *Writer // 有界空闲列表，设置最大数量时使用以替代sync.Pool
+	capacities  []int
+	counters    []*writerPoolCounter
+	maxCapacity atomic.Int64
+}
+
+type writerPoolCounter struct {
+	gets  atomic.Int64 // 获取次数
+	news  atomic.Int64 // 新建次数（对象池未命中）
+	puts  atomic.Int64 // 回收次数
+	drops atomic.Int64 // 丢弃次数（超过最大容量）
+	overs atomic.Int64 // 丢弃次数（超过最大数量）
+}
+
+// WriterPoolStat 对象池统计信息
+type WriterPoolStat struct {
+	Capacity int   // 容量
+	Gets     int64 // 获取次数
+	News     int64 // 新建次数（对象池未命中）
+	Puts     int64 // 回收次数
+	Drops    int64 // 丢弃次数（超过最大容量）
+	Overs    int64 // 丢弃次数（超过最大数量），仅设置最大数量时统计
+	Idle     int   // 空闲数量，仅设置最大数量时统计
 }
 
-func NewWriterPool(capacities []int) *WriterPool {
+// NewWriterPool 新建对象池
+// maxCount为每个容量档位最多缓存的空闲Writer数量，大于0时使用有界空闲列表，超出的Writer直接交由GC回收，不足时新建；默认不限制，使用sync.Pool
+func NewWriterPool(capacities []int, maxCount ...int) *WriterPool {
 	p := &WriterPool{}
 	p.pools = make([]*sync.Pool, len(capacities))
 	p.capacities = capacities
+	p.counters = make([]*writerPoolCounter, len(capacities))
+	p.maxCapacity.Store(defaultWriterMaxCapacity)
+
+	if len(maxCount) > 0 && maxCount[0] > 0 {
+		p.frees = make([]chan *Writer, len(capacities))
+	}
+
 	for i := range capacities {
 		c := capacities[i]
-		p.pools[i] = &sync.Pool{New: func() any { return NewWriter(c) }}
+		counter := &writerPoolCounter{}
+		p.counters[i] = counter
+		p.pools[i] = &sync.Pool{New: func() any {
+			counter.news.Add(1)
+			return NewWriter(c)
+		}}
+
+		if p.frees != nil {
+			p.frees[i] = make(chan *Writer, maxCount[0])
+		}
 	}
 
 	return p
@@ -23,22 +67,84 @@ func NewWriterPool(capacities []int) *WriterPool {
 
 // Get 获取
 func (p *WriterPool) Get(cap int) *Writer {
-	pool := p.getPool(cap)
-	return pool.Get().(*Writer)
+	i := p.index(cap)
+	p.counters[i].gets.Add(1)
+
+	var w *Writer
+
+	if p.frees != nil {
+		select {
+		case w = <-p.frees[i]:
+		default:
+			w = p.pools[i].New().(*Writer)
+		}
+	} else {
+		w = p.pools[i].Get().(*Writer)
+	}
+
+	trackWriter(w)
+	return w
 }
 
-// Put 放回
+// Put 放回，容量超过最大容量的Writer将被丢弃
 func (p *WriterPool) Put(w *Writer) {
-	pool := p.getPool(w.Cap())
-	pool.Put(w)
+	untrackWriter(w)
+
+	i := p.index(w.Cap())
+
+	if max := p.maxCapacity.Load(); max > 0 && int64(w.Cap()) > max {
+		p.counters[i].drops.Add(1)
+		return
+	}
+
+	w.Reset()
+
+	if p.frees != nil {
+		select {
+		case p.frees[i] <- w:
+			p.counters[i].puts.Add(1)
+		default:
+			p.counters[i].overs.Add(1)
+		}
+		return
+	}
+
+	p.counters[i].puts.Add(1)
+	p.pools[i].Put(w)
+}
+
+// SetMaxCapacity 设置回收Writer的最大容量，小于等于0时不限制，默认64KB
+func (p *WriterPool) SetMaxCapacity(max int) {
+	p.maxCapacity.Store(int64(max))
+}
+
+// Stats 获取对象池统计信息
+func (p *WriterPool) Stats() []WriterPoolStat {
+	stats := make([]WriterPoolStat, len(p.capacities))
+	for i, c := range p.capacities {
+		stats[i] = WriterPoolStat{
+			Capacity: c,
+			Gets:     p.counters[i].gets.Load(),
+			News:     p.counters[i].news.Load(),
+			Puts:     p.counters[i].puts.Load(),
+			Drops:    p.counters[i].drops.Load(),
+			Overs:    p.counters[i].overs.Load(),
+		}
+
+		if p.frees != nil {
+			stats[i].Idle = len(p.frees[i])
+		}
+	}
+
+	return stats
 }
 
-// 获取对象池
-func (p *WriterPool) getPool(cap int) *sync.Pool {
+// 获取对象池索引
+func (p *WriterPool) index(cap int) int {
 	for i, c := range p.capacities {
 		if cap <= c {
-			return p.pools[i]
+			return i
 		}
 	}
-	return p.pools[len(p.pools)-1]
+	return len(p.pools) - 1
 }
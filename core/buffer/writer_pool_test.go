@@ -0,0 +1,82 @@
+package buffer_test
+
+import (
+	"encoding/binary"
+	"github.com/dobyte/due/v2/core/buffer"
+	"testing"
+)
+
+func TestWriterPool_Stats(t *testing.T) {
+	pool := buffer.NewWriterPool([]int{32, 64})
+
+	w := pool.Get(20)
+	pool.Put(w)
+	pool.Get(50)
+
+	stats := pool.Stats()
+
+	if stats[0].Gets != 1 || stats[0].Puts != 1 || stats[0].News < 1 {
+		t.Fatalf("unexpected stat: %+v", stats[0])
+	}
+
+	if stats[1].Gets != 1 || stats[1].Puts != 0 || stats[1].News != 1 {
+		t.Fatalf("unexpected stat: %+v", stats[1])
+	}
+
+	t.Logf("%+v", stats)
+}
+
+func TestWriterPool_SetMaxCapacity(t *testing.T) {
+	pool := buffer.NewWriterPool([]int{32, 64})
+	pool.SetMaxCapacity(64)
+
+	w := pool.Get(64)
+	w.Grow(1024)
+	pool.Put(w)
+
+	stats := pool.Stats()
+
+	if stats[1].Drops != 1 || stats[1].Puts != 0 {
+		t.Fatalf("unexpected stat: %+v", stats[1])
+	}
+}
+
+func TestWriterPool_MaxCount(t *testing.T) {
+	pool := buffer.NewWriterPool([]int{32}, 2)
+
+	writers := []*buffer.Writer{pool.Get(32), pool.Get(32), pool.Get(32)}
+	for _, w := range writers {
+		pool.Put(w)
+	}
+
+	stats := pool.Stats()
+
+	if stats[0].Idle != 2 || stats[0].Puts != 2 || stats[0].Overs != 1 || stats[0].News != 3 {
+		t.Fatalf("unexpected stat: %+v", stats[0])
+	}
+
+	if w := pool.Get(32); w != writers[0] {
+		t.Fatal("idle writer is not reused")
+	}
+}
+
+func TestWriter_Reset(t *testing.T) {
+	w := buffer.NewWriter(64)
+	w.WriteString("hello")
+	w.Reset()
+
+	if w.Len() != 0 || w.Cap() != 64 {
+		t.Fatalf("unexpected writer, len: %d cap: %d", w.Len(), w.Cap())
+	}
+}
+
+func TestWriter_ResetReuse(t *testing.T) {
+	w := buffer.NewWriter(8)
+	w.WriteInt64s(binary.BigEndian, 1)
+	w.Reset()
+	w.WriteInt64s(binary.BigEndian, 2)
+
+	if w.Cap() != 8 {
+		t.Fatalf("unexpected writer cap: %d", w.Cap())
+	}
+}
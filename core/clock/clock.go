@@ -0,0 +1,69 @@
+package clock
+
+import (
+	"time"
+)
+
+// Clock 时钟，抽象时间相关操作以便在测试中精确控制时间流逝
+type Clock interface {
+	// Now 获取当前时间
+	Now() time.Time
+	// After 等待指定时长后向返回的通道发送当前时间
+	After(d time.Duration) <-chan time.Time
+	// AfterFunc 等待指定时长后在独立协程中执行函数
+	AfterFunc(d time.Duration, f func()) Timer
+	// NewTicker 新建周期定时器
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer 单次定时器
+type Timer interface {
+	// Stop 停止定时器，定时器已触发或已停止时返回false
+	Stop() bool
+}
+
+// Ticker 周期定时器
+type Ticker interface {
+	// C 获取定时通道
+	C() <-chan time.Time
+	// Stop 停止定时器
+	Stop()
+}
+
+var realClock Clock = &clock{}
+
+// Real 获取系统时钟，所有操作直接委托给time包
+func Real() Clock {
+	return realClock
+}
+
+type clock struct{}
+
+// Now 获取当前时间
+func (c *clock) Now() time.Time {
+	return time.Now()
+}
+
+// After 等待指定时长后向返回的通道发送当前时间
+func (c *clock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// AfterFunc 等待指定时长后在独立协程中执行函数
+func (c *clock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// NewTicker 新建周期定时器
+func (c *clock) NewTicker(d time.Duration) Ticker {
+	return &ticker{Ticker: time.NewTicker(d)}
+}
+
+type ticker struct {
+	*time.Ticker
+}
+
+// C 获取定时通道
+func (t *ticker) C() <-chan time.Time {
+	return t.Ticker.C
+}
@@ -0,0 +1,89 @@
+package clock_test
+
+import (
+	"github.com/dobyte/due/v2/core/clock"
+	"testing"
+	"time"
+)
+
+func TestFake_Advance(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := clock.NewFake(start)
+
+	after := c.After(time.Second)
+
+	var fired []time.Time
+	timer := c.AfterFunc(2*time.Second, func() { fired = append(fired, c.Now()) })
+
+	ticker := c.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	c.Advance(500 * time.Millisecond)
+
+	select {
+	case <-after:
+		t.Fatal("after fired too early")
+	default:
+	}
+
+	c.Advance(time.Second)
+
+	select {
+	case now := <-after:
+		if !now.Equal(start.Add(time.Second)) {
+			t.Fatalf("unexpected after time: %v", now)
+		}
+	default:
+		t.Fatal("after is not fired")
+	}
+
+	select {
+	case <-ticker.C():
+	default:
+		t.Fatal("ticker is not fired")
+	}
+
+	c.Advance(time.Second)
+
+	if len(fired) != 1 || !fired[0].Equal(start.Add(2*time.Second)) {
+		t.Fatalf("unexpected timer fired: %v", fired)
+	}
+
+	if timer.Stop() {
+		t.Fatal("fired timer should not be stopped")
+	}
+
+	if now := c.Now(); !now.Equal(start.Add(2500 * time.Millisecond)) {
+		t.Fatalf("unexpected now: %v", now)
+	}
+}
+
+func TestFake_Stop(t *testing.T) {
+	c := clock.NewFake(time.Now())
+
+	fired := false
+	timer := c.AfterFunc(time.Second, func() { fired = true })
+
+	if !timer.Stop() {
+		t.Fatal("pending timer should be stopped")
+	}
+
+	ticker := c.NewTicker(time.Second)
+	ticker.Stop()
+
+	if n := c.Waiters(); n != 0 {
+		t.Fatalf("unexpected waiters: %d", n)
+	}
+
+	c.Advance(2 * time.Second)
+
+	if fired {
+		t.Fatal("stopped timer is fired")
+	}
+
+	select {
+	case <-ticker.C():
+		t.Fatal("stopped ticker is fired")
+	default:
+	}
+}
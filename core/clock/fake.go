@@ -0,0 +1,193 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake 模拟时钟，时间仅在调用Advance时流逝，用于确定性地测试超时、续租、心跳等时间相关逻辑
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*waiter
+}
+
+type waiter struct {
+	clock  *Fake
+	when   time.Time
+	period time.Duration
+	ch     chan time.Time
+	fn     func()
+}
+
+var _ Clock = &Fake{}
+
+// NewFake 新建模拟时钟，初始时间为now
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.mu)
+
+	return f
+}
+
+// Now 获取当前时间
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+// After 等待指定时长后向返回的通道发送当前时间
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+
+	f.add(&waiter{when: f.Now().Add(d), ch: ch})
+
+	return ch
+}
+
+// AfterFunc 等待指定时长后执行函数，函数在Advance所在协程中同步执行
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	w := &waiter{when: f.Now().Add(d), fn: fn}
+
+	f.add(w)
+
+	return &fakeTimer{waiter: w}
+}
+
+// NewTicker 新建周期定时器
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+
+	w := &waiter{when: f.Now().Add(d), period: d, ch: make(chan time.Time, 1)}
+
+	f.add(w)
+
+	return &fakeTicker{waiter: w}
+}
+
+// Advance 推进时间，按触发时间顺序依次触发到期的定时器
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	end := f.now.Add(d)
+	f.mu.Unlock()
+
+	for {
+		f.mu.Lock()
+
+		if len(f.waiters) == 0 || f.waiters[0].when.After(end) {
+			f.now = end
+			f.mu.Unlock()
+			return
+		}
+
+		w := f.waiters[0]
+		f.waiters = f.waiters[1:]
+		f.now = w.when
+
+		if w.period > 0 {
+			w.when = w.when.Add(w.period)
+			f.insert(w)
+		}
+
+		now := f.now
+		f.mu.Unlock()
+
+		w.fire(now)
+	}
+}
+
+// Waiters 获取等待中的定时器数量
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.waiters)
+}
+
+// BlockUntil 阻塞直到等待中的定时器数量不少于n，用于等待被测协程创建定时器后再推进时间
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+// 添加定时器
+func (f *Fake) add(w *waiter) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.clock = f
+	f.insert(w)
+	f.cond.Broadcast()
+}
+
+// 按触发时间有序插入定时器，调用方需持有锁
+func (f *Fake) insert(w *waiter) {
+	i := sort.Search(len(f.waiters), func(i int) bool {
+		return f.waiters[i].when.After(w.when)
+	})
+
+	f.waiters = append(f.waiters, nil)
+	copy(f.waiters[i+1:], f.waiters[i:])
+	f.waiters[i] = w
+}
+
+// 移除定时器
+func (f *Fake) remove(w *waiter) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range f.waiters {
+		if f.waiters[i] == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// 触发定时器，通道已满时与time.Ticker一样丢弃本次触发
+func (w *waiter) fire(now time.Time) {
+	if w.fn != nil {
+		w.fn()
+		return
+	}
+
+	select {
+	case w.ch <- now:
+	default:
+	}
+}
+
+type fakeTimer struct {
+	*waiter
+}
+
+// Stop 停止定时器，定时器已触发或已停止时返回false
+func (t *fakeTimer) Stop() bool {
+	return t.clock.remove(t.waiter)
+}
+
+type fakeTicker struct {
+	*waiter
+}
+
+// C 获取定时通道
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.ch
+}
+
+// Stop 停止定时器
+func (t *fakeTicker) Stop() {
+	t.clock.remove(t.waiter)
+}
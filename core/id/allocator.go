@@ -0,0 +1,39 @@
+package id
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cache"
+	"time"
+)
+
+var _ Generator = &Allocator{}
+
+// Allocator 基于缓存自增的全局唯一ID分配器，搭配Redis缓存时通过INCR保证全局唯一
+type Allocator struct {
+	cache   cache.Cache
+	key     string
+	timeout time.Duration
+}
+
+func NewAllocator(c cache.Cache, key string, timeout ...time.Duration) *Allocator {
+	a := &Allocator{cache: c, key: key, timeout: 3 * time.Second}
+
+	if len(timeout) > 0 && timeout[0] > 0 {
+		a.timeout = timeout[0]
+	}
+
+	return a
+}
+
+// Next 生成下一个ID
+func (a *Allocator) Next() (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	return a.NextContext(ctx)
+}
+
+// NextContext 生成下一个ID
+func (a *Allocator) NextContext(ctx context.Context) (int64, error) {
+	return a.cache.IncrInt(ctx, a.key, 1)
+}
@@ -0,0 +1,6 @@
+package id
+
+type Generator interface {
+	// Next 生成下一个ID
+	Next() (int64, error)
+}
@@ -0,0 +1,180 @@
+package id
+
+import (
+	"context"
+	"fmt"
+	"github.com/dobyte/due/v2/cache"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/log"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLeaseKey     = "due:id:machine:%d" // 默认机器ID租约key
+	defaultLeaseTTL     = 30 * time.Second    // 默认租约有效期
+	defaultLeaseTimeout = 3 * time.Second     // 默认缓存读写超时时间
+)
+
+// 支持原子写入的缓存，写入成功即表示抢占到机器ID
+type nxSetter interface {
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+}
+
+// Lease 机器ID租约
+// 通过缓存抢占[0, 1023]范围内的空闲机器ID并定期续约，持有租约期间其他实例无法抢占同一机器ID
+type Lease struct {
+	cache     cache.Cache
+	key       string
+	insID     string
+	machineID int64
+	ttl       time.Duration
+	lost      chan struct{}
+	done      chan struct{}
+	once      sync.Once
+	wg        sync.WaitGroup
+}
+
+// LeaseMachineID 抢占机器ID租约，可将注册中心的实例ID作为参数
+// 从实例ID的哈希位置开始依次尝试抢占，所有机器ID均被占用时返回errors.ErrInvalidMachineID
+// 租约按有效期的三分之一间隔续约，续约时发现租约已被其他实例持有则视为租约丢失，应停止使用该机器ID生成ID
+// 缓存实现SetNX时使用SetNX抢占，否则以IncrInt抢占后再写入实例ID
+func LeaseMachineID(ctx context.Context, c cache.Cache, insID string, ttl ...time.Duration) (*Lease, error) {
+	l := &Lease{
+		cache: c,
+		insID: insID,
+		ttl:   defaultLeaseTTL,
+		lost:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	if len(ttl) > 0 && ttl[0] > 0 {
+		l.ttl = ttl[0]
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(insID))
+	start := int64(h.Sum32()) & maxMachineID
+
+	for i := int64(0); i <= maxMachineID; i++ {
+		machineID := (start + i) & maxMachineID
+		key := fmt.Sprintf(defaultLeaseKey, machineID)
+
+		ok, err := l.claim(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			l.key = key
+			l.machineID = machineID
+			l.wg.Add(1)
+			go l.keepalive()
+
+			return l, nil
+		}
+	}
+
+	return nil, errors.ErrInvalidMachineID
+}
+
+// MachineID 获取租约持有的机器ID
+func (l *Lease) MachineID() int64 {
+	return l.machineID
+}
+
+// Lost 租约丢失信号
+func (l *Lease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Release 停止续约并释放租约
+func (l *Lease) Release(ctx context.Context) error {
+	l.once.Do(func() { close(l.done) })
+	l.wg.Wait()
+
+	select {
+	case <-l.lost:
+		return nil
+	default:
+	}
+
+	if ok, err := l.holding(ctx); err != nil || !ok {
+		return err
+	}
+
+	_, err := l.cache.Delete(ctx, l.key)
+
+	return err
+}
+
+// 抢占机器ID
+func (l *Lease) claim(ctx context.Context, key string) (bool, error) {
+	if s, ok := l.cache.(nxSetter); ok {
+		return s.SetNX(ctx, key, l.insID, l.ttl)
+	}
+
+	n, err := l.cache.IncrInt(ctx, key, 1)
+	if err != nil || n != 1 {
+		return false, err
+	}
+
+	return true, l.cache.Set(ctx, key, l.insID, l.ttl)
+}
+
+// 检测租约是否仍由当前实例持有
+func (l *Lease) holding(ctx context.Context) (bool, error) {
+	val, err := l.cache.Get(ctx, l.key).String()
+	if err != nil {
+		if errors.Is(err, errors.ErrNil) {
+			err = nil
+		}
+		return false, err
+	}
+
+	return val == l.insID, nil
+}
+
+// 续约
+func (l *Lease) keepalive() {
+	defer l.wg.Done()
+
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+			if !l.renew() {
+				log.Errorf("machine id lease lost, machine id: %d", l.machineID)
+				close(l.lost)
+				return
+			}
+		}
+	}
+}
+
+// 续约一次，续约失败时租约仍在有效期内则等待下次续约
+func (l *Lease) renew() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultLeaseTimeout)
+	defer cancel()
+
+	ok, err := l.holding(ctx)
+	if err != nil {
+		log.Warnf("machine id lease renew failed, machine id: %d err: %v", l.machineID, err)
+		return true
+	}
+
+	if !ok {
+		return false
+	}
+
+	if err = l.cache.Set(ctx, l.key, l.insID, l.ttl); err != nil {
+		log.Warnf("machine id lease renew failed, machine id: %d err: %v", l.machineID, err)
+	}
+
+	return true
+}
@@ -0,0 +1,165 @@
+package id_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cache"
+	"github.com/dobyte/due/v2/core/id"
+	"github.com/dobyte/due/v2/errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memCache struct {
+	cache.Cache
+	rw    sync.RWMutex
+	items map[string]interface{}
+}
+
+func newMemCache() *memCache {
+	return &memCache{items: make(map[string]interface{})}
+}
+
+func (c *memCache) Get(ctx context.Context, key string, def ...interface{}) cache.Result {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+
+	if val, ok := c.items[key]; ok {
+		return cache.NewResult(val)
+	}
+
+	return cache.NewResult(nil, errors.ErrNil)
+}
+
+func (c *memCache) Set(ctx context.Context, key string, value interface{}, expiration ...time.Duration) error {
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	c.items[key] = value
+
+	return nil
+}
+
+func (c *memCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	if _, ok := c.items[key]; ok {
+		return false, nil
+	}
+
+	c.items[key] = value
+
+	return true, nil
+}
+
+func (c *memCache) Delete(ctx context.Context, keys ...string) (bool, error) {
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	for _, key := range keys {
+		delete(c.items, key)
+	}
+
+	return true, nil
+}
+
+func TestLeaseMachineID(t *testing.T) {
+	var (
+		ctx = context.Background()
+		c   = newMemCache()
+	)
+
+	l1, err := id.LeaseMachineID(ctx, c, "711baf8d-8a06-11ef-b7df-f4f19e1f0070")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 相同哈希位置的实例抢占到不同的机器ID
+	l2, err := id.LeaseMachineID(ctx, c, "711baf8d-8a06-11ef-b7df-f4f19e1f0070")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l1.MachineID() == l2.MachineID() {
+		t.Fatalf("duplicate machine id: %d", l1.MachineID())
+	}
+
+	// 释放后的机器ID可被再次抢占
+	if err = l1.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	l3, err := id.LeaseMachineID(ctx, c, "711baf8d-8a06-11ef-b7df-f4f19e1f0070")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l3.MachineID() != l1.MachineID() {
+		t.Fatalf("unexpected machine id: %d != %d", l3.MachineID(), l1.MachineID())
+	}
+
+	_ = l2.Release(ctx)
+	_ = l3.Release(ctx)
+}
+
+func TestLeaseMachineID_Exhausted(t *testing.T) {
+	var (
+		ctx = context.Background()
+		c   = newMemCache()
+	)
+
+	leases := make([]*id.Lease, 0, 1024)
+	defer func() {
+		for _, l := range leases {
+			_ = l.Release(ctx)
+		}
+	}()
+
+	for i := 0; i < 1024; i++ {
+		l, err := id.LeaseMachineID(ctx, c, "ins")
+		if err != nil {
+			t.Fatal(err)
+		}
+		leases = append(leases, l)
+	}
+
+	if _, err := id.LeaseMachineID(ctx, c, "ins"); !errors.Is(err, errors.ErrInvalidMachineID) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLease_Lost(t *testing.T) {
+	var (
+		ctx = context.Background()
+		c   = newMemCache()
+	)
+
+	l, err := id.LeaseMachineID(ctx, c, "ins-1", 30*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Release(ctx)
+
+	// 模拟租约过期后被其他实例抢占
+	c.rw.Lock()
+	for key := range c.items {
+		c.items[key] = "ins-2"
+	}
+	c.rw.Unlock()
+
+	select {
+	case <-l.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("lease lost is not notified")
+	}
+
+	// 丢失的租约释放时不应删除其他实例持有的租约
+	if err = l.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.items) != 1 {
+		t.Fatal("lease of other instance is deleted")
+	}
+}
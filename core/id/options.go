@@ -0,0 +1,73 @@
+package id
+
+import (
+	"fmt"
+	"github.com/dobyte/due/v2/etc"
+	"time"
+)
+
+const (
+	defaultMachineID   = -1
+	defaultMaxBackward = 10 * time.Millisecond
+)
+
+const (
+	defaultMachineIDKey   = "etc.id.snowflake.machineID"
+	defaultEpochKey       = "etc.id.snowflake.epoch"
+	defaultMaxBackwardKey = "etc.id.snowflake.maxBackward"
+)
+
+// 默认起始时间：2024-01-01 00:00:00 UTC
+var defaultEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type Option func(o *options)
+
+type options struct {
+	// 机器ID
+	// 取值范围为[0, 1023]，必须显式配置或通过LeaseMachineID抢占，未配置时创建生成器将返回errors.ErrInvalidMachineID
+	machineID int64
+
+	// 起始时间
+	// 默认为2024-01-01 00:00:00 UTC
+	epoch time.Time
+
+	// 最大时钟回拨等待时间
+	// 时钟回拨在该时间内时阻塞等待时钟追上，超过该时间时返回errors.ErrClockMovedBackwards，默认为10ms
+	maxBackward time.Duration
+
+	// 配置解析错误
+	err error
+}
+
+func defaultOptions() *options {
+	opts := &options{
+		machineID:   etc.Get(defaultMachineIDKey, defaultMachineID).Int64(),
+		epoch:       defaultEpoch,
+		maxBackward: etc.Get(defaultMaxBackwardKey, defaultMaxBackward).Duration(),
+	}
+
+	if epoch := etc.Get(defaultEpochKey).String(); epoch != "" {
+		if t, err := time.Parse(time.DateTime, epoch); err != nil {
+			opts.err = fmt.Errorf("invalid snowflake epoch %q: %w", epoch, err)
+		} else {
+			opts.epoch = t
+		}
+	}
+
+	return opts
+}
+
+// WithMachineID 设置机器ID
+func WithMachineID(machineID int64) Option {
+	return func(o *options) { o.machineID = machineID }
+}
+
+// WithEpoch 设置起始时间，将覆盖配置文件中的起始时间
+func WithEpoch(epoch time.Time) Option {
+	return func(o *options) { o.epoch, o.err = epoch, nil }
+}
+
+// WithMaxBackward 设置最大时钟回拨等待时间
+func WithMaxBackward(maxBackward time.Duration) Option {
+	return func(o *options) { o.maxBackward = maxBackward }
+}
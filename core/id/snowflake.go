@@ -0,0 +1,88 @@
+package id
+
+import (
+	"github.com/dobyte/due/v2/errors"
+	"sync"
+	"time"
+)
+
+const (
+	machineBits  = 10
+	sequenceBits = 12
+	maxMachineID = -1 ^ (-1 << machineBits)
+	maxSequence  = -1 ^ (-1 << sequenceBits)
+	machineShift = sequenceBits
+	timeShift    = sequenceBits + machineBits
+)
+
+var _ Generator = &Snowflake{}
+
+// Snowflake 雪花算法ID生成器
+// 41位毫秒级时间戳 + 10位机器ID + 12位序列号
+type Snowflake struct {
+	mu        sync.Mutex
+	opts      *options
+	epoch     int64 // 起始时间（毫秒）
+	lastTime  int64 // 上次生成ID的时间（毫秒）
+	sequence  int64 // 当前毫秒内的序列号
+	machineID int64 // 机器ID
+}
+
+func NewSnowflake(opts ...Option) (*Snowflake, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.err != nil {
+		return nil, o.err
+	}
+
+	if o.machineID < 0 || o.machineID > maxMachineID {
+		return nil, errors.ErrInvalidMachineID
+	}
+
+	return &Snowflake{
+		opts:      o,
+		epoch:     o.epoch.UnixMilli(),
+		machineID: o.machineID,
+	}, nil
+}
+
+// Next 生成下一个ID
+func (s *Snowflake) Next() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+
+	if now < s.lastTime {
+		backward := time.Duration(s.lastTime-now) * time.Millisecond
+		if backward > s.opts.maxBackward {
+			return 0, errors.ErrClockMovedBackwards
+		}
+
+		time.Sleep(backward)
+
+		if now = time.Now().UnixMilli(); now < s.lastTime {
+			return 0, errors.ErrClockMovedBackwards
+		}
+	}
+
+	if now == s.lastTime {
+		s.sequence = (s.sequence + 1) & maxSequence
+
+		if s.sequence == 0 {
+			for now <= s.lastTime {
+				time.Sleep(100 * time.Microsecond)
+				now = time.Now().UnixMilli()
+			}
+		}
+	} else {
+		s.sequence = 0
+	}
+
+	s.lastTime = now
+
+	return (now-s.epoch)<<timeShift | s.machineID<<machineShift | s.sequence, nil
+}
@@ -0,0 +1,42 @@
+package id_test
+
+import (
+	"github.com/dobyte/due/v2/core/id"
+	"github.com/dobyte/due/v2/errors"
+	"testing"
+)
+
+func TestSnowflake_Next(t *testing.T) {
+	s, err := id.NewSnowflake(id.WithMachineID(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var last int64
+
+	for i := 0; i < 100000; i++ {
+		v, err := s.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v <= last {
+			t.Fatalf("id is not monotonic: %d <= %d", v, last)
+		}
+
+		last = v
+	}
+
+	t.Log(last)
+}
+
+func TestNewSnowflake(t *testing.T) {
+	if _, err := id.NewSnowflake(id.WithMachineID(1024)); err == nil {
+		t.Fatal("expected invalid machine id")
+	}
+
+	// 未配置机器ID
+	if _, err := id.NewSnowflake(); !errors.Is(err, errors.ErrInvalidMachineID) {
+		t.Fatalf("expected unconfigured machine id, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package limiter
 import (
 	"github.com/dobyte/due/v2/utils/xtime"
 	"sync"
+	"time"
 )
 
 // Limiter 令牌桶限流器实现
@@ -50,3 +51,30 @@ func (l *Limiter) doAllow(n int) bool {
 
 	return false
 }
+
+// Reserve 预占n个令牌并返回需要等待的时长，令牌不足时允许透支，透支部分由后续填充偿还
+// 适用于按字节限速等必须放行但需平滑突发的场景，调用方按返回时长等待后再执行操作即可保证速率
+func (l *Limiter) Reserve(n int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate <= 0 {
+		return 0
+	}
+
+	now := xtime.Now()
+	num := now.Sub(l.lastFillTime).Seconds() * l.rate
+
+	if num > 0 {
+		l.num = min(l.num+num, l.cap)
+		l.lastFillTime = now
+	}
+
+	l.num -= float64(n)
+
+	if l.num >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.num / l.rate * float64(time.Second))
+}
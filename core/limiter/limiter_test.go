@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/dobyte/due/v2/core/limiter"
 	"testing"
+	"time"
 )
 
 func TestLimiter_Allow(t *testing.T) {
@@ -19,3 +20,15 @@ func TestLimiter_Allow(t *testing.T) {
 		//time.Sleep(100 * time.Millisecond)
 	}
 }
+
+func TestLimiter_Reserve(t *testing.T) {
+	l := limiter.NewLimiter(1024, 1024)
+
+	if wait := l.Reserve(1024); wait != 0 {
+		t.Fatalf("burst should not wait, wait = %v", wait)
+	}
+
+	if wait := l.Reserve(512); wait < 400*time.Millisecond || wait > 600*time.Millisecond {
+		t.Fatalf("unexpected wait: %v", wait)
+	}
+}
@@ -0,0 +1,62 @@
+package limiter
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Throttle 出站限速，按字节进行令牌桶限速，在写入协程中等待以保证消息顺序
+type Throttle struct {
+	limiter atomic.Pointer[Limiter]
+	total   atomic.Int64
+}
+
+// Reset 重置限速器及累计等待时长
+func (t *Throttle) Reset(rate, burst int) {
+	t.SetRate(rate, burst)
+	t.total.Store(0)
+}
+
+// SetRate 设置出站速率（字节/秒）及突发容量（字节），rate小于等于0时不限速，burst小于等于0时与rate相同
+func (t *Throttle) SetRate(rate, burst int) {
+	if rate <= 0 {
+		t.limiter.Store(nil)
+		return
+	}
+
+	if burst <= 0 {
+		burst = rate
+	}
+
+	t.limiter.Store(NewLimiter(float64(burst), float64(rate)))
+}
+
+// Throttled 获取累计等待时长
+func (t *Throttle) Throttled() time.Duration {
+	return time.Duration(t.total.Load())
+}
+
+// Wait 等待至令牌足够后返回，等待期间收到关闭信号时返回false
+func (t *Throttle) Wait(n int, close <-chan struct{}) bool {
+	l := t.limiter.Load()
+	if l == nil {
+		return true
+	}
+
+	d := l.Reserve(n)
+	if d <= 0 {
+		return true
+	}
+
+	t.total.Add(int64(d))
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-close:
+		return false
+	}
+}
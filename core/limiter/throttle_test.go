@@ -0,0 +1,56 @@
+package limiter_test
+
+import (
+	"github.com/dobyte/due/v2/core/limiter"
+	"testing"
+	"time"
+)
+
+func TestThrottle_Wait(t *testing.T) {
+	var (
+		throttle limiter.Throttle
+		close    = make(chan struct{})
+	)
+
+	// 未设置速率时不限速
+	if !throttle.Wait(1<<20, close) || throttle.Throttled() != 0 {
+		t.Fatal("unlimited throttle should not wait")
+	}
+
+	throttle.Reset(1024, 0)
+
+	if !throttle.Wait(1024, close) || throttle.Throttled() != 0 {
+		t.Fatal("burst should not wait")
+	}
+
+	start := time.Now()
+
+	if !throttle.Wait(100, close) {
+		t.Fatal("throttle should pass after waiting")
+	}
+
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("throttle waited too short: %v", elapsed)
+	}
+
+	if throttled := throttle.Throttled(); throttled <= 0 {
+		t.Fatalf("unexpected throttled duration: %v", throttled)
+	}
+
+	// 等待期间关闭
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close <- struct{}{}
+	}()
+
+	if throttle.Wait(1024, close) {
+		t.Fatal("throttle should stop waiting when closed")
+	}
+
+	// 重置后取消限速并清空累计等待时长
+	throttle.Reset(0, 0)
+
+	if !throttle.Wait(1<<20, close) || throttle.Throttled() != 0 {
+		t.Fatal("reset throttle should not wait")
+	}
+}
@@ -3,6 +3,7 @@ package net
 import (
 	"github.com/dobyte/due/v2/errors"
 	"net"
+	"net/url"
 	"strconv"
 )
 
@@ -156,3 +157,33 @@ func FulfillAddr(addr string) string {
 
 	return net.JoinHostPort(host, port)
 }
+
+// SplitEndpoint 拆分服务端点，返回的主机地址不含方括号，IPv6链路本地地址保留区域标识（如fe80::1%eth0）
+// 端点格式为scheme://host:port，IPv6地址需使用方括号包裹，区域标识中的%需编码为%25
+func SplitEndpoint(endpoint string) (scheme, host string, port int, err error) {
+	raw, err := url.Parse(endpoint)
+	if err != nil {
+		return
+	}
+
+	h, p, err := net.SplitHostPort(raw.Host)
+	if err != nil {
+		return
+	}
+
+	if port, err = strconv.Atoi(p); err != nil {
+		return
+	}
+
+	if port < 0 || port > 65535 {
+		err = errors.ErrInvalidArgument
+		return
+	}
+
+	return raw.Scheme, h, port, nil
+}
+
+// JoinEndpoint 组装服务端点，IPv6地址将重新使用方括号包裹，区域标识中的%将被编码为%25，保证组装后的端点可被重新解析与拨号
+func JoinEndpoint(scheme, host string, port int) string {
+	return (&url.URL{Scheme: scheme, Host: net.JoinHostPort(host, strconv.Itoa(port))}).String()
+}
@@ -22,3 +22,36 @@ func TestInternalIP(t *testing.T) {
 
 	t.Log(ip)
 }
+
+func TestSplitEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		host     string
+		port     int
+	}{
+		{endpoint: "grpc://127.0.0.1:3553", host: "127.0.0.1", port: 3553},
+		{endpoint: "grpc://[::1]:3553", host: "::1", port: 3553},
+		{endpoint: "grpc://[fe80::1%25eth0]:3553", host: "fe80::1%eth0", port: 3553},
+	}
+
+	for _, tt := range tests {
+		scheme, host, port, err := net.SplitEndpoint(tt.endpoint)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.endpoint, err)
+		}
+
+		if scheme != "grpc" || host != tt.host || port != tt.port {
+			t.Fatalf("%s: unexpected result: %s %s %d", tt.endpoint, scheme, host, port)
+		}
+
+		if endpoint := net.JoinEndpoint(scheme, host, port); endpoint != tt.endpoint {
+			t.Fatalf("%s: unexpected joined endpoint: %s", tt.endpoint, endpoint)
+		}
+	}
+
+	for _, endpoint := range []string{"grpc://::1:3553", "grpc://127.0.0.1", "grpc://[fe80::1%eth0]:3553"} {
+		if _, _, _, err := net.SplitEndpoint(endpoint); err == nil {
+			t.Fatalf("%s: expected error", endpoint)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package retry
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Policy 退避策略
+type Policy interface {
+	// Backoff 获取第N次退避的等待时间，attempts从1开始
+	Backoff(attempts int) time.Duration
+}
+
+// PolicyFunc 退避策略函数
+type PolicyFunc func(attempts int) time.Duration
+
+// Backoff 获取第N次退避的等待时间
+func (fn PolicyFunc) Backoff(attempts int) time.Duration {
+	return fn(attempts)
+}
+
+// Fixed 固定间隔退避策略
+func Fixed(interval time.Duration) Policy {
+	return PolicyFunc(func(int) time.Duration {
+		return interval
+	})
+}
+
+// Exponential 指数退避策略，首次等待min，之后每次翻倍，最大不超过max
+func Exponential(min, max time.Duration) Policy {
+	if max < min {
+		max = min
+	}
+
+	return PolicyFunc(func(attempts int) time.Duration {
+		interval := min
+
+		for i := 1; i < attempts && interval < max; i++ {
+			interval *= 2
+		}
+
+		if interval > max {
+			interval = max
+		}
+
+		return interval
+	})
+}
+
+// Jittered 为退避策略增加随机抖动，等待时间在[d*(1-factor), d*(1+factor)]范围内随机，避免大量调用方同时重试
+// factor取值范围为(0, 1]，超出范围时按边界值处理
+func Jittered(policy Policy, factor float64) Policy {
+	factor = max(0, min(factor, 1))
+
+	return PolicyFunc(func(attempts int) time.Duration {
+		d := policy.Backoff(attempts)
+		if d <= 0 || factor == 0 {
+			return d
+		}
+
+		delta := float64(d) * factor
+
+		return time.Duration(float64(d) - delta + rand.Float64()*2*delta)
+	})
+}
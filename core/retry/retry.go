@@ -0,0 +1,153 @@
+package retry
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/errors"
+	"time"
+)
+
+// Classifier 错误分类器，返回错误是否可重试
+type Classifier func(err error) bool
+
+// Notifier 重试通知，在每次执行失败且将进行重试时调用
+type Notifier func(attempts int, err error, wait time.Duration)
+
+type Option func(r *Retrier)
+
+// Retrier 重试器
+type Retrier struct {
+	policy      Policy
+	maxAttempts int
+	delayFirst  bool
+	classifier  Classifier
+	notifier    Notifier
+}
+
+// New 创建重试器，默认不限制执行次数，除永久性错误外的错误均可重试
+func New(policy Policy, opts ...Option) *Retrier {
+	r := &Retrier{policy: policy, classifier: Retryable}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// WithMaxAttempts 设置最大执行次数（包含首次执行），小于等于0时不限制
+func WithMaxAttempts(attempts int) Option {
+	return func(r *Retrier) { r.maxAttempts = attempts }
+}
+
+// WithDelayFirst 设置首次执行前是否同样进行退避等待，适用于连接断开后的重连等场景
+func WithDelayFirst(delayFirst bool) Option {
+	return func(r *Retrier) { r.delayFirst = delayFirst }
+}
+
+// WithClassifier 设置错误分类器
+func WithClassifier(classifier Classifier) Option {
+	return func(r *Retrier) { r.classifier = classifier }
+}
+
+// WithNotifier 设置重试通知
+func WithNotifier(notifier Notifier) Option {
+	return func(r *Retrier) { r.notifier = notifier }
+}
+
+// Do 执行fn直至成功、遇到不可重试的错误、达到最大执行次数或上下文结束
+// 达到最大执行次数或遇到不可重试的错误时返回最后一次执行的错误，上下文结束时返回上下文错误
+func (r *Retrier) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.delayFirst {
+		if err := Sleep(ctx, r.policy.Backoff(1)); err != nil {
+			return err
+		}
+	}
+
+	for attempts := 1; ; attempts++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if r.maxAttempts > 0 && attempts >= r.maxAttempts {
+			return err
+		}
+
+		if r.classifier != nil && !r.classifier(err) {
+			return err
+		}
+
+		n := attempts
+		if r.delayFirst {
+			n++
+		}
+
+		wait := r.policy.Backoff(n)
+
+		if r.notifier != nil {
+			r.notifier(attempts, err, wait)
+		}
+
+		if e := Sleep(ctx, wait); e != nil {
+			return e
+		}
+	}
+}
+
+// Do 使用退避策略执行fn，等同于New(policy, opts...).Do(ctx, fn)
+func Do(ctx context.Context, policy Policy, fn func(ctx context.Context) error, opts ...Option) error {
+	return New(policy, opts...).Do(ctx, fn)
+}
+
+// Sleep 等待指定时间，上下文结束时提前返回上下文错误
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent 将错误标记为永久性错误，默认的错误分类器不会对其进行重试
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+// IsPermanent 检测是否为永久性错误
+func IsPermanent(err error) bool {
+	var e *permanentError
+	return errors.As(err, &e)
+}
+
+// Retryable 默认的错误分类器，永久性错误及上下文错误不可重试
+func Retryable(err error) bool {
+	if IsPermanent(err) {
+		return false
+	}
+
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
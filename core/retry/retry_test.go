@@ -0,0 +1,83 @@
+package retry_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/core/retry"
+	"github.com/dobyte/due/v2/errors"
+	"testing"
+	"time"
+)
+
+func TestExponential(t *testing.T) {
+	policy := retry.Exponential(time.Second, 5*time.Second)
+
+	for attempts, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if got := policy.Backoff(attempts + 1); got != want {
+			t.Fatalf("attempts %d: want %v got %v", attempts+1, want, got)
+		}
+	}
+}
+
+func TestJittered(t *testing.T) {
+	policy := retry.Jittered(retry.Fixed(100*time.Millisecond), 0.5)
+
+	for i := 0; i < 100; i++ {
+		if d := policy.Backoff(1); d < 50*time.Millisecond || d > 150*time.Millisecond {
+			t.Fatalf("jittered backoff out of range: %v", d)
+		}
+	}
+}
+
+func TestRetrier_Do(t *testing.T) {
+	attempts := 0
+
+	err := retry.Do(context.Background(), retry.Fixed(time.Millisecond), func(ctx context.Context) error {
+		if attempts++; attempts < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 3 {
+		t.Fatalf("want 3 attempts got %d", attempts)
+	}
+}
+
+func TestRetrier_MaxAttempts(t *testing.T) {
+	attempts := 0
+	failed := errors.New("connection refused")
+
+	err := retry.Do(context.Background(), retry.Fixed(time.Millisecond), func(ctx context.Context) error {
+		attempts++
+		return failed
+	}, retry.WithMaxAttempts(3))
+	if !errors.Is(err, failed) || attempts != 3 {
+		t.Fatalf("want 3 attempts with last error, got %d attempts err: %v", attempts, err)
+	}
+}
+
+func TestRetrier_Permanent(t *testing.T) {
+	attempts := 0
+	failed := errors.New("invalid config")
+
+	err := retry.Do(context.Background(), retry.Fixed(time.Millisecond), func(ctx context.Context) error {
+		attempts++
+		return retry.Permanent(failed)
+	})
+	if !errors.Is(err, failed) || !retry.IsPermanent(err) || attempts != 1 {
+		t.Fatalf("want 1 attempt with permanent error, got %d attempts err: %v", attempts, err)
+	}
+}
+
+func TestRetrier_Cancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := retry.Do(ctx, retry.Fixed(time.Second), func(ctx context.Context) error { return nil }, retry.WithDelayFirst(true))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
@@ -65,3 +65,9 @@ func Invoke(name string) Codec {
 
 	return codec
 }
+
+// Lookup 查找编解码器，未注册时返回false
+func Lookup(name string) (Codec, bool) {
+	codec, ok := codecs[name]
+	return codec, ok
+}
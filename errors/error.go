@@ -39,6 +39,7 @@ var (
 	ErrInvalidFormat         = New("invalid format")
 	ErrIllegalRequest        = New("illegal request")
 	ErrIllegalOperation      = New("illegal operation")
+	ErrLockNotHeld           = New("lock not held")
 	ErrInvalidPointer        = New("invalid pointer")
 	ErrNotFoundLocator       = New("not found locator")
 	ErrUnexpectedEOF         = New("unexpected EOF")
@@ -54,6 +55,27 @@ var (
 	ErrNotFoundActor         = New("not found actor")
 	ErrWriterClosing         = New("writer is closing")
 	ErrDeadlineExceeded      = New("deadline exceeded")
+	ErrCircuitOpen           = New("circuit breaker is open")
+	ErrNotLeader             = New("not leader")
+	ErrNotFoundRoomManager   = New("not found room manager")
+	ErrNotReady              = New("not ready")
+	ErrClockMovedBackwards   = New("clock moved backwards")
+	ErrInvalidMachineID      = New("invalid machine id")
+	ErrInvalidRoute          = New("invalid route")
+	ErrDisconnected          = New("disconnected")
+	ErrInvalidConfig         = New("invalid config")
+	ErrNotSupported          = New("not supported")
+	ErrRouteConflict         = New("route conflict")
+	ErrNodeDraining          = New("node is draining")
+	ErrDrainTimeout          = New("drain timeout")
+	ErrMissRegistry          = New("miss registry")
+	ErrNotFoundInstance      = New("not found service instance")
+	ErrInvalidHandoffToken   = New("invalid handoff token")
+	ErrMetaTooLarge          = New("meta too large")
+	ErrInvalidCompression    = New("invalid compression")
+	ErrStringTooLong         = New("string too long")
+	ErrInvalidUTF8           = New("invalid utf-8 string")
+	ErrDuplicateBind         = New("duplicate bind")
 )
 
 // NewError 新建一个错误
@@ -9,6 +9,7 @@ import (
 type consumer struct {
 	rw       sync.RWMutex
 	handlers map[uintptr][]EventHandler
+	pointers []uintptr // 处理器的订阅顺序
 }
 
 // 添加处理器
@@ -20,6 +21,7 @@ func (c *consumer) addHandler(handler EventHandler) int {
 
 	if _, ok := c.handlers[pointer]; !ok {
 		c.handlers[pointer] = make([]EventHandler, 0, 1)
+		c.pointers = append(c.pointers, pointer)
 	}
 
 	c.handlers[pointer] = append(c.handlers[pointer], handler)
@@ -34,7 +36,16 @@ func (c *consumer) remHandler(handler EventHandler) int {
 	c.rw.Lock()
 	defer c.rw.Unlock()
 
-	delete(c.handlers, pointer)
+	if _, ok := c.handlers[pointer]; ok {
+		delete(c.handlers, pointer)
+
+		for i, p := range c.pointers {
+			if p == pointer {
+				c.pointers = append(c.pointers[:i], c.pointers[i+1:]...)
+				break
+			}
+		}
+	}
 
 	return len(c.handlers)
 }
@@ -51,3 +62,17 @@ func (c *consumer) dispatch(event *Event) {
 		}
 	}
 }
+
+// 按订阅顺序在当前协程中同步投递数据
+func (c *consumer) deliver(event *Event) {
+	c.rw.RLock()
+	handlers := make([]EventHandler, 0, len(c.pointers))
+	for _, pointer := range c.pointers {
+		handlers = append(handlers, c.handlers[pointer]...)
+	}
+	c.rw.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
@@ -25,6 +25,22 @@ type Event struct {
 	Timestamp time.Time   // 事件时间
 }
 
+type eventIDKey struct{}
+
+// WithEventID 在上下文中指定发布事件的ID，未指定时由事件总线生成
+func WithEventID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, eventIDKey{}, id)
+}
+
+// EventID 获取上下文中指定的事件ID，未指定时生成新的事件ID
+func EventID(ctx context.Context) string {
+	if id, ok := ctx.Value(eventIDKey{}).(string); ok && id != "" {
+		return id
+	}
+
+	return xuuid.UUID()
+}
+
 type Eventbus interface {
 	// Close 关闭事件总线
 	Close() error
@@ -36,23 +52,57 @@ type Eventbus interface {
 	Unsubscribe(ctx context.Context, topic string, handler EventHandler) error
 }
 
+type Option func(eb *defaultEventbus)
+
 type defaultEventbus struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
 	rw        sync.RWMutex
 	consumers map[string]*consumer
+
+	deterministic bool       // 是否为确定性模式
+	mu            sync.Mutex // 事件队列锁
+	queue         []*Event   // 确定性模式下待处理的事件队列
+	draining      bool       // 是否正在处理事件队列
 }
 
-func NewEventbus() *defaultEventbus {
+func NewEventbus(opts ...Option) *defaultEventbus {
 	eb := &defaultEventbus{}
 	eb.consumers = make(map[string]*consumer)
 
+	for _, opt := range opts {
+		opt(eb)
+	}
+
 	return eb
 }
 
+// WithDeterministic 开启确定性模式，仅用于测试
+// 确定性模式下发布事件时在调用方协程中按订阅顺序同步投递；处理器中发布的事件进入队列，待当前事件处理完毕后按发布顺序依次投递
+func WithDeterministic() Option {
+	return func(eb *defaultEventbus) { eb.deterministic = true }
+}
+
 // Publish 发布事件
 func (eb *defaultEventbus) Publish(ctx context.Context, topic string, payload interface{}) error {
+	event := &Event{
+		ID:        EventID(ctx),
+		Topic:     topic,
+		Payload:   value.NewValue(payload),
+		Timestamp: xtime.UnixNano(xtime.Now().UnixNano()),
+	}
+
+	if eb.deterministic {
+		eb.mu.Lock()
+		eb.queue = append(eb.queue, event)
+		eb.mu.Unlock()
+
+		eb.Drain()
+
+		return nil
+	}
+
 	eb.rw.RLock()
 	defer eb.rw.RUnlock()
 
@@ -61,16 +111,57 @@ func (eb *defaultEventbus) Publish(ctx context.Context, topic string, payload in
 		return nil
 	}
 
-	c.dispatch(&Event{
-		ID:        xuuid.UUID(),
-		Topic:     topic,
-		Payload:   value.NewValue(payload),
-		Timestamp: xtime.UnixNano(xtime.Now().UnixNano()),
-	})
+	c.dispatch(event)
 
 	return nil
 }
 
+// Drain 在调用方协程中依次处理队列中的事件直至队列为空，返回处理的事件数
+// 仅在确定性模式下生效；已有协程正在处理队列时直接返回，新入队的事件将由该协程处理
+func (eb *defaultEventbus) Drain() int {
+	if !eb.deterministic {
+		return 0
+	}
+
+	eb.mu.Lock()
+	if eb.draining {
+		eb.mu.Unlock()
+		return 0
+	}
+	eb.draining = true
+	eb.mu.Unlock()
+
+	defer func() {
+		eb.mu.Lock()
+		eb.draining = false
+		eb.mu.Unlock()
+	}()
+
+	n := 0
+
+	for {
+		eb.mu.Lock()
+		if len(eb.queue) == 0 {
+			eb.mu.Unlock()
+			return n
+		}
+		event := eb.queue[0]
+		eb.queue[0] = nil
+		eb.queue = eb.queue[1:]
+		eb.mu.Unlock()
+
+		eb.rw.RLock()
+		c, ok := eb.consumers[event.Topic]
+		eb.rw.RUnlock()
+
+		if ok {
+			c.deliver(event)
+		}
+
+		n++
+	}
+}
+
 // Subscribe 订阅事件
 func (eb *defaultEventbus) Subscribe(ctx context.Context, topic string, handler EventHandler) error {
 	eb.rw.Lock()
@@ -55,3 +55,44 @@ func TestEventbus_Subscribe(t *testing.T) {
 
 	time.Sleep(30 * time.Second)
 }
+
+func TestEventbus_Deterministic(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		bus    = eventbus.NewEventbus(eventbus.WithDeterministic())
+		events []string
+	)
+
+	_ = bus.Subscribe(ctx, loginTopic, func(event *eventbus.Event) {
+		events = append(events, event.Topic+":"+event.Payload.String())
+
+		// 处理器中发布的事件在当前事件处理完毕后投递
+		_ = bus.Publish(ctx, paidTopic, event.Payload.String())
+
+		events = append(events, "login:done")
+	})
+
+	_ = bus.Subscribe(ctx, paidTopic, func(event *eventbus.Event) {
+		events = append(events, event.Topic+":"+event.Payload.String())
+	})
+
+	if err := bus.Publish(ctx, loginTopic, "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"login:1", "login:done", "paid:1"}
+
+	if len(events) != len(expected) {
+		t.Fatalf("unexpected events: %v", events)
+	}
+
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Fatalf("unexpected events: %v", events)
+		}
+	}
+
+	if n := bus.Drain(); n != 0 {
+		t.Fatalf("unexpected drained events: %d", n)
+	}
+}
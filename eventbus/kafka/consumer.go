@@ -2,6 +2,7 @@ package kafka
 
 import (
 	"context"
+	"github.com/IBM/sarama"
 	"github.com/dobyte/due/v2/eventbus"
 	"github.com/dobyte/due/v2/log"
 	"github.com/dobyte/due/v2/task"
@@ -14,6 +15,53 @@ type consumer struct {
 	cancel   context.CancelFunc
 	rw       sync.RWMutex
 	handlers map[uintptr][]eventbus.EventHandler
+
+	mu      sync.Mutex
+	running map[int32]bool  // 分区 -> 是否正在消费
+	offsets map[int32]int64 // 分区 -> 下一条待消费消息的偏移量，重新订阅时从该位置继续消费
+}
+
+func newConsumer(ctx context.Context) *consumer {
+	c := &consumer{
+		handlers: make(map[uintptr][]eventbus.EventHandler),
+		running:  make(map[int32]bool),
+		offsets:  make(map[int32]int64),
+	}
+	c.ctx, c.cancel = context.WithCancel(ctx)
+
+	return c
+}
+
+// 获取分区重新消费的起始偏移量，首次消费时从最新位置开始；分区已在消费时返回false
+func (c *consumer) startPartition(partition int32) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.running[partition] {
+		return 0, false
+	}
+
+	c.running[partition] = true
+
+	if offset, ok := c.offsets[partition]; ok {
+		return offset, true
+	}
+
+	return sarama.OffsetNewest, true
+}
+
+// 标记分区停止消费
+func (c *consumer) stopPartition(partition int32) {
+	c.mu.Lock()
+	delete(c.running, partition)
+	c.mu.Unlock()
+}
+
+// 记录分区已消费的消息偏移量
+func (c *consumer) commitOffset(partition int32, offset int64) {
+	c.mu.Lock()
+	c.offsets[partition] = offset + 1
+	c.mu.Unlock()
 }
 
 // 添加处理器
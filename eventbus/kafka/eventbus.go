@@ -3,21 +3,27 @@ package kafka
 import (
 	"context"
 	"github.com/IBM/sarama"
+	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/eventbus"
+	"net"
 	"sync"
+	"sync/atomic"
 )
 
 type Eventbus struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 	opts   *options
+	keeper *eventbus.Keeper
 
-	err      error
-	err1     error
-	err2     error
-	consumer sarama.Consumer
-	producer sarama.AsyncProducer
-	builtin  bool
+	err          error
+	err1         error
+	err2         error
+	client       sarama.Client
+	consumer     sarama.Consumer
+	producer     sarama.AsyncProducer
+	builtin      bool
+	reconnecting atomic.Bool
 
 	rw        sync.RWMutex
 	consumers map[string]*consumer
@@ -33,10 +39,14 @@ func NewEventbus(opts ...Option) *Eventbus {
 	eb.opts = o
 	eb.consumers = make(map[string]*consumer)
 	eb.ctx, eb.cancel = context.WithCancel(o.ctx)
+	eb.keeper = eventbus.NewKeeper(
+		eventbus.WithReconnectInterval(o.minReconnectInterval, o.maxReconnectInterval),
+		eventbus.WithConnectHandler(o.connectHandler),
+		eventbus.WithDisconnectHandler(o.disconnectHandler),
+	)
 
 	if o.client != nil {
-		eb.consumer, eb.err1 = sarama.NewConsumerFromClient(o.client)
-		eb.producer, eb.err2 = sarama.NewAsyncProducerFromClient(o.client)
+		eb.client = o.client
 	} else {
 		eb.builtin = true
 		config := sarama.NewConfig()
@@ -52,11 +62,15 @@ func NewEventbus(opts ...Option) *Eventbus {
 		}
 
 		if eb.err == nil {
-			eb.consumer, eb.err1 = sarama.NewConsumer(o.addrs, config)
-			eb.producer, eb.err2 = sarama.NewAsyncProducer(o.addrs, config)
+			eb.client, eb.err = sarama.NewClient(o.addrs, config)
 		}
 	}
 
+	if eb.err == nil {
+		eb.consumer, eb.err1 = sarama.NewConsumerFromClient(eb.client)
+		eb.producer, eb.err2 = sarama.NewAsyncProducerFromClient(eb.client)
+	}
+
 	return eb
 }
 
@@ -70,7 +84,11 @@ func (eb *Eventbus) Publish(ctx context.Context, topic string, payload interface
 		return eb.err2
 	}
 
-	buf, err := serialize(topic, payload)
+	if err := eb.keeper.Check(); err != nil {
+		return err
+	}
+
+	buf, err := serialize(eventbus.EventID(ctx), topic, payload)
 	if err != nil {
 		return err
 	}
@@ -86,6 +104,9 @@ func (eb *Eventbus) Publish(ctx context.Context, topic string, payload interface
 	case <-eb.producer.Successes():
 		return nil
 	case err = <-eb.producer.Errors():
+		if isDisconnected(err) {
+			eb.reconnect(err)
+		}
 		return err
 	}
 }
@@ -103,8 +124,7 @@ func (eb *Eventbus) Subscribe(_ context.Context, topic string, handler eventbus.
 	eb.rw.Lock()
 	c, ok := eb.consumers[topic]
 	if !ok {
-		c = &consumer{handlers: make(map[uintptr][]eventbus.EventHandler)}
-		c.ctx, c.cancel = context.WithCancel(eb.ctx)
+		c = newConsumer(eb.ctx)
 		eb.consumers[topic] = c
 	}
 	c.addHandler(handler)
@@ -163,14 +183,20 @@ func (eb *Eventbus) Close() error {
 
 	err1 := eb.consumer.Close()
 	err2 := eb.producer.Close()
+	err3 := eb.client.Close()
 
 	if err1 != nil {
 		return err1
 	}
 
-	return err2
+	if err2 != nil {
+		return err2
+	}
+
+	return err3
 }
 
+// 监听主题所有未在消费的分区，分区消费出错时标记连接断开并以指数退避的方式重新订阅
 func (eb *Eventbus) watch(c *consumer, topic string) error {
 	partitions, err := eb.consumer.Partitions(topic)
 	if err != nil {
@@ -178,26 +204,93 @@ func (eb *Eventbus) watch(c *consumer, topic string) error {
 	}
 
 	for _, partition := range partitions {
-		cp, err := eb.consumer.ConsumePartition(topic, partition, sarama.OffsetNewest)
+		offset, ok := c.startPartition(partition)
+		if !ok {
+			continue
+		}
+
+		cp, err := eb.consumer.ConsumePartition(topic, partition, offset)
+		if errors.Is(err, sarama.ErrOffsetOutOfRange) {
+			cp, err = eb.consumer.ConsumePartition(topic, partition, sarama.OffsetNewest)
+		}
 		if err != nil {
+			c.stopPartition(partition)
 			return err
 		}
 
-		go func(cp sarama.PartitionConsumer) {
-			defer cp.AsyncClose()
+		go func(partition int32, cp sarama.PartitionConsumer) {
+			var err error
+
+			defer func() {
+				_ = cp.Close()
+				c.stopPartition(partition)
+
+				if err != nil && c.ctx.Err() == nil {
+					eb.reconnect(err)
+				}
+			}()
 
 			for {
 				select {
 				case <-c.ctx.Done():
 					return
 				case message := <-cp.Messages():
+					c.commitOffset(partition, message.Offset)
 					c.dispatch(message.Value)
-				case <-cp.Errors():
+				case err = <-cp.Errors():
 					return
 				}
 			}
-		}(cp)
+		}(partition, cp)
 	}
 
 	return nil
 }
+
+// 标记连接断开，并在后台以指数退避的方式进行重连
+func (eb *Eventbus) reconnect(err error) {
+	eb.keeper.Disconnected(err)
+
+	if !eb.reconnecting.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer eb.reconnecting.Store(false)
+
+		_ = eb.keeper.Reconnect(eb.ctx, eb.resubscribe)
+	}()
+}
+
+// 刷新集群元数据并重新订阅所有主题中停止消费的分区
+func (eb *Eventbus) resubscribe(_ context.Context) error {
+	if err := eb.client.RefreshMetadata(); err != nil {
+		return err
+	}
+
+	eb.rw.RLock()
+	consumers := make(map[string]*consumer, len(eb.consumers))
+	for topic, c := range eb.consumers {
+		consumers[topic] = c
+	}
+	eb.rw.RUnlock()
+
+	for topic, c := range consumers {
+		if c.ctx.Err() != nil {
+			continue
+		}
+
+		if err := eb.watch(c, topic); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 检测错误是否由连接断开引起
+func isDisconnected(err error) bool {
+	var netErr net.Error
+
+	return errors.Is(err, sarama.ErrOutOfBrokers) || errors.Is(err, sarama.ErrNotConnected) || errors.As(err, &netErr)
+}
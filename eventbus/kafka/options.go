@@ -4,17 +4,25 @@ import (
 	"context"
 	"github.com/IBM/sarama"
 	"github.com/dobyte/due/v2/etc"
+	"github.com/dobyte/due/v2/eventbus"
+	"time"
 )
 
 const (
 	defaultAddr   = "127.0.0.1:9092"
 	defaultPrefix = "due"
+
+	defaultMinReconnectInterval = time.Second
+	defaultMaxReconnectInterval = 30 * time.Second
 )
 
 const (
 	defaultAddrsKey   = "etc.eventbus.kafka.addrs"
 	defaultPrefixKey  = "etc.eventbus.kafka.prefix"
 	defaultVersionKey = "etc.eventbus.kafka.version"
+
+	defaultMinReconnectIntervalKey = "etc.eventbus.kafka.minReconnectInterval"
+	defaultMaxReconnectIntervalKey = "etc.eventbus.kafka.maxReconnectInterval"
 )
 
 type Option func(o *options)
@@ -36,6 +44,22 @@ type options struct {
 	// 客户端
 	// 外部客户端配置，存在外部客户端时，优先使用外部客户端，默认为nil
 	client sarama.Client
+
+	// 重连最小间隔
+	// 连接断开后以指数退避的方式无限重连，并重新订阅所有主题，默认为1s
+	minReconnectInterval time.Duration
+
+	// 重连最大间隔
+	// 默认为30s
+	maxReconnectInterval time.Duration
+
+	// 连接恢复回调
+	// 默认为nil
+	connectHandler eventbus.ConnectHandler
+
+	// 连接断开回调
+	// 默认为nil
+	disconnectHandler eventbus.DisconnectHandler
 }
 
 func defaultOptions() *options {
@@ -44,6 +68,9 @@ func defaultOptions() *options {
 		addrs:   etc.Get(defaultAddrsKey, []string{defaultAddr}).Strings(),
 		prefix:  etc.Get(defaultPrefixKey, defaultPrefix).String(),
 		version: etc.Get(defaultVersionKey).String(),
+
+		minReconnectInterval: etc.Get(defaultMinReconnectIntervalKey, defaultMinReconnectInterval).Duration(),
+		maxReconnectInterval: etc.Get(defaultMaxReconnectIntervalKey, defaultMaxReconnectInterval).Duration(),
 	}
 }
 
@@ -71,3 +98,18 @@ func WithVersion(version string) Option {
 func WithClient(client sarama.Client) Option {
 	return func(o *options) { o.client = client }
 }
+
+// WithReconnectInterval 设置重连退避的最小与最大间隔
+func WithReconnectInterval(min, max time.Duration) Option {
+	return func(o *options) { o.minReconnectInterval, o.maxReconnectInterval = min, max }
+}
+
+// WithConnectHandler 设置连接恢复回调
+func WithConnectHandler(handler eventbus.ConnectHandler) Option {
+	return func(o *options) { o.connectHandler = handler }
+}
+
+// WithDisconnectHandler 设置连接断开回调
+func WithDisconnectHandler(handler eventbus.DisconnectHandler) Option {
+	return func(o *options) { o.disconnectHandler = handler }
+}
@@ -0,0 +1,130 @@
+package eventbus
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/core/retry"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/log"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultMinReconnectInterval = time.Second
+	defaultMaxReconnectInterval = 30 * time.Second
+)
+
+// ConnectHandler 连接恢复回调
+type ConnectHandler func()
+
+// DisconnectHandler 连接断开回调
+type DisconnectHandler func(err error)
+
+type KeeperOption func(k *Keeper)
+
+// Keeper 连接保持器，供各事件总线后端共用
+// 维护与后端的连接状态，断开期间发布事件将返回errors.ErrDisconnected，并以指数退避的方式进行重连
+type Keeper struct {
+	minInterval  time.Duration
+	maxInterval  time.Duration
+	policy       retry.Policy
+	disconnected atomic.Bool
+	onConnect    ConnectHandler
+	onDisconnect DisconnectHandler
+}
+
+func NewKeeper(opts ...KeeperOption) *Keeper {
+	k := &Keeper{
+		minInterval: defaultMinReconnectInterval,
+		maxInterval: defaultMaxReconnectInterval,
+	}
+
+	for _, opt := range opts {
+		opt(k)
+	}
+
+	if k.minInterval <= 0 {
+		k.minInterval = defaultMinReconnectInterval
+	}
+
+	if k.maxInterval < k.minInterval {
+		k.maxInterval = k.minInterval
+	}
+
+	k.policy = retry.Exponential(k.minInterval, k.maxInterval)
+
+	return k
+}
+
+// WithReconnectInterval 设置重连退避的最小与最大间隔
+func WithReconnectInterval(min, max time.Duration) KeeperOption {
+	return func(k *Keeper) { k.minInterval, k.maxInterval = min, max }
+}
+
+// WithConnectHandler 设置连接恢复回调
+func WithConnectHandler(handler ConnectHandler) KeeperOption {
+	return func(k *Keeper) { k.onConnect = handler }
+}
+
+// WithDisconnectHandler 设置连接断开回调
+func WithDisconnectHandler(handler DisconnectHandler) KeeperOption {
+	return func(k *Keeper) { k.onDisconnect = handler }
+}
+
+// Check 检测连接状态，连接断开时返回errors.ErrDisconnected
+func (k *Keeper) Check() error {
+	if k.disconnected.Load() {
+		return errors.ErrDisconnected
+	}
+
+	return nil
+}
+
+// Connected 标记连接已恢复
+func (k *Keeper) Connected() {
+	if !k.disconnected.CompareAndSwap(true, false) {
+		return
+	}
+
+	log.Infof("eventbus connection recovered")
+
+	if k.onConnect != nil {
+		k.onConnect()
+	}
+}
+
+// Disconnected 标记连接已断开
+func (k *Keeper) Disconnected(err error) {
+	if !k.disconnected.CompareAndSwap(false, true) {
+		return
+	}
+
+	log.Warnf("eventbus connection lost: %v", err)
+
+	if k.onDisconnect != nil {
+		k.onDisconnect(err)
+	}
+}
+
+// Backoff 获取第N次重连前的等待时间
+func (k *Keeper) Backoff(attempts int) time.Duration {
+	return k.policy.Backoff(attempts)
+}
+
+// Reconnect 以指数退避的方式执行重连，直至重连成功或上下文结束
+func (k *Keeper) Reconnect(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := retry.Do(ctx, k.policy, fn,
+		retry.WithDelayFirst(true),
+		retry.WithClassifier(func(error) bool { return true }),
+		retry.WithNotifier(func(attempts int, err error, _ time.Duration) {
+			log.Warnf("eventbus reconnect failed, attempts: %d err: %v", attempts, err)
+		}),
+	)
+	if err != nil {
+		return err
+	}
+
+	k.Connected()
+
+	return nil
+}
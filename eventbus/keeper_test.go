@@ -0,0 +1,71 @@
+package eventbus_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/eventbus"
+	"testing"
+	"time"
+)
+
+func TestKeeper_Backoff(t *testing.T) {
+	keeper := eventbus.NewKeeper(eventbus.WithReconnectInterval(time.Second, 5*time.Second))
+
+	for attempts, want := range []time.Duration{time.Second, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if attempts == 0 {
+			continue
+		}
+
+		if got := keeper.Backoff(attempts); got != want {
+			t.Fatalf("attempts %d: want %v got %v", attempts, want, got)
+		}
+	}
+}
+
+func TestKeeper_Reconnect(t *testing.T) {
+	var connects, disconnects int
+
+	keeper := eventbus.NewKeeper(
+		eventbus.WithReconnectInterval(10*time.Millisecond, 40*time.Millisecond),
+		eventbus.WithConnectHandler(func() { connects++ }),
+		eventbus.WithDisconnectHandler(func(err error) { disconnects++ }),
+	)
+
+	keeper.Disconnected(errors.New("connection reset"))
+	keeper.Disconnected(errors.New("connection reset"))
+
+	if err := keeper.Check(); !errors.Is(err, errors.ErrDisconnected) {
+		t.Fatalf("expected disconnected, got %v", err)
+	}
+
+	attempts := 0
+	err := keeper.Reconnect(context.Background(), func(ctx context.Context) error {
+		if attempts++; attempts < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = keeper.Check(); err != nil {
+		t.Fatal(err)
+	}
+
+	if connects != 1 || disconnects != 1 || attempts != 3 {
+		t.Fatalf("unexpected callbacks, connects: %d disconnects: %d attempts: %d", connects, disconnects, attempts)
+	}
+}
+
+func TestKeeper_ReconnectCancel(t *testing.T) {
+	keeper := eventbus.NewKeeper(eventbus.WithReconnectInterval(time.Second, time.Second))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := keeper.Reconnect(ctx, func(ctx context.Context) error { return nil })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
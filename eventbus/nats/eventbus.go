@@ -8,8 +8,9 @@ import (
 )
 
 type Eventbus struct {
-	err  error
-	opts *options
+	err    error
+	opts   *options
+	keeper *eventbus.Keeper
 
 	rw        sync.RWMutex
 	consumers map[string]*consumer
@@ -24,9 +25,28 @@ func NewEventbus(opts ...Option) *Eventbus {
 	eb := &Eventbus{opts: o}
 	eb.opts = o
 	eb.consumers = make(map[string]*consumer)
+	eb.keeper = eventbus.NewKeeper(
+		eventbus.WithReconnectInterval(o.minReconnectInterval, o.maxReconnectInterval),
+		eventbus.WithConnectHandler(o.connectHandler),
+		eventbus.WithDisconnectHandler(o.disconnectHandler),
+	)
 
 	if o.conn == nil {
-		o.conn, eb.err = nats.Connect(o.url, nats.Timeout(o.timeout))
+		o.conn, eb.err = nats.Connect(o.url,
+			nats.Timeout(o.timeout),
+			nats.MaxReconnects(-1),
+			nats.CustomReconnectDelay(eb.keeper.Backoff),
+		)
+	}
+
+	if eb.err == nil {
+		// 重连成功后客户端会自动恢复所有订阅
+		o.conn.SetDisconnectErrHandler(func(conn *nats.Conn, err error) {
+			if !conn.IsClosed() {
+				eb.keeper.Disconnected(err)
+			}
+		})
+		o.conn.SetReconnectHandler(func(_ *nats.Conn) { eb.keeper.Connected() })
 	}
 
 	return eb
@@ -38,7 +58,11 @@ func (eb *Eventbus) Publish(ctx context.Context, topic string, payload interface
 		return eb.err
 	}
 
-	buf, err := serialize(topic, payload)
+	if err := eb.keeper.Check(); err != nil {
+		return err
+	}
+
+	buf, err := serialize(eventbus.EventID(ctx), topic, payload)
 	if err != nil {
 		return err
 	}
@@ -2,6 +2,7 @@ package nats
 
 import (
 	"github.com/dobyte/due/v2/etc"
+	"github.com/dobyte/due/v2/eventbus"
 	"github.com/nats-io/nats.go"
 	"time"
 )
@@ -9,11 +10,17 @@ import (
 const (
 	defaultUrl     = "nats://127.0.0.1:4222"
 	defaultTimeout = 2 * time.Second
+
+	defaultMinReconnectInterval = time.Second
+	defaultMaxReconnectInterval = 30 * time.Second
 )
 
 const (
 	defaultUrlKey     = "etc.eventbus.nats.url"
 	defaultTimeoutKey = "etc.eventbus.nats.timeout"
+
+	defaultMinReconnectIntervalKey = "etc.eventbus.nats.minReconnectInterval"
+	defaultMaxReconnectIntervalKey = "etc.eventbus.nats.maxReconnectInterval"
 )
 
 type Option func(o *options)
@@ -30,12 +37,31 @@ type options struct {
 	// 客户端连接
 	// 外部客户端连接配置，存在外部客户端连接时，优先使用外部客户端连接，默认为nil
 	conn *nats.Conn
+
+	// 重连最小间隔
+	// 连接断开后以指数退避的方式无限重连，仅对内建客户端连接生效，默认为1s
+	minReconnectInterval time.Duration
+
+	// 重连最大间隔
+	// 默认为30s
+	maxReconnectInterval time.Duration
+
+	// 连接恢复回调
+	// 默认为nil
+	connectHandler eventbus.ConnectHandler
+
+	// 连接断开回调
+	// 默认为nil
+	disconnectHandler eventbus.DisconnectHandler
 }
 
 func defaultOptions() *options {
 	return &options{
 		url:     etc.Get(defaultUrlKey, defaultUrl).String(),
 		timeout: etc.Get(defaultTimeoutKey, defaultTimeout).Duration(),
+
+		minReconnectInterval: etc.Get(defaultMinReconnectIntervalKey, defaultMinReconnectInterval).Duration(),
+		maxReconnectInterval: etc.Get(defaultMaxReconnectIntervalKey, defaultMaxReconnectInterval).Duration(),
 	}
 }
 
@@ -53,3 +79,18 @@ func WithTimeout(timeout time.Duration) Option {
 func WithConn(conn *nats.Conn) Option {
 	return func(o *options) { o.conn = conn }
 }
+
+// WithReconnectInterval 设置重连退避的最小与最大间隔
+func WithReconnectInterval(min, max time.Duration) Option {
+	return func(o *options) { o.minReconnectInterval, o.maxReconnectInterval = min, max }
+}
+
+// WithConnectHandler 设置连接恢复回调
+func WithConnectHandler(handler eventbus.ConnectHandler) Option {
+	return func(o *options) { o.connectHandler = handler }
+}
+
+// WithDisconnectHandler 设置连接断开回调
+func WithDisconnectHandler(handler eventbus.DisconnectHandler) Option {
+	return func(o *options) { o.disconnectHandler = handler }
+}
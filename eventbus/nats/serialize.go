@@ -6,7 +6,6 @@ import (
 	"github.com/dobyte/due/v2/eventbus"
 	"github.com/dobyte/due/v2/utils/xconv"
 	"github.com/dobyte/due/v2/utils/xtime"
-	"github.com/dobyte/due/v2/utils/xuuid"
 )
 
 type data struct {
@@ -17,9 +16,9 @@ type data struct {
 }
 
 // 序列化
-func serialize(topic string, payload interface{}) ([]byte, error) {
+func serialize(id, topic string, payload interface{}) ([]byte, error) {
 	return json.Marshal(&data{
-		ID:        xuuid.UUID(),
+		ID:        id,
 		Topic:     topic,
 		Payload:   xconv.String(payload),
 		Timestamp: xtime.Now().UnixNano(),
@@ -0,0 +1,215 @@
+package eventbus
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cache"
+	"github.com/dobyte/due/v2/log"
+	"github.com/dobyte/due/v2/utils/xconv"
+	"github.com/dobyte/due/v2/utils/xtime"
+	"github.com/dobyte/due/v2/utils/xuuid"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRelayInterval  = time.Second
+	defaultRelayBatchSize = 100
+	defaultDedupPrefix    = "outbox"
+)
+
+// OutboxRecord 发件箱事件记录
+type OutboxRecord struct {
+	ID        string `json:"id"`        // 记录ID，同一记录多次投递时保持不变，作为事件ID发布，可用于消费端去重
+	Topic     string `json:"topic"`     // 事件主题
+	Payload   string `json:"payload"`   // 事件载荷
+	Timestamp int64  `json:"timestamp"` // 写入时间
+}
+
+// NewOutboxRecord 创建发件箱事件记录
+func NewOutboxRecord(topic string, payload interface{}) *OutboxRecord {
+	return &OutboxRecord{
+		ID:        xuuid.UUID(),
+		Topic:     topic,
+		Payload:   xconv.String(payload),
+		Timestamp: xtime.Now().UnixNano(),
+	}
+}
+
+// OutboxStore 发件箱存储
+// 业务方应在与业务数据相同的事务中调用Append写入事件，由Relay异步投递至事件总线
+type OutboxStore interface {
+	// Append 写入事件记录
+	Append(ctx context.Context, records ...*OutboxRecord) error
+	// Fetch 按写入顺序拉取待投递的事件记录
+	Fetch(ctx context.Context, limit int) ([]*OutboxRecord, error)
+	// Ack 标记事件记录已投递
+	Ack(ctx context.Context, ids ...string) error
+}
+
+type RelayOption func(r *Relay)
+
+// Relay 发件箱投递器
+// 周期性地从发件箱中拉取事件并发布至事件总线，发布成功后再标记为已投递，保证事件至少投递一次
+type Relay struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	store     OutboxStore
+	eventbus  Eventbus
+	interval  time.Duration
+	batchSize int
+	wg        sync.WaitGroup
+}
+
+func NewRelay(store OutboxStore, opts ...RelayOption) *Relay {
+	r := &Relay{
+		store:     store,
+		interval:  defaultRelayInterval,
+		batchSize: defaultRelayBatchSize,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.interval <= 0 {
+		r.interval = defaultRelayInterval
+	}
+
+	if r.batchSize <= 0 {
+		r.batchSize = defaultRelayBatchSize
+	}
+
+	return r
+}
+
+// WithRelayEventbus 设置投递的事件总线，默认为全局事件总线
+func WithRelayEventbus(eb Eventbus) RelayOption {
+	return func(r *Relay) { r.eventbus = eb }
+}
+
+// WithRelayInterval 设置拉取间隔
+func WithRelayInterval(interval time.Duration) RelayOption {
+	return func(r *Relay) { r.interval = interval }
+}
+
+// WithRelayBatchSize 设置单次拉取的最大记录数
+func WithRelayBatchSize(size int) RelayOption {
+	return func(r *Relay) { r.batchSize = size }
+}
+
+// Start 启动投递器
+func (r *Relay) Start() {
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	r.wg.Add(1)
+
+	go r.run()
+}
+
+// Stop 停止投递器
+func (r *Relay) Stop() {
+	if r.cancel == nil {
+		return
+	}
+
+	r.cancel()
+	r.wg.Wait()
+}
+
+// Flush 执行一次投递，返回成功投递的记录数
+// 发布失败时立即停止，已发布成功的记录仍会被标记为已投递，失败的记录留待下次重试
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	records, err := r.store.Fetch(ctx, r.batchSize)
+	if err != nil || len(records) == 0 {
+		return 0, err
+	}
+
+	eb := r.eventbus
+	if eb == nil {
+		eb = GetEventbus()
+	}
+
+	ids := make([]string, 0, len(records))
+
+	for _, record := range records {
+		if err = eb.Publish(WithEventID(ctx, record.ID), record.Topic, record.Payload); err != nil {
+			break
+		}
+
+		ids = append(ids, record.ID)
+	}
+
+	if len(ids) > 0 {
+		if e := r.store.Ack(ctx, ids...); e != nil {
+			return 0, e
+		}
+	}
+
+	return len(ids), err
+}
+
+func (r *Relay) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				n, err := r.Flush(r.ctx)
+				if err != nil {
+					log.Warnf("outbox relay flush failed: %v", err)
+					break
+				}
+
+				if n < r.batchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// 支持原子写入的缓存，写入成功即表示抢占到事件的处理权
+type nxSetter interface {
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+}
+
+// Dedup 包装事件处理器，借助缓存对重复投递的事件进行去重
+// 发件箱记录以记录ID作为事件ID发布，处理前先以事件ID原子地抢占处理权，抢占失败则视为重复事件直接丢弃
+// 缓存实现SetNX时使用SetNX抢占，否则以IncrInt抢占后再设置过期时间
+func Dedup(c cache.Cache, ttl time.Duration, handler EventHandler) EventHandler {
+	return func(event *Event) {
+		ctx := context.Background()
+		key := defaultDedupPrefix + ":" + event.Topic + ":" + event.ID
+
+		if ok, err := claim(ctx, c, key, ttl); err != nil {
+			log.Warnf("outbox dedup claim failed, id: %s err: %v", event.ID, err)
+		} else if !ok {
+			return
+		}
+
+		handler(event)
+	}
+}
+
+// 抢占事件的处理权
+func claim(ctx context.Context, c cache.Cache, key string, ttl time.Duration) (bool, error) {
+	if s, ok := c.(nxSetter); ok {
+		return s.SetNX(ctx, key, 1, ttl)
+	}
+
+	n, err := c.IncrInt(ctx, key, 1)
+	if err != nil || n != 1 {
+		return false, err
+	}
+
+	if err = c.Set(ctx, key, 1, ttl); err != nil {
+		log.Warnf("outbox dedup expire failed, key: %s err: %v", key, err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,177 @@
+package eventbus_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cache"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/eventbus"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 内存发件箱，可模拟投递成功后标记失败的场景
+type memoryOutbox struct {
+	mu      sync.Mutex
+	records []*eventbus.OutboxRecord
+	ackErr  error
+}
+
+func (s *memoryOutbox) Append(ctx context.Context, records ...*eventbus.OutboxRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records = append(s.records, records...)
+
+	return nil
+}
+
+func (s *memoryOutbox) Fetch(ctx context.Context, limit int) ([]*eventbus.OutboxRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if limit > len(s.records) {
+		limit = len(s.records)
+	}
+
+	return append([]*eventbus.OutboxRecord(nil), s.records[:limit]...), nil
+}
+
+func (s *memoryOutbox) Ack(ctx context.Context, ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ackErr; err != nil {
+		s.ackErr = nil
+		return err
+	}
+
+	acked := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		acked[id] = struct{}{}
+	}
+
+	records := s.records[:0]
+	for _, record := range s.records {
+		if _, ok := acked[record.ID]; !ok {
+			records = append(records, record)
+		}
+	}
+	s.records = records
+
+	return nil
+}
+
+// 仅实现去重所需方法的内存缓存
+type memoryCache struct {
+	cache.Cache
+	keys   sync.Map
+	marked chan string
+}
+
+func (c *memoryCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	if _, loaded := c.keys.LoadOrStore(key, value); loaded {
+		return false, nil
+	}
+
+	c.marked <- key
+
+	return true, nil
+}
+
+// 未实现SetNX的内存缓存，去重时以自增抢占
+type counterCache struct {
+	cache.Cache
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func (c *counterCache) IncrInt(ctx context.Context, key string, value int64) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts[key] += value
+
+	return c.counts[key], nil
+}
+
+func (c *counterCache) Set(ctx context.Context, key string, value interface{}, expiration ...time.Duration) error {
+	return nil
+}
+
+func TestRelay_Flush(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		bus   = eventbus.NewEventbus()
+		store = &memoryOutbox{}
+		relay = eventbus.NewRelay(store, eventbus.WithRelayEventbus(bus))
+		ch    = make(chan *eventbus.Event, 4)
+		c     = &memoryCache{marked: make(chan string, 4)}
+	)
+
+	err := bus.Subscribe(ctx, "order", eventbus.Dedup(c, time.Minute, func(event *eventbus.Event) {
+		ch <- event
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := eventbus.NewOutboxRecord("order", "paid")
+
+	if err = store.Append(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟发布成功后标记失败，记录将被再次投递
+	store.ackErr = errors.New("connection reset")
+
+	if _, err = relay.Flush(ctx); err == nil {
+		t.Fatal("expected ack error")
+	}
+
+	select {
+	case event := <-ch:
+		if event.ID != record.ID || event.Payload.String() != "paid" {
+			t.Fatalf("unexpected event: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not delivered")
+	}
+
+	<-c.marked
+
+	n, err := relay.Flush(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 1 {
+		t.Fatalf("expected 1 record relayed, got %d", n)
+	}
+
+	select {
+	case event := <-ch:
+		t.Fatalf("duplicate event delivered: %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if n, _ = relay.Flush(ctx); n != 0 {
+		t.Fatalf("expected empty outbox, got %d", n)
+	}
+}
+
+func TestDedup_Incr(t *testing.T) {
+	var (
+		c       = &counterCache{counts: make(map[string]int64)}
+		handled = 0
+		handler = eventbus.Dedup(c, time.Minute, func(event *eventbus.Event) { handled++ })
+	)
+
+	handler(&eventbus.Event{ID: "1", Topic: "order"})
+	handler(&eventbus.Event{ID: "1", Topic: "order"})
+	handler(&eventbus.Event{ID: "2", Topic: "order"})
+
+	if handled != 2 {
+		t.Fatalf("expected 2 events handled, got %d", handled)
+	}
+}
@@ -2,6 +2,7 @@ package redis
 
 import (
 	"context"
+	xredis "github.com/dobyte/due/redis/v2"
 	"github.com/dobyte/due/v2/eventbus"
 	"github.com/dobyte/due/v2/utils/xconv"
 	"github.com/go-redis/redis/v8"
@@ -10,10 +11,13 @@ import (
 )
 
 type Eventbus struct {
-	ctx    context.Context
-	cancel context.CancelFunc
-	opts   *options
-	sub    *redis.PubSub
+	ctx     context.Context
+	cancel  context.CancelFunc
+	opts    *options
+	builtin bool
+	sub     *redis.PubSub
+	keeper  *eventbus.Keeper
+	done    chan struct{}
 
 	rw        sync.RWMutex
 	consumers map[string]*consumer
@@ -30,20 +34,34 @@ func NewEventbus(opts ...Option) *Eventbus {
 	}
 
 	if o.client == nil {
+		o.client = xredis.GetSharedClient()
+	}
+
+	eb := &Eventbus{}
+
+	if o.client == nil {
+		eb.builtin = true
 		o.client = redis.NewUniversalClient(&redis.UniversalOptions{
-			Addrs:      o.addrs,
-			DB:         o.db,
-			Username:   o.username,
-			Password:   o.password,
-			MaxRetries: o.maxRetries,
+			Addrs:            o.addrs,
+			DB:               o.db,
+			Username:         o.username,
+			Password:         o.password,
+			MaxRetries:       o.maxRetries,
+			MasterName:       o.masterName,
+			SentinelPassword: o.sentinelPassword,
 		})
 	}
 
-	eb := &Eventbus{}
 	eb.ctx, eb.cancel = context.WithCancel(o.ctx)
 	eb.opts = o
+	eb.keeper = eventbus.NewKeeper(
+		eventbus.WithReconnectInterval(o.minReconnectInterval, o.maxReconnectInterval),
+		eventbus.WithConnectHandler(o.connectHandler),
+		eventbus.WithDisconnectHandler(o.disconnectHandler),
+	)
 	eb.sub = eb.opts.client.Subscribe(eb.ctx)
 	eb.consumers = make(map[string]*consumer)
+	eb.done = make(chan struct{})
 	go eb.watch()
 
 	return eb
@@ -51,7 +69,11 @@ func NewEventbus(opts ...Option) *Eventbus {
 
 // Publish 发布事件
 func (eb *Eventbus) Publish(ctx context.Context, topic string, payload interface{}) error {
-	buf, err := serialize(topic, payload)
+	if err := eb.keeper.Check(); err != nil {
+		return err
+	}
+
+	buf, err := serialize(eventbus.EventID(ctx), topic, payload)
 	if err != nil {
 		return err
 	}
@@ -103,10 +125,22 @@ func (eb *Eventbus) Unsubscribe(ctx context.Context, topic string, handler event
 
 // watch 监听事件
 func (eb *Eventbus) watch() {
+	defer close(eb.done)
+
 	for {
 		iface, err := eb.sub.Receive(eb.ctx)
 		if err != nil {
-			return
+			if eb.ctx.Err() != nil {
+				return
+			}
+
+			eb.keeper.Disconnected(err)
+
+			if err = eb.keeper.Reconnect(eb.ctx, eb.resubscribe); err != nil {
+				return
+			}
+
+			continue
 		}
 
 		switch v := iface.(type) {
@@ -123,10 +157,51 @@ func (eb *Eventbus) watch() {
 	}
 }
 
+// 重新订阅所有主题
+func (eb *Eventbus) resubscribe(ctx context.Context) error {
+	if err := eb.sub.Ping(ctx); err != nil {
+		return err
+	}
+
+	eb.rw.RLock()
+	channels := make([]string, 0, len(eb.consumers))
+	for topic := range eb.consumers {
+		channels = append(channels, eb.buildChannelKey(topic))
+	}
+	eb.rw.RUnlock()
+
+	if len(channels) == 0 {
+		return nil
+	}
+
+	return eb.sub.Subscribe(ctx, channels...)
+}
+
 // Close 停止监听
 func (eb *Eventbus) Close() error {
+	return eb.Shutdown(context.Background())
+}
+
+// Shutdown 有序关闭事件总线
+// 依次停止监听并等待监听协程退出、关闭订阅连接，最后关闭内建客户端；共享客户端与外部客户端由调用方关闭
+func (eb *Eventbus) Shutdown(ctx context.Context) error {
 	eb.cancel()
-	return eb.sub.Close()
+
+	err := eb.sub.Close()
+
+	select {
+	case <-eb.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if eb.builtin {
+		if e := eb.opts.client.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	return err
 }
 
 // build channel key pass by topic
@@ -96,3 +96,53 @@ func TestEventbus_Publish(t *testing.T) {
 
 	t.Log("publish success")
 }
+
+func TestOutboxStore_Relay(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		bus    = eventbus.NewEventbus()
+		store  = redis.NewOutboxStore(nil, "due:outbox:test")
+		relay  = eventbus.NewRelay(store, eventbus.WithRelayEventbus(bus))
+		events = make(chan *eventbus.Event, 1)
+	)
+
+	err := bus.Subscribe(ctx, paidTopic, func(event *eventbus.Event) {
+		events <- event
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := eventbus.NewOutboxRecord(paidTopic, "order-1")
+
+	if err = store.Append(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := relay.Flush(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 1 {
+		t.Fatalf("expected 1 record relayed, got %d", n)
+	}
+
+	select {
+	case event := <-events:
+		if event.ID != record.ID || event.Payload.String() != "order-1" {
+			t.Fatalf("unexpected event: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected 1 event published, got none")
+	}
+
+	records, err := store.Fetch(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 0 {
+		t.Fatalf("expected empty outbox, got %d records", len(records))
+	}
+}
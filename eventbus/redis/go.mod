@@ -3,6 +3,7 @@ module github.com/dobyte/due/eventbus/redis/v2
 go 1.22.9
 
 require (
+	github.com/dobyte/due/redis/v2 v2.0.0
 	github.com/dobyte/due/v2 v2.2.4
 	github.com/go-redis/redis/v8 v8.11.5
 )
@@ -18,6 +19,7 @@ require (
 	github.com/fsnotify/fsnotify v1.8.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/jinzhu/copier v0.4.0 // indirect
+	github.com/klauspost/compress v1.16.7 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.8 // indirect
 	github.com/lestrrat-go/file-rotatelogs v2.4.0+incompatible // indirect
 	github.com/lestrrat-go/strftime v1.0.6 // indirect
@@ -33,3 +35,5 @@ require (
 )
 
 replace github.com/dobyte/due/v2 => ../../
+
+replace github.com/dobyte/due/redis/v2 => ../../redis
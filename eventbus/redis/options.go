@@ -3,7 +3,9 @@ package redis
 import (
 	"context"
 	"github.com/dobyte/due/v2/etc"
+	"github.com/dobyte/due/v2/eventbus"
 	"github.com/go-redis/redis/v8"
+	"time"
 )
 
 const (
@@ -11,15 +13,23 @@ const (
 	defaultDB         = 0
 	defaultMaxRetries = 3
 	defaultPrefix     = "due"
+
+	defaultMinReconnectInterval = "1s"
+	defaultMaxReconnectInterval = "30s"
 )
 
 const (
-	defaultAddrsKey      = "etc.eventbus.redis.addrs"
-	defaultDBKey         = "etc.eventbus.redis.db"
-	defaultMaxRetriesKey = "etc.eventbus.redis.maxRetries"
-	defaultPrefixKey     = "etc.eventbus.redis.prefix"
-	defaultUsernameKey   = "etc.eventbus.redis.username"
-	defaultPasswordKey   = "etc.eventbus.redis.password"
+	defaultAddrsKey            = "etc.eventbus.redis.addrs"
+	defaultDBKey               = "etc.eventbus.redis.db"
+	defaultMaxRetriesKey       = "etc.eventbus.redis.maxRetries"
+	defaultMasterNameKey       = "etc.eventbus.redis.masterName"
+	defaultSentinelPasswordKey = "etc.eventbus.redis.sentinelPassword"
+	defaultPrefixKey           = "etc.eventbus.redis.prefix"
+	defaultUsernameKey         = "etc.eventbus.redis.username"
+	defaultPasswordKey         = "etc.eventbus.redis.password"
+
+	defaultMinReconnectIntervalKey = "etc.eventbus.redis.minReconnectInterval"
+	defaultMaxReconnectIntervalKey = "etc.eventbus.redis.maxReconnectInterval"
 )
 
 type Option func(o *options)
@@ -47,24 +57,53 @@ type options struct {
 	// 内建客户端配置，默认为3次
 	maxRetries int
 
+	// 哨兵模式主节点名称
+	// 内建客户端配置，设置后将以哨兵模式连接，此时addrs为哨兵节点地址，默认为空
+	masterName string
+
+	// 哨兵节点密码
+	// 内建客户端配置，仅哨兵模式下生效，默认为空
+	sentinelPassword string
+
 	// 客户端
-	// 外部客户端配置，存在外部客户端时，优先使用外部客户端，默认为nil
+	// 外部客户端配置，存在外部客户端时，优先使用外部客户端；其次使用共享客户端（xredis.SetSharedClient）；默认为nil
 	client redis.UniversalClient
 
 	// 前缀
 	// key前缀，默认为due
 	prefix string
+
+	// 重连最小间隔
+	// 订阅连接断开后以指数退避的方式重连，默认为1s
+	minReconnectInterval time.Duration
+
+	// 重连最大间隔
+	// 默认为30s
+	maxReconnectInterval time.Duration
+
+	// 连接恢复回调
+	// 默认为nil
+	connectHandler eventbus.ConnectHandler
+
+	// 连接断开回调
+	// 默认为nil
+	disconnectHandler eventbus.DisconnectHandler
 }
 
 func defaultOptions() *options {
 	return &options{
-		ctx:        context.Background(),
-		addrs:      etc.Get(defaultAddrsKey, []string{defaultAddr}).Strings(),
-		db:         etc.Get(defaultDBKey, defaultDB).Int(),
-		maxRetries: etc.Get(defaultMaxRetriesKey, defaultMaxRetries).Int(),
-		prefix:     etc.Get(defaultPrefixKey, defaultPrefix).String(),
-		username:   etc.Get(defaultUsernameKey).String(),
-		password:   etc.Get(defaultPasswordKey).String(),
+		ctx:              context.Background(),
+		addrs:            etc.Get(defaultAddrsKey, []string{defaultAddr}).Strings(),
+		db:               etc.Get(defaultDBKey, defaultDB).Int(),
+		maxRetries:       etc.Get(defaultMaxRetriesKey, defaultMaxRetries).Int(),
+		masterName:       etc.Get(defaultMasterNameKey).String(),
+		sentinelPassword: etc.Get(defaultSentinelPasswordKey).String(),
+		prefix:           etc.Get(defaultPrefixKey, defaultPrefix).String(),
+		username:         etc.Get(defaultUsernameKey).String(),
+		password:         etc.Get(defaultPasswordKey).String(),
+
+		minReconnectInterval: etc.Get(defaultMinReconnectIntervalKey, defaultMinReconnectInterval).Duration(),
+		maxReconnectInterval: etc.Get(defaultMaxReconnectIntervalKey, defaultMaxReconnectInterval).Duration(),
 	}
 }
 
@@ -98,6 +137,16 @@ func WithMaxRetries(maxRetries int) Option {
 	return func(o *options) { o.maxRetries = maxRetries }
 }
 
+// WithMasterName 设置哨兵模式主节点名称，设置后将以哨兵模式连接，此时连接地址为哨兵节点地址
+func WithMasterName(masterName string) Option {
+	return func(o *options) { o.masterName = masterName }
+}
+
+// WithSentinelPassword 设置哨兵节点密码
+func WithSentinelPassword(password string) Option {
+	return func(o *options) { o.sentinelPassword = password }
+}
+
 // WithClient 设置外部客户端
 func WithClient(client redis.UniversalClient) Option {
 	return func(o *options) { o.client = client }
@@ -107,3 +156,18 @@ func WithClient(client redis.UniversalClient) Option {
 func WithPrefix(prefix string) Option {
 	return func(o *options) { o.prefix = prefix }
 }
+
+// WithReconnectInterval 设置重连退避的最小与最大间隔
+func WithReconnectInterval(min, max time.Duration) Option {
+	return func(o *options) { o.minReconnectInterval, o.maxReconnectInterval = min, max }
+}
+
+// WithConnectHandler 设置连接恢复回调
+func WithConnectHandler(handler eventbus.ConnectHandler) Option {
+	return func(o *options) { o.connectHandler = handler }
+}
+
+// WithDisconnectHandler 设置连接断开回调
+func WithDisconnectHandler(handler eventbus.DisconnectHandler) Option {
+	return func(o *options) { o.disconnectHandler = handler }
+}
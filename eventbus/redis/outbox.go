@@ -0,0 +1,155 @@
+package redis
+
+import (
+	"context"
+	xredis "github.com/dobyte/due/redis/v2"
+	"github.com/dobyte/due/v2/encoding/json"
+	"github.com/dobyte/due/v2/eventbus"
+	"github.com/dobyte/due/v2/log"
+	"github.com/go-redis/redis/v8"
+	"strings"
+)
+
+const defaultOutboxKey = defaultPrefix + ":outbox"
+
+// OutboxStore 基于Redis的发件箱存储
+// 记录ID按写入顺序保存在列表中，记录内容保存在哈希表中；两者的键使用相同的哈希标签，确保集群模式下可在同一事务中写入
+type OutboxStore struct {
+	client redis.UniversalClient
+	key    string
+}
+
+var _ eventbus.OutboxStore = &OutboxStore{}
+
+// NewOutboxStore 创建Redis发件箱存储，client为空时优先使用共享客户端，其次按配置创建客户端；key为空时使用due:outbox
+// key未包含哈希标签时将整体作为哈希标签，例如due:outbox对应的键为{due:outbox}及{due:outbox}:records
+func NewOutboxStore(client redis.UniversalClient, key string) *OutboxStore {
+	if client == nil {
+		client = xredis.GetSharedClient()
+	}
+
+	if client == nil {
+		o := defaultOptions()
+		client = redis.NewUniversalClient(&redis.UniversalOptions{
+			Addrs:            o.addrs,
+			DB:               o.db,
+			Username:         o.username,
+			Password:         o.password,
+			MaxRetries:       o.maxRetries,
+			MasterName:       o.masterName,
+			SentinelPassword: o.sentinelPassword,
+		})
+	}
+
+	if key == "" {
+		key = defaultOutboxKey
+	}
+
+	if !hasHashTag(key) {
+		key = "{" + key + "}"
+	}
+
+	return &OutboxStore{client: client, key: key}
+}
+
+// Append 写入事件记录
+func (s *OutboxStore) Append(ctx context.Context, records ...*eventbus.OutboxRecord) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		return s.AppendTx(ctx, pipe, records...)
+	})
+
+	return err
+}
+
+// AppendTx 在调用方的事务中写入事件记录，使事件与业务数据的写入同时生效
+// 例如：client.TxPipelined(ctx, func(pipe redis.Pipeliner) error { pipe.Set(...); return store.AppendTx(ctx, pipe, record) })
+func (s *OutboxStore) AppendTx(ctx context.Context, pipe redis.Pipeliner, records ...*eventbus.OutboxRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	ids := make([]interface{}, 0, len(records))
+	values := make([]interface{}, 0, 2*len(records))
+
+	for _, record := range records {
+		buf, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+
+		ids = append(ids, record.ID)
+		values = append(values, record.ID, buf)
+	}
+
+	pipe.HSet(ctx, s.buildRecordsKey(), values...)
+	pipe.RPush(ctx, s.key, ids...)
+
+	return nil
+}
+
+// Fetch 按写入顺序拉取待投递的事件记录
+func (s *OutboxStore) Fetch(ctx context.Context, limit int) ([]*eventbus.OutboxRecord, error) {
+	ids, err := s.client.LRange(ctx, s.key, 0, int64(limit)-1).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	values, err := s.client.HMGet(ctx, s.buildRecordsKey(), ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*eventbus.OutboxRecord, 0, len(values))
+
+	for i, value := range values {
+		v, ok := value.(string)
+		if !ok {
+			// 记录内容缺失时直接从列表中移除，避免阻塞后续投递
+			log.Warnf("outbox record missing, id: %s", ids[i])
+			_ = s.client.LRem(ctx, s.key, 1, ids[i]).Err()
+			continue
+		}
+
+		record := &eventbus.OutboxRecord{}
+
+		if err = json.Unmarshal([]byte(v), record); err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// Ack 标记事件记录已投递
+func (s *OutboxStore) Ack(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, id := range ids {
+			pipe.LRem(ctx, s.key, 1, id)
+		}
+
+		pipe.HDel(ctx, s.buildRecordsKey(), ids...)
+
+		return nil
+	})
+
+	return err
+}
+
+func (s *OutboxStore) buildRecordsKey() string {
+	return s.key + ":records"
+}
+
+// 检测键是否包含哈希标签
+func hasHashTag(key string) bool {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		return strings.IndexByte(key[start+1:], '}') > 0
+	}
+
+	return false
+}
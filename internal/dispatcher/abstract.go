@@ -4,6 +4,7 @@ import (
 	"github.com/dobyte/due/v2/cluster"
 	"github.com/dobyte/due/v2/core/endpoint"
 	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/registry"
 	"math/rand/v2"
 	"sync"
 	"sync/atomic"
@@ -13,8 +14,11 @@ type serviceEndpoint struct {
 	insID    string
 	state    string
 	endpoint *endpoint.Endpoint
+	instance *registry.ServiceInstance
 }
 
+func noop() {}
+
 type abstract struct {
 	counter    atomic.Uint64
 	dispatcher *Dispatcher
@@ -22,6 +26,7 @@ type abstract struct {
 	endpoints2 map[string]*serviceEndpoint // 所有端口（包含work、busy、hang、shut状态的实例）
 	endpoints3 []*serviceEndpoint          // 所有端口（包含work、busy状态的实例）
 	endpoints4 map[string]*serviceEndpoint // 所有端口（包含work、busy状态的实例）
+	candidates []*registry.ServiceInstance // 负载均衡器的候选实例（包含work、busy状态的实例）
 	// 加权轮询相关字段
 	currentQueue *wrrQueue  // 当前队列
 	nextQueue    *wrrQueue  // 下一个队列
@@ -59,6 +64,64 @@ func (a *abstract) FindEndpoint(insID ...string) (*endpoint.Endpoint, error) {
 	return a.directDispatch(insID[0])
 }
 
+// SelectEndpoint 选择服务端点，指定实例ID时直接分配，否则优先使用负载均衡器进行选择
+// key为负载均衡器的选择依据；返回的done需在调用结束后执行，用于负载均衡器统计进行中的请求
+func (a *abstract) SelectEndpoint(key string, insID ...string) (*endpoint.Endpoint, func(), error) {
+	balancer := a.dispatcher.balancer
+
+	if balancer == nil || (len(insID) > 0 && insID[0] != "") {
+		ep, err := a.FindEndpoint(insID...)
+		return ep, noop, err
+	}
+
+	ins := balancer.Select(a.candidates, key)
+	if ins == nil {
+		return nil, noop, errors.ErrNotFoundEndpoint
+	}
+
+	sep, ok := a.endpoints4[ins.ID]
+	if !ok {
+		if tracker, ok := balancer.(registry.Tracker); ok {
+			tracker.Done(ins)
+		}
+		return nil, noop, errors.ErrNotFoundEndpoint
+	}
+
+	if tracker, ok := balancer.(registry.Tracker); ok {
+		return sep.endpoint, func() { tracker.Done(ins) }, nil
+	}
+
+	return sep.endpoint, noop, nil
+}
+
+// Balanced 是否设置了负载均衡器
+func (a *abstract) Balanced() bool {
+	return a.dispatcher.balancer != nil
+}
+
+// SelectInstance 通过负载均衡器选择实例ID，用于为未绑定的用户选择绑定目标
+func (a *abstract) SelectInstance(key string) (string, error) {
+	balancer := a.dispatcher.balancer
+	if balancer == nil {
+		return "", errors.ErrNotFoundEndpoint
+	}
+
+	ins := balancer.Select(a.candidates, key)
+	if ins == nil {
+		return "", errors.ErrNotFoundEndpoint
+	}
+
+	if tracker, ok := balancer.(registry.Tracker); ok {
+		tracker.Done(ins)
+	}
+
+	if _, ok := a.endpoints4[ins.ID]; !ok {
+		return "", errors.ErrNotFoundEndpoint
+	}
+
+	return ins.ID, nil
+}
+
 // IterateEndpoint 迭代服务端口
 func (a *abstract) IterateEndpoint(fn func(insID string, ep *endpoint.Endpoint) bool) {
 	for _, se := range a.endpoints1 {
@@ -69,27 +132,22 @@ func (a *abstract) IterateEndpoint(fn func(insID string, ep *endpoint.Endpoint)
 }
 
 // 添加服务端点
-func (a *abstract) addEndpoint(insID string, state string, endpoint *endpoint.Endpoint) {
+func (a *abstract) addEndpoint(instance *registry.ServiceInstance, endpoint *endpoint.Endpoint) {
+	insID, state := instance.ID, instance.State
+
 	if se, ok := a.endpoints2[insID]; ok {
 		se.state = state
 		se.endpoint = endpoint
+		se.instance = instance
 	} else {
-		se = &serviceEndpoint{insID: insID, state: state, endpoint: endpoint}
+		se = &serviceEndpoint{insID: insID, state: state, endpoint: endpoint, instance: instance}
 		a.endpoints1 = append(a.endpoints1, se)
 		a.endpoints2[insID] = se
 	}
 
-	switch state {
-	case cluster.Work.String(), cluster.Busy.String():
-		if se, ok := a.endpoints4[insID]; ok {
-			se.state = state
-			se.endpoint = endpoint
-		} else {
-			se = &serviceEndpoint{insID: insID, state: state, endpoint: endpoint}
-			a.endpoints3 = append(a.endpoints3, se)
-			a.endpoints4[insID] = se
-		}
-	case cluster.Hang.String():
+	// 处于警告或异常健康状态的实例不参与负载均衡，但仍可直接分配
+	switch {
+	case state == cluster.Hang.String(), state == cluster.Drain.String(), instance.Health.Unhealthy():
 		if _, ok := a.endpoints4[insID]; ok {
 			delete(a.endpoints4, insID)
 
@@ -100,6 +158,25 @@ func (a *abstract) addEndpoint(insID string, state string, endpoint *endpoint.En
 				}
 			}
 		}
+	case state == cluster.Work.String(), state == cluster.Busy.String():
+		if se, ok := a.endpoints4[insID]; ok {
+			se.state = state
+			se.endpoint = endpoint
+			se.instance = instance
+		} else {
+			se = &serviceEndpoint{insID: insID, state: state, endpoint: endpoint, instance: instance}
+			a.endpoints3 = append(a.endpoints3, se)
+			a.endpoints4[insID] = se
+		}
+	}
+}
+
+// 初始化负载均衡器的候选实例
+func (a *abstract) initCandidates() {
+	a.candidates = make([]*registry.ServiceInstance, 0, len(a.endpoints3))
+
+	for _, se := range a.endpoints3 {
+		a.candidates = append(a.candidates, se.instance)
 	}
 }
 
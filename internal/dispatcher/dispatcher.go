@@ -18,6 +18,7 @@ const (
 
 type Dispatcher struct {
 	strategy  BalanceStrategy
+	balancer  registry.Balancer
 	rw        sync.RWMutex
 	routes    map[int32]*Route
 	events    map[int]*Event
@@ -25,8 +26,15 @@ type Dispatcher struct {
 	instances map[string]*registry.ServiceInstance
 }
 
-func NewDispatcher(strategy BalanceStrategy) *Dispatcher {
-	return &Dispatcher{strategy: strategy}
+// NewDispatcher 创建分发器，设置负载均衡器后将优先使用负载均衡器选择服务端点
+func NewDispatcher(strategy BalanceStrategy, balancer ...registry.Balancer) *Dispatcher {
+	d := &Dispatcher{strategy: strategy}
+
+	if len(balancer) > 0 {
+		d.balancer = balancer[0]
+	}
+
+	return d
 }
 
 // FindEndpoint 查找服务端口
@@ -104,7 +112,7 @@ func (d *Dispatcher) ReplaceServices(services ...*registry.ServiceInstance) {
 				route = newRoute(d, item.ID, service.Alias, item.Stateful, item.Internal)
 				routes[item.ID] = route
 			}
-			route.addEndpoint(service.ID, service.State, ep)
+			route.addEndpoint(service, ep)
 		}
 
 		for _, evt := range service.Events {
@@ -113,7 +121,16 @@ func (d *Dispatcher) ReplaceServices(services ...*registry.ServiceInstance) {
 				event = newEvent(d, evt)
 				events[evt] = event
 			}
-			event.addEndpoint(service.ID, service.State, ep)
+			event.addEndpoint(service, ep)
+		}
+	}
+
+	if d.balancer != nil {
+		for _, route := range routes {
+			route.initCandidates()
+		}
+		for _, event := range events {
+			event.initCandidates()
 		}
 	}
 
@@ -3,8 +3,10 @@ package dispatcher_test
 import (
 	"github.com/dobyte/due/v2/cluster"
 	"github.com/dobyte/due/v2/core/endpoint"
+	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/internal/dispatcher"
 	"github.com/dobyte/due/v2/registry"
+	"strconv"
 	"testing"
 	"math"
 	"fmt"
@@ -303,4 +305,166 @@ func BenchmarkDispatcher_WeightRoundRobin(b *testing.B) {
             b.ReportAllocs()
         })
     }
-}
\ No newline at end of file
+}
+func TestDispatcher_Balancer(t *testing.T) {
+	var (
+		instance1 = &registry.ServiceInstance{
+			ID:       "xa",
+			Kind:     cluster.Node.String(),
+			State:    cluster.Work.String(),
+			Endpoint: endpoint.NewEndpoint("grpc", "127.0.0.1:8001", false).String(),
+			Routes:   []registry.Route{{ID: 1}},
+		}
+		instance2 = &registry.ServiceInstance{
+			ID:       "xb",
+			Kind:     cluster.Node.String(),
+			State:    cluster.Hang.String(),
+			Endpoint: endpoint.NewEndpoint("grpc", "127.0.0.1:8002", false).String(),
+			Routes:   []registry.Route{{ID: 1}},
+		}
+	)
+
+	d := dispatcher.NewDispatcher(dispatcher.Random, registry.NewLeastConnBalancer())
+
+	d.ReplaceServices(instance1, instance2)
+
+	route, err := d.FindRoute(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		ep, done, err := route.SelectEndpoint("1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ep.Address() != "127.0.0.1:8001" {
+			t.Fatalf("hanged instance selected: %s", ep.Address())
+		}
+
+		done()
+	}
+
+	ep, _, err := route.SelectEndpoint("", "xb")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ep.Address() != "127.0.0.1:8002" {
+		t.Fatalf("unexpected direct endpoint: %s", ep.Address())
+	}
+}
+
+func TestDispatcher_SelectInstance(t *testing.T) {
+	var (
+		stable = &registry.ServiceInstance{
+			ID:       "stable",
+			Kind:     cluster.Node.String(),
+			State:    cluster.Work.String(),
+			Endpoint: endpoint.NewEndpoint("grpc", "127.0.0.1:8001", false).String(),
+			Routes:   []registry.Route{{ID: 1, Stateful: true}},
+		}
+		canary = &registry.ServiceInstance{
+			ID:       "canary",
+			Kind:     cluster.Node.String(),
+			State:    cluster.Work.String(),
+			Endpoint: endpoint.NewEndpoint("grpc", "127.0.0.1:8002", false).String(),
+			Routes:   []registry.Route{{ID: 1, Stateful: true}},
+			Tags:     []string{registry.CanaryTag},
+		}
+		balancer = registry.NewCanaryBalancer(100, nil)
+	)
+
+	d := dispatcher.NewDispatcher(dispatcher.Random, balancer)
+
+	d.ReplaceServices(stable, canary)
+
+	route, err := d.FindRoute(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !route.Balanced() {
+		t.Fatal("route not balanced")
+	}
+
+	// 有状态路由的绑定目标按金丝雀分流选择
+	for i := 0; i < 10; i++ {
+		insID, err := route.SelectInstance(strconv.Itoa(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if insID != canary.ID {
+			t.Fatalf("unexpected bind target: %s", insID)
+		}
+	}
+
+	if stat := balancer.Stat(); stat.CanarySessions != 10 {
+		t.Fatalf("unexpected canary stat: %+v", stat)
+	}
+
+	d = dispatcher.NewDispatcher(dispatcher.Random)
+
+	d.ReplaceServices(stable, canary)
+
+	if route, err = d.FindRoute(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = route.SelectInstance("1"); err != errors.ErrNotFoundEndpoint {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDispatcher_UnhealthyInstance(t *testing.T) {
+	var (
+		instance1 = &registry.ServiceInstance{
+			ID:       "xa",
+			Kind:     cluster.Node.String(),
+			State:    cluster.Work.String(),
+			Endpoint: endpoint.NewEndpoint("grpc", "127.0.0.1:8001", false).String(),
+			Routes:   []registry.Route{{ID: 1}},
+			Health:   registry.HealthPassing,
+		}
+		instance2 = &registry.ServiceInstance{
+			ID:       "xb",
+			Kind:     cluster.Node.String(),
+			State:    cluster.Work.String(),
+			Endpoint: endpoint.NewEndpoint("grpc", "127.0.0.1:8002", false).String(),
+			Routes:   []registry.Route{{ID: 1}},
+			Health:   registry.HealthWarning,
+		}
+		instance3 = &registry.ServiceInstance{
+			ID:       "xc",
+			Kind:     cluster.Node.String(),
+			State:    cluster.Work.String(),
+			Endpoint: endpoint.NewEndpoint("grpc", "127.0.0.1:8003", false).String(),
+			Routes:   []registry.Route{{ID: 1}},
+			Health:   registry.HealthCritical,
+		}
+	)
+
+	d := dispatcher.NewDispatcher(dispatcher.RoundRobin)
+
+	d.ReplaceServices(instance1, instance2, instance3)
+
+	route, err := d.FindRoute(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		ep, done, err := route.SelectEndpoint("1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ep.Address() != "127.0.0.1:8001" {
+			t.Fatalf("unhealthy instance selected: %s", ep.Address())
+		}
+
+		done()
+	}
+}
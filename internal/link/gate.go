@@ -5,6 +5,7 @@ import (
 	"github.com/dobyte/due/v2/cluster"
 	"github.com/dobyte/due/v2/core/buffer"
 	"github.com/dobyte/due/v2/core/endpoint"
+	"github.com/dobyte/due/v2/encoding"
 	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/internal/dispatcher"
 	"github.com/dobyte/due/v2/internal/transporter/gate"
@@ -31,8 +32,8 @@ func NewGateLinker(ctx context.Context, opts *Options) *GateLinker {
 	l := &GateLinker{
 		ctx:        ctx,
 		opts:       opts,
-		builder:    gate.NewBuilder(&gate.Options{InsID: opts.InsID, InsKind: opts.InsKind}),
-		dispatcher: dispatcher.NewDispatcher(opts.BalanceStrategy),
+		builder:    gate.NewBuilder(&gate.Options{InsID: opts.InsID, InsKind: opts.InsKind, AuditWindow: opts.AuditWindow, SeqGenerator: opts.SeqGenerator, CloseReasonHandler: opts.CloseReasonHandler}),
+		dispatcher: dispatcher.NewDispatcher(opts.BalanceStrategy, opts.Balancer),
 	}
 
 	return l
@@ -303,6 +304,74 @@ func (l *GateLinker) doIndirectDisconnect(ctx context.Context, uid int64, force
 	return err
 }
 
+// Kick 踢用户下线，由用户所在的网关下发踢下线通知消息后断开连接
+// 返回值为true时表示已找到用户并踢下线，用户不在线或并发断开连接时返回false
+func (l *GateLinker) Kick(ctx context.Context, args *KickArgs) (bool, error) {
+	if args.GID != "" {
+		return l.doDirectKick(ctx, args)
+	}
+
+	if presence, ok := l.opts.Locator.(locate.Presence); ok {
+		if _, _, online, err := presence.Locate(ctx, args.UID, ""); err != nil {
+			return false, err
+		} else if !online {
+			return false, nil
+		}
+	}
+
+	messages, err := l.doPackKickMessages(args)
+	if err != nil {
+		return false, err
+	}
+
+	v, err := l.doRPC(ctx, args.UID, func(client *gate.Client) (bool, interface{}, error) {
+		miss, err := client.Kick(ctx, args.UID, messages)
+		return miss, miss, err
+	})
+	if err != nil {
+		if errors.Is(err, errors.ErrNotFoundUserLocation) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if miss, _ := v.(bool); miss {
+		l.sources.Delete(args.UID)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// 直接踢掉指定网关上的用户连接
+func (l *GateLinker) doDirectKick(ctx context.Context, args *KickArgs) (bool, error) {
+	messages, err := l.doPackKickMessages(args)
+	if err != nil {
+		return false, err
+	}
+
+	client, err := l.doBuildClient(args.GID)
+	if err != nil {
+		return false, err
+	}
+
+	miss, err := client.Kick(ctx, args.UID, messages)
+	if err != nil {
+		return false, err
+	}
+
+	return !miss, nil
+}
+
+// 打包踢下线通知消息，未携带通知消息时返回空
+func (l *GateLinker) doPackKickMessages(args *KickArgs) (map[string][]byte, error) {
+	if args.Message == nil {
+		return nil, nil
+	}
+
+	return l.doPackCodecMessages(args.Message, args.Codecs)
+}
+
 // Push 推送消息
 func (l *GateLinker) Push(ctx context.Context, args *PushArgs) error {
 	switch args.Kind {
@@ -331,7 +400,7 @@ func (l *GateLinker) doDirectPush(ctx context.Context, args *PushArgs) error {
 		return err
 	}
 
-	return client.Push(ctx, args.Kind, args.Target, message)
+	return client.PushWithHeader(ctx, args.Kind, args.Target, args.Header, message)
 }
 
 // 间接推送
@@ -342,7 +411,7 @@ func (l *GateLinker) doIndirectPush(ctx context.Context, args *PushArgs) error {
 	}
 
 	_, err = l.doRPC(ctx, args.Target, func(client *gate.Client) (bool, interface{}, error) {
-		return false, nil, client.Push(ctx, args.Kind, args.Target, message)
+		return false, nil, client.PushWithHeader(ctx, args.Kind, args.Target, args.Header, message)
 	})
 
 	return err
@@ -383,33 +452,27 @@ func (l *GateLinker) doDirectMulticast(ctx context.Context, args *MulticastArgs)
 	return client.Multicast(ctx, args.Kind, args.Targets, message)
 }
 
-// 间接推送组播消息
+// 间接推送组播消息，消息帧仅打包一次并由所有接收方共享
 func (l *GateLinker) doIndirectMulticast(ctx context.Context, args *MulticastArgs) error {
 	if len(args.Targets) == 0 {
 		return errors.ErrReceiveTargetEmpty
 	}
 
-	buf, err := l.PackBuffer(args.Message.Data, true)
+	message, err := l.PackMessage(args.Message, true)
 	if err != nil {
 		return err
 	}
 
+	shared := buffer.NewSharedBuffer(message)
+	defer shared.Release()
+
 	eg, ctx := errgroup.WithContext(ctx)
 
 	for _, target := range args.Targets {
 		func(target int64) {
 			eg.Go(func() error {
-				message, err := packet.PackBuffer(&packet.Message{
-					Seq:    args.Message.Seq,
-					Route:  args.Message.Route,
-					Buffer: buf,
-				})
-				if err != nil {
-					return err
-				}
-
-				_, err = l.doRPC(ctx, target, func(client *gate.Client) (bool, interface{}, error) {
-					return false, nil, client.Push(ctx, args.Kind, target, message)
+				_, err := l.doRPC(ctx, target, func(client *gate.Client) (bool, interface{}, error) {
+					return false, nil, client.Push(ctx, args.Kind, target, shared.Ref())
 				})
 				return err
 			})
@@ -420,31 +483,56 @@ func (l *GateLinker) doIndirectMulticast(ctx context.Context, args *MulticastArg
 }
 
 // Broadcast 推送广播消息
+// 广播消息帧与接收方无关，仅打包一次并由所有网关共享
 func (l *GateLinker) Broadcast(ctx context.Context, args *BroadcastArgs) error {
-	buf, err := l.PackBuffer(args.Message.Data, true)
+	message, err := l.PackMessage(args.Message, true)
 	if err != nil {
 		return err
 	}
 
+	shared := buffer.NewSharedBuffer(message)
+	defer shared.Release()
+
 	eg, ctx := errgroup.WithContext(ctx)
 
 	l.dispatcher.IterateEndpoint(func(_ string, ep *endpoint.Endpoint) bool {
 		eg.Go(func() error {
-			message, err := packet.PackBuffer(&packet.Message{
-				Seq:    args.Message.Seq,
-				Route:  args.Message.Route,
-				Buffer: buf,
-			})
+			client, err := l.builder.Build(ep.Address())
 			if err != nil {
 				return err
 			}
 
+			return client.Broadcast(ctx, args.Kind, shared.Ref())
+		})
+
+		return true
+	})
+
+	return eg.Wait()
+}
+
+// BroadcastCodecs 按客户端连接协商的编解码器推送广播消息
+// 消息分别以默认编解码器及codecs中的各编解码器打包，网关按连接协商的编解码器选择对应的消息；codecs为空时等同于Broadcast
+func (l *GateLinker) BroadcastCodecs(ctx context.Context, args *BroadcastArgs, codecs []encoding.Codec) error {
+	if len(codecs) == 0 {
+		return l.Broadcast(ctx, args)
+	}
+
+	messages, err := l.doPackCodecMessages(args.Message, codecs)
+	if err != nil {
+		return err
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+
+	l.dispatcher.IterateEndpoint(func(_ string, ep *endpoint.Endpoint) bool {
+		eg.Go(func() error {
 			client, err := l.builder.Build(ep.Address())
 			if err != nil {
 				return err
 			}
 
-			return client.Broadcast(ctx, args.Kind, message)
+			return client.BroadcastCodecs(ctx, args.Kind, messages)
 		})
 
 		return true
@@ -453,6 +541,35 @@ func (l *GateLinker) Broadcast(ctx context.Context, args *BroadcastArgs) error {
 	return eg.Wait()
 }
 
+// 分别以默认编解码器及codecs中的各编解码器打包消息，以默认编解码器打包的消息对应的编解码器名称为空
+func (l *GateLinker) doPackCodecMessages(message *Message, codecs []encoding.Codec) (map[string][]byte, error) {
+	buf, err := l.PackMessage(message, true)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make(map[string][]byte, len(codecs)+1)
+	messages[""] = buf.Bytes()
+
+	for _, codec := range codecs {
+		data, err := l.PackBufferWithCodec(codec, message.Data, true)
+		if err != nil {
+			log.Warnf("message marshal failed, codec: %s err: %v", codec.Name(), err)
+			continue
+		}
+
+		if messages[codec.Name()], err = packet.PackMessage(&packet.Message{
+			Seq:    message.Seq,
+			Route:  message.Route,
+			Buffer: data,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return messages, nil
+}
+
 // 执行RPC调用
 func (l *GateLinker) doRPC(ctx context.Context, uid int64, fn func(client *gate.Client) (bool, interface{}, error)) (interface{}, error) {
 	var (
@@ -522,6 +639,11 @@ func (l *GateLinker) PackMessage(message *Message, encrypt bool) (buffer.Buffer,
 
 // PackBuffer 消息转buffer
 func (l *GateLinker) PackBuffer(message interface{}, encrypt bool) ([]byte, error) {
+	return l.PackBufferWithCodec(l.opts.Codec, message, encrypt)
+}
+
+// PackBufferWithCodec 使用指定的编解码器将消息转buffer
+func (l *GateLinker) PackBufferWithCodec(codec encoding.Codec, message interface{}, encrypt bool) ([]byte, error) {
 	if message == nil {
 		return nil, nil
 	}
@@ -530,7 +652,7 @@ func (l *GateLinker) PackBuffer(message interface{}, encrypt bool) ([]byte, erro
 		return v, nil
 	}
 
-	data, err := l.opts.Codec.Marshal(message)
+	data, err := codec.Marshal(message)
 	if err != nil {
 		return nil, err
 	}
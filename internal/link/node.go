@@ -12,31 +12,69 @@ import (
 	"github.com/dobyte/due/v2/packet"
 	"github.com/dobyte/due/v2/registry"
 	"golang.org/x/sync/errgroup"
+	"strconv"
 	"sync"
 	"time"
 )
 
 type NodeLinker struct {
-	ctx        context.Context             // 上下文
-	opts       *Options                    // 参数项
-	builder    *node.Builder               // 构建器
-	dispatcher *dispatcher.Dispatcher      // 分发器
-	rw         sync.RWMutex                // 锁
-	sources    map[int64]map[string]string // 用户来源节点
+	ctx        context.Context                      // 上下文
+	opts       *Options                             // 参数项
+	builder    *node.Builder                        // 构建器
+	dispatcher *dispatcher.Dispatcher               // 分发器
+	rw         sync.RWMutex                         // 锁
+	sources    map[int64]map[string]string          // 用户来源节点
+	hedgings   map[int32]time.Duration              // 请求对冲路由
+	statefuls  map[string]*registry.ServiceInstance // 有状态节点实例
+	lmu        sync.Mutex                           // 丢失节点锁
+	losts      map[string]context.CancelFunc        // 待确认丢失的有状态节点
 }
 
 func NewNodeLinker(ctx context.Context, opts *Options) *NodeLinker {
 	l := &NodeLinker{
 		ctx:        ctx,
 		opts:       opts,
-		builder:    node.NewBuilder(&node.Options{InsID: opts.InsID, InsKind: opts.InsKind}),
-		dispatcher: dispatcher.NewDispatcher(opts.BalanceStrategy),
+		builder:    node.NewBuilder(&node.Options{InsID: opts.InsID, InsKind: opts.InsKind, AuditWindow: opts.AuditWindow, SeqGenerator: opts.SeqGenerator, CloseReasonHandler: opts.CloseReasonHandler}),
+		dispatcher: dispatcher.NewDispatcher(opts.BalanceStrategy, opts.Balancer),
 		sources:    make(map[int64]map[string]string),
+		hedgings:   make(map[int32]time.Duration),
+		statefuls:  make(map[string]*registry.ServiceInstance),
+		losts:      make(map[string]context.CancelFunc),
+	}
+
+	for _, item := range opts.HedgingRoutes {
+		if item.Enable && item.Idempotent && item.Delay > 0 {
+			l.hedgings[item.Route] = item.Delay
+		}
+	}
+
+	if feeder, ok := opts.Balancer.(registry.Feeder); ok {
+		feeder.Feed(l.doLoad)
 	}
 
 	return l
 }
 
+// InflightStats 获取各节点地址进行中的调用数
+func (l *NodeLinker) InflightStats() map[string]int64 {
+	return l.builder.InflightStats()
+}
+
+// AuditStats 获取各节点地址的序列号审计统计
+func (l *NodeLinker) AuditStats() map[string]cluster.AuditStat {
+	return l.builder.AuditStats()
+}
+
+// 获取节点进行中的调用数，供负载均衡器使用
+func (l *NodeLinker) doLoad(ins *registry.ServiceInstance) int64 {
+	ep, err := l.dispatcher.FindEndpoint(ins.ID)
+	if err != nil {
+		return 0
+	}
+
+	return l.builder.Inflight(ep.Address())
+}
+
 // Ask 检测用户是否在给定的节点上
 func (l *NodeLinker) Ask(ctx context.Context, uid int64, name, nid string) (string, bool, error) {
 	if l.opts.Locator == nil {
@@ -146,15 +184,19 @@ func (l *NodeLinker) Deliver(ctx context.Context, args *DeliverArgs) error {
 	}
 
 	if args.NID != "" {
-		client, err := l.doBuildClient(args.NID)
+		ep, err := l.doFindEndpoint(args.NID)
 		if err != nil {
 			return err
 		}
 
-		return client.Deliver(ctx, args.CID, args.UID, message)
+		_, _, err = l.doCall(ctx, ep, func(ctx context.Context, client *node.Client) (bool, interface{}, error) {
+			return false, nil, client.Deliver(ctx, args.CID, args.UID, args.Codec, message)
+		})
+
+		return err
 	} else {
 		_, err := l.doRPC(ctx, args.Route, args.UID, func(ctx context.Context, client *node.Client) (bool, interface{}, error) {
-			return false, nil, client.Deliver(ctx, args.CID, args.UID, message)
+			return false, nil, client.Deliver(ctx, args.CID, args.UID, args.Codec, message)
 		})
 		if err != nil && !errors.Is(err, errors.ErrNotFoundUserLocation) {
 			return err
@@ -175,12 +217,11 @@ func (l *NodeLinker) Trigger(ctx context.Context, args *TriggerArgs) error {
 
 	event.IterateEndpoint(func(_ string, ep *endpoint.Endpoint) bool {
 		eg.Go(func() error {
-			client, err := l.builder.Build(ep.Address())
-			if err != nil {
-				return err
-			}
+			_, _, err := l.doCall(ctx, ep, func(ctx context.Context, client *node.Client) (bool, interface{}, error) {
+				return false, nil, client.Trigger(ctx, args.Event, args.CID, args.UID)
+			})
 
-			return client.Trigger(ctx, args.Event, args.CID, args.UID)
+			return err
 		})
 
 		return true
@@ -242,12 +283,17 @@ func (l *NodeLinker) doRPC(ctx context.Context, routeID int32, uid int64, fn fun
 		nid       string
 		prev      string
 		route     *dispatcher.Route
-		client    *node.Client
 		ep        *endpoint.Endpoint
+		done      func()
 		continued bool
 		reply     interface{}
+		key       string
 	)
 
+	if uid > 0 {
+		key = strconv.FormatInt(uid, 10)
+	}
+
 	if route, err = l.dispatcher.FindRoute(routeID); err != nil {
 		return nil, err
 	}
@@ -256,9 +302,15 @@ func (l *NodeLinker) doRPC(ctx context.Context, routeID int32, uid int64, fn fun
 		return nil, errors.ErrIllegalRequest
 	}
 
+	if !route.Stateful() {
+		if delay, ok := l.hedgings[routeID]; ok {
+			return l.doHedgingRPC(ctx, route, delay, fn)
+		}
+	}
+
 	for i := 0; i < 2; i++ {
 		if route.Stateful() {
-			if nid, err = l.Locate(ctx, uid, route.Group()); err != nil {
+			if nid, err = l.locateOrAssign(ctx, route, uid, key); err != nil {
 				return nil, err
 			}
 			if nid == prev {
@@ -267,17 +319,13 @@ func (l *NodeLinker) doRPC(ctx context.Context, routeID int32, uid int64, fn fun
 			prev = nid
 		}
 
-		ep, err = route.FindEndpoint(nid)
+		ep, done, err = route.SelectEndpoint(key, nid)
 		if err != nil {
 			return nil, err
 		}
 
-		client, err = l.builder.Build(ep.Address())
-		if err != nil {
-			return nil, err
-		}
-
-		continued, reply, err = fn(ctx, client)
+		continued, reply, err = l.doCall(ctx, ep, fn)
+		done()
 		if continued {
 			if route.Stateful() {
 				l.doDeleteSource(uid, route.Group(), prev)
@@ -291,13 +339,119 @@ func (l *NodeLinker) doRPC(ctx context.Context, routeID int32, uid int64, fn fun
 	return reply, err
 }
 
-// 构建节点客户端
-func (l *NodeLinker) doBuildClient(nid string) (*node.Client, error) {
-	if nid == "" {
-		return nil, errors.ErrInvalidNID
+// 定位用户所在节点
+// 用户未绑定且设置了负载均衡器时，由负载均衡器选择节点并进行绑定，使金丝雀等分流策略作用于绑定目标
+func (l *NodeLinker) locateOrAssign(ctx context.Context, route *dispatcher.Route, uid int64, key string) (string, error) {
+	nid, err := l.Locate(ctx, uid, route.Group())
+	if err == nil || uid <= 0 || !route.Balanced() || !errors.Is(err, errors.ErrNotFoundUserLocation) {
+		return nid, err
+	}
+
+	if nid, err = route.SelectInstance(key); err != nil {
+		return "", err
+	}
+
+	if err = l.Bind(ctx, uid, route.Group(), nid); err != nil {
+		return "", err
+	}
+
+	return nid, nil
+}
+
+// 执行节点对冲RPC调用
+// 首个请求超过延迟阈值仍未返回时，向其他节点发送对冲请求，以先返回的结果为准，并取消另一个请求
+func (l *NodeLinker) doHedgingRPC(ctx context.Context, route *dispatcher.Route, delay time.Duration, fn func(ctx context.Context, client *node.Client) (bool, interface{}, error)) (interface{}, error) {
+	type result struct {
+		reply interface{}
+		err   error
+	}
+
+	ep, done, err := route.SelectEndpoint("")
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan *result, 2)
+
+	call := func(ep *endpoint.Endpoint, done func()) {
+		_, reply, err := l.doCall(ctx, ep, fn)
+		done()
+
+		results <- &result{reply: reply, err: err}
+	}
+
+	go call(ep, done)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	pending := 1
+
+	for {
+		select {
+		case res := <-results:
+			pending--
+
+			if res.err == nil || pending == 0 {
+				return res.reply, res.err
+			}
+		case <-timer.C:
+			if hedged, done, ok := l.doFindHedgingEndpoint(route, ep); ok {
+				pending++
+				go call(hedged, done)
+			}
+		}
+	}
+}
+
+// 调用节点，开启熔断器时目标节点熔断后将快速失败
+func (l *NodeLinker) doCall(ctx context.Context, ep *endpoint.Endpoint, fn func(ctx context.Context, client *node.Client) (bool, interface{}, error)) (bool, interface{}, error) {
+	client, err := l.builder.Build(ep.Address())
+	if err != nil {
+		return false, nil, err
+	}
+
+	if l.opts.Breaker == nil {
+		return fn(ctx, client)
+	}
+
+	b := l.opts.Breaker.Get(ep.Address())
+
+	if err = b.Allow(); err != nil {
+		return false, nil, err
+	}
+
+	continued, reply, err := fn(ctx, client)
+
+	b.Done(err)
+
+	return continued, reply, err
+}
+
+// 查找对冲请求的节点端点，需与首个请求的节点不同
+func (l *NodeLinker) doFindHedgingEndpoint(route *dispatcher.Route, prev *endpoint.Endpoint) (*endpoint.Endpoint, func(), bool) {
+	for i := 0; i < 3; i++ {
+		ep, done, err := route.SelectEndpoint("")
+		if err != nil {
+			return nil, nil, false
+		}
+
+		if ep.Address() != prev.Address() {
+			return ep, done, true
+		}
+
+		done()
 	}
 
-	ep, err := l.dispatcher.FindEndpoint(nid)
+	return nil, nil, false
+}
+
+// 构建节点客户端
+func (l *NodeLinker) doBuildClient(nid string) (*node.Client, error) {
+	ep, err := l.doFindEndpoint(nid)
 	if err != nil {
 		return nil, err
 	}
@@ -305,6 +459,15 @@ func (l *NodeLinker) doBuildClient(nid string) (*node.Client, error) {
 	return l.builder.Build(ep.Address())
 }
 
+// 查找节点端点
+func (l *NodeLinker) doFindEndpoint(nid string) (*endpoint.Endpoint, error) {
+	if nid == "" {
+		return nil, errors.ErrInvalidNID
+	}
+
+	return l.dispatcher.FindEndpoint(nid)
+}
+
 // 打包消息
 func (l *NodeLinker) doPackMessage(message *Message, encrypt bool) ([]byte, error) {
 	buffer, err := l.toBuffer(message.Data, encrypt)
@@ -430,7 +593,11 @@ func (l *NodeLinker) WatchUserLocate() {
 				case locate.UnbindNode:
 					l.doDeleteSource(event.UID, event.InsName, event.InsID)
 				default:
-					// ignore
+					continue
+				}
+
+				if l.opts.NodeBindHandler != nil {
+					l.opts.NodeBindHandler(event.UID, event.InsName, event.InsID, event.Type == locate.BindNode)
 				}
 			}
 		}
@@ -462,6 +629,139 @@ func (l *NodeLinker) WatchClusterInstance() {
 			}
 
 			l.dispatcher.ReplaceServices(services...)
+
+			l.doRetainBreakers(services)
+
+			l.doHandleLostNodes(services)
 		}
 	}()
 }
+
+// 处理已下线的有状态节点，节点在宽限期内重新上线时视为抖动不做处理
+func (l *NodeLinker) doHandleLostNodes(services []*registry.ServiceInstance) {
+	statefuls := make(map[string]*registry.ServiceInstance, len(l.statefuls))
+	for _, service := range services {
+		for _, route := range service.Routes {
+			if route.Stateful {
+				statefuls[service.ID] = service
+				break
+			}
+		}
+	}
+
+	l.lmu.Lock()
+	for nid := range statefuls {
+		if cancel, ok := l.losts[nid]; ok {
+			cancel()
+			delete(l.losts, nid)
+		}
+	}
+
+	for nid, ins := range l.statefuls {
+		if _, ok := statefuls[nid]; ok {
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(l.ctx)
+		l.losts[nid] = cancel
+
+		go l.doConfirmLostNode(ctx, ins)
+	}
+	l.lmu.Unlock()
+
+	l.statefuls = statefuls
+}
+
+// 宽限期结束后向注册中心确认节点已下线，再清理绑定到该节点的用户来源并通知处理器
+func (l *NodeLinker) doConfirmLostNode(ctx context.Context, ins *registry.ServiceInstance) {
+	timer := time.NewTimer(l.opts.NodeLostGrace)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-timer.C:
+	}
+
+	alive := l.doCheckNodeAlive(ctx, ins)
+
+	l.lmu.Lock()
+	if ctx.Err() != nil {
+		l.lmu.Unlock()
+		return
+	}
+	l.losts[ins.ID]()
+	delete(l.losts, ins.ID)
+	l.lmu.Unlock()
+
+	if alive {
+		return
+	}
+
+	uids := l.doClearSources(ins.Name, ins.ID)
+
+	if l.opts.NodeLostHandler != nil {
+		l.opts.NodeLostHandler(ins, uids)
+	}
+}
+
+// 检测节点是否仍注册在注册中心，查询失败时以监听结果为准
+func (l *NodeLinker) doCheckNodeAlive(ctx context.Context, ins *registry.ServiceInstance) bool {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	services, err := l.opts.Registry.Services(ctx, cluster.Node.String())
+	cancel()
+	if err != nil {
+		log.Warnf("confirm lost node failed, nid: %s err: %v", ins.ID, err)
+		return false
+	}
+
+	for _, service := range services {
+		if service.ID == ins.ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 清理绑定到指定节点的用户来源，返回受影响的用户
+func (l *NodeLinker) doClearSources(name, nid string) []int64 {
+	l.rw.Lock()
+	defer l.rw.Unlock()
+
+	uids := make([]int64, 0)
+	for uid, sources := range l.sources {
+		if sources[name] != nid {
+			continue
+		}
+
+		uids = append(uids, uid)
+
+		if len(sources) == 1 {
+			delete(l.sources, uid)
+		} else {
+			delete(sources, name)
+		}
+	}
+
+	return uids
+}
+
+// 重置已下线节点的熔断器
+func (l *NodeLinker) doRetainBreakers(services []*registry.ServiceInstance) {
+	if l.opts.Breaker == nil {
+		return
+	}
+
+	targets := make(map[string]struct{}, len(services))
+	for _, service := range services {
+		ep, err := endpoint.ParseEndpoint(service.Endpoint)
+		if err != nil {
+			continue
+		}
+
+		targets[ep.Address()] = struct{}{}
+	}
+
+	l.opts.Breaker.Retain(targets)
+}
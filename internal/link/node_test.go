@@ -0,0 +1,162 @@
+package link
+
+import (
+	"context"
+	"fmt"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/core/endpoint"
+	"github.com/dobyte/due/v2/internal/dispatcher"
+	"github.com/dobyte/due/v2/internal/transporter/node"
+	"github.com/dobyte/due/v2/registry"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const hedgingDelay = 50 * time.Millisecond
+
+type nopProvider struct{}
+
+func (p *nopProvider) Trigger(ctx context.Context, gid string, cid, uid int64, event cluster.Event) error {
+	return nil
+}
+
+func (p *nopProvider) Deliver(ctx context.Context, gid, nid string, cid, uid int64, codec string, message []byte) error {
+	return nil
+}
+
+func (p *nopProvider) GetState() (cluster.State, error) {
+	return cluster.Work, nil
+}
+
+func (p *nopProvider) SetState(state cluster.State) error {
+	return nil
+}
+
+func (p *nopProvider) Drain(timeout time.Duration, progress func(remaining int)) (int, error) {
+	return 0, nil
+}
+
+func newHedgingLinker(t *testing.T, nodes int) *NodeLinker {
+	l := NewNodeLinker(context.Background(), &Options{
+		InsID:           "gate-1",
+		InsKind:         cluster.Gate,
+		BalanceStrategy: dispatcher.RoundRobin,
+		HedgingRoutes: []cluster.HedgingRoute{
+			{Route: 1, Enable: true, Idempotent: true, Delay: hedgingDelay},
+			{Route: 2, Enable: true, Idempotent: false, Delay: hedgingDelay},
+		},
+	})
+
+	services := make([]*registry.ServiceInstance, 0, nodes)
+	for i := 0; i < nodes; i++ {
+		server, err := node.NewServer(&node.ServerOptions{Addr: "127.0.0.1:0"}, &nopProvider{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		go server.Start()
+		t.Cleanup(func() { _ = server.Stop() })
+
+		services = append(services, &registry.ServiceInstance{
+			ID:       fmt.Sprintf("node-%d", i+1),
+			Name:     "test",
+			Kind:     cluster.Node.String(),
+			State:    cluster.Work.String(),
+			Routes:   []registry.Route{{ID: 1}, {ID: 2}},
+			Endpoint: endpoint.NewEndpoint("tcp", server.ListenAddr(), false).String(),
+		})
+	}
+
+	l.dispatcher.ReplaceServices(services...)
+
+	return l
+}
+
+func TestNodeLinker_Hedging(t *testing.T) {
+	l := newHedgingLinker(t, 2)
+
+	var (
+		calls    atomic.Int32
+		mu       sync.Mutex
+		clients  = make(map[*node.Client]struct{})
+		canceled = make(chan struct{})
+	)
+
+	start := time.Now()
+
+	reply, err := l.doRPC(context.Background(), 1, 0, func(ctx context.Context, client *node.Client) (bool, interface{}, error) {
+		mu.Lock()
+		clients[client] = struct{}{}
+		mu.Unlock()
+
+		if calls.Add(1) > 1 {
+			return false, "hedged", nil
+		}
+
+		// 首个请求阻塞，直到对冲请求返回后被取消
+		<-ctx.Done()
+		close(canceled)
+
+		return false, nil, ctx.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reply != "hedged" {
+		t.Fatalf("unexpected reply: %v", reply)
+	}
+
+	if elapsed := time.Since(start); elapsed < hedgingDelay {
+		t.Fatalf("hedged before delay: %v", elapsed)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("first call was not canceled")
+	}
+
+	if calls.Load() != 2 || len(clients) != 2 {
+		t.Fatalf("unexpected calls: %d clients: %d", calls.Load(), len(clients))
+	}
+}
+
+func TestNodeLinker_HedgingSkipped(t *testing.T) {
+	slow := func(calls *atomic.Int32) func(ctx context.Context, client *node.Client) (bool, interface{}, error) {
+		return func(ctx context.Context, client *node.Client) (bool, interface{}, error) {
+			calls.Add(1)
+			time.Sleep(2 * hedgingDelay)
+			return false, "ok", nil
+		}
+	}
+
+	// 非幂等路由不进行对冲
+	t.Run("not idempotent", func(t *testing.T) {
+		var calls atomic.Int32
+
+		if _, err := newHedgingLinker(t, 2).doRPC(context.Background(), 2, 0, slow(&calls)); err != nil {
+			t.Fatal(err)
+		}
+
+		if calls.Load() != 1 {
+			t.Fatalf("unexpected calls: %d", calls.Load())
+		}
+	})
+
+	// 无其他节点时等待首个请求返回
+	t.Run("single node", func(t *testing.T) {
+		var calls atomic.Int32
+
+		reply, err := newHedgingLinker(t, 1).doRPC(context.Background(), 1, 0, slow(&calls))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if reply != "ok" || calls.Load() != 1 {
+			t.Fatalf("unexpected reply: %v calls: %d", reply, calls.Load())
+		}
+	})
+}
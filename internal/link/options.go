@@ -2,19 +2,40 @@ package link
 
 import (
 	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/core/breaker"
+	"github.com/dobyte/due/v2/core/id"
 	"github.com/dobyte/due/v2/crypto"
 	"github.com/dobyte/due/v2/encoding"
 	"github.com/dobyte/due/v2/internal/dispatcher"
 	"github.com/dobyte/due/v2/locate"
 	"github.com/dobyte/due/v2/registry"
+	"time"
 )
 
 type Options struct {
-	InsID           string                     // 实例ID
-	InsKind         cluster.Kind               // 实例类型
-	Codec           encoding.Codec             // 编解码器
-	Locator         locate.Locator             // 定位器
-	Registry        registry.Registry          // 注册器
-	Encryptor       crypto.Encryptor           // 加密器
-	BalanceStrategy dispatcher.BalanceStrategy // 负载均衡策略
+	InsID              string                     // 实例ID
+	InsKind            cluster.Kind               // 实例类型
+	Codec              encoding.Codec             // 编解码器
+	Locator            locate.Locator             // 定位器
+	Registry           registry.Registry          // 注册器
+	Encryptor          crypto.Encryptor           // 加密器
+	BalanceStrategy    dispatcher.BalanceStrategy // 负载均衡策略
+	Balancer           registry.Balancer          // 负载均衡器，设置后优先于负载均衡策略
+	HedgingRoutes      []cluster.HedgingRoute     // 请求对冲路由
+	Breaker            *breaker.Group             // 熔断器组
+	NodeLostHandler    NodeLostHandler            // 有状态节点丢失处理器
+	NodeLostGrace      time.Duration              // 有状态节点丢失宽限期，宽限期内重新上线的节点不视为丢失
+	NodeBindHandler    NodeBindHandler            // 节点绑定变更处理器
+	CloseReasonHandler CloseReasonHandler         // 关闭通知处理器
+	AuditWindow        time.Duration              // 序列号审计窗口，大于0时开启传输层序列号审计
+	SeqGenerator       id.Generator               // 传输层序列号ID生成器，为空时使用单调递增的序列号
 }
+
+// NodeLostHandler 有状态节点丢失处理器，uids为本地来源缓存中绑定到该节点的用户，不包含未经过本实例访问过该节点的用户
+type NodeLostHandler func(ins *registry.ServiceInstance, uids []int64)
+
+// NodeBindHandler 节点绑定变更处理器，监听到用户绑定或解绑节点时调用，bound为false时表示解绑
+type NodeBindHandler func(uid int64, name, nid string, bound bool)
+
+// CloseReasonHandler 关闭通知处理器，已连接的实例关闭前下发关闭原因时调用，addr为该实例的传输层地址
+type CloseReasonHandler func(addr string, reason cluster.CloseReason)
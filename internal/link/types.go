@@ -9,6 +9,7 @@ type (
 	GetIPArgs      = cluster.GetIPArgs
 	IsOnlineArgs   = cluster.IsOnlineArgs
 	DisconnectArgs = cluster.DisconnectArgs
+	KickArgs       = cluster.KickArgs
 	PushArgs       = cluster.PushArgs
 	MulticastArgs  = cluster.MulticastArgs
 	BroadcastArgs  = cluster.BroadcastArgs
@@ -19,6 +20,7 @@ type DeliverArgs struct {
 	CID     int64       // 连接ID
 	UID     int64       // 用户ID
 	Route   int32       // 路由
+	Codec   string      // 客户端连接协商的编解码器，为空时使用节点默认的编解码器
 	Message interface{} // 消息
 }
 
@@ -8,11 +8,9 @@ import (
 	"github.com/dobyte/due/v2/internal/transporter/internal/codes"
 	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
 	"github.com/dobyte/due/v2/session"
-	"sync/atomic"
 )
 
 type Client struct {
-	seq uint64
 	cli *client.Client
 }
 
@@ -22,6 +20,24 @@ func NewClient(cli *client.Client) *Client {
 	}
 }
 
+// IsOnlineResult 批量检测在线状态的结果
+type IsOnlineResult struct {
+	Miss     bool  // 是否未找到会话
+	IsOnline bool  // 是否在线
+	Err      error // 检测错误
+}
+
+// NextSeq 生成序列号，用于编码批量调用的请求
+func (c *Client) NextSeq() uint64 {
+	return c.doGenSequence()
+}
+
+// BatchCall 批量调用，在同一连接上流水线发送全部请求，随后按序列号收集响应
+// 请求需使用NextSeq生成的序列号编码，响应与请求一一对应，单个请求的错误记录在对应响应中
+func (c *Client) BatchCall(ctx context.Context, reqs []client.Request) ([]client.Response, error) {
+	return c.cli.BatchCall(ctx, reqs)
+}
+
 // Bind 绑定用户与连接
 func (c *Client) Bind(ctx context.Context, cid, uid int64) (bool, error) {
 	seq := c.doGenSequence()
@@ -38,6 +54,10 @@ func (c *Client) Bind(ctx context.Context, cid, uid int64) (bool, error) {
 		return false, err
 	}
 
+	if code == codes.DuplicateBind {
+		return false, codes.CodeToError(code)
+	}
+
 	return code == codes.NotFoundSession, nil
 }
 
@@ -114,20 +134,83 @@ func (c *Client) IsOnline(ctx context.Context, kind session.Kind, target int64)
 	return code == codes.NotFoundSession, isOnline, nil
 }
 
-// Disconnect 断开连接
+// BatchIsOnline 批量检测是否在线，以一次批量调用代替逐个检测，结果与targets一一对应
+func (c *Client) BatchIsOnline(ctx context.Context, kind session.Kind, targets []int64) ([]IsOnlineResult, error) {
+	reqs := make([]client.Request, len(targets))
+
+	for i, target := range targets {
+		seq := c.doGenSequence()
+		reqs[i] = client.Request{Seq: seq, Buf: protocol.EncodeIsOnlineReq(seq, kind, target)}
+	}
+
+	resps, err := c.cli.BatchCall(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]IsOnlineResult, len(resps))
+
+	for i, resp := range resps {
+		if resp.Err != nil {
+			results[i].Err = resp.Err
+			continue
+		}
+
+		code, isOnline, err := protocol.DecodeIsOnlineRes(resp.Data)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		results[i].Miss = code == codes.NotFoundSession
+		results[i].IsOnline = isOnline
+	}
+
+	return results, nil
+}
+
+// Disconnect 断开连接，强制断开时以高优先级发送
 func (c *Client) Disconnect(ctx context.Context, kind session.Kind, target int64, force bool) error {
 	if force {
-		return c.cli.Send(ctx, protocol.EncodeDisconnectReq(0, kind, target, force))
+		return c.cli.Send(ctx, protocol.MarkPriority(protocol.EncodeDisconnectReq(0, kind, target, force)))
 	} else {
 		return c.cli.Send(ctx, protocol.EncodeDisconnectReq(0, kind, target, force), target)
 	}
 }
 
+// Kick 踢用户下线，messages为按编解码器区分的踢下线通知消息，返回值为true时表示未找到用户会话
+func (c *Client) Kick(ctx context.Context, uid int64, messages map[string][]byte) (bool, error) {
+	seq := c.doGenSequence()
+
+	buf := protocol.MarkPriority(protocol.EncodeKickReq(seq, uid, messages))
+
+	res, err := c.cli.Call(ctx, seq, buf, uid)
+	if err != nil {
+		return false, err
+	}
+
+	code, err := protocol.DecodeKickRes(res)
+	if err != nil {
+		return false, err
+	}
+
+	if code == codes.NotFoundSession {
+		return true, nil
+	}
+
+	return false, codes.CodeToError(code)
+}
+
 // Push 异步推送消息
 func (c *Client) Push(ctx context.Context, kind session.Kind, target int64, message buffer.Buffer) error {
 	return c.cli.Send(ctx, protocol.EncodePushReq(0, kind, target, message), target)
 }
 
+// PushWithHeader 异步推送携带推送头信息的消息
+func (c *Client) PushWithHeader(ctx context.Context, kind session.Kind, target int64, header *cluster.PushHeader, message buffer.Buffer) error {
+	return c.cli.Send(ctx, protocol.EncodePushReqWithHeader(0, kind, target, header, message), target)
+}
+
 // Multicast 推送组播消息
 func (c *Client) Multicast(ctx context.Context, kind session.Kind, targets []int64, message buffer.Buffer) error {
 	return c.cli.Send(ctx, protocol.EncodeMulticastReq(0, kind, targets, message))
@@ -138,6 +221,11 @@ func (c *Client) Broadcast(ctx context.Context, kind session.Kind, message buffe
 	return c.cli.Send(ctx, protocol.EncodeBroadcastReq(0, kind, message))
 }
 
+// BroadcastCodecs 推送按编解码器区分的广播消息，messages以编解码器名称为键，键为空的消息推送给未协商编解码器的连接
+func (c *Client) BroadcastCodecs(ctx context.Context, kind session.Kind, messages map[string][]byte) error {
+	return c.cli.Send(ctx, protocol.EncodeBroadcastCodecsReq(0, kind, messages))
+}
+
 // GetState 获取状态
 func (c *Client) GetState(ctx context.Context) (cluster.State, error) {
 	seq := c.doGenSequence()
@@ -176,11 +264,7 @@ func (c *Client) SetState(ctx context.Context, state cluster.State) error {
 	return codes.CodeToError(code)
 }
 
-// 生成序列号，规避生成序列号为0的编号
-func (c *Client) doGenSequence() (seq uint64) {
-	for {
-		if seq = atomic.AddUint64(&c.seq, 1); seq != 0 {
-			return
-		}
-	}
+// 生成序列号
+func (c *Client) doGenSequence() uint64 {
+	return c.cli.NextSeq()
 }
@@ -0,0 +1,60 @@
+package gate_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/internal/transporter/gate"
+	"github.com/dobyte/due/v2/session"
+	"testing"
+)
+
+func TestClient_BatchIsOnline(t *testing.T) {
+	server, err := gate.NewServer(&gate.ServerOptions{Addr: "127.0.0.1:0"}, &onlineProvider{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go server.Start()
+
+	client, err := gate.NewBuilder(&gate.Options{InsID: "node-1", InsKind: cluster.Node}).Build(server.ListenAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer server.Stop()
+
+	targets := make([]int64, 100)
+	for i := range targets {
+		targets[i] = int64(i + 1)
+	}
+
+	results, err := client.BatchIsOnline(context.Background(), session.User, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != len(targets) {
+		t.Fatalf("results = %d, want %d", len(results), len(targets))
+	}
+
+	// 结果与请求一一对应
+	for i, result := range results {
+		if result.Err != nil {
+			t.Fatalf("target %d: %v", targets[i], result.Err)
+		}
+
+		if result.IsOnline != (targets[i]%2 == 0) {
+			t.Fatalf("target %d: isOnline = %v", targets[i], result.IsOnline)
+		}
+	}
+}
+
+// 偶数用户在线
+type onlineProvider struct {
+	provider
+}
+
+// IsOnline 检测是否在线
+func (p *onlineProvider) IsOnline(ctx context.Context, kind session.Kind, target int64) (bool, error) {
+	return target%2 == 0, nil
+}
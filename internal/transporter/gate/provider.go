@@ -25,8 +25,22 @@ type Provider interface {
 	Multicast(ctx context.Context, kind session.Kind, targets []int64, message []byte) (total int64, err error)
 	// Broadcast 推送广播消息
 	Broadcast(ctx context.Context, kind session.Kind, message []byte) (total int64, err error)
+	// Kick 踢用户下线，先向用户发送与连接协商的编解码器对应的踢下线通知消息再关闭连接，未找到用户会话时返回errors.ErrNotFoundSession
+	Kick(ctx context.Context, uid int64, messages map[string][]byte) error
 	// GetState 获取状态
 	GetState() (cluster.State, error)
 	// SetState 设置状态
 	SetState(state cluster.State) error
 }
+
+// HeaderPusher 支持推送头信息的提供者，未实现时将忽略推送头信息
+type HeaderPusher interface {
+	// PushWithHeader 发送携带推送头信息的消息
+	PushWithHeader(ctx context.Context, kind session.Kind, target int64, header *cluster.PushHeader, message []byte) error
+}
+
+// CodecBroadcaster 支持按连接协商的编解码器推送广播消息的提供者，未实现时将推送默认消息
+type CodecBroadcaster interface {
+	// BroadcastCodecs 推送广播消息，messages以编解码器名称为键，未协商编解码器或无对应消息的连接推送键为空的消息
+	BroadcastCodecs(ctx context.Context, kind session.Kind, messages map[string][]byte) (total int64, err error)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
 	"github.com/dobyte/due/v2/internal/transporter/internal/route"
 	"github.com/dobyte/due/v2/internal/transporter/internal/server"
+	"io"
 )
 
 type Server struct {
@@ -13,8 +14,30 @@ type Server struct {
 	provider Provider
 }
 
-func NewServer(addr string, provider Provider) (*Server, error) {
-	serv, err := server.NewServer(&server.Options{Addr: addr})
+type ServerOptions struct {
+	Addr         string    // 监听地址
+	DrainOnClose bool      // 连接关闭时是否在关闭写入前处理已读取但尚未分发的消息，为false时直接丢弃
+	RecordWriter io.Writer // 消息录制输出，不为nil时录制连接上读取到的原始消息，用于调试时回放
+}
+
+func NewServer(opts *ServerOptions, provider Provider) (*Server, error) {
+	var recorder *protocol.Recorder
+
+	if opts.RecordWriter != nil {
+		r, err := protocol.NewRecorder(opts.RecordWriter)
+		if err != nil {
+			return nil, err
+		}
+
+		recorder = r
+	}
+
+	serv, err := server.NewServer(&server.Options{
+		Addr:           opts.Addr,
+		Recorder:       recorder,
+		ReadBufferSize: protocol.DefaultReadBufferSize,
+		DrainOnClose:   opts.DrainOnClose,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -35,6 +58,8 @@ func (s *Server) init() {
 	s.RegisterHandler(route.Push, s.push)
 	s.RegisterHandler(route.Multicast, s.multicast)
 	s.RegisterHandler(route.Broadcast, s.broadcast)
+	s.RegisterHandler(route.BroadcastCodecs, s.broadcastCodecs)
+	s.RegisterHandler(route.Kick, s.kick)
 }
 
 // 绑定用户
@@ -123,18 +148,38 @@ func (s *Server) disconnect(conn *server.Conn, data []byte) error {
 
 // 推送单个消息
 func (s *Server) push(conn *server.Conn, data []byte) error {
-	seq, kind, target, message, err := protocol.DecodePushReq(data)
+	seq, kind, target, header, message, err := protocol.DecodePushReq(data)
 	if err != nil {
 		return err
 	}
 
-	if err = s.provider.Push(context.Background(), kind, target, message); seq == 0 {
+	if pusher, ok := s.provider.(HeaderPusher); ok && header != nil {
+		err = pusher.PushWithHeader(context.Background(), kind, target, header, message)
+	} else {
+		err = s.provider.Push(context.Background(), kind, target, message)
+	}
+
+	if seq == 0 {
 		return err
 	} else {
 		return conn.Send(protocol.EncodePushRes(seq, codes.ErrorToCode(err)))
 	}
 }
 
+// 踢下线
+func (s *Server) kick(conn *server.Conn, data []byte) error {
+	seq, uid, messages, err := protocol.DecodeKickReq(data)
+	if err != nil {
+		return err
+	}
+
+	if err = s.provider.Kick(context.Background(), uid, messages); seq == 0 {
+		return err
+	} else {
+		return conn.Send(protocol.EncodeKickRes(seq, codes.ErrorToCode(err)))
+	}
+}
+
 // 推送组播消息
 func (s *Server) multicast(conn *server.Conn, data []byte) error {
 	seq, kind, targets, message, err := protocol.DecodeMulticastReq(data)
@@ -163,6 +208,27 @@ func (s *Server) broadcast(conn *server.Conn, data []byte) error {
 	}
 }
 
+// 推送按编解码器区分的广播消息，提供者未实现CodecBroadcaster时推送默认消息
+func (s *Server) broadcastCodecs(conn *server.Conn, data []byte) error {
+	seq, kind, messages, err := protocol.DecodeBroadcastCodecsReq(data)
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	if broadcaster, ok := s.provider.(CodecBroadcaster); ok {
+		total, err = broadcaster.BroadcastCodecs(context.Background(), kind, messages)
+	} else {
+		total, err = s.provider.Broadcast(context.Background(), kind, messages[""])
+	}
+
+	if seq == 0 {
+		return err
+	} else {
+		return conn.Send(protocol.EncodeBroadcastRes(seq, codes.ErrorToCode(err), uint64(total)))
+	}
+}
+
 // 获取状态
 func (s *Server) getState(conn *server.Conn, data []byte) error {
 	seq, err := protocol.DecodeGetStateReq(data)
@@ -11,7 +11,7 @@ import (
 )
 
 func TestServer(t *testing.T) {
-	server, err := gate.NewServer(":49899", &provider{})
+	server, err := gate.NewServer(&gate.ServerOptions{Addr: ":49899"}, &provider{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -65,6 +65,11 @@ func (p *provider) Broadcast(ctx context.Context, kind session.Kind, message []b
 	return
 }
 
+// Kick 踢用户下线
+func (p *provider) Kick(ctx context.Context, uid int64, messages map[string][]byte) error {
+	return nil
+}
+
 // Stat 统计会话总数
 func (p *provider) Stat(ctx context.Context, kind session.Kind) (total int64, err error) {
 	return
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 序列号审计器，跟踪已发送请求的序列号，检测重复响应、未知响应及丢失响应
+type auditor struct {
+	addr        string               // 连接地址
+	window      time.Duration        // 审计窗口
+	mu          sync.Mutex           // 锁
+	outstanding map[uint64]time.Time // 等待响应的序列号
+	completed   map[uint64]time.Time // 已完成的序列号，用于检测重复响应
+	duplicates  atomic.Int64         // 重复响应数
+	unknowns    atomic.Int64         // 未知序列号响应数
+	missings    atomic.Int64         // 丢失响应数
+}
+
+func newAuditor(addr string, window time.Duration) *auditor {
+	return &auditor{
+		addr:        addr,
+		window:      window,
+		outstanding: make(map[uint64]time.Time),
+		completed:   make(map[uint64]time.Time),
+	}
+}
+
+// 记录发送的请求
+func (a *auditor) sent(seq uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.completed, seq)
+	a.outstanding[seq] = time.Now()
+}
+
+// 记录收到的响应，more为true时表示流式响应的非最终帧
+func (a *auditor) received(seq uint64, more bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+
+	if _, ok := a.outstanding[seq]; ok {
+		if more {
+			a.outstanding[seq] = now
+		} else {
+			delete(a.outstanding, seq)
+			a.completed[seq] = now
+		}
+		return
+	}
+
+	if _, ok := a.completed[seq]; ok {
+		a.duplicates.Add(1)
+		log.Warnf("transport audit: duplicate response, addr = %s seq = %d", a.addr, seq)
+	} else {
+		a.unknowns.Add(1)
+		log.Warnf("transport audit: response for unknown seq, addr = %s seq = %d", a.addr, seq)
+	}
+}
+
+// 清理过期的序列号，超出审计窗口仍未收到响应的序列号记为丢失
+func (a *auditor) sweep(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	deadline := now.Add(-a.window)
+
+	for seq, t := range a.outstanding {
+		if t.Before(deadline) {
+			delete(a.outstanding, seq)
+			a.missings.Add(1)
+			log.Warnf("transport audit: no response within %v, addr = %s seq = %d", a.window, a.addr, seq)
+		}
+	}
+
+	for seq, t := range a.completed {
+		if t.Before(deadline) {
+			delete(a.completed, seq)
+		}
+	}
+}
+
+// 获取审计统计
+func (a *auditor) stat() cluster.AuditStat {
+	a.mu.Lock()
+	outstanding := len(a.outstanding)
+	a.mu.Unlock()
+
+	return cluster.AuditStat{
+		Outstanding: int64(outstanding),
+		Duplicates:  a.duplicates.Load(),
+		Unknowns:    a.unknowns.Load(),
+		Missings:    a.missings.Load(),
+	}
+}
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuditor(t *testing.T) {
+	a := newAuditor("127.0.0.1:0", time.Second)
+
+	a.sent(1)
+	a.sent(2)
+	a.sent(3)
+
+	a.received(1, false)
+	a.received(1, false)
+	a.received(2, true)
+	a.received(2, false)
+	a.received(9, false)
+
+	a.sweep(time.Now().Add(2 * time.Second))
+
+	stat := a.stat()
+
+	if stat.Duplicates != 1 || stat.Unknowns != 1 || stat.Missings != 1 || stat.Outstanding != 0 {
+		t.Fatalf("unexpected audit stat: %+v", stat)
+	}
+
+	a.received(1, false)
+
+	if stat = a.stat(); stat.Unknowns != 2 {
+		t.Fatalf("expected completed seqs to be pruned after window: %+v", stat)
+	}
+}
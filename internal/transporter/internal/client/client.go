@@ -2,8 +2,10 @@ package client
 
 import (
 	"context"
+	"github.com/dobyte/due/v2/cluster"
 	"github.com/dobyte/due/v2/core/buffer"
 	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -22,7 +24,19 @@ type chWrite struct {
 	ctx  context.Context // 上下文
 	seq  uint64          // 序列号
 	buf  buffer.Buffer   // 数据Buffer
-	call chan []byte     // 回调数据
+	call *call           // 回调
+}
+
+// Request 批量调用请求
+type Request struct {
+	Seq uint64        // 序列号
+	Buf buffer.Buffer // 请求数据
+}
+
+// Response 批量调用响应
+type Response struct {
+	Data []byte // 响应数据
+	Err  error  // 调用错误
 }
 
 type Client struct {
@@ -31,11 +45,16 @@ type Client struct {
 	connections []*Conn        // 连接
 	wg          sync.WaitGroup // 等待组
 	closed      atomic.Bool    // 已关闭
+	inflight    atomic.Int64   // 进行中的调用数
+	audits      []*auditor     // 各连接的序列号审计器
 }
 
 func NewClient(opts *Options) *Client {
 	c := &Client{}
 	c.opts = opts
+	if c.opts.SeqGenerator == nil {
+		c.opts.SeqGenerator = NewSeqGenerator()
+	}
 	c.chWrite = make(chan *chWrite, 10240)
 	c.connections = make([]*Conn, 0, ordered+unordered)
 	c.init()
@@ -49,11 +68,17 @@ func (c *Client) Call(ctx context.Context, seq uint64, buf buffer.Buffer, idx ..
 		return nil, errors.ErrClientClosed
 	}
 
-	call := make(chan []byte)
+	c.inflight.Add(1)
+	defer c.inflight.Add(-1)
 
-	conn := c.load(idx...)
+	call := &call{ch: make(chan []byte)}
+
+	conn, err := c.route(buf, idx...)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := conn.send(&chWrite{
+	if err = conn.send(&chWrite{
 		ctx:  ctx,
 		seq:  seq,
 		buf:  buf,
@@ -72,18 +97,131 @@ func (c *Client) Call(ctx context.Context, seq uint64, buf buffer.Buffer, idx ..
 	case <-ctx1.Done():
 		conn.cancel(seq)
 		return nil, ctx1.Err()
-	case data := <-call:
+	case data := <-call.ch:
 		return data, nil
 	}
 }
 
+// BatchCall 批量调用
+// 在同一连接上流水线发送全部请求，无需逐个等待响应，随后按序列号收集响应；响应与请求一一对应，单个请求的错误记录在对应响应中
+func (c *Client) BatchCall(ctx context.Context, reqs []Request, idx ...int64) ([]Response, error) {
+	if c.closed.Load() {
+		return nil, errors.ErrClientClosed
+	}
+
+	n := int64(len(reqs))
+	c.inflight.Add(n)
+	defer c.inflight.Add(-n)
+
+	var (
+		conn  = c.load(idx...)
+		calls = make([]*call, len(reqs))
+		resps = make([]Response, len(reqs))
+	)
+
+	for i, req := range reqs {
+		calls[i] = &call{ch: make(chan []byte, 1)}
+
+		if err := conn.send(&chWrite{
+			ctx:  ctx,
+			seq:  req.Seq,
+			buf:  req.Buf,
+			call: calls[i],
+		}); err != nil {
+			calls[i] = nil
+			resps[i].Err = err
+		}
+	}
+
+	ctx1, cancel1 := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel1()
+
+	for i, call := range calls {
+		if call == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			conn.cancel(reqs[i].Seq)
+			resps[i].Err = ctx.Err()
+		case <-ctx1.Done():
+			conn.cancel(reqs[i].Seq)
+			resps[i].Err = ctx1.Err()
+		case data := <-call.ch:
+			resps[i].Data = data
+		}
+	}
+
+	return resps, nil
+}
+
+// Stream 流式调用，服务端可针对同一序列号响应多帧数据，收到最终帧或上下文取消后关闭通道
+func (c *Client) Stream(ctx context.Context, seq uint64, buf buffer.Buffer, idx ...int64) (<-chan []byte, error) {
+	if c.closed.Load() {
+		return nil, errors.ErrClientClosed
+	}
+
+	conn, err := c.route(buf, idx...)
+	if err != nil {
+		return nil, err
+	}
+
+	call := &call{ch: make(chan []byte, 16), done: make(chan struct{}), stream: true}
+
+	c.inflight.Add(1)
+
+	if err = conn.send(&chWrite{
+		ctx:  ctx,
+		seq:  seq,
+		buf:  buf,
+		call: call,
+	}); err != nil {
+		c.inflight.Add(-1)
+		return nil, err
+	}
+
+	results := make(chan []byte)
+
+	go func() {
+		defer c.inflight.Add(-1)
+		defer close(results)
+
+		for {
+			select {
+			case <-ctx.Done():
+				conn.cancel(seq)
+				close(call.done)
+				return
+			case data, ok := <-call.ch:
+				if !ok {
+					return
+				}
+
+				select {
+				case results <- data:
+				case <-ctx.Done():
+					conn.cancel(seq)
+					close(call.done)
+					return
+				}
+			}
+		}
+	}()
+
+	return results, nil
+}
+
 // Send 发送
 func (c *Client) Send(ctx context.Context, buf buffer.Buffer, idx ...int64) error {
 	if c.closed.Load() {
 		return errors.ErrClientClosed
 	}
 
-	conn := c.load(idx...)
+	conn, err := c.route(buf, idx...)
+	if err != nil {
+		return err
+	}
 
 	return conn.send(&chWrite{
 		ctx: ctx,
@@ -91,6 +229,31 @@ func (c *Client) Send(ctx context.Context, buf buffer.Buffer, idx ...int64) erro
 	})
 }
 
+// NextSeq 生成下一个请求序列号
+func (c *Client) NextSeq() uint64 {
+	return c.opts.SeqGenerator.Next()
+}
+
+// Inflight 获取进行中的调用数（包含未结束的流式调用）
+func (c *Client) Inflight() int64 {
+	return c.inflight.Load()
+}
+
+// AuditStat 获取序列号审计统计，未开启审计时返回空统计
+func (c *Client) AuditStat() cluster.AuditStat {
+	var stat cluster.AuditStat
+
+	for _, audit := range c.audits {
+		s := audit.stat()
+		stat.Outstanding += s.Outstanding
+		stat.Duplicates += s.Duplicates
+		stat.Unknowns += s.Unknowns
+		stat.Missings += s.Missings
+	}
+
+	return stat
+}
+
 // 获取连接
 func (c *Client) load(idx ...int64) *Conn {
 	if len(idx) > 0 {
@@ -100,6 +263,23 @@ func (c *Client) load(idx ...int64) *Conn {
 	}
 }
 
+// 选择发送连接，高优先级消息在指定连接未连通（如重连中）时改用其他已连通的连接，均未连通时立即失败，避免滞留在无法写出的队列中
+func (c *Client) route(buf buffer.Buffer, idx ...int64) (*Conn, error) {
+	conn := c.load(idx...)
+
+	if !protocol.IsPriorityBuffer(buf) || conn.isOpened() {
+		return conn, nil
+	}
+
+	for _, conn = range c.connections {
+		if conn.isOpened() {
+			return conn, nil
+		}
+	}
+
+	return nil, errors.ErrConnectionNotOpened
+}
+
 // 新建连接
 func (c *Client) init() {
 	c.wg.Add(ordered + unordered)
@@ -107,11 +287,20 @@ func (c *Client) init() {
 	go c.wait()
 
 	for i := 0; i < ordered; i++ {
-		c.connections = append(c.connections, newConn(c))
+		c.addConn(newConn(c))
 	}
 
 	for i := 0; i < unordered; i++ {
-		c.connections = append(c.connections, newConn(c, c.chWrite))
+		c.addConn(newConn(c, c.chWrite))
+	}
+}
+
+// 添加连接
+func (c *Client) addConn(conn *Conn) {
+	c.connections = append(c.connections, conn)
+
+	if conn.audit != nil {
+		c.audits = append(c.audits, conn.audit)
 	}
 }
 
@@ -0,0 +1,309 @@
+package client
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/core/buffer"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/internal/transporter/internal/codes"
+	"github.com/dobyte/due/v2/internal/transporter/internal/def"
+	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
+	"github.com/dobyte/due/v2/internal/transporter/internal/route"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_BatchCall(t *testing.T) {
+	const total = 100
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// 收齐全部请求后逆序响应，验证响应按序列号收集
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+
+				seqs := make([]uint64, 0, total)
+
+				for {
+					isHeartbeat, r, seq, _, err := protocol.ReadMessage(conn)
+					if err != nil {
+						return
+					}
+
+					if isHeartbeat {
+						continue
+					}
+
+					if r == route.Handshake {
+						buf := protocol.EncodeHandshakeRes(seq, codes.OK)
+						_, _ = conn.Write(buf.Bytes())
+						buf.Release()
+						continue
+					}
+
+					if seqs = append(seqs, seq); len(seqs) < total {
+						continue
+					}
+
+					for i := len(seqs) - 1; i >= 0; i-- {
+						buf := protocol.EncodeGetStateRes(seqs[i], codes.OK, cluster.State(seqs[i]%3))
+						_, _ = conn.Write(buf.Bytes())
+						buf.Release()
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	cli := NewClient(&Options{Addr: ln.Addr().String(), InsKind: cluster.Node, InsID: "test"})
+
+	reqs := make([]Request, total)
+	for i := range reqs {
+		reqs[i] = Request{Seq: uint64(i + 2), Buf: protocol.EncodeGetStateReq(uint64(i + 2))}
+	}
+
+	resps, err := cli.BatchCall(context.Background(), reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, resp := range resps {
+		if resp.Err != nil {
+			t.Fatalf("request %d failed: %v", i, resp.Err)
+		}
+
+		code, state, err := protocol.DecodeGetStateRes(resp.Data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if code != codes.OK || state != cluster.State(reqs[i].Seq%3) {
+			t.Fatalf("response %d mismatch: code = %d state = %d", i, code, state)
+		}
+	}
+}
+
+func TestClient_CloseReason(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// 握手成功后立即下发关闭通知
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+
+				for {
+					isHeartbeat, r, seq, _, err := protocol.ReadMessage(conn)
+					if err != nil {
+						return
+					}
+
+					if isHeartbeat || r != route.Handshake {
+						continue
+					}
+
+					buf := protocol.EncodeHandshakeRes(seq, codes.OK)
+					_, _ = conn.Write(buf.Bytes())
+					buf.Release()
+
+					buf = protocol.EncodeCloseReq(uint16(cluster.CloseMaintenance))
+					_, _ = conn.Write(buf.Bytes())
+					buf.Release()
+				}
+			}(conn)
+		}
+	}()
+
+	reasons := make(chan cluster.CloseReason, ordered+unordered)
+
+	NewClient(&Options{
+		Addr:               ln.Addr().String(),
+		InsKind:            cluster.Node,
+		InsID:              "test",
+		CloseReasonHandler: func(reason cluster.CloseReason) { reasons <- reason },
+	})
+
+	select {
+	case reason := <-reasons:
+		if reason != cluster.CloseMaintenance {
+			t.Fatalf("unexpected close reason: %s", reason)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("close reason not received")
+	}
+}
+
+func TestClient_HandshakeLimit(t *testing.T) {
+	// 模拟服务端，advertise为0时模拟不通告最大消息字节数的旧版本服务端
+	serve := func(advertise uint32) (string, chan uint8) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = ln.Close() })
+
+		routes := make(chan uint8, 64)
+
+		go func() {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+
+				go func(conn net.Conn) {
+					defer conn.Close()
+
+					for {
+						isHeartbeat, r, seq, _, err := protocol.ReadMessage(conn)
+						if err != nil {
+							return
+						}
+
+						if isHeartbeat || r == route.Handshake {
+							if !isHeartbeat {
+								buf := protocol.EncodeHandshakeResWithLimit(seq, codes.OK, advertise)
+								_, _ = conn.Write(buf.Bytes())
+								buf.Release()
+							}
+							continue
+						}
+
+						routes <- r
+					}
+				}(conn)
+			}
+		}()
+
+		return ln.Addr().String(), routes
+	}
+
+	t.Run("old server", func(t *testing.T) {
+		addr, routes := serve(0)
+		cli := NewClient(&Options{Addr: addr, InsKind: cluster.Node, InsID: "test"})
+
+		if err := cli.Send(context.Background(), protocol.EncodeGetStateReq(2)); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case r := <-routes:
+			if r != route.GetState {
+				t.Fatalf("unexpected route %d sent to old server", r)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("request not received")
+		}
+	})
+
+	t.Run("new server", func(t *testing.T) {
+		addr, routes := serve(8)
+		cli := NewClient(&Options{Addr: addr, InsKind: cluster.Node, InsID: "test"})
+
+		select {
+		case r := <-routes:
+			if r != route.Limit {
+				t.Fatalf("unexpected route %d", r)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("limit not received")
+		}
+
+		if err := cli.Send(context.Background(), protocol.EncodeGetStateReq(2)); !errors.Is(err, errors.ErrMessageTooLarge) {
+			t.Fatalf("expected message too large, got: %v", err)
+		}
+	})
+}
+
+func TestClient_PriorityRoute(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+
+				for {
+					isHeartbeat, r, seq, _, err := protocol.ReadMessage(conn)
+					if err != nil {
+						return
+					}
+
+					if isHeartbeat {
+						continue
+					}
+
+					var buf buffer.Buffer
+
+					switch r {
+					case route.Handshake:
+						buf = protocol.EncodeHandshakeRes(seq, codes.OK)
+					case route.GetState:
+						buf = protocol.EncodeGetStateRes(seq, codes.OK, cluster.Work)
+					default:
+						continue
+					}
+
+					_, _ = conn.Write(buf.Bytes())
+					buf.Release()
+				}
+			}(conn)
+		}
+	}()
+
+	cli := NewClient(&Options{Addr: ln.Addr().String(), InsKind: cluster.Node, InsID: "test"})
+
+	// 模拟有序连接正在重连，高优先级消息改由其他已连通的连接发送
+	atomic.StoreInt32(&cli.connections[0].state, def.ConnRetrying)
+
+	seq := cli.NextSeq()
+
+	res, err := cli.Call(context.Background(), seq, protocol.MarkPriority(protocol.EncodeGetStateReq(seq)), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code, state, err := protocol.DecodeGetStateRes(res); err != nil || code != codes.OK || state != cluster.Work {
+		t.Fatalf("unexpected response, code: %d state: %v err: %v", code, state, err)
+	}
+
+	// 全部连接均未连通时立即失败
+	atomic.StoreInt32(&cli.connections[1].state, def.ConnRetrying)
+
+	seq = cli.NextSeq()
+
+	if err = cli.Send(context.Background(), protocol.MarkPriority(protocol.EncodeGetStateReq(seq))); !errors.Is(err, errors.ErrConnectionNotOpened) {
+		t.Fatalf("expected ErrConnectionNotOpened, got: %v", err)
+	}
+}
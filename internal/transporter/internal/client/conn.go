@@ -1,10 +1,13 @@
 package client
 
 import (
+	"context"
+	"github.com/dobyte/due/v2/cluster"
 	"github.com/dobyte/due/v2/core/buffer"
 	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/internal/transporter/internal/def"
 	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
+	"github.com/dobyte/due/v2/internal/transporter/internal/route"
 	"github.com/dobyte/due/v2/log"
 	"github.com/dobyte/due/v2/utils/xtime"
 	"net"
@@ -18,13 +21,17 @@ const (
 )
 
 type Conn struct {
-	cli               *Client       // 客户端
-	state             int32         // 连接状态
-	chWrite           chan *chWrite // 写入队列
-	pending           *pending      // 等待队列
-	done              chan struct{} // 关闭请求
-	builtin           bool          // 是否内建
-	lastHeartbeatTime int64         // 上次心跳时间
+	cli               *Client            // 客户端
+	state             int32              // 连接状态
+	chWrite           chan *chWrite      // 写入队列
+	chHighWrite       chan *chWrite      // 高优先级写入队列
+	pending           *pending           // 等待队列
+	ctx               context.Context    // 上下文，连接断开时取消
+	stop              context.CancelFunc // 取消上下文
+	builtin           bool               // 是否内建
+	lastHeartbeatTime int64              // 上次心跳时间
+	audit             *auditor           // 序列号审计器，未开启审计时为nil
+	peerLimit         atomic.Uint32      // 服务端通告的最大消息字节数，未握手或服务端未通告时为0
 }
 
 func newConn(cli *Client, ch ...chan *chWrite) *Conn {
@@ -32,6 +39,11 @@ func newConn(cli *Client, ch ...chan *chWrite) *Conn {
 	c.cli = cli
 	c.state = def.ConnClosed
 	c.pending = newPending()
+	c.chHighWrite = make(chan *chWrite, 1024)
+
+	if cli.opts.AuditWindow > 0 {
+		c.audit = newAuditor(cli.opts.Addr, cli.opts.AuditWindow)
+	}
 
 	if len(ch) > 0 {
 		c.chWrite = ch[0]
@@ -51,9 +63,31 @@ func (c *Conn) send(ch *chWrite) error {
 		return errors.ErrConnectionClosed
 	}
 
-	c.chWrite <- ch
+	if err := protocol.CheckMessageSize(ch.buf, c.peerLimit.Load()); err != nil {
+		return err
+	}
+
+	if !protocol.IsPriorityBuffer(ch.buf) {
+		c.chWrite <- ch
+		return nil
+	}
+
+	// 高优先级消息仅写入已连通的连接，重连中的连接无法及时写出，直接失败
+	if !c.isOpened() {
+		return errors.ErrConnectionNotOpened
+	}
+
+	select {
+	case c.chHighWrite <- ch:
+		return nil
+	case <-ch.ctx.Done():
+		return ch.ctx.Err()
+	}
+}
 
-	return nil
+// 检测连接是否已连通
+func (c *Conn) isOpened() bool {
+	return atomic.LoadInt32(&c.state) == def.ConnOpened
 }
 
 // 拨号
@@ -98,7 +132,7 @@ func (c *Conn) dial() {
 func (c *Conn) process(conn net.Conn) {
 	atomic.StoreInt32(&c.state, def.ConnOpened)
 
-	c.done = make(chan struct{})
+	c.ctx, c.stop = context.WithCancel(context.Background())
 
 	c.lastHeartbeatTime = xtime.Now().Unix()
 
@@ -106,10 +140,14 @@ func (c *Conn) process(conn net.Conn) {
 
 	seq := uint64(1)
 
-	call := make(chan []byte)
+	call := &call{ch: make(chan []byte)}
 
 	c.pending.store(seq, call)
 
+	if c.audit != nil {
+		c.audit.sent(seq)
+	}
+
 	buf := protocol.EncodeHandshakeReq(seq, c.cli.opts.InsKind, c.cli.opts.InsID)
 
 	defer buf.Release()
@@ -118,19 +156,38 @@ func (c *Conn) process(conn net.Conn) {
 		return
 	}
 
-	<-call
+	_, maxMessageSize, err := protocol.DecodeHandshakeResWithLimit(<-call.ch)
+	if err != nil {
+		maxMessageSize = 0
+	}
+
+	c.peerLimit.Store(maxMessageSize)
+
+	// 服务端通告了最大消息字节数时才回告本端的限制，旧版本服务端不通告，也就不会收到无法识别的消息
+	if maxMessageSize > 0 {
+		limit := protocol.EncodeLimitReq(protocol.MaxMessageBytes)
+		_, err = conn.Write(limit.Bytes())
+		limit.Release()
+
+		if err != nil {
+			return
+		}
+	}
 
 	go c.write(conn)
 }
 
 // 读取数据
 func (c *Conn) read(conn net.Conn) {
+	reader := protocol.NewBufferedReader(conn, c.cli.opts.ReadBufferSize)
+
 	for {
 		select {
-		case <-c.done:
+		case <-c.ctx.Done():
 			return
 		default:
-			isHeartbeat, _, seq, data, err := protocol.ReadMessage(conn)
+			// 连接断开时取消上下文中止阻塞的读取
+			isHeartbeat, r, seq, data, err := protocol.ReadMessageContext(c.ctx, reader)
 			if err != nil {
 				c.retry(conn)
 				return
@@ -142,24 +199,78 @@ func (c *Conn) read(conn net.Conn) {
 				continue
 			}
 
-			call, ok := c.pending.extract(seq)
+			if r == route.Close {
+				c.closeNotified(data)
+				continue
+			}
+
+			more := protocol.IsMore(data)
+
+			if c.audit != nil {
+				c.audit.received(seq, more)
+			}
+
+			var (
+				call *call
+				ok   bool
+			)
+
+			if more {
+				call, ok = c.pending.load(seq)
+			} else {
+				call, ok = c.pending.extract(seq)
+			}
+
 			if !ok {
 				continue
 			}
 
-			call <- data
+			select {
+			case call.ch <- data:
+			case <-call.done:
+				continue
+			}
+
+			if call.stream && !more {
+				close(call.ch)
+			}
 		}
 	}
 }
 
+// 处理服务端下发的关闭通知
+func (c *Conn) closeNotified(data []byte) {
+	code, err := protocol.DecodeCloseReq(data)
+	if err != nil {
+		log.Warnf("decode close message error: %v", err)
+		return
+	}
+
+	reason := cluster.CloseReason(code)
+
+	if c.cli.opts.CloseReasonHandler != nil {
+		c.cli.opts.CloseReasonHandler(reason)
+	} else {
+		log.Infof("server %s is closing, reason: %s", c.cli.opts.Addr, reason)
+	}
+}
+
 // 写入数据
 func (c *Conn) write(conn net.Conn) {
 	ticker := time.NewTicker(def.HeartbeatInterval)
 	defer ticker.Stop()
 
+	var auditC <-chan time.Time
+
+	if c.audit != nil {
+		auditTicker := time.NewTicker(c.audit.window)
+		defer auditTicker.Stop()
+		auditC = auditTicker.C
+	}
+
 	for {
 		select {
-		case <-c.done:
+		case <-c.ctx.Done():
 			return
 		case <-ticker.C:
 			deadline := xtime.Now().Add(-2 * def.HeartbeatInterval).Unix()
@@ -173,26 +284,65 @@ func (c *Conn) write(conn net.Conn) {
 					return
 				}
 			}
+		case now := <-auditC:
+			c.audit.sweep(now)
+		case ch := <-c.chHighWrite:
+			c.doWrite(conn, ch)
 		case ch, ok := <-c.chWrite:
 			if !ok {
 				return
 			}
 
-			if ch.seq != 0 {
-				c.pending.store(ch.seq, ch.call)
-			}
+			c.flushHigh(conn)
 
-			ch.buf.Range(func(node *buffer.NocopyNode) bool {
-				if _, err := conn.Write(node.Bytes()); err != nil {
-					return false
-				} else {
-					return true
-				}
-			})
+			c.doWrite(conn, ch)
+		}
+	}
+}
 
+// 写入高优先级队列中的全部消息
+func (c *Conn) flushHigh(conn net.Conn) {
+	for {
+		select {
+		case ch := <-c.chHighWrite:
+			c.doWrite(conn, ch)
+		default:
+			return
+		}
+	}
+}
+
+// 释放滞留在高优先级队列中的消息，等待响应的调用将超时返回
+func (c *Conn) releaseHigh() {
+	for {
+		select {
+		case ch := <-c.chHighWrite:
 			ch.buf.Release()
+		default:
+			return
+		}
+	}
+}
+
+// 执行写入操作
+func (c *Conn) doWrite(conn net.Conn, ch *chWrite) {
+	if ch.seq != 0 {
+		c.pending.store(ch.seq, ch.call)
+
+		if c.audit != nil {
+			c.audit.sent(ch.seq)
 		}
 	}
+
+	ch.buf.Range(func(node *buffer.NocopyNode) bool {
+		if _, err := conn.Write(node.Bytes()); err != nil {
+			return false
+		} else {
+			return true
+		}
+	})
+
+	ch.buf.Release()
 }
 
 // 重试拨号
@@ -201,9 +351,9 @@ func (c *Conn) retry(conn net.Conn) {
 		return
 	}
 
-	_ = conn.Close()
+	c.stop()
 
-	close(c.done)
+	_ = conn.Close()
 
 	c.dial()
 }
@@ -214,6 +364,8 @@ func (c *Conn) close() {
 
 	atomic.StoreInt32(&c.state, def.ConnClosed)
 
+	c.releaseHigh()
+
 	if c.builtin {
 		close(c.chWrite)
 	}
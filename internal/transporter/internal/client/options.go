@@ -1,10 +1,17 @@
 package client
 
-import "github.com/dobyte/due/v2/cluster"
+import (
+	"github.com/dobyte/due/v2/cluster"
+	"time"
+)
 
 type Options struct {
-	Addr         string       // 连接地址
-	InsID        string       // 实例ID
-	InsKind      cluster.Kind // 实例类型
-	CloseHandler func()       // 关闭处理器
+	Addr               string                           // 连接地址
+	InsID              string                           // 实例ID
+	InsKind            cluster.Kind                     // 实例类型
+	CloseHandler       func()                           // 关闭处理器
+	CloseReasonHandler func(reason cluster.CloseReason) // 关闭通知处理器，服务端关闭前下发关闭原因时回调，为空时仅打印日志
+	ReadBufferSize     int                              // 读缓冲区大小，小于等于0时不使用缓冲读取
+	AuditWindow        time.Duration                    // 序列号审计窗口，大于0时开启审计，检测重复响应、未知响应及窗口内未响应的请求
+	SeqGenerator       SeqGenerator                     // 序列号生成器，为空时使用单调递增的序列号生成器
 }
@@ -2,6 +2,12 @@ package client
 
 import "sync"
 
+type call struct {
+	ch     chan []byte   // 回调数据
+	done   chan struct{} // 取消通知，仅流式调用有效
+	stream bool          // 是否流式调用
+}
+
 type pending struct {
 	partitions []*partition // 分片
 }
@@ -10,19 +16,24 @@ func newPending() *pending {
 	p := &pending{partitions: make([]*partition, 20)}
 
 	for i := 0; i < len(p.partitions); i++ {
-		p.partitions[i] = &partition{calls: make(map[uint64]chan []byte)}
+		p.partitions[i] = &partition{calls: make(map[uint64]*call)}
 	}
 
 	return p
 }
 
+// 加载
+func (p *pending) load(seq uint64) (*call, bool) {
+	return p.partitions[int(seq%uint64(len(p.partitions)))].load(seq)
+}
+
 // 提取
-func (p *pending) extract(seq uint64) (chan []byte, bool) {
+func (p *pending) extract(seq uint64) (*call, bool) {
 	return p.partitions[int(seq%uint64(len(p.partitions)))].extract(seq)
 }
 
 // 存储
-func (p *pending) store(seq uint64, call chan []byte) {
+func (p *pending) store(seq uint64, call *call) {
 	p.partitions[int(seq%uint64(len(p.partitions)))].store(seq, call)
 }
 
@@ -32,12 +43,12 @@ func (p *pending) delete(seq uint64) {
 }
 
 type partition struct {
-	mu    sync.Mutex             // 锁
-	calls map[uint64]chan []byte // 同步通道
+	mu    sync.Mutex       // 锁
+	calls map[uint64]*call // 回调
 }
 
 // 提取
-func (p *partition) extract(seq uint64) (chan []byte, bool) {
+func (p *partition) extract(seq uint64) (*call, bool) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -49,8 +60,18 @@ func (p *partition) extract(seq uint64) (chan []byte, bool) {
 	return call, ok
 }
 
+// 加载
+func (p *partition) load(seq uint64) (*call, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	call, ok := p.calls[seq]
+
+	return call, ok
+}
+
 // 存储
-func (p *partition) store(seq uint64, call chan []byte) {
+func (p *partition) store(seq uint64, call *call) {
 	p.mu.Lock()
 	p.calls[seq] = call
 	p.mu.Unlock()
@@ -9,7 +9,7 @@ import (
 func BenchmarkPending(b *testing.B) {
 	var (
 		sequence uint64
-		call     = make(chan []byte)
+		call     = &call{ch: make(chan []byte)}
 		ch       = make(chan uint64, 10240)
 		p        = newPending()
 		wg       sync.WaitGroup
@@ -52,7 +52,7 @@ func BenchmarkPending(b *testing.B) {
 func BenchmarkSyncMap(b *testing.B) {
 	var (
 		sequence uint64
-		call     = make(chan []byte)
+		call     = &call{ch: make(chan []byte)}
 		ch       = make(chan uint64, 10240)
 		m        sync.Map
 		wg       sync.WaitGroup
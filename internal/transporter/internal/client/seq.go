@@ -0,0 +1,56 @@
+package client
+
+import (
+	"github.com/dobyte/due/v2/core/id"
+	"github.com/dobyte/due/v2/log"
+	"sync/atomic"
+)
+
+// SeqGenerator 序列号生成器，生成的序列号用于关联请求与响应，不得为0
+type SeqGenerator interface {
+	// Next 生成下一个序列号
+	Next() uint64
+}
+
+type atomicSeqGenerator struct {
+	seq atomic.Uint64
+}
+
+// NewSeqGenerator 新建单调递增的序列号生成器，规避生成序列号为0的编号
+func NewSeqGenerator() SeqGenerator {
+	return &atomicSeqGenerator{}
+}
+
+// Next 生成下一个序列号
+func (g *atomicSeqGenerator) Next() (seq uint64) {
+	for {
+		if seq = g.seq.Add(1); seq != 0 {
+			return
+		}
+	}
+}
+
+type idSeqGenerator struct {
+	generator id.Generator
+	fallback  SeqGenerator
+}
+
+// NewIDSeqGenerator 新建基于ID生成器的序列号生成器，可传入id.Snowflake使序列号在重连及重建客户端后仍全局唯一
+// ID生成失败（如时钟回拨超出容忍范围）时退化为单调递增的序列号
+func NewIDSeqGenerator(generator id.Generator) SeqGenerator {
+	return &idSeqGenerator{
+		generator: generator,
+		fallback:  NewSeqGenerator(),
+	}
+}
+
+// Next 生成下一个序列号
+func (g *idSeqGenerator) Next() uint64 {
+	seq, err := g.generator.Next()
+	if err != nil || seq <= 0 {
+		log.Warnf("generate sequence failed, fallback to counter: %v", err)
+		return g.fallback.Next()
+	}
+
+	return uint64(seq)
+}
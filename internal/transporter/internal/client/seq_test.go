@@ -0,0 +1,48 @@
+package client
+
+import (
+	"errors"
+	"github.com/dobyte/due/v2/core/id"
+	"testing"
+)
+
+type failGenerator struct{}
+
+func (g *failGenerator) Next() (int64, error) {
+	return 0, errors.New("clock moved backwards")
+}
+
+func TestSeqGenerator(t *testing.T) {
+	g := &atomicSeqGenerator{}
+	g.seq.Store(^uint64(0) - 1)
+
+	if seq := g.Next(); seq != ^uint64(0) {
+		t.Fatalf("unexpected seq: %d", seq)
+	}
+
+	if seq := g.Next(); seq != 1 {
+		t.Fatalf("seq 0 should be skipped, got: %d", seq)
+	}
+}
+
+func TestIDSeqGenerator(t *testing.T) {
+	snowflake, err := id.NewSnowflake(id.WithMachineID(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewIDSeqGenerator(snowflake)
+
+	last := g.Next()
+	for i := 0; i < 1000; i++ {
+		seq := g.Next()
+		if seq <= last {
+			t.Fatalf("seq is not increasing: %d <= %d", seq, last)
+		}
+		last = seq
+	}
+
+	if seq := NewIDSeqGenerator(&failGenerator{}).Next(); seq != 1 {
+		t.Fatalf("unexpected fallback seq: %d", seq)
+	}
+}
@@ -8,6 +8,8 @@ const (
 	OK              uint16 = iota // 成功
 	NotFoundSession               // 未找到会话连接
 	InternalError                 // 内部错误
+	DrainTimeout                  // 排空超时
+	DuplicateBind                 // 重复绑定
 )
 
 // ErrorToCode 错误转错误码
@@ -17,6 +19,10 @@ func ErrorToCode(err error) uint16 {
 		return OK
 	case errors.Is(err, errors.ErrNotFoundSession):
 		return NotFoundSession
+	case errors.Is(err, errors.ErrDrainTimeout):
+		return DrainTimeout
+	case errors.Is(err, errors.ErrDuplicateBind):
+		return DuplicateBind
 	default:
 		return InternalError
 	}
@@ -28,6 +34,10 @@ func CodeToError(code uint16) error {
 		return nil
 	case NotFoundSession:
 		return errors.ErrNotFoundSession
+	case DrainTimeout:
+		return errors.ErrDrainTimeout
+	case DuplicateBind:
+		return errors.ErrDuplicateBind
 	default:
 		return errors.ErrUnknownError
 	}
@@ -35,6 +35,10 @@ func DecodeBindReq(data []byte) (seq uint64, cid, uid int64, err error) {
 		return
 	}
 
+	if err = checkRoute(data, route.Bind); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
@@ -78,6 +82,10 @@ func DecodeBindRes(data []byte) (code uint16, err error) {
 		return
 	}
 
+	if err = checkRoute(data, route.Bind); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(-defaultCodeBytes, io.SeekEnd); err != nil {
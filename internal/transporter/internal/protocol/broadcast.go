@@ -33,6 +33,10 @@ func EncodeBroadcastReq(seq uint64, kind session.Kind, message buffer.Buffer) bu
 // DecodeBroadcastReq 解码广播请求
 // 协议：size + header + route + seq + session kind + <message packet>
 func DecodeBroadcastReq(data []byte) (seq uint64, kind session.Kind, message []byte, err error) {
+	if err = checkRoute(data, route.Broadcast); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
@@ -86,6 +90,10 @@ func DecodeBroadcastRes(data []byte) (code uint16, total uint64, err error) {
 		return
 	}
 
+	if err = checkRoute(data, route.Broadcast); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes+defaultSeqBytes, io.SeekStart); err != nil {
@@ -102,3 +110,109 @@ func DecodeBroadcastRes(data []byte) (code uint16, total uint64, err error) {
 
 	return
 }
+
+// EncodeBroadcastCodecsReq 编码按编解码器区分的广播请求，网关按连接协商的编解码器选择对应的消息，未协商或无对应消息时使用编解码器名称为空的消息
+// 协议：size + header + route + seq + session kind + count + [codec len + codec + message len + <message packet>]...
+func EncodeBroadcastCodecsReq(seq uint64, kind session.Kind, messages map[string][]byte) buffer.Buffer {
+	size := broadcastReqBytes + codecMessagesBytes(messages)
+
+	buf := buffer.NewNocopyBuffer()
+	writer := buf.Malloc(size)
+	writer.WriteUint32s(binary.BigEndian, uint32(size-defaultSizeBytes))
+	writer.WriteUint8s(dataBit)
+	writer.WriteUint8s(route.BroadcastCodecs)
+	writer.WriteUint64s(binary.BigEndian, seq)
+	writer.WriteUint8s(uint8(kind))
+	writeCodecMessages(writer, messages)
+
+	return buf
+}
+
+// DecodeBroadcastCodecsReq 解码按编解码器区分的广播请求
+// 协议：size + header + route + seq + session kind + count + [codec len + codec + message len + <message packet>]...
+func DecodeBroadcastCodecsReq(data []byte) (seq uint64, kind session.Kind, messages map[string][]byte, err error) {
+	if err = checkRoute(data, route.BroadcastCodecs); err != nil {
+		return
+	}
+
+	reader := buffer.NewReader(data)
+
+	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
+		return
+	}
+
+	if seq, err = reader.ReadUint64(binary.BigEndian); err != nil {
+		return
+	}
+
+	var k uint8
+	if k, err = reader.ReadUint8(); err != nil {
+		return
+	} else {
+		kind = session.Kind(k)
+	}
+
+	messages, err = readCodecMessages(reader)
+
+	return
+}
+
+// 按编解码器区分的消息字节数
+// 协议：count + [codec len + codec + message len + <message packet>]...
+func codecMessagesBytes(messages map[string][]byte) int {
+	size := b8
+	for codec, message := range messages {
+		size += b8 + len(codec) + b32 + len(message)
+	}
+
+	return size
+}
+
+// 写入按编解码器区分的消息
+// 协议：count + [codec len + codec + message len + <message packet>]...
+func writeCodecMessages(writer *buffer.Writer, messages map[string][]byte) {
+	writer.WriteUint8s(uint8(len(messages)))
+
+	for codec, message := range messages {
+		writer.WriteUint8s(uint8(len(codec)))
+		writer.WriteString(codec)
+		writer.WriteUint32s(binary.BigEndian, uint32(len(message)))
+		writer.WriteBytes(message...)
+	}
+}
+
+// 读取按编解码器区分的消息
+// 协议：count + [codec len + codec + message len + <message packet>]...
+func readCodecMessages(reader *buffer.Reader) (messages map[string][]byte, err error) {
+	var count, n uint8
+	if count, err = reader.ReadUint8(); err != nil {
+		return
+	}
+
+	messages = make(map[string][]byte, count)
+
+	for i := 0; i < int(count); i++ {
+		if n, err = reader.ReadUint8(); err != nil {
+			return
+		}
+
+		var codec string
+		if codec, err = reader.ReadString(int(n)); err != nil {
+			return
+		}
+
+		var size uint32
+		if size, err = reader.ReadUint32(binary.BigEndian); err != nil {
+			return
+		}
+
+		var message []byte
+		if message, err = reader.ReadBytes(int(size)); err != nil {
+			return
+		}
+
+		messages[codec] = message
+	}
+
+	return
+}
@@ -63,3 +63,24 @@ func TestDecodeBroadcastRes(t *testing.T) {
 	t.Logf("code: %v", code)
 	t.Logf("total: %v", total)
 }
+
+func TestBroadcastCodecsReq(t *testing.T) {
+	messages := map[string][]byte{"": []byte("proto"), "json": []byte(`{"a":1}`)}
+
+	buf := protocol.EncodeBroadcastCodecsReq(3, session.User, messages)
+
+	seq, kind, decoded, err := protocol.DecodeBroadcastCodecsReq(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if seq != 3 || kind != session.User || len(decoded) != 2 {
+		t.Fatalf("unexpected req: seq=%d kind=%v messages=%v", seq, kind, decoded)
+	}
+
+	for codec, message := range messages {
+		if string(decoded[codec]) != string(message) {
+			t.Fatalf("unexpected message of codec %q: %s", codec, decoded[codec])
+		}
+	}
+}
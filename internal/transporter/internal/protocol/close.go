@@ -0,0 +1,50 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"github.com/dobyte/due/v2/core/buffer"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/internal/transporter/internal/route"
+	"io"
+)
+
+const (
+	closeReqBytes = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + defaultCodeBytes
+)
+
+// EncodeCloseReq 编码关闭通知，关闭通知无需响应，序列号固定为0
+// 协议：size + header + route + seq + code
+func EncodeCloseReq(code uint16) buffer.Buffer {
+	buf := buffer.NewNocopyBuffer()
+	writer := buf.Malloc(closeReqBytes)
+	writer.WriteUint32s(binary.BigEndian, uint32(closeReqBytes-defaultSizeBytes))
+	writer.WriteUint8s(dataBit)
+	writer.WriteUint8s(route.Close)
+	writer.WriteUint64s(binary.BigEndian, 0)
+	writer.WriteUint16s(binary.BigEndian, code)
+
+	return buf
+}
+
+// DecodeCloseReq 解码关闭通知
+// 协议：size + header + route + seq + code
+func DecodeCloseReq(data []byte) (code uint16, err error) {
+	if len(data) != closeReqBytes {
+		err = errors.ErrInvalidMessage
+		return
+	}
+
+	if err = checkRoute(data, route.Close); err != nil {
+		return
+	}
+
+	reader := buffer.NewReader(data)
+
+	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes+defaultSeqBytes, io.SeekStart); err != nil {
+		return
+	}
+
+	code, err = reader.ReadUint16(binary.BigEndian)
+
+	return
+}
@@ -0,0 +1,20 @@
+package protocol_test
+
+import (
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
+	"testing"
+)
+
+func TestDecodeCloseReq(t *testing.T) {
+	buf := protocol.EncodeCloseReq(uint16(cluster.CloseMaintenance))
+
+	code, err := protocol.DecodeCloseReq(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cluster.CloseReason(code) != cluster.CloseMaintenance {
+		t.Fatalf("round trip mismatch, code: %v", code)
+	}
+}
@@ -0,0 +1,75 @@
+package protocol
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// 支持设置读取超时的读取器，如net.Conn
+type deadlineReader interface {
+	SetReadDeadline(t time.Time) error
+}
+
+type readResult struct {
+	isHeartbeat bool
+	route       uint8
+	seq         uint64
+	data        []byte
+	err         error
+}
+
+// ReadMessageContext 读取消息，上下文取消时中止读取并返回ctx.Err()
+// 读取器（或带缓冲读取器的底层读取器）支持设置读取超时时，取消时将读取超时设为过去的时间以中止读取，否则在协程中读取，中止后协程将在底层读取返回后退出
+// 未取消时不会修改读取器的读取超时；取消后读取超时保持为过去的时间，读取器中可能残留未读完的消息，调用方不应继续使用该读取器
+func ReadMessageContext(ctx context.Context, reader io.Reader) (isHeartbeat bool, route uint8, seq uint64, data []byte, err error) {
+	if ctx.Done() == nil {
+		return ReadMessage(reader)
+	}
+
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	if r, ok := deadlineReaderOf(reader); ok {
+		stop := context.AfterFunc(ctx, func() {
+			_ = r.SetReadDeadline(time.Unix(1, 0))
+		})
+
+		isHeartbeat, route, seq, data, err = ReadMessage(reader)
+
+		// 取消与读取完成同时发生时，已完整读取的消息仍然返回
+		if !stop() && err != nil {
+			err = ctx.Err()
+		}
+
+		return
+	}
+
+	ch := make(chan readResult, 1)
+
+	go func() {
+		var res readResult
+		res.isHeartbeat, res.route, res.seq, res.data, res.err = ReadMessage(reader)
+		ch <- res
+	}()
+
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+		return
+	case res := <-ch:
+		return res.isHeartbeat, res.route, res.seq, res.data, res.err
+	}
+}
+
+// 获取可设置读取超时的读取器，带缓冲读取器使用其底层读取器
+func deadlineReaderOf(reader io.Reader) (deadlineReader, bool) {
+	if r, ok := reader.(*BufferedReader); ok {
+		reader = r.rd
+	}
+
+	r, ok := reader.(deadlineReader)
+
+	return r, ok
+}
@@ -0,0 +1,70 @@
+package protocol_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestReadMessageContext(t *testing.T) {
+	frame := protocol.EncodeDeliverReq(1, 2, 3, []byte("hello world")).Bytes()
+
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	// 带缓冲的读取器通过底层连接设置读取超时
+	reader := protocol.NewBufferedReader(server, 1024)
+
+	go func() { _, _ = client.Write(frame) }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, _, seq, data, err := protocol.ReadMessageContext(ctx, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if seq != 1 || !bytes.Equal(data, frame) {
+		t.Fatalf("unexpected message, seq: %d", seq)
+	}
+
+	// 未取消时保留调用方设置的读取超时
+	if err = server.SetReadDeadline(time.Now().Add(50 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, _, _, err = protocol.ReadMessageContext(ctx, reader); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	if err = server.SetReadDeadline(time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	if _, _, _, _, err = protocol.ReadMessageContext(ctx, reader); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled, got %v", err)
+	}
+}
+
+func TestReadMessageContext_Goroutine(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	// 不支持读取超时的读取器在协程中读取
+	if _, _, _, _, err := protocol.ReadMessageContext(ctx, pr); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled, got %v", err)
+	}
+}
@@ -8,9 +8,16 @@ const (
 	defaultCodeBytes   = 2 // 错误码字节数
 )
 
+const (
+	MaxMessageBytes = 16 * 1024 * 1024 // 最大消息字节数（包含包长度）
+)
+
 const (
 	dataBit      uint8 = 0 << 7 // 数据标识位
 	heartbeatBit uint8 = 1 << 7 // 心跳标识位
+	moreBit      uint8 = 1 << 6 // 流式响应后续帧标识位
+	extBit       uint8 = 1 << 5 // 扩展头信息标识位
+	priorityBit  uint8 = 1 << 4 // 高优先级标识位
 )
 
 const (
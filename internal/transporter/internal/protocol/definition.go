@@ -0,0 +1,282 @@
+package protocol
+
+import (
+	"fmt"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/internal/transporter/internal/route"
+	"sort"
+	"sync"
+)
+
+const definitionVersion uint8 = 1
+
+// Fields 消息包字段，供录制回放、日志、统计等工具展示
+type Fields map[string]any
+
+// Decoder 消息包解码器
+type Decoder func(data []byte) (Fields, error)
+
+// Definition 消息包定义
+type Definition struct {
+	Route     uint8   // 路由号
+	Name      string  // 名称
+	Version   uint8   // 协议版本
+	DecodeReq Decoder // 请求解码器
+	DecodeRes Decoder // 响应解码器
+}
+
+var definitions struct {
+	rw    sync.RWMutex
+	items map[uint8]*Definition
+}
+
+func init() {
+	definitions.items = make(map[uint8]*Definition)
+
+	err := Register(
+		&Definition{Route: route.Handshake, Name: "handshake", DecodeReq: decodeHandshakeReq, DecodeRes: decodeCodeRes(DecodeHandshakeRes)},
+		&Definition{Route: route.Bind, Name: "bind", DecodeReq: decodeBindReq, DecodeRes: decodeCodeRes(DecodeBindRes)},
+		&Definition{Route: route.Unbind, Name: "unbind", DecodeReq: decodeUnbindReq, DecodeRes: decodeCodeRes(DecodeUnbindRes)},
+		&Definition{Route: route.GetIP, Name: "getip", DecodeReq: decodeGetIPReq, DecodeRes: decodeGetIPRes},
+		&Definition{Route: route.Stat, Name: "stat", DecodeReq: decodeStatReq, DecodeRes: decodeTotalRes(DecodeStatRes)},
+		&Definition{Route: route.IsOnline, Name: "isonline", DecodeReq: decodeIsOnlineReq, DecodeRes: decodeIsOnlineRes},
+		&Definition{Route: route.Disconnect, Name: "disconnect", DecodeReq: decodeDisconnectReq, DecodeRes: decodeCodeRes(DecodeDisconnectRes)},
+		&Definition{Route: route.Push, Name: "push", DecodeReq: decodePushReq, DecodeRes: decodeCodeRes(DecodePushRes)},
+		&Definition{Route: route.Multicast, Name: "multicast", DecodeReq: decodeMulticastReq, DecodeRes: decodeTotalRes(DecodeMulticastRes)},
+		&Definition{Route: route.Broadcast, Name: "broadcast", DecodeReq: decodeBroadcastReq, DecodeRes: decodeTotalRes(DecodeBroadcastRes)},
+		&Definition{Route: route.Trigger, Name: "trigger", DecodeReq: decodeTriggerReq, DecodeRes: decodeCodeRes(DecodeTriggerRes)},
+		&Definition{Route: route.Deliver, Name: "deliver", DecodeReq: decodeDeliverReq, DecodeRes: decodeCodeRes(DecodeDeliverRes)},
+		&Definition{Route: route.GetState, Name: "getstate", DecodeReq: decodeGetStateReq, DecodeRes: decodeGetStateRes},
+		&Definition{Route: route.SetState, Name: "setstate", DecodeReq: decodeSetStateReq, DecodeRes: decodeCodeRes(DecodeSetStateRes)},
+		&Definition{Route: route.Kick, Name: "kick", DecodeReq: decodeKickReq, DecodeRes: decodeCodeRes(DecodeKickRes)},
+		&Definition{Route: route.Drain, Name: "drain", DecodeReq: decodeDrainReq, DecodeRes: decodeDrainRes},
+		&Definition{Route: route.Close, Name: "close", DecodeReq: decodeCloseReq},
+		&Definition{Route: route.BroadcastCodecs, Name: "broadcastcodecs", DecodeReq: decodeBroadcastCodecsReq},
+		&Definition{Route: route.Limit, Name: "limit", DecodeReq: decodeLimitReq},
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Register 注册消息包定义，路由号或名称与已注册的定义冲突时返回errors.ErrRouteConflict
+func Register(defs ...*Definition) error {
+	definitions.rw.Lock()
+	defer definitions.rw.Unlock()
+
+	for _, def := range defs {
+		if def.Name == "" {
+			return errors.ErrInvalidArgument
+		}
+
+		if exists, ok := definitions.items[def.Route]; ok {
+			return errors.NewError(fmt.Sprintf("route %d is already registered as %s", def.Route, exists.Name), errors.ErrRouteConflict)
+		}
+
+		for _, exists := range definitions.items {
+			if exists.Name == def.Name {
+				return errors.NewError(fmt.Sprintf("name %s is already registered by route %d", def.Name, exists.Route), errors.ErrRouteConflict)
+			}
+		}
+
+		if def.Version == 0 {
+			def.Version = definitionVersion
+		}
+
+		definitions.items[def.Route] = def
+	}
+
+	return nil
+}
+
+// Lookup 通过路由号查找消息包定义
+func Lookup(route uint8) (*Definition, bool) {
+	definitions.rw.RLock()
+	defer definitions.rw.RUnlock()
+
+	def, ok := definitions.items[route]
+
+	return def, ok
+}
+
+// Definitions 获取所有消息包定义，按路由号排序
+func Definitions() []*Definition {
+	definitions.rw.RLock()
+	defer definitions.rw.RUnlock()
+
+	defs := make([]*Definition, 0, len(definitions.items))
+	for _, def := range definitions.items {
+		defs = append(defs, def)
+	}
+
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Route < defs[j].Route })
+
+	return defs
+}
+
+// Label 获取消息包标签，可用于日志及统计
+func Label(isHeartbeat bool, route uint8) string {
+	if isHeartbeat {
+		return "heartbeat"
+	}
+
+	if def, ok := Lookup(route); ok {
+		return def.Name
+	}
+
+	return fmt.Sprintf("unknown(%d)", route)
+}
+
+// Describe 解码消息包字段，isReply为true时按响应进行解码
+func Describe(isHeartbeat, isReply bool, route uint8, data []byte) (string, Fields, error) {
+	if isHeartbeat {
+		return Label(true, route), Fields{}, nil
+	}
+
+	def, ok := Lookup(route)
+	if !ok {
+		return Label(false, route), nil, errors.ErrInvalidRoute
+	}
+
+	decode := def.DecodeReq
+	if isReply {
+		decode = def.DecodeRes
+	}
+
+	if decode == nil {
+		return def.Name, Fields{}, nil
+	}
+
+	fields, err := decode(data)
+
+	return def.Name, fields, err
+}
+
+func decodeCodeRes(fn func(data []byte) (uint16, error)) Decoder {
+	return func(data []byte) (Fields, error) {
+		code, err := fn(data)
+		return Fields{"code": code}, err
+	}
+}
+
+func decodeTotalRes(fn func(data []byte) (uint16, uint64, error)) Decoder {
+	return func(data []byte) (Fields, error) {
+		code, total, err := fn(data)
+		return Fields{"code": code, "total": total}, err
+	}
+}
+
+func decodeHandshakeReq(data []byte) (Fields, error) {
+	seq, insKind, insID, err := DecodeHandshakeReq(data)
+	return Fields{"seq": seq, "insKind": insKind, "insID": insID}, err
+}
+
+func decodeBindReq(data []byte) (Fields, error) {
+	seq, cid, uid, err := DecodeBindReq(data)
+	return Fields{"seq": seq, "cid": cid, "uid": uid}, err
+}
+
+func decodeUnbindReq(data []byte) (Fields, error) {
+	seq, uid, err := DecodeUnbindReq(data)
+	return Fields{"seq": seq, "uid": uid}, err
+}
+
+func decodeGetIPReq(data []byte) (Fields, error) {
+	seq, kind, target, err := DecodeGetIPReq(data)
+	return Fields{"seq": seq, "kind": kind, "target": target}, err
+}
+
+func decodeGetIPRes(data []byte) (Fields, error) {
+	code, ip, err := DecodeGetIPRes(data)
+	return Fields{"code": code, "ip": ip}, err
+}
+
+func decodeStatReq(data []byte) (Fields, error) {
+	seq, kind, err := DecodeStatReq(data)
+	return Fields{"seq": seq, "kind": kind}, err
+}
+
+func decodeIsOnlineReq(data []byte) (Fields, error) {
+	seq, kind, target, err := DecodeIsOnlineReq(data)
+	return Fields{"seq": seq, "kind": kind, "target": target}, err
+}
+
+func decodeIsOnlineRes(data []byte) (Fields, error) {
+	code, isOnline, err := DecodeIsOnlineRes(data)
+	return Fields{"code": code, "isOnline": isOnline}, err
+}
+
+func decodeDisconnectReq(data []byte) (Fields, error) {
+	seq, kind, target, force, err := DecodeDisconnectReq(data)
+	return Fields{"seq": seq, "kind": kind, "target": target, "force": force}, err
+}
+
+func decodePushReq(data []byte) (Fields, error) {
+	seq, kind, target, header, message, err := DecodePushReq(data)
+	return Fields{"seq": seq, "kind": kind, "target": target, "header": header, "message": len(message)}, err
+}
+
+func decodeMulticastReq(data []byte) (Fields, error) {
+	seq, kind, targets, message, err := DecodeMulticastReq(data)
+	return Fields{"seq": seq, "kind": kind, "targets": targets, "message": len(message)}, err
+}
+
+func decodeBroadcastReq(data []byte) (Fields, error) {
+	seq, kind, message, err := DecodeBroadcastReq(data)
+	return Fields{"seq": seq, "kind": kind, "message": len(message)}, err
+}
+
+func decodeBroadcastCodecsReq(data []byte) (Fields, error) {
+	seq, kind, messages, err := DecodeBroadcastCodecsReq(data)
+	return Fields{"seq": seq, "kind": kind, "messages": len(messages)}, err
+}
+
+func decodeTriggerReq(data []byte) (Fields, error) {
+	seq, event, cid, uid, err := DecodeTriggerReq(data)
+	return Fields{"seq": seq, "event": event, "cid": cid, "uid": uid}, err
+}
+
+func decodeDeliverReq(data []byte) (Fields, error) {
+	seq, cid, uid, codec, message, err := DecodeDeliverReqWithCodec(data)
+	return Fields{"seq": seq, "cid": cid, "uid": uid, "codec": codec, "message": len(message)}, err
+}
+
+func decodeGetStateReq(data []byte) (Fields, error) {
+	seq, err := DecodeGetStateReq(data)
+	return Fields{"seq": seq}, err
+}
+
+func decodeGetStateRes(data []byte) (Fields, error) {
+	code, state, err := DecodeGetStateRes(data)
+	return Fields{"code": code, "state": state}, err
+}
+
+func decodeSetStateReq(data []byte) (Fields, error) {
+	seq, state, err := DecodeSetStateReq(data)
+	return Fields{"seq": seq, "state": state}, err
+}
+
+func decodeKickReq(data []byte) (Fields, error) {
+	seq, uid, messages, err := DecodeKickReq(data)
+	return Fields{"seq": seq, "uid": uid, "messages": len(messages)}, err
+}
+
+func decodeDrainReq(data []byte) (Fields, error) {
+	seq, timeout, err := DecodeDrainReq(data)
+	return Fields{"seq": seq, "timeout": timeout}, err
+}
+
+func decodeDrainRes(data []byte) (Fields, error) {
+	code, remaining, err := DecodeDrainRes(data)
+	return Fields{"code": code, "remaining": remaining}, err
+}
+
+func decodeCloseReq(data []byte) (Fields, error) {
+	code, err := DecodeCloseReq(data)
+	return Fields{"code": code}, err
+}
+
+func decodeLimitReq(data []byte) (Fields, error) {
+	maxMessageSize, err := DecodeLimitReq(data)
+	return Fields{"maxMessageSize": maxMessageSize}, err
+}
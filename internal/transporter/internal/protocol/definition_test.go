@@ -0,0 +1,47 @@
+package protocol_test
+
+import (
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
+	"github.com/dobyte/due/v2/internal/transporter/internal/route"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	def, ok := protocol.Lookup(route.Unbind)
+	if !ok {
+		t.Fatal("unbind definition not found")
+	}
+
+	t.Logf("route: %d name: %s version: %d", def.Route, def.Name, def.Version)
+
+	for _, def := range protocol.Definitions() {
+		t.Logf("route: %d name: %s", def.Route, def.Name)
+	}
+
+	if label := protocol.Label(false, 200); label != "unknown(200)" {
+		t.Fatalf("unexpected label: %s", label)
+	}
+}
+
+func TestRegister_Conflict(t *testing.T) {
+	err := protocol.Register(&protocol.Definition{Route: route.Push, Name: "custom"})
+	if !errors.Is(err, errors.ErrRouteConflict) {
+		t.Fatalf("expected route conflict, got %v", err)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	data := protocol.EncodeUnbindReq(1, 100).Bytes()
+
+	name, fields, err := protocol.Describe(false, false, route.Unbind, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name != "unbind" || fields["uid"] != int64(100) {
+		t.Fatalf("unexpected description: %s %v", name, fields)
+	}
+
+	t.Logf("name: %s fields: %v", name, fields)
+}
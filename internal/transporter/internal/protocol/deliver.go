@@ -13,6 +13,9 @@ const (
 	deliverResBytes = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + defaultCodeBytes
 )
 
+// MaxDeliverMessageBytes 投递消息的最大消息字节数，与读取消息时的最大消息字节数保持一致
+const MaxDeliverMessageBytes = MaxMessageBytes - deliverReqBytes
+
 // EncodeDeliverReq 编码投递消息请求
 // 协议：size + header + route + seq + cid + uid + <message packet>
 func EncodeDeliverReq(seq uint64, cid int64, uid int64, message []byte) buffer.Buffer {
@@ -28,11 +31,63 @@ func EncodeDeliverReq(seq uint64, cid int64, uid int64, message []byte) buffer.B
 	return buf
 }
 
+// EncodeDeliverReqWithCodec 编码携带编解码器的投递消息请求，编解码器为空时与EncodeDeliverReq一致
+// 协议：size + header(ext) + route + seq + cid + uid + codec len + codec + <message packet>
+func EncodeDeliverReqWithCodec(seq uint64, cid int64, uid int64, codec string, message []byte) buffer.Buffer {
+	if codec == "" {
+		return EncodeDeliverReq(seq, cid, uid, message)
+	}
+
+	size := deliverReqBytes + b8 + len(codec)
+	buf := buffer.NewNocopyBuffer()
+	writer := buf.Malloc(size)
+	writer.WriteUint32s(binary.BigEndian, uint32(size-defaultSizeBytes+len(message)))
+	writer.WriteUint8s(dataBit | extBit)
+	writer.WriteUint8s(route.Deliver)
+	writer.WriteUint64s(binary.BigEndian, seq)
+	writer.WriteInt64s(binary.BigEndian, cid, uid)
+	writer.WriteUint8s(uint8(len(codec)))
+	writer.WriteString(codec)
+	buf.Mount(message)
+
+	return buf
+}
+
 // DecodeDeliverReq 解码投递消息请求
 func DecodeDeliverReq(data []byte) (seq uint64, cid int64, uid int64, message []byte, err error) {
+	seq, cid, uid, _, message, err = DecodeDeliverReqWithCodec(data)
+	return
+}
+
+// DecodeDeliverReqWithCodec 解码投递消息请求，未携带编解码器时codec为空
+// 协议：size + header + route + seq + cid + uid + [codec len + codec] + <message packet>
+func DecodeDeliverReqWithCodec(data []byte) (seq uint64, cid int64, uid int64, codec string, message []byte, err error) {
+	if len(data) < deliverReqBytes {
+		err = errors.ErrInvalidMessage
+		return
+	}
+
+	if len(data) > MaxMessageBytes {
+		err = errors.ErrMessageTooLarge
+		return
+	}
+
+	if err = checkRoute(data, route.Deliver); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
-	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
+	if _, err = reader.Seek(defaultSizeBytes, io.SeekStart); err != nil {
+		return
+	}
+
+	var h uint8
+	if h, err = reader.ReadUint8(); err != nil {
+		return
+	}
+
+	if _, err = reader.Seek(defaultRouteBytes, io.SeekCurrent); err != nil {
 		return
 	}
 
@@ -48,7 +103,23 @@ func DecodeDeliverReq(data []byte) (seq uint64, cid int64, uid int64, message []
 		return
 	}
 
-	message = data[deliverReqBytes:]
+	if h&extBit == 0 {
+		message = data[deliverReqBytes:]
+		return
+	}
+
+	var n uint8
+	if n, err = reader.ReadUint8(); err != nil {
+		return
+	}
+
+	if len(data) < deliverReqBytes+b8+int(n) {
+		err = errors.ErrInvalidMessage
+		return
+	}
+
+	codec = string(data[deliverReqBytes+b8 : deliverReqBytes+b8+int(n)])
+	message = data[deliverReqBytes+b8+int(n):]
 
 	return
 }
@@ -75,6 +146,10 @@ func DecodeDeliverRes(data []byte) (code uint16, err error) {
 		return
 	}
 
+	if err = checkRoute(data, route.Deliver); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(-defaultCodeBytes, io.SeekEnd); err != nil {
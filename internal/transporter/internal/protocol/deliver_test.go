@@ -1,6 +1,7 @@
 package protocol_test
 
 import (
+	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/internal/transporter/internal/codes"
 	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
 	"testing"
@@ -33,7 +34,7 @@ func TestEncodeDeliverRes(t *testing.T) {
 }
 
 func TestDecodeDeliverRes(t *testing.T) {
-	buffer := protocol.EncodePushRes(1, codes.OK)
+	buffer := protocol.EncodeDeliverRes(1, codes.OK)
 
 	code, err := protocol.DecodeDeliverRes(buffer.Bytes())
 	if err != nil {
@@ -42,3 +43,43 @@ func TestDecodeDeliverRes(t *testing.T) {
 
 	t.Logf("code: %v", code)
 }
+
+func TestDeliverReq_RoundTrip(t *testing.T) {
+	buffer := protocol.EncodeDeliverReq(1, 2, 3, []byte("hello world"))
+
+	seq, cid, uid, message, err := protocol.DecodeDeliverReq(buffer.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if seq != 1 || cid != 2 || uid != 3 || string(message) != "hello world" {
+		t.Fatalf("round trip mismatch, seq: %v cid: %v uid: %v message: %v", seq, cid, uid, string(message))
+	}
+}
+
+func TestDecodeDeliverReq_Invalid(t *testing.T) {
+	buffer := protocol.EncodeDeliverReq(1, 2, 3, nil)
+
+	data := buffer.Bytes()
+
+	if _, _, _, _, err := protocol.DecodeDeliverReq(data[:len(data)-1]); !errors.Is(err, errors.ErrInvalidMessage) {
+		t.Fatalf("expected invalid message, got %v", err)
+	}
+}
+
+func TestDeliverReqWithCodec_RoundTrip(t *testing.T) {
+	buffer := protocol.EncodeDeliverReqWithCodec(1, 2, 3, "proto", []byte("hello world"))
+
+	seq, cid, uid, codec, message, err := protocol.DecodeDeliverReqWithCodec(buffer.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if seq != 1 || cid != 2 || uid != 3 || codec != "proto" || string(message) != "hello world" {
+		t.Fatalf("round trip mismatch, seq: %v cid: %v uid: %v codec: %v message: %v", seq, cid, uid, codec, string(message))
+	}
+
+	if _, _, _, message, err = protocol.DecodeDeliverReq(buffer.Bytes()); err != nil || string(message) != "hello world" {
+		t.Fatalf("decode without codec mismatch, message: %v err: %v", string(message), err)
+	}
+}
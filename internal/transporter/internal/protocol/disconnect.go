@@ -38,6 +38,10 @@ func DecodeDisconnectReq(data []byte) (seq uint64, kind session.Kind, target int
 		return
 	}
 
+	if err = checkRoute(data, route.Disconnect); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
@@ -88,6 +92,10 @@ func DecodeDisconnectRes(data []byte) (code uint16, err error) {
 		return
 	}
 
+	if err = checkRoute(data, route.Disconnect); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(-defaultCodeBytes, io.SeekEnd); err != nil {
@@ -0,0 +1,105 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"github.com/dobyte/due/v2/core/buffer"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/internal/transporter/internal/route"
+	"io"
+	"time"
+)
+
+const (
+	drainReqBytes = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + b32
+	drainResBytes = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + defaultCodeBytes + b64
+)
+
+// EncodeDrainReq 编码排空节点请求
+// 协议：size + header + route + seq + timeout
+func EncodeDrainReq(seq uint64, timeout time.Duration) buffer.Buffer {
+	buf := buffer.NewNocopyBuffer()
+	writer := buf.Malloc(drainReqBytes)
+	writer.WriteUint32s(binary.BigEndian, uint32(drainReqBytes-defaultSizeBytes))
+	writer.WriteUint8s(dataBit)
+	writer.WriteUint8s(route.Drain)
+	writer.WriteUint64s(binary.BigEndian, seq)
+	writer.WriteUint32s(binary.BigEndian, uint32(timeout.Milliseconds()))
+
+	return buf
+}
+
+// DecodeDrainReq 解码排空节点请求
+// 协议：size + header + route + seq + timeout
+func DecodeDrainReq(data []byte) (seq uint64, timeout time.Duration, err error) {
+	if len(data) != drainReqBytes {
+		err = errors.ErrInvalidMessage
+		return
+	}
+
+	if err = checkRoute(data, route.Drain); err != nil {
+		return
+	}
+
+	reader := buffer.NewReader(data)
+
+	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
+		return
+	}
+
+	if seq, err = reader.ReadUint64(binary.BigEndian); err != nil {
+		return
+	}
+
+	var ms uint32
+	if ms, err = reader.ReadUint32(binary.BigEndian); err != nil {
+		return
+	}
+
+	timeout = time.Duration(ms) * time.Millisecond
+
+	return
+}
+
+// EncodeDrainRes 编码排空节点响应
+// 协议：size + header + route + seq + code + remaining
+func EncodeDrainRes(seq uint64, code uint16, remaining uint64) buffer.Buffer {
+	buf := buffer.NewNocopyBuffer()
+	writer := buf.Malloc(drainResBytes)
+	writer.WriteUint32s(binary.BigEndian, uint32(drainResBytes-defaultSizeBytes))
+	writer.WriteUint8s(dataBit)
+	writer.WriteUint8s(route.Drain)
+	writer.WriteUint64s(binary.BigEndian, seq)
+	writer.WriteUint16s(binary.BigEndian, code)
+	writer.WriteUint64s(binary.BigEndian, remaining)
+
+	return buf
+}
+
+// DecodeDrainRes 解码排空节点响应
+// 协议：size + header + route + seq + code + remaining
+func DecodeDrainRes(data []byte) (code uint16, remaining uint64, err error) {
+	if len(data) != drainResBytes {
+		err = errors.ErrInvalidMessage
+		return
+	}
+
+	if err = checkRoute(data, route.Drain); err != nil {
+		return
+	}
+
+	reader := buffer.NewReader(data)
+
+	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes+defaultSeqBytes, io.SeekStart); err != nil {
+		return
+	}
+
+	if code, err = reader.ReadUint16(binary.BigEndian); err != nil {
+		return
+	}
+
+	if remaining, err = reader.ReadUint64(binary.BigEndian); err != nil {
+		return
+	}
+
+	return
+}
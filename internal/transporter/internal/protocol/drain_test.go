@@ -0,0 +1,34 @@
+package protocol_test
+
+import (
+	"github.com/dobyte/due/v2/internal/transporter/internal/codes"
+	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
+	"testing"
+	"time"
+)
+
+func TestDecodeDrainReq(t *testing.T) {
+	buf := protocol.EncodeDrainReq(1, 30*time.Second)
+
+	seq, timeout, err := protocol.DecodeDrainReq(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if seq != 1 || timeout != 30*time.Second {
+		t.Fatalf("round trip mismatch, seq: %v timeout: %v", seq, timeout)
+	}
+}
+
+func TestDecodeDrainRes(t *testing.T) {
+	buf := protocol.EncodeDrainRes(1, codes.DrainTimeout, 3)
+
+	code, remaining, err := protocol.DecodeDrainRes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code != codes.DrainTimeout || remaining != 3 {
+		t.Fatalf("round trip mismatch, code: %v remaining: %v", code, remaining)
+	}
+}
@@ -39,6 +39,10 @@ func DecodeGetIPReq(data []byte) (seq uint64, kind session.Kind, target int64, e
 		return
 	}
 
+	if err = checkRoute(data, route.GetIP); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
@@ -92,6 +96,10 @@ func DecodeGetIPRes(data []byte) (code uint16, ip string, err error) {
 		return
 	}
 
+	if err = checkRoute(data, route.GetIP); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes+defaultSeqBytes, io.SeekStart); err != nil {
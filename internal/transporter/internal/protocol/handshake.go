@@ -12,6 +12,7 @@ import (
 const (
 	handshakeReqBytes = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + b8
 	handshakeResBytes = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + defaultCodeBytes
+	limitReqBytes     = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + b32
 )
 
 // EncodeHandshakeReq 编码握手请求
@@ -33,6 +34,15 @@ func EncodeHandshakeReq(seq uint64, insKind cluster.Kind, insID string) buffer.B
 // DecodeHandshakeReq 解码握手请求
 // 协议：size + header + route + seq + ins kind + ins id
 func DecodeHandshakeReq(data []byte) (seq uint64, insKind cluster.Kind, insID string, err error) {
+	if len(data) < handshakeReqBytes {
+		err = errors.ErrInvalidMessage
+		return
+	}
+
+	if err = checkRoute(data, route.Handshake); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
@@ -71,17 +81,48 @@ func EncodeHandshakeRes(seq uint64, code uint16) buffer.Buffer {
 	return buf
 }
 
+// EncodeHandshakeResWithLimit 编码携带最大消息字节数的握手响应，maxMessageSize为0时与EncodeHandshakeRes一致
+// 旧版本客户端不解析握手响应内容，因此服务端总是可以在握手响应中通告最大消息字节数
+// 协议：size + header(ext) + route + seq + code + max message size
+func EncodeHandshakeResWithLimit(seq uint64, code uint16, maxMessageSize uint32) buffer.Buffer {
+	if maxMessageSize == 0 {
+		return EncodeHandshakeRes(seq, code)
+	}
+
+	buf := buffer.NewNocopyBuffer()
+	writer := buf.Malloc(handshakeResBytes + b32)
+	writer.WriteUint32s(binary.BigEndian, uint32(handshakeResBytes+b32-defaultSizeBytes))
+	writer.WriteUint8s(dataBit | extBit)
+	writer.WriteUint8s(route.Handshake)
+	writer.WriteUint64s(binary.BigEndian, seq)
+	writer.WriteUint16s(binary.BigEndian, code)
+	writer.WriteUint32s(binary.BigEndian, maxMessageSize)
+
+	return buf
+}
+
 // DecodeHandshakeRes 解码握手响应
 // 协议：size + header + route + seq + code
 func DecodeHandshakeRes(data []byte) (code uint16, err error) {
-	if len(data) != handshakeResBytes {
+	code, _, err = DecodeHandshakeResWithLimit(data)
+	return
+}
+
+// DecodeHandshakeResWithLimit 解码握手响应，未携带最大消息字节数时maxMessageSize为0
+// 协议：size + header + route + seq + code + [max message size]
+func DecodeHandshakeResWithLimit(data []byte) (code uint16, maxMessageSize uint32, err error) {
+	if len(data) != handshakeResBytes && len(data) != handshakeResBytes+b32 {
 		err = errors.ErrInvalidMessage
 		return
 	}
 
+	if err = checkRoute(data, route.Handshake); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
-	if _, err = reader.Seek(-defaultCodeBytes, io.SeekEnd); err != nil {
+	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes+defaultSeqBytes, io.SeekStart); err != nil {
 		return
 	}
 
@@ -89,5 +130,64 @@ func DecodeHandshakeRes(data []byte) (code uint16, err error) {
 		return
 	}
 
+	if data[defaultSizeBytes]&extBit == 0 || len(data) == handshakeResBytes {
+		return
+	}
+
+	if maxMessageSize, err = reader.ReadUint32(binary.BigEndian); err != nil {
+		return
+	}
+
 	return
 }
+
+// EncodeLimitReq 编码最大消息字节数通告，通告无需响应，序列号固定为0
+// 客户端仅在服务端于握手响应中通告过最大消息字节数后才发送，避免旧版本服务端收到无法识别的消息
+// 协议：size + header + route + seq + max message size
+func EncodeLimitReq(maxMessageSize uint32) buffer.Buffer {
+	buf := buffer.NewNocopyBuffer()
+	writer := buf.Malloc(limitReqBytes)
+	writer.WriteUint32s(binary.BigEndian, uint32(limitReqBytes-defaultSizeBytes))
+	writer.WriteUint8s(dataBit)
+	writer.WriteUint8s(route.Limit)
+	writer.WriteUint64s(binary.BigEndian, 0)
+	writer.WriteUint32s(binary.BigEndian, maxMessageSize)
+
+	return buf
+}
+
+// DecodeLimitReq 解码最大消息字节数通告
+// 协议：size + header + route + seq + max message size
+func DecodeLimitReq(data []byte) (maxMessageSize uint32, err error) {
+	if len(data) != limitReqBytes {
+		err = errors.ErrInvalidMessage
+		return
+	}
+
+	if err = checkRoute(data, route.Limit); err != nil {
+		return
+	}
+
+	reader := buffer.NewReader(data)
+
+	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes+defaultSeqBytes, io.SeekStart); err != nil {
+		return
+	}
+
+	maxMessageSize, err = reader.ReadUint32(binary.BigEndian)
+
+	return
+}
+
+// CheckMessageSize 检测待发送的消息是否超出对端通告的最大消息字节数，对端未通告时以MaxMessageBytes为限
+func CheckMessageSize(buf buffer.Buffer, maxMessageSize uint32) error {
+	if maxMessageSize == 0 || maxMessageSize > MaxMessageBytes {
+		maxMessageSize = MaxMessageBytes
+	}
+
+	if buf.Len() > int(maxMessageSize) {
+		return errors.ErrMessageTooLarge
+	}
+
+	return nil
+}
@@ -2,6 +2,8 @@ package protocol_test
 
 import (
 	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/core/buffer"
+	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/internal/transporter/internal/codes"
 	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
 	"github.com/dobyte/due/v2/utils/xuuid"
@@ -43,3 +45,53 @@ func TestDecodeHandshakeRes(t *testing.T) {
 
 	t.Logf("code: %v", code)
 }
+
+func TestDecodeHandshakeReq_Invalid(t *testing.T) {
+	if _, _, _, err := protocol.DecodeHandshakeReq([]byte{0, 0, 0, 1}); !errors.Is(err, errors.ErrInvalidMessage) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDecodeLimitReq(t *testing.T) {
+	maxMessageSize, err := protocol.DecodeLimitReq(protocol.EncodeLimitReq(1024).Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if maxMessageSize != 1024 {
+		t.Fatalf("unexpected max message size: %d", maxMessageSize)
+	}
+}
+
+func TestDecodeHandshakeResWithLimit(t *testing.T) {
+	code, maxMessageSize, err := protocol.DecodeHandshakeResWithLimit(protocol.EncodeHandshakeResWithLimit(1, codes.OK, 1024).Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code != codes.OK || maxMessageSize != 1024 {
+		t.Fatalf("unexpected handshake: %d %d", code, maxMessageSize)
+	}
+
+	code, err = protocol.DecodeHandshakeRes(protocol.EncodeHandshakeResWithLimit(1, codes.OK, 1024).Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code != codes.OK {
+		t.Fatalf("unexpected code: %d", code)
+	}
+}
+
+func TestCheckMessageSize(t *testing.T) {
+	buf := buffer.NewNocopyBuffer()
+	buf.Mount(make([]byte, 100))
+
+	if err := protocol.CheckMessageSize(buf, 64); !errors.Is(err, errors.ErrMessageTooLarge) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := protocol.CheckMessageSize(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -37,6 +37,10 @@ func DecodeIsOnlineReq(data []byte) (seq uint64, kind session.Kind, target int64
 		return
 	}
 
+	if err = checkRoute(data, route.IsOnline); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
@@ -84,6 +88,10 @@ func DecodeIsOnlineRes(data []byte) (code uint16, isOnline bool, err error) {
 		return
 	}
 
+	if err = checkRoute(data, route.IsOnline); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes+defaultSeqBytes, io.SeekStart); err != nil {
@@ -0,0 +1,102 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"github.com/dobyte/due/v2/core/buffer"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/internal/transporter/internal/route"
+	"io"
+)
+
+const (
+	kickReqBytes = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + b64
+	kickResBytes = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + defaultCodeBytes
+)
+
+// EncodeKickReq 编码踢下线请求，messages为按编解码器区分的踢下线通知消息，可为空
+// 网关按连接协商的编解码器选择对应的通知消息，未协商或无对应消息时使用编解码器名称为空的消息
+// 协议：size + header + route + seq + uid + count + [codec len + codec + message len + <message packet>]...
+func EncodeKickReq(seq uint64, uid int64, messages map[string][]byte) buffer.Buffer {
+	size := kickReqBytes + codecMessagesBytes(messages)
+
+	buf := buffer.NewNocopyBuffer()
+	writer := buf.Malloc(size)
+	writer.WriteUint32s(binary.BigEndian, uint32(size-defaultSizeBytes))
+	writer.WriteUint8s(dataBit)
+	writer.WriteUint8s(route.Kick)
+	writer.WriteUint64s(binary.BigEndian, seq)
+	writer.WriteInt64s(binary.BigEndian, uid)
+	writeCodecMessages(writer, messages)
+
+	return buf
+}
+
+// DecodeKickReq 解码踢下线请求
+// 协议：size + header + route + seq + uid + count + [codec len + codec + message len + <message packet>]...
+func DecodeKickReq(data []byte) (seq uint64, uid int64, messages map[string][]byte, err error) {
+	if len(data) < kickReqBytes+b8 {
+		err = errors.ErrInvalidMessage
+		return
+	}
+
+	if err = checkRoute(data, route.Kick); err != nil {
+		return
+	}
+
+	reader := buffer.NewReader(data)
+
+	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
+		return
+	}
+
+	if seq, err = reader.ReadUint64(binary.BigEndian); err != nil {
+		return
+	}
+
+	if uid, err = reader.ReadInt64(binary.BigEndian); err != nil {
+		return
+	}
+
+	messages, err = readCodecMessages(reader)
+
+	return
+}
+
+// EncodeKickRes 编码踢下线响应
+// 协议：size + header + route + seq + code
+func EncodeKickRes(seq uint64, code uint16) buffer.Buffer {
+	buf := buffer.NewNocopyBuffer()
+	writer := buf.Malloc(kickResBytes)
+	writer.WriteUint32s(binary.BigEndian, uint32(kickResBytes-defaultSizeBytes))
+	writer.WriteUint8s(dataBit)
+	writer.WriteUint8s(route.Kick)
+	writer.WriteUint64s(binary.BigEndian, seq)
+	writer.WriteUint16s(binary.BigEndian, code)
+
+	return buf
+}
+
+// DecodeKickRes 解码踢下线响应
+// 协议：size + header + route + seq + code
+func DecodeKickRes(data []byte) (code uint16, err error) {
+	if len(data) != kickResBytes {
+		err = errors.ErrInvalidMessage
+		return
+	}
+
+	if err = checkRoute(data, route.Kick); err != nil {
+		return
+	}
+
+	reader := buffer.NewReader(data)
+
+	if _, err = reader.Seek(-defaultCodeBytes, io.SeekEnd); err != nil {
+		return
+	}
+
+	if code, err = reader.ReadUint16(binary.BigEndian); err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,37 @@
+package protocol_test
+
+import (
+	"github.com/dobyte/due/v2/internal/transporter/internal/codes"
+	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
+	"testing"
+)
+
+func TestDecodeKickReq(t *testing.T) {
+	buf := protocol.EncodeKickReq(1, 3, map[string][]byte{"": []byte("kicked"), "msgpack": []byte("packed")})
+
+	seq, uid, messages, err := protocol.DecodeKickReq(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if seq != 1 || uid != 3 || len(messages) != 2 || string(messages[""]) != "kicked" || string(messages["msgpack"]) != "packed" {
+		t.Fatalf("unexpected kick request, seq: %d uid: %d messages: %v", seq, uid, messages)
+	}
+
+	if _, _, messages, err = protocol.DecodeKickReq(protocol.EncodeKickReq(2, 3, nil).Bytes()); err != nil || len(messages) != 0 {
+		t.Fatalf("unexpected kick request without message, messages: %v err: %v", messages, err)
+	}
+}
+
+func TestDecodeKickRes(t *testing.T) {
+	buf := protocol.EncodeKickRes(1, codes.NotFoundSession)
+
+	code, err := protocol.DecodeKickRes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code != codes.NotFoundSession {
+		t.Fatalf("expect code %d, got %d", codes.NotFoundSession, code)
+	}
+}
@@ -36,6 +36,10 @@ func EncodeMulticastReq(seq uint64, kind session.Kind, targets []int64, message
 // DecodeMulticastReq 解码组播请求
 // 协议：size + header + route + seq + session kind + count + targets + <message packet>
 func DecodeMulticastReq(data []byte) (seq uint64, kind session.Kind, targets []int64, message []byte, err error) {
+	if err = checkRoute(data, route.Multicast); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
@@ -98,6 +102,10 @@ func DecodeMulticastRes(data []byte) (code uint16, total uint64, err error) {
 		return
 	}
 
+	if err = checkRoute(data, route.Multicast); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes+defaultSeqBytes, io.SeekStart); err != nil {
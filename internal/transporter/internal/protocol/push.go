@@ -2,16 +2,19 @@ package protocol
 
 import (
 	"encoding/binary"
+	"github.com/dobyte/due/v2/cluster"
 	"github.com/dobyte/due/v2/core/buffer"
 	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/internal/transporter/internal/route"
 	"github.com/dobyte/due/v2/session"
 	"io"
+	"time"
 )
 
 const (
-	pushReqBytes = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + b8 + b64
-	pushResBytes = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + defaultCodeBytes
+	pushReqBytes    = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + b8 + b64
+	pushResBytes    = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + defaultCodeBytes
+	pushHeaderBytes = b8 + b32 + b8
 )
 
 // EncodePushReq 编码推送请求
@@ -30,12 +33,48 @@ func EncodePushReq(seq uint64, kind session.Kind, target int64, message buffer.B
 	return buf
 }
 
-// DecodePushReq 解码推送消息
-// 协议：size + header + route + seq + session kind + target + <message packet>
-func DecodePushReq(data []byte) (seq uint64, kind session.Kind, target int64, message []byte, err error) {
+// EncodePushReqWithHeader 编码携带推送头信息的推送请求，推送头信息为空时与EncodePushReq一致
+// 协议：size + header(ext) + route + seq + session kind + target + priority + ttl + type + <message packet>
+func EncodePushReqWithHeader(seq uint64, kind session.Kind, target int64, header *cluster.PushHeader, message buffer.Buffer) buffer.Buffer {
+	if header == nil {
+		return EncodePushReq(seq, kind, target, message)
+	}
+
+	buf := buffer.NewNocopyBuffer()
+	writer := buf.Malloc(pushReqBytes + pushHeaderBytes)
+	writer.WriteUint32s(binary.BigEndian, uint32(pushReqBytes+pushHeaderBytes-defaultSizeBytes+message.Len()))
+	writer.WriteUint8s(dataBit | extBit)
+	writer.WriteUint8s(route.Push)
+	writer.WriteUint64s(binary.BigEndian, seq)
+	writer.WriteUint8s(uint8(kind))
+	writer.WriteInt64s(binary.BigEndian, target)
+	writer.WriteUint8s(header.Priority)
+	writer.WriteUint32s(binary.BigEndian, uint32(header.TTL.Milliseconds()))
+	writer.WriteUint8s(header.Type)
+	buf.Mount(message)
+
+	return buf
+}
+
+// DecodePushReq 解码推送消息，未携带推送头信息时header为nil
+// 协议：size + header + route + seq + session kind + target + [priority + ttl + type] + <message packet>
+func DecodePushReq(data []byte) (seq uint64, kind session.Kind, target int64, header *cluster.PushHeader, message []byte, err error) {
+	if err = checkRoute(data, route.Push); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
-	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
+	if _, err = reader.Seek(defaultSizeBytes, io.SeekStart); err != nil {
+		return
+	}
+
+	var h uint8
+	if h, err = reader.ReadUint8(); err != nil {
+		return
+	}
+
+	if _, err = reader.Seek(defaultRouteBytes, io.SeekCurrent); err != nil {
 		return
 	}
 
@@ -54,7 +93,29 @@ func DecodePushReq(data []byte) (seq uint64, kind session.Kind, target int64, me
 		return
 	}
 
-	message = data[pushReqBytes:]
+	if h&extBit == 0 {
+		message = data[pushReqBytes:]
+		return
+	}
+
+	header = &cluster.PushHeader{}
+
+	if header.Priority, err = reader.ReadUint8(); err != nil {
+		return
+	}
+
+	var ttl uint32
+	if ttl, err = reader.ReadUint32(binary.BigEndian); err != nil {
+		return
+	} else {
+		header.TTL = time.Duration(ttl) * time.Millisecond
+	}
+
+	if header.Type, err = reader.ReadUint8(); err != nil {
+		return
+	}
+
+	message = data[pushReqBytes+pushHeaderBytes:]
 
 	return
 }
@@ -81,6 +142,10 @@ func DecodePushRes(data []byte) (code uint16, err error) {
 		return
 	}
 
+	if err = checkRoute(data, route.Push); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(-defaultCodeBytes, io.SeekEnd); err != nil {
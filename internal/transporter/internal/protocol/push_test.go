@@ -1,12 +1,15 @@
 package protocol_test
 
 import (
+	"bytes"
+	"github.com/dobyte/due/v2/cluster"
 	"github.com/dobyte/due/v2/core/buffer"
 	"github.com/dobyte/due/v2/internal/transporter/internal/codes"
 	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
 	"github.com/dobyte/due/v2/packet"
 	"github.com/dobyte/due/v2/session"
 	"testing"
+	"time"
 )
 
 func TestEncodePushReq(t *testing.T) {
@@ -36,17 +39,59 @@ func TestDecodePushReq(t *testing.T) {
 
 	buf := protocol.EncodePushReq(1, session.User, 3, buffer.NewNocopyBuffer(message))
 
-	seq, kind, target, msg, err := protocol.DecodePushReq(buf.Bytes())
+	seq, kind, target, header, msg, err := protocol.DecodePushReq(buf.Bytes())
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	if header != nil {
+		t.Fatalf("unexpected header: %+v", header)
+	}
+
+	if !bytes.Equal(msg, message) {
+		t.Fatalf("message mismatch, want %v got %v", message, msg)
+	}
+
 	t.Logf("seq: %v", seq)
 	t.Logf("kind: %v", kind)
 	t.Logf("target: %v", target)
 	t.Logf("message: %v", msg)
 }
 
+func TestDecodePushReqWithHeader(t *testing.T) {
+	message, err := packet.PackMessage(&packet.Message{
+		Route:  1,
+		Seq:    2,
+		Buffer: []byte("hello world"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := &cluster.PushHeader{Priority: 1, TTL: 1500 * time.Millisecond, Type: 3}
+
+	buf := protocol.EncodePushReqWithHeader(1, session.User, 3, header, buffer.NewNocopyBuffer(message))
+
+	seq, kind, target, h, msg, err := protocol.DecodePushReq(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if seq != 1 || kind != session.User || target != 3 {
+		t.Fatalf("unexpected fields, seq: %v kind: %v target: %v", seq, kind, target)
+	}
+
+	if h == nil || *h != *header {
+		t.Fatalf("header mismatch, want %+v got %+v", header, h)
+	}
+
+	if !bytes.Equal(msg, message) {
+		t.Fatalf("message mismatch, want %v got %v", message, msg)
+	}
+
+	t.Logf("header: %+v", h)
+}
+
 func TestEncodePushRes(t *testing.T) {
 	buffer := protocol.EncodePushRes(1, codes.OK)
 
@@ -1,53 +1,219 @@
 package protocol
 
 import (
+	"bufio"
 	"encoding/binary"
+	"github.com/dobyte/due/v2/core/buffer"
 	"github.com/dobyte/due/v2/errors"
 	"io"
 	"sync"
 )
 
-var sizePool = sync.Pool{New: func() any {
-	return make([]byte, 4)
+// DefaultReadBufferSize 默认读缓冲区大小
+const DefaultReadBufferSize = 32 * 1024
+
+// 数据消息的消息头字节数：size + header + route + seq
+const headBytes = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes
+
+var headPool = sync.Pool{New: func() any {
+	return make([]byte, headBytes)
 }}
 
-// ReadMessage 读取消息
+// BufferedReader 带缓冲的读取器
+type BufferedReader struct {
+	*bufio.Reader
+	rd io.Reader // 底层读取器
+}
+
+// NewBufferedReader 创建带缓冲的读取器，单次系统调用可读取多帧消息；size小于等于0时直接返回原始读取器
+// ReadMessage每次调用仍只返回一条完整消息，跨帧边界的数据会保留在缓冲区中供下次读取
+func NewBufferedReader(reader io.Reader, size int) io.Reader {
+	if size <= 0 {
+		return reader
+	}
+
+	return &BufferedReader{Reader: bufio.NewReaderSize(reader, size), rd: reader}
+}
+
+// Drain 取出缓冲区中已完整读取但尚未分发的消息，不会从底层读取器读取数据；不完整的消息保留在缓冲区中
+// 连接关闭前调用，可使分发循环在关闭前处理已读取到的消息
+func (r *BufferedReader) Drain() [][]byte {
+	var frames [][]byte
+
+	for {
+		buffered := r.Buffered()
+		if buffered < defaultSizeBytes {
+			return frames
+		}
+
+		head, _ := r.Peek(defaultSizeBytes)
+		n := defaultSizeBytes + int(binary.BigEndian.Uint32(head))
+
+		if n == defaultSizeBytes || n > buffered {
+			return frames
+		}
+
+		frame, _ := r.Peek(n)
+		frames = append(frames, append([]byte(nil), frame...))
+
+		_, _ = r.Discard(n)
+	}
+}
+
+// Clear 丢弃缓冲区中全部未读取的数据，返回丢弃的字节数
+func (r *BufferedReader) Clear() int {
+	n, _ := r.Discard(r.Buffered())
+	return n
+}
+
+// Drain 取出读取器缓冲区中已完整读取但尚未分发的消息，读取器不带缓冲时返回nil
+func Drain(reader io.Reader) [][]byte {
+	if r, ok := reader.(*BufferedReader); ok {
+		return r.Drain()
+	}
+
+	return nil
+}
+
+// ReadMessage 读取消息，心跳消息返回的data为共享的心跳帧，调用方不可修改
 func ReadMessage(reader io.Reader) (isHeartbeat bool, route uint8, seq uint64, data []byte, err error) {
-	buf := sizePool.Get().([]byte)
+	head := headPool.Get().([]byte)
+	defer headPool.Put(head)
 
-	if _, err = io.ReadFull(reader, buf); err != nil {
-		sizePool.Put(buf)
+	size, err := readHead(reader, head)
+	if err != nil {
 		return
 	}
 
-	size := binary.BigEndian.Uint32(buf)
+	// 心跳帧仅包含头信息，直接返回共享的心跳帧，避免为高频的心跳分配内存
+	if head[defaultSizeBytes]&heartbeatBit == heartbeatBit {
+		isHeartbeat = true
+
+		if size == defaultHeaderBytes {
+			data = heartbeat
+			return
+		}
+
+		data = make([]byte, defaultSizeBytes+size)
+		copy(data, head[:defaultSizeBytes+defaultHeaderBytes])
+
+		_, err = io.ReadFull(reader, data[defaultSizeBytes+defaultHeaderBytes:])
 
-	if size == 0 {
-		sizePool.Put(buf)
-		err = errors.ErrInvalidMessage
 		return
 	}
 
 	data = make([]byte, defaultSizeBytes+size)
-	copy(data[:defaultSizeBytes], buf)
+	copy(data, head)
 
-	sizePool.Put(buf)
+	if _, err = io.ReadFull(reader, data[headBytes:]); err != nil {
+		return
+	}
+
+	route = head[defaultSizeBytes+defaultHeaderBytes]
 
-	if _, err = io.ReadFull(reader, data[defaultSizeBytes:]); err != nil {
+	seq = binary.BigEndian.Uint64(head[defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes:])
+
+	return
+}
+
+// 读取消息头，数据消息读取size + header + route + seq，心跳消息仅读取size + header，返回包长度
+// 包长度超出MaxMessageBytes时返回errors.ErrMessageTooLarge，不会按声明的长度读取或分配内存
+func readHead(reader io.Reader, head []byte) (size uint32, err error) {
+	if _, err = io.ReadFull(reader, head[:defaultSizeBytes]); err != nil {
 		return
 	}
 
-	header := data[defaultSizeBytes : defaultSizeBytes+defaultHeaderBytes][0]
+	size = binary.BigEndian.Uint32(head)
 
-	isHeartbeat = header&heartbeatBit == heartbeatBit
+	if size == 0 {
+		err = errors.ErrInvalidMessage
+		return
+	}
+
+	if size > MaxMessageBytes-defaultSizeBytes {
+		err = errors.ErrMessageTooLarge
+		return
+	}
 
-	if isHeartbeat {
+	if _, err = io.ReadFull(reader, head[defaultSizeBytes:defaultSizeBytes+defaultHeaderBytes]); err != nil {
 		return
 	}
 
-	route = data[defaultSizeBytes+defaultHeaderBytes : defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes][0]
+	if head[defaultSizeBytes]&heartbeatBit == heartbeatBit {
+		return
+	}
+
+	if size < headBytes-defaultSizeBytes {
+		err = errors.ErrInvalidMessage
+		return
+	}
 
-	seq = binary.BigEndian.Uint64(data[defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes : defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes+8])
+	_, err = io.ReadFull(reader, head[defaultSizeBytes+defaultHeaderBytes:headBytes])
 
 	return
 }
+
+// IsMore 检测是否为流式响应的非最终帧
+func IsMore(data []byte) bool {
+	if len(data) <= defaultSizeBytes {
+		return false
+	}
+
+	return data[defaultSizeBytes]&moreBit == moreBit
+}
+
+// MarkMore 标记为流式响应的非最终帧，同一序列号的最后一帧无需标记
+func MarkMore(buf buffer.Buffer) buffer.Buffer {
+	buf.Range(func(node *buffer.NocopyNode) bool {
+		if b := node.Bytes(); len(b) > defaultSizeBytes {
+			b[defaultSizeBytes] |= moreBit
+		}
+		return false
+	})
+
+	return buf
+}
+
+// IsPriority 检测是否为高优先级消息，高优先级消息在收发两端均优先于普通消息处理
+func IsPriority(data []byte) bool {
+	if len(data) <= defaultSizeBytes {
+		return false
+	}
+
+	return data[defaultSizeBytes]&heartbeatBit == 0 && data[defaultSizeBytes]&priorityBit == priorityBit
+}
+
+// IsPriorityBuffer 检测Buffer中的消息是否为高优先级消息
+func IsPriorityBuffer(buf buffer.Buffer) (priority bool) {
+	buf.Range(func(node *buffer.NocopyNode) bool {
+		priority = IsPriority(node.Bytes())
+		return false
+	})
+
+	return
+}
+
+// MarkPriority 标记为高优先级消息，适用于踢下线、断开连接等不应排在大量普通消息之后的控制消息
+func MarkPriority(buf buffer.Buffer) buffer.Buffer {
+	buf.Range(func(node *buffer.NocopyNode) bool {
+		if b := node.Bytes(); len(b) > defaultSizeBytes {
+			b[defaultSizeBytes] |= priorityBit
+		}
+		return false
+	})
+
+	return buf
+}
+
+// 校验消息路由号，路由号与期望的消息类型不一致时返回errors.ErrInvalidMessage，避免误路由的消息被按错误的格式解析
+func checkRoute(data []byte, expected uint8) error {
+	if len(data) < defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes {
+		return errors.ErrInvalidMessage
+	}
+
+	if data[defaultSizeBytes+defaultHeaderBytes] != expected {
+		return errors.ErrInvalidMessage
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package protocol_test
+
+import (
+	"bytes"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadMessage_Buffered(t *testing.T) {
+	frames := [][]byte{
+		protocol.EncodeDeliverReq(1, 2, 3, bytes.Repeat([]byte("a"), 40)).Bytes(),
+		protocol.Heartbeat(),
+		protocol.EncodeDeliverReq(4, 5, 6, []byte("hello world")).Bytes(),
+	}
+
+	stream := bytes.Join(frames, nil)
+
+	// 使用最小的缓冲区和单字节读取，确保帧会跨缓冲区边界
+	reader := protocol.NewBufferedReader(iotest.OneByteReader(bytes.NewReader(stream)), 16)
+
+	for i, frame := range frames {
+		_, _, _, data, err := protocol.ReadMessage(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(data, frame) {
+			t.Fatalf("frame %d mismatch", i)
+		}
+	}
+
+	if _, _, _, _, err := protocol.ReadMessage(reader); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+}
+
+func TestBufferedReader_Drain(t *testing.T) {
+	frames := [][]byte{
+		protocol.EncodeDeliverReq(1, 2, 3, []byte("first")).Bytes(),
+		protocol.Heartbeat(),
+		protocol.EncodeDeliverReq(4, 5, 6, []byte("second")).Bytes(),
+	}
+
+	partial := protocol.EncodeDeliverReq(7, 8, 9, []byte("partial")).Bytes()
+
+	stream := append(bytes.Join(frames, nil), partial[:len(partial)-1]...)
+
+	reader := protocol.NewBufferedReader(bytes.NewReader(stream), 1024)
+
+	// 首次读取将全部数据读入缓冲区
+	if _, _, _, data, err := protocol.ReadMessage(reader); err != nil || !bytes.Equal(data, frames[0]) {
+		t.Fatalf("unexpected first frame, err: %v", err)
+	}
+
+	drained := protocol.Drain(reader)
+
+	if len(drained) != 2 || !bytes.Equal(drained[0], frames[1]) || !bytes.Equal(drained[1], frames[2]) {
+		t.Fatalf("unexpected drained frames: %d", len(drained))
+	}
+
+	// 不完整的消息保留在缓冲区中
+	if n := reader.(*protocol.BufferedReader).Clear(); n != len(partial)-1 {
+		t.Fatalf("unexpected cleared bytes: %d", n)
+	}
+
+	if protocol.Drain(bytes.NewReader(stream)) != nil {
+		t.Fatal("unexpected drained frames from unbuffered reader")
+	}
+}
+
+func TestReadMessage_Heartbeat(t *testing.T) {
+	isHeartbeat, _, _, data, err := protocol.ReadMessage(bytes.NewReader(protocol.Heartbeat()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !isHeartbeat || !bytes.Equal(data, protocol.Heartbeat()) {
+		t.Fatal("expected heartbeat frame")
+	}
+
+	// 仅包含头信息但未标记心跳的帧为非法消息
+	if _, _, _, _, err = protocol.ReadMessage(bytes.NewReader([]byte{0, 0, 0, 1, 0})); !errors.Is(err, errors.ErrInvalidMessage) {
+		t.Fatalf("expected invalid message, got %v", err)
+	}
+}
+
+func BenchmarkReadMessage(b *testing.B) {
+	benchmarkReadMessage(b, 0)
+}
+
+func BenchmarkReadMessage_Buffered(b *testing.B) {
+	benchmarkReadMessage(b, protocol.DefaultReadBufferSize)
+}
+
+func benchmarkReadMessage(b *testing.B, size int) {
+	frame := protocol.EncodeDeliverReq(1, 2, 3, bytes.Repeat([]byte("a"), 64)).Bytes()
+	stream := bytes.Repeat(frame, 1024)
+
+	b.SetBytes(int64(len(frame)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var (
+		reads  int
+		reader io.Reader
+	)
+
+	for i := 0; i < b.N; i++ {
+		if i%1024 == 0 {
+			reader = protocol.NewBufferedReader(&countReader{r: bytes.NewReader(stream), n: &reads}, size)
+		}
+
+		if _, _, _, _, err := protocol.ReadMessage(reader); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ReportMetric(float64(reads)/float64(b.N), "reads/op")
+}
+
+// 心跳占多数的流量，每16帧中包含1帧数据消息
+func BenchmarkReadMessage_Heartbeat(b *testing.B) {
+	frames := make([][]byte, 0, 16)
+	for i := 0; i < 15; i++ {
+		frames = append(frames, protocol.Heartbeat())
+	}
+	frames = append(frames, protocol.EncodeDeliverReq(1, 2, 3, bytes.Repeat([]byte("a"), 64)).Bytes())
+
+	stream := bytes.Repeat(bytes.Join(frames, nil), 64)
+	count := len(frames) * 64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var reader io.Reader
+
+	for i := 0; i < b.N; i++ {
+		if i%count == 0 {
+			reader = protocol.NewBufferedReader(bytes.NewReader(stream), protocol.DefaultReadBufferSize)
+		}
+
+		if _, _, _, _, err := protocol.ReadMessage(reader); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// 统计底层读取次数（对应系统调用次数）的读取器
+type countReader struct {
+	r io.Reader
+	n *int
+}
+
+func (r *countReader) Read(p []byte) (int, error) {
+	*r.n++
+	return r.r.Read(p)
+}
+
+func TestMarkMore(t *testing.T) {
+	buf := protocol.EncodeDeliverReq(1, 2, 3, []byte("hello world"))
+
+	if protocol.IsMore(buf.Bytes()) {
+		t.Fatal("unexpected more frame")
+	}
+
+	data := protocol.MarkMore(buf).Bytes()
+
+	if !protocol.IsMore(data) {
+		t.Fatal("expected more frame")
+	}
+
+	seq, _, _, message, err := protocol.DecodeDeliverReq(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if seq != 1 || string(message) != "hello world" {
+		t.Fatalf("unexpected frame, seq: %v message: %v", seq, string(message))
+	}
+}
+
+func TestMarkPriority(t *testing.T) {
+	buf := protocol.EncodeKickReq(1, 2, nil)
+
+	if protocol.IsPriorityBuffer(buf) {
+		t.Fatal("unexpected priority frame")
+	}
+
+	protocol.MarkPriority(buf)
+
+	if !protocol.IsPriorityBuffer(buf) {
+		t.Fatal("expected priority frame")
+	}
+
+	if protocol.IsPriority(protocol.Heartbeat()) {
+		t.Fatal("unexpected priority heartbeat")
+	}
+
+	seq, uid, _, err := protocol.DecodeKickReq(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if seq != 1 || uid != 2 {
+		t.Fatalf("unexpected frame, seq: %v uid: %v", seq, uid)
+	}
+}
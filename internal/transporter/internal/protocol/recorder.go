@@ -0,0 +1,99 @@
+package protocol
+
+import (
+	"bytes"
+	"github.com/dobyte/due/v2/errors"
+	"io"
+	"sync"
+)
+
+// 录制文件格式：magic + version + <frame>...
+// 每一帧均为原始消息包（size + header + ...），size为大端序，帧与帧之间紧密相连
+var recordMagic = []byte{'D', 'R', 'P', 'C'}
+
+const recordVersion uint8 = 1
+
+type Recorder struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewRecorder 创建录制器，创建时会写入文件头
+func NewRecorder(w io.Writer) (*Recorder, error) {
+	header := make([]byte, 0, len(recordMagic)+1)
+	header = append(header, recordMagic...)
+	header = append(header, recordVersion)
+
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &Recorder{w: w}, nil
+}
+
+// Record 录制一帧消息，data为ReadMessage读取到的完整消息包
+func (r *Recorder) Record(data []byte) error {
+	if len(data) < defaultSizeBytes+defaultHeaderBytes {
+		return errors.ErrInvalidMessage
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.w.Write(data)
+
+	return err
+}
+
+type Player struct {
+	r io.Reader
+}
+
+// NewPlayer 创建回放器，创建时会校验文件头
+func NewPlayer(r io.Reader) (*Player, error) {
+	header := make([]byte, len(recordMagic)+1)
+
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(header[:len(recordMagic)], recordMagic) || header[len(recordMagic)] != recordVersion {
+		return nil, errors.ErrInvalidFormat
+	}
+
+	return &Player{r: r}, nil
+}
+
+// Next 读取下一帧消息，与连接读取消息使用相同的解析流程，读取完毕时返回io.EOF
+func (p *Player) Next() (isHeartbeat bool, route uint8, seq uint64, data []byte, err error) {
+	return ReadMessage(p.r)
+}
+
+// Play 依次回放所有帧消息
+func (p *Player) Play(fn func(isHeartbeat bool, route uint8, seq uint64, data []byte) error) error {
+	for {
+		isHeartbeat, route, seq, data, err := p.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+
+		if err = fn(isHeartbeat, route, seq, data); err != nil {
+			return err
+		}
+	}
+}
+
+// WriteTo 将所有帧消息原样写入目标（例如连接至测试节点的连接）
+func (p *Player) WriteTo(w io.Writer) (n int64, err error) {
+	err = p.Play(func(_ bool, _ uint8, _ uint64, data []byte) error {
+		c, err := w.Write(data)
+		n += int64(c)
+		return err
+	})
+
+	return
+}
@@ -0,0 +1,60 @@
+package protocol_test
+
+import (
+	"bytes"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
+	"testing"
+)
+
+func TestRecorder_Play(t *testing.T) {
+	file := &bytes.Buffer{}
+
+	recorder, err := protocol.NewRecorder(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frames := [][]byte{
+		protocol.Heartbeat(),
+		protocol.EncodeDeliverReq(1, 2, 3, []byte("hello world")).Bytes(),
+	}
+
+	for _, frame := range frames {
+		if err = recorder.Record(frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	player, err := protocol.NewPlayer(bytes.NewReader(file.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	i := 0
+
+	err = player.Play(func(isHeartbeat bool, route uint8, seq uint64, data []byte) error {
+		if !bytes.Equal(data, frames[i]) {
+			t.Fatalf("frame %d mismatch", i)
+		}
+
+		t.Logf("isHeartbeat: %v route: %v seq: %v", isHeartbeat, route, seq)
+
+		i++
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if i != len(frames) {
+		t.Fatalf("expected %d frames, got %d", len(frames), i)
+	}
+}
+
+func TestNewPlayer_InvalidFormat(t *testing.T) {
+	if _, err := protocol.NewPlayer(bytes.NewReader([]byte("HTTP/1.1"))); !errors.Is(err, errors.ErrInvalidFormat) {
+		t.Fatalf("expected invalid format, got %v", err)
+	}
+}
@@ -37,6 +37,10 @@ func DecodeStatReq(data []byte) (seq uint64, kind session.Kind, err error) {
 		return
 	}
 
+	if err = checkRoute(data, route.Stat); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
@@ -87,6 +91,10 @@ func DecodeStatRes(data []byte) (code uint16, total uint64, err error) {
 		return
 	}
 
+	if err = checkRoute(data, route.Stat); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes+defaultSeqBytes, io.SeekStart); err != nil {
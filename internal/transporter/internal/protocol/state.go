@@ -37,6 +37,10 @@ func DecodeGetStateReq(data []byte) (seq uint64, err error) {
 		return
 	}
 
+	if err = checkRoute(data, route.GetState); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
@@ -73,6 +77,10 @@ func DecodeGetStateRes(data []byte) (code uint16, state cluster.State, err error
 		return
 	}
 
+	if err = checkRoute(data, route.GetState); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes+defaultSeqBytes, io.SeekStart); err != nil {
@@ -114,6 +122,10 @@ func DecodeSetStateReq(data []byte) (seq uint64, state cluster.State, err error)
 		return
 	}
 
+	if err = checkRoute(data, route.SetState); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
@@ -155,6 +167,10 @@ func DecodeSetStateRes(data []byte) (code uint16, err error) {
 		return
 	}
 
+	if err = checkRoute(data, route.SetState); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(-defaultCodeBytes, io.SeekEnd); err != nil {
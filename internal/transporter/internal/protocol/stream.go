@@ -0,0 +1,49 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// StreamMessage 流式读取的消息，仅读取消息头，消息内容通过Payload按需读取
+// 读取下一帧前必须读完Payload或调用Discard丢弃剩余内容
+type StreamMessage struct {
+	IsHeartbeat bool      // 是否为心跳消息，心跳消息不包含路由号与序列号
+	Route       uint8     // 路由号
+	Seq         uint64    // 序列号
+	Size        int       // 消息内容字节数，不包含消息头
+	Payload     io.Reader // 消息内容
+}
+
+// Discard 丢弃未读取的消息内容，使读取器前进到下一帧
+func (m *StreamMessage) Discard() error {
+	_, err := io.Copy(io.Discard, m.Payload)
+	return err
+}
+
+// ReadMessageStream 流式读取消息，读取消息头后返回，适用于大消息边读取边处理的场景；小消息仍建议使用ReadMessage
+// 包长度与ReadMessage一致以MaxMessageBytes为限，超出时返回errors.ErrMessageTooLarge
+func ReadMessageStream(reader io.Reader) (*StreamMessage, error) {
+	head := headPool.Get().([]byte)
+	defer headPool.Put(head)
+
+	size, err := readHead(reader, head)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &StreamMessage{}
+
+	if head[defaultSizeBytes]&heartbeatBit == heartbeatBit {
+		msg.IsHeartbeat = true
+		msg.Size = int(size) - defaultHeaderBytes
+	} else {
+		msg.Route = head[defaultSizeBytes+defaultHeaderBytes]
+		msg.Seq = binary.BigEndian.Uint64(head[defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes:])
+		msg.Size = int(size) - (headBytes - defaultSizeBytes)
+	}
+
+	msg.Payload = io.LimitReader(reader, int64(msg.Size))
+
+	return msg, nil
+}
@@ -0,0 +1,95 @@
+package protocol_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
+	"io"
+	"testing"
+)
+
+func TestReadMessageStream(t *testing.T) {
+	large := bytes.Repeat([]byte("a"), 4096)
+
+	stream := &bytes.Buffer{}
+	stream.Write(protocol.EncodeDeliverReq(1, 2, 3, large).Bytes())
+	stream.Write(protocol.EncodeDeliverReq(4, 5, 6, []byte("small")).Bytes())
+	stream.Write(protocol.Heartbeat())
+
+	// 分块读取大消息内容
+	msg, err := protocol.ReadMessageStream(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.IsHeartbeat || msg.Seq != 1 || msg.Size != 16+len(large) {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+
+	payload := &bytes.Buffer{}
+	chunk := make([]byte, 1000)
+	for {
+		n, err := msg.Payload.Read(chunk)
+		payload.Write(chunk[:n])
+		if err != nil {
+			break
+		}
+	}
+
+	if !bytes.Equal(payload.Bytes()[16:], large) {
+		t.Fatal("payload mismatch")
+	}
+
+	// 部分读取后丢弃剩余内容，读取器前进到下一帧
+	msg, err = protocol.ReadMessageStream(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.Seq != 4 || msg.Size != 16+len("small") {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+
+	if _, err = msg.Payload.Read(make([]byte, 2)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = msg.Discard(); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err = protocol.ReadMessageStream(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !msg.IsHeartbeat || msg.Size != 0 {
+		t.Fatalf("unexpected heartbeat: %+v", msg)
+	}
+
+	if _, err = protocol.ReadMessageStream(stream); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+}
+
+func TestReadMessageStream_TooLarge(t *testing.T) {
+	head := make([]byte, 4)
+	binary.BigEndian.PutUint32(head, protocol.MaxMessageBytes)
+
+	// 超出最大消息字节数的帧在读取消息头前即被拒绝
+	if _, err := protocol.ReadMessageStream(bytes.NewReader(head)); !errors.Is(err, errors.ErrMessageTooLarge) {
+		t.Fatalf("expected ErrMessageTooLarge, got %v", err)
+	}
+
+	if _, _, _, _, err := protocol.ReadMessage(bytes.NewReader(head)); !errors.Is(err, errors.ErrMessageTooLarge) {
+		t.Fatalf("expected ErrMessageTooLarge, got %v", err)
+	}
+
+	// 数据帧长度不足以容纳路由号与序列号
+	short := []byte{0, 0, 0, 2, 0, 0}
+
+	if _, _, _, _, err := protocol.ReadMessage(bytes.NewReader(short)); !errors.Is(err, errors.ErrInvalidMessage) {
+		t.Fatalf("expected ErrInvalidMessage, got %v", err)
+	}
+}
@@ -46,6 +46,10 @@ func DecodeTriggerReq(data []byte) (seq uint64, event cluster.Event, cid int64,
 		return
 	}
 
+	if err = checkRoute(data, route.Trigger); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
@@ -96,6 +100,10 @@ func DecodeTriggerRes(data []byte) (code uint16, err error) {
 		return
 	}
 
+	if err = checkRoute(data, route.Trigger); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(-defaultCodeBytes, io.SeekEnd); err != nil {
@@ -35,6 +35,10 @@ func DecodeUnbindReq(data []byte) (seq uint64, uid int64, err error) {
 		return
 	}
 
+	if err = checkRoute(data, route.Unbind); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
@@ -74,6 +78,10 @@ func DecodeUnbindRes(data []byte) (code uint16, err error) {
 		return
 	}
 
+	if err = checkRoute(data, route.Unbind); err != nil {
+		return
+	}
+
 	reader := buffer.NewReader(data)
 
 	if _, err = reader.Seek(-defaultCodeBytes, io.SeekEnd); err != nil {
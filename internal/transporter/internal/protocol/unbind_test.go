@@ -1,7 +1,9 @@
 package protocol_test
 
 import (
+	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
+	"github.com/dobyte/due/v2/internal/transporter/internal/route"
 	"testing"
 )
 
@@ -39,3 +41,17 @@ func TestDecodeUnbindRes(t *testing.T) {
 
 	t.Logf("code: %v", code)
 }
+
+func TestDecodeUnbindReq_MisroutedFrame(t *testing.T) {
+	// 与解绑请求等长但路由号不同的消息
+	data := protocol.EncodeUnbindReq(1, 2).Bytes()
+	data[5] = route.Bind
+
+	if _, _, err := protocol.DecodeUnbindReq(data); !errors.Is(err, errors.ErrInvalidMessage) {
+		t.Fatalf("expected invalid message, got: %v", err)
+	}
+
+	if _, err := protocol.DecodeUnbindRes(protocol.EncodeBindRes(1, 2).Bytes()); !errors.Is(err, errors.ErrInvalidMessage) {
+		t.Fatalf("expected invalid message, got: %v", err)
+	}
+}
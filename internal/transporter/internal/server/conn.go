@@ -1,6 +1,7 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"github.com/dobyte/due/v2/cluster"
 	"github.com/dobyte/due/v2/core/buffer"
@@ -9,6 +10,7 @@ import (
 	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
 	"github.com/dobyte/due/v2/log"
 	"github.com/dobyte/due/v2/utils/xtime"
+	"io"
 	"net"
 	"sync"
 	"sync/atomic"
@@ -23,7 +25,12 @@ type Conn struct {
 	conn              net.Conn           // TCP源连接
 	state             int32              // 连接状态
 	chData            chan chData        // 消息处理通道
+	chHighData        chan chData        // 高优先级消息处理通道
 	lastHeartbeatTime int64              // 上次心跳时间
+	peerLimit         atomic.Uint32      // 对端通告的最大消息字节数，未握手或对端未通告时为0
+	draining          atomic.Bool        // 是否正在排空未分发的消息
+	chDrain           chan [][]byte      // 读缓冲区中未分发的消息
+	done              chan struct{}      // 连接彻底关闭
 	InsKind           cluster.Kind       // 集群类型
 	InsID             string             // 集群ID
 }
@@ -35,6 +42,9 @@ func newConn(server *Server, conn net.Conn) *Conn {
 	c.server = server
 	c.state = def.ConnOpened
 	c.chData = make(chan chData, 10240)
+	c.chHighData = make(chan chData, 1024)
+	c.chDrain = make(chan [][]byte, 2)
+	c.done = make(chan struct{})
 	c.lastHeartbeatTime = xtime.Now().Unix()
 
 	go c.read()
@@ -53,6 +63,11 @@ func (c *Conn) Send(buf buffer.Buffer) (err error) {
 		return err
 	}
 
+	if err = protocol.CheckMessageSize(buf, c.peerLimit.Load()); err != nil {
+		buf.Release()
+		return err
+	}
+
 	buf.Range(func(node *buffer.NocopyNode) bool {
 		if _, err = c.conn.Write(node.Bytes()); err != nil {
 			return false
@@ -65,9 +80,14 @@ func (c *Conn) Send(buf buffer.Buffer) (err error) {
 	return
 }
 
-// 检测连接状态
+// SendMore 发送流式响应的非最终帧，同一序列号的最后一帧需通过Send发送
+func (c *Conn) SendMore(buf buffer.Buffer) error {
+	return c.Send(protocol.MarkMore(buf))
+}
+
+// 检测连接状态，排空期间仍允许写入
 func (c *Conn) checkState() error {
-	if atomic.LoadInt32(&c.state) == def.ConnClosed {
+	if atomic.LoadInt32(&c.state) == def.ConnClosed && !c.draining.Load() {
 		return errors.ErrConnectionClosed
 	} else {
 		return nil
@@ -83,55 +103,106 @@ func (c *Conn) close(isNeedRecycle ...bool) error {
 	c.rw.Lock()
 	defer c.rw.Unlock()
 
+	if c.server.drain {
+		c.draining.Store(true)
+	}
+
 	c.cancel()
 
 	close(c.chData)
 
+	close(c.chHighData)
+
 	if len(isNeedRecycle) > 0 && isNeedRecycle[0] {
 		c.server.recycle(c.conn)
 	}
 
+	if c.server.drain {
+		// 停止读取，待处理协程排空未分发的消息后再关闭连接
+		return c.conn.SetReadDeadline(time.Now())
+	}
+
+	close(c.done)
+
 	return c.conn.Close()
 }
 
 // 读取消息
 func (c *Conn) read() {
-	conn := c.conn
+	conn := protocol.NewBufferedReader(c.conn, c.server.bufferSize)
+
+	defer c.handoff(conn)
 
 	for {
 		select {
 		case <-c.ctx.Done():
 			return
 		default:
-			isHeartbeat, route, _, data, err := protocol.ReadMessage(conn)
+			// 连接关闭时取消上下文中止阻塞的读取，无需等待底层连接关闭
+			isHeartbeat, route, _, data, err := protocol.ReadMessageContext(c.ctx, conn)
 			if err != nil {
 				_ = c.close(true)
 				return
 			}
 
+			if c.server.recorder != nil {
+				if err = c.server.recorder.Record(data); err != nil {
+					log.Warnf("record message failed: %v", err)
+				}
+			}
+
 			c.rw.RLock()
 
 			if atomic.LoadInt32(&c.state) == def.ConnClosed {
 				c.rw.RUnlock()
+
+				if c.server.drain {
+					c.chDrain <- [][]byte{data}
+				}
+
 				return
 			}
 
-			c.chData <- chData{
+			ch := chData{
 				isHeartbeat: isHeartbeat,
 				route:       route,
 				data:        data,
 			}
 
+			if protocol.IsPriority(data) {
+				c.chHighData <- ch
+			} else {
+				c.chData <- ch
+			}
+
 			c.rw.RUnlock()
 		}
 	}
 }
 
+// 将读缓冲区中已完整读取但尚未分发的消息移交给处理协程，不排空时直接丢弃
+func (c *Conn) handoff(reader io.Reader) {
+	if !c.server.drain {
+		if r, ok := reader.(*protocol.BufferedReader); ok {
+			if n := r.Clear(); n > 0 {
+				log.Debugf("discard %d buffered bytes on connection close", n)
+			}
+		}
+		return
+	}
+
+	c.chDrain <- protocol.Drain(reader)
+
+	close(c.chDrain)
+}
+
 // 处理数据
 func (c *Conn) process() {
 	ticker := time.NewTicker(def.HeartbeatInterval)
 	defer ticker.Stop()
 
+	defer c.drain()
+
 	for {
 		select {
 		case <-c.ctx.Done():
@@ -142,27 +213,93 @@ func (c *Conn) process() {
 				_ = c.close(true)
 				return
 			}
+		case ch, ok := <-c.chHighData:
+			if !ok {
+				return
+			}
+
+			c.handle(ch)
 		case ch, ok := <-c.chData:
 			if !ok {
 				return
 			}
 
-			atomic.StoreInt64(&c.lastHeartbeatTime, xtime.Now().Unix())
+			// 高优先级通道已关闭时，排空模式下仍需处理已取出的消息
+			if !c.handleHigh() && !c.server.drain {
+				return
+			}
 
-			if ch.isHeartbeat {
-				c.heartbeat()
-			} else {
-				handler, ok := c.server.handlers[ch.route]
-				if !ok {
-					continue
-				}
+			c.handle(ch)
+		}
+	}
+}
 
-				if err := handler(c, ch.data); err != nil && !errors.Is(err, errors.ErrNotFoundUserLocation) {
-					log.Warnf("process route %d message failed: %v", ch.route, err)
-				}
+// 处理高优先级通道中的全部消息，通道关闭时返回false
+func (c *Conn) handleHigh() bool {
+	for {
+		select {
+		case ch, ok := <-c.chHighData:
+			if !ok {
+				return false
+			}
+
+			c.handle(ch)
+		default:
+			return true
+		}
+	}
+}
+
+// 处理消息
+func (c *Conn) handle(ch chData) {
+	atomic.StoreInt64(&c.lastHeartbeatTime, xtime.Now().Unix())
+
+	if ch.isHeartbeat {
+		c.heartbeat()
+		return
+	}
+
+	handler, ok := c.server.handlers[ch.route]
+	if !ok {
+		return
+	}
+
+	if err := handler(c, ch.data); err != nil && !errors.Is(err, errors.ErrNotFoundUserLocation) {
+		log.Warnf("process route %d(%s) message failed: %v", ch.route, protocol.Label(false, ch.route), err)
+	}
+}
+
+// 连接关闭后处理通道及读缓冲区中尚未分发的消息，处理完成后关闭连接，不排空时丢弃
+func (c *Conn) drain() {
+	if !c.server.drain {
+		return
+	}
+
+	for ch := range c.chHighData {
+		c.handle(ch)
+	}
+
+	for ch := range c.chData {
+		c.handle(ch)
+	}
+
+	for frames := range c.chDrain {
+		for _, data := range frames {
+			isHeartbeat, route, _, _, err := protocol.ReadMessage(bytes.NewReader(data))
+			if err != nil {
+				continue
 			}
+
+			c.handle(chData{isHeartbeat: isHeartbeat, route: route, data: data})
 		}
 	}
+
+	c.rw.Lock()
+	c.draining.Store(false)
+	_ = c.conn.Close()
+	c.rw.Unlock()
+
+	close(c.done)
 }
 
 // 响应心跳消息
@@ -174,3 +311,10 @@ func (c *Conn) heartbeat() {
 		log.Warnf("write heartbeat message error: %v", err)
 	}
 }
+
+// 下发关闭通知
+func (c *Conn) notifyClose(reason cluster.CloseReason) {
+	if err := c.Send(protocol.EncodeCloseReq(uint16(reason))); err != nil && !errors.Is(err, errors.ErrConnectionClosed) {
+		log.Warnf("write close message error: %v", err)
+	}
+}
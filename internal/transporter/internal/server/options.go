@@ -1,5 +1,10 @@
 package server
 
+import "github.com/dobyte/due/v2/internal/transporter/internal/protocol"
+
 type Options struct {
-	Addr string // 监听地址
+	Addr           string             // 监听地址
+	Recorder       *protocol.Recorder // 消息录制器，用于调试时录制连接上读取到的原始消息
+	ReadBufferSize int                // 读缓冲区大小，小于等于0时不使用缓冲读取
+	DrainOnClose   bool               // 连接关闭时是否在关闭写入前处理已读取但尚未分发的消息，为false时直接丢弃
 }
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"github.com/dobyte/due/v2/cluster"
 	"github.com/dobyte/due/v2/core/endpoint"
 	xnet "github.com/dobyte/due/v2/core/net"
 	"github.com/dobyte/due/v2/internal/transporter/internal/codes"
@@ -22,6 +23,10 @@ type Server struct {
 	handlers    map[uint8]RouteHandler // 路由处理器
 	rw          sync.RWMutex           // 锁
 	connections map[net.Conn]*Conn     // 连接
+	recorder    *protocol.Recorder     // 消息录制器
+	bufferSize  int                    // 读缓冲区大小
+	drain       bool                   // 连接关闭时是否处理尚未分发的消息
+	stopped     bool                   // 是否已停止
 }
 
 func NewServer(opts *Options) (*Server, error) {
@@ -34,9 +39,13 @@ func NewServer(opts *Options) (*Server, error) {
 	s.listenAddr = listenAddr
 	s.exposeAddr = exposeAddr
 	s.endpoint = endpoint.NewEndpoint(scheme, exposeAddr, false)
+	s.recorder = opts.Recorder
+	s.bufferSize = opts.ReadBufferSize
+	s.drain = opts.DrainOnClose
 	s.connections = make(map[net.Conn]*Conn)
 	s.handlers = make(map[uint8]RouteHandler)
 	s.handlers[route.Handshake] = s.handshake
+	s.handlers[route.Limit] = s.limit
 
 	return s, nil
 }
@@ -73,12 +82,19 @@ func (s *Server) Start() error {
 		return err
 	}
 
+	// 启动前已停止时直接关闭监听器
+	s.rw.Lock()
+	if s.stopped {
+		s.rw.Unlock()
+		return ln.Close()
+	}
 	s.listener = ln
+	s.rw.Unlock()
 
 	var tempDelay time.Duration
 
 	for {
-		conn, err := s.listener.Accept()
+		conn, err := ln.Accept()
 		if err != nil {
 			if e, ok := err.(net.Error); ok && e.Timeout() {
 				if tempDelay == 0 {
@@ -106,19 +122,38 @@ func (s *Server) Start() error {
 	}
 }
 
-// Stop 停止服务器
+// Stop 停止服务器，默认以停服原因下发关闭通知
 func (s *Server) Stop() error {
-	if err := s.listener.Close(); err != nil {
-		return err
+	return s.StopWithReason(cluster.CloseShutdown)
+}
+
+// StopWithReason 停止服务器，关闭连接前向客户端下发携带关闭原因的关闭通知
+func (s *Server) StopWithReason(reason cluster.CloseReason) error {
+	s.rw.Lock()
+	s.stopped = true
+	ln := s.listener
+	s.rw.Unlock()
+
+	if ln != nil {
+		if err := ln.Close(); err != nil {
+			return err
+		}
 	}
 
 	s.rw.Lock()
-	for _, conn := range s.connections {
+	connections := s.connections
+	for _, conn := range connections {
+		conn.notifyClose(reason)
 		_ = conn.close()
 	}
 	s.connections = nil
 	s.rw.Unlock()
 
+	// 等待连接排空未分发的消息
+	for _, conn := range connections {
+		<-conn.done
+	}
+
 	return nil
 }
 
@@ -151,5 +186,17 @@ func (s *Server) handshake(conn *Conn, data []byte) error {
 	conn.InsKind = insKind
 	conn.InsID = insID
 
-	return conn.Send(protocol.EncodeHandshakeRes(seq, codes.ErrorToCode(err)))
+	return conn.Send(protocol.EncodeHandshakeResWithLimit(seq, codes.ErrorToCode(err), protocol.MaxMessageBytes))
+}
+
+// 处理客户端通告的最大消息字节数
+func (s *Server) limit(conn *Conn, data []byte) error {
+	maxMessageSize, err := protocol.DecodeLimitReq(data)
+	if err != nil {
+		return err
+	}
+
+	conn.peerLimit.Store(maxMessageSize)
+
+	return nil
 }
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"github.com/dobyte/due/v2/core/buffer"
+	"github.com/dobyte/due/v2/internal/transporter/internal/codes"
+	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
+	"github.com/dobyte/due/v2/internal/transporter/internal/route"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestServer_DrainOnClose(t *testing.T) {
+	const total = 10
+
+	t.Run("drain", func(t *testing.T) {
+		handled, replies := runDrainServer(t, true, total)
+
+		if handled != total {
+			t.Fatalf("handled = %d, want %d", handled, total)
+		}
+
+		if replies != total {
+			t.Fatalf("replies = %d, want %d", replies, total)
+		}
+	})
+
+	t.Run("discard", func(t *testing.T) {
+		handled, _ := runDrainServer(t, false, total)
+
+		if handled >= total {
+			t.Fatalf("handled = %d, want less than %d", handled, total)
+		}
+	})
+}
+
+// 向服务器连续写入total条请求，在首条请求处理期间停止服务器，返回服务器处理的请求数及客户端收到的响应数
+func runDrainServer(t *testing.T, drain bool, total int) (int, int) {
+	t.Helper()
+
+	s, err := NewServer(&Options{
+		Addr:           "127.0.0.1:0",
+		ReadBufferSize: protocol.DefaultReadBufferSize,
+		DrainOnClose:   drain,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		handled atomic.Int64
+		started = make(chan struct{}, total)
+	)
+
+	s.RegisterHandler(route.Bind, func(conn *Conn, data []byte) error {
+		seq, _, _, err := protocol.DecodeBindReq(data)
+		if err != nil {
+			return err
+		}
+
+		started <- struct{}{}
+		time.Sleep(20 * time.Millisecond)
+		handled.Add(1)
+
+		return conn.Send(protocol.EncodeBindRes(seq, codes.OK))
+	})
+
+	go s.Start()
+
+	var conn net.Conn
+	for i := 0; i < 50; i++ {
+		if conn, err = net.Dial("tcp", s.ListenAddr()); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	reqs := buffer.NewNocopyBuffer()
+	for i := 1; i <= total; i++ {
+		reqs.Mount(protocol.EncodeBindReq(uint64(i), int64(i), int64(i)))
+	}
+
+	if _, err = conn.Write(reqs.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	reqs.Release()
+
+	select {
+	case <-started:
+	case <-time.After(3 * time.Second):
+		t.Fatal("request not handled")
+	}
+
+	if err = s.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	replies := 0
+
+	_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+
+	for {
+		isHeartbeat, r, _, _, err := protocol.ReadMessage(conn)
+		if err != nil {
+			break
+		}
+
+		if !isHeartbeat && r == route.Bind {
+			replies++
+		}
+	}
+
+	return int(handled.Load()), replies
+}
+
+func TestServer_StopBeforeStart(t *testing.T) {
+	s, err := NewServer(&Options{Addr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 启动前停止不应访问尚未创建的监听器
+	if err = s.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+
+	go func() { done <- s.Start() }()
+
+	select {
+	case err = <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("server started after stop")
+	}
+}
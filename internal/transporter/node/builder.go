@@ -2,26 +2,39 @@ package node
 
 import (
 	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/core/id"
 	"github.com/dobyte/due/v2/internal/transporter/internal/client"
+	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
 	"golang.org/x/sync/singleflight"
 	"sync"
+	"time"
 )
 
 type Options struct {
-	InsID   string       // 实例ID
-	InsKind cluster.Kind // 实例类型
+	InsID              string                                        // 实例ID
+	InsKind            cluster.Kind                                  // 实例类型
+	AuditWindow        time.Duration                                 // 序列号审计窗口，大于0时开启审计
+	SeqGenerator       id.Generator                                  // 序列号ID生成器，可设置为id.Snowflake使序列号全局唯一，为空时使用构建器内共享的单调递增序列号
+	CloseReasonHandler func(addr string, reason cluster.CloseReason) // 关闭通知处理器，服务端关闭前下发关闭原因时回调
 }
 
 type Builder struct {
 	sfg     singleflight.Group
 	opts    *Options
+	seq     client.SeqGenerator
 	clients sync.Map
 }
 
 func NewBuilder(opts *Options) *Builder {
-	return &Builder{
-		opts: opts,
+	b := &Builder{opts: opts}
+
+	if opts.SeqGenerator != nil {
+		b.seq = client.NewIDSeqGenerator(opts.SeqGenerator)
+	} else {
+		b.seq = client.NewSeqGenerator()
 	}
+
+	return b
 }
 
 // Build 构建客户端
@@ -32,10 +45,14 @@ func (b *Builder) Build(addr string) (*Client, error) {
 
 	cli, err, _ := b.sfg.Do(addr, func() (interface{}, error) {
 		cli := NewClient(client.NewClient(&client.Options{
-			Addr:         addr,
-			InsID:        b.opts.InsID,
-			InsKind:      b.opts.InsKind,
-			CloseHandler: func() { b.clients.Delete(addr) },
+			Addr:               addr,
+			InsID:              b.opts.InsID,
+			InsKind:            b.opts.InsKind,
+			CloseHandler:       func() { b.clients.Delete(addr) },
+			CloseReasonHandler: b.closeReasonHandler(addr),
+			ReadBufferSize:     protocol.DefaultReadBufferSize,
+			AuditWindow:        b.opts.AuditWindow,
+			SeqGenerator:       b.seq,
 		}))
 
 		b.clients.Store(addr, cli)
@@ -48,3 +65,45 @@ func (b *Builder) Build(addr string) (*Client, error) {
 
 	return cli.(*Client), nil
 }
+
+// Inflight 获取目标地址进行中的调用数，客户端不存在时返回0
+func (b *Builder) Inflight(addr string) int64 {
+	if cli, ok := b.clients.Load(addr); ok {
+		return cli.(*Client).Inflight()
+	}
+
+	return 0
+}
+
+// InflightStats 获取各目标地址进行中的调用数
+func (b *Builder) InflightStats() map[string]int64 {
+	stats := make(map[string]int64)
+
+	b.clients.Range(func(addr, cli any) bool {
+		stats[addr.(string)] = cli.(*Client).Inflight()
+		return true
+	})
+
+	return stats
+}
+
+// AuditStats 获取各目标地址的序列号审计统计
+func (b *Builder) AuditStats() map[string]cluster.AuditStat {
+	stats := make(map[string]cluster.AuditStat)
+
+	b.clients.Range(func(addr, cli any) bool {
+		stats[addr.(string)] = cli.(*Client).AuditStat()
+		return true
+	})
+
+	return stats
+}
+
+// 绑定连接地址的关闭通知处理器，未设置处理器时返回nil，由客户端打印日志
+func (b *Builder) closeReasonHandler(addr string) func(reason cluster.CloseReason) {
+	if b.opts.CloseReasonHandler == nil {
+		return nil
+	}
+
+	return func(reason cluster.CloseReason) { b.opts.CloseReasonHandler(addr, reason) }
+}
@@ -19,7 +19,7 @@ func TestBuilder(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err = client.Deliver(context.Background(), 1, 2, []byte("hello world"))
+	err = client.Deliver(context.Background(), 1, 2, "", []byte("hello world"))
 	if err != nil {
 		t.Fatal(err)
 	}
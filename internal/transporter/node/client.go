@@ -3,14 +3,15 @@ package node
 import (
 	"context"
 	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/internal/transporter/internal/client"
 	"github.com/dobyte/due/v2/internal/transporter/internal/codes"
 	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
-	"sync/atomic"
+	"math"
+	"time"
 )
 
 type Client struct {
-	seq uint64
 	cli *client.Client
 }
 
@@ -20,14 +21,48 @@ func NewClient(cli *client.Client) *Client {
 	}
 }
 
+// Inflight 获取进行中的调用数
+func (c *Client) Inflight() int64 {
+	return c.cli.Inflight()
+}
+
+// AuditStat 获取序列号审计统计，未开启审计时返回空统计
+func (c *Client) AuditStat() cluster.AuditStat {
+	return c.cli.AuditStat()
+}
+
+// NextSeq 生成序列号，用于编码批量调用的请求
+func (c *Client) NextSeq() uint64 {
+	return c.doGenSequence()
+}
+
+// BatchCall 批量调用，在同一连接上流水线发送全部请求，随后按序列号收集响应
+// 请求需使用NextSeq生成的序列号编码，响应与请求一一对应，单个请求的错误记录在对应响应中
+func (c *Client) BatchCall(ctx context.Context, reqs []client.Request) ([]client.Response, error) {
+	return c.cli.BatchCall(ctx, reqs)
+}
+
 // Trigger 触发事件
 func (c *Client) Trigger(ctx context.Context, event cluster.Event, cid, uid int64) error {
 	return c.cli.Send(ctx, protocol.EncodeTriggerReq(0, event, cid, uid))
 }
 
-// Deliver 投递消息
-func (c *Client) Deliver(ctx context.Context, cid, uid int64, message []byte) error {
-	return c.cli.Send(ctx, protocol.EncodeDeliverReq(0, cid, uid, message), cid)
+// Deliver 投递消息，codec为客户端连接协商的编解码器，为空时使用节点默认的编解码器
+func (c *Client) Deliver(ctx context.Context, cid, uid int64, codec string, message []byte) error {
+	if len(codec) > math.MaxUint8 {
+		return errors.ErrInvalidArgument
+	}
+
+	maxBytes := protocol.MaxDeliverMessageBytes
+	if codec != "" {
+		maxBytes -= 1 + len(codec)
+	}
+
+	if len(message) > maxBytes {
+		return errors.ErrMessageTooLarge
+	}
+
+	return c.cli.Send(ctx, protocol.EncodeDeliverReqWithCodec(0, cid, uid, codec, message), cid)
 }
 
 // GetState 获取状态
@@ -53,7 +88,7 @@ func (c *Client) GetState(ctx context.Context) (cluster.State, error) {
 func (c *Client) SetState(ctx context.Context, state cluster.State) error {
 	seq := c.doGenSequence()
 
-	buf := protocol.EncodeSetStateReq(seq, state)
+	buf := protocol.MarkPriority(protocol.EncodeSetStateReq(seq, state))
 
 	res, err := c.cli.Call(ctx, seq, buf)
 	if err != nil {
@@ -68,11 +103,41 @@ func (c *Client) SetState(ctx context.Context, state cluster.State) error {
 	return codes.CodeToError(code)
 }
 
-// 生成序列号，规避生成序列号为0的编号
-func (c *Client) doGenSequence() (seq uint64) {
-	for {
-		if seq = atomic.AddUint64(&c.seq, 1); seq != 0 {
-			return
+// Drain 排空节点，阻塞至节点上绑定的用户全部迁移完成或超时，返回未完成迁移的用户数
+// 排空耗时可能超过默认的调用超时时间，因此以流式调用等待响应，由调用方的上下文控制超时；progress不为nil时接收节点下发的剩余用户数
+func (c *Client) Drain(ctx context.Context, timeout time.Duration, progress func(remaining int)) (int, error) {
+	seq := c.doGenSequence()
+
+	buf := protocol.MarkPriority(protocol.EncodeDrainReq(seq, timeout))
+
+	ch, err := c.cli.Stream(ctx, seq, buf)
+	if err != nil {
+		return 0, err
+	}
+
+	for res := range ch {
+		code, remaining, err := protocol.DecodeDrainRes(res)
+		if err != nil {
+			return 0, err
+		}
+
+		if !protocol.IsMore(res) {
+			return int(remaining), codes.CodeToError(code)
 		}
+
+		if progress != nil {
+			progress(int(remaining))
+		}
+	}
+
+	if err = ctx.Err(); err == nil {
+		err = errors.ErrClientClosed
 	}
+
+	return 0, err
+}
+
+// 生成序列号
+func (c *Client) doGenSequence() uint64 {
+	return c.cli.NextSeq()
 }
@@ -1,6 +1,12 @@
 package node_test
 
 import (
+	"context"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/internal/transporter/internal/client"
+	"github.com/dobyte/due/v2/internal/transporter/internal/codes"
+	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
+	"github.com/dobyte/due/v2/internal/transporter/node"
 	"math"
 	"sync/atomic"
 	"testing"
@@ -14,3 +20,49 @@ func TestNewClient(t *testing.T) {
 	t.Log(atomic.AddUint64(&idx, 1))
 	t.Log(atomic.AddUint64(&idx, 1))
 }
+
+func TestClient_BatchCall(t *testing.T) {
+	server, err := node.NewServer(&node.ServerOptions{Addr: "127.0.0.1:0"}, &provider{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go server.Start()
+
+	cli, err := node.NewBuilder(&node.Options{InsID: "gate-1", InsKind: cluster.Gate}).Build(server.ListenAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer server.Stop()
+
+	reqs := make([]client.Request, 10)
+	for i := range reqs {
+		seq := cli.NextSeq()
+		reqs[i] = client.Request{Seq: seq, Buf: protocol.EncodeGetStateReq(seq)}
+	}
+
+	resps, err := cli.BatchCall(context.Background(), reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resps) != len(reqs) {
+		t.Fatalf("responses = %d, want %d", len(resps), len(reqs))
+	}
+
+	for i, resp := range resps {
+		if resp.Err != nil {
+			t.Fatalf("request %d: %v", i, resp.Err)
+		}
+
+		code, state, err := protocol.DecodeGetStateRes(resp.Data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if code != codes.OK || state != cluster.Work {
+			t.Fatalf("request %d: code = %d state = %v", i, code, state)
+		}
+	}
+}
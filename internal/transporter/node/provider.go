@@ -3,15 +3,18 @@ package node
 import (
 	"context"
 	"github.com/dobyte/due/v2/cluster"
+	"time"
 )
 
 type Provider interface {
 	// Trigger 触发事件
 	Trigger(ctx context.Context, gid string, cid, uid int64, event cluster.Event) error
-	// Deliver 投递消息
-	Deliver(ctx context.Context, gid, nid string, cid, uid int64, message []byte) error
+	// Deliver 投递消息，codec为客户端连接协商的编解码器，未协商时为空
+	Deliver(ctx context.Context, gid, nid string, cid, uid int64, codec string, message []byte) error
 	// GetState 获取状态
 	GetState() (cluster.State, error)
 	// SetState 设置状态
 	SetState(state cluster.State) error
+	// Drain 排空节点，阻塞至绑定到节点的用户全部迁移完成或超时，返回未完成迁移的用户数；排空期间剩余用户数变化时调用progress
+	Drain(timeout time.Duration, progress func(remaining int)) (int, error)
 }
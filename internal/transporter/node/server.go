@@ -8,6 +8,7 @@ import (
 	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
 	"github.com/dobyte/due/v2/internal/transporter/internal/route"
 	"github.com/dobyte/due/v2/internal/transporter/internal/server"
+	"io"
 )
 
 type Server struct {
@@ -15,8 +16,30 @@ type Server struct {
 	provider Provider
 }
 
-func NewServer(addr string, provider Provider) (*Server, error) {
-	serv, err := server.NewServer(&server.Options{Addr: addr})
+type ServerOptions struct {
+	Addr         string    // 监听地址
+	DrainOnClose bool      // 连接关闭时是否在关闭写入前处理已读取但尚未分发的消息，为false时直接丢弃
+	RecordWriter io.Writer // 消息录制输出，不为nil时录制连接上读取到的原始消息，用于调试时回放
+}
+
+func NewServer(opts *ServerOptions, provider Provider) (*Server, error) {
+	var recorder *protocol.Recorder
+
+	if opts.RecordWriter != nil {
+		r, err := protocol.NewRecorder(opts.RecordWriter)
+		if err != nil {
+			return nil, err
+		}
+
+		recorder = r
+	}
+
+	serv, err := server.NewServer(&server.Options{
+		Addr:           opts.Addr,
+		Recorder:       recorder,
+		ReadBufferSize: protocol.DefaultReadBufferSize,
+		DrainOnClose:   opts.DrainOnClose,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +55,7 @@ func (s *Server) init() {
 	s.RegisterHandler(route.Deliver, s.deliver)
 	s.RegisterHandler(route.GetState, s.getState)
 	s.RegisterHandler(route.SetState, s.setState)
+	s.RegisterHandler(route.Drain, s.drain)
 }
 
 // 触发事件
@@ -58,7 +82,7 @@ func (s *Server) trigger(conn *server.Conn, data []byte) error {
 
 // 投递消息
 func (s *Server) deliver(conn *server.Conn, data []byte) error {
-	seq, cid, uid, message, err := protocol.DecodeDeliverReq(data)
+	seq, cid, uid, codec, message, err := protocol.DecodeDeliverReqWithCodec(data)
 	if err != nil {
 		return err
 	}
@@ -77,7 +101,7 @@ func (s *Server) deliver(conn *server.Conn, data []byte) error {
 		return errors.ErrIllegalRequest
 	}
 
-	if err = s.provider.Deliver(context.Background(), gid, nid, cid, uid, message); seq == 0 {
+	if err = s.provider.Deliver(context.Background(), gid, nid, cid, uid, codec, message); seq == 0 {
 		return err
 	} else {
 		return conn.Send(protocol.EncodeDeliverRes(seq, codes.ErrorToCode(err)))
@@ -107,3 +131,22 @@ func (s *Server) setState(conn *server.Conn, data []byte) error {
 
 	return conn.Send(protocol.EncodeSetStateRes(seq, codes.ErrorToCode(err)))
 }
+
+// 排空节点，排空过程耗时较长，需异步执行以免阻塞连接上的其他请求
+func (s *Server) drain(conn *server.Conn, data []byte) error {
+	seq, timeout, err := protocol.DecodeDrainReq(data)
+	if err != nil {
+		return err
+	}
+
+	// 排空期间以流式响应的非最终帧下发进度，最终帧携带排空结果
+	go func() {
+		remaining, err := s.provider.Drain(timeout, func(remaining int) {
+			_ = conn.SendMore(protocol.EncodeDrainRes(seq, codes.OK, uint64(remaining)))
+		})
+
+		_ = conn.Send(protocol.EncodeDrainRes(seq, codes.ErrorToCode(err), uint64(remaining)))
+	}()
+
+	return nil
+}
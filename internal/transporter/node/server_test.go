@@ -1,17 +1,21 @@
 package node_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/internal/transporter/internal/protocol"
+	"github.com/dobyte/due/v2/internal/transporter/internal/route"
 	"github.com/dobyte/due/v2/internal/transporter/node"
 	"github.com/dobyte/due/v2/log"
+	"sync"
 	"testing"
 	"time"
 )
 
 func TestServer(t *testing.T) {
-	server, err := node.NewServer(":49898", &provider{})
+	server, err := node.NewServer(&node.ServerOptions{Addr: ":49898"}, &provider{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -23,6 +27,108 @@ func TestServer(t *testing.T) {
 	<-time.After(20 * time.Second)
 }
 
+func TestServer_Record(t *testing.T) {
+	w := &recordWriter{}
+
+	server, err := node.NewServer(&node.ServerOptions{Addr: "127.0.0.1:0", RecordWriter: w}, &provider{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go server.Start()
+
+	client, err := node.NewBuilder(&node.Options{InsID: "gate-1", InsKind: cluster.Gate}).Build(server.ListenAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = client.Deliver(context.Background(), 1, 2, "", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	defer server.Stop()
+
+	// 投递为单向消息，等待服务器读取并录制
+	var message []byte
+
+	for deadline := time.Now().Add(3 * time.Second); message == nil && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+
+		player, err := protocol.NewPlayer(bytes.NewReader(w.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = player.Play(func(isHeartbeat bool, r uint8, seq uint64, data []byte) error {
+			if !isHeartbeat && r == route.Deliver {
+				_, _, _, message, err = protocol.DecodeDeliverReq(data)
+			}
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if string(message) != "hello" {
+		t.Fatalf("recorded deliver message = %q, want %q", message, "hello")
+	}
+}
+
+func TestServer_DrainProgress(t *testing.T) {
+	server, err := node.NewServer(&node.ServerOptions{Addr: "127.0.0.1:0"}, &provider{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go server.Start()
+	defer server.Stop()
+
+	client, err := node.NewBuilder(&node.Options{InsID: "master-1", InsKind: cluster.Master}).Build(server.ListenAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var progress []int
+
+	remaining, err := client.Drain(ctx, time.Second, func(remaining int) {
+		progress = append(progress, remaining)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if remaining != 0 {
+		t.Fatalf("remaining = %d, want 0", remaining)
+	}
+
+	if len(progress) != 2 || progress[0] != 2 || progress[1] != 1 {
+		t.Fatalf("progress = %v, want [2 1]", progress)
+	}
+}
+
+// 并发安全的录制输出
+type recordWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *recordWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.Write(p)
+}
+
+func (w *recordWriter) Bytes() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]byte(nil), w.buf.Bytes()...)
+}
+
 type provider struct {
 }
 
@@ -32,7 +138,7 @@ func (p *provider) Trigger(ctx context.Context, gid string, cid, uid int64, even
 }
 
 // Deliver 投递消息
-func (p *provider) Deliver(ctx context.Context, gid, nid string, cid, uid int64, message []byte) error {
+func (p *provider) Deliver(ctx context.Context, gid, nid string, cid, uid int64, codec string, message []byte) error {
 	log.Infof("gid: %s, nid: %s, cid: %d, uid: %d message: %s", gid, nid, cid, uid, string(message))
 	return nil
 }
@@ -63,3 +169,11 @@ func (p *provider) GetState() (cluster.State, error) {
 func (p *provider) SetState(state cluster.State) error {
 	return nil
 }
+
+// Drain 排空节点，依次下发剩余用户数2、1后完成排空
+func (p *provider) Drain(timeout time.Duration, progress func(remaining int)) (int, error) {
+	progress(2)
+	progress(1)
+
+	return 0, nil
+}
@@ -30,6 +30,16 @@ type Locator interface {
 	LocateNode(ctx context.Context, uid int64, name string) (string, error)
 }
 
+// Presence 在线状态，定位器可选实现
+type Presence interface {
+	// Refresh 刷新用户在线状态
+	Refresh(ctx context.Context, uids ...int64) error
+	// Locate 定位用户所在网关和节点
+	Locate(ctx context.Context, uid int64, name string) (gid string, nid string, ok bool, err error)
+	// CountOnline 统计在线用户数
+	CountOnline(ctx context.Context) (int64, error)
+}
+
 type Watcher interface {
 	// Next 返回用户位置列表
 	Next() ([]*Event, error)
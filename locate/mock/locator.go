@@ -0,0 +1,142 @@
+package mock
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/locate"
+	"sync"
+)
+
+const name = "mock"
+
+var _ locate.Locator = &Locator{}
+
+// Locator 内存定位器，用于单元测试
+// 用户位置变化时同步投递至所有监听对应实例类型的监听器，测试中无需等待
+type Locator struct {
+	rw       sync.RWMutex
+	gates    map[int64]string
+	nodes    map[int64]map[string]string
+	watchers map[*watcher]struct{}
+}
+
+func NewLocator() *Locator {
+	return &Locator{
+		gates:    make(map[int64]string),
+		nodes:    make(map[int64]map[string]string),
+		watchers: make(map[*watcher]struct{}),
+	}
+}
+
+// Name 获取定位器组件名
+func (l *Locator) Name() string {
+	return name
+}
+
+// Watch 监听用户定位变化
+// 与真实定位器一致，ctx仅作用于创建监听器，监听器在调用Stop后结束
+func (l *Locator) Watch(ctx context.Context, kinds ...string) (locate.Watcher, error) {
+	l.rw.Lock()
+	defer l.rw.Unlock()
+
+	w := newWatcher(l, kinds...)
+	l.watchers[w] = struct{}{}
+
+	return w, nil
+}
+
+// BindGate 绑定网关
+func (l *Locator) BindGate(ctx context.Context, uid int64, gid string) error {
+	l.rw.Lock()
+	defer l.rw.Unlock()
+
+	l.gates[uid] = gid
+	l.broadcast(&locate.Event{UID: uid, Type: locate.BindGate, InsID: gid, InsKind: cluster.Gate.String()})
+
+	return nil
+}
+
+// BindNode 绑定节点
+func (l *Locator) BindNode(ctx context.Context, uid int64, name, nid string) error {
+	l.rw.Lock()
+	defer l.rw.Unlock()
+
+	nodes, ok := l.nodes[uid]
+	if !ok {
+		nodes = make(map[string]string)
+		l.nodes[uid] = nodes
+	}
+
+	nodes[name] = nid
+	l.broadcast(&locate.Event{UID: uid, Type: locate.BindNode, InsID: nid, InsKind: cluster.Node.String(), InsName: name})
+
+	return nil
+}
+
+// UnbindGate 解绑网关，用户已绑定到其他网关时忽略
+func (l *Locator) UnbindGate(ctx context.Context, uid int64, gid string) error {
+	l.rw.Lock()
+	defer l.rw.Unlock()
+
+	if l.gates[uid] != gid {
+		return nil
+	}
+
+	delete(l.gates, uid)
+	l.broadcast(&locate.Event{UID: uid, Type: locate.UnbindGate, InsID: gid, InsKind: cluster.Gate.String()})
+
+	return nil
+}
+
+// UnbindNode 解绑节点，用户已绑定到其他节点时忽略
+func (l *Locator) UnbindNode(ctx context.Context, uid int64, name string, nid string) error {
+	l.rw.Lock()
+	defer l.rw.Unlock()
+
+	if l.nodes[uid][name] != nid {
+		return nil
+	}
+
+	delete(l.nodes[uid], name)
+
+	if len(l.nodes[uid]) == 0 {
+		delete(l.nodes, uid)
+	}
+
+	l.broadcast(&locate.Event{UID: uid, Type: locate.UnbindNode, InsID: nid, InsKind: cluster.Node.String(), InsName: name})
+
+	return nil
+}
+
+// LocateGate 定位用户所在网关
+func (l *Locator) LocateGate(ctx context.Context, uid int64) (string, error) {
+	l.rw.RLock()
+	defer l.rw.RUnlock()
+
+	return l.gates[uid], nil
+}
+
+// LocateNode 定位用户所在节点
+func (l *Locator) LocateNode(ctx context.Context, uid int64, name string) (string, error) {
+	l.rw.RLock()
+	defer l.rw.RUnlock()
+
+	return l.nodes[uid][name], nil
+}
+
+// 回收监听器
+func (l *Locator) recycle(w *watcher) {
+	l.rw.Lock()
+	defer l.rw.Unlock()
+
+	delete(l.watchers, w)
+}
+
+// 向监听对应实例类型的监听器投递事件，调用方需持有写锁
+func (l *Locator) broadcast(event *locate.Event) {
+	for w := range l.watchers {
+		if w.match(event.InsKind) {
+			w.push(event)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package mock_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/locate"
+	"github.com/dobyte/due/v2/locate/mock"
+	"testing"
+)
+
+func TestLocator_Locate(t *testing.T) {
+	ctx := context.Background()
+	locator := mock.NewLocator()
+
+	_ = locator.BindGate(ctx, 1, "gate-1")
+	_ = locator.BindNode(ctx, 1, "game", "node-1")
+
+	if gid, _ := locator.LocateGate(ctx, 1); gid != "gate-1" {
+		t.Fatalf("unexpected gate: %s", gid)
+	}
+
+	if nid, _ := locator.LocateNode(ctx, 1, "game"); nid != "node-1" {
+		t.Fatalf("unexpected node: %s", nid)
+	}
+
+	// 用户已绑定到其他实例时忽略解绑
+	_ = locator.UnbindGate(ctx, 1, "gate-2")
+	_ = locator.UnbindNode(ctx, 1, "game", "node-2")
+
+	if gid, _ := locator.LocateGate(ctx, 1); gid != "gate-1" {
+		t.Fatalf("unexpected gate: %s", gid)
+	}
+
+	if nid, _ := locator.LocateNode(ctx, 1, "game"); nid != "node-1" {
+		t.Fatalf("unexpected node: %s", nid)
+	}
+
+	_ = locator.UnbindGate(ctx, 1, "gate-1")
+	_ = locator.UnbindNode(ctx, 1, "game", "node-1")
+
+	if gid, _ := locator.LocateGate(ctx, 1); gid != "" {
+		t.Fatalf("unexpected gate: %s", gid)
+	}
+
+	if nid, _ := locator.LocateNode(ctx, 1, "game"); nid != "" {
+		t.Fatalf("unexpected node: %s", nid)
+	}
+}
+
+func TestLocator_Watch(t *testing.T) {
+	locator := mock.NewLocator()
+
+	// 创建监听器的上下文取消后监听器仍然有效
+	ctx, cancel := context.WithCancel(context.Background())
+	watcher, err := locator.Watch(ctx, cluster.Node.String())
+	cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx = context.Background()
+
+	_ = locator.BindGate(ctx, 1, "gate-1")
+	_ = locator.BindNode(ctx, 1, "game", "node-1")
+	_ = locator.UnbindNode(ctx, 1, "game", "node-1")
+
+	// 仅投递监听的实例类型的事件
+	if events, err := watcher.Next(); err != nil || len(events) != 1 || events[0].Type != locate.BindNode || events[0].InsName != "game" {
+		t.Fatalf("unexpected bind events: %v, %v", events, err)
+	}
+
+	if events, err := watcher.Next(); err != nil || len(events) != 1 || events[0].Type != locate.UnbindNode || events[0].InsID != "node-1" {
+		t.Fatalf("unexpected unbind events: %v, %v", events, err)
+	}
+
+	if err = watcher.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = watcher.Next(); err == nil {
+		t.Fatal("expected error after stop")
+	}
+}
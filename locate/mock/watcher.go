@@ -0,0 +1,93 @@
+package mock
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/locate"
+	"sync"
+)
+
+var _ locate.Watcher = &watcher{}
+
+type watcher struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	locator  *Locator
+	kinds    map[string]struct{}
+	mu       sync.Mutex
+	queue    [][]*locate.Event
+	chNotify chan struct{}
+}
+
+func newWatcher(l *Locator, kinds ...string) *watcher {
+	w := &watcher{}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	w.locator = l
+	w.kinds = make(map[string]struct{}, len(kinds))
+	w.chNotify = make(chan struct{}, 1)
+
+	for _, kind := range kinds {
+		w.kinds[kind] = struct{}{}
+	}
+
+	return w
+}
+
+// 是否监听该实例类型
+func (w *watcher) match(kind string) bool {
+	_, ok := w.kinds[kind]
+	return ok
+}
+
+// 投递事件，事件在投递时即已入队，不会阻塞绑定流程
+func (w *watcher) push(event *locate.Event) {
+	w.mu.Lock()
+	w.queue = append(w.queue, []*locate.Event{event})
+	w.mu.Unlock()
+
+	select {
+	case w.chNotify <- struct{}{}:
+	default:
+	}
+}
+
+// 弹出最早的事件
+func (w *watcher) pop() ([]*locate.Event, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.queue) == 0 {
+		return nil, false
+	}
+
+	events := w.queue[0]
+	w.queue[0] = nil
+	w.queue = w.queue[1:]
+
+	return events, true
+}
+
+// Next 返回用户位置列表
+func (w *watcher) Next() ([]*locate.Event, error) {
+	for {
+		if err := w.ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if events, ok := w.pop(); ok {
+			return events, nil
+		}
+
+		select {
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		case <-w.chNotify:
+		}
+	}
+}
+
+// Stop 停止监听
+func (w *watcher) Stop() error {
+	w.cancel()
+	w.locator.recycle(w)
+	return nil
+}
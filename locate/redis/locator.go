@@ -18,6 +18,7 @@ import (
 const (
 	userGateKey     = "%s:locate:user:%d:gate"     // string
 	userNodeKey     = "%s:locate:user:%d:node"     // hash
+	userOnlineKey   = "%s:locate:user:online"      // sorted set
 	clusterEventKey = "%s:locate:cluster:%s:event" // channel
 )
 
@@ -115,6 +116,10 @@ func (l *Locator) BindGate(ctx context.Context, uid int64, gid string) error {
 		return err
 	}
 
+	if err := l.Refresh(ctx, uid); err != nil {
+		log.Errorf("user presence refresh failed: %v", err)
+	}
+
 	if err := l.broadcast(ctx, locate.BindGate, uid, gid); err != nil {
 		log.Errorf("location event broadcast failed: %v", err)
 	}
@@ -147,6 +152,10 @@ func (l *Locator) UnbindGate(ctx context.Context, uid int64, gid string) error {
 	}
 
 	if rst[0] == "OK" {
+		if err = l.opts.client.ZRem(ctx, fmt.Sprintf(userOnlineKey, l.opts.prefix), uid).Err(); err != nil {
+			log.Errorf("user presence clear failed: %v", err)
+		}
+
 		if err = l.broadcast(ctx, locate.UnbindGate, uid, gid); err != nil {
 			log.Errorf("location event broadcast failed: %v", err)
 		}
@@ -4,22 +4,25 @@ import (
 	"context"
 	"github.com/dobyte/due/v2/etc"
 	"github.com/go-redis/redis/v8"
+	"time"
 )
 
 const (
-	defaultAddr       = "127.0.0.1:6379"
-	defaultDB         = 0
-	defaultMaxRetries = 3
-	defaultPrefix     = "due"
+	defaultAddr        = "127.0.0.1:6379"
+	defaultDB          = 0
+	defaultMaxRetries  = 3
+	defaultPrefix      = "due"
+	defaultPresenceTTL = time.Minute
 )
 
 const (
-	defaultAddrsKey      = "etc.locate.redis.addrs"
-	defaultDBKey         = "etc.locate.redis.db"
-	defaultMaxRetriesKey = "etc.locate.redis.maxRetries"
-	defaultPrefixKey     = "etc.locate.redis.prefix"
-	defaultUsernameKey   = "etc.locate.redis.username"
-	defaultPasswordKey   = "etc.locate.redis.password"
+	defaultAddrsKey       = "etc.locate.redis.addrs"
+	defaultDBKey          = "etc.locate.redis.db"
+	defaultMaxRetriesKey  = "etc.locate.redis.maxRetries"
+	defaultPrefixKey      = "etc.locate.redis.prefix"
+	defaultUsernameKey    = "etc.locate.redis.username"
+	defaultPasswordKey    = "etc.locate.redis.password"
+	defaultPresenceTTLKey = "etc.locate.redis.presenceTTL"
 )
 
 type Option func(o *options)
@@ -54,17 +57,22 @@ type options struct {
 	// 前缀
 	// key前缀，默认为due
 	prefix string
+
+	// 在线状态有效期
+	// 网关需在有效期内刷新用户在线状态，否则视为离线，默认为1min
+	presenceTTL time.Duration
 }
 
 func defaultOptions() *options {
 	return &options{
-		ctx:        context.Background(),
-		addrs:      etc.Get(defaultAddrsKey, []string{defaultAddr}).Strings(),
-		db:         etc.Get(defaultDBKey, defaultDB).Int(),
-		maxRetries: etc.Get(defaultMaxRetriesKey, defaultMaxRetries).Int(),
-		prefix:     etc.Get(defaultPrefixKey, defaultPrefix).String(),
-		username:   etc.Get(defaultUsernameKey).String(),
-		password:   etc.Get(defaultPasswordKey).String(),
+		ctx:         context.Background(),
+		addrs:       etc.Get(defaultAddrsKey, []string{defaultAddr}).Strings(),
+		db:          etc.Get(defaultDBKey, defaultDB).Int(),
+		maxRetries:  etc.Get(defaultMaxRetriesKey, defaultMaxRetries).Int(),
+		prefix:      etc.Get(defaultPrefixKey, defaultPrefix).String(),
+		username:    etc.Get(defaultUsernameKey).String(),
+		password:    etc.Get(defaultPasswordKey).String(),
+		presenceTTL: etc.Get(defaultPresenceTTLKey, defaultPresenceTTL).Duration(),
 	}
 }
 
@@ -107,3 +115,8 @@ func WithClient(client redis.UniversalClient) Option {
 func WithPrefix(prefix string) Option {
 	return func(o *options) { o.prefix = prefix }
 }
+
+// WithPresenceTTL 设置在线状态有效期
+func WithPresenceTTL(ttl time.Duration) Option {
+	return func(o *options) { o.presenceTTL = ttl }
+}
@@ -0,0 +1,73 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/go-redis/redis/v8"
+	"strconv"
+	"time"
+)
+
+// Refresh 刷新用户在线状态
+func (l *Locator) Refresh(ctx context.Context, uids ...int64) error {
+	if l.opts.presenceTTL <= 0 || len(uids) == 0 {
+		return nil
+	}
+
+	score := float64(time.Now().Add(l.opts.presenceTTL).UnixMilli())
+	members := make([]*redis.Z, 0, len(uids))
+	for _, uid := range uids {
+		members = append(members, &redis.Z{Score: score, Member: uid})
+	}
+
+	return l.opts.client.ZAdd(ctx, fmt.Sprintf(userOnlineKey, l.opts.prefix), members...).Err()
+}
+
+// Locate 定位用户所在网关和节点
+func (l *Locator) Locate(ctx context.Context, uid int64, name string) (string, string, bool, error) {
+	var (
+		score   *redis.FloatCmd
+		gateCmd *redis.StringCmd
+		nodeCmd *redis.StringCmd
+	)
+
+	_, err := l.opts.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		score = pipe.ZScore(ctx, fmt.Sprintf(userOnlineKey, l.opts.prefix), strconv.FormatInt(uid, 10))
+		gateCmd = pipe.Get(ctx, fmt.Sprintf(userGateKey, l.opts.prefix, uid))
+		nodeCmd = pipe.HGet(ctx, fmt.Sprintf(userNodeKey, l.opts.prefix, uid), name)
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", "", false, err
+	}
+
+	if l.opts.presenceTTL > 0 {
+		if expire, err := score.Result(); err != nil || int64(expire) < time.Now().UnixMilli() {
+			return "", "", false, nil
+		}
+	}
+
+	gid, nid := gateCmd.Val(), nodeCmd.Val()
+
+	return gid, nid, gid != "", nil
+}
+
+// CountOnline 统计在线用户数
+func (l *Locator) CountOnline(ctx context.Context) (int64, error) {
+	key := fmt.Sprintf(userOnlineKey, l.opts.prefix)
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+
+	var count *redis.IntCmd
+
+	_, err := l.opts.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(ctx, key, "-inf", "("+now)
+		count = pipe.ZCard(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count.Val(), nil
+}
@@ -0,0 +1,55 @@
+package redis_test
+
+import (
+	"context"
+	"github.com/dobyte/due/locate/redis/v2"
+	"github.com/dobyte/due/v2/utils/xuuid"
+	"testing"
+	"time"
+)
+
+func TestLocator_Presence(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		uid     = time.Now().UnixNano()
+		gid     = xuuid.UUID()
+		locator = redis.NewLocator(
+			redis.WithAddrs("127.0.0.1:6379"),
+			redis.WithPresenceTTL(time.Second),
+		)
+	)
+
+	if err := locator.BindGate(ctx, uid, gid); err != nil {
+		t.Fatal(err)
+	}
+	defer locator.UnbindGate(ctx, uid, gid)
+
+	// 未刷新在线状态的用户视为离线
+	if _, _, ok, err := locator.Locate(ctx, uid, "node"); err != nil || ok {
+		t.Fatalf("unexpected locate before refresh, ok: %v err: %v", ok, err)
+	}
+
+	if err := locator.Refresh(ctx, uid); err != nil {
+		t.Fatal(err)
+	}
+
+	if g, _, ok, err := locator.Locate(ctx, uid, "node"); err != nil || !ok || g != gid {
+		t.Fatalf("unexpected locate after refresh, gid: %s ok: %v err: %v", g, ok, err)
+	}
+
+	online, err := locator.CountOnline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if online < 1 {
+		t.Fatalf("unexpected online count: %d", online)
+	}
+
+	// 在线状态过期后视为离线
+	time.Sleep(1100 * time.Millisecond)
+
+	if _, _, ok, err := locator.Locate(ctx, uid, "node"); err != nil || ok {
+		t.Fatalf("unexpected locate after expiration, ok: %v err: %v", ok, err)
+	}
+}
@@ -3,6 +3,7 @@ module github.com/dobyte/due/lock/redis/v2
 go 1.22.9
 
 require (
+	github.com/dobyte/due/redis/v2 v2.0.0
 	github.com/dobyte/due/v2 v2.2.4
 	github.com/go-redis/redis/v8 v8.11.5
 )
@@ -19,6 +20,9 @@ require (
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/jinzhu/copier v0.4.0 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.8 // indirect
+	github.com/lestrrat-go/file-rotatelogs v2.4.0+incompatible // indirect
+	github.com/lestrrat-go/strftime v1.0.6 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
 	github.com/stretchr/testify v1.10.0 // indirect
 	github.com/twitchyliquid64/golang-asm v0.15.1 // indirect
 	golang.org/x/arch v0.11.0 // indirect
@@ -27,3 +31,5 @@ require (
 )
 
 replace github.com/dobyte/due/v2 => ../../
+
+replace github.com/dobyte/due/redis/v2 => ../../redis
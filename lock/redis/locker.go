@@ -2,52 +2,190 @@ package redis
 
 import (
 	"context"
+	"github.com/dobyte/due/v2/core/clock"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/log"
 	"sync"
 	"time"
 )
 
 type Locker struct {
-	maker   *Maker
-	key     string
-	version string
-	rw      sync.RWMutex
-	timer   *time.Timer
+	maker      *Maker
+	key        string
+	version    string
+	rw         sync.RWMutex
+	timer      clock.Timer
+	stopped    bool
+	wg         sync.WaitGroup
+	renewed    time.Time // 上次成功获取或续租的请求发起时间
+	onAcquired func()    // 获取锁回调
+	onLost     func()    // 丢失锁回调
+}
+
+// OnAcquired 设置获取锁回调，通过Acquire或Claim成功持有锁后调用
+func (l *Locker) OnAcquired(fn func()) {
+	l.rw.Lock()
+	l.onAcquired = fn
+	l.rw.Unlock()
+}
+
+// OnLost 设置丢失锁回调，续租时发现锁已被其他持有者占用，或续租持续失败直至锁过期时调用，持有者应据此中止临界区操作
+// 回调为尽力而为的通知，仅在续租时检测，可能滞后于锁的实际丢失；通过Handoff交接锁后，原持有者同样会收到该通知
+func (l *Locker) OnLost(fn func()) {
+	l.rw.Lock()
+	l.onLost = fn
+	l.rw.Unlock()
 }
 
 // Acquire 获取锁
 func (l *Locker) Acquire(ctx context.Context) error {
+	if l.maker.closed.Load() {
+		return errors.ErrClientClosed
+	}
+
+	start := l.maker.opts.clock.Now()
+
 	if err := l.maker.acquire(ctx, l.key, l.version); err != nil {
 		return err
 	}
 
-	l.timer = time.AfterFunc(l.maker.opts.expiration/2, l.renewal)
+	l.hold(start)
 
 	return nil
 }
 
 // TryAcquire 尝试获取锁
 func (l *Locker) TryAcquire(ctx context.Context, expiration ...time.Duration) error {
+	if l.maker.closed.Load() {
+		return errors.ErrClientClosed
+	}
+
 	return l.maker.tryAcquire(ctx, l.key, l.version, expiration...)
 }
 
 // Release 释放锁
 func (l *Locker) Release(ctx context.Context) error {
+	l.stop()
+
+	l.maker.lockers.Delete(l)
+
+	return l.maker.release(ctx, l.key, l.version)
+}
+
+// TTL 获取锁的剩余生存时间，锁未被当前持有者持有时返回errors.ErrLockNotHeld
+// 返回的剩余生存时间已扣除时钟漂移安全余量，扣除后不大于0时同样返回errors.ErrLockNotHeld
+func (l *Locker) TTL(ctx context.Context) (time.Duration, error) {
+	return l.maker.ttl(ctx, l.key, l.version)
+}
+
+// Validity 获取锁在本地时钟下的剩余有效期，锁未持有或已超出有效期时返回0
+// 有效期自获取或续租请求发起时起算，并扣除时钟漂移安全余量，假设本地时钟与Redis时钟的偏差不超过该余量
+func (l *Locker) Validity() time.Duration {
 	l.rw.RLock()
+	defer l.rw.RUnlock()
+
+	if l.stopped || l.renewed.IsZero() {
+		return 0
+	}
+
+	if validity := l.maker.validity() - l.maker.opts.clock.Now().Sub(l.renewed); validity > 0 {
+		return validity
+	}
+
+	return 0
+}
+
+// Handoff 生成锁交接令牌，用于滚动重启时将持有的锁不经释放地交接给继任进程
+// 令牌在expiration内有效且仅可使用一次，交接完成前当前持有者继续续租，交接完成后续租自动失效；锁未被当前持有者持有时返回errors.ErrLockNotHeld
+func (l *Locker) Handoff(ctx context.Context, expiration time.Duration) (string, error) {
+	return l.maker.handoff(ctx, l.key, l.version, expiration)
+}
+
+// Claim 凭交接令牌认领锁，认领成功后由当前Locker持有并续租；令牌无效或已过期时返回errors.ErrInvalidHandoffToken
+func (l *Locker) Claim(ctx context.Context, token string) error {
+	if l.maker.closed.Load() {
+		return errors.ErrClientClosed
+	}
+
+	start := l.maker.opts.clock.Now()
+
+	if err := l.maker.claim(ctx, l.key, l.version, token); err != nil {
+		return err
+	}
+
+	l.hold(start)
+
+	return nil
+}
+
+// 持有锁，开启续租
+func (l *Locker) hold(start time.Time) {
+	l.rw.Lock()
+	l.stopped = false
+	l.renewed = start
+	l.timer = l.maker.opts.clock.AfterFunc(l.maker.validity()/2, l.renewal)
+	fn := l.onAcquired
+	l.rw.Unlock()
+
+	l.maker.lockers.Store(l, struct{}{})
+
+	if fn != nil {
+		fn()
+	}
+}
+
+// 停止续租，并等待进行中的续租操作完成
+func (l *Locker) stop() {
+	l.rw.Lock()
+	l.stopped = true
 	if l.timer != nil {
 		l.timer.Stop()
 	}
-	l.rw.RUnlock()
+	l.rw.Unlock()
 
-	return l.maker.release(ctx, l.key, l.version)
+	l.wg.Wait()
 }
 
 // 续租锁
+// 锁已被其他持有者占用时判定为丢失；其他错误（如网络异常、主从切换）时按获取锁的频率重试，直至超出扣除时钟漂移安全余量后的有效期仍未续租成功时判定为丢失
 func (l *Locker) renewal() {
-	if err := l.maker.renewal(context.Background(), l.key, l.version); err != nil {
+	l.rw.Lock()
+	if l.stopped {
+		l.rw.Unlock()
 		return
 	}
+	l.wg.Add(1)
+	l.rw.Unlock()
+
+	defer l.wg.Done()
+
+	start := l.maker.opts.clock.Now()
+
+	err := l.maker.renewal(context.Background(), l.key, l.version)
 
 	l.rw.Lock()
-	l.timer = time.AfterFunc(l.maker.opts.expiration/2, l.renewal)
-	l.rw.Unlock()
+	defer l.rw.Unlock()
+
+	if l.stopped {
+		return
+	}
+
+	now := l.maker.opts.clock.Now()
+
+	switch {
+	case err == nil:
+		l.renewed = start
+		l.timer = l.maker.opts.clock.AfterFunc(l.maker.validity()/2, l.renewal)
+	case !errors.Is(err, errors.ErrIllegalOperation) && now.Sub(l.renewed) < l.maker.validity():
+		l.timer = l.maker.opts.clock.AfterFunc(l.maker.opts.acquireInterval, l.renewal)
+	default:
+		l.stopped = true
+		l.maker.lockers.Delete(l)
+
+		log.Warnf("lock lost, key: %s, err: %v", l.key, err)
+
+		if l.onLost != nil {
+			go l.onLost()
+		}
+	}
 }
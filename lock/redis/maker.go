@@ -2,19 +2,32 @@ package redis
 
 import (
 	"context"
+	xredis "github.com/dobyte/due/redis/v2"
 	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/lock"
+	"github.com/dobyte/due/v2/log"
 	"github.com/dobyte/due/v2/utils/xconv"
 	"github.com/dobyte/due/v2/utils/xuuid"
 	"github.com/go-redis/redis/v8"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// 重定向错误的重试间隔
+const redirectInterval = 10 * time.Millisecond
+
 type Maker struct {
 	opts          *options
 	builtin       bool
+	closed        atomic.Bool
+	lockers       sync.Map
 	releaseScript *redis.Script
 	renewalScript *redis.Script
+	ttlScript     *redis.Script
+	handoffScript *redis.Script
+	claimScript   *redis.Script
 }
 
 func NewMaker(opts ...Option) *Maker {
@@ -31,16 +44,17 @@ func NewMaker(opts ...Option) *Maker {
 	m.opts = o
 	m.releaseScript = redis.NewScript(releaseScript)
 	m.renewalScript = redis.NewScript(renewalScript)
+	m.ttlScript = redis.NewScript(ttlScript)
+	m.handoffScript = redis.NewScript(handoffScript)
+	m.claimScript = redis.NewScript(claimScript)
+
+	if o.client == nil {
+		o.client = xredis.GetSharedClient()
+	}
 
 	if o.client == nil {
 		m.builtin = true
-		o.client = redis.NewUniversalClient(&redis.UniversalOptions{
-			Addrs:      o.addrs,
-			DB:         o.db,
-			Username:   o.username,
-			Password:   o.password,
-			MaxRetries: o.maxRetries,
-		})
+		o.client = newClient(o)
 	}
 
 	return m
@@ -63,6 +77,27 @@ func (m *Maker) Make(name string) lock.Locker {
 
 // Close 关闭构建器
 func (m *Maker) Close() error {
+	return m.Shutdown(context.Background())
+}
+
+// Shutdown 有序关闭构建器
+// 依次停止所有锁的续租（等待进行中的续租完成）、释放当前持有的锁，最后关闭内建客户端；共享客户端与外部客户端由调用方关闭
+// 关闭后获取锁将返回errors.ErrClientClosed
+func (m *Maker) Shutdown(ctx context.Context) error {
+	if !m.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	m.lockers.Range(func(key, _ any) bool {
+		l := key.(*Locker)
+
+		if err := l.Release(ctx); err != nil && !errors.Is(err, errors.ErrIllegalOperation) {
+			log.Warnf("release lock failed on shutdown, key: %s, err: %v", l.key, err)
+		}
+
+		return true
+	})
+
 	if m.builtin {
 		return m.opts.client.Close()
 	}
@@ -75,8 +110,15 @@ func (m *Maker) acquire(ctx context.Context, key, version string) error {
 	var (
 		args    = redis.SetArgs{Mode: "NX", TTL: m.opts.expiration}
 		retries int
+		timer   *time.Timer
 	)
 
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
 	for {
 		val, err := m.opts.client.SetArgs(ctx, key, version, args).Result()
 		if err != nil && !errors.Is(err, redis.Nil) {
@@ -95,7 +137,17 @@ func (m *Maker) acquire(ctx context.Context, key, version string) error {
 			retries++
 		}
 
-		time.Sleep(m.opts.acquireInterval)
+		if timer == nil {
+			timer = time.NewTimer(m.opts.acquireInterval)
+		} else {
+			timer.Reset(m.opts.acquireInterval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
 	}
 }
 
@@ -121,7 +173,7 @@ func (m *Maker) tryAcquire(ctx context.Context, key, version string, expiration
 
 // 执行释放锁操作
 func (m *Maker) release(ctx context.Context, key, version string) error {
-	rst, err := m.releaseScript.Run(ctx, m.opts.client, []string{key}, version).StringSlice()
+	rst, err := m.run(ctx, m.releaseScript, []string{key}, version).StringSlice()
 	if err != nil {
 		return err
 	}
@@ -133,9 +185,45 @@ func (m *Maker) release(ctx context.Context, key, version string) error {
 	return nil
 }
 
+// 执行锁脚本
+// 集群重新分片期间，集群客户端会自动跟随MOVED/ASK重定向；重定向次数耗尽或槽位迁移未完成（TRYAGAIN、CLUSTERDOWN）时仍会返回重定向错误，
+// 此时在最大重定向次数内稍后重试，待集群客户端刷新槽位映射后在新节点上执行，保证持有中的锁在重新分片期间仍能续租与释放
+// 收到重定向错误时脚本并未执行，重试不会重复修改锁状态
+// 脚本以EVALSHA执行，返回NOSCRIPT错误（如首次执行、Redis重启或主从切换）时自动回退为EVAL执行并缓存脚本，无需预加载
+func (m *Maker) run(ctx context.Context, script *redis.Script, keys []string, args ...interface{}) *redis.Cmd {
+	var timer *time.Timer
+
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for i := 0; ; i++ {
+		cmd := script.Run(ctx, m.opts.client, keys, args...)
+		if i >= m.opts.maxRedirects || !isRedirectError(cmd.Err()) {
+			return cmd
+		}
+
+		log.Warnf("lock script redirected, key: %s, err: %v", keys[0], cmd.Err())
+
+		if timer == nil {
+			timer = time.NewTimer(redirectInterval)
+		} else {
+			timer.Reset(redirectInterval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return cmd
+		case <-timer.C:
+		}
+	}
+}
+
 // 执行续租锁操作
 func (m *Maker) renewal(ctx context.Context, key, version string) error {
-	rst, err := m.renewalScript.Run(ctx, m.opts.client, []string{key}, version, m.opts.expiration.Milliseconds()).StringSlice()
+	rst, err := m.run(ctx, m.renewalScript, []string{key}, version, m.opts.expiration.Milliseconds()).StringSlice()
 	if err != nil {
 		return err
 	}
@@ -146,3 +234,117 @@ func (m *Maker) renewal(ctx context.Context, key, version string) error {
 
 	return nil
 }
+
+// 获取锁剩余生存时间，持有者校验与PTTL读取在同一脚本中原子执行
+// 返回的剩余生存时间已扣除时钟漂移安全余量，扣除后不大于0时视为锁已丢失
+func (m *Maker) ttl(ctx context.Context, key, version string) (time.Duration, error) {
+	ms, err := m.run(ctx, m.ttlScript, []string{key}, version).Int64()
+	if err != nil {
+		return 0, err
+	}
+
+	if ms < 0 {
+		return 0, errors.ErrLockNotHeld
+	}
+
+	ttl := time.Duration(ms)*time.Millisecond - m.drift()
+	if ttl <= 0 {
+		return 0, errors.ErrLockNotHeld
+	}
+
+	return ttl, nil
+}
+
+// 获取时钟漂移安全余量
+func (m *Maker) drift() time.Duration {
+	if m.opts.clockDrift > 0 {
+		return m.opts.clockDrift
+	}
+
+	return m.opts.expiration/100 + 2*time.Millisecond
+}
+
+// 获取扣除时钟漂移安全余量后的锁有效期
+func (m *Maker) validity() time.Duration {
+	return m.opts.expiration - m.drift()
+}
+
+// 生成锁交接令牌
+func (m *Maker) handoff(ctx context.Context, key, version string, expiration time.Duration) (string, error) {
+	token := xuuid.UUID()
+
+	rst, err := m.run(ctx, m.handoffScript, []string{key, handoffKey(key)}, version, token, expiration.Milliseconds()).StringSlice()
+	if err != nil {
+		return "", err
+	}
+
+	if rst[0] != "OK" {
+		return "", errors.ErrLockNotHeld
+	}
+
+	return token, nil
+}
+
+// 凭交接令牌认领锁
+func (m *Maker) claim(ctx context.Context, key, version, token string) error {
+	rst, err := m.run(ctx, m.claimScript, []string{key, handoffKey(key)}, token, version, m.opts.expiration.Milliseconds()).StringSlice()
+	if err != nil {
+		return err
+	}
+
+	if rst[0] != "OK" {
+		return errors.ErrInvalidHandoffToken
+	}
+
+	return nil
+}
+
+// 锁交接令牌键，与锁键使用相同的哈希标签，确保集群模式下两者位于同一槽位
+func handoffKey(key string) string {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			return key + ":handoff"
+		}
+	}
+
+	return "{" + key + "}:handoff"
+}
+
+// 创建内建客户端
+// 设置哨兵模式主节点名称时以哨兵模式连接；开启集群模式或存在多个地址时以集群模式连接，集群客户端会自动跟随MOVED/ASK重定向
+func newClient(o *options) redis.UniversalClient {
+	if o.cluster && o.masterName == "" {
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        o.addrs,
+			Username:     o.username,
+			Password:     o.password,
+			MaxRetries:   o.maxRetries,
+			MaxRedirects: o.maxRedirects,
+		})
+	}
+
+	return redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:            o.addrs,
+		DB:               o.db,
+		Username:         o.username,
+		Password:         o.password,
+		MaxRetries:       o.maxRetries,
+		MaxRedirects:     o.maxRedirects,
+		MasterName:       o.masterName,
+		SentinelPassword: o.sentinelPassword,
+	})
+}
+
+// 是否为集群重定向错误
+func isRedirectError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	msg := err.Error()
+
+	return strings.HasPrefix(msg, "MOVED ") ||
+		strings.HasPrefix(msg, "ASK ") ||
+		strings.HasPrefix(msg, "TRYAGAIN") ||
+		strings.HasPrefix(msg, "CLUSTERDOWN")
+}
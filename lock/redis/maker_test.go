@@ -2,8 +2,14 @@ package redis_test
 
 import (
 	"context"
+	"errors"
 	"github.com/dobyte/due/lock/redis/v2"
+	"github.com/dobyte/due/v2/core/clock"
+	dueerrors "github.com/dobyte/due/v2/errors"
+	goredis "github.com/go-redis/redis/v8"
+	"net"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -55,3 +61,396 @@ func TestMaker_Parallel_Make(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestMaker_SentinelFailover(t *testing.T) {
+	var (
+		ctx        = context.Background()
+		masterName = "mymaster"
+		sentinel   = "127.0.0.1:26379"
+	)
+
+	maker := redis.NewMaker(
+		redis.WithAddrs(sentinel),
+		redis.WithMasterName(masterName),
+		redis.WithExpiration(30*time.Second),
+	)
+
+	locker := maker.Make("lockName")
+
+	if err := locker.Acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	client := goredis.NewSentinelClient(&goredis.Options{Addr: sentinel})
+	defer client.Close()
+
+	before, err := client.GetMasterAddrByName(ctx, masterName).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = client.Failover(ctx, masterName).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 30; i++ {
+		time.Sleep(time.Second)
+
+		after, err := client.GetMasterAddrByName(ctx, masterName).Result()
+		if err == nil && after[0]+after[1] != before[0]+before[1] {
+			t.Logf("master switched from %v to %v", before, after)
+			break
+		}
+	}
+
+	if err = locker.Release(ctx); err != nil {
+		t.Fatalf("the lock does not survive the failover: %v", err)
+	}
+}
+
+func TestMaker_AcquireCancel(t *testing.T) {
+	maker := redis.NewMaker(
+		redis.WithAcquireInterval(5*time.Second),
+		redis.WithAcquireMaxRetries(0),
+	)
+
+	holder := maker.Make("cancelLockName")
+
+	if err := holder.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	defer holder.Release(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	err := maker.Make("cancelLockName").Acquire(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context deadline exceeded, got: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Fatalf("acquire returned too late, elapsed: %v", elapsed)
+	}
+}
+
+type countHook struct {
+	count atomic.Int64
+}
+
+func (h *countHook) BeforeProcess(ctx context.Context, cmd goredis.Cmder) (context.Context, error) {
+	h.count.Add(1)
+	return ctx, nil
+}
+
+func (h *countHook) AfterProcess(ctx context.Context, cmd goredis.Cmder) error {
+	return nil
+}
+
+func (h *countHook) BeforeProcessPipeline(ctx context.Context, cmds []goredis.Cmder) (context.Context, error) {
+	h.count.Add(int64(len(cmds)))
+	return ctx, nil
+}
+
+func (h *countHook) AfterProcessPipeline(ctx context.Context, cmds []goredis.Cmder) error {
+	return nil
+}
+
+func TestMaker_Shutdown(t *testing.T) {
+	hook := &countHook{}
+	client := goredis.NewUniversalClient(&goredis.UniversalOptions{Addrs: []string{"127.0.0.1:6379"}})
+	client.AddHook(hook)
+	defer client.Close()
+
+	expiration := 200 * time.Millisecond
+	maker := redis.NewMaker(redis.WithClient(client), redis.WithExpiration(expiration))
+	locker := maker.Make("shutdownLockName")
+
+	if err := locker.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(expiration)
+
+	if err := maker.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	count := hook.count.Load()
+
+	time.Sleep(3 * expiration)
+
+	if n := hook.count.Load() - count; n != 0 {
+		t.Fatalf("%d commands fired after shutdown", n)
+	}
+
+	if err := locker.Acquire(context.Background()); !errors.Is(err, dueerrors.ErrClientClosed) {
+		t.Fatalf("expected client closed, got: %v", err)
+	}
+
+	if n, err := client.Exists(context.Background(), "lock:shutdownLockName").Result(); err != nil || n != 0 {
+		t.Fatalf("lock is not released on shutdown: %d, %v", n, err)
+	}
+}
+
+func TestLocker_TTL(t *testing.T) {
+	ctx := context.Background()
+	maker := redis.NewMaker(redis.WithExpiration(3 * time.Second))
+	locker := maker.Make("ttlLockName").(*redis.Locker)
+
+	if _, err := locker.TTL(ctx); !errors.Is(err, dueerrors.ErrLockNotHeld) {
+		t.Fatalf("expected lock not held, got: %v", err)
+	}
+
+	if err := locker.Acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	ttl, err := locker.TTL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ttl <= 0 || ttl > 3*time.Second {
+		t.Fatalf("unexpected ttl: %v", ttl)
+	}
+
+	other := maker.Make("ttlLockName").(*redis.Locker)
+
+	if _, err = other.TTL(ctx); !errors.Is(err, dueerrors.ErrLockNotHeld) {
+		t.Fatalf("expected lock not held for other holder, got: %v", err)
+	}
+
+	if err = locker.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = locker.TTL(ctx); !errors.Is(err, dueerrors.ErrLockNotHeld) {
+		t.Fatalf("expected lock not held after release, got: %v", err)
+	}
+}
+
+func TestLocker_Handoff(t *testing.T) {
+	ctx := context.Background()
+	maker := redis.NewMaker(redis.WithExpiration(3 * time.Second))
+	outgoing := maker.Make("handoffLockName").(*redis.Locker)
+	incoming := maker.Make("handoffLockName").(*redis.Locker)
+
+	if _, err := outgoing.Handoff(ctx, time.Second); !errors.Is(err, dueerrors.ErrLockNotHeld) {
+		t.Fatalf("expected lock not held, got: %v", err)
+	}
+
+	if err := outgoing.Acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := outgoing.Handoff(ctx, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = incoming.Claim(ctx, "invalid"); !errors.Is(err, dueerrors.ErrInvalidHandoffToken) {
+		t.Fatalf("expected invalid handoff token, got: %v", err)
+	}
+
+	if err = incoming.Claim(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	defer incoming.Release(ctx)
+
+	if _, err = incoming.TTL(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = outgoing.TTL(ctx); !errors.Is(err, dueerrors.ErrLockNotHeld) {
+		t.Fatalf("expected lock not held for outgoing holder, got: %v", err)
+	}
+
+	if err = incoming.Claim(ctx, token); !errors.Is(err, dueerrors.ErrInvalidHandoffToken) {
+		t.Fatalf("expected token to be single use, got: %v", err)
+	}
+}
+
+func TestLocker_OnLost(t *testing.T) {
+	ctx := context.Background()
+	maker := redis.NewMaker(redis.WithExpiration(time.Second))
+	locker := maker.Make("lostLockName").(*redis.Locker)
+
+	var acquired atomic.Bool
+	lost := make(chan struct{})
+
+	locker.OnAcquired(func() { acquired.Store(true) })
+	locker.OnLost(func() { close(lost) })
+
+	if err := locker.Acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if !acquired.Load() {
+		t.Fatal("acquired hook not called")
+	}
+
+	client := goredis.NewClient(&goredis.Options{Addr: "127.0.0.1:6379"})
+	defer client.Close()
+
+	// 模拟锁被其他持有者占用
+	if err := client.Set(ctx, "lock:lostLockName", "other", time.Minute).Err(); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Del(ctx, "lock:lostLockName")
+
+	select {
+	case <-lost:
+	case <-time.After(3 * time.Second):
+		t.Fatal("lost hook not called")
+	}
+
+	if err := locker.Release(ctx); !errors.Is(err, dueerrors.ErrIllegalOperation) {
+		t.Fatalf("expected illegal operation, got: %v", err)
+	}
+}
+
+func TestLocker_ClockDrift(t *testing.T) {
+	ctx := context.Background()
+	fake := clock.NewFake(time.Now())
+	maker := redis.NewMaker(
+		redis.WithExpiration(time.Second),
+		redis.WithClockDrift(100*time.Millisecond),
+		redis.WithClock(fake),
+	)
+	locker := maker.Make("driftLockName").(*redis.Locker)
+
+	if validity := locker.Validity(); validity != 0 {
+		t.Fatalf("unexpected validity before acquire: %v", validity)
+	}
+
+	if err := locker.Acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if validity := locker.Validity(); validity != 900*time.Millisecond {
+		t.Fatalf("unexpected validity: %v", validity)
+	}
+
+	// 模拟本地时钟快于Redis时钟，本地有效期先于Redis侧过期耗尽
+	fake.Advance(400 * time.Millisecond)
+
+	if validity := locker.Validity(); validity != 500*time.Millisecond {
+		t.Fatalf("unexpected validity after skew: %v", validity)
+	}
+
+	ttl, err := locker.TTL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ttl <= 0 || ttl > 900*time.Millisecond {
+		t.Fatalf("unexpected ttl: %v", ttl)
+	}
+
+	if err = locker.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if validity := locker.Validity(); validity != 0 {
+		t.Fatalf("unexpected validity after release: %v", validity)
+	}
+}
+
+// 模拟集群重新分片，续租脚本首次执行时返回MOVED重定向错误
+type movedHook struct {
+	moved atomic.Int32
+}
+
+func (h *movedHook) BeforeProcess(ctx context.Context, cmd goredis.Cmder) (context.Context, error) {
+	// 续租脚本参数为：evalsha sha 1 key version expiration
+	if cmd.Name() == "evalsha" && len(cmd.Args()) == 6 && h.moved.CompareAndSwap(0, 1) {
+		return ctx, errors.New("MOVED 3999 127.0.0.1:6379")
+	}
+
+	return ctx, nil
+}
+
+func (h *movedHook) AfterProcess(ctx context.Context, cmd goredis.Cmder) error {
+	return nil
+}
+
+func (h *movedHook) BeforeProcessPipeline(ctx context.Context, cmds []goredis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h *movedHook) AfterProcessPipeline(ctx context.Context, cmds []goredis.Cmder) error {
+	return nil
+}
+
+func TestLocker_Resharding(t *testing.T) {
+	ctx := context.Background()
+	hook := &movedHook{}
+	client := goredis.NewClient(&goredis.Options{Addr: "127.0.0.1:6379"})
+	client.AddHook(hook)
+	defer client.Close()
+
+	maker := redis.NewMaker(
+		redis.WithClient(client),
+		redis.WithExpiration(time.Second),
+		redis.WithAcquireInterval(time.Minute),
+	)
+	locker := maker.Make("reshardLockName").(*redis.Locker)
+
+	var lost atomic.Bool
+	locker.OnLost(func() { lost.Store(true) })
+
+	if err := locker.Acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// 等待多个过期周期，续租失败时锁将在Redis侧过期
+	time.Sleep(2500 * time.Millisecond)
+
+	if hook.moved.Load() != 1 {
+		t.Fatal("renewal was not redirected")
+	}
+
+	if lost.Load() {
+		t.Fatal("lock lost during resharding")
+	}
+
+	if _, err := locker.TTL(ctx); err != nil {
+		t.Fatalf("lock not held after resharding: %v", err)
+	}
+
+	if err := locker.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewMaker_NonBlocking(t *testing.T) {
+	// 接受连接但不响应的服务端
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	start := time.Now()
+
+	maker := redis.NewMaker(redis.WithAddrs(listener.Addr().String()))
+	defer maker.Close()
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("NewMaker blocked for %v", elapsed)
+	}
+}
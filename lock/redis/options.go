@@ -1,6 +1,7 @@
 package redis
 
 import (
+	"github.com/dobyte/due/v2/core/clock"
 	"github.com/dobyte/due/v2/etc"
 	"github.com/go-redis/redis/v8"
 	"time"
@@ -10,22 +11,29 @@ const (
 	defaultAddr              = "127.0.0.1:6379"
 	defaultDB                = 0
 	defaultMaxRetries        = 3
+	defaultMaxRedirects      = 3
 	defaultPrefix            = "lock"
 	defaultExpiration        = "3s"
 	defaultAcquireInterval   = "100ms"
 	defaultAcquireMaxRetries = 0
+	defaultClockDrift        = "0s"
 )
 
 const (
 	defaultAddrsKey             = "etc.lock.redis.addrs"
 	defaultDBKey                = "etc.lock.redis.db"
 	defaultMaxRetriesKey        = "etc.lock.redis.maxRetries"
+	defaultClusterKey           = "etc.lock.redis.cluster"
+	defaultMaxRedirectsKey      = "etc.lock.redis.maxRedirects"
+	defaultMasterNameKey        = "etc.lock.redis.masterName"
+	defaultSentinelPasswordKey  = "etc.lock.redis.sentinelPassword"
 	defaultPrefixKey            = "etc.lock.redis.prefix"
 	defaultUsernameKey          = "etc.lock.redis.username"
 	defaultPasswordKey          = "etc.lock.redis.password"
 	defaultExpirationKey        = "etc.lock.redis.expiration"
 	defaultAcquireIntervalKey   = "etc.lock.redis.acquireInterval"
 	defaultAcquireMaxRetriesKey = "etc.lock.redis.acquireMaxRetries"
+	defaultClockDriftKey        = "etc.lock.redis.clockDrift"
 )
 
 type Option func(o *options)
@@ -51,8 +59,24 @@ type options struct {
 	// 内建客户端配置，默认为3次
 	maxRetries int
 
+	// 是否以集群模式连接
+	// 内建客户端配置，开启后即使仅配置一个种子节点地址也以集群模式连接，默认为false（多个地址时自动以集群模式连接）
+	cluster bool
+
+	// 最大重定向次数
+	// 集群重新分片期间，锁脚本（续租、释放等）收到MOVED/ASK等重定向错误时的最大重试次数，内建集群客户端同时以此作为重定向跟随次数，默认为3次
+	maxRedirects int
+
+	// 哨兵模式主节点名称
+	// 内建客户端配置，设置后将以哨兵模式连接，此时addrs为哨兵节点地址，默认为空
+	masterName string
+
+	// 哨兵节点密码
+	// 内建客户端配置，仅哨兵模式下生效，默认为空
+	sentinelPassword string
+
 	// 客户端
-	// 外部客户端配置，存在外部客户端时，优先使用外部客户端，默认为nil
+	// 外部客户端配置，存在外部客户端时，优先使用外部客户端；其次使用共享客户端（xredis.SetSharedClient）；默认为nil
 	client redis.UniversalClient
 
 	// 前缀
@@ -67,6 +91,15 @@ type options struct {
 
 	// 循环获取锁的最大重试次数，默认为无限次
 	acquireMaxRetries int
+
+	// 时钟漂移安全余量
+	// 本地时钟与Redis时钟存在偏差时，锁的实际有效期可能短于本地计算的有效期；本地判断锁是否仍然有效（有效期、续租失败判定、TTL）时均扣除该余量
+	// 小于等于0时为锁过期时间的1%加2ms，默认为0
+	clockDrift time.Duration
+
+	// 时钟
+	// 用于锁续租定时，测试中可替换为模拟时钟，默认为系统时钟
+	clock clock.Clock
 }
 
 func defaultOptions() *options {
@@ -74,12 +107,18 @@ func defaultOptions() *options {
 		addrs:             etc.Get(defaultAddrsKey, []string{defaultAddr}).Strings(),
 		db:                etc.Get(defaultDBKey, defaultDB).Int(),
 		maxRetries:        etc.Get(defaultMaxRetriesKey, defaultMaxRetries).Int(),
+		cluster:           etc.Get(defaultClusterKey).Bool(),
+		maxRedirects:      etc.Get(defaultMaxRedirectsKey, defaultMaxRedirects).Int(),
+		masterName:        etc.Get(defaultMasterNameKey).String(),
+		sentinelPassword:  etc.Get(defaultSentinelPasswordKey).String(),
 		prefix:            etc.Get(defaultPrefixKey, defaultPrefix).String(),
 		username:          etc.Get(defaultUsernameKey).String(),
 		password:          etc.Get(defaultPasswordKey).String(),
 		expiration:        etc.Get(defaultExpirationKey, defaultExpiration).Duration(),
 		acquireInterval:   etc.Get(defaultAcquireIntervalKey, defaultAcquireInterval).Duration(),
 		acquireMaxRetries: etc.Get(defaultAcquireMaxRetriesKey, defaultAcquireMaxRetries).Int(),
+		clockDrift:        etc.Get(defaultClockDriftKey, defaultClockDrift).Duration(),
+		clock:             clock.Real(),
 	}
 }
 
@@ -108,6 +147,26 @@ func WithMaxRetries(maxRetries int) Option {
 	return func(o *options) { o.maxRetries = maxRetries }
 }
 
+// WithCluster 设置是否以集群模式连接
+func WithCluster(cluster bool) Option {
+	return func(o *options) { o.cluster = cluster }
+}
+
+// WithMaxRedirects 设置最大重定向次数
+func WithMaxRedirects(maxRedirects int) Option {
+	return func(o *options) { o.maxRedirects = maxRedirects }
+}
+
+// WithMasterName 设置哨兵模式主节点名称，设置后将以哨兵模式连接，此时连接地址为哨兵节点地址
+func WithMasterName(masterName string) Option {
+	return func(o *options) { o.masterName = masterName }
+}
+
+// WithSentinelPassword 设置哨兵节点密码
+func WithSentinelPassword(password string) Option {
+	return func(o *options) { o.sentinelPassword = password }
+}
+
 // WithClient 设置外部客户端
 func WithClient(client redis.UniversalClient) Option {
 	return func(o *options) { o.client = client }
@@ -132,3 +191,13 @@ func WithAcquireInterval(acquireInterval time.Duration) Option {
 func WithAcquireMaxRetries(acquireMaxRetries int) Option {
 	return func(o *options) { o.acquireMaxRetries = acquireMaxRetries }
 }
+
+// WithClockDrift 设置时钟漂移安全余量，本地判断锁是否仍然有效时扣除该余量
+func WithClockDrift(drift time.Duration) Option {
+	return func(o *options) { o.clockDrift = drift }
+}
+
+// WithClock 设置时钟，测试中可替换为模拟时钟以精确控制续租定时
+func WithClock(clock clock.Clock) Option {
+	return func(o *options) { o.clock = clock }
+}
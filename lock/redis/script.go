@@ -33,3 +33,46 @@ const renewalScript = `
 
 	return {'OK'}
 `
+
+// 获取锁剩余生存时间（毫秒），非持有者返回-3
+const ttlScript = `
+	local val = redis.call('GET', KEYS[1])
+
+	if val ~= ARGV[1] then
+		return -3
+	end
+
+	return redis.call('PTTL', KEYS[1])
+`
+
+// 生成锁交接令牌，仅持有者可生成，令牌过期后失效
+const handoffScript = `
+	local val = redis.call('GET', KEYS[1])
+
+	if val ~= ARGV[1] then
+		return {'NO'}
+	end
+
+	redis.call('SET', KEYS[2], ARGV[2], 'PX', ARGV[3])
+
+	return {'OK'}
+`
+
+// 凭交接令牌认领锁，校验令牌后原子地替换锁持有者并销毁令牌
+const claimScript = `
+	local token = redis.call('GET', KEYS[2])
+
+	if token ~= ARGV[1] then
+		return {'NO'}
+	end
+
+	redis.call('DEL', KEYS[2])
+
+	if redis.call('EXISTS', KEYS[1]) == 0 then
+		return {'NO'}
+	end
+
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+
+	return {'OK'}
+`
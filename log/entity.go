@@ -114,11 +114,17 @@ func (e *Entity) Free() {
 func (e *Entity) Log() {
 	defer e.Free()
 
-	if e.Level < e.pool.logger.opts.level {
+	if e.Level < e.pool.logger.Level() {
 		return
 	}
 
 	buffers := make(map[bool][]byte, 2)
+
+	if e.pool.logger.output.active.Load() {
+		buffers[false] = e.pool.logger.formatter.format(e, false)
+		e.pool.logger.output.write(buffers[false])
+	}
+
 	for _, s := range e.pool.logger.syncers {
 		if !s.enabler(e.Level) {
 			continue
@@ -1,5 +1,11 @@
 package log
 
+import (
+	"github.com/dobyte/due/v2/errors"
+	"io"
+	"time"
+)
+
 var globalLogger Logger
 
 func init() {
@@ -20,6 +26,30 @@ func GetLogger() Logger {
 	return globalLogger
 }
 
+// SetLevel 设置全局日志记录器输出的最低日志级别，可在运行期间调用；设置revert后将在超时后恢复为原级别
+func SetLevel(level Level, revert ...time.Duration) error {
+	s, ok := globalLogger.(Swapper)
+	if !ok {
+		return errors.ErrNotSupported
+	}
+
+	s.SetLevel(level, revert...)
+
+	return nil
+}
+
+// SetOutput 设置全局日志记录器的附加输出，w为nil时移除附加输出，可在运行期间调用；设置revert后将在超时后恢复为原附加输出
+func SetOutput(w io.Writer, revert ...time.Duration) error {
+	s, ok := globalLogger.(Swapper)
+	if !ok {
+		return errors.ErrNotSupported
+	}
+
+	s.SetOutput(w, revert...)
+
+	return nil
+}
+
 // Print 打印日志，不含堆栈信息
 func Print(level Level, a ...interface{}) {
 	if globalLogger != nil {
@@ -1,8 +1,14 @@
 package log_test
 
 import (
+	"bytes"
 	"github.com/dobyte/due/v2/log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestLog(t *testing.T) {
@@ -13,3 +19,114 @@ func TestLog(t *testing.T) {
 	logger.Warn("welcome to due-framework")
 	logger.Error("welcome to due-framework")
 }
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLogger_SetOutput(t *testing.T) {
+	logger := log.NewLogger(log.WithFile(""), log.WithLevel(log.InfoLevel))
+	output := &syncBuffer{}
+
+	logger.Debug("invisible before swap")
+	logger.SetLevel(log.DebugLevel, 50*time.Millisecond)
+	logger.SetOutput(output, 50*time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Debug("incident")
+		}()
+	}
+	wg.Wait()
+
+	if n := strings.Count(output.String(), "incident"); n != 10 {
+		t.Fatalf("expected 10 lines, got %d", n)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if logger.Level() != log.InfoLevel {
+		t.Fatalf("level not reverted: %v", logger.Level())
+	}
+
+	logger.Debug("after revert")
+
+	if strings.Contains(output.String(), "after revert") {
+		t.Fatal("output not reverted")
+	}
+}
+
+type closeBuffer struct {
+	syncBuffer
+	closed bool
+}
+
+func (b *closeBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestLogger_SetOutput_Close(t *testing.T) {
+	logger := log.NewLogger(log.WithFile(""))
+	first := &closeBuffer{}
+	second := &closeBuffer{}
+
+	logger.SetOutput(first)
+	logger.SetOutput(second)
+
+	if !first.closed {
+		t.Fatal("replaced output not closed")
+	}
+
+	logger.Info("incident")
+	logger.SetOutput(nil)
+
+	if !second.closed {
+		t.Fatal("removed output not closed")
+	}
+
+	if strings.Contains(first.String(), "incident") || !strings.Contains(second.String(), "incident") {
+		t.Fatal("unexpected output")
+	}
+}
+
+func TestOutputHandler(t *testing.T) {
+	handler := log.OutputHandler()
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/debug/log?level=debug", nil))
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("unexpected status for GET: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodPost, "/debug/log?level=unknown", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status for invalid level: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodPost, "/debug/log?file=/tmp/x.log&level=info", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+}
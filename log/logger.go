@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"sync"
+	"sync/atomic"
 )
 
 type Logger interface {
@@ -42,11 +43,15 @@ type Logger interface {
 }
 
 type defaultLogger struct {
-	opts       *options
-	formatter  formatter
-	syncers    []syncer
-	bufferPool sync.Pool
-	entityPool *EntityPool
+	opts          *options
+	formatter     formatter
+	syncers       []syncer
+	bufferPool    sync.Pool
+	entityPool    *EntityPool
+	level         atomic.Int32
+	levelVersion  atomic.Uint64
+	output        guardedWriter
+	outputVersion atomic.Uint64
 }
 
 type enabler func(level Level) bool
@@ -63,6 +68,8 @@ type syncer struct {
 
 var _ Logger = &defaultLogger{}
 
+var _ Swapper = &defaultLogger{}
+
 func NewLogger(opts ...Option) *defaultLogger {
 	o := defaultOptions()
 	for _, opt := range opts {
@@ -71,6 +78,7 @@ func NewLogger(opts ...Option) *defaultLogger {
 
 	l := &defaultLogger{}
 	l.opts = o
+	l.level.Store(int32(o.level))
 	l.syncers = make([]syncer, 0, 7)
 	l.entityPool = newEntityPool(l)
 
@@ -138,7 +146,8 @@ func (l *defaultLogger) buildWriter(level Level) io.Writer {
 
 func (l *defaultLogger) buildEnabler(level Level) enabler {
 	return func(lvl Level) bool {
-		return lvl >= l.opts.level && (level == NoneLevel || (lvl >= level && level >= l.opts.level))
+		cur := l.Level()
+		return lvl >= cur && (level == NoneLevel || (lvl >= level && level >= cur))
 	}
 }
 
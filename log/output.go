@@ -0,0 +1,136 @@
+package log
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Swapper 支持在运行期间切换输出级别与输出目标的日志记录器
+type Swapper interface {
+	// SetLevel 设置输出的最低日志级别，设置revert后将在超时后恢复为原级别
+	SetLevel(level Level, revert ...time.Duration)
+	// SetOutput 设置附加输出w，w为nil时移除附加输出；设置revert后将在超时后恢复为原附加输出
+	SetOutput(w io.Writer, revert ...time.Duration)
+}
+
+// 受保护的附加输出，未设置时写入无需加锁；切换输出时会等待进行中的写入完成，避免日志写入已关闭的输出
+type guardedWriter struct {
+	active atomic.Bool
+	mu     sync.RWMutex
+	writer io.Writer
+}
+
+// 写入日志
+func (w *guardedWriter) write(b []byte) {
+	if !w.active.Load() {
+		return
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.writer != nil {
+		_, _ = w.writer.Write(b)
+	}
+}
+
+// 切换输出，返回被替换的输出
+func (w *guardedWriter) swap(writer io.Writer) io.Writer {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	old := w.writer
+	w.writer = writer
+	w.active.Store(writer != nil)
+
+	return old
+}
+
+// Level 获取输出的最低日志级别
+func (l *defaultLogger) Level() Level {
+	return Level(l.level.Load())
+}
+
+// SetLevel 设置输出的最低日志级别，可在运行期间调用
+// 设置revert后将在超时后恢复为原级别，期间再次设置时原定的恢复将被取消
+func (l *defaultLogger) SetLevel(level Level, revert ...time.Duration) {
+	prev := Level(l.level.Swap(int32(level)))
+	version := l.levelVersion.Add(1)
+
+	if len(revert) > 0 && revert[0] > 0 {
+		time.AfterFunc(revert[0], func() {
+			if l.levelVersion.CompareAndSwap(version, version+1) {
+				l.level.Store(int32(prev))
+			}
+		})
+	}
+}
+
+// SetOutput 设置附加输出w，日志在写入配置的输出（文件及终端）的同时写入w；w为nil时移除附加输出，可在运行期间调用
+// 设置后w由日志记录器持有：被替换（未设置revert时）或超时恢复时将关闭w（如实现了io.Closer）
+// 设置revert后将在超时后恢复为原附加输出，期间再次设置时原定的恢复将被取消
+func (l *defaultLogger) SetOutput(w io.Writer, revert ...time.Duration) {
+	prev := l.output.swap(w)
+	version := l.outputVersion.Add(1)
+
+	if len(revert) == 0 || revert[0] <= 0 {
+		closeWriter(prev)
+		return
+	}
+
+	time.AfterFunc(revert[0], func() {
+		if l.outputVersion.CompareAndSwap(version, version+1) {
+			closeWriter(l.output.swap(prev))
+		}
+	})
+}
+
+// 关闭输出
+func closeWriter(w io.Writer) {
+	if c, ok := w.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
+
+// OutputHandler 日志级别设置处理器，可注册到调试组件中以便在运行时调整日志级别，仅支持POST请求
+// 例如：POST /debug/log?level=debug&revert=10m
+// revert为空时不自动恢复
+func OutputHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+		revert := time.Duration(0)
+
+		if query.Has("revert") {
+			d, err := time.ParseDuration(query.Get("revert"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			revert = d
+		}
+
+		level := ParseLevel(query.Get("level"))
+		if level == NoneLevel {
+			http.Error(w, "invalid level", http.StatusBadRequest)
+			return
+		}
+
+		if err := SetLevel(level, revert); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		Infof("log level changed, level: %s revert: %v", query.Get("level"), revert)
+
+		_, _ = w.Write([]byte("ok"))
+	}
+}
@@ -9,6 +9,7 @@ package network
 
 import (
 	"net"
+	"time"
 )
 
 const (
@@ -46,4 +47,18 @@ type (
 		// RemoteAddr 获取远端地址
 		RemoteAddr() (net.Addr, error)
 	}
+
+	// CodecConn 支持在握手阶段协商编解码器的连接，连接的可选实现
+	CodecConn interface {
+		// Codec 获取协商的编解码器名称，未协商时返回空字符串
+		Codec() string
+	}
+
+	// ThrottleConn 支持按字节限制出站速率的连接，连接的可选实现
+	ThrottleConn interface {
+		// SetWriteRate 设置出站速率（字节/秒）及突发容量（字节），rate小于等于0时取消限速
+		SetWriteRate(rate, burst int)
+		// Throttled 获取因限速而等待的累计时长
+		Throttled() time.Duration
+	}
 )
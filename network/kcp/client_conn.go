@@ -277,6 +277,8 @@ func (c *clientConn) read() {
 
 			// ignore heartbeat packet
 			if isHeartbeat {
+				packet.ReleaseMessage(msg)
+
 				continue
 			}
 
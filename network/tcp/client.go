@@ -44,6 +44,11 @@ func (c *client) Dial(addr ...string) (network.Conn, error) {
 		return nil, err
 	}
 
+	if err = setTCPOptions(conn, c.opts.keepAlivePeriod, c.opts.noDelay); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
 	return newClientConn(c, atomic.AddInt64(&c.id, 1), conn), nil
 }
 
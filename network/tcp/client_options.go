@@ -9,12 +9,16 @@ const (
 	defaultClientDialAddr          = "127.0.0.1:3553"
 	defaultClientDialTimeout       = "5s"
 	defaultClientHeartbeatInterval = "10s"
+	defaultClientKeepAlivePeriod   = "0s"
+	defaultClientNoDelay           = true
 )
 
 const (
 	defaultClientDialAddrKey          = "etc.network.tcp.client.addr"
 	defaultClientDialTimeoutKey       = "etc.network.tcp.client.timeout"
 	defaultClientHeartbeatIntervalKey = "etc.network.tcp.client.heartbeatInterval"
+	defaultClientKeepAlivePeriodKey   = "etc.network.tcp.client.keepAlivePeriod"
+	defaultClientNoDelayKey           = "etc.network.tcp.client.noDelay"
 )
 
 type ClientOption func(o *clientOptions)
@@ -23,6 +27,8 @@ type clientOptions struct {
 	addr              string        // 地址
 	timeout           time.Duration // 拨号超时时间，默认5s
 	heartbeatInterval time.Duration // 心跳间隔时间，默认10s
+	keepAlivePeriod   time.Duration // TCP保活探测周期，等于0时使用系统默认值，小于0时关闭保活，默认0s
+	noDelay           bool          // 是否禁用Nagle算法，默认true
 }
 
 func defaultClientOptions() *clientOptions {
@@ -30,6 +36,8 @@ func defaultClientOptions() *clientOptions {
 		addr:              etc.Get(defaultClientDialAddrKey, defaultClientDialAddr).String(),
 		timeout:           etc.Get(defaultClientDialTimeoutKey, defaultClientDialTimeout).Duration(),
 		heartbeatInterval: etc.Get(defaultClientHeartbeatIntervalKey, defaultClientHeartbeatInterval).Duration(),
+		keepAlivePeriod:   etc.Get(defaultClientKeepAlivePeriodKey, defaultClientKeepAlivePeriod).Duration(),
+		noDelay:           etc.Get(defaultClientNoDelayKey, defaultClientNoDelay).Bool(),
 	}
 }
 
@@ -47,3 +55,13 @@ func WithClientDialTimeout(timeout time.Duration) ClientOption {
 func WithClientHeartbeatInterval(heartbeatInterval time.Duration) ClientOption {
 	return func(o *clientOptions) { o.heartbeatInterval = heartbeatInterval }
 }
+
+// WithClientKeepAlivePeriod 设置TCP保活探测周期，等于0时使用系统默认值，小于0时关闭保活
+func WithClientKeepAlivePeriod(keepAlivePeriod time.Duration) ClientOption {
+	return func(o *clientOptions) { o.keepAlivePeriod = keepAlivePeriod }
+}
+
+// WithClientNoDelay 设置是否禁用Nagle算法
+func WithClientNoDelay(noDelay bool) ClientOption {
+	return func(o *clientOptions) { o.noDelay = noDelay }
+}
@@ -1,5 +1,10 @@
 package tcp
 
+import (
+	"net"
+	"time"
+)
+
 const protocol = "tcp"
 
 const (
@@ -11,3 +16,29 @@ type chWrite struct {
 	typ int
 	msg []byte
 }
+
+// 设置TCP连接参数
+// keepAlivePeriod等于0时使用系统默认的保活机制，小于0时关闭保活机制，大于0时按照指定周期进行保活探测
+func setTCPOptions(conn net.Conn, keepAlivePeriod time.Duration, noDelay bool) error {
+	c, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+
+	if err := c.SetNoDelay(noDelay); err != nil {
+		return err
+	}
+
+	switch {
+	case keepAlivePeriod < 0:
+		return c.SetKeepAlive(false)
+	case keepAlivePeriod > 0:
+		if err := c.SetKeepAlive(true); err != nil {
+			return err
+		}
+
+		return c.SetKeepAlivePeriod(keepAlivePeriod)
+	default:
+		return nil
+	}
+}
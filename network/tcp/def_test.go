@@ -0,0 +1,88 @@
+//go:build unix
+
+package tcp
+
+import (
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSetTCPOptions(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// 关闭保活并启用Nagle算法
+	if err = setTCPOptions(conn, -1, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if keepAlive, noDelay := sockopts(t, conn); keepAlive != 0 || noDelay != 0 {
+		t.Fatalf("unexpected options, keepAlive: %d noDelay: %d", keepAlive, noDelay)
+	}
+
+	// 开启保活并禁用Nagle算法
+	if err = setTCPOptions(conn, 30*time.Second, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if keepAlive, noDelay := sockopts(t, conn); keepAlive == 0 || noDelay == 0 {
+		t.Fatalf("unexpected options, keepAlive: %d noDelay: %d", keepAlive, noDelay)
+	}
+
+	// 保活周期为0时保持当前的保活设置
+	if err = setTCPOptions(conn, 0, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if keepAlive, _ := sockopts(t, conn); keepAlive == 0 {
+		t.Fatal("keep alive changed by zero period")
+	}
+
+	// 非TCP连接忽略
+	pc, _ := net.Pipe()
+	defer pc.Close()
+
+	if err = setTCPOptions(pc, -1, false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// 获取连接的保活及禁用Nagle算法选项
+func sockopts(t *testing.T, conn net.Conn) (keepAlive int, noDelay int) {
+	t.Helper()
+
+	raw, err := conn.(*net.TCPConn).SyscallConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var opErr error
+
+	err = raw.Control(func(fd uintptr) {
+		if keepAlive, opErr = syscall.GetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_KEEPALIVE); opErr != nil {
+			return
+		}
+
+		noDelay, opErr = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_NODELAY)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if opErr != nil {
+		t.Fatal(opErr)
+	}
+
+	return
+}
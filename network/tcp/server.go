@@ -143,6 +143,10 @@ func (s *server) serve() {
 
 		tempDelay = 0
 
+		if err = setTCPOptions(conn, s.opts.keepAlivePeriod, s.opts.noDelay); err != nil {
+			log.Warnf("tcp set options error: %v", err)
+		}
+
 		if err = s.connMgr.allocate(conn); err != nil {
 			log.Errorf("connection allocate error: %v", err)
 			_ = conn.Close()
@@ -1,6 +1,7 @@
 package tcp
 
 import (
+	"github.com/dobyte/due/v2/core/limiter"
 	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/log"
 	"github.com/dobyte/due/v2/network"
@@ -15,19 +16,23 @@ import (
 )
 
 type serverConn struct {
-	id                int64          // 连接ID
-	uid               int64          // 用户ID
-	state             int32          // 连接状态
-	connMgr           *serverConnMgr // 连接管理
-	rw                sync.RWMutex   // 读写锁
-	conn              net.Conn       // TCP源连接
-	chWrite           chan chWrite   // 写入队列
-	done              chan struct{}  // 写入完成信号
-	close             chan struct{}  // 关闭信号
-	lastHeartbeatTime int64          // 上次心跳时间
+	id                int64            // 连接ID
+	uid               int64            // 用户ID
+	state             int32            // 连接状态
+	connMgr           *serverConnMgr   // 连接管理
+	rw                sync.RWMutex     // 读写锁
+	conn              net.Conn         // TCP源连接
+	chWrite           chan chWrite     // 写入队列
+	done              chan struct{}    // 写入完成信号
+	close             chan struct{}    // 关闭信号
+	lastHeartbeatTime int64            // 上次心跳时间
+	throttle          limiter.Throttle // 出站限速
 }
 
-var _ network.Conn = &serverConn{}
+var (
+	_ network.Conn         = &serverConn{}
+	_ network.ThrottleConn = &serverConn{}
+)
 
 // ID 获取连接ID
 func (c *serverConn) ID() int64 {
@@ -63,6 +68,10 @@ func (c *serverConn) Send(msg []byte) (err error) {
 		return errors.ErrConnectionClosed
 	}
 
+	if !c.throttle.Wait(len(msg), c.close) {
+		return errors.ErrConnectionClosed
+	}
+
 	_, err = conn.Write(msg)
 	return
 }
@@ -81,6 +90,16 @@ func (c *serverConn) Push(msg []byte) (err error) {
 	return
 }
 
+// SetWriteRate 设置出站速率（字节/秒）及突发容量（字节），rate小于等于0时取消限速
+func (c *serverConn) SetWriteRate(rate, burst int) {
+	c.throttle.SetRate(rate, burst)
+}
+
+// Throttled 获取因限速而等待的累计时长
+func (c *serverConn) Throttled() time.Duration {
+	return c.throttle.Throttled()
+}
+
 // State 获取连接状态
 func (c *serverConn) State() network.ConnState {
 	return network.ConnState(atomic.LoadInt32(&c.state))
@@ -89,6 +108,9 @@ func (c *serverConn) State() network.ConnState {
 // Close 关闭连接
 func (c *serverConn) Close(force ...bool) error {
 	if len(force) > 0 && force[0] {
+		if c.connMgr.server.opts.closeLinger > 0 {
+			return c.lingerClose(true)
+		}
 		return c.forceClose(true)
 	} else {
 		return c.graceClose(true)
@@ -167,9 +189,10 @@ func (c *serverConn) init(cm *serverConnMgr, id int64, conn net.Conn) {
 	c.conn = conn
 	c.connMgr = cm
 	c.chWrite = make(chan chWrite, 4096)
-	c.done = make(chan struct{})
+	c.done = make(chan struct{}, 1)
 	c.close = make(chan struct{})
 	c.lastHeartbeatTime = xtime.Now().UnixNano()
+	c.throttle.Reset(c.connMgr.server.opts.writeRate, c.connMgr.server.opts.writeBurst)
 	atomic.StoreInt64(&c.uid, 0)
 	atomic.StoreInt32(&c.state, int32(network.ConnOpened))
 
@@ -192,7 +215,7 @@ func (c *serverConn) graceClose(isNeedRecycle bool) error {
 	c.chWrite <- chWrite{typ: closeSig}
 	c.rw.RUnlock()
 
-	<-c.done
+	c.waitDone()
 
 	if !atomic.CompareAndSwapInt32(&c.state, int32(network.ConnHanged), int32(network.ConnClosed)) {
 		return errors.ErrConnectionNotHanged
@@ -219,6 +242,39 @@ func (c *serverConn) graceClose(isNeedRecycle bool) error {
 	return err
 }
 
+// 逗留关闭，关闭逗留时间内尽力发送写入队列中剩余的消息，超时后剩余的消息将被丢弃
+func (c *serverConn) lingerClose(isNeedRecycle bool) error {
+	if err := c.graceClose(isNeedRecycle); !errors.Is(err, errors.ErrConnectionNotOpened) {
+		return err
+	}
+
+	return c.forceClose(isNeedRecycle)
+}
+
+// 等待写入完成，设置了关闭逗留时间时最多等待逗留时间，并以此作为写入的截止时间
+func (c *serverConn) waitDone() {
+	linger := c.connMgr.server.opts.closeLinger
+	if linger <= 0 {
+		<-c.done
+		return
+	}
+
+	c.rw.RLock()
+	if c.conn != nil {
+		_ = c.conn.SetWriteDeadline(time.Now().Add(linger))
+	}
+	c.rw.RUnlock()
+
+	timer := time.NewTimer(linger)
+	defer timer.Stop()
+
+	select {
+	case <-c.done:
+	case <-timer.C:
+		log.Warnf("connection close linger timeout, cid: %d", c.id)
+	}
+}
+
 // 强制关闭
 func (c *serverConn) forceClose(isNeedRecycle bool) error {
 	if !atomic.CompareAndSwapInt32(&c.state, int32(network.ConnOpened), int32(network.ConnClosed)) {
@@ -284,6 +340,8 @@ func (c *serverConn) read() {
 
 			// ignore heartbeat packet
 			if isHeartbeat {
+				packet.ReleaseMessage(msg)
+
 				// responsive heartbeat
 				if c.connMgr.server.opts.heartbeatMechanism == RespHeartbeat {
 					if heartbeat, err := packet.PackHeartbeat(); err != nil {
@@ -332,7 +390,9 @@ func (c *serverConn) write() {
 
 			if r.typ == closeSig {
 				c.rw.RLock()
-				c.done <- struct{}{}
+				if !c.isClosed() {
+					c.done <- struct{}{}
+				}
 				c.rw.RUnlock()
 				return
 			}
@@ -341,11 +401,15 @@ func (c *serverConn) write() {
 				return
 			}
 
+			if !c.throttle.Wait(len(r.msg), c.close) {
+				return
+			}
+
 			if _, err := conn.Write(r.msg); err != nil {
 				log.Errorf("write data message error: %v", err)
 			}
 		case <-ticker.C:
-			deadline := xtime.Now().Add(-2 * c.connMgr.server.opts.heartbeatInterval).UnixNano()
+			deadline := xtime.Now().Add(-c.connMgr.server.opts.heartbeatTimeout()).UnixNano()
 			if atomic.LoadInt64(&c.lastHeartbeatTime) < deadline {
 				log.Debugf("connection heartbeat timeout, cid: %d", c.id)
 				_ = c.forceClose(true)
@@ -10,6 +10,12 @@ const (
 	defaultServerMaxConnNum         = 5000
 	defaultServerHeartbeatInterval  = "10s"
 	defaultServerHeartbeatMechanism = "resp"
+	defaultServerHeartbeatMisses    = 2
+	defaultServerKeepAlivePeriod    = "0s"
+	defaultServerNoDelay            = true
+	defaultServerCloseLinger        = "0s"
+	defaultServerWriteRate          = 0
+	defaultServerWriteBurst         = 0
 )
 
 const (
@@ -17,6 +23,12 @@ const (
 	defaultServerMaxConnNumKey         = "etc.network.tcp.server.maxConnNum"
 	defaultServerHeartbeatIntervalKey  = "etc.network.tcp.server.heartbeatInterval"
 	defaultServerHeartbeatMechanismKey = "etc.network.tcp.server.heartbeatMechanism"
+	defaultServerHeartbeatMissesKey    = "etc.network.tcp.server.heartbeatMisses"
+	defaultServerKeepAlivePeriodKey    = "etc.network.tcp.server.keepAlivePeriod"
+	defaultServerNoDelayKey            = "etc.network.tcp.server.noDelay"
+	defaultServerCloseLingerKey        = "etc.network.tcp.server.closeLinger"
+	defaultServerWriteRateKey          = "etc.network.tcp.server.writeRate"
+	defaultServerWriteBurstKey         = "etc.network.tcp.server.writeBurst"
 )
 
 const (
@@ -33,6 +45,12 @@ type serverOptions struct {
 	maxConnNum         int                // 最大连接数，默认5000
 	heartbeatInterval  time.Duration      // 心跳检测间隔时间，默认10s
 	heartbeatMechanism HeartbeatMechanism // 心跳机制，默认resp
+	heartbeatMisses    int                // 心跳超时允许错过的心跳间隔数，超过后关闭连接，默认2
+	keepAlivePeriod    time.Duration      // TCP保活探测周期，等于0时使用系统默认值，小于0时关闭保活，默认0s
+	noDelay            bool               // 是否禁用Nagle算法，默认true
+	closeLinger        time.Duration      // 关闭逗留时间，关闭连接前在该时间内尽力发送写入队列中剩余的消息，默认0s不逗留
+	writeRate          int                // 单连接出站速率（字节/秒），默认0不限速
+	writeBurst         int                // 单连接出站突发容量（字节），默认0时与出站速率相同
 }
 
 func defaultServerOptions() *serverOptions {
@@ -41,6 +59,12 @@ func defaultServerOptions() *serverOptions {
 		maxConnNum:         etc.Get(defaultServerMaxConnNumKey, defaultServerMaxConnNum).Int(),
 		heartbeatInterval:  etc.Get(defaultServerHeartbeatIntervalKey, defaultServerHeartbeatInterval).Duration(),
 		heartbeatMechanism: HeartbeatMechanism(etc.Get(defaultServerHeartbeatMechanismKey, defaultServerHeartbeatMechanism).String()),
+		heartbeatMisses:    etc.Get(defaultServerHeartbeatMissesKey, defaultServerHeartbeatMisses).Int(),
+		keepAlivePeriod:    etc.Get(defaultServerKeepAlivePeriodKey, defaultServerKeepAlivePeriod).Duration(),
+		noDelay:            etc.Get(defaultServerNoDelayKey, defaultServerNoDelay).Bool(),
+		closeLinger:        etc.Get(defaultServerCloseLingerKey, defaultServerCloseLinger).Duration(),
+		writeRate:          etc.Get(defaultServerWriteRateKey, defaultServerWriteRate).Int(),
+		writeBurst:         etc.Get(defaultServerWriteBurstKey, defaultServerWriteBurst).Int(),
 	}
 }
 
@@ -63,3 +87,38 @@ func WithServerHeartbeatInterval(heartbeatInterval time.Duration) ServerOption {
 func WithServerHeartbeatMechanism(heartbeatMechanism HeartbeatMechanism) ServerOption {
 	return func(o *serverOptions) { o.heartbeatMechanism = heartbeatMechanism }
 }
+
+// WithServerHeartbeatMisses 设置心跳超时允许错过的心跳间隔数，连续该数量的心跳间隔内未收到任何消息（含心跳）时关闭连接
+func WithServerHeartbeatMisses(heartbeatMisses int) ServerOption {
+	return func(o *serverOptions) { o.heartbeatMisses = heartbeatMisses }
+}
+
+// WithServerKeepAlivePeriod 设置TCP保活探测周期，等于0时使用系统默认值，小于0时关闭保活
+func WithServerKeepAlivePeriod(keepAlivePeriod time.Duration) ServerOption {
+	return func(o *serverOptions) { o.keepAlivePeriod = keepAlivePeriod }
+}
+
+// WithServerNoDelay 设置是否禁用Nagle算法
+func WithServerNoDelay(noDelay bool) ServerOption {
+	return func(o *serverOptions) { o.noDelay = noDelay }
+}
+
+// WithServerCloseLinger 设置关闭逗留时间，关闭连接前在该时间内尽力发送写入队列中剩余的消息
+func WithServerCloseLinger(closeLinger time.Duration) ServerOption {
+	return func(o *serverOptions) { o.closeLinger = closeLinger }
+}
+
+// WithServerWriteRate 设置单连接出站速率（字节/秒）及突发容量（字节），突发容量为0时与出站速率相同
+// 限速按字节进行，超出速率的消息将在写入前等待，不会打乱消息顺序
+func WithServerWriteRate(writeRate, writeBurst int) ServerOption {
+	return func(o *serverOptions) { o.writeRate, o.writeBurst = writeRate, writeBurst }
+}
+
+// 心跳超时时间，错过的心跳间隔数小于等于0时使用默认值
+func (o *serverOptions) heartbeatTimeout() time.Duration {
+	if o.heartbeatMisses <= 0 {
+		return defaultServerHeartbeatMisses * o.heartbeatInterval
+	}
+
+	return time.Duration(o.heartbeatMisses) * o.heartbeatInterval
+}
@@ -5,9 +5,11 @@ import (
 	"github.com/dobyte/due/v2/log"
 	"github.com/dobyte/due/v2/network"
 	"github.com/dobyte/due/v2/packet"
+	"net"
 	"net/http"
 	_ "net/http/pprof"
 	"testing"
+	"time"
 )
 
 func TestServer_Simple(t *testing.T) {
@@ -105,3 +107,46 @@ func TestServer_Benchmark(t *testing.T) {
 
 	select {}
 }
+
+func TestServer_HeartbeatTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	server := tcp.NewServer(
+		tcp.WithServerListenAddr(addr),
+		tcp.WithServerHeartbeatInterval(50*time.Millisecond),
+		tcp.WithServerHeartbeatMisses(3),
+	)
+
+	disconnected := make(chan time.Time, 1)
+
+	server.OnDisconnect(func(conn network.Conn) {
+		disconnected <- time.Now()
+	})
+
+	if err = server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	start := time.Now()
+
+	select {
+	case at := <-disconnected:
+		if elapsed := at.Sub(start); elapsed < 150*time.Millisecond {
+			t.Fatalf("connection closed before missing 3 heartbeats: %v", elapsed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("silent connection was not closed")
+	}
+}
@@ -24,7 +24,9 @@ func NewClient(opts ...ClientOption) network.Client {
 	}
 
 	return &client{opts: o, dialer: &websocket.Dialer{
-		HandshakeTimeout: o.handshakeTimeout,
+		HandshakeTimeout:  o.handshakeTimeout,
+		EnableCompression: o.compression,
+		Subprotocols:      o.codecs,
 	}}
 }
 
@@ -43,6 +45,13 @@ func (c *client) Dial(addr ...string) (network.Conn, error) {
 		return nil, err
 	}
 
+	if c.opts.compression {
+		if err = conn.SetCompressionLevel(c.opts.compressionLevel); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
 	return newClientConn(atomic.AddInt64(&c.id, 1), conn, c), nil
 }
 
@@ -27,9 +27,13 @@ type clientConn struct {
 	lastHeartbeatTime int64           // 上次心跳时间
 	done              chan struct{}   // 写入完成信号
 	close             chan struct{}   // 关闭信号
+	codec             string          // 协商的编解码器
 }
 
-var _ network.Conn = &clientConn{}
+var (
+	_ network.Conn      = &clientConn{}
+	_ network.CodecConn = &clientConn{}
+)
 
 func newClientConn(id int64, conn *websocket.Conn, client *client) network.Conn {
 	c := &clientConn{
@@ -42,6 +46,7 @@ func newClientConn(id int64, conn *websocket.Conn, client *client) network.Conn
 		lastHeartbeatTime: xtime.Now().UnixNano(),
 		done:              make(chan struct{}),
 		close:             make(chan struct{}),
+		codec:             conn.Subprotocol(),
 	}
 
 	xcall.Go(c.read)
@@ -105,6 +110,11 @@ func (c *clientConn) Push(msg []byte) (err error) {
 	return
 }
 
+// Codec 获取握手阶段协商的编解码器名称，未协商时返回空字符串
+func (c *clientConn) Codec() string {
+	return c.codec
+}
+
 // State 获取连接状态
 func (c *clientConn) State() network.ConnState {
 	return network.ConnState(atomic.LoadInt32(&c.state))
@@ -383,7 +393,7 @@ func (c *clientConn) doWrite(conn *websocket.Conn, r chWrite) bool {
 		}
 	}
 
-	if err := conn.WriteMessage(websocket.BinaryMessage, r.msg); err != nil {
+	if err := writeMessage(conn, r.msg, c.client.opts.compression, c.client.opts.compressionThreshold); err != nil {
 		if !errors.Is(err, net.ErrClosed) {
 			if _, ok := err.(*websocket.CloseError); !ok {
 				log.Errorf("write message error: %v", err)
@@ -410,7 +420,7 @@ func (c *clientConn) doHandleHeartbeat(conn *websocket.Conn) bool {
 			log.Errorf("pack heartbeat message error: %v", err)
 		} else {
 			// send heartbeat packet
-			if err := conn.WriteMessage(websocket.BinaryMessage, heartbeat); err != nil {
+			if err := writeMessage(conn, heartbeat, c.client.opts.compression, c.client.opts.compressionThreshold); err != nil {
 				log.Errorf("write heartbeat message error: %v", err)
 			}
 		}
@@ -6,31 +6,46 @@ import (
 )
 
 const (
-	defaultClientDialUrl           = "ws://127.0.0.1:3553"
-	defaultClientHandshakeTimeout  = "10s"
-	defaultClientHeartbeatInterval = "10s"
+	defaultClientDialUrl              = "ws://127.0.0.1:3553"
+	defaultClientHandshakeTimeout     = "10s"
+	defaultClientHeartbeatInterval    = "10s"
+	defaultClientCompression          = false
+	defaultClientCompressionLevel     = 1
+	defaultClientCompressionThreshold = 512
 )
 
 const (
-	defaultClientDialUrlKey           = "etc.network.ws.client.url"
-	defaultClientHandshakeTimeoutKey  = "etc.network.ws.client.handshakeTimeout"
-	defaultClientHeartbeatIntervalKey = "etc.network.ws.client.heartbeatInterval"
+	defaultClientDialUrlKey              = "etc.network.ws.client.url"
+	defaultClientHandshakeTimeoutKey     = "etc.network.ws.client.handshakeTimeout"
+	defaultClientHeartbeatIntervalKey    = "etc.network.ws.client.heartbeatInterval"
+	defaultClientCompressionKey          = "etc.network.ws.client.compression"
+	defaultClientCompressionLevelKey     = "etc.network.ws.client.compressionLevel"
+	defaultClientCompressionThresholdKey = "etc.network.ws.client.compressionThreshold"
+	defaultClientCodecsKey               = "etc.network.ws.client.codecs"
 )
 
 type ClientOption func(o *clientOptions)
 
 type clientOptions struct {
-	url               string        // 拨号地址
-	msgType           string        // 默认消息类型，text | binary
-	handshakeTimeout  time.Duration // 握手超时时间
-	heartbeatInterval time.Duration // 心跳间隔时间，默认10s
+	url                  string        // 拨号地址
+	msgType              string        // 默认消息类型，text | binary
+	handshakeTimeout     time.Duration // 握手超时时间
+	heartbeatInterval    time.Duration // 心跳间隔时间，默认10s
+	compression          bool          // 是否协商启用permessage-deflate压缩，默认false
+	compressionLevel     int           // 压缩级别，取值范围[-2,9]，默认1
+	compressionThreshold int           // 压缩阈值，小于该字节数的消息不压缩，默认512
+	codecs               []string      // 期望协商的编解码器，按优先级排序
 }
 
 func defaultClientOptions() *clientOptions {
 	return &clientOptions{
-		url:               etc.Get(defaultClientDialUrlKey, defaultClientDialUrl).String(),
-		handshakeTimeout:  etc.Get(defaultClientHandshakeTimeoutKey, defaultClientHandshakeTimeout).Duration(),
-		heartbeatInterval: etc.Get(defaultClientHeartbeatIntervalKey, defaultClientHeartbeatInterval).Duration(),
+		url:                  etc.Get(defaultClientDialUrlKey, defaultClientDialUrl).String(),
+		handshakeTimeout:     etc.Get(defaultClientHandshakeTimeoutKey, defaultClientHandshakeTimeout).Duration(),
+		heartbeatInterval:    etc.Get(defaultClientHeartbeatIntervalKey, defaultClientHeartbeatInterval).Duration(),
+		compression:          etc.Get(defaultClientCompressionKey, defaultClientCompression).Bool(),
+		compressionLevel:     etc.Get(defaultClientCompressionLevelKey, defaultClientCompressionLevel).Int(),
+		compressionThreshold: etc.Get(defaultClientCompressionThresholdKey, defaultClientCompressionThreshold).Int(),
+		codecs:               etc.Get(defaultClientCodecsKey).Strings(),
 	}
 }
 
@@ -48,3 +63,23 @@ func WithClientHandshakeTimeout(handshakeTimeout time.Duration) ClientOption {
 func WithClientHeartbeatInterval(heartbeatInterval time.Duration) ClientOption {
 	return func(o *clientOptions) { o.heartbeatInterval = heartbeatInterval }
 }
+
+// WithClientCompression 设置是否协商启用permessage-deflate压缩
+func WithClientCompression(compression bool) ClientOption {
+	return func(o *clientOptions) { o.compression = compression }
+}
+
+// WithClientCompressionLevel 设置压缩级别
+func WithClientCompressionLevel(compressionLevel int) ClientOption {
+	return func(o *clientOptions) { o.compressionLevel = compressionLevel }
+}
+
+// WithClientCompressionThreshold 设置压缩阈值，小于该字节数的消息不进行压缩
+func WithClientCompressionThreshold(compressionThreshold int) ClientOption {
+	return func(o *clientOptions) { o.compressionThreshold = compressionThreshold }
+}
+
+// WithClientCodecs 设置期望协商的编解码器，按优先级排序，服务器选择的编解码器可通过连接的Codec方法获取
+func WithClientCodecs(codecs ...string) ClientOption {
+	return func(o *clientOptions) { o.codecs = codecs }
+}
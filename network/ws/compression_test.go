@@ -0,0 +1,65 @@
+package ws_test
+
+import (
+	"bytes"
+	"github.com/dobyte/due/network/ws/v2"
+	"github.com/dobyte/due/v2/network"
+	"github.com/dobyte/due/v2/packet"
+	"testing"
+	"time"
+)
+
+func TestCompression(t *testing.T) {
+	server := ws.NewServer(
+		ws.WithServerListenAddr("127.0.0.1:3563"),
+		ws.WithServerPath("/compression"),
+		ws.WithServerCompression(true),
+		ws.WithServerCompressionThreshold(64),
+	)
+	server.OnReceive(func(conn network.Conn, msg []byte) {
+		if err := conn.Push(msg); err != nil {
+			t.Error(err)
+		}
+	})
+
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	received := make(chan []byte, 2)
+
+	client := ws.NewClient(
+		ws.WithClientCompression(true),
+		ws.WithClientCompressionThreshold(64),
+	)
+	client.OnReceive(func(conn network.Conn, msg []byte) {
+		received <- msg
+	})
+
+	conn, err := client.Dial("ws://127.0.0.1:3563/compression")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(true)
+
+	for _, buffer := range [][]byte{[]byte("tiny"), bytes.Repeat([]byte("compressible"), 256)} {
+		msg, err := packet.PackMessage(&packet.Message{Seq: 1, Route: 1, Buffer: buffer})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = conn.Push(msg); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case data := <-received:
+			if !bytes.Equal(data, msg) {
+				t.Fatalf("echo mismatch, want %d bytes got %d bytes", len(msg), len(data))
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatal("wait echo timeout")
+		}
+	}
+}
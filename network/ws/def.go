@@ -1,5 +1,9 @@
 package ws
 
+import (
+	"github.com/gorilla/websocket"
+)
+
 const protocol = "ws"
 
 const (
@@ -12,3 +16,12 @@ type chWrite struct {
 	typ int
 	msg []byte
 }
+
+// 写入二进制消息；当连接已协商permessage-deflate时，仅对不小于阈值的消息进行压缩
+func writeMessage(conn *websocket.Conn, msg []byte, compression bool, threshold int) error {
+	if compression {
+		conn.EnableWriteCompression(len(msg) >= threshold)
+	}
+
+	return conn.WriteMessage(websocket.BinaryMessage, msg)
+}
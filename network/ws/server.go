@@ -109,8 +109,9 @@ func (s *server) serve() {
 	upgrader := websocket.Upgrader{
 		ReadBufferSize:    4096,
 		WriteBufferSize:   4096,
-		EnableCompression: false,
+		EnableCompression: s.opts.compression,
 		CheckOrigin:       s.opts.checkOrigin,
+		Subprotocols:      s.opts.codecs,
 	}
 
 	http.HandleFunc(s.opts.path, func(w http.ResponseWriter, r *http.Request) {
@@ -130,6 +131,14 @@ func (s *server) serve() {
 			return
 		}
 
+		if s.opts.compression {
+			if err = conn.SetCompressionLevel(s.opts.compressionLevel); err != nil {
+				log.Errorf("websocket set compression level error: %v", err)
+				_ = conn.Close()
+				return
+			}
+		}
+
 		if err = s.connMgr.allocate(conn); err != nil {
 			log.Errorf("connection allocate error: %v", err)
 			_ = conn.Close()
@@ -8,6 +8,7 @@
 package ws
 
 import (
+	"github.com/dobyte/due/v2/core/limiter"
 	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/log"
 	"github.com/dobyte/due/v2/network"
@@ -23,20 +24,26 @@ import (
 )
 
 type serverConn struct {
-	rw                sync.RWMutex    // 锁
-	id                int64           // 连接ID
-	uid               int64           // 用户ID
-	state             int32           // 连接状态
-	conn              *websocket.Conn // WS源连接
-	connMgr           *serverConnMgr  // 连接管理
-	chLowWrite        chan chWrite    // 低级队列
-	chHighWrite       chan chWrite    // 优先队列
-	done              chan struct{}   // 写入完成信号
-	close             chan struct{}   // 关闭信号
-	lastHeartbeatTime int64           // 上次心跳时间
+	rw                sync.RWMutex     // 锁
+	id                int64            // 连接ID
+	uid               int64            // 用户ID
+	state             int32            // 连接状态
+	conn              *websocket.Conn  // WS源连接
+	connMgr           *serverConnMgr   // 连接管理
+	chLowWrite        chan chWrite     // 低级队列
+	chHighWrite       chan chWrite     // 优先队列
+	done              chan struct{}    // 写入完成信号
+	close             chan struct{}    // 关闭信号
+	lastHeartbeatTime int64            // 上次心跳时间
+	codec             string           // 协商的编解码器
+	throttle          limiter.Throttle // 出站限速
 }
 
-var _ network.Conn = &serverConn{}
+var (
+	_ network.Conn         = &serverConn{}
+	_ network.CodecConn    = &serverConn{}
+	_ network.ThrottleConn = &serverConn{}
+)
 
 // ID 获取连接ID
 func (c *serverConn) ID() int64 {
@@ -86,6 +93,21 @@ func (c *serverConn) Push(msg []byte) (err error) {
 	return
 }
 
+// Codec 获取握手阶段协商的编解码器名称，未协商时返回空字符串
+func (c *serverConn) Codec() string {
+	return c.codec
+}
+
+// SetWriteRate 设置出站速率（字节/秒）及突发容量（字节），rate小于等于0时取消限速
+func (c *serverConn) SetWriteRate(rate, burst int) {
+	c.throttle.SetRate(rate, burst)
+}
+
+// Throttled 获取因限速而等待的累计时长
+func (c *serverConn) Throttled() time.Duration {
+	return c.throttle.Throttled()
+}
+
 // State 获取连接状态
 func (c *serverConn) State() network.ConnState {
 	return network.ConnState(atomic.LoadInt32(&c.state))
@@ -94,6 +116,9 @@ func (c *serverConn) State() network.ConnState {
 // Close 关闭连接
 func (c *serverConn) Close(force ...bool) error {
 	if len(force) > 0 && force[0] {
+		if c.connMgr.server.opts.closeLinger > 0 {
+			return c.lingerClose(true)
+		}
 		return c.forceClose(true)
 	} else {
 		return c.graceClose(true)
@@ -159,11 +184,13 @@ func (c *serverConn) init(cm *serverConnMgr, id int64, conn *websocket.Conn) {
 	c.id = id
 	c.conn = conn
 	c.connMgr = cm
+	c.codec = conn.Subprotocol()
 	c.chLowWrite = make(chan chWrite, 4096)
 	c.chHighWrite = make(chan chWrite, 1024)
-	c.done = make(chan struct{})
+	c.done = make(chan struct{}, 1)
 	c.close = make(chan struct{})
 	c.lastHeartbeatTime = xtime.Now().UnixNano()
+	c.throttle.Reset(c.connMgr.server.opts.writeRate, c.connMgr.server.opts.writeBurst)
 	atomic.StoreInt64(&c.uid, 0)
 	atomic.StoreInt32(&c.state, int32(network.ConnOpened))
 
@@ -198,8 +225,46 @@ func (c *serverConn) graceClose(isNeedRecycle bool) error {
 	c.chLowWrite <- chWrite{typ: closeSig}
 	c.rw.RUnlock()
 
-	<-c.done
+	c.waitDone()
+
+	return c.doClose(isNeedRecycle)
+}
+
+// 逗留关闭，关闭逗留时间内优先发送优先队列中剩余的消息，其次发送低级队列中剩余的消息，超时后剩余的消息将被丢弃
+func (c *serverConn) lingerClose(isNeedRecycle bool) error {
+	if !atomic.CompareAndSwapInt32(&c.state, int32(network.ConnOpened), int32(network.ConnHanged)) {
+		return c.forceClose(isNeedRecycle)
+	}
+
+	c.rw.RLock()
+	c.chHighWrite <- chWrite{typ: closeSig}
+	c.rw.RUnlock()
+
+	c.waitDone()
+
+	return c.doClose(isNeedRecycle)
+}
+
+// 等待写入完成，设置了关闭逗留时间时最多等待逗留时间，超时后关闭连接将中断阻塞中的写入
+func (c *serverConn) waitDone() {
+	linger := c.connMgr.server.opts.closeLinger
+	if linger <= 0 {
+		<-c.done
+		return
+	}
+
+	timer := time.NewTimer(linger)
+	defer timer.Stop()
+
+	select {
+	case <-c.done:
+	case <-timer.C:
+		log.Warnf("connection close linger timeout, cid: %d", c.id)
+	}
+}
 
+// 关闭已挂起的连接
+func (c *serverConn) doClose(isNeedRecycle bool) error {
 	if !atomic.CompareAndSwapInt32(&c.state, int32(network.ConnHanged), int32(network.ConnClosed)) {
 		return errors.ErrConnectionNotHanged
 	}
@@ -382,8 +447,12 @@ func (c *serverConn) write() {
 // 执行写入操作
 func (c *serverConn) doWrite(conn *websocket.Conn, r chWrite) bool {
 	if r.typ == closeSig {
+		c.doFlush(conn)
+
 		c.rw.RLock()
-		c.done <- struct{}{}
+		if !c.isClosed() {
+			c.done <- struct{}{}
+		}
 		c.rw.RUnlock()
 		return false
 	}
@@ -399,9 +468,11 @@ func (c *serverConn) doWrite(conn *websocket.Conn, r chWrite) bool {
 		} else {
 			r.msg = msg
 		}
+	} else if !c.throttle.Wait(len(r.msg), c.close) {
+		return false
 	}
 
-	if err := conn.WriteMessage(websocket.BinaryMessage, r.msg); err != nil {
+	if err := writeMessage(conn, r.msg, c.connMgr.server.opts.compression, c.connMgr.server.opts.compressionThreshold); err != nil {
 		if !errors.Is(err, net.ErrClosed) {
 			if _, ok := err.(*websocket.CloseError); !ok {
 				log.Errorf("write message error: %v", err)
@@ -412,9 +483,39 @@ func (c *serverConn) doWrite(conn *websocket.Conn, r chWrite) bool {
 	return true
 }
 
+// 发送写入队列中剩余的消息，仅在设置了关闭逗留时间时生效
+// 优先发送优先队列中的消息，超过逗留时间后剩余的消息随写入队列一同丢弃
+func (c *serverConn) doFlush(conn *websocket.Conn) {
+	linger := c.connMgr.server.opts.closeLinger
+	if linger <= 0 {
+		return
+	}
+
+	deadline := time.Now().Add(linger)
+	_ = conn.SetWriteDeadline(deadline)
+
+	for _, ch := range []chan chWrite{c.chHighWrite, c.chLowWrite} {
+	loop:
+		for time.Now().Before(deadline) {
+			select {
+			case r, ok := <-ch:
+				if !ok || c.isClosed() {
+					return
+				}
+
+				if r.typ != closeSig {
+					c.doWrite(conn, r)
+				}
+			default:
+				break loop
+			}
+		}
+	}
+}
+
 // 处理心跳
 func (c *serverConn) doHandleHeartbeat(conn *websocket.Conn) bool {
-	deadline := xtime.Now().Add(-2 * c.connMgr.server.opts.heartbeatInterval).UnixNano()
+	deadline := xtime.Now().Add(-c.connMgr.server.opts.heartbeatTimeout()).UnixNano()
 	if atomic.LoadInt64(&c.lastHeartbeatTime) < deadline {
 		log.Debugf("connection heartbeat timeout, cid: %d", c.id)
 		_ = c.forceClose(true)
@@ -429,7 +530,7 @@ func (c *serverConn) doHandleHeartbeat(conn *websocket.Conn) bool {
 				log.Errorf("pack heartbeat message error: %v", err)
 			} else {
 				// send heartbeat packet
-				if err := conn.WriteMessage(websocket.BinaryMessage, heartbeat); err != nil {
+				if err := writeMessage(conn, heartbeat, c.connMgr.server.opts.compression, c.connMgr.server.opts.compressionThreshold); err != nil {
 					log.Errorf("write heartbeat message error: %v", err)
 				}
 			}
@@ -7,25 +7,40 @@ import (
 )
 
 const (
-	defaultServerAddr               = ":3553"
-	defaultServerPath               = "/"
-	defaultServerMaxConnNum         = 5000
-	defaultServerCheckOrigin        = "*"
-	defaultServerHandshakeTimeout   = "10s"
-	defaultServerHeartbeatInterval  = "10s"
-	defaultServerHeartbeatMechanism = "resp"
+	defaultServerAddr                 = ":3553"
+	defaultServerPath                 = "/"
+	defaultServerMaxConnNum           = 5000
+	defaultServerCheckOrigin          = "*"
+	defaultServerHandshakeTimeout     = "10s"
+	defaultServerHeartbeatInterval    = "10s"
+	defaultServerHeartbeatMechanism   = "resp"
+	defaultServerHeartbeatMisses      = 2
+	defaultServerCompression          = false
+	defaultServerCompressionLevel     = 1
+	defaultServerCompressionThreshold = 512
+	defaultServerCloseLinger          = "0s"
+	defaultServerWriteRate            = 0
+	defaultServerWriteBurst           = 0
 )
 
 const (
-	defaultServerAddrKey               = "etc.network.ws.server.addr"
-	defaultServerPathKey               = "etc.network.ws.server.path"
-	defaultServerMaxConnNumKey         = "etc.network.ws.server.maxConnNum"
-	defaultServerCheckOriginsKey       = "etc.network.ws.server.origins"
-	defaultServerKeyFileKey            = "etc.network.ws.server.keyFile"
-	defaultServerCertFileKey           = "etc.network.ws.server.certFile"
-	defaultServerHandshakeTimeoutKey   = "etc.network.ws.server.handshakeTimeout"
-	defaultServerHeartbeatIntervalKey  = "etc.network.ws.server.heartbeatInterval"
-	defaultServerHeartbeatMechanismKey = "etc.network.ws.server.heartbeatMechanism"
+	defaultServerAddrKey                 = "etc.network.ws.server.addr"
+	defaultServerPathKey                 = "etc.network.ws.server.path"
+	defaultServerMaxConnNumKey           = "etc.network.ws.server.maxConnNum"
+	defaultServerCheckOriginsKey         = "etc.network.ws.server.origins"
+	defaultServerKeyFileKey              = "etc.network.ws.server.keyFile"
+	defaultServerCertFileKey             = "etc.network.ws.server.certFile"
+	defaultServerHandshakeTimeoutKey     = "etc.network.ws.server.handshakeTimeout"
+	defaultServerHeartbeatIntervalKey    = "etc.network.ws.server.heartbeatInterval"
+	defaultServerHeartbeatMechanismKey   = "etc.network.ws.server.heartbeatMechanism"
+	defaultServerHeartbeatMissesKey      = "etc.network.ws.server.heartbeatMisses"
+	defaultServerCompressionKey          = "etc.network.ws.server.compression"
+	defaultServerCompressionLevelKey     = "etc.network.ws.server.compressionLevel"
+	defaultServerCompressionThresholdKey = "etc.network.ws.server.compressionThreshold"
+	defaultServerCloseLingerKey          = "etc.network.ws.server.closeLinger"
+	defaultServerCodecsKey               = "etc.network.ws.server.codecs"
+	defaultServerWriteRateKey            = "etc.network.ws.server.writeRate"
+	defaultServerWriteBurstKey           = "etc.network.ws.server.writeBurst"
 )
 
 const (
@@ -40,15 +55,23 @@ type ServerOption func(o *serverOptions)
 type CheckOriginFunc func(r *http.Request) bool
 
 type serverOptions struct {
-	addr               string             // 监听地址
-	maxConnNum         int                // 最大连接数
-	certFile           string             // 证书文件
-	keyFile            string             // 秘钥文件
-	path               string             // 路径，默认为"/"
-	checkOrigin        CheckOriginFunc    // 跨域检测
-	handshakeTimeout   time.Duration      // 握手超时时间，默认10s
-	heartbeatInterval  time.Duration      // 心跳间隔时间，默认10s
-	heartbeatMechanism HeartbeatMechanism // 心跳机制，默认resp
+	addr                 string             // 监听地址
+	maxConnNum           int                // 最大连接数
+	certFile             string             // 证书文件
+	keyFile              string             // 秘钥文件
+	path                 string             // 路径，默认为"/"
+	checkOrigin          CheckOriginFunc    // 跨域检测
+	handshakeTimeout     time.Duration      // 握手超时时间，默认10s
+	heartbeatInterval    time.Duration      // 心跳间隔时间，默认10s
+	heartbeatMechanism   HeartbeatMechanism // 心跳机制，默认resp
+	heartbeatMisses      int                // 心跳超时允许错过的心跳间隔数，超过后关闭连接，默认2
+	compression          bool               // 是否协商启用permessage-deflate压缩，默认false
+	compressionLevel     int                // 压缩级别，取值范围[-2,9]，默认1
+	compressionThreshold int                // 压缩阈值，小于该字节数的消息不压缩，默认512
+	closeLinger          time.Duration      // 关闭逗留时间，关闭连接前在该时间内尽力发送写入队列中剩余的消息，默认0s不逗留
+	codecs               []string           // 支持协商的编解码器，通过Sec-WebSocket-Protocol在握手阶段协商，按优先级排序
+	writeRate            int                // 单连接出站速率（字节/秒），默认0不限速
+	writeBurst           int                // 单连接出站突发容量（字节），默认0时与出站速率相同
 }
 
 func defaultServerOptions() *serverOptions {
@@ -69,15 +92,23 @@ func defaultServerOptions() *serverOptions {
 	}
 
 	return &serverOptions{
-		addr:               etc.Get(defaultServerAddrKey, defaultServerAddr).String(),
-		maxConnNum:         etc.Get(defaultServerMaxConnNumKey, defaultServerMaxConnNum).Int(),
-		path:               etc.Get(defaultServerPathKey, defaultServerPath).String(),
-		checkOrigin:        checkOrigin,
-		keyFile:            etc.Get(defaultServerKeyFileKey).String(),
-		certFile:           etc.Get(defaultServerCertFileKey).String(),
-		handshakeTimeout:   etc.Get(defaultServerHandshakeTimeoutKey, defaultServerHandshakeTimeout).Duration(),
-		heartbeatInterval:  etc.Get(defaultServerHeartbeatIntervalKey, defaultServerHeartbeatInterval).Duration(),
-		heartbeatMechanism: HeartbeatMechanism(etc.Get(defaultServerHeartbeatMechanismKey, defaultServerHeartbeatMechanism).String()),
+		addr:                 etc.Get(defaultServerAddrKey, defaultServerAddr).String(),
+		maxConnNum:           etc.Get(defaultServerMaxConnNumKey, defaultServerMaxConnNum).Int(),
+		path:                 etc.Get(defaultServerPathKey, defaultServerPath).String(),
+		checkOrigin:          checkOrigin,
+		keyFile:              etc.Get(defaultServerKeyFileKey).String(),
+		certFile:             etc.Get(defaultServerCertFileKey).String(),
+		handshakeTimeout:     etc.Get(defaultServerHandshakeTimeoutKey, defaultServerHandshakeTimeout).Duration(),
+		heartbeatInterval:    etc.Get(defaultServerHeartbeatIntervalKey, defaultServerHeartbeatInterval).Duration(),
+		heartbeatMechanism:   HeartbeatMechanism(etc.Get(defaultServerHeartbeatMechanismKey, defaultServerHeartbeatMechanism).String()),
+		heartbeatMisses:      etc.Get(defaultServerHeartbeatMissesKey, defaultServerHeartbeatMisses).Int(),
+		compression:          etc.Get(defaultServerCompressionKey, defaultServerCompression).Bool(),
+		compressionLevel:     etc.Get(defaultServerCompressionLevelKey, defaultServerCompressionLevel).Int(),
+		compressionThreshold: etc.Get(defaultServerCompressionThresholdKey, defaultServerCompressionThreshold).Int(),
+		closeLinger:          etc.Get(defaultServerCloseLingerKey, defaultServerCloseLinger).Duration(),
+		codecs:               etc.Get(defaultServerCodecsKey).Strings(),
+		writeRate:            etc.Get(defaultServerWriteRateKey, defaultServerWriteRate).Int(),
+		writeBurst:           etc.Get(defaultServerWriteBurstKey, defaultServerWriteBurst).Int(),
 	}
 }
 
@@ -120,3 +151,49 @@ func WithServerHeartbeatInterval(heartbeatInterval time.Duration) ServerOption {
 func WithServerHeartbeatMechanism(heartbeatMechanism HeartbeatMechanism) ServerOption {
 	return func(o *serverOptions) { o.heartbeatMechanism = heartbeatMechanism }
 }
+
+// WithServerHeartbeatMisses 设置心跳超时允许错过的心跳间隔数，连续该数量的心跳间隔内未收到任何消息（含心跳）时关闭连接
+func WithServerHeartbeatMisses(heartbeatMisses int) ServerOption {
+	return func(o *serverOptions) { o.heartbeatMisses = heartbeatMisses }
+}
+
+// WithServerCompression 设置是否协商启用permessage-deflate压缩
+func WithServerCompression(compression bool) ServerOption {
+	return func(o *serverOptions) { o.compression = compression }
+}
+
+// WithServerCompressionLevel 设置压缩级别
+func WithServerCompressionLevel(compressionLevel int) ServerOption {
+	return func(o *serverOptions) { o.compressionLevel = compressionLevel }
+}
+
+// WithServerCompressionThreshold 设置压缩阈值，小于该字节数的消息不进行压缩
+func WithServerCompressionThreshold(compressionThreshold int) ServerOption {
+	return func(o *serverOptions) { o.compressionThreshold = compressionThreshold }
+}
+
+// WithServerCloseLinger 设置关闭逗留时间，关闭连接前在该时间内尽力发送写入队列中剩余的消息，优先发送优先队列中的消息
+func WithServerCloseLinger(closeLinger time.Duration) ServerOption {
+	return func(o *serverOptions) { o.closeLinger = closeLinger }
+}
+
+// WithServerCodecs 设置支持协商的编解码器，按优先级排序
+// 客户端通过Sec-WebSocket-Protocol携带期望的编解码器，服务器选择首个支持的编解码器作为该连接的编解码器
+func WithServerCodecs(codecs ...string) ServerOption {
+	return func(o *serverOptions) { o.codecs = codecs }
+}
+
+// WithServerWriteRate 设置单连接出站速率（字节/秒）及突发容量（字节），突发容量为0时与出站速率相同
+// 限速按字节进行，超出速率的消息将在写入协程中等待，不会打乱消息顺序
+func WithServerWriteRate(writeRate, writeBurst int) ServerOption {
+	return func(o *serverOptions) { o.writeRate, o.writeBurst = writeRate, writeBurst }
+}
+
+// 心跳超时时间，错过的心跳间隔数小于等于0时使用默认值
+func (o *serverOptions) heartbeatTimeout() time.Duration {
+	if o.heartbeatMisses <= 0 {
+		return defaultServerHeartbeatMisses * o.heartbeatInterval
+	}
+
+	return time.Duration(o.heartbeatMisses) * o.heartbeatInterval
+}
@@ -14,7 +14,9 @@ import (
 	"github.com/dobyte/due/v2/packet"
 	"github.com/dobyte/due/v2/utils/xcall"
 	"net/http"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestServer(t *testing.T) {
@@ -110,3 +112,64 @@ func TestServer_Benchmark(t *testing.T) {
 
 	select {}
 }
+
+func TestServer_CloseLinger(t *testing.T) {
+	const total = 100
+
+	server := ws.NewServer(
+		ws.WithServerListenAddr(":3599"),
+		ws.WithServerCloseLinger(time.Second),
+	)
+	server.OnConnect(func(conn network.Conn) {
+		for i := 0; i < total; i++ {
+			msg, err := packet.PackMessage(&packet.Message{Seq: int32(i), Route: 1, Buffer: []byte("bye~~")})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if err = conn.Push(msg); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		xcall.Go(func() {
+			if err := conn.Close(true); err != nil {
+				t.Error(err)
+			}
+		})
+	})
+
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	var (
+		received atomic.Int32
+		closed   = make(chan struct{})
+	)
+
+	client := ws.NewClient(ws.WithClientDialUrl("ws://127.0.0.1:3599"))
+	client.OnReceive(func(conn network.Conn, msg []byte) {
+		received.Add(1)
+	})
+	client.OnDisconnect(func(conn network.Conn) {
+		close(closed)
+	})
+
+	if _, err := client.Dial(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-closed:
+	case <-time.After(3 * time.Second):
+		t.Fatal("connection is not closed")
+	}
+
+	if n := received.Load(); n != total {
+		t.Fatalf("expect %d messages, got %d", total, n)
+	}
+}
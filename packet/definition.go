@@ -0,0 +1,75 @@
+package packet
+
+import (
+	"fmt"
+	"github.com/dobyte/due/v2/errors"
+	"sort"
+	"sync"
+)
+
+// Definition 消息定义
+type Definition struct {
+	Route int32  // 路由ID
+	Name  string // 名称，用作统计、日志等工具的路由标签
+}
+
+var definitions struct {
+	rw    sync.RWMutex
+	items map[int32]*Definition
+}
+
+func init() {
+	definitions.items = make(map[int32]*Definition)
+}
+
+// Register 注册消息定义，路由ID或名称与已注册的定义冲突时返回errors.ErrRouteConflict
+// 统计时已注册定义的路由以名称作为标签，可将路由ID映射为有限的名称集合以控制统计标签数量
+func Register(defs ...*Definition) error {
+	definitions.rw.Lock()
+	defer definitions.rw.Unlock()
+
+	for _, def := range defs {
+		if def.Name == "" || def.Name == otherRouteLabel {
+			return errors.ErrInvalidArgument
+		}
+
+		if exists, ok := definitions.items[def.Route]; ok {
+			return errors.NewError(fmt.Sprintf("route %d is already registered as %s", def.Route, exists.Name), errors.ErrRouteConflict)
+		}
+
+		for _, exists := range definitions.items {
+			if exists.Name == def.Name {
+				return errors.NewError(fmt.Sprintf("name %s is already registered by route %d", def.Name, exists.Route), errors.ErrRouteConflict)
+			}
+		}
+
+		definitions.items[def.Route] = def
+	}
+
+	return nil
+}
+
+// Lookup 通过路由ID查找消息定义
+func Lookup(route int32) (*Definition, bool) {
+	definitions.rw.RLock()
+	defer definitions.rw.RUnlock()
+
+	def, ok := definitions.items[route]
+
+	return def, ok
+}
+
+// Definitions 获取所有消息定义，按路由ID排序
+func Definitions() []*Definition {
+	definitions.rw.RLock()
+	defer definitions.rw.RUnlock()
+
+	defs := make([]*Definition, 0, len(definitions.items))
+	for _, def := range definitions.items {
+		defs = append(defs, def)
+	}
+
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Route < defs[j].Route })
+
+	return defs
+}
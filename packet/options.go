@@ -29,6 +29,11 @@ const (
 	defaultBufferBytes        = 5000
 	defaultHeartbeatTime      = false
 	defaultHeartbeatTimeBytes = 8
+	defaultMetrics            = false
+	defaultLargeBytes         = 0
+	defaultPoolSize           = 0
+	defaultRouteOther         = true
+	defaultRouteLabels        = 100
 )
 
 const (
@@ -37,6 +42,11 @@ const (
 	defaultSeqBytesKey      = "etc.packet.seqBytes"
 	defaultBufferBytesKey   = "etc.packet.bufferBytes"
 	defaultHeartbeatTimeKey = "etc.packet.heartbeatTime"
+	defaultMetricsKey       = "etc.packet.metrics"
+	defaultLargeBytesKey    = "etc.packet.largeBytes"
+	defaultPoolSizeKey      = "etc.packet.poolSize"
+	defaultRouteOtherKey    = "etc.packet.routeOther"
+	defaultRouteLabelsKey   = "etc.packet.routeLabels"
 )
 
 type options struct {
@@ -59,6 +69,27 @@ type options struct {
 	// 是否携带心跳时间
 	// 默认为false
 	heartbeatTime bool
+
+	// 是否开启消息大小统计，开启后记录读取及打包的消息帧大小直方图
+	// 默认为false
+	metrics bool
+
+	// 大消息告警字节数，消息帧超过该值时输出告警日志（包含路由），应小于消息字节数上限；为0时不告警
+	// 默认为0
+	largeBytes int
+
+	// 打包器对象池每个容量档位最多缓存的空闲对象数量，超出的对象直接交由GC回收，用于在突发流量后限制对象池占用的内存
+	// 设置后打包消息帧（包含消息内容）及读取消息的缓冲均从有界对象池分配；为0时使用不限数量的对象池，打包时仅消息头来自全局对象池
+	// 默认为0
+	poolSize int
+
+	// 是否将未注册消息定义的路由统一归入other标签，为false时以路由ID作为标签
+	// 默认为true
+	routeOther bool
+
+	// 以路由ID作为标签时最多统计的路由标签数量，超出后的路由归入other标签
+	// 默认为100
+	routeLabels int
 }
 
 type Option func(o *options)
@@ -70,6 +101,11 @@ func defaultOptions() *options {
 		seqBytes:      etc.Get(defaultSeqBytesKey, defaultSeqBytes).Int(),
 		bufferBytes:   etc.Get(defaultBufferBytesKey, defaultBufferBytes).Int(),
 		heartbeatTime: etc.Get(defaultHeartbeatTimeKey, defaultHeartbeatTime).Bool(),
+		metrics:       etc.Get(defaultMetricsKey, defaultMetrics).Bool(),
+		largeBytes:    etc.Get(defaultLargeBytesKey, defaultLargeBytes).Int(),
+		poolSize:      etc.Get(defaultPoolSizeKey, defaultPoolSize).Int(),
+		routeOther:    etc.Get(defaultRouteOtherKey, defaultRouteOther).Bool(),
+		routeLabels:   etc.Get(defaultRouteLabelsKey, defaultRouteLabels).Int(),
 	}
 
 	endian := etc.Get(defaultEndianKey, bigEndian).String()
@@ -107,3 +143,28 @@ func WithBufferBytes(bufferBytes int) Option {
 func WithHeartbeatTime(heartbeatTime bool) Option {
 	return func(o *options) { o.heartbeatTime = heartbeatTime }
 }
+
+// WithMetrics 是否开启消息大小统计
+func WithMetrics(metrics bool) Option {
+	return func(o *options) { o.metrics = metrics }
+}
+
+// WithLargeBytes 设置大消息告警字节数
+func WithLargeBytes(largeBytes int) Option {
+	return func(o *options) { o.largeBytes = largeBytes }
+}
+
+// WithPoolSize 设置打包器对象池每个容量档位最多缓存的空闲对象数量
+func WithPoolSize(poolSize int) Option {
+	return func(o *options) { o.poolSize = poolSize }
+}
+
+// WithRouteOther 设置是否将未注册消息定义的路由统一归入other标签
+func WithRouteOther(other bool) Option {
+	return func(o *options) { o.routeOther = other }
+}
+
+// WithRouteLabels 设置以路由ID作为标签时最多统计的路由标签数量
+func WithRouteLabels(routeLabels int) Option {
+	return func(o *options) { o.routeLabels = routeLabels }
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/dobyte/due/v2/log"
 	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -45,12 +46,27 @@ type Packer interface {
 	CheckHeartbeat(data []byte) (bool, error)
 }
 
+type RouteChecker interface {
+	// CheckRoute 检测路由是否在打包器允许的范围内
+	CheckRoute(route int32) error
+}
+
+type MessageReleaser interface {
+	// ReleaseMessage 将ReadMessage读取的消息放回对象池，调用后不可再使用该消息
+	ReleaseMessage(data []byte)
+}
+
 type defaultPacker struct {
 	opts             *options
 	once             sync.Once
 	heartbeat        []byte
-	readerSizePool   sync.Pool
-	readerBufferPool sync.Pool
+	readerSizePool   *bytesPool         // 读取消息长度的缓冲对象池
+	readerBufferPool *bytesPool         // 读取心跳等小消息帧的缓冲对象池
+	pool             *buffer.WriterPool // 打包消息帧的有界对象池，未设置对象池数量时为nil
+	inbound          sizeHistogram
+	outbound         sizeHistogram
+	routes           sync.Map     // 路由标签 -> 路由统计计数器
+	labels           atomic.Int64 // 以路由ID作为标签的路由标签数量
 }
 
 func NewPacker(opts ...Option) *defaultPacker {
@@ -71,6 +87,14 @@ func NewPacker(opts ...Option) *defaultPacker {
 		log.Fatalf("the number of buffer bytes must be greater than or equal to 0, and give %d", o.bufferBytes)
 	}
 
+	if o.largeBytes < 0 {
+		log.Fatalf("the number of large bytes must be greater than or equal to 0, and give %d", o.largeBytes)
+	}
+
+	if o.poolSize < 0 {
+		log.Fatalf("the pool size must be greater than or equal to 0, and give %d", o.poolSize)
+	}
+
 	p := &defaultPacker{opts: o}
 
 	if !o.heartbeatTime {
@@ -85,25 +109,70 @@ func NewPacker(opts ...Option) *defaultPacker {
 		p.heartbeat = buf.Bytes()
 	}
 
-	p.readerSizePool = sync.Pool{New: func() any { return make([]byte, defaultSizeBytes) }}
+	p.readerSizePool = newBytesPool(defaultSizeBytes, o.poolSize)
+
+	p.readerBufferPool = newBytesPool(defaultSizeBytes+defaultHeaderBytes+defaultHeartbeatTimeBytes, o.poolSize)
 
-	p.readerBufferPool = sync.Pool{New: func() any {
-		return make([]byte, defaultSizeBytes+defaultHeaderBytes+o.routeBytes+o.seqBytes+o.bufferBytes)
-	}}
+	if o.poolSize > 0 {
+		p.pool = buffer.NewWriterPool(p.frameCapacities(), o.poolSize)
+	}
 
 	return p
 }
 
+// 打包消息帧的对象池容量档位，最大档位可容纳消息内容达到上限的完整消息帧
+func (p *defaultPacker) frameCapacities() []int {
+	var (
+		head       = defaultSizeBytes + defaultHeaderBytes + p.opts.routeBytes + p.opts.seqBytes
+		max        = head + p.opts.bufferBytes
+		capacities = []int{head}
+	)
+
+	for c := 64; c < max; c *= 4 {
+		if c > head {
+			capacities = append(capacities, c)
+		}
+	}
+
+	if max > head {
+		capacities = append(capacities, max)
+	}
+
+	return capacities
+}
+
+// 消息帧长度上限（不包含长度字段）
+func (p *defaultPacker) maxFrameSize() uint32 {
+	size := defaultHeaderBytes + p.opts.routeBytes + p.opts.seqBytes + p.opts.bufferBytes
+
+	if heartbeat := defaultHeaderBytes + defaultHeartbeatTimeBytes; heartbeat > size {
+		size = heartbeat
+	}
+
+	return uint32(size)
+}
+
 // ReadMessage 读取消息
 func (p *defaultPacker) ReadMessage(reader interface{}) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+
 	switch r := reader.(type) {
 	case NocopyReader:
-		return p.nocopyReadMessage(r)
+		data, err = p.nocopyReadMessage(r)
 	case io.Reader:
-		return p.copyReadMessage(r)
+		data, err = p.copyReadMessage(r)
 	default:
 		return nil, errors.ErrInvalidReader
 	}
+
+	if err == nil && len(data) > 0 {
+		p.recordInbound(data)
+	}
+
+	return data, err
 }
 
 // 无拷贝读取消息
@@ -125,6 +194,10 @@ func (p *defaultPacker) nocopyReadMessage(reader NocopyReader) ([]byte, error) {
 		return nil, nil
 	}
 
+	if size > p.maxFrameSize() {
+		return nil, errors.ErrMessageTooLarge
+	}
+
 	n := int(defaultSizeBytes + size)
 
 	r, err := reader.Slice(n)
@@ -146,7 +219,8 @@ func (p *defaultPacker) nocopyReadMessage(reader NocopyReader) ([]byte, error) {
 
 // 拷贝读取消息
 func (p *defaultPacker) copyReadMessage(reader io.Reader) ([]byte, error) {
-	buf := make([]byte, defaultSizeBytes)
+	buf := p.readerSizePool.get()
+	defer p.readerSizePool.put(buf)
 
 	_, err := io.ReadFull(reader, buf)
 	if err != nil {
@@ -165,17 +239,46 @@ func (p *defaultPacker) copyReadMessage(reader io.Reader) ([]byte, error) {
 		return nil, nil
 	}
 
-	data := make([]byte, defaultSizeBytes+size)
+	if size > p.maxFrameSize() {
+		return nil, errors.ErrMessageTooLarge
+	}
+
+	var (
+		n    = defaultSizeBytes + int(size)
+		data []byte
+	)
+
+	if n <= p.readerBufferPool.size {
+		data = p.readerBufferPool.get()[:n]
+	} else {
+		data = make([]byte, n)
+	}
+
 	copy(data[:defaultSizeBytes], buf)
 
 	_, err = io.ReadFull(reader, data[defaultSizeBytes:])
 	if err != nil {
+		p.readerBufferPool.put(data)
 		return nil, err
 	}
 
 	return data, nil
 }
 
+// ReleaseMessage 将ReadMessage读取的心跳等小消息帧放回对象池，其余消息直接交由GC回收；调用后不可再使用该消息
+func (p *defaultPacker) ReleaseMessage(data []byte) {
+	p.readerBufferPool.put(data)
+}
+
+// CheckRoute 检测路由是否在打包器允许的范围内
+func (p *defaultPacker) CheckRoute(route int32) error {
+	if route > int32(1<<(8*p.opts.routeBytes-1)-1) || route < int32(-1<<(8*p.opts.routeBytes-1)) {
+		return errors.ErrRouteOverflow
+	}
+
+	return nil
+}
+
 // PackMessage 打包消息
 func (p *defaultPacker) PackMessage(message *Message) ([]byte, error) {
 	if message.Route > int32(1<<(8*p.opts.routeBytes-1)-1) || message.Route < int32(-1<<(8*p.opts.routeBytes-1)) {
@@ -238,6 +341,8 @@ func (p *defaultPacker) PackMessage(message *Message) ([]byte, error) {
 		return nil, err
 	}
 
+	p.recordOutbound(message.Route, buf.Len())
+
 	return buf.Bytes(), nil
 }
 
@@ -262,7 +367,13 @@ func (p *defaultPacker) PackBuffer(message *Message) (buffer.Buffer, error) {
 		buf  = buffer.NewNocopyBuffer()
 	)
 
-	writer := buf.Malloc(defaultSizeBytes + defaultHeaderBytes + p.opts.routeBytes + p.opts.seqBytes)
+	// 设置对象池数量时消息内容一并写入对象池分配的Writer，使整个消息帧占用的内存受对象池约束
+	var writer *buffer.Writer
+	if p.pool != nil {
+		writer = buf.MallocFrom(p.pool, defaultSizeBytes+size)
+	} else {
+		writer = buf.Malloc(defaultSizeBytes + defaultHeaderBytes + p.opts.routeBytes + p.opts.seqBytes)
+	}
 	writer.WriteInt32s(p.opts.byteOrder, int32(size))
 	writer.WriteInt8s(int8(dataBit))
 
@@ -284,7 +395,13 @@ func (p *defaultPacker) PackBuffer(message *Message) (buffer.Buffer, error) {
 		writer.WriteInt32s(p.opts.byteOrder, message.Seq)
 	}
 
-	buf.Mount(message.Buffer)
+	if p.pool != nil {
+		writer.WriteBytes(message.Buffer...)
+	} else {
+		buf.Mount(message.Buffer)
+	}
+
+	p.recordOutbound(message.Route, defaultSizeBytes+size)
 
 	return buf, nil
 }
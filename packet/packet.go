@@ -1,6 +1,9 @@
 package packet
 
-import "github.com/dobyte/due/v2/core/buffer"
+import (
+	"github.com/dobyte/due/v2/core/buffer"
+	"strconv"
+)
 
 var globalPacker Packer
 
@@ -23,6 +26,13 @@ func ReadMessage(reader interface{}) ([]byte, error) {
 	return globalPacker.ReadMessage(reader)
 }
 
+// ReleaseMessage 将ReadMessage读取的消息放回对象池，调用后不可再使用该消息；打包器未实现MessageReleaser时不做处理
+func ReleaseMessage(data []byte) {
+	if releaser, ok := globalPacker.(MessageReleaser); ok {
+		releaser.ReleaseMessage(data)
+	}
+}
+
 // PackBuffer 打包消息
 func PackBuffer(message *Message) (buffer.Buffer, error) {
 	return globalPacker.PackBuffer(message)
@@ -47,3 +57,30 @@ func PackHeartbeat() ([]byte, error) {
 func CheckHeartbeat(data []byte) (bool, error) {
 	return globalPacker.CheckHeartbeat(data)
 }
+
+// CheckRoute 检测路由是否在打包器允许的范围内，打包器未实现RouteChecker时不做检测
+func CheckRoute(route int32) error {
+	if checker, ok := globalPacker.(RouteChecker); ok {
+		return checker.CheckRoute(route)
+	}
+
+	return nil
+}
+
+// Stats 获取消息大小统计，可注册为调试组件的统计收集器；打包器未实现Statter时返回空统计
+func Stats() SizeStat {
+	if statter, ok := globalPacker.(Statter); ok {
+		return statter.Stats()
+	}
+
+	return SizeStat{}
+}
+
+// RouteLabel 获取路由的统计标签，打包器未实现RouteLabeler时返回路由ID
+func RouteLabel(route int32) string {
+	if labeler, ok := globalPacker.(RouteLabeler); ok {
+		return labeler.RouteLabel(route)
+	}
+
+	return strconv.Itoa(int(route))
+}
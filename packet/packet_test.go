@@ -2,8 +2,12 @@ package packet_test
 
 import (
 	"bytes"
+	"github.com/dobyte/due/v2/core/buffer"
+	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/packet"
 	"github.com/dobyte/due/v2/utils/xrand"
+	"runtime"
+	"sync"
 	"testing"
 )
 
@@ -128,3 +132,289 @@ func BenchmarkDefaultPacker_UnpackMessage(b *testing.B) {
 		}
 	}
 }
+
+func TestDefaultPacker_CheckRoute(t *testing.T) {
+	p := packet.NewPacker(packet.WithRouteBytes(1))
+
+	if err := p.CheckRoute(127); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.CheckRoute(128); err == nil {
+		t.Fatal("expected route overflow")
+	}
+}
+
+func TestDefaultPacker_Stats(t *testing.T) {
+	p := packet.NewPacker(
+		packet.WithMetrics(true),
+		packet.WithLargeBytes(100),
+	)
+
+	data, err := p.PackMessage(&packet.Message{
+		Seq:    1,
+		Route:  1,
+		Buffer: []byte(xrand.Letters(200)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := p.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(msg, data) {
+		t.Fatal("read message mismatch")
+	}
+
+	stats := p.Stats()
+
+	for _, hist := range []packet.SizeHistogram{stats.Inbound, stats.Outbound} {
+		if hist.Count != 1 || hist.Bytes != int64(len(data)) {
+			t.Fatalf("unexpected histogram: %+v", hist)
+		}
+
+		if hist.Buckets[1].Upper != 256 || hist.Buckets[1].Count != 1 {
+			t.Fatalf("unexpected buckets: %+v", hist.Buckets)
+		}
+	}
+}
+
+func TestDefaultPacker_PoolSize(t *testing.T) {
+	const (
+		poolSize = 16
+		workers  = 8
+		burst    = 1000
+	)
+
+	p := packet.NewPacker(packet.WithPoolSize(poolSize))
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		bufs = make([]buffer.Buffer, 0, workers*burst)
+	)
+
+	// 突发期间同时持有大量打包缓冲，随后一次性释放
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < burst; j++ {
+				buf, err := p.PackBuffer(&packet.Message{Seq: 1, Route: 1, Buffer: []byte("hello")})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				mu.Lock()
+				bufs = append(bufs, buf)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := p.PackMessage(&packet.Message{Seq: 1, Route: 1, Buffer: []byte("hello")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(bufs[0].Bytes(), data) {
+		t.Fatalf("unexpected pooled frame: %v", bufs[0].Bytes())
+	}
+
+	for _, buf := range bufs {
+		buf.Release()
+	}
+
+	// 消息帧整体从容纳其大小的最小容量档位分配
+	index := func() int {
+		for i, stat := range p.PoolStats() {
+			if stat.Capacity >= len(data) {
+				return i
+			}
+		}
+		t.Fatalf("no pool capacity fits the frame: %+v", p.PoolStats())
+		return -1
+	}()
+
+	stat := p.PoolStats()[index]
+
+	if stat.Idle != poolSize || stat.Puts != poolSize || stat.Overs != int64(workers*burst-poolSize) {
+		t.Fatalf("pool is not bounded: %+v", stat)
+	}
+
+	// 空闲列表中的Writer被复用，不足部分新建
+	for i := 0; i < poolSize*2; i++ {
+		if _, err := p.PackBuffer(&packet.Message{Seq: 1, Route: 1, Buffer: []byte("hello")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if stat = p.PoolStats()[index]; stat.Idle != 0 || stat.News != int64(workers*burst+poolSize) {
+		t.Fatalf("unexpected pool stat after reuse: %+v", stat)
+	}
+}
+
+func TestDefaultPacker_PoolSizeMemory(t *testing.T) {
+	const (
+		poolSize = 16
+		burst    = 8000
+	)
+
+	var (
+		p       = packet.NewPacker(packet.WithPoolSize(poolSize))
+		payload = []byte(xrand.Letters(4096))
+		bufs    = make([]buffer.Buffer, 0, burst)
+		limit   uint64
+	)
+
+	// 对象池最多保留每个容量档位poolSize个空闲Writer
+	for _, stat := range p.PoolStats() {
+		limit += uint64(stat.Capacity * poolSize)
+	}
+
+	heapAlloc := func() uint64 {
+		runtime.GC()
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		return m.HeapAlloc
+	}
+
+	before := heapAlloc()
+
+	for i := 0; i < burst; i++ {
+		buf, err := p.PackBuffer(&packet.Message{Seq: 1, Route: 1, Buffer: payload})
+		if err != nil {
+			t.Fatal(err)
+		}
+		bufs = append(bufs, buf)
+	}
+
+	if peak := heapAlloc(); peak-before < uint64(burst*len(payload)) {
+		t.Fatalf("payload is not held by pooled writers, peak growth: %d", peak-before)
+	}
+
+	for i, buf := range bufs {
+		buf.Release()
+		bufs[i] = nil
+	}
+
+	// 突发结束后对象池占用的内存不超过有界空闲列表的容量
+	if after := heapAlloc(); after > before && after-before > limit+1<<20 {
+		t.Fatalf("pool retains too much memory after burst, growth: %d limit: %d", after-before, limit)
+	}
+}
+
+func TestDefaultPacker_ReadMessageLimit(t *testing.T) {
+	p := packet.NewPacker(packet.WithBufferBytes(16))
+
+	// 伪造超出消息字节数上限的消息长度，不应按声明长度分配内存
+	data := []byte{0x7f, 0xff, 0xff, 0xff}
+
+	if _, err := p.ReadMessage(bytes.NewReader(data)); !errors.Is(err, errors.ErrMessageTooLarge) {
+		t.Fatalf("expected ErrMessageTooLarge, got: %v", err)
+	}
+
+	message, err := p.PackMessage(&packet.Message{Seq: 1, Route: 1, Buffer: []byte("0123456789abcdef")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := p.ReadMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(msg, message) {
+		t.Fatalf("unexpected message: %v", msg)
+	}
+}
+
+func TestDefaultPacker_ReleaseMessage(t *testing.T) {
+	p := packet.NewPacker(packet.WithHeartbeatTime(true), packet.WithPoolSize(4))
+
+	heartbeat, err := p.PackHeartbeat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader := bytes.NewReader(heartbeat)
+
+	// 读取的心跳消息放回对象池后被复用，不再产生内存分配
+	allocs := testing.AllocsPerRun(100, func() {
+		reader.Reset(heartbeat)
+
+		msg, err := p.ReadMessage(reader)
+		if err != nil || len(msg) != len(heartbeat) {
+			t.Fatalf("unexpected heartbeat: %v %v", msg, err)
+		}
+
+		p.ReleaseMessage(msg)
+	})
+
+	if allocs != 0 {
+		t.Fatalf("unexpected allocs per heartbeat read: %v", allocs)
+	}
+}
+
+func TestDefaultPacker_RouteLabel(t *testing.T) {
+	if err := packet.Register(&packet.Definition{Route: 1, Name: "login"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := packet.Register(&packet.Definition{Route: 2, Name: "login"}); !errors.Is(err, errors.ErrRouteConflict) {
+		t.Fatalf("expected route conflict, got %v", err)
+	}
+
+	p := packet.NewPacker(packet.WithMetrics(true))
+
+	for _, route := range []int32{1, 2, 3} {
+		data, err := p.PackMessage(&packet.Message{Seq: 1, Route: route, Buffer: []byte("hello")})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = p.ReadMessage(bytes.NewReader(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if label := p.RouteLabel(1); label != "login" {
+		t.Fatalf("unexpected label: %s", label)
+	}
+
+	routes := p.Stats().Routes
+
+	if len(routes) != 2 || routes["login"].Inbound != 1 || routes["login"].Outbound != 1 || routes["other"].Inbound != 2 {
+		t.Fatalf("unexpected route stats: %+v", routes)
+	}
+
+	// 以路由ID作为标签时超出上限的路由归入other标签
+	p = packet.NewPacker(packet.WithMetrics(true), packet.WithRouteOther(false), packet.WithRouteLabels(2))
+
+	for route := int32(2); route < 100; route++ {
+		data, err := p.PackMessage(&packet.Message{Seq: 1, Route: route, Buffer: []byte("hello")})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = p.ReadMessage(bytes.NewReader(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	routes = p.Stats().Routes
+
+	if len(routes) != 3 || routes["2"].Inbound != 1 || routes["3"].Inbound != 1 || routes["other"].Inbound != 96 {
+		t.Fatalf("unexpected bounded route stats: %+v", routes)
+	}
+
+	if label := p.RouteLabel(3); label != "3" {
+		t.Fatalf("unexpected label: %s", label)
+	}
+}
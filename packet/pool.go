@@ -0,0 +1,60 @@
+package packet
+
+import "sync"
+
+// 定长字节切片对象池
+// maxCount大于0时使用有界空闲列表，超出的切片直接交由GC回收，不足时新建；否则使用sync.Pool
+type bytesPool struct {
+	size  int
+	pool  sync.Pool
+	frees chan []byte
+}
+
+func newBytesPool(size, maxCount int) *bytesPool {
+	p := &bytesPool{size: size}
+	p.pool.New = func() any { return make([]byte, size) }
+
+	if maxCount > 0 {
+		p.frees = make(chan []byte, maxCount)
+	}
+
+	return p
+}
+
+// 获取
+func (p *bytesPool) get() []byte {
+	if p.frees == nil {
+		return p.pool.Get().([]byte)
+	}
+
+	select {
+	case buf := <-p.frees:
+		return buf
+	default:
+		return make([]byte, p.size)
+	}
+}
+
+// 放回，容量与对象池不一致的切片将被丢弃
+func (p *bytesPool) put(buf []byte) {
+	if cap(buf) != p.size {
+		return
+	}
+
+	buf = buf[:p.size]
+
+	if p.frees == nil {
+		p.pool.Put(buf)
+		return
+	}
+
+	select {
+	case p.frees <- buf:
+	default:
+	}
+}
+
+// 空闲数量，仅使用有界空闲列表时统计
+func (p *bytesPool) idle() int {
+	return len(p.frees)
+}
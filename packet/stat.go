@@ -0,0 +1,226 @@
+package packet
+
+import (
+	"github.com/dobyte/due/v2/core/buffer"
+	"github.com/dobyte/due/v2/log"
+	"math"
+	"strconv"
+	"sync/atomic"
+)
+
+// 未注册消息定义的路由统一使用的统计标签
+const otherRouteLabel = "other"
+
+// 消息大小直方图的分桶上限（字节）
+var sizeBuckets = [...]int{64, 256, 1024, 4096, 16384, 65536, math.MaxInt}
+
+// SizeBucket 消息大小分桶统计
+type SizeBucket struct {
+	Upper int   `json:"upper"` // 分桶上限（包含）
+	Count int64 `json:"count"` // 落入该分桶的消息数
+}
+
+// SizeHistogram 消息大小直方图
+type SizeHistogram struct {
+	Count   int64        `json:"count"`   // 消息总数
+	Bytes   int64        `json:"bytes"`   // 消息总字节数
+	Buckets []SizeBucket `json:"buckets"` // 分桶统计
+}
+
+// SizeStat 消息大小统计
+type SizeStat struct {
+	Inbound  SizeHistogram        `json:"inbound"`          // 读取的消息
+	Outbound SizeHistogram        `json:"outbound"`         // 打包的消息
+	Routes   map[string]RouteStat `json:"routes,omitempty"` // 按路由标签划分的消息统计
+}
+
+// RouteStat 路由消息统计
+type RouteStat struct {
+	Inbound       int64 `json:"inbound"`       // 读取的消息数
+	InboundBytes  int64 `json:"inboundBytes"`  // 读取的消息字节数
+	Outbound      int64 `json:"outbound"`      // 打包的消息数
+	OutboundBytes int64 `json:"outboundBytes"` // 打包的消息字节数
+}
+
+type RouteLabeler interface {
+	// RouteLabel 获取路由的统计标签
+	RouteLabel(route int32) string
+}
+
+type routeCounter struct {
+	inbound       atomic.Int64
+	inboundBytes  atomic.Int64
+	outbound      atomic.Int64
+	outboundBytes atomic.Int64
+}
+
+type Statter interface {
+	// Stats 获取消息大小统计
+	Stats() SizeStat
+}
+
+type sizeHistogram struct {
+	count   atomic.Int64
+	bytes   atomic.Int64
+	buckets [len(sizeBuckets)]atomic.Int64
+}
+
+// 记录消息大小
+func (h *sizeHistogram) record(size int) {
+	h.count.Add(1)
+	h.bytes.Add(int64(size))
+
+	for i, upper := range sizeBuckets {
+		if size <= upper {
+			h.buckets[i].Add(1)
+			return
+		}
+	}
+}
+
+// 生成直方图快照
+func (h *sizeHistogram) snapshot() SizeHistogram {
+	hist := SizeHistogram{
+		Count:   h.count.Load(),
+		Bytes:   h.bytes.Load(),
+		Buckets: make([]SizeBucket, len(sizeBuckets)),
+	}
+
+	for i, upper := range sizeBuckets {
+		hist.Buckets[i] = SizeBucket{Upper: upper, Count: h.buckets[i].Load()}
+	}
+
+	return hist
+}
+
+// Stats 获取消息大小统计，未开启统计时返回空统计
+func (p *defaultPacker) Stats() SizeStat {
+	stat := SizeStat{
+		Inbound:  p.inbound.snapshot(),
+		Outbound: p.outbound.snapshot(),
+	}
+
+	p.routes.Range(func(label, value any) bool {
+		if stat.Routes == nil {
+			stat.Routes = make(map[string]RouteStat)
+		}
+
+		counter := value.(*routeCounter)
+
+		stat.Routes[label.(string)] = RouteStat{
+			Inbound:       counter.inbound.Load(),
+			InboundBytes:  counter.inboundBytes.Load(),
+			Outbound:      counter.outbound.Load(),
+			OutboundBytes: counter.outboundBytes.Load(),
+		}
+
+		return true
+	})
+
+	return stat
+}
+
+// RouteLabel 获取路由的统计标签
+// 已注册消息定义的路由返回定义名称；未注册的路由在开启other标签时返回other，否则返回路由ID
+func (p *defaultPacker) RouteLabel(route int32) string {
+	label, _ := p.routeLabel(route)
+
+	return label
+}
+
+// 获取路由的统计标签，以路由ID作为标签时返回true
+func (p *defaultPacker) routeLabel(route int32) (string, bool) {
+	if def, ok := Lookup(route); ok {
+		return def.Name, false
+	}
+
+	if p.opts.routeOther {
+		return otherRouteLabel, false
+	}
+
+	return strconv.Itoa(int(route)), true
+}
+
+// 获取路由统计计数器，以路由ID作为标签的路由数量超出上限后归入other标签
+func (p *defaultPacker) routeCounter(route int32) *routeCounter {
+	label, bounded := p.routeLabel(route)
+
+	if counter, ok := p.routes.Load(label); ok {
+		return counter.(*routeCounter)
+	}
+
+	if bounded && p.labels.Add(1) > int64(p.opts.routeLabels) {
+		p.labels.Add(-1)
+		label = otherRouteLabel
+	}
+
+	counter, loaded := p.routes.LoadOrStore(label, &routeCounter{})
+	if loaded && bounded && label != otherRouteLabel {
+		p.labels.Add(-1)
+	}
+
+	return counter.(*routeCounter)
+}
+
+// 记录读取的消息帧
+func (p *defaultPacker) recordInbound(data []byte) {
+	route, ok := p.peekRoute(data)
+
+	if p.opts.metrics {
+		p.inbound.record(len(data))
+
+		if ok {
+			counter := p.routeCounter(route)
+			counter.inbound.Add(1)
+			counter.inboundBytes.Add(int64(len(data)))
+		}
+	}
+
+	if ok && p.opts.largeBytes > 0 && len(data) > p.opts.largeBytes {
+		log.Warnf("read large message, route = %d size = %d threshold = %d", route, len(data), p.opts.largeBytes)
+	}
+}
+
+// 记录打包的消息帧
+func (p *defaultPacker) recordOutbound(route int32, size int) {
+	if p.opts.metrics {
+		p.outbound.record(size)
+
+		counter := p.routeCounter(route)
+		counter.outbound.Add(1)
+		counter.outboundBytes.Add(int64(size))
+	}
+
+	if p.opts.largeBytes > 0 && size > p.opts.largeBytes {
+		log.Warnf("pack large message, route = %d size = %d threshold = %d", route, size, p.opts.largeBytes)
+	}
+}
+
+// 从数据帧中读取路由，心跳帧或数据不完整时返回false
+func (p *defaultPacker) peekRoute(data []byte) (int32, bool) {
+	offset := defaultSizeBytes + defaultHeaderBytes
+
+	if len(data) < offset+p.opts.routeBytes || data[defaultSizeBytes]&heartbeatBit == heartbeatBit {
+		return 0, false
+	}
+
+	buf := data[offset : offset+p.opts.routeBytes]
+
+	switch p.opts.routeBytes {
+	case 1:
+		return int32(int8(buf[0])), true
+	case 2:
+		return int32(int16(p.opts.byteOrder.Uint16(buf))), true
+	default:
+		return int32(p.opts.byteOrder.Uint32(buf)), true
+	}
+}
+
+// PoolStats 获取打包器对象池统计信息，未设置对象池数量时返回全局对象池统计
+func (p *defaultPacker) PoolStats() []buffer.WriterPoolStat {
+	if p.pool != nil {
+		return p.pool.Stats()
+	}
+
+	return buffer.WriterPoolStats()
+}
@@ -0,0 +1,45 @@
+package redis
+
+import (
+	"github.com/go-redis/redis/v8"
+	"sync"
+)
+
+var (
+	mu           sync.RWMutex
+	sharedClient redis.UniversalClient
+)
+
+// NewClient 新建客户端，根据配置自动选择单机、集群或哨兵模式
+func NewClient(opts ...Option) redis.UniversalClient {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:            o.addrs,
+		DB:               o.db,
+		Username:         o.username,
+		Password:         o.password,
+		MaxRetries:       o.maxRetries,
+		MasterName:       o.masterName,
+		SentinelPassword: o.sentinelPassword,
+	})
+}
+
+// SetSharedClient 设置共享客户端
+// 设置后lock、cache、eventbus等Redis组件在未指定专用客户端时将复用该客户端
+func SetSharedClient(client redis.UniversalClient) {
+	mu.Lock()
+	sharedClient = client
+	mu.Unlock()
+}
+
+// GetSharedClient 获取共享客户端，未设置时返回nil
+func GetSharedClient() redis.UniversalClient {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return sharedClient
+}
@@ -0,0 +1,100 @@
+package redis
+
+import (
+	"github.com/dobyte/due/v2/etc"
+)
+
+const (
+	defaultAddr       = "127.0.0.1:6379"
+	defaultDB         = 0
+	defaultMaxRetries = 3
+)
+
+const (
+	defaultAddrsKey            = "etc.redis.addrs"
+	defaultDBKey               = "etc.redis.db"
+	defaultMaxRetriesKey       = "etc.redis.maxRetries"
+	defaultUsernameKey         = "etc.redis.username"
+	defaultPasswordKey         = "etc.redis.password"
+	defaultMasterNameKey       = "etc.redis.masterName"
+	defaultSentinelPasswordKey = "etc.redis.sentinelPassword"
+)
+
+type Option func(o *options)
+
+type options struct {
+	// 客户端连接地址
+	// 单机、集群模式下为Redis节点地址，哨兵模式下为哨兵节点地址，默认为[]string{"127.0.0.1:6379"}
+	addrs []string
+
+	// 数据库号
+	// 默认为0
+	db int
+
+	// 用户名
+	// 默认为空
+	username string
+
+	// 密码
+	// 默认为空
+	password string
+
+	// 最大重试次数
+	// 默认为3次
+	maxRetries int
+
+	// 哨兵模式主节点名称
+	// 设置后将以哨兵模式连接，默认为空
+	masterName string
+
+	// 哨兵节点密码
+	// 仅哨兵模式下生效，默认为空
+	sentinelPassword string
+}
+
+func defaultOptions() *options {
+	return &options{
+		addrs:            etc.Get(defaultAddrsKey, []string{defaultAddr}).Strings(),
+		db:               etc.Get(defaultDBKey, defaultDB).Int(),
+		maxRetries:       etc.Get(defaultMaxRetriesKey, defaultMaxRetries).Int(),
+		username:         etc.Get(defaultUsernameKey).String(),
+		password:         etc.Get(defaultPasswordKey).String(),
+		masterName:       etc.Get(defaultMasterNameKey).String(),
+		sentinelPassword: etc.Get(defaultSentinelPasswordKey).String(),
+	}
+}
+
+// WithAddrs 设置连接地址
+func WithAddrs(addrs ...string) Option {
+	return func(o *options) { o.addrs = addrs }
+}
+
+// WithDB 设置数据库号
+func WithDB(db int) Option {
+	return func(o *options) { o.db = db }
+}
+
+// WithUsername 设置用户名
+func WithUsername(username string) Option {
+	return func(o *options) { o.username = username }
+}
+
+// WithPassword 设置密码
+func WithPassword(password string) Option {
+	return func(o *options) { o.password = password }
+}
+
+// WithMaxRetries 设置最大重试次数
+func WithMaxRetries(maxRetries int) Option {
+	return func(o *options) { o.maxRetries = maxRetries }
+}
+
+// WithMasterName 设置哨兵模式主节点名称，设置后将以哨兵模式连接，此时连接地址为哨兵节点地址
+func WithMasterName(masterName string) Option {
+	return func(o *options) { o.masterName = masterName }
+}
+
+// WithSentinelPassword 设置哨兵节点密码
+func WithSentinelPassword(password string) Option {
+	return func(o *options) { o.sentinelPassword = password }
+}
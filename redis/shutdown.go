@@ -0,0 +1,45 @@
+package redis
+
+import (
+	"context"
+	"errors"
+)
+
+// Component 支持有序关闭的Redis组件，如lock、eventbus、cache等Redis组件
+type Component interface {
+	// Shutdown 关闭组件，组件仅关闭其内建客户端
+	Shutdown(ctx context.Context) error
+}
+
+// Shutdown 有序关闭Redis组件，并在所有组件关闭后关闭共享客户端
+// 组件按传入顺序依次关闭，建议顺序为lock、eventbus、cache：
+// 1. lock停止所有续租并等待进行中的续租完成，随后释放当前持有的锁，保证关闭后不再有续租请求
+// 2. eventbus停止监听并等待订阅协程退出
+// 3. cache关闭其内建客户端
+// 共享客户端仅在所有组件关闭完成后关闭，关闭后将清空共享客户端；任一组件关闭失败不会中断后续流程，所有错误将合并返回
+func Shutdown(ctx context.Context, components ...Component) error {
+	var errs []error
+
+	for _, component := range components {
+		if component == nil {
+			continue
+		}
+
+		if err := component.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	mu.Lock()
+	client := sharedClient
+	sharedClient = nil
+	mu.Unlock()
+
+	if client != nil {
+		if err := client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
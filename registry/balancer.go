@@ -0,0 +1,176 @@
+package registry
+
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+)
+
+// Balancer 负载均衡器
+type Balancer interface {
+	// Select 从可用的服务实例中选择一个实例，key为调用方提供的选择依据（如用户ID），可能为空
+	Select(instances []*ServiceInstance, key string) *ServiceInstance
+}
+
+// Tracker 负载均衡器的可选接口，实现后每次调用结束时将收到通知，可用于统计进行中的请求
+type Tracker interface {
+	// Done 调用结束
+	Done(ins *ServiceInstance)
+}
+
+// LoadFunc 负载获取函数，返回服务实例进行中的请求数
+type LoadFunc func(ins *ServiceInstance) int64
+
+// Feeder 负载均衡器的可选接口，实现后将由传输层客户端提供各服务实例实时的进行中请求数
+type Feeder interface {
+	// Feed 设置负载获取函数
+	Feed(fn LoadFunc)
+}
+
+type randomBalancer struct{}
+
+// NewRandomBalancer 创建随机负载均衡器
+func NewRandomBalancer() Balancer {
+	return &randomBalancer{}
+}
+
+// Select 随机选择一个实例
+func (b *randomBalancer) Select(instances []*ServiceInstance, _ string) *ServiceInstance {
+	if n := len(instances); n > 0 {
+		return instances[rand.IntN(n)]
+	}
+
+	return nil
+}
+
+type roundRobinBalancer struct {
+	counter atomic.Uint64
+}
+
+// NewRoundRobinBalancer 创建轮询负载均衡器
+func NewRoundRobinBalancer() Balancer {
+	return &roundRobinBalancer{}
+}
+
+// Select 轮询选择一个实例
+func (b *roundRobinBalancer) Select(instances []*ServiceInstance, _ string) *ServiceInstance {
+	if n := len(instances); n > 0 {
+		return instances[b.counter.Add(1)%uint64(n)]
+	}
+
+	return nil
+}
+
+type leastConnBalancer struct {
+	conns sync.Map     // 实例ID -> *atomic.Int64
+	load  atomic.Value // 负载获取函数，由传输层客户端提供
+}
+
+var (
+	_ Tracker = &leastConnBalancer{}
+	_ Feeder  = &leastConnBalancer{}
+)
+
+// NewLeastConnBalancer 创建最少连接负载均衡器，优先选择进行中的请求数最少的实例
+// 设置到集群组件后，进行中的请求数由传输层客户端实时提供；否则以经由负载均衡器发起的请求进行统计
+func NewLeastConnBalancer() Balancer {
+	return &leastConnBalancer{}
+}
+
+// Feed 设置负载获取函数
+func (b *leastConnBalancer) Feed(fn LoadFunc) {
+	if fn != nil {
+		b.load.Store(fn)
+	}
+}
+
+// Select 选择进行中的请求数最少的实例，请求数相同时随机选择
+func (b *leastConnBalancer) Select(instances []*ServiceInstance, _ string) *ServiceInstance {
+	var (
+		selected *ServiceInstance
+		least    int64
+		ties     int
+	)
+
+	load, fed := b.load.Load().(LoadFunc)
+
+	for _, ins := range instances {
+		var n int64
+		if fed {
+			n = load(ins)
+		} else {
+			n = b.counter(ins.ID).Load()
+		}
+
+		switch {
+		case selected == nil || n < least:
+			selected, least, ties = ins, n, 1
+		case n == least:
+			ties++
+			if rand.IntN(ties) == 0 {
+				selected = ins
+			}
+		}
+	}
+
+	if selected != nil && !fed {
+		b.counter(selected.ID).Add(1)
+	}
+
+	return selected
+}
+
+// Done 调用结束
+func (b *leastConnBalancer) Done(ins *ServiceInstance) {
+	if _, fed := b.load.Load().(LoadFunc); fed {
+		return
+	}
+
+	b.counter(ins.ID).Add(-1)
+}
+
+func (b *leastConnBalancer) counter(insID string) *atomic.Int64 {
+	if v, ok := b.conns.Load(insID); ok {
+		return v.(*atomic.Int64)
+	}
+
+	v, _ := b.conns.LoadOrStore(insID, &atomic.Int64{})
+
+	return v.(*atomic.Int64)
+}
+
+type leastLoadBalancer struct{}
+
+// NewLeastLoadBalancer 创建最低负载负载均衡器，优先选择上报负载评分最低的实例，评分相同时选择活跃数较少的实例
+// 负载由服务实例定期上报至注册中心，并随服务发现的监听事件更新；未上报负载的实例视为空闲
+func NewLeastLoadBalancer() Balancer {
+	return &leastLoadBalancer{}
+}
+
+// Select 选择负载最低的实例，负载相同时随机选择
+func (b *leastLoadBalancer) Select(instances []*ServiceInstance, _ string) *ServiceInstance {
+	var (
+		selected *ServiceInstance
+		least    Load
+		ties     int
+	)
+
+	for _, ins := range instances {
+		var load Load
+		if ins.Load != nil {
+			load = *ins.Load
+		}
+
+		switch {
+		case selected == nil || load.Score < least.Score || (load.Score == least.Score && load.Active < least.Active):
+			selected, least, ties = ins, load, 1
+		case load == least:
+			ties++
+			if rand.IntN(ties) == 0 {
+				selected = ins
+			}
+		}
+	}
+
+	return selected
+}
@@ -0,0 +1,181 @@
+package registry_test
+
+import (
+	"github.com/dobyte/due/v2/core/clock"
+	"github.com/dobyte/due/v2/registry"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var instances = []*registry.ServiceInstance{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+
+func TestRoundRobinBalancer(t *testing.T) {
+	balancer := registry.NewRoundRobinBalancer()
+	counts := make(map[string]int)
+
+	for i := 0; i < 30; i++ {
+		counts[balancer.Select(instances, "").ID]++
+	}
+
+	for _, ins := range instances {
+		if counts[ins.ID] != 10 {
+			t.Fatalf("unbalanced selection: %v", counts)
+		}
+	}
+
+	if balancer.Select(nil, "") != nil {
+		t.Fatal("expected nil instance")
+	}
+}
+
+func TestLeastConnBalancer(t *testing.T) {
+	balancer := registry.NewLeastConnBalancer()
+	tracker := balancer.(registry.Tracker)
+
+	selected := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		selected[balancer.Select(instances, "").ID] = true
+	}
+
+	if len(selected) != 3 {
+		t.Fatalf("expected all instances selected once, got %v", selected)
+	}
+
+	tracker.Done(instances[1])
+
+	if ins := balancer.Select(instances, ""); ins.ID != "2" {
+		t.Fatalf("expected least connection instance 2, got %s", ins.ID)
+	}
+}
+
+func TestLeastConnBalancer_Feed(t *testing.T) {
+	balancer := registry.NewLeastConnBalancer()
+	loads := map[string]int64{"1": 5, "2": 3, "3": 1}
+
+	balancer.(registry.Feeder).Feed(func(ins *registry.ServiceInstance) int64 {
+		return loads[ins.ID]
+	})
+
+	for i := 0; i < 3; i++ {
+		if ins := balancer.Select(instances, ""); ins.ID != "3" {
+			t.Fatalf("expected least loaded instance 3, got %s", ins.ID)
+		}
+	}
+
+	loads["3"] = 10
+
+	if ins := balancer.Select(instances, ""); ins.ID != "2" {
+		t.Fatalf("expected least loaded instance 2, got %s", ins.ID)
+	}
+}
+
+func TestLeastLoadBalancer(t *testing.T) {
+	balancer := registry.NewLeastLoadBalancer()
+	instances := []*registry.ServiceInstance{
+		{ID: "1", Load: &registry.Load{Active: 1, Score: 0.8}},
+		{ID: "2", Load: &registry.Load{Active: 9, Score: 0.2}},
+		{ID: "3", Load: &registry.Load{Active: 3, Score: 0.2}},
+	}
+
+	if ins := balancer.Select(instances, ""); ins.ID != "3" {
+		t.Fatalf("expected least loaded instance 3, got %s", ins.ID)
+	}
+
+	instances = append(instances, &registry.ServiceInstance{ID: "4"})
+
+	if ins := balancer.Select(instances, ""); ins.ID != "4" {
+		t.Fatalf("expected unreported instance 4, got %s", ins.ID)
+	}
+}
+
+func TestCanaryBalancer(t *testing.T) {
+	var (
+		stable   = &registry.ServiceInstance{ID: "stable"}
+		canary   = &registry.ServiceInstance{ID: "canary", Tags: []string{registry.CanaryTag}}
+		nodes    = []*registry.ServiceInstance{stable, canary}
+		balancer = registry.NewCanaryBalancer(30, nil)
+		assigned = make(map[string]string)
+	)
+
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		assigned[key] = balancer.Select(nodes, key).ID
+	}
+
+	stat := balancer.Stat()
+	if stat.CanarySessions+stat.StableSessions != 1000 {
+		t.Fatalf("unexpected sessions: %+v", stat)
+	}
+
+	if stat.CanarySessions < 200 || stat.CanarySessions > 400 {
+		t.Fatalf("unexpected canary split: %+v", stat)
+	}
+
+	// 调整百分比后已分配的会话仍保持在原实例组
+	balancer.SetPercent(100)
+
+	for key, id := range assigned {
+		if ins := balancer.Select(nodes, key); ins.ID != id {
+			t.Fatalf("session %s moved from %s to %s", key, id, ins.ID)
+		}
+	}
+
+	balancer.Release("0")
+
+	if ins := balancer.Select(nodes, "0"); ins.ID != canary.ID {
+		t.Fatalf("expected released session routed to canary, got %s", ins.ID)
+	}
+
+	// 金丝雀实例组无可用实例时选择稳定实例
+	if ins := balancer.Select([]*registry.ServiceInstance{stable}, "new"); ins.ID != stable.ID {
+		t.Fatalf("expected fallback to stable, got %s", ins.ID)
+	}
+}
+
+func TestCanaryBalancer_TTL(t *testing.T) {
+	var (
+		canary   = &registry.ServiceInstance{ID: "canary", Tags: []string{registry.CanaryTag}}
+		nodes    = []*registry.ServiceInstance{{ID: "stable"}, canary}
+		fake     = clock.NewFake(time.Now())
+		balancer = registry.NewCanaryBalancer(100, nil)
+	)
+
+	balancer.SetClock(fake)
+
+	for i := 0; i < 100; i++ {
+		balancer.Select(nodes, strconv.Itoa(i))
+	}
+
+	// 未设置过期时间时会话在调用Release前始终保持在所分配的实例组
+	fake.Advance(24 * time.Hour)
+	balancer.SetPercent(0)
+
+	if ins := balancer.Select(nodes, "0"); ins.ID != canary.ID {
+		t.Fatalf("expected idle session kept on canary, got %s", ins.ID)
+	}
+
+	if stat := balancer.Stat(); stat.CanarySessions != 100 {
+		t.Fatalf("unexpected sessions: %+v", stat)
+	}
+
+	balancer.SetTTL(time.Minute)
+
+	fake.Advance(30 * time.Second)
+	balancer.Select(nodes, "0")
+
+	fake.Advance(40 * time.Second)
+
+	// 空闲过期的会话被清理，新会话按调整后的百分比分配
+	if ins := balancer.Select(nodes, "1"); ins.ID != "stable" {
+		t.Fatalf("expected expired session routed to stable, got %s", ins.ID)
+	}
+
+	if ins := balancer.Select(nodes, "0"); ins.ID != canary.ID {
+		t.Fatalf("expected active session kept on canary, got %s", ins.ID)
+	}
+
+	if stat := balancer.Stat(); stat.CanarySessions != 1 || stat.StableSessions != 1 {
+		t.Fatalf("unexpected sessions after expiry: %+v", stat)
+	}
+}
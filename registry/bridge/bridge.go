@@ -0,0 +1,151 @@
+package bridge
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/eventbus"
+	"github.com/dobyte/due/v2/log"
+	"github.com/dobyte/due/v2/registry"
+	"reflect"
+	"sync"
+)
+
+const (
+	ServiceAdded   EventKind = "added"   // 服务实例上线
+	ServiceUpdated EventKind = "updated" // 服务实例更新
+	ServiceRemoved EventKind = "removed" // 服务实例下线
+)
+
+// EventKind 服务实例变化事件类型
+type EventKind string
+
+// Event 服务实例变化事件
+type Event struct {
+	Kind     EventKind                 `json:"kind"`     // 事件类型
+	Service  string                    `json:"service"`  // 服务名称
+	Instance *registry.ServiceInstance `json:"instance"` // 服务实例，下线事件为下线前的最后状态
+}
+
+// Bridge 注册中心事件桥接器
+// 由单个进程监听注册中心，将服务实例的上线、更新、下线事件发布到事件总线，其余进程订阅事件总线即可感知服务变化，无需各自监听注册中心
+type Bridge struct {
+	opts    *options
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started bool
+}
+
+func NewBridge(opts ...Option) *Bridge {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	b := &Bridge{}
+	b.opts = o
+	b.ctx, b.cancel = context.WithCancel(o.ctx)
+
+	return b
+}
+
+// Start 启动桥接，监听全部服务并发布变化事件；启动时已存在的服务实例将以上线事件发布
+func (b *Bridge) Start() error {
+	if b.opts.discovery == nil {
+		return errors.ErrMissRegistry
+	}
+
+	if b.started {
+		return nil
+	}
+
+	eb := b.opts.eventbus
+	if eb == nil {
+		eb = eventbus.GetEventbus()
+	}
+
+	for _, service := range b.opts.services {
+		watcher, err := b.opts.discovery.Watch(b.ctx, service)
+		if err != nil {
+			b.cancel()
+			b.wg.Wait()
+			return err
+		}
+
+		b.wg.Add(1)
+		go b.watch(eb, service, watcher)
+	}
+
+	b.started = true
+
+	return nil
+}
+
+// Stop 停止桥接
+func (b *Bridge) Stop() {
+	b.cancel()
+	b.wg.Wait()
+}
+
+// 监听服务实例变化
+func (b *Bridge) watch(eb eventbus.Eventbus, service string, watcher registry.Watcher) {
+	defer b.wg.Done()
+
+	go func() {
+		<-b.ctx.Done()
+		_ = watcher.Stop()
+	}()
+
+	instances := make(map[string]*registry.ServiceInstance)
+
+	for {
+		services, err := watcher.Next()
+		if err != nil {
+			return
+		}
+
+		latest := make(map[string]*registry.ServiceInstance, len(services))
+
+		for _, ins := range services {
+			latest[ins.ID] = ins
+
+			prev, ok := instances[ins.ID]
+			switch {
+			case !ok:
+				b.publish(eb, &Event{Kind: ServiceAdded, Service: service, Instance: ins})
+			case !reflect.DeepEqual(prev, ins):
+				b.publish(eb, &Event{Kind: ServiceUpdated, Service: service, Instance: ins})
+			}
+		}
+
+		for id, ins := range instances {
+			if _, ok := latest[id]; !ok {
+				b.publish(eb, &Event{Kind: ServiceRemoved, Service: service, Instance: ins})
+			}
+		}
+
+		instances = latest
+	}
+}
+
+// 发布事件
+func (b *Bridge) publish(eb eventbus.Eventbus, event *Event) {
+	if err := eb.Publish(b.ctx, b.opts.topic, event); err != nil {
+		log.Warnf("publish registry event failed, service: %s, instance: %s, err: %v", event.Service, event.Instance.ID, err)
+	}
+}
+
+// Handler 将服务实例变化事件处理器包装为事件总线处理器，用于订阅桥接器发布的事件主题
+// 取消订阅时需传入同一个包装后的处理器
+func Handler(handler func(event *Event)) eventbus.EventHandler {
+	return func(e *eventbus.Event) {
+		event := &Event{}
+
+		if err := e.Payload.Scan(event); err != nil {
+			log.Warnf("decode registry event failed, topic: %s, err: %v", e.Topic, err)
+			return
+		}
+
+		handler(event)
+	}
+}
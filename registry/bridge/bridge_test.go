@@ -0,0 +1,85 @@
+package bridge_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/eventbus"
+	"github.com/dobyte/due/v2/registry"
+	"github.com/dobyte/due/v2/registry/bridge"
+	"github.com/dobyte/due/v2/registry/mock"
+	"testing"
+	"time"
+)
+
+const topic = "registry.test"
+
+func newInstance(id string, state string) *registry.ServiceInstance {
+	return &registry.ServiceInstance{
+		ID:       id,
+		Name:     "node",
+		Kind:     "node",
+		State:    state,
+		Endpoint: "drpc://127.0.0.1:3553",
+	}
+}
+
+func TestBridge(t *testing.T) {
+	ctx := context.Background()
+	reg := mock.NewRegistry()
+	eb := eventbus.NewEventbus()
+	events := make(chan *bridge.Event, 10)
+
+	if err := eb.Subscribe(ctx, topic, bridge.Handler(func(event *bridge.Event) {
+		events <- event
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(ctx, newInstance("1", "work")); err != nil {
+		t.Fatal(err)
+	}
+
+	b := bridge.NewBridge(
+		bridge.WithDiscovery(reg),
+		bridge.WithEventbus(eb),
+		bridge.WithTopic(topic),
+		bridge.WithServices("node"),
+	)
+
+	if err := b.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Stop()
+
+	expect := func(kind bridge.EventKind, id string, state string) {
+		t.Helper()
+
+		select {
+		case event := <-events:
+			if event.Kind != kind || event.Service != "node" || event.Instance.ID != id || event.Instance.State != state {
+				t.Fatalf("unexpected event: %+v, instance: %+v", event, event.Instance)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("wait %s event timeout", kind)
+		}
+	}
+
+	expect(bridge.ServiceAdded, "1", "work")
+
+	if err := reg.Register(ctx, newInstance("2", "work")); err != nil {
+		t.Fatal(err)
+	}
+
+	expect(bridge.ServiceAdded, "2", "work")
+
+	if err := reg.Register(ctx, newInstance("1", "busy")); err != nil {
+		t.Fatal(err)
+	}
+
+	expect(bridge.ServiceUpdated, "1", "busy")
+
+	if err := reg.Deregister(ctx, newInstance("2", "work")); err != nil {
+		t.Fatal(err)
+	}
+
+	expect(bridge.ServiceRemoved, "2", "work")
+}
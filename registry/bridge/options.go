@@ -0,0 +1,74 @@
+package bridge
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/etc"
+	"github.com/dobyte/due/v2/eventbus"
+	"github.com/dobyte/due/v2/registry"
+)
+
+const (
+	defaultTopic = "registry"
+)
+
+const (
+	defaultTopicKey    = "etc.registry.bridge.topic"
+	defaultServicesKey = "etc.registry.bridge.services"
+)
+
+type Option func(o *options)
+
+type options struct {
+	// 上下文
+	// 默认为context.Background
+	ctx context.Context
+
+	// 服务发现组件
+	// 必填项，服务实例变化的来源
+	discovery registry.Discovery
+
+	// 事件总线
+	// 默认为全局事件总线eventbus.GetEventbus()
+	eventbus eventbus.Eventbus
+
+	// 事件主题
+	// 默认为registry
+	topic string
+
+	// 需要桥接的服务名称列表
+	// 默认为空
+	services []string
+}
+
+func defaultOptions() *options {
+	return &options{
+		ctx:      context.Background(),
+		topic:    etc.Get(defaultTopicKey, defaultTopic).String(),
+		services: etc.Get(defaultServicesKey).Strings(),
+	}
+}
+
+// WithContext 设置上下文
+func WithContext(ctx context.Context) Option {
+	return func(o *options) { o.ctx = ctx }
+}
+
+// WithDiscovery 设置服务发现组件
+func WithDiscovery(discovery registry.Discovery) Option {
+	return func(o *options) { o.discovery = discovery }
+}
+
+// WithEventbus 设置事件总线
+func WithEventbus(eb eventbus.Eventbus) Option {
+	return func(o *options) { o.eventbus = eb }
+}
+
+// WithTopic 设置事件主题
+func WithTopic(topic string) Option {
+	return func(o *options) { o.topic = topic }
+}
+
+// WithServices 设置需要桥接的服务名称列表
+func WithServices(services ...string) Option {
+	return func(o *options) { o.services = services }
+}
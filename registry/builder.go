@@ -0,0 +1,203 @@
+package registry
+
+import (
+	"fmt"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/packet"
+	"net"
+	"net/url"
+	"strings"
+)
+
+type InstanceBuilder struct {
+	ins      *ServiceInstance
+	problems []string
+	routes   map[int32]struct{}
+	events   map[int]struct{}
+}
+
+// NewInstanceBuilder 新建服务实例构建器，构建时将校验各字段的合法性
+func NewInstanceBuilder() *InstanceBuilder {
+	return &InstanceBuilder{
+		ins:    &ServiceInstance{},
+		routes: make(map[int32]struct{}),
+		events: make(map[int]struct{}),
+	}
+}
+
+// WithID 设置服务实体ID
+func (b *InstanceBuilder) WithID(id string) *InstanceBuilder {
+	b.ins.ID = id
+	return b
+}
+
+// WithName 设置服务实体名
+func (b *InstanceBuilder) WithName(name string) *InstanceBuilder {
+	b.ins.Name = name
+	return b
+}
+
+// WithKind 设置服务实体类型
+func (b *InstanceBuilder) WithKind(kind string) *InstanceBuilder {
+	b.ins.Kind = kind
+	return b
+}
+
+// WithAlias 设置服务实体别名
+func (b *InstanceBuilder) WithAlias(alias string) *InstanceBuilder {
+	b.ins.Alias = alias
+	return b
+}
+
+// WithState 设置服务实例状态
+func (b *InstanceBuilder) WithState(state string) *InstanceBuilder {
+	if state == "" {
+		b.problems = append(b.problems, "state is empty")
+	}
+
+	b.ins.State = state
+	return b
+}
+
+// WithWeight 设置服务实例权重
+func (b *InstanceBuilder) WithWeight(weight int) *InstanceBuilder {
+	if weight < 0 {
+		b.problems = append(b.problems, fmt.Sprintf("weight %d is negative", weight))
+	}
+
+	b.ins.Weight = weight
+	return b
+}
+
+// WithEndpoint 设置服务实例暴露端口，格式为scheme://host:port
+func (b *InstanceBuilder) WithEndpoint(endpoint string) *InstanceBuilder {
+	if err := checkEndpoint(endpoint); err != nil {
+		b.problems = append(b.problems, fmt.Sprintf("endpoint %q is invalid: %v", endpoint, err))
+	}
+
+	b.ins.Endpoint = endpoint
+	return b
+}
+
+// WithNamedEndpoint 设置服务实例命名端口，格式为scheme://host:port
+func (b *InstanceBuilder) WithNamedEndpoint(name, endpoint string) *InstanceBuilder {
+	if name == "" {
+		b.problems = append(b.problems, "endpoint name is empty")
+	}
+
+	if err := checkEndpoint(endpoint); err != nil {
+		b.problems = append(b.problems, fmt.Sprintf("endpoint %s %q is invalid: %v", name, endpoint, err))
+	}
+
+	if b.ins.Endpoints == nil {
+		b.ins.Endpoints = make(map[string]string)
+	}
+
+	b.ins.Endpoints[name] = endpoint
+	return b
+}
+
+// WithRoute 添加路由
+func (b *InstanceBuilder) WithRoute(id int32, stateful, internal bool) *InstanceBuilder {
+	b.addRoute(Route{ID: id, Stateful: stateful, Internal: internal})
+	return b
+}
+
+// WithRouteAttrs 按路由属性位域添加路由，未知的属性位将被忽略
+func (b *InstanceBuilder) WithRouteAttrs(id int32, attrs RouteAttr) *InstanceBuilder {
+	route := Route{ID: id}
+	route.SetAttrs(attrs)
+
+	b.addRoute(route)
+	return b
+}
+
+// 添加路由，路由重复或超出打包器允许的范围时记录问题
+func (b *InstanceBuilder) addRoute(route Route) {
+	if _, ok := b.routes[route.ID]; ok {
+		b.problems = append(b.problems, fmt.Sprintf("route %d is duplicated", route.ID))
+		return
+	}
+
+	if err := packet.CheckRoute(route.ID); err != nil {
+		b.problems = append(b.problems, fmt.Sprintf("route %d is out of range", route.ID))
+		return
+	}
+
+	b.routes[route.ID] = struct{}{}
+	b.ins.Routes = append(b.ins.Routes, route)
+}
+
+// WithEvent 添加订阅事件
+func (b *InstanceBuilder) WithEvent(event int) *InstanceBuilder {
+	if event <= 0 {
+		b.problems = append(b.problems, fmt.Sprintf("event %d is invalid", event))
+		return b
+	}
+
+	if _, ok := b.events[event]; ok {
+		return b
+	}
+
+	b.events[event] = struct{}{}
+	b.ins.Events = append(b.ins.Events, event)
+	return b
+}
+
+// WithService 添加微服务
+func (b *InstanceBuilder) WithService(service string) *InstanceBuilder {
+	if service == "" {
+		b.problems = append(b.problems, "service is empty")
+		return b
+	}
+
+	b.ins.Services = append(b.ins.Services, service)
+	return b
+}
+
+// Build 构建服务实例，存在不合法字段时返回全部错误
+func (b *InstanceBuilder) Build() (*ServiceInstance, error) {
+	problems := make([]string, 0, len(b.problems)+3)
+
+	if b.ins.ID == "" {
+		problems = append(problems, "id is empty")
+	}
+
+	if b.ins.Name == "" {
+		problems = append(problems, "name is empty")
+	}
+
+	if b.ins.Kind == "" {
+		problems = append(problems, "kind is empty")
+	}
+
+	if b.ins.Endpoint == "" {
+		problems = append(problems, "endpoint is empty")
+	}
+
+	problems = append(problems, b.problems...)
+
+	if len(problems) > 0 {
+		return nil, errors.NewError(strings.Join(problems, "; "), errors.ErrInvalidArgument)
+	}
+
+	return b.ins, nil
+}
+
+// 检测端口地址
+func checkEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme == "" {
+		return errors.New("missing scheme")
+	}
+
+	if _, _, err = net.SplitHostPort(u.Host); err != nil {
+		return err
+	}
+
+	return nil
+}
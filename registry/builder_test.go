@@ -0,0 +1,59 @@
+package registry_test
+
+import (
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/registry"
+	"strings"
+	"testing"
+)
+
+func TestInstanceBuilder_Build(t *testing.T) {
+	ins, err := registry.NewInstanceBuilder().
+		WithID("1").
+		WithName("node").
+		WithKind("node").
+		WithState("work").
+		WithEndpoint("grpc://127.0.0.1:3553").
+		WithRoute(1, true, false).
+		WithEvent(1).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("%+v", ins)
+}
+
+func TestInstanceBuilder_Invalid(t *testing.T) {
+	_, err := registry.NewInstanceBuilder().
+		WithID("1").
+		WithName("node").
+		WithKind("node").
+		WithEndpoint("127.0.0.1").
+		WithRoute(1, true, false).
+		WithRoute(1, false, false).
+		Build()
+	if !errors.Is(err, errors.ErrInvalidArgument) {
+		t.Fatalf("expected invalid argument, got %v", err)
+	}
+
+	t.Log(err)
+}
+
+func TestInstanceBuilder_RouteOutOfRange(t *testing.T) {
+	for _, build := range []func(b *registry.InstanceBuilder) *registry.InstanceBuilder{
+		func(b *registry.InstanceBuilder) *registry.InstanceBuilder { return b.WithRoute(1<<20, true, false) },
+		func(b *registry.InstanceBuilder) *registry.InstanceBuilder { return b.WithRouteAttrs(-1<<20, 0) },
+	} {
+		_, err := build(registry.NewInstanceBuilder().
+			WithID("1").
+			WithName("node").
+			WithKind("node").
+			WithState("work").
+			WithEndpoint("grpc://127.0.0.1:3553")).
+			Build()
+		if !errors.Is(err, errors.ErrInvalidArgument) || !strings.Contains(err.Error(), "out of range") {
+			t.Fatalf("expected out of range route rejected, got %v", err)
+		}
+	}
+}
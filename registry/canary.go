@@ -0,0 +1,222 @@
+package registry
+
+import (
+	"github.com/dobyte/due/v2/core/clock"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const CanaryTag = "canary" // 金丝雀实例的自定义标签
+
+// Releaser 负载均衡器的可选接口，实现后会话结束时将收到通知，可用于释放按key保存的粘性状态
+type Releaser interface {
+	// Release 释放key对应的状态
+	Release(key string)
+}
+
+// CanaryStat 金丝雀分流统计
+type CanaryStat struct {
+	Percent        float64 // 当前金丝雀分流百分比
+	CanarySessions int64   // 当前分配到金丝雀实例的会话数
+	StableSessions int64   // 当前分配到稳定实例的会话数
+	CanarySelects  int64   // 累计选择金丝雀实例的次数
+	StableSelects  int64   // 累计选择稳定实例的次数
+}
+
+// CanaryBalancer 金丝雀负载均衡器，按百分比将新会话分配到带有CanaryTag标签的实例，其余会话分配到稳定实例
+// 会话以key（如用户ID）标识，首次选择后固定在所分配的实例组中，调整百分比仅影响新会话；key为空时每次按百分比随机分流
+// 会话的分流状态在调用Release后释放；通过SetTTL设置空闲过期时间后，空闲超时的会话也将被释放
+type CanaryBalancer struct {
+	clock         clock.Clock   // 时钟
+	percent       atomic.Uint64 // 金丝雀分流百分比，以float64的位存储
+	ttl           atomic.Int64  // 会话分流状态的空闲过期时间，为0时不过期
+	swept         atomic.Int64  // 上次清理过期会话的时间
+	next          Balancer      // 实例组内的负载均衡器
+	sessions      sync.Map      // key -> *canarySession
+	canaries      atomic.Int64  // 分配到金丝雀实例组的会话数
+	stables       atomic.Int64  // 分配到稳定实例组的会话数
+	canarySelects atomic.Int64  // 选择金丝雀实例的次数
+	stableSelects atomic.Int64  // 选择稳定实例的次数
+}
+
+type canarySession struct {
+	canary bool         // 是否分配到金丝雀实例组
+	active atomic.Int64 // 最近一次选择的时间
+}
+
+var (
+	_ Releaser = &CanaryBalancer{}
+	_ Tracker  = &CanaryBalancer{}
+	_ Feeder   = &CanaryBalancer{}
+)
+
+// NewCanaryBalancer 创建金丝雀负载均衡器，percent为分配到金丝雀实例的新会话百分比（0~100），next为实例组内的负载均衡器，为nil时随机选择
+func NewCanaryBalancer(percent float64, next Balancer) *CanaryBalancer {
+	if next == nil {
+		next = NewRandomBalancer()
+	}
+
+	b := &CanaryBalancer{next: next, clock: clock.Real()}
+	b.SetPercent(percent)
+
+	return b
+}
+
+// SetClock 设置时钟，测试中可替换为模拟时钟以精确控制会话过期，需在选择实例前设置
+func (b *CanaryBalancer) SetClock(clock clock.Clock) {
+	b.clock = clock
+}
+
+// SetPercent 设置金丝雀分流百分比，可在运行时调整，超出0~100范围时取边界值
+func (b *CanaryBalancer) SetPercent(percent float64) {
+	b.percent.Store(math.Float64bits(min(max(percent, 0), 100)))
+}
+
+// SetTTL 设置会话分流状态的空闲过期时间，适用于无法调用Release的场景（如节点、网格中的调用），空闲超时的会话将被释放
+// 默认不过期，会话在调用Release前始终保持在所分配的实例组；小于等于0时关闭过期
+func (b *CanaryBalancer) SetTTL(ttl time.Duration) {
+	b.ttl.Store(int64(max(ttl, 0)))
+}
+
+// Percent 获取金丝雀分流百分比
+func (b *CanaryBalancer) Percent() float64 {
+	return math.Float64frombits(b.percent.Load())
+}
+
+// Select 按会话所属的实例组选择实例，所属实例组无可用实例时选择另一实例组的实例
+func (b *CanaryBalancer) Select(instances []*ServiceInstance, key string) *ServiceInstance {
+	var canaries, stables []*ServiceInstance
+
+	for _, ins := range instances {
+		if slices.Contains(ins.Tags, CanaryTag) {
+			canaries = append(canaries, ins)
+		} else {
+			stables = append(stables, ins)
+		}
+	}
+
+	canary := b.cohort(key)
+
+	if (canary && len(canaries) == 0) || (!canary && len(stables) == 0) {
+		canary = !canary
+	}
+
+	if canary {
+		b.canarySelects.Add(1)
+		return b.next.Select(canaries, key)
+	}
+
+	b.stableSelects.Add(1)
+
+	return b.next.Select(stables, key)
+}
+
+// Release 释放会话的实例组分配，会话结束时调用
+func (b *CanaryBalancer) Release(key string) {
+	if v, ok := b.sessions.LoadAndDelete(key); ok {
+		b.forget(v.(*canarySession))
+	}
+}
+
+// Done 调用结束，透传给实例组内的负载均衡器
+func (b *CanaryBalancer) Done(ins *ServiceInstance) {
+	if tracker, ok := b.next.(Tracker); ok {
+		tracker.Done(ins)
+	}
+}
+
+// Feed 设置负载获取函数，透传给实例组内的负载均衡器
+func (b *CanaryBalancer) Feed(fn LoadFunc) {
+	if feeder, ok := b.next.(Feeder); ok {
+		feeder.Feed(fn)
+	}
+}
+
+// Stat 获取金丝雀分流统计，可用于核对实际的分流比例
+func (b *CanaryBalancer) Stat() CanaryStat {
+	return CanaryStat{
+		Percent:        b.Percent(),
+		CanarySessions: b.canaries.Load(),
+		StableSessions: b.stables.Load(),
+		CanarySelects:  b.canarySelects.Load(),
+		StableSelects:  b.stableSelects.Load(),
+	}
+}
+
+// 获取会话所属的实例组，首次选择时按百分比分配
+func (b *CanaryBalancer) cohort(key string) bool {
+	if key == "" {
+		return b.roll()
+	}
+
+	now := b.clock.Now().UnixNano()
+
+	b.sweep(now)
+
+	if v, ok := b.sessions.Load(key); ok {
+		session := v.(*canarySession)
+		session.active.Store(now)
+		return session.canary
+	}
+
+	session := &canarySession{canary: b.roll()}
+	session.active.Store(now)
+
+	v, loaded := b.sessions.LoadOrStore(key, session)
+	if loaded {
+		session = v.(*canarySession)
+		session.active.Store(now)
+	} else if session.canary {
+		b.canaries.Add(1)
+	} else {
+		b.stables.Add(1)
+	}
+
+	return session.canary
+}
+
+// 清理空闲过期的会话，每半个过期时间最多执行一次，未设置过期时间时不清理
+func (b *CanaryBalancer) sweep(now int64) {
+	ttl := b.ttl.Load()
+	if ttl <= 0 {
+		return
+	}
+
+	swept := b.swept.Load()
+	if swept == 0 {
+		b.swept.CompareAndSwap(0, now)
+		return
+	}
+
+	if now-swept < ttl/2 || !b.swept.CompareAndSwap(swept, now) {
+		return
+	}
+
+	b.sessions.Range(func(key, value any) bool {
+		session := value.(*canarySession)
+
+		if now-session.active.Load() >= ttl && b.sessions.CompareAndDelete(key, session) {
+			b.forget(session)
+		}
+
+		return true
+	})
+}
+
+// 扣减会话所属实例组的会话数
+func (b *CanaryBalancer) forget(session *canarySession) {
+	if session.canary {
+		b.canaries.Add(-1)
+	} else {
+		b.stables.Add(-1)
+	}
+}
+
+// 按百分比随机决定是否分配到金丝雀实例组
+func (b *CanaryBalancer) roll() bool {
+	return rand.Float64()*100 < b.Percent()
+}
@@ -0,0 +1,251 @@
+package consul
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/core/endpoint"
+	"github.com/dobyte/due/v2/log"
+	"github.com/hashicorp/consul/api"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCleanupDialTimeout = 3 * time.Second
+	defaultAgentPort          = "8500"
+	checkExpiresAt            = "expires at "
+)
+
+// CleanupOptions 孤儿服务清理选项
+type CleanupOptions struct {
+	Kinds       []string      // 需要清理的服务类型，为空时清理所有带有类型元数据的服务
+	Threshold   time.Duration // 服务实例持续处于critical状态超过该时长后才会被解注册
+	DialTimeout time.Duration // 探测服务实例连通性的超时时间，默认3s
+	DryRun      bool          // 仅报告，不执行解注册
+	AgentPort   int           // 服务所在Consul代理的HTTP端口，为0时使用当前客户端连接地址的端口
+}
+
+// OrphanService 孤儿服务
+type OrphanService struct {
+	ID           string        // 服务实例ID
+	Name         string        // 服务名称
+	Kind         string        // 服务类型
+	Node         string        // 所在Consul节点
+	Endpoint     string        // 服务暴露端点
+	CriticalFor  time.Duration // 已处于critical状态的时长
+	Deregistered bool          // 是否已解注册
+}
+
+// 处于critical状态的服务实例
+type criticalService struct {
+	index uint64    // 健康检测的最大修改索引
+	since time.Time // 进入critical状态的时间
+}
+
+// Cleanup 清理孤儿服务
+// 遍历指定类型的服务实例，元数据经配置的序列化器解码，对健康检测处于critical状态且TCP探测不可达的实例，持续critical超过阈值后通过所在的Consul代理解注册
+// 心跳检测的critical时长从TTL到期时间起算；其他检测无法获取状态变更时间，从当前Registry首次发现其进入critical状态起算，需由单一协调者周期性调用
+func (r *Registry) Cleanup(ctx context.Context, opts CleanupOptions) ([]*OrphanService, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = defaultCleanupDialTimeout
+	}
+
+	qo := (&api.QueryOptions{}).WithContext(ctx)
+
+	names, _, err := r.opts.client.Catalog().Services(qo)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		now     = time.Now()
+		seen    = make(map[string]struct{})
+		orphans = make([]*OrphanService, 0)
+	)
+
+	for name := range names {
+		entries, _, err := r.opts.client.Health().Service(name, "", false, qo)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if entry.Checks.AggregatedStatus() != api.HealthCritical {
+				continue
+			}
+
+			ins, err := r.opts.serializer.Unmarshal(entry.Service.Meta)
+			if err != nil {
+				log.Warnf("unmarshal service meta failed, id: %s err: %v", entry.Service.ID, err)
+				continue
+			}
+
+			kind, ep := ins.Kind, ins.Endpoint
+			if kind == "" || !r.matchKind(opts.Kinds, kind) {
+				continue
+			}
+
+			if r.reachable(ep, opts.DialTimeout) {
+				continue
+			}
+
+			seen[entry.Service.ID] = struct{}{}
+
+			orphan := &OrphanService{
+				ID:          entry.Service.ID,
+				Name:        entry.Service.Service,
+				Kind:        kind,
+				Node:        entry.Node.Node,
+				Endpoint:    ep,
+				CriticalFor: now.Sub(r.criticalSince(entry.Service.ID, entry.Checks, now)),
+			}
+
+			if orphan.CriticalFor < opts.Threshold {
+				continue
+			}
+
+			if !opts.DryRun {
+				if err = r.deregisterOrphan(ctx, entry, opts.AgentPort); err != nil {
+					log.Warnf("deregister orphan service failed, id: %s err: %v", entry.Service.ID, err)
+				} else {
+					orphan.Deregistered = true
+					r.criticals.Delete(entry.Service.ID)
+				}
+			}
+
+			orphans = append(orphans, orphan)
+		}
+	}
+
+	r.criticals.Range(func(key, _ any) bool {
+		if _, ok := seen[key.(string)]; !ok {
+			r.criticals.Delete(key)
+		}
+		return true
+	})
+
+	return orphans, nil
+}
+
+// 获取服务实例进入critical状态的时间
+// 心跳检测TTL到期后Consul保留最后一次上报的输出，从中解析TTL到期时间；否则以健康检测修改索引变化后首次发现的时间为准
+func (r *Registry) criticalSince(id string, checks api.HealthChecks, now time.Time) time.Time {
+	var (
+		index   uint64
+		expired time.Time
+	)
+
+	for _, check := range checks {
+		index = max(index, check.ModifyIndex)
+
+		if check.Status != api.HealthCritical {
+			continue
+		}
+
+		if at, ok := parseExpiresAt(check.Output); ok && (expired.IsZero() || at.Before(expired)) {
+			expired = at
+		}
+	}
+
+	if !expired.IsZero() && !expired.After(now) {
+		r.criticals.Store(id, &criticalService{index: index, since: expired})
+		return expired
+	}
+
+	if v, ok := r.criticals.Load(id); ok && v.(*criticalService).index == index {
+		return v.(*criticalService).since
+	}
+
+	r.criticals.Store(id, &criticalService{index: index, since: now})
+
+	return now
+}
+
+// 通过服务实例所在的Consul代理解注册服务，避免代理的反熵同步将通过Catalog解注册的服务重新注册
+func (r *Registry) deregisterOrphan(ctx context.Context, entry *api.ServiceEntry, port int) error {
+	client, err := r.agentClient(entry.Node.Address, port)
+	if err != nil {
+		return err
+	}
+
+	return client.Agent().ServiceDeregisterOpts(entry.Service.ID, (&api.QueryOptions{}).WithContext(ctx))
+}
+
+// 获取指定地址的Consul代理客户端，与当前客户端连接同一代理时直接使用当前客户端
+func (r *Registry) agentClient(addr string, port int) (*api.Client, error) {
+	_, p, err := net.SplitHostPort(r.opts.addr)
+	if err != nil {
+		p = defaultAgentPort
+	}
+
+	if port > 0 {
+		p = strconv.Itoa(port)
+	}
+
+	address := net.JoinHostPort(addr, p)
+
+	if addr == "" || address == r.opts.addr {
+		return r.opts.client, nil
+	}
+
+	config := api.DefaultConfig()
+	config.Address = address
+
+	return api.NewClient(config)
+}
+
+// 解析心跳输出中携带的TTL到期时间
+func parseExpiresAt(output string) (time.Time, bool) {
+	i := strings.LastIndex(output, checkExpiresAt)
+	if i < 0 {
+		return time.Time{}, false
+	}
+
+	sec, err := strconv.ParseInt(strings.TrimSpace(output[i+len(checkExpiresAt):]), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(sec, 0), true
+}
+
+// 检测服务类型是否匹配
+func (r *Registry) matchKind(kinds []string, kind string) bool {
+	if len(kinds) == 0 {
+		return true
+	}
+
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 探测服务实例是否可达
+func (r *Registry) reachable(ep string, timeout time.Duration) bool {
+	if ep == "" {
+		return false
+	}
+
+	e, err := endpoint.ParseEndpoint(ep)
+	if err != nil {
+		return false
+	}
+
+	conn, err := net.DialTimeout("tcp", e.Address(), timeout)
+	if err != nil {
+		return false
+	}
+
+	_ = conn.Close()
+
+	return true
+}
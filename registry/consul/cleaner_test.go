@@ -0,0 +1,236 @@
+package consul_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/dobyte/due/registry/consul/v2"
+	"github.com/dobyte/due/v2/registry"
+	"github.com/hashicorp/consul/api"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 模拟Consul目录，包含一个存活的网关实例、一个心跳已过期的节点实例及一个TCP检测失败的节点实例
+type fakeCatalog struct {
+	mu           sync.Mutex
+	expiredAt    int64
+	deregistered []string
+	endpoints    map[string]string   // 服务实例ID -> 暴露端点，未设置时为不可达端点
+	serializer   registry.Serializer // 元数据序列化器，为nil时写入原始元数据
+}
+
+func (c *fakeCatalog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/v1/catalog/services":
+		_ = json.NewEncoder(w).Encode(map[string][]string{"gate": nil, "node": nil})
+	case r.URL.Path == "/v1/health/service/gate":
+		_ = json.NewEncoder(w).Encode([]*api.ServiceEntry{c.entry("gate-1", "gate", api.HealthPassing, "")})
+	case r.URL.Path == "/v1/health/service/node":
+		_ = json.NewEncoder(w).Encode([]*api.ServiceEntry{
+			c.entry("node-1", "node", api.HealthCritical, fmt.Sprintf("TTL expired (last output before timeout follows): passed, expires at %d", c.expiredAt)),
+			c.entry("node-2", "node", api.HealthCritical, "dial tcp 127.0.0.1:1: connect: connection refused"),
+		})
+	case strings.HasPrefix(r.URL.Path, "/v1/agent/service/deregister/"):
+		c.deregistered = append(c.deregistered, strings.TrimPrefix(r.URL.Path, "/v1/agent/service/deregister/"))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (c *fakeCatalog) entry(id, kind, status, output string) *api.ServiceEntry {
+	ep, ok := c.endpoints[id]
+	if !ok {
+		ep = "grpc://127.0.0.1:1"
+	}
+
+	meta := map[string]string{"kind": kind, "endpoint": ep}
+
+	if c.serializer != nil {
+		meta, _ = c.serializer.Marshal(&registry.ServiceInstance{ID: id, Kind: kind, Endpoint: ep})
+	}
+
+	return &api.ServiceEntry{
+		Node: &api.Node{Node: "consul-1", Address: "127.0.0.1"},
+		Service: &api.AgentService{
+			ID:      id,
+			Service: kind,
+			Meta:    meta,
+		},
+		Checks: api.HealthChecks{{ServiceID: id, Status: status, Output: output, ModifyIndex: 10}},
+	}
+}
+
+func (c *fakeCatalog) list() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.deregistered...)
+}
+
+func TestRegistry_Cleanup(t *testing.T) {
+	var (
+		catalog = &fakeCatalog{expiredAt: time.Now().Add(-time.Hour).Unix()}
+		agent   = &fakeCatalog{}
+		ctx     = context.Background()
+	)
+
+	server := httptest.NewServer(catalog)
+	defer server.Close()
+
+	agentServer := httptest.NewServer(agent)
+	defer agentServer.Close()
+
+	reg := consul.NewRegistry(consul.WithAddr(strings.TrimPrefix(server.URL, "http://")))
+
+	orphans, err := reg.Cleanup(ctx, consul.CleanupOptions{Kinds: []string{"gate", "node"}, Threshold: 30 * time.Minute, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 心跳过期的实例从TTL到期时间起算，TCP检测失败的实例从首次发现起算
+	if len(orphans) != 1 || orphans[0].ID != "node-1" || orphans[0].CriticalFor < time.Hour || orphans[0].Deregistered {
+		t.Fatalf("unexpected dry run orphans: %+v", orphans)
+	}
+
+	if ids := catalog.list(); len(ids) != 0 {
+		t.Fatalf("dry run should not deregister, got: %v", ids)
+	}
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(agentServer.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	agentPort, _ := strconv.Atoi(port)
+
+	orphans, err = reg.Cleanup(ctx, consul.CleanupOptions{Kinds: []string{"node"}, AgentPort: agentPort})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(orphans) != 2 || !orphans[0].Deregistered || !orphans[1].Deregistered {
+		t.Fatalf("unexpected orphans: %+v", orphans)
+	}
+
+	// 通过服务实例所在的代理解注册
+	if ids := catalog.list(); len(ids) != 0 {
+		t.Fatalf("unexpected deregistered services on catalog agent: %v", ids)
+	}
+
+	if ids := agent.list(); len(ids) != 2 || ids[0] != "node-1" || ids[1] != "node-2" {
+		t.Fatalf("unexpected deregistered services: %v", ids)
+	}
+}
+
+// 以自定义前缀写入元数据的序列化器
+type prefixSerializer struct {
+	registry.Serializer
+}
+
+func (s *prefixSerializer) Marshal(ins *registry.ServiceInstance) (map[string]string, error) {
+	meta, err := s.Serializer.Marshal(ins)
+	if err != nil {
+		return nil, err
+	}
+
+	prefixed := make(map[string]string, len(meta))
+	for k, v := range meta {
+		prefixed["x-"+k] = v
+	}
+
+	return prefixed, nil
+}
+
+func (s *prefixSerializer) Unmarshal(meta map[string]string) (*registry.ServiceInstance, error) {
+	trimmed := make(map[string]string, len(meta))
+	for k, v := range meta {
+		trimmed[strings.TrimPrefix(k, "x-")] = v
+	}
+
+	return s.Serializer.Unmarshal(trimmed)
+}
+
+func TestRegistry_CleanupSerializer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	var (
+		serializer = &prefixSerializer{Serializer: consul.NewMetaSerializer()}
+		unreached  = "grpc://127.0.0.1:1"
+	)
+
+	catalog := &fakeCatalog{
+		expiredAt:  time.Now().Add(-time.Hour).Unix(),
+		endpoints:  map[string]string{"node-1": unreached, "node-2": "grpc://" + ln.Addr().String()},
+		serializer: serializer,
+	}
+
+	server := httptest.NewServer(catalog)
+	defer server.Close()
+
+	reg := consul.NewRegistry(
+		consul.WithAddr(strings.TrimPrefix(server.URL, "http://")),
+		consul.WithSerializer(serializer),
+	)
+
+	// 元数据经配置的序列化器解码后再过滤类型及探测连通性，可达的实例不会被清理
+	orphans, err := reg.Cleanup(context.Background(), consul.CleanupOptions{Kinds: []string{"node"}, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(orphans) != 1 || orphans[0].ID != "node-1" || orphans[0].Kind != "node" || orphans[0].Endpoint != unreached {
+		t.Fatalf("unexpected orphans: %+v", orphans)
+	}
+}
+
+func TestRegistry_CleanupCompressed(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	var (
+		serializer = consul.NewMetaSerializer(consul.WithCompressThreshold(64))
+		query      = "?isSecure=false&pad=" + strings.Repeat("0", 128) // 超出压缩阈值的端点
+		unreached  = "grpc://127.0.0.1:1" + query
+	)
+
+	catalog := &fakeCatalog{
+		expiredAt:  time.Now().Add(-time.Hour).Unix(),
+		endpoints:  map[string]string{"node-1": unreached, "node-2": "grpc://" + ln.Addr().String() + query},
+		serializer: serializer,
+	}
+
+	server := httptest.NewServer(catalog)
+	defer server.Close()
+
+	reg := consul.NewRegistry(
+		consul.WithAddr(strings.TrimPrefix(server.URL, "http://")),
+		consul.WithSerializer(serializer),
+	)
+
+	// 压缩的元数据经序列化器解码后再过滤类型及探测连通性，可达的实例不会被清理
+	orphans, err := reg.Cleanup(context.Background(), consul.CleanupOptions{Kinds: []string{"node"}, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(orphans) != 1 || orphans[0].ID != "node-1" || orphans[0].Kind != "node" || orphans[0].Endpoint != unreached {
+		t.Fatalf("unexpected orphans: %+v", orphans)
+	}
+}
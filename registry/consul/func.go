@@ -3,9 +3,69 @@ package consul
 import (
 	"fmt"
 	"github.com/dobyte/due/v2/registry"
+	"github.com/hashicorp/consul/api"
+	"strings"
 )
 
 // 构建实例ID
 func makeInsID(ins *registry.ServiceInstance) string {
 	return fmt.Sprintf("%s-%s", ins.Kind, ins.ID)
 }
+
+// 构建事件标签
+func makeEventTag(event int) string {
+	return fmt.Sprintf("%s:%d", eventTagPrefix, event)
+}
+
+// 构建事件标签列表
+func makeEventTags(events []int) []string {
+	tags := make([]string, 0, len(events))
+	for _, event := range events {
+		tags = append(tags, makeEventTag(event))
+	}
+
+	return tags
+}
+
+// 构建自定义标签，添加前缀以避免与事件标签冲突
+func makeCustomTag(tag string) string {
+	return fmt.Sprintf("%s:%s", customTagPrefix, tag)
+}
+
+// 构建服务实例标签列表，包含事件标签及自定义标签
+func makeTags(ins *registry.ServiceInstance) []string {
+	tags := makeEventTags(ins.Events)
+	for _, tag := range ins.Tags {
+		tags = append(tags, makeCustomTag(tag))
+	}
+
+	return tags
+}
+
+// 解析Consul服务标签中的自定义标签
+func parseCustomTags(tags []string) []string {
+	prefix := customTagPrefix + ":"
+	customs := make([]string, 0, len(tags))
+
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, prefix) {
+			customs = append(customs, strings.TrimPrefix(tag, prefix))
+		}
+	}
+
+	return customs
+}
+
+// 转换Consul健康检查状态，维护状态视为异常
+func toHealth(status string) registry.Health {
+	switch status {
+	case api.HealthPassing:
+		return registry.HealthPassing
+	case api.HealthWarning:
+		return registry.HealthWarning
+	case api.HealthCritical, api.HealthMaint:
+		return registry.HealthCritical
+	default:
+		return registry.HealthUnknown
+	}
+}
@@ -1,13 +1,48 @@
 package consul
 
 import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/binary"
 	"fmt"
+	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/registry"
 	"github.com/dobyte/due/v2/utils/xconv"
+	"io"
+	"sort"
 	"strings"
 )
 
-const metaValueSize = 512
+// Consul元数据限制
+const (
+	metaMaxKeys   = 64  // 最大字段数
+	metaKeySize   = 128 // 字段名最大长度
+	metaValueSize = 512 // 字段值最大长度
+)
+
+// 压缩元数据值的前缀，用于区分压缩值与原始值
+const compressedPrefix = "gz:"
+
+// 检测元数据是否超出Consul的限制
+func checkMeta(meta map[string]string) error {
+	if len(meta) > metaMaxKeys {
+		return fmt.Errorf("%w: %d meta keys exceeds consul limit %d", errors.ErrMetaTooLarge, len(meta), metaMaxKeys)
+	}
+
+	for key, val := range meta {
+		if len(key) > metaKeySize {
+			return fmt.Errorf("%w: meta key %s exceeds consul limit %d", errors.ErrMetaTooLarge, key, metaKeySize)
+		}
+
+		if len(val) > metaValueSize {
+			return fmt.Errorf("%w: meta value of %s exceeds consul limit %d", errors.ErrMetaTooLarge, key, metaValueSize)
+		}
+	}
+
+	return nil
+}
 
 // 编码元数据路由
 func marshalMetaRoutes(routes []registry.Route) map[string]string {
@@ -21,6 +56,11 @@ func marshalMetaRoutes(routes []registry.Route) map[string]string {
 	for _, route := range routes {
 		val := fmt.Sprintf("%d-%d-%d", route.ID, xconv.Int(route.Stateful), xconv.Int(route.Internal))
 
+		// 仅在存在有状态、内部以外的属性时追加完整属性位域，保证旧版本解析器可正常解析其余路由
+		if attrs := route.Attrs(); attrs&^(registry.RouteAttrStateful|registry.RouteAttrInternal) != 0 {
+			val += "-" + xconv.String(uint8(attrs))
+		}
+
 		if s := len(items); s == 0 {
 			size = len(val)
 		} else {
@@ -70,19 +110,166 @@ func unmarshalMetaRoutes(metas map[string]string) []registry.Route {
 		}
 
 		for _, item := range strings.Split(items, ",") {
+			neg := strings.HasPrefix(item, "-")
+			if neg {
+				item = item[1:]
+			}
+
 			val := strings.Split(item, "-")
 
-			if len(val) != 3 {
+			if len(val) < 3 {
 				continue
 			}
 
-			routes = append(routes, registry.Route{
+			route := registry.Route{
 				ID:       xconv.Int32(val[0]),
 				Stateful: xconv.Bool(val[1]),
 				Internal: xconv.Bool(val[2]),
-			})
+			}
+
+			if neg {
+				route.ID = -route.ID
+			}
+
+			// 第四段为完整属性位域，忽略未知的属性位及多余的字段
+			if len(val) > 3 {
+				route.SetAttrs(registry.RouteAttr(xconv.Uint8(val[3])))
+			}
+
+			routes = append(routes, route)
 		}
 	}
 
 	return routes
 }
+
+// 紧凑编码元数据路由
+// 路由按ID排序后，依次写入ID差值（zigzag）与属性位域两个变长整数，压缩后以base64编码为单个字段值
+func marshalCompactRoutes(routes []registry.Route) (string, error) {
+	sorted := make([]registry.Route, len(routes))
+	copy(sorted, routes)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	var (
+		prev int64
+		raw  = make([]byte, 0, len(sorted)*2)
+	)
+
+	for _, route := range sorted {
+		delta := int64(route.ID) - prev
+		prev = int64(route.ID)
+
+		raw = binary.AppendUvarint(raw, uint64((delta<<1)^(delta>>63)))
+		raw = binary.AppendUvarint(raw, uint64(route.Attrs()))
+	}
+
+	buf := &bytes.Buffer{}
+
+	w, err := flate.NewWriter(buf, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = w.Write(raw); err != nil {
+		return "", err
+	}
+
+	if err = w.Close(); err != nil {
+		return "", err
+	}
+
+	return base64.RawStdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// 解码紧凑编码的元数据路由
+func unmarshalCompactRoutes(value string) ([]registry.Route, error) {
+	data, err := base64.RawStdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		prev   int64
+		routes = make([]registry.Route, 0)
+	)
+
+	for len(raw) > 0 {
+		zigzag, n := binary.Uvarint(raw)
+		if n <= 0 {
+			return nil, errors.ErrInvalidArgument
+		}
+		raw = raw[n:]
+
+		attrs, n := binary.Uvarint(raw)
+		if n <= 0 {
+			return nil, errors.ErrInvalidArgument
+		}
+		raw = raw[n:]
+
+		prev += int64(zigzag>>1) ^ -int64(zigzag&1)
+
+		route := registry.Route{ID: int32(prev)}
+		route.SetAttrs(registry.RouteAttr(attrs))
+
+		routes = append(routes, route)
+	}
+
+	return routes, nil
+}
+
+// 压缩元数据值，以gzip压缩后base64编码并添加压缩前缀；压缩后未变短时返回原始值
+// 原始值本身以压缩前缀开头时始终压缩，避免解码时被误判为压缩值
+func compressMetaValue(value string) (string, error) {
+	buf := &bytes.Buffer{}
+
+	w, err := gzip.NewWriterLevel(buf, gzip.BestCompression)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = w.Write([]byte(value)); err != nil {
+		return "", err
+	}
+
+	if err = w.Close(); err != nil {
+		return "", err
+	}
+
+	compressed := compressedPrefix + base64.RawStdEncoding.EncodeToString(buf.Bytes())
+
+	if len(compressed) >= len(value) && !strings.HasPrefix(value, compressedPrefix) {
+		return value, nil
+	}
+
+	return compressed, nil
+}
+
+// 解压元数据值，不带压缩前缀的值原样返回
+func decompressMetaValue(value string) (string, error) {
+	if !strings.HasPrefix(value, compressedPrefix) {
+		return value, nil
+	}
+
+	data, err := base64.RawStdEncoding.DecodeString(value[len(compressedPrefix):])
+	if err != nil {
+		return "", err
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	return string(raw), nil
+}
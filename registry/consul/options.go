@@ -2,7 +2,10 @@ package consul
 
 import (
 	"context"
+	"github.com/dobyte/due/v2/config"
+	"github.com/dobyte/due/v2/core/clock"
 	"github.com/dobyte/due/v2/etc"
+	"github.com/dobyte/due/v2/registry"
 	"github.com/hashicorp/consul/api"
 )
 
@@ -13,7 +16,14 @@ const (
 	defaultHealthCheckTimeout             = 5
 	defaultHeartbeatCheck                 = true
 	defaultHeartbeatCheckInterval         = 10
-	defaultDeregisterCriticalServiceAfter = 30
+	defaultDeregisterCriticalServiceAfter = 60
+	defaultPassingOnly                    = true
+	defaultReplaceExisting                = false
+	defaultCompressThreshold              = 0
+)
+
+const (
+	minDeregisterCriticalServiceAfter = 60 // Consul允许的最小自动注销服务时间（秒）
 )
 
 const (
@@ -24,8 +34,33 @@ const (
 	defaultHeartbeatCheckKey                 = "etc.registry.consul.heartbeatCheck"
 	defaultHeartbeatCheckIntervalKey         = "etc.registry.consul.heartbeatCheckInterval"
 	defaultDeregisterCriticalServiceAfterKey = "etc.registry.consul.deregisterCriticalServiceAfter"
+	defaultPassingOnlyKey                    = "etc.registry.consul.passingOnly"
+	defaultReplaceExistingKey                = "etc.registry.consul.replaceExisting"
+	defaultCompressThresholdKey              = "etc.registry.consul.compressThreshold"
 )
 
+const defaultPrefixKey = "etc.registry.consul"
+
+func init() {
+	config.RegisterSchema(&config.Schema{
+		Name:   "consul registry",
+		Prefix: defaultPrefixKey,
+		Strict: true,
+		Fields: []*config.Field{
+			{Key: defaultAddrKey, Type: config.TypeString, Default: defaultAddr},
+			{Key: defaultHealthCheckKey, Type: config.TypeBool, Default: defaultHealthCheck},
+			{Key: defaultHealthCheckIntervalKey, Type: config.TypeInt, Default: defaultHealthCheckInterval},
+			{Key: defaultHealthCheckTimeoutKey, Type: config.TypeInt, Default: defaultHealthCheckTimeout},
+			{Key: defaultHeartbeatCheckKey, Type: config.TypeBool, Default: defaultHeartbeatCheck},
+			{Key: defaultHeartbeatCheckIntervalKey, Type: config.TypeInt, Default: defaultHeartbeatCheckInterval},
+			{Key: defaultDeregisterCriticalServiceAfterKey, Type: config.TypeInt, Default: defaultDeregisterCriticalServiceAfter},
+			{Key: defaultPassingOnlyKey, Type: config.TypeBool, Default: defaultPassingOnly},
+			{Key: defaultReplaceExistingKey, Type: config.TypeBool, Default: defaultReplaceExisting},
+			{Key: defaultCompressThresholdKey, Type: config.TypeInt, Default: defaultCompressThreshold},
+		},
+	})
+}
+
 type Option func(o *options)
 
 type options struct {
@@ -53,6 +88,10 @@ type options struct {
 	// 默认5秒
 	healthCheckTimeout int
 
+	// 命名端点健康检查配置，仅在启用健康检查后生效
+	// 以命名端点名称为键，未配置的命名端点使用全局健康检查时间间隔及超时时间，默认为nil
+	endpointChecks map[string]EndpointCheck
+
 	// 是否启用心跳检查
 	// 默认为true
 	enableHeartbeatCheck bool
@@ -62,10 +101,42 @@ type options struct {
 	heartbeatCheckInterval int
 
 	// 健康检测失败后自动注销服务时间（秒）
-	// 默认30秒
+	// 小于等于0时永不自动注销服务；大于0时最小为60秒（Consul限制）
+	// 默认60秒
 	deregisterCriticalServiceAfter int
+
+	// 服务实例序列化器
+	// 默认为Consul元数据序列化器，元数据值的压缩阈值取自etc.registry.consul.compressThreshold配置
+	serializer registry.Serializer
+
+	// 是否仅发现健康检查通过的服务实例
+	// 为false时同时返回处于警告或异常状态的服务实例，并通过健康状态标识，负载均衡时将跳过这些实例
+	// 默认为true
+	passingOnly bool
+
+	// 是否替换已存在的注册
+	// 节点快速重启时Consul可能仍保留相同ID的旧注册，启用后首次注册前将清理旧注册遗留的检查，避免旧检查导致服务被判定为异常
+	// 默认为false
+	replaceExisting bool
+
+	// 时钟
+	// 用于心跳定时，测试中可替换为模拟时钟，默认为系统时钟
+	clock clock.Clock
+
+	// 心跳健康检查函数
+	// 每次更新心跳TTL前调用，返回错误时以异常状态上报心跳，Consul将服务实例标记为异常以摘除流量；默认为nil，始终上报健康状态
+	healthChecker HealthChecker
 }
 
+// EndpointCheck 端点健康检查配置，小于等于0的字段使用全局配置
+type EndpointCheck struct {
+	Interval int // 健康检查时间间隔（秒）
+	Timeout  int // 健康检查超时时间（秒）
+}
+
+// HealthChecker 心跳健康检查函数，返回错误时心跳以异常状态上报，错误信息作为检查输出
+type HealthChecker func(ctx context.Context) error
+
 func defaultOptions() *options {
 	return &options{
 		ctx:                            context.Background(),
@@ -76,6 +147,10 @@ func defaultOptions() *options {
 		enableHeartbeatCheck:           etc.Get(defaultHeartbeatCheckKey, defaultHeartbeatCheck).Bool(),
 		heartbeatCheckInterval:         etc.Get(defaultHeartbeatCheckIntervalKey, defaultHeartbeatCheckInterval).Int(),
 		deregisterCriticalServiceAfter: etc.Get(defaultDeregisterCriticalServiceAfterKey, defaultDeregisterCriticalServiceAfter).Int(),
+		serializer:                     NewMetaSerializer(WithCompressThreshold(etc.Get(defaultCompressThresholdKey, defaultCompressThreshold).Int())),
+		passingOnly:                    etc.Get(defaultPassingOnlyKey, defaultPassingOnly).Bool(),
+		replaceExisting:                etc.Get(defaultReplaceExistingKey, defaultReplaceExisting).Bool(),
+		clock:                          clock.Real(),
 	}
 }
 
@@ -109,6 +184,17 @@ func WithHealthCheckTimeout(timeout int) Option {
 	return func(o *options) { o.healthCheckTimeout = timeout }
 }
 
+// WithEndpointCheck 设置命名端点的健康检查配置，可为内部端点设置更短的检查间隔
+func WithEndpointCheck(name string, check EndpointCheck) Option {
+	return func(o *options) {
+		if o.endpointChecks == nil {
+			o.endpointChecks = make(map[string]EndpointCheck)
+		}
+
+		o.endpointChecks[name] = check
+	}
+}
+
 // WithEnableHeartbeatCheck 设置是否启用心跳检查
 func WithEnableHeartbeatCheck(enable bool) Option {
 	return func(o *options) { o.enableHeartbeatCheck = enable }
@@ -119,7 +205,32 @@ func WithHeartbeatCheckInterval(interval int) Option {
 	return func(o *options) { o.heartbeatCheckInterval = interval }
 }
 
-// WithDeregisterCriticalServiceAfter 设置健康检测失败后自动注销服务时间
+// WithDeregisterCriticalServiceAfter 设置健康检测失败后自动注销服务时间，小于等于0时永不自动注销服务，小于Consul允许的最小值60秒时使用最小值
 func WithDeregisterCriticalServiceAfter(after int) Option {
 	return func(o *options) { o.deregisterCriticalServiceAfter = after }
 }
+
+// WithSerializer 设置服务实例序列化器
+func WithSerializer(serializer registry.Serializer) Option {
+	return func(o *options) { o.serializer = serializer }
+}
+
+// WithPassingOnly 设置是否仅发现健康检查通过的服务实例
+func WithPassingOnly(passingOnly bool) Option {
+	return func(o *options) { o.passingOnly = passingOnly }
+}
+
+// WithReplaceExisting 设置是否替换已存在的注册，启用后首次注册前将清理相同ID的旧注册遗留的检查
+func WithReplaceExisting(replace bool) Option {
+	return func(o *options) { o.replaceExisting = replace }
+}
+
+// WithClock 设置时钟，测试中可替换为模拟时钟以精确控制心跳定时
+func WithClock(clock clock.Clock) Option {
+	return func(o *options) { o.clock = clock }
+}
+
+// WithHealthChecker 设置心跳健康检查函数，返回错误时心跳以异常状态上报，仅在启用心跳检查后生效
+func WithHealthChecker(checker HealthChecker) Option {
+	return func(o *options) { o.healthChecker = checker }
+}
@@ -3,28 +3,44 @@ package consul
 import (
 	"context"
 	"fmt"
+	"github.com/dobyte/due/v2/core/retry"
+	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/log"
 	"github.com/dobyte/due/v2/registry"
-	"github.com/dobyte/due/v2/utils/xconv"
+	"github.com/dobyte/due/v2/utils/xnet"
 	"github.com/hashicorp/consul/api"
 	"net"
-	"net/url"
+	"sort"
 	"strconv"
+	"sync"
 	"time"
 )
 
 const (
-	checkIDFormat     = "service:%s"
-	checkUpdateOutput = "passed"
-	metaFieldID       = "id"
-	metaFieldKind     = "kind"
-	metaFieldAlias    = "alias"
-	metaFieldState    = "state"
-	metaFieldRoutes   = "routes"
-	metaFieldEvents   = "events"
-	metaFieldWeight   = "weight"
-	metaFieldServices = "services"
-	metaFieldEndpoint = "endpoint"
+	checkIDFormat      = "service:%s"
+	endpointCheckID    = "service:%s:endpoint"    // 主端点健康检查ID
+	namedCheckIDFormat = "service:%s:endpoint:%s" // 命名端点健康检查ID
+	taggedAddrFormat   = "endpoint_%s"            // 主端点的标签地址键，以角色前缀区分命名端点，避免与同名协议的命名端点相互覆盖
+	checkUpdateOutput  = "passed, expires at %d"
+	metaFieldID        = "id"
+	metaFieldKind      = "kind"
+	metaFieldAlias     = "alias"
+	metaFieldState     = "state"
+	metaFieldRoutes    = "routes"
+	metaFieldEvents    = "events"
+	metaFieldWeight    = "weight"
+	metaFieldServices  = "services"
+	metaFieldEndpoint  = "endpoint"
+	metaFieldEndpoints = "endpoints"
+	metaFieldLoad      = "load"
+	eventTagPrefix     = "event"
+	customTagPrefix    = "tag"
+)
+
+const (
+	minReregisterInterval = time.Second     // 最小重新注册间隔
+	maxReregisterInterval = time.Minute     // 最大重新注册间隔
+	heartbeatSendTimeout  = 3 * time.Second // 通知心跳协程的超时时间
 )
 
 type registrar struct {
@@ -32,6 +48,8 @@ type registrar struct {
 	cancel      context.CancelFunc
 	registry    *Registry
 	chHeartbeat chan string
+	rw          sync.RWMutex
+	ins         *registry.ServiceInstance // 最近一次注册的服务实例
 }
 
 func newRegistrar(registry *Registry) *registrar {
@@ -49,17 +67,54 @@ func newRegistrar(registry *Registry) *registrar {
 
 // 注册服务
 func (r *registrar) register(ctx context.Context, ins *registry.ServiceInstance) error {
-	raw, err := url.Parse(ins.Endpoint)
-	if err != nil {
+	if err := r.doRegister(ctx, ins); err != nil {
 		return err
 	}
 
-	host, p, err := net.SplitHostPort(raw.Host)
-	if err != nil {
-		return err
+	r.rw.Lock()
+	r.ins = ins
+	r.rw.Unlock()
+
+	if r.registry.opts.enableHeartbeatCheck {
+		return r.notifyHeartbeat(ctx, makeInsID(ins))
+	}
+
+	return nil
+}
+
+// 通知心跳协程开始心跳，心跳协程已退出或无法及时接收时返回错误，避免注册流程永久阻塞
+func (r *registrar) notifyHeartbeat(ctx context.Context, insID string) error {
+	timer := time.NewTimer(heartbeatSendTimeout)
+	defer timer.Stop()
+
+	select {
+	case r.chHeartbeat <- insID:
+		return nil
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return errors.ErrDeadlineExceeded
+	}
+}
+
+// 重新注册服务，用于Consul代理重启后丢失服务及检测状态时进行自愈
+func (r *registrar) reregister(ctx context.Context) error {
+	r.rw.RLock()
+	ins := r.ins
+	r.rw.RUnlock()
+
+	if ins == nil {
+		return nil
 	}
 
-	port, err := strconv.Atoi(p)
+	return r.doRegister(ctx, ins)
+}
+
+// 执行注册服务操作
+func (r *registrar) doRegister(ctx context.Context, ins *registry.ServiceInstance) error {
+	scheme, host, port, err := xnet.SplitEndpoint(ins.Endpoint)
 	if err != nil {
 		return err
 	}
@@ -71,59 +126,129 @@ func (r *registrar) register(ctx context.Context, ins *registry.ServiceInstance)
 	registration.Name = ins.Name
 	registration.Address = host
 	registration.Port = port
-	registration.TaggedAddresses = map[string]api.ServiceAddress{raw.Scheme: {Address: host, Port: port}}
-	registration.Meta = make(map[string]string, 7)
-	registration.Meta[metaFieldID] = ins.ID
-	registration.Meta[metaFieldKind] = ins.Kind
-	registration.Meta[metaFieldAlias] = ins.Alias
-	registration.Meta[metaFieldState] = ins.State
-	registration.Meta[metaFieldEndpoint] = ins.Endpoint
-	registration.Meta[metaFieldEvents] = xconv.Json(ins.Events)
-	registration.Meta[metaFieldWeight] = xconv.String(ins.Weight)
-	registration.Meta[metaFieldServices] = xconv.Json(ins.Services)
+	registration.Tags = makeTags(ins)
+	registration.TaggedAddresses = map[string]api.ServiceAddress{fmt.Sprintf(taggedAddrFormat, scheme): {Address: host, Port: port}}
+	registration.Meta, err = r.registry.opts.serializer.Marshal(ins)
+	if err != nil {
+		return err
+	}
 
-	for field, value := range marshalMetaRoutes(ins.Routes) {
-		registration.Meta[field] = value
+	if err = checkMeta(registration.Meta); err != nil {
+		return err
 	}
 
+	names := make([]string, 0, len(ins.Endpoints))
+	for name, endpoint := range ins.Endpoints {
+		_, addr, p, err := xnet.SplitEndpoint(endpoint)
+		if err != nil {
+			return err
+		}
+
+		registration.TaggedAddresses[name] = api.ServiceAddress{Address: addr, Port: p}
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
 	if r.registry.opts.enableHealthCheck {
-		registration.Checks = append(registration.Checks, &api.AgentServiceCheck{
-			TCP:                            raw.Host,
-			Interval:                       fmt.Sprintf("%ds", r.registry.opts.healthCheckInterval),
-			Timeout:                        fmt.Sprintf("%ds", r.registry.opts.healthCheckTimeout),
-			DeregisterCriticalServiceAfter: fmt.Sprintf("%ds", r.registry.opts.deregisterCriticalServiceAfter),
-		})
+		registration.Checks = append(registration.Checks, r.tcpCheck(fmt.Sprintf(endpointCheckID, insID), "endpoint", host, port, EndpointCheck{}))
+
+		for _, name := range names {
+			addr := registration.TaggedAddresses[name]
+			check := r.registry.opts.endpointChecks[name]
+
+			registration.Checks = append(registration.Checks, r.tcpCheck(fmt.Sprintf(namedCheckIDFormat, insID, name), fmt.Sprintf("endpoint %s", name), addr.Address, addr.Port, check))
+		}
 	}
 
 	if r.registry.opts.enableHeartbeatCheck {
 		registration.Checks = append(registration.Checks, &api.AgentServiceCheck{
 			CheckID:                        fmt.Sprintf(checkIDFormat, insID),
 			TTL:                            fmt.Sprintf("%ds", r.registry.opts.heartbeatCheckInterval),
-			DeregisterCriticalServiceAfter: fmt.Sprintf("%ds", r.registry.opts.deregisterCriticalServiceAfter),
+			DeregisterCriticalServiceAfter: r.deregisterCriticalServiceAfter(),
 		})
 	}
 
-	if err = r.registry.opts.client.Agent().ServiceRegister(registration); err != nil {
-		return err
+	return r.registry.opts.client.Agent().ServiceRegisterOpts(registration, api.ServiceRegisterOpts{}.WithContext(ctx))
+}
+
+// 构建端点TCP健康检查，检查配置小于等于0的字段使用全局配置
+func (r *registrar) tcpCheck(checkID, name, host string, port int, check EndpointCheck) *api.AgentServiceCheck {
+	interval, timeout := check.Interval, check.Timeout
+
+	if interval <= 0 {
+		interval = r.registry.opts.healthCheckInterval
 	}
 
-	if r.registry.opts.enableHeartbeatCheck {
-		r.chHeartbeat <- insID
+	if timeout <= 0 {
+		timeout = r.registry.opts.healthCheckTimeout
 	}
 
-	return nil
+	return &api.AgentServiceCheck{
+		CheckID:                        checkID,
+		Name:                           name,
+		TCP:                            net.JoinHostPort(host, strconv.Itoa(port)),
+		Interval:                       fmt.Sprintf("%ds", interval),
+		Timeout:                        fmt.Sprintf("%ds", timeout),
+		DeregisterCriticalServiceAfter: r.deregisterCriticalServiceAfter(),
+	}
+}
+
+// 清理服务实例关联的全部检查，清理失败时不影响注册及解注册
+func (r *registrar) cleanup(ctx context.Context, insID string) {
+	qo := (&api.QueryOptions{}).WithContext(ctx)
+
+	checks, err := r.registry.opts.client.Agent().ChecksWithFilterOpts(fmt.Sprintf("ServiceID == %s", strconv.Quote(insID)), qo)
+	if err != nil {
+		log.Warnf("query existing checks failed, id = %s: %v", insID, err)
+		return
+	}
+
+	for checkID := range checks {
+		if err = r.registry.opts.client.Agent().CheckDeregisterOpts(checkID, qo); err != nil {
+			log.Warnf("deregister check failed, id = %s check = %s: %v", insID, checkID, err)
+			continue
+		}
+
+		log.Infof("check deregistered, id = %s check = %s", insID, checkID)
+	}
+}
+
+// 获取健康检测失败后自动注销服务时间，小于等于0时返回空，Consul将永不自动注销服务
+func (r *registrar) deregisterCriticalServiceAfter() string {
+	after := r.registry.opts.deregisterCriticalServiceAfter
+
+	if after <= 0 {
+		return ""
+	}
+
+	if after < minDeregisterCriticalServiceAfter {
+		log.Warnf("deregister critical service after %ds is less than consul's minimum %ds, use the minimum instead", after, minDeregisterCriticalServiceAfter)
+		after = minDeregisterCriticalServiceAfter
+	}
+
+	return fmt.Sprintf("%ds", after)
 }
 
 // 解注册服务
 func (r *registrar) deregister(ctx context.Context, ins *registry.ServiceInstance) error {
+	return r.stop(ctx, makeInsID(ins))
+}
+
+// 停止心跳并解注册服务
+// 心跳协程随上下文取消退出，不关闭心跳通道，避免与并发注册的发送操作竞争
+func (r *registrar) stop(ctx context.Context, insID string) error {
 	r.cancel()
-	close(r.chHeartbeat)
 
-	insID := makeInsID(ins)
+	if _, ok := r.registry.registrars.LoadAndDelete(insID); !ok {
+		return nil
+	}
 
-	r.registry.registrars.Delete(insID)
+	if r.registry.opts.enableHealthCheck || r.registry.opts.enableHeartbeatCheck {
+		r.cleanup(ctx, insID)
+	}
 
-	return r.registry.opts.client.Agent().ServiceDeregister(insID)
+	return r.registry.opts.client.Agent().ServiceDeregisterOpts(insID, (&api.QueryOptions{}).WithContext(ctx))
 }
 
 // 心跳检测
@@ -135,15 +260,11 @@ func (r *registrar) keepHeartbeat() {
 
 	for {
 		select {
-		case insID, ok := <-r.chHeartbeat:
+		case insID := <-r.chHeartbeat:
 			if cancel != nil {
 				cancel()
 			}
 
-			if !ok {
-				return
-			}
-
 			ctx, cancel = context.WithCancel(r.ctx)
 			go r.heartbeat(ctx, insID)
 		case <-r.ctx.Done():
@@ -156,28 +277,81 @@ func (r *registrar) keepHeartbeat() {
 }
 
 // 心跳
+// 更新TTL失败时（如Consul代理重启导致服务及检测状态丢失）将尝试重新注册服务，重新注册失败时按指数退避重试，避免代理配置错误时频繁重试
 func (r *registrar) heartbeat(ctx context.Context, insID string) {
-	checkID := fmt.Sprintf(checkIDFormat, insID)
+	var (
+		checkID  = fmt.Sprintf(checkIDFormat, insID)
+		policy   = retry.Exponential(minReregisterInterval, maxReregisterInterval)
+		failures int
+		next     time.Time
+		qo       = (&api.QueryOptions{}).WithContext(ctx)
+	)
+
+	update := func() {
+		status, output := r.check(ctx)
+
+		err := r.registry.opts.client.Agent().UpdateTTLOpts(checkID, output, status, qo)
+		if err == nil {
+			return
+		}
 
-	err := r.registry.opts.client.Agent().UpdateTTL(checkID, checkUpdateOutput, api.HealthPassing)
-	if err != nil {
 		log.Warnf("update heartbeat ttl failed: %v", err)
+
+		if r.registry.opts.clock.Now().Before(next) {
+			return
+		}
+
+		if err = r.reregister(ctx); err != nil {
+			failures++
+			interval := policy.Backoff(failures)
+			log.Warnf("reregister service instance failed, retry after %v: %v", interval, err)
+			next = r.registry.opts.clock.Now().Add(interval)
+			return
+		}
+
+		log.Infof("service instance reregistered, id = %s", insID)
+
+		failures, next = 0, time.Time{}
+
+		if err = r.registry.opts.client.Agent().UpdateTTLOpts(checkID, output, status, qo); err != nil {
+			log.Warnf("update heartbeat ttl failed: %v", err)
+		}
 	}
 
-	ticker := time.NewTicker(time.Duration(r.registry.opts.heartbeatCheckInterval) * time.Second / 2)
+	update()
+
+	ticker := r.registry.opts.clock.NewTicker(time.Duration(r.registry.opts.heartbeatCheckInterval) * time.Second / 2)
 	defer ticker.Stop()
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			if ctx.Err() != nil {
 				return
 			}
 
-			if err = r.registry.opts.client.Agent().UpdateTTL(checkID, checkUpdateOutput, api.HealthPassing); err != nil {
-				log.Warnf("update heartbeat ttl failed: %v", err)
-			}
+			update()
 		case <-ctx.Done():
 			return
 		}
 	}
 }
+
+// 执行心跳健康检查，返回心跳上报的状态与输出
+func (r *registrar) check(ctx context.Context) (string, string) {
+	if r.registry.opts.healthChecker == nil {
+		return api.HealthPassing, r.passedOutput()
+	}
+
+	if err := r.registry.opts.healthChecker(ctx); err != nil {
+		return api.HealthCritical, err.Error()
+	}
+
+	return api.HealthPassing, r.passedOutput()
+}
+
+// 心跳通过时上报的输出，携带TTL的到期时间，TTL到期后Consul会保留该输出，供孤儿服务清理计算进入critical状态的时间
+func (r *registrar) passedOutput() string {
+	ttl := time.Duration(r.registry.opts.heartbeatCheckInterval) * time.Second
+
+	return fmt.Sprintf(checkUpdateOutput, r.registry.opts.clock.Now().Add(ttl).Unix())
+}
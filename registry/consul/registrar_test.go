@@ -0,0 +1,708 @@
+package consul_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/dobyte/due/registry/consul/v2"
+	"github.com/dobyte/due/v2/core/clock"
+	"github.com/dobyte/due/v2/registry"
+	"github.com/hashicorp/consul/api"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 模拟Consul代理，restart后将丢失已注册的服务及检测状态
+type fakeAgent struct {
+	mu         sync.Mutex
+	registered bool
+	registers  int
+	updates    int
+	removes    int
+}
+
+func (a *fakeAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/v1/agent/service/register":
+		a.registered = true
+		a.registers++
+	case strings.HasPrefix(r.URL.Path, "/v1/agent/service/deregister/"):
+		a.registered = false
+		a.removes++
+	case strings.HasPrefix(r.URL.Path, "/v1/agent/check/update/"):
+		if !a.registered {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("Unknown check ID"))
+			return
+		}
+		a.updates++
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (a *fakeAgent) restart() {
+	a.mu.Lock()
+	a.registered = false
+	a.mu.Unlock()
+}
+
+func (a *fakeAgent) count() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.registers
+}
+
+func (a *fakeAgent) heartbeats() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.updates
+}
+
+func (a *fakeAgent) deregisters() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.removes
+}
+
+func TestRegistry_HeartbeatInterval(t *testing.T) {
+	agent := &fakeAgent{}
+	server := httptest.NewServer(agent)
+	defer server.Close()
+
+	client, err := api.NewClient(&api.Config{Address: strings.TrimPrefix(server.URL, "http://")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := clock.NewFake(time.Now())
+
+	reg := consul.NewRegistry(
+		consul.WithClient(client),
+		consul.WithEnableHealthCheck(false),
+		consul.WithHeartbeatCheckInterval(10),
+		consul.WithClock(fake),
+	)
+
+	ins := &registry.ServiceInstance{
+		ID:       "test-heartbeat",
+		Name:     "node",
+		Endpoint: "grpc://127.0.0.1:3553",
+	}
+
+	if err = reg.Register(context.Background(), ins); err != nil {
+		t.Fatal(err)
+	}
+	defer reg.Deregister(context.Background(), ins)
+
+	// 等待心跳协程完成首次心跳并创建定时器
+	fake.BlockUntil(1)
+
+	waitHeartbeats := func(n int) {
+		for i := 0; i < 100 && agent.heartbeats() < n; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if count := agent.heartbeats(); count != n {
+			t.Fatalf("unexpected heartbeats, expected: %d, got: %d", n, count)
+		}
+	}
+
+	waitHeartbeats(1)
+
+	// 心跳间隔为心跳检查时间间隔的一半
+	fake.Advance(4 * time.Second)
+
+	time.Sleep(50 * time.Millisecond)
+
+	if count := agent.heartbeats(); count != 1 {
+		t.Fatalf("heartbeat fired too early, heartbeats: %d", count)
+	}
+
+	fake.Advance(time.Second)
+	waitHeartbeats(2)
+
+	fake.Advance(5 * time.Second)
+	waitHeartbeats(3)
+}
+
+func TestRegistry_Reregister(t *testing.T) {
+	agent := &fakeAgent{}
+	server := httptest.NewServer(agent)
+	defer server.Close()
+
+	client, err := api.NewClient(&api.Config{Address: strings.TrimPrefix(server.URL, "http://")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reg := consul.NewRegistry(
+		consul.WithClient(client),
+		consul.WithEnableHealthCheck(false),
+		consul.WithHeartbeatCheckInterval(1),
+	)
+
+	ins := &registry.ServiceInstance{
+		ID:       "test-reregister",
+		Name:     "node",
+		Endpoint: "grpc://127.0.0.1:3553",
+	}
+
+	if err = reg.Register(context.Background(), ins); err != nil {
+		t.Fatal(err)
+	}
+
+	agent.restart()
+
+	for i := 0; i < 50; i++ {
+		if agent.count() >= 2 {
+			break
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if count := agent.count(); count < 2 {
+		t.Fatalf("service instance is not reregistered, registers: %d", count)
+	}
+
+	_ = reg.Deregister(context.Background(), ins)
+}
+
+func TestRegistry_Stop(t *testing.T) {
+	agent := &fakeAgent{}
+	server := httptest.NewServer(agent)
+	defer server.Close()
+
+	client, err := api.NewClient(&api.Config{Address: strings.TrimPrefix(server.URL, "http://")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reg := consul.NewRegistry(
+		consul.WithClient(client),
+		consul.WithEnableHealthCheck(false),
+		consul.WithHeartbeatCheckInterval(1),
+	)
+
+	instances := []*registry.ServiceInstance{
+		{ID: "test-stop-1", Name: "node", Endpoint: "grpc://127.0.0.1:3553"},
+		{ID: "test-stop-2", Name: "node", Endpoint: "grpc://127.0.0.1:3554"},
+	}
+
+	for _, ins := range instances {
+		if err = reg.Register(context.Background(), ins); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = reg.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 停止时解注册所有服务实例
+	if err = reg.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if removes := agent.deregisters(); removes != len(instances) {
+		t.Fatalf("unexpected deregisters: %d", removes)
+	}
+
+	// 停止后再次解注册或停止不会重复解注册
+	if err = reg.Deregister(context.Background(), instances[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = reg.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	heartbeats := agent.heartbeats()
+
+	time.Sleep(1500 * time.Millisecond)
+
+	if agent.heartbeats() != heartbeats {
+		t.Fatal("heartbeat continued after stop")
+	}
+}
+
+func TestRegistry_RegisterTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 模拟无响应的Consul代理
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := api.NewClient(&api.Config{Address: strings.TrimPrefix(server.URL, "http://")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reg := consul.NewRegistry(consul.WithClient(client), consul.WithEnableHealthCheck(false))
+
+	ins := &registry.ServiceInstance{
+		ID:       "test-timeout",
+		Name:     "node",
+		Endpoint: "grpc://127.0.0.1:3553",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	if err = reg.Register(ctx, ins); err == nil {
+		t.Fatal("expected register timeout")
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("register is not cancelled in time, elapsed: %v", elapsed)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	if err = reg.Deregister(ctx, ins); err == nil {
+		t.Fatal("expected deregister timeout")
+	}
+}
+
+func TestRegistry_RegisterIPv6(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		address  string
+		check    string
+	}{
+		{endpoint: "grpc://[::1]:3553", address: "::1", check: "[::1]:3553"},
+		{endpoint: "grpc://[fe80::1%25eth0]:3553", address: "fe80::1%eth0", check: "[fe80::1%eth0]:3553"},
+	}
+
+	for _, tt := range tests {
+		var (
+			mu           sync.Mutex
+			registration api.AgentServiceRegistration
+		)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/v1/agent/service/register" {
+				mu.Lock()
+				_ = json.NewDecoder(r.Body).Decode(&registration)
+				mu.Unlock()
+			}
+
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		client, err := api.NewClient(&api.Config{Address: strings.TrimPrefix(server.URL, "http://")})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		reg := consul.NewRegistry(consul.WithClient(client), consul.WithEnableHeartbeatCheck(false))
+
+		ins := &registry.ServiceInstance{
+			ID:        "test-ipv6",
+			Name:      "node",
+			Endpoint:  tt.endpoint,
+			Endpoints: map[string]string{"http": strings.Replace(tt.endpoint, "grpc", "http", 1)},
+		}
+
+		if err = reg.Register(context.Background(), ins); err != nil {
+			t.Fatalf("%s: %v", tt.endpoint, err)
+		}
+
+		mu.Lock()
+		if registration.Address != tt.address || registration.Port != 3553 {
+			t.Fatalf("%s: unexpected address: %s %d", tt.endpoint, registration.Address, registration.Port)
+		}
+
+		if addr := registration.TaggedAddresses["http"]; addr.Address != tt.address || addr.Port != 3553 {
+			t.Fatalf("%s: unexpected tagged address: %+v", tt.endpoint, addr)
+		}
+
+		if len(registration.Checks) == 0 || registration.Checks[0].TCP != tt.check {
+			t.Fatalf("%s: unexpected checks: %+v", tt.endpoint, registration.Checks)
+		}
+
+		if registration.Meta["endpoint"] != tt.endpoint {
+			t.Fatalf("%s: endpoint is not preserved: %s", tt.endpoint, registration.Meta["endpoint"])
+		}
+		mu.Unlock()
+
+		_ = reg.Deregister(context.Background(), ins)
+		server.Close()
+	}
+}
+
+func TestRegistry_HealthChecker(t *testing.T) {
+	type checkUpdate struct {
+		Status string
+		Output string
+	}
+
+	var (
+		mu      sync.Mutex
+		updates []checkUpdate
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/v1/agent/check/update/") {
+			var update checkUpdate
+			_ = json.NewDecoder(r.Body).Decode(&update)
+
+			mu.Lock()
+			updates = append(updates, update)
+			mu.Unlock()
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(&api.Config{Address: strings.TrimPrefix(server.URL, "http://")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var healthy atomic.Bool
+	fake := clock.NewFake(time.Now())
+
+	reg := consul.NewRegistry(
+		consul.WithClient(client),
+		consul.WithEnableHealthCheck(false),
+		consul.WithHeartbeatCheckInterval(10),
+		consul.WithClock(fake),
+		consul.WithHealthChecker(func(ctx context.Context) error {
+			if healthy.Load() {
+				return nil
+			}
+
+			return errors.New("redis is down")
+		}),
+	)
+
+	ins := &registry.ServiceInstance{
+		ID:       "test-health-checker",
+		Name:     "node",
+		Endpoint: "grpc://127.0.0.1:3553",
+	}
+
+	if err = reg.Register(context.Background(), ins); err != nil {
+		t.Fatal(err)
+	}
+	defer reg.Deregister(context.Background(), ins)
+
+	fake.BlockUntil(1)
+
+	healthy.Store(true)
+	fake.Advance(5 * time.Second)
+
+	for i := 0; i < 100; i++ {
+		mu.Lock()
+		n := len(updates)
+		mu.Unlock()
+
+		if n >= 2 {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(updates) != 2 {
+		t.Fatalf("unexpected updates: %+v", updates)
+	}
+
+	if updates[0].Status != api.HealthCritical || updates[0].Output != "redis is down" {
+		t.Fatalf("unexpected unhealthy update: %+v", updates[0])
+	}
+
+	if updates[1].Status != api.HealthPassing {
+		t.Fatalf("unexpected healthy update: %+v", updates[1])
+	}
+}
+
+func TestRegistry_RegisterHeartbeatBlocked(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(&api.Config{Address: strings.TrimPrefix(server.URL, "http://")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 注册中心上下文已取消，心跳协程已退出，注册时通知心跳协程的发送操作将无法被接收
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reg := consul.NewRegistry(
+		consul.WithContext(ctx),
+		consul.WithClient(client),
+		consul.WithEnableHealthCheck(false),
+	)
+
+	ins := &registry.ServiceInstance{
+		ID:       "test-heartbeat-blocked",
+		Name:     "node",
+		Endpoint: "grpc://127.0.0.1:3553",
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- reg.Register(context.Background(), ins)
+	}()
+
+	select {
+	case err = <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("unexpected register error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("register blocked on heartbeat notification")
+	}
+}
+
+func TestRegistry_RegisterSameScheme(t *testing.T) {
+	var (
+		mu           sync.Mutex
+		registration api.AgentServiceRegistration
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/agent/service/register" {
+			mu.Lock()
+			_ = json.NewDecoder(r.Body).Decode(&registration)
+			mu.Unlock()
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(&api.Config{Address: strings.TrimPrefix(server.URL, "http://")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reg := consul.NewRegistry(consul.WithClient(client), consul.WithEnableHeartbeatCheck(false))
+
+	ins := &registry.ServiceInstance{
+		ID:        "test-same-scheme",
+		Name:      "node",
+		Endpoint:  "grpc://127.0.0.1:3553",
+		Endpoints: map[string]string{"grpc": "grpc://[::1]:4000"},
+	}
+
+	if err = reg.Register(context.Background(), ins); err != nil {
+		t.Fatal(err)
+	}
+	defer reg.Deregister(context.Background(), ins)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if registration.Address != "127.0.0.1" || registration.Port != 3553 {
+		t.Fatalf("unexpected address: %s %d", registration.Address, registration.Port)
+	}
+
+	if addr := registration.TaggedAddresses["endpoint_grpc"]; addr.Address != "127.0.0.1" || addr.Port != 3553 {
+		t.Fatalf("unexpected endpoint tagged address: %+v", addr)
+	}
+
+	if addr := registration.TaggedAddresses["grpc"]; addr.Address != "::1" || addr.Port != 4000 {
+		t.Fatalf("unexpected named tagged address: %+v", addr)
+	}
+
+	if len(registration.Checks) != 2 || registration.Checks[0].TCP != "127.0.0.1:3553" || registration.Checks[1].TCP != "[::1]:4000" {
+		t.Fatalf("unexpected checks: %+v", registration.Checks)
+	}
+}
+
+func TestRegistry_EndpointChecks(t *testing.T) {
+	var (
+		mu           sync.Mutex
+		registration api.AgentServiceRegistration
+		deregistered []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/v1/agent/service/register":
+			_ = json.NewDecoder(r.Body).Decode(&registration)
+		case r.URL.Path == "/v1/agent/checks":
+			checks := make(map[string]*api.AgentCheck)
+			for _, check := range registration.Checks {
+				checks[check.CheckID] = &api.AgentCheck{CheckID: check.CheckID, ServiceID: registration.ID}
+			}
+			_ = json.NewEncoder(w).Encode(checks)
+			return
+		case strings.HasPrefix(r.URL.Path, "/v1/agent/check/deregister/"):
+			deregistered = append(deregistered, strings.TrimPrefix(r.URL.Path, "/v1/agent/check/deregister/"))
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(&api.Config{Address: strings.TrimPrefix(server.URL, "http://")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reg := consul.NewRegistry(
+		consul.WithClient(client),
+		consul.WithEnableHeartbeatCheck(false),
+		consul.WithEndpointCheck("link", consul.EndpointCheck{Interval: 2, Timeout: 1}),
+	)
+
+	ins := &registry.ServiceInstance{
+		ID:        "test-endpoint-checks",
+		Name:      "node",
+		Endpoint:  "grpc://127.0.0.1:3553",
+		Endpoints: map[string]string{"link": "drpc://127.0.0.1:3554", "admin": "http://127.0.0.1:3555"},
+	}
+
+	if err = reg.Register(context.Background(), ins); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	checks := registration.Checks
+	mu.Unlock()
+
+	if len(checks) != 3 {
+		t.Fatalf("unexpected checks: %+v", checks)
+	}
+
+	ids := make(map[string]bool, len(checks))
+	for _, check := range checks {
+		ids[check.CheckID] = true
+	}
+
+	if len(ids) != len(checks) {
+		t.Fatalf("check ids are not distinct: %+v", ids)
+	}
+
+	// 命名端点按名称排序，admin使用全局配置，link使用单独配置
+	if checks[0].Interval != "10s" || checks[1].Interval != "10s" || checks[1].Timeout != "5s" {
+		t.Fatalf("unexpected default check settings: %+v %+v", checks[0], checks[1])
+	}
+
+	if checks[2].Name != "endpoint link" || checks[2].Interval != "2s" || checks[2].Timeout != "1s" {
+		t.Fatalf("unexpected link check settings: %+v", checks[2])
+	}
+
+	if err = reg.Deregister(context.Background(), ins); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(deregistered) != len(checks) {
+		t.Fatalf("unexpected deregistered checks: %v", deregistered)
+	}
+}
+
+func TestRegistry_GetServicesByTags(t *testing.T) {
+	var (
+		mu           sync.Mutex
+		registration api.AgentServiceRegistration
+		queried      []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/v1/agent/service/register":
+			_ = json.NewDecoder(r.Body).Decode(&registration)
+		case r.URL.Path == "/v1/health/service/node":
+			queried = r.URL.Query()["tag"]
+			_ = json.NewEncoder(w).Encode([]*api.ServiceEntry{{
+				Service: &api.AgentService{
+					ID:      registration.ID,
+					Service: registration.Name,
+					Tags:    registration.Tags,
+					Meta:    registration.Meta,
+				},
+			}})
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(&api.Config{Address: strings.TrimPrefix(server.URL, "http://")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reg := consul.NewRegistry(consul.WithClient(client), consul.WithEnableHealthCheck(false), consul.WithEnableHeartbeatCheck(false))
+
+	ins := &registry.ServiceInstance{
+		ID:       "test-tags",
+		Name:     "node",
+		Kind:     "node",
+		Endpoint: "grpc://127.0.0.1:3553",
+		Events:   []int{1},
+		Tags:     []string{"region=eu", "version=canary"},
+	}
+
+	if err = reg.Register(context.Background(), ins); err != nil {
+		t.Fatal(err)
+	}
+	defer reg.Deregister(context.Background(), ins)
+
+	mu.Lock()
+	tags := strings.Join(registration.Tags, ",")
+	mu.Unlock()
+
+	if tags != "event:1,tag:region=eu,tag:version=canary" {
+		t.Fatalf("unexpected registration tags: %s", tags)
+	}
+
+	services, err := reg.GetServicesByTags(context.Background(), "node", "version=canary")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(queried) != 1 || queried[0] != "tag:version=canary" {
+		t.Fatalf("unexpected query tags: %v", queried)
+	}
+
+	if len(services) != 1 || strings.Join(services[0].Tags, ",") != "region=eu,version=canary" {
+		t.Fatalf("unexpected services: %+v", services)
+	}
+}
@@ -2,10 +2,11 @@ package consul
 
 import (
 	"context"
-	"github.com/dobyte/due/v2/encoding/json"
+	"fmt"
+	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/registry"
-	"github.com/dobyte/due/v2/utils/xconv"
 	"github.com/hashicorp/consul/api"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -21,6 +22,7 @@ type Registry struct {
 	opts       *options
 	watchers   sync.Map
 	registrars sync.Map
+	criticals  sync.Map // 处于critical状态的服务实例及首次发现时间，用于清理孤儿服务
 }
 
 func NewRegistry(opts ...Option) *Registry {
@@ -65,7 +67,12 @@ func (r *Registry) Register(ctx context.Context, ins *registry.ServiceInstance)
 
 	reg := newRegistrar(r)
 
+	if r.opts.replaceExisting {
+		reg.cleanup(ctx, insID)
+	}
+
 	if err := reg.register(ctx, ins); err != nil {
+		reg.cancel()
 		return err
 	}
 
@@ -83,7 +90,28 @@ func (r *Registry) Deregister(ctx context.Context, ins *registry.ServiceInstance
 		return v.(*registrar).deregister(ctx, ins)
 	}
 
-	return r.opts.client.Agent().ServiceDeregister(insID)
+	return r.opts.client.Agent().ServiceDeregisterOpts(insID, (&api.QueryOptions{}).WithContext(ctx))
+}
+
+// Start 启动服务注册发现组件，实现component.Lifecycle接口
+func (r *Registry) Start(ctx context.Context) error {
+	return r.err
+}
+
+// Stop 停止服务注册发现组件，解注册所有服务实例并停止心跳与监听，实现component.Lifecycle接口
+func (r *Registry) Stop(ctx context.Context) error {
+	var err error
+
+	r.registrars.Range(func(key, value any) bool {
+		if e := value.(*registrar).stop(ctx, key.(string)); e != nil {
+			err = e
+		}
+		return true
+	})
+
+	r.cancel()
+
+	return err
 }
 
 // Services 获取服务实例列表
@@ -96,7 +124,7 @@ func (r *Registry) Services(ctx context.Context, serviceName string) ([]*registr
 	if ok {
 		return v.(*watcherMgr).services(), nil
 	} else {
-		services, _, err := r.services(ctx, serviceName, 0, true)
+		services, _, err := r.services(ctx, serviceName, nil, 0)
 		return services, err
 	}
 }
@@ -121,53 +149,98 @@ func (r *Registry) Watch(ctx context.Context, serviceName string) (registry.Watc
 	return w.fork(), nil
 }
 
+// GetServiceByAlias 根据别名获取服务实例列表，多个服务实例共用同一别名时返回全部服务实例
+func (r *Registry) GetServiceByAlias(ctx context.Context, alias string) ([]*registry.ServiceInstance, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	opts := &api.QueryOptions{Filter: fmt.Sprintf("ServiceMeta.%s == %s", metaFieldAlias, strconv.Quote(alias))}
+	opts = opts.WithContext(ctx)
+
+	names, _, err := r.opts.client.Catalog().Services(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	services := make([]*registry.ServiceInstance, 0, len(names))
+	for name := range names {
+		list, err := r.Services(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ins := range list {
+			if ins.Alias == alias {
+				services = append(services, ins)
+			}
+		}
+	}
+
+	return services, nil
+}
+
+// GetServicesByEvent 获取订阅了指定事件的服务实例列表，通过注册时写入的事件标签在Consul侧完成过滤
+func (r *Registry) GetServicesByEvent(ctx context.Context, serviceName string, event int) ([]*registry.ServiceInstance, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	services, _, err := r.services(ctx, serviceName, []string{makeEventTag(event)}, 0)
+
+	return services, err
+}
+
+// GetServicesByTags 获取同时包含全部指定自定义标签的服务实例列表，通过注册时写入的自定义标签在Consul侧完成过滤，可用于按区域或版本路由
+func (r *Registry) GetServicesByTags(ctx context.Context, serviceName string, tags ...string) ([]*registry.ServiceInstance, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if len(tags) == 0 {
+		return nil, errors.ErrInvalidArgument
+	}
+
+	filters := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		filters = append(filters, makeCustomTag(tag))
+	}
+
+	services, _, err := r.services(ctx, serviceName, filters, 0)
+
+	return services, err
+}
+
 // 获取服务实体列表
-func (r *Registry) services(ctx context.Context, serviceName string, waitIndex uint64, passingOnly bool) ([]*registry.ServiceInstance, uint64, error) {
+func (r *Registry) services(ctx context.Context, serviceName string, tags []string, waitIndex uint64) ([]*registry.ServiceInstance, uint64, error) {
 	opts := &api.QueryOptions{
 		WaitIndex: waitIndex,
 		WaitTime:  60 * time.Second,
 	}
-	opts.WithContext(ctx)
+	opts = opts.WithContext(ctx)
 
-	entries, meta, err := r.opts.client.Health().Service(serviceName, "", passingOnly, opts)
+	entries, meta, err := r.opts.client.Health().ServiceMultipleTags(serviceName, tags, r.opts.passingOnly, opts)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	services := make([]*registry.ServiceInstance, 0, len(entries))
 	for _, entry := range entries {
-		ins := &registry.ServiceInstance{
-			Name:     entry.Service.Service,
-			Routes:   unmarshalMetaRoutes(entry.Service.Meta),
-			Events:   make([]int, 0),
-			Services: make([]string, 0),
+		ins, err := r.opts.serializer.Unmarshal(entry.Service.Meta)
+		if err != nil {
+			return nil, 0, err
 		}
 
-		for k, v := range entry.Service.Meta {
-			switch k {
-			case metaFieldID:
-				ins.ID = v
-			case metaFieldKind:
-				ins.Kind = v
-			case metaFieldAlias:
-				ins.Alias = v
-			case metaFieldState:
-				ins.State = v
-			case metaFieldWeight:
-				ins.Weight = xconv.Int(v)
-			case metaFieldEvents:
-				if err = json.Unmarshal([]byte(v), &ins.Events); err != nil {
-					continue
-				}
-			case metaFieldServices:
-				if err = json.Unmarshal([]byte(v), &ins.Services); err != nil {
-					continue
-				}
-			case metaFieldEndpoint:
-				ins.Endpoint = v
-			}
+		if ins.Name == "" {
+			ins.Name = entry.Service.Service
 		}
 
+		if len(ins.Tags) == 0 {
+			ins.Tags = parseCustomTags(entry.Service.Tags)
+		}
+
+		ins.Health = toHealth(entry.Checks.AggregatedStatus())
+
 		services = append(services, ins)
 	}
 
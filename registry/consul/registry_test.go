@@ -2,12 +2,16 @@ package consul_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/dobyte/due/registry/consul/v2"
 	"github.com/dobyte/due/v2/cluster"
 	"github.com/dobyte/due/v2/registry"
 	"github.com/dobyte/due/v2/utils/xnet"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -91,6 +95,55 @@ func TestRegistry_Services(t *testing.T) {
 	}
 }
 
+func TestRegistry_GetServiceByAlias(t *testing.T) {
+	services, err := reg.GetServiceByAlias(context.Background(), "mahjong")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, service := range services {
+		t.Logf("%+v", service)
+	}
+}
+
+func TestRegistry_QueryContext(t *testing.T) {
+	block := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-block:
+		}
+	}))
+	defer server.Close()
+	defer close(block)
+
+	reg := consul.NewRegistry(consul.WithAddr(strings.TrimPrefix(server.URL, "http://")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// 查询遵循调用方的超时设置
+	if _, err := reg.GetServiceByAlias(ctx, "mahjong"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected alias query error: %v", err)
+	}
+
+	if _, err := reg.GetServicesByEvent(ctx, serviceName, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected event query error: %v", err)
+	}
+}
+
+func TestRegistry_GetServicesByEvent(t *testing.T) {
+	services, err := reg.GetServicesByEvent(context.Background(), serviceName, int(cluster.Connect))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, service := range services {
+		t.Logf("%+v", service)
+	}
+}
+
 func TestRegistry_Watch(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
 	defer cancel()
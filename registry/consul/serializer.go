@@ -0,0 +1,185 @@
+package consul
+
+import (
+	"fmt"
+	"github.com/dobyte/due/v2/encoding/json"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/registry"
+	"github.com/dobyte/due/v2/utils/xconv"
+	"strings"
+)
+
+type SerializerOption func(s *metaSerializer)
+
+type metaSerializer struct {
+	compressThreshold int // 压缩阈值，元数据值长度超过该值时压缩，小于等于0时不压缩
+}
+
+var _ registry.Serializer = &metaSerializer{}
+
+// NewMetaSerializer 创建Consul元数据序列化器
+// 服务名称不写入元数据，由Consul服务名承载；路由列表按元数据值长度限制拆分为多个字段
+// 拆分后字段数超出Consul限制时，路由列表改为紧凑编码写入单个字段
+func NewMetaSerializer(opts ...SerializerOption) registry.Serializer {
+	s := &metaSerializer{}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithCompressThreshold 设置元数据值的压缩阈值，长度超过阈值的元数据值以gzip压缩并base64编码后写入，默认不压缩
+// 压缩值带有压缩前缀，解码时无论是否开启压缩均可识别；集群中仍存在不支持解压的旧版本实例时不应开启
+func WithCompressThreshold(threshold int) SerializerOption {
+	return func(s *metaSerializer) { s.compressThreshold = threshold }
+}
+
+// Marshal 将服务实例编码为元数据
+func (s *metaSerializer) Marshal(ins *registry.ServiceInstance) (map[string]string, error) {
+	meta := make(map[string]string, 8)
+	meta[metaFieldID] = ins.ID
+	meta[metaFieldKind] = ins.Kind
+	meta[metaFieldAlias] = ins.Alias
+	meta[metaFieldState] = ins.State
+	meta[metaFieldEndpoint] = ins.Endpoint
+	meta[metaFieldEvents] = xconv.Json(ins.Events)
+	meta[metaFieldWeight] = xconv.String(ins.Weight)
+	meta[metaFieldServices] = xconv.Json(ins.Services)
+
+	if len(ins.Endpoints) > 0 {
+		meta[metaFieldEndpoints] = xconv.Json(ins.Endpoints)
+	}
+
+	if ins.Load != nil {
+		meta[metaFieldLoad] = xconv.Json(ins.Load)
+	}
+
+	routes := marshalMetaRoutes(ins.Routes)
+
+	if len(meta)+len(routes) <= metaMaxKeys {
+		for field, value := range routes {
+			meta[field] = value
+		}
+
+		return s.compress(meta)
+	}
+
+	compact, err := marshalCompactRoutes(ins.Routes)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(compact) > metaValueSize {
+		return nil, fmt.Errorf("%w: %d routes can not be packed into a single meta value", errors.ErrMetaTooLarge, len(ins.Routes))
+	}
+
+	meta[metaFieldRoutes] = compact
+
+	return s.compress(meta)
+}
+
+// Unmarshal 将元数据解码为服务实例
+func (s *metaSerializer) Unmarshal(meta map[string]string) (*registry.ServiceInstance, error) {
+	meta, err := s.decompress(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	ins := &registry.ServiceInstance{
+		Routes:   unmarshalMetaRoutes(meta),
+		Events:   make([]int, 0),
+		Services: make([]string, 0),
+	}
+
+	if compact, ok := meta[metaFieldRoutes]; ok {
+		routes, err := unmarshalCompactRoutes(compact)
+		if err != nil {
+			return nil, err
+		}
+
+		ins.Routes = append(ins.Routes, routes...)
+	}
+
+	for k, v := range meta {
+		switch k {
+		case metaFieldID:
+			ins.ID = v
+		case metaFieldKind:
+			ins.Kind = v
+		case metaFieldAlias:
+			ins.Alias = v
+		case metaFieldState:
+			ins.State = v
+		case metaFieldWeight:
+			ins.Weight = xconv.Int(v)
+		case metaFieldEvents:
+			_ = json.Unmarshal([]byte(v), &ins.Events)
+		case metaFieldServices:
+			_ = json.Unmarshal([]byte(v), &ins.Services)
+		case metaFieldEndpoint:
+			ins.Endpoint = v
+		case metaFieldEndpoints:
+			_ = json.Unmarshal([]byte(v), &ins.Endpoints)
+		case metaFieldLoad:
+			ins.Load = &registry.Load{}
+			_ = json.Unmarshal([]byte(v), ins.Load)
+		}
+	}
+
+	return ins, nil
+}
+
+// 压缩长度超过压缩阈值的元数据值
+func (s *metaSerializer) compress(meta map[string]string) (map[string]string, error) {
+	if s.compressThreshold <= 0 {
+		return meta, nil
+	}
+
+	for field, value := range meta {
+		if len(value) <= s.compressThreshold && !strings.HasPrefix(value, compressedPrefix) {
+			continue
+		}
+
+		compressed, err := compressMetaValue(value)
+		if err != nil {
+			return nil, err
+		}
+
+		meta[field] = compressed
+	}
+
+	return meta, nil
+}
+
+// 解压带有压缩前缀的元数据值，不修改原始元数据
+func (s *metaSerializer) decompress(meta map[string]string) (map[string]string, error) {
+	var decompressed map[string]string
+
+	for field, value := range meta {
+		if !strings.HasPrefix(value, compressedPrefix) {
+			continue
+		}
+
+		if decompressed == nil {
+			decompressed = make(map[string]string, len(meta))
+			for k, v := range meta {
+				decompressed[k] = v
+			}
+		}
+
+		raw, err := decompressMetaValue(value)
+		if err != nil {
+			return nil, err
+		}
+
+		decompressed[field] = raw
+	}
+
+	if decompressed == nil {
+		return meta, nil
+	}
+
+	return decompressed, nil
+}
@@ -0,0 +1,192 @@
+package consul_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dobyte/due/registry/consul/v2"
+	dueerrors "github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/registry"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestMetaSerializer(t *testing.T) {
+	ins := &registry.ServiceInstance{
+		ID:        "1",
+		Kind:      "node",
+		Alias:     "mahjong",
+		State:     "work",
+		Events:    []int{1, 2},
+		Services:  []string{"wallet"},
+		Endpoint:  "grpc://127.0.0.1:3553",
+		Endpoints: map[string]string{"http": "http://127.0.0.1:8080"},
+		Weight:    10,
+		Load:      &registry.Load{Active: 12, Score: 0.35},
+	}
+
+	for i := 0; i < 200; i++ {
+		ins.Routes = append(ins.Routes, registry.Route{ID: int32(i), Stateful: i%2 == 0, Internal: i%3 == 0})
+	}
+
+	serializer := consul.NewMetaSerializer()
+
+	meta, err := serializer.Marshal(ins)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for field, value := range meta {
+		if len(value) > 512 {
+			t.Fatalf("meta field %s exceeds consul value limit: %d", field, len(value))
+		}
+	}
+
+	decoded, err := serializer.Unmarshal(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Slice(decoded.Routes, func(i, j int) bool { return decoded.Routes[i].ID < decoded.Routes[j].ID })
+
+	if !reflect.DeepEqual(ins, decoded) {
+		t.Fatalf("round trip mismatch, want %+v got %+v", ins, decoded)
+	}
+}
+
+func TestMetaSerializer_CompactRoutes(t *testing.T) {
+	ins := &registry.ServiceInstance{
+		ID:       "1",
+		Kind:     "node",
+		State:    "work",
+		Events:   []int{},
+		Services: []string{},
+		Endpoint: "grpc://127.0.0.1:3553",
+	}
+
+	for i := -100; i < 5000; i++ {
+		ins.Routes = append(ins.Routes, registry.Route{ID: int32(i), Stateful: i%2 == 0, Internal: i%3 == 0})
+	}
+
+	serializer := consul.NewMetaSerializer()
+
+	meta, err := serializer.Marshal(ins)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(meta) > 64 {
+		t.Fatalf("meta keys exceed consul limit: %d", len(meta))
+	}
+
+	if len(meta["routes"]) == 0 || len(meta["routes"]) > 512 {
+		t.Fatalf("unexpected compact routes: %d", len(meta["routes"]))
+	}
+
+	decoded, err := serializer.Unmarshal(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(ins.Routes, decoded.Routes) {
+		t.Fatal("compact routes round trip mismatch")
+	}
+
+	r := rand.New(rand.NewSource(1))
+
+	ins.Routes = ins.Routes[:0]
+	for i := 0; i < 5000; i++ {
+		ins.Routes = append(ins.Routes, registry.Route{ID: r.Int31()})
+	}
+
+	if _, err = serializer.Marshal(ins); !errors.Is(err, dueerrors.ErrMetaTooLarge) {
+		t.Fatalf("expected meta too large, got: %v", err)
+	}
+}
+
+func TestMetaSerializer_RouteAttrs(t *testing.T) {
+	serializer := consul.NewMetaSerializer()
+
+	ins, err := serializer.Unmarshal(map[string]string{
+		"id":       "1",
+		"routes-0": "1-1-0,2-0-1-6,3-0-0-133,-4-1-1,5-1-1-254-future",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Slice(ins.Routes, func(i, j int) bool { return ins.Routes[i].ID < ins.Routes[j].ID })
+
+	expected := []registry.Route{
+		{ID: -4, Stateful: true, Internal: true},
+		{ID: 1, Stateful: true},
+		{ID: 2, Stateful: true, Ephemeral: true},
+		{ID: 3, Internal: true, Ephemeral: true},
+		{ID: 5, Stateful: true, Ephemeral: true},
+	}
+
+	if !reflect.DeepEqual(ins.Routes, expected) {
+		t.Fatalf("unexpected routes: %+v", ins.Routes)
+	}
+
+	meta, err := serializer.Marshal(&registry.ServiceInstance{ID: "1", Routes: expected})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := serializer.Unmarshal(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Slice(decoded.Routes, func(i, j int) bool { return decoded.Routes[i].ID < decoded.Routes[j].ID })
+
+	if !reflect.DeepEqual(decoded.Routes, expected) {
+		t.Fatalf("round trip mismatch: %+v", decoded.Routes)
+	}
+}
+
+func TestMetaSerializer_Compress(t *testing.T) {
+	ins := &registry.ServiceInstance{
+		ID:       "1",
+		Kind:     "node",
+		State:    "work",
+		Events:   []int{},
+		Endpoint: "grpc://127.0.0.1:3553",
+	}
+
+	for i := 0; i < 60; i++ {
+		ins.Services = append(ins.Services, fmt.Sprintf("service-%d", i))
+	}
+
+	if _, err := consul.NewMetaSerializer().Marshal(ins); err != nil {
+		t.Fatal(err)
+	}
+
+	serializer := consul.NewMetaSerializer(consul.WithCompressThreshold(256))
+
+	meta, err := serializer.Marshal(ins)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(meta["services"], "gz:") || len(meta["services"]) > 512 {
+		t.Fatalf("unexpected compressed services: %s", meta["services"])
+	}
+
+	if strings.HasPrefix(meta["id"], "gz:") {
+		t.Fatal("values below threshold should not be compressed")
+	}
+
+	// 未开启压缩的序列化器同样可以解码压缩值
+	decoded, err := consul.NewMetaSerializer().Unmarshal(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(ins.Services, decoded.Services) {
+		t.Fatalf("unexpected services: %v", decoded.Services)
+	}
+}
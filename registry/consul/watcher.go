@@ -78,7 +78,7 @@ type watcherMgr struct {
 }
 
 func newWatcherMgr(registry *Registry, ctx context.Context, serviceName string) (*watcherMgr, error) {
-	services, index, err := registry.services(ctx, serviceName, 0, true)
+	services, index, err := registry.services(ctx, serviceName, nil, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -92,33 +92,32 @@ func newWatcherMgr(registry *Registry, ctx context.Context, serviceName string)
 	wm.serviceInstances.Store(services)
 	wm.watchers = make(map[int64]*watcher)
 
-	go func() {
-		ticker := time.NewTicker(time.Second)
-		defer ticker.Stop()
+	go wm.watch()
 
-		for {
+	return wm, nil
+}
+
+// 基于阻塞查询监听服务实例变化，服务实例未变化时查询阻塞至等待超时
+func (wm *watcherMgr) watch() {
+	for {
+		ctx, cancel := context.WithTimeout(wm.ctx, 120*time.Second)
+		services, index, err := wm.registry.services(ctx, wm.serviceName, nil, wm.serviceWaitIndex)
+		cancel()
+		if err != nil {
 			select {
 			case <-wm.ctx.Done():
 				return
-			case <-ticker.C:
-				ctx, cancel := context.WithTimeout(ctx, 120*time.Second)
-				services, index, err = wm.registry.services(ctx, wm.serviceName, wm.serviceWaitIndex, true)
-				cancel()
-				if err != nil {
-					time.Sleep(time.Second)
-					continue
-				}
-
-				if index != wm.serviceWaitIndex {
-					wm.serviceWaitIndex = index
-					wm.serviceInstances.Store(services)
-					wm.broadcast()
-				}
+			case <-time.After(time.Second):
+				continue
 			}
 		}
-	}()
 
-	return wm, nil
+		if index != wm.serviceWaitIndex {
+			wm.serviceWaitIndex = index
+			wm.serviceInstances.Store(services)
+			wm.broadcast()
+		}
+	}
 }
 
 func (wm *watcherMgr) fork() registry.Watcher {
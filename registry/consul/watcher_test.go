@@ -0,0 +1,107 @@
+package consul_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dobyte/due/registry/consul/v2"
+	"github.com/hashicorp/consul/api"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 模拟支持阻塞查询的Consul健康检查接口
+type fakeHealth struct {
+	mu       sync.Mutex
+	index    uint64
+	ids      []string
+	chChange chan struct{}
+}
+
+func (h *fakeHealth) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/health/service/gate" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	wait, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+
+	h.mu.Lock()
+	for wait != 0 && wait == h.index {
+		ch := h.chChange
+		h.mu.Unlock()
+
+		select {
+		case <-ch:
+		case <-r.Context().Done():
+			return
+		}
+
+		h.mu.Lock()
+	}
+
+	entries := make([]*api.ServiceEntry, 0, len(h.ids))
+	for _, id := range h.ids {
+		entries = append(entries, &api.ServiceEntry{
+			Node:    &api.Node{Node: "consul-1", Address: "127.0.0.1"},
+			Service: &api.AgentService{ID: id, Service: "gate", Meta: map[string]string{"id": id, "kind": "gate"}},
+			Checks:  api.HealthChecks{{ServiceID: id, Status: api.HealthPassing}},
+		})
+	}
+
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(h.index, 10))
+	h.mu.Unlock()
+
+	_ = json.NewEncoder(w).Encode(entries)
+}
+
+// 新增服务实例并唤醒阻塞中的查询
+func (h *fakeHealth) add(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.index++
+	h.ids = append(h.ids, id)
+	close(h.chChange)
+	h.chChange = make(chan struct{})
+}
+
+func TestRegistry_WatchBlockingQuery(t *testing.T) {
+	health := &fakeHealth{index: 1, ids: []string{"gate-1"}, chChange: make(chan struct{})}
+
+	server := httptest.NewServer(health)
+	defer server.Close()
+
+	reg := consul.NewRegistry(consul.WithAddr(strings.TrimPrefix(server.URL, "http://")))
+	defer reg.Stop(context.Background())
+
+	watcher, err := reg.Watch(context.Background(), "gate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Stop()
+
+	if services, err := watcher.Next(); err != nil || len(services) != 1 {
+		t.Fatalf("unexpected initial services: %v, %v", services, err)
+	}
+
+	// 等待阻塞查询发出
+	time.Sleep(50 * time.Millisecond)
+
+	health.add("gate-2")
+	start := time.Now()
+
+	services, err := watcher.Next()
+	if err != nil || len(services) != 2 {
+		t.Fatalf("unexpected changed services: %v, %v", services, err)
+	}
+
+	// 阻塞查询返回后立即通知，无需等待轮询周期
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("change notified too late: %v", elapsed)
+	}
+}
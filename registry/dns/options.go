@@ -0,0 +1,166 @@
+package dns
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/etc"
+	"net"
+	"time"
+)
+
+const (
+	defaultDomain          = "svc.cluster.local"
+	defaultNamespace       = "default"
+	defaultProtocol        = "tcp"
+	defaultScheme          = "grpc"
+	defaultState           = "work"
+	defaultTimeout         = "3s"
+	defaultRefreshInterval = "5s"
+)
+
+const (
+	defaultDomainKey          = "etc.registry.dns.domain"
+	defaultNamespaceKey       = "etc.registry.dns.namespace"
+	defaultPortNameKey        = "etc.registry.dns.portName"
+	defaultProtocolKey        = "etc.registry.dns.protocol"
+	defaultPortKey            = "etc.registry.dns.port"
+	defaultSchemeKey          = "etc.registry.dns.scheme"
+	defaultStateKey           = "etc.registry.dns.state"
+	defaultTimeoutKey         = "etc.registry.dns.timeout"
+	defaultRefreshIntervalKey = "etc.registry.dns.refreshInterval"
+)
+
+// Resolver DNS解析器，*net.Resolver实现了该接口
+type Resolver interface {
+	// LookupSRV 解析SRV记录
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	// LookupIPAddr 解析A/AAAA记录
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+type Option func(o *options)
+
+type options struct {
+	// 上下文
+	// 默认context.Background
+	ctx context.Context
+
+	// DNS解析器
+	// 默认为net.DefaultResolver
+	resolver Resolver
+
+	// 集群域名后缀
+	// 服务名将被解析为<服务名>.<命名空间>.<域名后缀>，默认为svc.cluster.local
+	domain string
+
+	// 命名空间
+	// Kubernetes命名空间，默认为default
+	namespace string
+
+	// SRV端口名称
+	// 设置后通过SRV记录（_<端口名称>._<协议>.<服务域名>）发现服务实例及端口；为空时解析A/AAAA记录并使用固定端口，默认为空
+	portName string
+
+	// SRV协议
+	// 默认为tcp
+	protocol string
+
+	// 服务端口
+	// 未设置SRV端口名称时使用，默认为0
+	port int
+
+	// 服务实例端点协议
+	// 默认为grpc
+	scheme string
+
+	// 服务实例状态
+	// Kubernetes仅将就绪的Pod加入无头服务的解析结果，故默认为work
+	state string
+
+	// 解析超时时间
+	// 默认为3秒
+	timeout time.Duration
+
+	// 重新解析间隔时间
+	// 默认为5秒
+	refreshInterval time.Duration
+
+	// 服务域名构建函数
+	// 设置后将替代默认的<服务名>.<命名空间>.<域名后缀>规则，默认为nil
+	hostFunc func(serviceName string) string
+}
+
+func defaultOptions() *options {
+	return &options{
+		ctx:             context.Background(),
+		resolver:        net.DefaultResolver,
+		domain:          etc.Get(defaultDomainKey, defaultDomain).String(),
+		namespace:       etc.Get(defaultNamespaceKey, defaultNamespace).String(),
+		portName:        etc.Get(defaultPortNameKey).String(),
+		protocol:        etc.Get(defaultProtocolKey, defaultProtocol).String(),
+		port:            etc.Get(defaultPortKey).Int(),
+		scheme:          etc.Get(defaultSchemeKey, defaultScheme).String(),
+		state:           etc.Get(defaultStateKey, defaultState).String(),
+		timeout:         etc.Get(defaultTimeoutKey, defaultTimeout).Duration(),
+		refreshInterval: etc.Get(defaultRefreshIntervalKey, defaultRefreshInterval).Duration(),
+	}
+}
+
+// WithContext 设置上下文
+func WithContext(ctx context.Context) Option {
+	return func(o *options) { o.ctx = ctx }
+}
+
+// WithResolver 设置DNS解析器
+func WithResolver(resolver Resolver) Option {
+	return func(o *options) { o.resolver = resolver }
+}
+
+// WithDomain 设置集群域名后缀
+func WithDomain(domain string) Option {
+	return func(o *options) { o.domain = domain }
+}
+
+// WithNamespace 设置命名空间
+func WithNamespace(namespace string) Option {
+	return func(o *options) { o.namespace = namespace }
+}
+
+// WithPortName 设置SRV端口名称，设置后通过SRV记录发现服务实例及端口
+func WithPortName(portName string) Option {
+	return func(o *options) { o.portName = portName }
+}
+
+// WithProtocol 设置SRV协议
+func WithProtocol(protocol string) Option {
+	return func(o *options) { o.protocol = protocol }
+}
+
+// WithPort 设置服务端口，未设置SRV端口名称时使用
+func WithPort(port int) Option {
+	return func(o *options) { o.port = port }
+}
+
+// WithScheme 设置服务实例端点协议
+func WithScheme(scheme string) Option {
+	return func(o *options) { o.scheme = scheme }
+}
+
+// WithState 设置服务实例状态
+func WithState(state string) Option {
+	return func(o *options) { o.state = state }
+}
+
+// WithTimeout 设置解析超时时间
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.timeout = timeout }
+}
+
+// WithRefreshInterval 设置重新解析间隔时间
+func WithRefreshInterval(refreshInterval time.Duration) Option {
+	return func(o *options) { o.refreshInterval = refreshInterval }
+}
+
+// WithHostFunc 设置服务域名构建函数
+func WithHostFunc(fn func(serviceName string) string) Option {
+	return func(o *options) { o.hostFunc = fn }
+}
@@ -0,0 +1,197 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"github.com/dobyte/due/v2/registry"
+	"github.com/dobyte/due/v2/utils/xnet"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const name = "dns"
+
+var _ registry.Registry = &Registry{}
+
+// Registry 基于DNS的服务发现组件，适用于Kubernetes无头服务（Headless Service）
+// 服务实例由Kubernetes根据Pod就绪状态维护，故注册与解注册均为空操作
+type Registry struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	opts     *options
+	rw       sync.Mutex
+	watchers map[string]*watcherMgr
+}
+
+func NewRegistry(opts ...Option) *Registry {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	r := &Registry{}
+	r.opts = o
+	r.ctx, r.cancel = context.WithCancel(o.ctx)
+	r.watchers = make(map[string]*watcherMgr)
+
+	return r
+}
+
+// Name 获取服务注册发现组件名
+func (r *Registry) Name() string {
+	return name
+}
+
+// Register 注册服务实例，服务实例由Kubernetes维护，无需注册
+func (r *Registry) Register(ctx context.Context, ins *registry.ServiceInstance) error {
+	return nil
+}
+
+// Deregister 解注册服务实例，服务实例由Kubernetes维护，无需解注册
+func (r *Registry) Deregister(ctx context.Context, ins *registry.ServiceInstance) error {
+	return nil
+}
+
+// Services 获取服务实例列表
+func (r *Registry) Services(ctx context.Context, serviceName string) ([]*registry.ServiceInstance, error) {
+	return r.resolve(ctx, serviceName)
+}
+
+// Watch 监听相同服务名的服务实例变化
+func (r *Registry) Watch(ctx context.Context, serviceName string) (registry.Watcher, error) {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	if wm, ok := r.watchers[serviceName]; ok {
+		return wm.fork(), nil
+	}
+
+	wm, err := newWatcherMgr(ctx, r, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	r.watchers[serviceName] = wm
+
+	return wm.fork(), nil
+}
+
+// Close 停止所有监听
+func (r *Registry) Close() error {
+	r.cancel()
+	return nil
+}
+
+// 构建服务域名
+func (r *Registry) buildHost(serviceName string) string {
+	if r.opts.hostFunc != nil {
+		return r.opts.hostFunc(serviceName)
+	}
+
+	return fmt.Sprintf("%s.%s.%s", serviceName, r.opts.namespace, r.opts.domain)
+}
+
+// 解析服务实例列表，结果按服务实例ID排序
+func (r *Registry) resolve(ctx context.Context, serviceName string) ([]*registry.ServiceInstance, error) {
+	if r.opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.opts.timeout)
+		defer cancel()
+	}
+
+	var (
+		services []*registry.ServiceInstance
+		err      error
+		host     = r.buildHost(serviceName)
+	)
+
+	if r.opts.portName != "" {
+		services, err = r.resolveSRV(ctx, serviceName, host)
+	} else {
+		services, err = r.resolveIP(ctx, serviceName, host)
+	}
+	if err != nil {
+		if isNotFound(err) {
+			return make([]*registry.ServiceInstance, 0), nil
+		}
+
+		return nil, err
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].ID < services[j].ID
+	})
+
+	return services, nil
+}
+
+// 解析SRV记录，SRV记录的目标主机为各Pod的域名，端口与权重映射为服务实例的端点与权重
+func (r *Registry) resolveSRV(ctx context.Context, serviceName, host string) ([]*registry.ServiceInstance, error) {
+	_, records, err := r.opts.resolver.LookupSRV(ctx, r.opts.portName, r.opts.protocol, host)
+	if err != nil {
+		return nil, err
+	}
+
+	services := make([]*registry.ServiceInstance, 0, len(records))
+
+	for _, record := range records {
+		target := strings.TrimSuffix(record.Target, ".")
+
+		addrs, err := r.opts.resolver.LookupIPAddr(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(addrs) == 0 {
+			continue
+		}
+
+		ins := r.makeInstance(serviceName, addrs[0], int(record.Port))
+		ins.ID = net.JoinHostPort(target, strconv.Itoa(int(record.Port)))
+		ins.Alias = strings.SplitN(target, ".", 2)[0]
+		ins.Weight = int(record.Weight)
+		ins.Endpoints = map[string]string{r.opts.portName: ins.Endpoint}
+
+		services = append(services, ins)
+	}
+
+	return services, nil
+}
+
+// 解析A/AAAA记录，使用固定端口
+func (r *Registry) resolveIP(ctx context.Context, serviceName, host string) ([]*registry.ServiceInstance, error) {
+	addrs, err := r.opts.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	services := make([]*registry.ServiceInstance, 0, len(addrs))
+
+	for _, addr := range addrs {
+		services = append(services, r.makeInstance(serviceName, addr, r.opts.port))
+	}
+
+	return services, nil
+}
+
+// 构建服务实例，IPv6链路本地地址保留区域标识
+func (r *Registry) makeInstance(serviceName string, addr net.IPAddr, port int) *registry.ServiceInstance {
+	return &registry.ServiceInstance{
+		ID:       net.JoinHostPort(addr.String(), strconv.Itoa(port)),
+		Name:     serviceName,
+		Kind:     serviceName,
+		Alias:    addr.String(),
+		State:    r.opts.state,
+		Endpoint: xnet.JoinEndpoint(r.opts.scheme, addr.String(), port),
+		Health:   registry.HealthPassing,
+	}
+}
+
+// 是否为域名不存在错误，无头服务无就绪Pod时解析结果为空
+func isNotFound(err error) bool {
+	e, ok := err.(*net.DNSError)
+	return ok && e.IsNotFound
+}
@@ -0,0 +1,197 @@
+package dns_test
+
+import (
+	"context"
+	"github.com/dobyte/due/registry/dns/v2"
+	"github.com/dobyte/due/v2/core/endpoint"
+	"github.com/dobyte/due/v2/registry"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 模拟DNS解析器
+type fakeResolver struct {
+	mu    sync.Mutex
+	srvs  map[string][]*net.SRV
+	hosts map[string][]net.IPAddr
+}
+
+func (r *fakeResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := "_" + service + "._" + proto + "." + name
+
+	srvs, ok := r.srvs[key]
+	if !ok {
+		return "", nil, &net.DNSError{Err: "no such host", Name: key, IsNotFound: true}
+	}
+
+	return key, srvs, nil
+}
+
+func (r *fakeResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	addrs, ok := r.hosts[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	return addrs, nil
+}
+
+func (r *fakeResolver) setSRV(name string, srvs ...*net.SRV) {
+	r.mu.Lock()
+	r.srvs[name] = srvs
+	r.mu.Unlock()
+}
+
+func newFakeResolver() *fakeResolver {
+	return &fakeResolver{
+		srvs: map[string][]*net.SRV{
+			"_drpc._tcp.node.game.svc.cluster.local": {
+				{Target: "node-0.node.game.svc.cluster.local.", Port: 3553, Weight: 10},
+			},
+		},
+		hosts: map[string][]net.IPAddr{
+			"node-0.node.game.svc.cluster.local": {{IP: net.ParseIP("10.0.0.1")}},
+			"node-1.node.game.svc.cluster.local": {{IP: net.ParseIP("10.0.0.2")}},
+			"gate.game.svc.cluster.local":        {{IP: net.ParseIP("10.0.1.2")}, {IP: net.ParseIP("10.0.1.1")}},
+			"ipv6.game.svc.cluster.local":        {{IP: net.ParseIP("fd00::1")}, {IP: net.ParseIP("fe80::1"), Zone: "eth0"}},
+		},
+	}
+}
+
+func TestRegistry_ServicesSRV(t *testing.T) {
+	reg := dns.NewRegistry(
+		dns.WithResolver(newFakeResolver()),
+		dns.WithNamespace("game"),
+		dns.WithPortName("drpc"),
+		dns.WithScheme("drpc"),
+	)
+
+	services, err := reg.Services(context.Background(), "node")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(services) != 1 {
+		t.Fatalf("unexpected services: %v", services)
+	}
+
+	ins := services[0]
+
+	if ins.ID != "node-0.node.game.svc.cluster.local:3553" || ins.Alias != "node-0" {
+		t.Fatalf("unexpected instance: %+v", ins)
+	}
+
+	if ins.Endpoint != "drpc://10.0.0.1:3553" || ins.Endpoints["drpc"] != ins.Endpoint || ins.Weight != 10 {
+		t.Fatalf("unexpected instance endpoint: %+v", ins)
+	}
+
+	if ins.Name != "node" || ins.State != "work" || ins.Health != registry.HealthPassing {
+		t.Fatalf("unexpected instance: %+v", ins)
+	}
+}
+
+func TestRegistry_ServicesIP(t *testing.T) {
+	reg := dns.NewRegistry(
+		dns.WithResolver(newFakeResolver()),
+		dns.WithNamespace("game"),
+		dns.WithPort(3553),
+	)
+
+	services, err := reg.Services(context.Background(), "gate")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(services) != 2 || services[0].Endpoint != "grpc://10.0.1.1:3553" || services[1].Endpoint != "grpc://10.0.1.2:3553" {
+		t.Fatalf("unexpected services: %v", services)
+	}
+
+	if services, err = reg.Services(context.Background(), "unknown"); err != nil || len(services) != 0 {
+		t.Fatalf("unexpected services of unknown service: %v, %v", services, err)
+	}
+}
+
+func TestRegistry_ServicesIPv6(t *testing.T) {
+	reg := dns.NewRegistry(
+		dns.WithResolver(newFakeResolver()),
+		dns.WithNamespace("game"),
+		dns.WithPort(3553),
+	)
+
+	services, err := reg.Services(context.Background(), "ipv6")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(services) != 2 {
+		t.Fatalf("unexpected services: %v", services)
+	}
+
+	expected := map[string]string{
+		"[fd00::1]:3553":      "grpc://[fd00::1]:3553",
+		"[fe80::1%eth0]:3553": "grpc://[fe80::1%25eth0]:3553",
+	}
+
+	for _, ins := range services {
+		if expected[ins.ID] != ins.Endpoint {
+			t.Fatalf("unexpected instance: %s %s", ins.ID, ins.Endpoint)
+		}
+
+		ep, err := endpoint.ParseEndpoint(ins.Endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ep.Address() != ins.ID {
+			t.Fatalf("endpoint is not dialable: %s", ep.Address())
+		}
+	}
+}
+
+func TestRegistry_Watch(t *testing.T) {
+	resolver := newFakeResolver()
+
+	reg := dns.NewRegistry(
+		dns.WithResolver(resolver),
+		dns.WithNamespace("game"),
+		dns.WithPortName("drpc"),
+		dns.WithRefreshInterval(10*time.Millisecond),
+	)
+	defer reg.Close()
+
+	watcher, err := reg.Watch(context.Background(), "node")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Stop()
+
+	services, err := watcher.Next()
+	if err != nil || len(services) != 1 {
+		t.Fatalf("unexpected initial services: %v, %v", services, err)
+	}
+
+	resolver.setSRV("_drpc._tcp.node.game.svc.cluster.local",
+		&net.SRV{Target: "node-0.node.game.svc.cluster.local.", Port: 3553, Weight: 10},
+		&net.SRV{Target: "node-1.node.game.svc.cluster.local.", Port: 3553, Weight: 10},
+	)
+
+	if services, err = watcher.Next(); err != nil || len(services) != 2 {
+		t.Fatalf("unexpected services after scale up: %v, %v", services, err)
+	}
+
+	resolver.setSRV("_drpc._tcp.node.game.svc.cluster.local",
+		&net.SRV{Target: "node-1.node.game.svc.cluster.local.", Port: 3553, Weight: 10},
+	)
+
+	if services, err = watcher.Next(); err != nil || len(services) != 1 || services[0].Alias != "node-1" {
+		t.Fatalf("unexpected services after scale down: %v, %v", services, err)
+	}
+}
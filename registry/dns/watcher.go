@@ -0,0 +1,170 @@
+package dns
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/log"
+	"github.com/dobyte/due/v2/registry"
+	"sync"
+	"time"
+)
+
+type watcherMgr struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	registry    *Registry
+	serviceName string
+
+	rw       sync.RWMutex
+	services []*registry.ServiceInstance
+	watchers map[*watcher]struct{}
+}
+
+type watcher struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	mgr     *watcherMgr
+	started bool
+	chWatch chan []*registry.ServiceInstance
+}
+
+func newWatcherMgr(ctx context.Context, r *Registry, serviceName string) (*watcherMgr, error) {
+	services, err := r.resolve(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	wm := &watcherMgr{}
+	wm.ctx, wm.cancel = context.WithCancel(r.ctx)
+	wm.registry = r
+	wm.serviceName = serviceName
+	wm.services = services
+	wm.watchers = make(map[*watcher]struct{})
+
+	go wm.refresh()
+
+	return wm, nil
+}
+
+// 定时重新解析，服务实例发生增减或变更时通知所有监听器
+func (wm *watcherMgr) refresh() {
+	ticker := time.NewTicker(wm.registry.opts.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-wm.ctx.Done():
+			return
+		case <-ticker.C:
+			services, err := wm.registry.resolve(wm.ctx, wm.serviceName)
+			if err != nil {
+				log.Warnf("resolve service instances failed, service: %s, err: %v", wm.serviceName, err)
+				continue
+			}
+
+			wm.rw.Lock()
+			if equal(wm.services, services) {
+				wm.rw.Unlock()
+				continue
+			}
+			wm.services = services
+			for w := range wm.watchers {
+				w.notify(services)
+			}
+			wm.rw.Unlock()
+		}
+	}
+}
+
+// 创建监听器
+func (wm *watcherMgr) fork() *watcher {
+	w := &watcher{}
+	w.ctx, w.cancel = context.WithCancel(wm.ctx)
+	w.mgr = wm
+	w.chWatch = make(chan []*registry.ServiceInstance, 1)
+
+	wm.rw.Lock()
+	wm.watchers[w] = struct{}{}
+	wm.rw.Unlock()
+
+	return w
+}
+
+// 回收监听器，所有监听器停止后停止解析
+func (wm *watcherMgr) recycle(w *watcher) {
+	wm.registry.rw.Lock()
+	defer wm.registry.rw.Unlock()
+
+	wm.rw.Lock()
+	delete(wm.watchers, w)
+	empty := len(wm.watchers) == 0
+	wm.rw.Unlock()
+
+	if empty {
+		if wm.registry.watchers[wm.serviceName] == wm {
+			delete(wm.registry.watchers, wm.serviceName)
+		}
+
+		wm.cancel()
+	}
+}
+
+// 获取当前服务实例列表
+func (wm *watcherMgr) current() []*registry.ServiceInstance {
+	wm.rw.RLock()
+	defer wm.rw.RUnlock()
+
+	return wm.services
+}
+
+// 通知最新的服务实例列表，未被读取的旧列表将被替换
+func (w *watcher) notify(services []*registry.ServiceInstance) {
+	select {
+	case <-w.chWatch:
+	default:
+	}
+
+	w.chWatch <- services
+}
+
+// Next 返回服务实例列表
+func (w *watcher) Next() ([]*registry.ServiceInstance, error) {
+	if !w.started {
+		w.started = true
+
+		select {
+		case <-w.chWatch:
+		default:
+		}
+
+		return w.mgr.current(), nil
+	}
+
+	select {
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	case services := <-w.chWatch:
+		return services, nil
+	}
+}
+
+// Stop 停止监听
+func (w *watcher) Stop() error {
+	w.cancel()
+	w.mgr.recycle(w)
+	return nil
+}
+
+// 比较服务实例列表是否一致，列表均已按服务实例ID排序
+func equal(a, b []*registry.ServiceInstance) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].ID != b[i].ID || a[i].Endpoint != b[i].Endpoint || a[i].Weight != b[i].Weight {
+			return false
+		}
+	}
+
+	return true
+}
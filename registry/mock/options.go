@@ -0,0 +1,26 @@
+package mock
+
+import (
+	"github.com/dobyte/due/v2/registry"
+)
+
+const name = "mock"
+
+type Option func(o *options)
+
+type options struct {
+	// 服务实例序列化器
+	// 默认为JSON序列化器，与consul等注册中心保持一致的元数据编码
+	serializer registry.Serializer
+}
+
+func defaultOptions() *options {
+	return &options{
+		serializer: registry.NewJSONSerializer(),
+	}
+}
+
+// WithSerializer 设置服务实例序列化器
+func WithSerializer(serializer registry.Serializer) Option {
+	return func(o *options) { o.serializer = serializer }
+}
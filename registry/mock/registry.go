@@ -0,0 +1,211 @@
+package mock
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/registry"
+	"sort"
+	"sync"
+)
+
+var _ registry.Registry = &Registry{}
+
+// Registry 内存服务注册中心，用于单元测试
+// 服务实例以元数据的形式保存，读取时重新解码，保证路由与事件的编码行为与真实注册中心一致
+// 服务实例变化时同步投递至所有监听器，测试中无需等待
+type Registry struct {
+	opts     *options
+	rw       sync.RWMutex
+	services map[string]map[string]*entry
+	watchers map[string]map[*watcher]struct{}
+}
+
+type entry struct {
+	meta   map[string]string
+	health registry.Health
+}
+
+func NewRegistry(opts ...Option) *Registry {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &Registry{
+		opts:     o,
+		services: make(map[string]map[string]*entry),
+		watchers: make(map[string]map[*watcher]struct{}),
+	}
+}
+
+// Name 获取服务注册发现组件名
+func (r *Registry) Name() string {
+	return name
+}
+
+// Register 注册服务实例
+func (r *Registry) Register(ctx context.Context, ins *registry.ServiceInstance) error {
+	if ins == nil || ins.ID == "" || ins.Name == "" {
+		return errors.ErrInvalidArgument
+	}
+
+	meta, err := r.opts.serializer.Marshal(ins)
+	if err != nil {
+		return err
+	}
+
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	instances, ok := r.services[ins.Name]
+	if !ok {
+		instances = make(map[string]*entry)
+		r.services[ins.Name] = instances
+	}
+
+	if e, ok := instances[ins.ID]; ok {
+		e.meta = meta
+	} else {
+		instances[ins.ID] = &entry{meta: meta, health: registry.HealthPassing}
+	}
+
+	r.broadcast(ins.Name)
+
+	return nil
+}
+
+// Deregister 解注册服务实例
+func (r *Registry) Deregister(ctx context.Context, ins *registry.ServiceInstance) error {
+	if ins == nil {
+		return errors.ErrInvalidArgument
+	}
+
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	instances, ok := r.services[ins.Name]
+	if !ok {
+		return nil
+	}
+
+	if _, ok = instances[ins.ID]; !ok {
+		return nil
+	}
+
+	delete(instances, ins.ID)
+
+	if len(instances) == 0 {
+		delete(r.services, ins.Name)
+	}
+
+	r.broadcast(ins.Name)
+
+	return nil
+}
+
+// SetHealth 设置服务实例健康状态，用于模拟注册中心健康检查结果
+func (r *Registry) SetHealth(serviceName, insID string, health registry.Health) error {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	e, ok := r.services[serviceName][insID]
+	if !ok {
+		return errors.ErrNotFoundInstance
+	}
+
+	if e.health == health {
+		return nil
+	}
+
+	e.health = health
+
+	r.broadcast(serviceName)
+
+	return nil
+}
+
+// Services 获取服务实例列表
+func (r *Registry) Services(ctx context.Context, serviceName string) ([]*registry.ServiceInstance, error) {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+
+	return r.snapshot(serviceName)
+}
+
+// Watch 监听相同服务名的服务实例变化
+// 与真实注册中心一致，ctx仅作用于创建监听器，监听器在调用Stop后结束
+func (r *Registry) Watch(ctx context.Context, serviceName string) (registry.Watcher, error) {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	services, err := r.snapshot(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	w := newWatcher(r, serviceName, services)
+
+	watchers, ok := r.watchers[serviceName]
+	if !ok {
+		watchers = make(map[*watcher]struct{})
+		r.watchers[serviceName] = watchers
+	}
+
+	watchers[w] = struct{}{}
+
+	return w, nil
+}
+
+// 回收监听器
+func (r *Registry) recycle(w *watcher) {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	if watchers, ok := r.watchers[w.serviceName]; ok {
+		delete(watchers, w)
+
+		if len(watchers) == 0 {
+			delete(r.watchers, w.serviceName)
+		}
+	}
+}
+
+// 向监听器投递服务实例快照，调用方需持有写锁
+func (r *Registry) broadcast(serviceName string) {
+	watchers, ok := r.watchers[serviceName]
+	if !ok {
+		return
+	}
+
+	for w := range watchers {
+		services, err := r.snapshot(serviceName)
+		if err != nil {
+			continue
+		}
+
+		w.push(services)
+	}
+}
+
+// 解码服务实例快照，按服务实例ID排序以保证结果稳定，调用方需持有锁
+func (r *Registry) snapshot(serviceName string) ([]*registry.ServiceInstance, error) {
+	instances := r.services[serviceName]
+	services := make([]*registry.ServiceInstance, 0, len(instances))
+
+	for _, e := range instances {
+		ins, err := r.opts.serializer.Unmarshal(e.meta)
+		if err != nil {
+			return nil, err
+		}
+
+		ins.Health = e.health
+
+		services = append(services, ins)
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].ID < services[j].ID
+	})
+
+	return services, nil
+}
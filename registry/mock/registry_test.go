@@ -0,0 +1,128 @@
+package mock_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/registry"
+	"github.com/dobyte/due/v2/registry/mock"
+	"testing"
+)
+
+func newInstance(id string) *registry.ServiceInstance {
+	return &registry.ServiceInstance{
+		ID:       id,
+		Name:     "node",
+		Kind:     "node",
+		State:    "work",
+		Routes:   []registry.Route{{ID: 1, Stateful: true}, {ID: 2, Internal: true}},
+		Events:   []int{1, 2},
+		Endpoint: "drpc://127.0.0.1:3553",
+		Weight:   10,
+	}
+}
+
+func TestRegistry_Services(t *testing.T) {
+	ctx := context.Background()
+	reg := mock.NewRegistry()
+
+	if err := reg.Register(ctx, newInstance("2")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(ctx, newInstance("1")); err != nil {
+		t.Fatal(err)
+	}
+
+	services, err := reg.Services(ctx, "node")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(services) != 2 || services[0].ID != "1" || services[1].ID != "2" {
+		t.Fatalf("unexpected services: %v", services)
+	}
+
+	ins := services[0]
+
+	if len(ins.Routes) != 2 || !ins.Routes[0].Stateful || !ins.Routes[1].Internal {
+		t.Fatalf("unexpected routes: %v", ins.Routes)
+	}
+
+	if len(ins.Events) != 2 || ins.Health != registry.HealthPassing {
+		t.Fatalf("unexpected instance: %+v", ins)
+	}
+}
+
+func TestRegistry_Watch(t *testing.T) {
+	ctx := context.Background()
+	reg := mock.NewRegistry()
+
+	if err := reg.Register(ctx, newInstance("1")); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := reg.Watch(ctx, "node")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Stop()
+
+	if services, err := watcher.Next(); err != nil || len(services) != 1 {
+		t.Fatalf("unexpected initial snapshot: %v, %v", services, err)
+	}
+
+	if err = reg.Register(ctx, newInstance("2")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = reg.SetHealth("node", "1", registry.HealthCritical); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = reg.Deregister(ctx, newInstance("2")); err != nil {
+		t.Fatal(err)
+	}
+
+	if services, err := watcher.Next(); err != nil || len(services) != 2 {
+		t.Fatalf("unexpected register snapshot: %v, %v", services, err)
+	}
+
+	if services, err := watcher.Next(); err != nil || len(services) != 2 || !services[0].Health.Unhealthy() {
+		t.Fatalf("unexpected health snapshot: %v, %v", services, err)
+	}
+
+	if services, err := watcher.Next(); err != nil || len(services) != 1 {
+		t.Fatalf("unexpected deregister snapshot: %v, %v", services, err)
+	}
+}
+
+func TestRegistry_WatchContext(t *testing.T) {
+	reg := mock.NewRegistry()
+
+	// 创建监听器的上下文取消后监听器仍然有效
+	ctx, cancel := context.WithCancel(context.Background())
+	watcher, err := reg.Watch(ctx, "node")
+	cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if services, err := watcher.Next(); err != nil || len(services) != 0 {
+		t.Fatalf("unexpected initial snapshot: %v, %v", services, err)
+	}
+
+	if err = reg.Register(context.Background(), newInstance("1")); err != nil {
+		t.Fatal(err)
+	}
+
+	if services, err := watcher.Next(); err != nil || len(services) != 1 {
+		t.Fatalf("unexpected register snapshot: %v, %v", services, err)
+	}
+
+	if err = watcher.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = watcher.Next(); err == nil {
+		t.Fatal("expected error after stop")
+	}
+}
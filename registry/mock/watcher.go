@@ -0,0 +1,84 @@
+package mock
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/registry"
+	"sync"
+)
+
+var _ registry.Watcher = &watcher{}
+
+type watcher struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	registry    *Registry
+	serviceName string
+	mu          sync.Mutex
+	queue       [][]*registry.ServiceInstance
+	chNotify    chan struct{}
+}
+
+func newWatcher(r *Registry, serviceName string, services []*registry.ServiceInstance) *watcher {
+	w := &watcher{}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	w.registry = r
+	w.serviceName = serviceName
+	w.queue = [][]*registry.ServiceInstance{services}
+	w.chNotify = make(chan struct{}, 1)
+
+	return w
+}
+
+// 投递服务实例快照，快照在投递时即已入队，不会阻塞注册流程
+func (w *watcher) push(services []*registry.ServiceInstance) {
+	w.mu.Lock()
+	w.queue = append(w.queue, services)
+	w.mu.Unlock()
+
+	select {
+	case w.chNotify <- struct{}{}:
+	default:
+	}
+}
+
+// 弹出最早的服务实例快照
+func (w *watcher) pop() ([]*registry.ServiceInstance, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.queue) == 0 {
+		return nil, false
+	}
+
+	services := w.queue[0]
+	w.queue[0] = nil
+	w.queue = w.queue[1:]
+
+	return services, true
+}
+
+// Next 返回服务实例列表
+func (w *watcher) Next() ([]*registry.ServiceInstance, error) {
+	for {
+		if err := w.ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if services, ok := w.pop(); ok {
+			return services, nil
+		}
+
+		select {
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		case <-w.chNotify:
+		}
+	}
+}
+
+// Stop 停止监听
+func (w *watcher) Stop() error {
+	w.cancel()
+	w.registry.recycle(w)
+	return nil
+}
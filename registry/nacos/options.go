@@ -3,6 +3,7 @@ package nacos
 import (
 	"context"
 	"github.com/dobyte/due/v2/etc"
+	"github.com/dobyte/due/v2/registry"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
 	"time"
 )
@@ -114,6 +115,10 @@ type options struct {
 	// 日志输出级别
 	// 默认为info
 	logLevel string
+
+	// 服务实例序列化器
+	// 默认为JSON序列化器
+	serializer registry.Serializer
 }
 
 func defaultOptions() *options {
@@ -134,6 +139,7 @@ func defaultOptions() *options {
 		password:    etc.Get(defaultPasswordKey, defaultPassword).String(),
 		logDir:      etc.Get(defaultLogDirKey, defaultLogDir).String(),
 		logLevel:    etc.Get(defaultLogLevelKey, defaultLogLevel).String(),
+		serializer:  registry.NewJSONSerializer(),
 	}
 }
 
@@ -221,3 +227,8 @@ func WithLogDir(logDir string) Option {
 func WithLogLevel(logLevel string) Option {
 	return func(o *options) { o.logLevel = logLevel }
 }
+
+// WithSerializer 设置服务实例序列化器
+func WithSerializer(serializer registry.Serializer) Option {
+	return func(o *options) { o.serializer = serializer }
+}
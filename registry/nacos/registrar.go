@@ -2,27 +2,10 @@ package nacos
 
 import (
 	"context"
-	"github.com/dobyte/due/v2/encoding/json"
 	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/registry"
-	"github.com/dobyte/due/v2/utils/xconv"
+	"github.com/dobyte/due/v2/utils/xnet"
 	"github.com/nacos-group/nacos-sdk-go/v2/vo"
-	"net"
-	"net/url"
-	"strconv"
-)
-
-const (
-	metaFieldID       = "id"
-	metaFieldName     = "name"
-	metaFieldKind     = "kind"
-	metaFieldAlias    = "alias"
-	metaFieldState    = "state"
-	metaFieldRoutes   = "routes"
-	metaFieldEvents   = "events"
-	metaFieldWeight   = "weight"
-	metaFieldServices = "services"
-	metaFieldEndpoint = "endpoint"
 )
 
 type registrar struct {
@@ -40,17 +23,7 @@ func (r *registrar) register(ctx context.Context, ins *registry.ServiceInstance)
 		return err
 	}
 
-	routes, err := json.Marshal(ins.Routes)
-	if err != nil {
-		return err
-	}
-
-	events, err := json.Marshal(ins.Events)
-	if err != nil {
-		return err
-	}
-
-	services, err := json.Marshal(ins.Services)
+	metadata, err := r.registry.opts.serializer.Marshal(ins)
 	if err != nil {
 		return err
 	}
@@ -65,18 +38,7 @@ func (r *registrar) register(ctx context.Context, ins *registry.ServiceInstance)
 		ServiceName: ins.Name,
 		ClusterName: r.registry.opts.clusterName,
 		GroupName:   r.registry.opts.groupName,
-		Metadata: map[string]string{
-			metaFieldID:       ins.ID,
-			metaFieldName:     ins.Name,
-			metaFieldKind:     ins.Kind,
-			metaFieldAlias:    ins.Alias,
-			metaFieldState:    ins.State,
-			metaFieldRoutes:   string(routes),
-			metaFieldEvents:   string(events),
-			metaFieldServices: string(services),
-			metaFieldEndpoint: ins.Endpoint,
-			metaFieldWeight:   xconv.String(ins.Weight),
-		},
+		Metadata:    metadata,
 	}
 
 	ok, err := r.registry.opts.client.RegisterInstance(param)
@@ -119,21 +81,12 @@ func (r *registrar) deregister(ctx context.Context, ins *registry.ServiceInstanc
 	return nil
 }
 
+// 解析端点的主机地址与端口，IPv6地址不含方括号，由Nacos按IP字段原样保存；服务发现时以元数据中的完整端点为准
 func (r *registrar) parseHostPort(endpoint string) (string, uint64, error) {
-	raw, err := url.Parse(endpoint)
-	if err != nil {
-		return "", 0, err
-	}
-
-	host, p, err := net.SplitHostPort(raw.Host)
-	if err != nil {
-		return "", 0, err
-	}
-
-	port, err := strconv.ParseUint(p, 10, 64)
+	_, host, port, err := xnet.SplitEndpoint(endpoint)
 	if err != nil {
 		return "", 0, err
 	}
 
-	return host, port, nil
+	return host, uint64(port), nil
 }
@@ -2,7 +2,6 @@ package nacos
 
 import (
 	"context"
-	"github.com/dobyte/due/v2/encoding/json"
 	"github.com/dobyte/due/v2/errors"
 	"github.com/dobyte/due/v2/log"
 	"github.com/dobyte/due/v2/registry"
@@ -10,7 +9,6 @@ import (
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
 	"github.com/nacos-group/nacos-sdk-go/v2/model"
 	"github.com/nacos-group/nacos-sdk-go/v2/vo"
-	"github.com/dobyte/due/v2/utils/xconv"
 	"net"
 	"net/url"
 	"strconv"
@@ -72,7 +70,7 @@ func NewRegistry(opts ...Option) *Registry {
 				err, endpoint = e, v
 			} else {
 				host, p, e := net.SplitHostPort(raw.Host)
-				if err != nil {
+				if e != nil {
 					err, endpoint = e, v
 					continue
 				}
@@ -200,44 +198,19 @@ func (r *Registry) services(ctx context.Context, serviceName string) ([]*registr
 		}
 	}
 
-	return parseInstances(instances)
+	return parseInstances(r.opts.serializer, instances)
 }
 
-func parseInstances(instances []model.Instance) ([]*registry.ServiceInstance, error) {
+func parseInstances(serializer registry.Serializer, instances []model.Instance) ([]*registry.ServiceInstance, error) {
 	services := make([]*registry.ServiceInstance, 0, len(instances))
 	for _, instance := range instances {
 		if !instance.Healthy || !instance.Enable {
 			continue
 		}
 
-		ins := &registry.ServiceInstance{}
-		ins.ID = instance.Metadata[metaFieldID]
-		ins.Name = instance.Metadata[metaFieldName]
-		ins.Kind = instance.Metadata[metaFieldKind]
-		ins.Alias = instance.Metadata[metaFieldAlias]
-		ins.State = instance.Metadata[metaFieldState]
-		ins.Endpoint = instance.Metadata[metaFieldEndpoint]
-		ins.Routes = make([]registry.Route, 0)
-		ins.Events = make([]int, 0)
-		ins.Services = make([]string, 0)
-		ins.Weight = xconv.Int(instance.Metadata[metaFieldWeight])
-
-		if v := instance.Metadata[metaFieldRoutes]; v != "" {
-			if err := json.Unmarshal([]byte(v), &ins.Routes); err != nil {
-				return nil, err
-			}
-		}
-
-		if v := instance.Metadata[metaFieldEvents]; v != "" {
-			if err := json.Unmarshal([]byte(v), &ins.Events); err != nil {
-				return nil, err
-			}
-		}
-
-		if v := instance.Metadata[metaFieldServices]; v != "" {
-			if err := json.Unmarshal([]byte(v), &ins.Services); err != nil {
-				return nil, err
-			}
+		ins, err := serializer.Unmarshal(instance.Metadata)
+		if err != nil {
+			return nil, err
 		}
 
 		services = append(services, ins)
@@ -110,7 +110,7 @@ func (wm *watcherMgr) subscribe() error {
 				return
 			}
 
-			services, err := parseInstances(instances)
+			services, err := parseInstances(wm.registry.opts.serializer, instances)
 			if err != nil {
 				log.Warnf("%s instances parse failed: %v", wm.serviceName, err)
 				return
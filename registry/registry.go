@@ -44,14 +44,58 @@ type ServiceInstance struct {
 	State string `json:"state,omitempty"`
 	// 服务事件集合
 	Events []int `json:"events,omitempty"`
+	// 服务实例自定义标签，如region=eu、version=canary，注册中心支持时可按标签过滤服务实例
+	Tags []string `json:"tags,omitempty"`
 	// 服务路由ID
 	Routes []Route `json:"routes,omitempty"`
 	// 服务路由列表
 	Services []string `json:"services,omitempty"`
 	// 微服务实体暴露端口
 	Endpoint string `json:"endpoint,omitempty"`
+	// 微服务实体命名端口（端口名称 -> 端口地址）
+	Endpoints map[string]string `json:"endpoints,omitempty"`
 	// 微服务路由加权轮询权重
 	Weight int `json:"weight,omitempty"`
+	// 服务实例负载，由服务实例定期上报
+	Load *Load `json:"load,omitempty"`
+	// 服务实例健康状态，由注册中心在服务发现时提供，不参与序列化
+	Health Health `json:"-"`
+}
+
+const (
+	HealthUnknown  Health = iota // 未知（注册中心未提供健康状态）
+	HealthPassing                // 健康
+	HealthWarning                // 警告
+	HealthCritical               // 异常
+)
+
+// Health 服务实例健康状态
+type Health int
+
+func (h Health) String() string {
+	switch h {
+	case HealthPassing:
+		return "passing"
+	case HealthWarning:
+		return "warning"
+	case HealthCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// Unhealthy 是否为不健康状态，负载均衡时将跳过处于警告或异常状态的服务实例
+func (h Health) Unhealthy() bool {
+	return h == HealthWarning || h == HealthCritical
+}
+
+// Load 服务实例负载
+type Load struct {
+	// 活跃数，如活跃会话数、Actor数等
+	Active int64 `json:"a,omitempty"`
+	// 负载评分，值越大表示越繁忙，由负载来源定义其取值范围
+	Score float64 `json:"s,omitempty"`
 }
 
 type Route struct {
@@ -61,4 +105,6 @@ type Route struct {
 	Stateful bool `json:"s,omitempty"`
 	// 是否内部路由
 	Internal bool `json:"n,omitempty"`
+	// 是否临时路由，临时路由仅在当前服务实例存活期间有效
+	Ephemeral bool `json:"e,omitempty"`
 }
@@ -0,0 +1,47 @@
+package registry
+
+// RouteAttr 路由属性位域
+// 各属性位的含义一经发布不可变更，新增属性只能追加新的位；解码时忽略未知的属性位，以兼容新版本写入的数据
+type RouteAttr uint8
+
+const (
+	RouteAttrInternal  RouteAttr = 1 << iota // 内部路由
+	RouteAttrStateful                        // 有状态路由
+	RouteAttrEphemeral                       // 临时路由
+)
+
+// RouteAttrKnown 当前版本已知的全部属性位
+const RouteAttrKnown = RouteAttrInternal | RouteAttrStateful | RouteAttrEphemeral
+
+// Has 是否包含指定属性
+func (a RouteAttr) Has(attr RouteAttr) bool {
+	return a&attr == attr
+}
+
+// Attrs 获取路由属性位域
+func (r Route) Attrs() RouteAttr {
+	var attrs RouteAttr
+
+	if r.Internal {
+		attrs |= RouteAttrInternal
+	}
+
+	if r.Stateful {
+		attrs |= RouteAttrStateful
+	}
+
+	if r.Ephemeral {
+		attrs |= RouteAttrEphemeral
+	}
+
+	return attrs
+}
+
+// SetAttrs 按属性位域设置路由属性，未知的属性位将被忽略
+func (r *Route) SetAttrs(attrs RouteAttr) {
+	attrs &= RouteAttrKnown
+
+	r.Internal = attrs.Has(RouteAttrInternal)
+	r.Stateful = attrs.Has(RouteAttrStateful)
+	r.Ephemeral = attrs.Has(RouteAttrEphemeral)
+}
@@ -0,0 +1,33 @@
+package registry_test
+
+import (
+	"github.com/dobyte/due/v2/registry"
+	"testing"
+)
+
+func TestRoute_SetAttrs(t *testing.T) {
+	tests := []struct {
+		attrs    registry.RouteAttr
+		expected registry.Route
+	}{
+		{attrs: 0, expected: registry.Route{}},
+		{attrs: registry.RouteAttrInternal, expected: registry.Route{Internal: true}},
+		{attrs: registry.RouteAttrStateful | registry.RouteAttrEphemeral, expected: registry.Route{Stateful: true, Ephemeral: true}},
+		{attrs: 1 << 7, expected: registry.Route{}},
+		{attrs: 0xff, expected: registry.Route{Stateful: true, Internal: true, Ephemeral: true}},
+		{attrs: registry.RouteAttrStateful | 1<<5, expected: registry.Route{Stateful: true}},
+	}
+
+	for _, tt := range tests {
+		route := registry.Route{}
+		route.SetAttrs(tt.attrs)
+
+		if route != tt.expected {
+			t.Fatalf("decode attrs %08b: want %+v got %+v", tt.attrs, tt.expected, route)
+		}
+
+		if route.Attrs() != tt.attrs&registry.RouteAttrKnown {
+			t.Fatalf("encode attrs %08b: got %08b", tt.attrs, route.Attrs())
+		}
+	}
+}
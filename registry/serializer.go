@@ -0,0 +1,134 @@
+package registry
+
+import (
+	"github.com/dobyte/due/v2/encoding/json"
+	"github.com/dobyte/due/v2/utils/xconv"
+)
+
+const (
+	metaFieldID        = "id"
+	metaFieldName      = "name"
+	metaFieldKind      = "kind"
+	metaFieldAlias     = "alias"
+	metaFieldState     = "state"
+	metaFieldRoutes    = "routes"
+	metaFieldEvents    = "events"
+	metaFieldWeight    = "weight"
+	metaFieldServices  = "services"
+	metaFieldEndpoint  = "endpoint"
+	metaFieldEndpoints = "endpoints"
+	metaFieldLoad      = "load"
+)
+
+// Serializer 服务实例序列化器，负责服务实例与注册中心元数据之间的相互转换
+type Serializer interface {
+	// Marshal 将服务实例编码为元数据
+	Marshal(ins *ServiceInstance) (map[string]string, error)
+	// Unmarshal 将元数据解码为服务实例
+	Unmarshal(meta map[string]string) (*ServiceInstance, error)
+}
+
+type jsonSerializer struct{}
+
+var _ Serializer = &jsonSerializer{}
+
+// NewJSONSerializer 创建JSON序列化器，复合字段以JSON字符串的形式写入元数据
+func NewJSONSerializer() Serializer {
+	return &jsonSerializer{}
+}
+
+// Marshal 将服务实例编码为元数据
+func (s *jsonSerializer) Marshal(ins *ServiceInstance) (map[string]string, error) {
+	routes, err := json.Marshal(ins.Routes)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := json.Marshal(ins.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := json.Marshal(ins.Services)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints, err := json.Marshal(ins.Endpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := map[string]string{
+		metaFieldID:        ins.ID,
+		metaFieldName:      ins.Name,
+		metaFieldKind:      ins.Kind,
+		metaFieldAlias:     ins.Alias,
+		metaFieldState:     ins.State,
+		metaFieldRoutes:    string(routes),
+		metaFieldEvents:    string(events),
+		metaFieldServices:  string(services),
+		metaFieldEndpoint:  ins.Endpoint,
+		metaFieldEndpoints: string(endpoints),
+		metaFieldWeight:    xconv.String(ins.Weight),
+	}
+
+	if ins.Load != nil {
+		load, err := json.Marshal(ins.Load)
+		if err != nil {
+			return nil, err
+		}
+
+		meta[metaFieldLoad] = string(load)
+	}
+
+	return meta, nil
+}
+
+// Unmarshal 将元数据解码为服务实例
+func (s *jsonSerializer) Unmarshal(meta map[string]string) (*ServiceInstance, error) {
+	ins := &ServiceInstance{}
+	ins.ID = meta[metaFieldID]
+	ins.Name = meta[metaFieldName]
+	ins.Kind = meta[metaFieldKind]
+	ins.Alias = meta[metaFieldAlias]
+	ins.State = meta[metaFieldState]
+	ins.Endpoint = meta[metaFieldEndpoint]
+	ins.Routes = make([]Route, 0)
+	ins.Events = make([]int, 0)
+	ins.Services = make([]string, 0)
+	ins.Weight = xconv.Int(meta[metaFieldWeight])
+
+	if v := meta[metaFieldRoutes]; v != "" {
+		if err := json.Unmarshal([]byte(v), &ins.Routes); err != nil {
+			return nil, err
+		}
+	}
+
+	if v := meta[metaFieldEvents]; v != "" {
+		if err := json.Unmarshal([]byte(v), &ins.Events); err != nil {
+			return nil, err
+		}
+	}
+
+	if v := meta[metaFieldServices]; v != "" {
+		if err := json.Unmarshal([]byte(v), &ins.Services); err != nil {
+			return nil, err
+		}
+	}
+
+	if v := meta[metaFieldEndpoints]; v != "" && v != "null" {
+		if err := json.Unmarshal([]byte(v), &ins.Endpoints); err != nil {
+			return nil, err
+		}
+	}
+
+	if v := meta[metaFieldLoad]; v != "" {
+		ins.Load = &Load{}
+		if err := json.Unmarshal([]byte(v), ins.Load); err != nil {
+			return nil, err
+		}
+	}
+
+	return ins, nil
+}
@@ -0,0 +1,42 @@
+package registry_test
+
+import (
+	"github.com/dobyte/due/v2/registry"
+	"reflect"
+	"testing"
+)
+
+func TestJSONSerializer(t *testing.T) {
+	ins := &registry.ServiceInstance{
+		ID:        "1",
+		Name:      "node",
+		Kind:      "node",
+		Alias:     "mahjong",
+		State:     "work",
+		Events:    []int{1, 2},
+		Routes:    []registry.Route{{ID: 1, Stateful: true}, {ID: 2, Internal: true}},
+		Services:  []string{"wallet"},
+		Endpoint:  "grpc://127.0.0.1:3553",
+		Endpoints: map[string]string{"http": "http://127.0.0.1:8080"},
+		Weight:    10,
+		Load:      &registry.Load{Active: 12, Score: 0.35},
+	}
+
+	serializer := registry.NewJSONSerializer()
+
+	meta, err := serializer.Marshal(ins)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("%+v", meta)
+
+	decoded, err := serializer.Unmarshal(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(ins, decoded) {
+		t.Fatalf("round trip mismatch, want %+v got %+v", ins, decoded)
+	}
+}
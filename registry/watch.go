@@ -0,0 +1,89 @@
+package registry
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/errors"
+)
+
+type WatchOptions struct {
+	// 服务名称，为空时监听与服务类型同名的服务
+	// 网关、节点及网格均以服务类型作为服务名称注册，仅按服务类型过滤时无需指定
+	Name string
+	// 服务类型，为空时不过滤
+	Kind string
+}
+
+// 获取监听的服务名称
+func (o WatchOptions) serviceName() string {
+	if o.Name != "" {
+		return o.Name
+	}
+
+	return o.Kind
+}
+
+// Match 检测服务实例是否匹配过滤条件
+func (o WatchOptions) Match(ins *ServiceInstance) bool {
+	if ins == nil {
+		return false
+	}
+
+	if o.Name != "" && ins.Name != o.Name {
+		return false
+	}
+
+	if o.Kind != "" && ins.Kind != o.Kind {
+		return false
+	}
+
+	return true
+}
+
+// Filter 过滤服务实例列表
+func (o WatchOptions) Filter(services []*ServiceInstance) []*ServiceInstance {
+	list := make([]*ServiceInstance, 0, len(services))
+
+	for _, ins := range services {
+		if o.Match(ins) {
+			list = append(list, ins)
+		}
+	}
+
+	return list
+}
+
+// Watch 按照过滤条件监听服务实例变化，返回过滤后的服务实例列表快照
+// 服务名称与服务类型不能同时为空，上下文取消或监听发生错误时关闭通道
+func Watch(ctx context.Context, discovery Discovery, opts WatchOptions) (<-chan []*ServiceInstance, error) {
+	serviceName := opts.serviceName()
+	if serviceName == "" {
+		return nil, errors.ErrInvalidArgument
+	}
+
+	watcher, err := discovery.Watch(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan []*ServiceInstance, 1)
+
+	go func() {
+		defer close(ch)
+		defer watcher.Stop()
+
+		for {
+			services, err := watcher.Next()
+			if err != nil {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- opts.Filter(services):
+			}
+		}
+	}()
+
+	return ch, nil
+}
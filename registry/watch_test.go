@@ -0,0 +1,95 @@
+package registry_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/registry"
+	"testing"
+)
+
+type discovery struct {
+	services []*registry.ServiceInstance
+	watched  string
+}
+
+func (d *discovery) Watch(ctx context.Context, serviceName string) (registry.Watcher, error) {
+	d.watched = serviceName
+	return &watcher{ctx: ctx, services: d.services}, nil
+}
+
+func (d *discovery) Services(ctx context.Context, serviceName string) ([]*registry.ServiceInstance, error) {
+	return d.services, nil
+}
+
+type watcher struct {
+	ctx      context.Context
+	services []*registry.ServiceInstance
+	done     bool
+}
+
+func (w *watcher) Next() ([]*registry.ServiceInstance, error) {
+	if !w.done {
+		w.done = true
+		return w.services, nil
+	}
+
+	<-w.ctx.Done()
+
+	return nil, w.ctx.Err()
+}
+
+func (w *watcher) Stop() error {
+	return nil
+}
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := registry.Watch(ctx, &discovery{services: []*registry.ServiceInstance{
+		{ID: "1", Name: "gate", Kind: "gate"},
+		{ID: "2", Name: "gate", Kind: "node"},
+	}}, registry.WatchOptions{Name: "gate", Kind: "gate"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	services := <-ch
+
+	if len(services) != 1 || services[0].ID != "1" {
+		t.Fatalf("unexpected services: %v", services)
+	}
+
+	cancel()
+
+	if _, ok := <-ch; ok {
+		t.Fatal("expected channel closed")
+	}
+}
+
+func TestWatch_Kind(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := &discovery{services: []*registry.ServiceInstance{
+		{ID: "1", Name: "gate", Kind: "gate"},
+		{ID: "2", Name: "gate", Kind: "node"},
+	}}
+
+	// 仅按服务类型过滤时监听与服务类型同名的服务
+	ch, err := registry.Watch(ctx, d, registry.WatchOptions{Kind: "gate"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.watched != "gate" {
+		t.Fatalf("unexpected watched service: %s", d.watched)
+	}
+
+	if services := <-ch; len(services) != 1 || services[0].ID != "1" {
+		t.Fatalf("unexpected services: %v", services)
+	}
+
+	if _, err = registry.Watch(ctx, d, registry.WatchOptions{}); err == nil {
+		t.Fatal("expected invalid argument")
+	}
+}
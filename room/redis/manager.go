@@ -0,0 +1,153 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"github.com/dobyte/due/v2/room"
+	"github.com/go-redis/redis/v8"
+	"strconv"
+)
+
+const (
+	roomMembersKey = "%s:room:%s:members"    // set
+	userRoomsKey   = "%s:room:user:%d:rooms" // set
+)
+
+const name = "redis"
+
+var _ room.Manager = &Manager{}
+
+type Manager struct {
+	opts *options
+}
+
+func NewManager(opts ...Option) *Manager {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.prefix == "" {
+		o.prefix = defaultPrefix
+	}
+
+	if o.client == nil {
+		o.client = redis.NewUniversalClient(&redis.UniversalOptions{
+			Addrs:      o.addrs,
+			DB:         o.db,
+			Username:   o.username,
+			Password:   o.password,
+			MaxRetries: o.maxRetries,
+		})
+	}
+
+	return &Manager{opts: o}
+}
+
+// Name 获取房间管理器组件名
+func (m *Manager) Name() string {
+	return name
+}
+
+// Join 加入房间
+func (m *Manager) Join(ctx context.Context, roomID string, uids ...int64) error {
+	if len(uids) == 0 {
+		return nil
+	}
+
+	members := make([]interface{}, 0, len(uids))
+	for _, uid := range uids {
+		members = append(members, uid)
+	}
+
+	_, err := m.opts.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, fmt.Sprintf(roomMembersKey, m.opts.prefix, roomID), members...)
+
+		for _, uid := range uids {
+			pipe.SAdd(ctx, fmt.Sprintf(userRoomsKey, m.opts.prefix, uid), roomID)
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+// Leave 离开房间
+func (m *Manager) Leave(ctx context.Context, roomID string, uids ...int64) error {
+	if len(uids) == 0 {
+		return nil
+	}
+
+	members := make([]interface{}, 0, len(uids))
+	for _, uid := range uids {
+		members = append(members, uid)
+	}
+
+	_, err := m.opts.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SRem(ctx, fmt.Sprintf(roomMembersKey, m.opts.prefix, roomID), members...)
+
+		for _, uid := range uids {
+			pipe.SRem(ctx, fmt.Sprintf(userRoomsKey, m.opts.prefix, uid), roomID)
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+// LeaveAll 离开用户所在的所有房间
+func (m *Manager) LeaveAll(ctx context.Context, uid int64) error {
+	rooms, err := m.Rooms(ctx, uid)
+	if err != nil {
+		return err
+	}
+
+	if len(rooms) == 0 {
+		return nil
+	}
+
+	_, err = m.opts.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, roomID := range rooms {
+			pipe.SRem(ctx, fmt.Sprintf(roomMembersKey, m.opts.prefix, roomID), uid)
+		}
+
+		pipe.Del(ctx, fmt.Sprintf(userRoomsKey, m.opts.prefix, uid))
+
+		return nil
+	})
+
+	return err
+}
+
+// Rooms 获取用户所在的所有房间
+func (m *Manager) Rooms(ctx context.Context, uid int64) ([]string, error) {
+	return m.opts.client.SMembers(ctx, fmt.Sprintf(userRoomsKey, m.opts.prefix, uid)).Result()
+}
+
+// Count 统计房间成员数
+func (m *Manager) Count(ctx context.Context, roomID string) (int64, error) {
+	return m.opts.client.SCard(ctx, fmt.Sprintf(roomMembersKey, m.opts.prefix, roomID)).Result()
+}
+
+// Members 分页迭代房间成员，cursor为0时从头开始，返回的next为0时表示迭代结束
+// 基于SSCAN实现，迭代期间房间成员变动时同一成员可能在多页中重复返回
+func (m *Manager) Members(ctx context.Context, roomID string, cursor uint64, count int64) ([]int64, uint64, error) {
+	vals, next, err := m.opts.client.SScan(ctx, fmt.Sprintf(roomMembersKey, m.opts.prefix, roomID), cursor, "", count).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	uids := make([]int64, 0, len(vals))
+	for _, val := range vals {
+		uid, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		uids = append(uids, uid)
+	}
+
+	return uids, next, nil
+}
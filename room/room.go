@@ -0,0 +1,21 @@
+package room
+
+import "context"
+
+type Manager interface {
+	// Name 获取房间管理器组件名
+	Name() string
+	// Join 加入房间
+	Join(ctx context.Context, roomID string, uids ...int64) error
+	// Leave 离开房间
+	Leave(ctx context.Context, roomID string, uids ...int64) error
+	// LeaveAll 离开用户所在的所有房间
+	LeaveAll(ctx context.Context, uid int64) error
+	// Rooms 获取用户所在的所有房间
+	Rooms(ctx context.Context, uid int64) ([]string, error)
+	// Count 统计房间成员数
+	Count(ctx context.Context, roomID string) (int64, error)
+	// Members 分页迭代房间成员，cursor为0时从头开始，返回的next为0时表示迭代结束
+	// 迭代期间房间成员变动时同一成员可能在多页中重复返回，调用方需自行去重
+	Members(ctx context.Context, roomID string, cursor uint64, count int64) (uids []int64, next uint64, err error)
+}
@@ -0,0 +1,135 @@
+package redis
+
+import (
+	"github.com/dobyte/due/v2/etc"
+	"github.com/go-redis/redis/v8"
+	"time"
+)
+
+const (
+	defaultAddr       = "127.0.0.1:6379"
+	defaultDB         = 0
+	defaultMaxRetries = 3
+	defaultPrefix     = "due"
+	defaultExpiration = "24h"
+)
+
+const (
+	defaultAddrsKey            = "etc.session.redis.addrs"
+	defaultDBKey               = "etc.session.redis.db"
+	defaultMaxRetriesKey       = "etc.session.redis.maxRetries"
+	defaultMasterNameKey       = "etc.session.redis.masterName"
+	defaultSentinelPasswordKey = "etc.session.redis.sentinelPassword"
+	defaultPrefixKey           = "etc.session.redis.prefix"
+	defaultUsernameKey         = "etc.session.redis.username"
+	defaultPasswordKey         = "etc.session.redis.password"
+	defaultExpirationKey       = "etc.session.redis.expiration"
+)
+
+type Option func(o *options)
+
+type options struct {
+	// 客户端连接地址
+	// 内建客户端配置，默认为[]string{"127.0.0.1:6379"}
+	addrs []string
+
+	// 数据库号
+	// 内建客户端配置，默认为0
+	db int
+
+	// 用户名
+	// 内建客户端配置，默认为空
+	username string
+
+	// 密码
+	// 内建客户端配置，默认为空
+	password string
+
+	// 最大重试次数
+	// 内建客户端配置，默认为3次
+	maxRetries int
+
+	// 哨兵模式主节点名称
+	// 内建客户端配置，设置后将以哨兵模式连接，此时addrs为哨兵节点地址，默认为空
+	masterName string
+
+	// 哨兵节点密码
+	// 内建客户端配置，仅哨兵模式下生效，默认为空
+	sentinelPassword string
+
+	// 客户端
+	// 外部客户端配置，存在外部客户端时，优先使用外部客户端；其次使用共享客户端（xredis.SetSharedClient）；默认为nil
+	client redis.UniversalClient
+
+	// 前缀
+	// key前缀，默认为due
+	prefix string
+
+	// 会话过期时间
+	// 会话数据自最近一次保存起的有效期，小于等于0时永不过期，默认为24h
+	expiration time.Duration
+}
+
+func defaultOptions() *options {
+	return &options{
+		addrs:            etc.Get(defaultAddrsKey, []string{defaultAddr}).Strings(),
+		db:               etc.Get(defaultDBKey, defaultDB).Int(),
+		maxRetries:       etc.Get(defaultMaxRetriesKey, defaultMaxRetries).Int(),
+		masterName:       etc.Get(defaultMasterNameKey).String(),
+		sentinelPassword: etc.Get(defaultSentinelPasswordKey).String(),
+		prefix:           etc.Get(defaultPrefixKey, defaultPrefix).String(),
+		username:         etc.Get(defaultUsernameKey).String(),
+		password:         etc.Get(defaultPasswordKey).String(),
+		expiration:       etc.Get(defaultExpirationKey, defaultExpiration).Duration(),
+	}
+}
+
+// WithAddrs 设置连接地址
+func WithAddrs(addrs ...string) Option {
+	return func(o *options) { o.addrs = addrs }
+}
+
+// WithDB 设置数据库号
+func WithDB(db int) Option {
+	return func(o *options) { o.db = db }
+}
+
+// WithUsername 设置用户名
+func WithUsername(username string) Option {
+	return func(o *options) { o.username = username }
+}
+
+// WithPassword 设置密码
+func WithPassword(password string) Option {
+	return func(o *options) { o.password = password }
+}
+
+// WithMaxRetries 设置最大重试次数
+func WithMaxRetries(maxRetries int) Option {
+	return func(o *options) { o.maxRetries = maxRetries }
+}
+
+// WithMasterName 设置哨兵模式主节点名称，设置后将以哨兵模式连接，此时连接地址为哨兵节点地址
+func WithMasterName(masterName string) Option {
+	return func(o *options) { o.masterName = masterName }
+}
+
+// WithSentinelPassword 设置哨兵节点密码
+func WithSentinelPassword(password string) Option {
+	return func(o *options) { o.sentinelPassword = password }
+}
+
+// WithClient 设置外部客户端
+func WithClient(client redis.UniversalClient) Option {
+	return func(o *options) { o.client = client }
+}
+
+// WithPrefix 设置前缀
+func WithPrefix(prefix string) Option {
+	return func(o *options) { o.prefix = prefix }
+}
+
+// WithExpiration 设置会话过期时间
+func WithExpiration(expiration time.Duration) Option {
+	return func(o *options) { o.expiration = expiration }
+}
@@ -0,0 +1,205 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	xredis "github.com/dobyte/due/redis/v2"
+	"github.com/dobyte/due/v2/encoding/json"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/session"
+	"github.com/go-redis/redis/v8"
+	"strconv"
+	"time"
+)
+
+const (
+	sessionKey      = "%s:session:%s"    // string
+	sessionIndexKey = "%s:session:index" // sorted set
+)
+
+const iterateBatchSize = 100
+
+var _ session.Store = &Store{}
+
+type Store struct {
+	opts    *options
+	builtin bool
+}
+
+func NewStore(opts ...Option) *Store {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.prefix == "" {
+		o.prefix = defaultPrefix
+	}
+
+	if o.client == nil {
+		o.client = xredis.GetSharedClient()
+	}
+
+	s := &Store{}
+
+	if o.client == nil {
+		s.builtin = true
+		o.client = redis.NewUniversalClient(&redis.UniversalOptions{
+			Addrs:            o.addrs,
+			DB:               o.db,
+			Username:         o.username,
+			Password:         o.password,
+			MaxRetries:       o.maxRetries,
+			MasterName:       o.masterName,
+			SentinelPassword: o.sentinelPassword,
+		})
+	}
+
+	s.opts = o
+
+	return s
+}
+
+// Save 保存会话数据，覆盖已有数据并刷新过期时间
+func (s *Store) Save(ctx context.Context, id string, values map[string]string) error {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	expiration := s.opts.expiration
+	if expiration < 0 {
+		expiration = 0
+	}
+
+	_, err = s.opts.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.sessionKey(id), data, expiration)
+		pipe.ZAdd(ctx, s.indexKey(), &redis.Z{Score: float64(time.Now().Unix()), Member: id})
+		return nil
+	})
+
+	return err
+}
+
+// Load 加载会话数据
+func (s *Store) Load(ctx context.Context, id string) (map[string]string, error) {
+	data, err := s.opts.client.Get(ctx, s.sessionKey(id)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errors.ErrNotFoundSession
+		}
+		return nil, err
+	}
+
+	values := make(map[string]string)
+
+	if err = json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// Delete 删除会话数据
+func (s *Store) Delete(ctx context.Context, id string) error {
+	_, err := s.opts.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, s.sessionKey(id))
+		pipe.ZRem(ctx, s.indexKey(), id)
+		return nil
+	})
+
+	return err
+}
+
+// Iterate 按保存时间顺序遍历会话数据，遍历时将清理索引中已过期的会话
+func (s *Store) Iterate(ctx context.Context, fn session.StoreIterateFunc) error {
+	if s.opts.expiration > 0 {
+		max := strconv.FormatInt(time.Now().Add(-s.opts.expiration).Unix(), 10)
+
+		if err := s.opts.client.ZRemRangeByScore(ctx, s.indexKey(), "-inf", "("+max).Err(); err != nil {
+			return err
+		}
+	}
+
+	var (
+		stale []interface{}
+		start int64
+	)
+
+	defer func() {
+		if len(stale) > 0 {
+			s.opts.client.ZRem(ctx, s.indexKey(), stale...)
+		}
+	}()
+
+	for {
+		ids, err := s.opts.client.ZRange(ctx, s.indexKey(), start, start+iterateBatchSize-1).Result()
+		if err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		start += int64(len(ids))
+
+		cmds := make([]*redis.StringCmd, 0, len(ids))
+
+		if _, err = s.opts.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, id := range ids {
+				cmds = append(cmds, pipe.Get(ctx, s.sessionKey(id)))
+			}
+			return nil
+		}); err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+
+		for i, cmd := range cmds {
+			data, err := cmd.Bytes()
+			if err != nil {
+				if errors.Is(err, redis.Nil) {
+					stale = append(stale, ids[i])
+					continue
+				}
+				return err
+			}
+
+			values := make(map[string]string)
+
+			if err = json.Unmarshal(data, &values); err != nil {
+				return err
+			}
+
+			if !fn(ids[i], values) {
+				return nil
+			}
+		}
+
+		if len(ids) < iterateBatchSize {
+			return nil
+		}
+	}
+}
+
+// Shutdown 关闭会话存储，仅关闭内建客户端；共享客户端与外部客户端由调用方关闭
+func (s *Store) Shutdown(ctx context.Context) error {
+	if s.builtin {
+		return s.opts.client.Close()
+	}
+
+	return nil
+}
+
+// Client 获取客户端
+func (s *Store) Client() interface{} {
+	return s.opts.client
+}
+
+func (s *Store) sessionKey(id string) string {
+	return fmt.Sprintf(sessionKey, s.opts.prefix, id)
+}
+
+func (s *Store) indexKey() string {
+	return fmt.Sprintf(sessionIndexKey, s.opts.prefix)
+}
@@ -0,0 +1,52 @@
+package redis_test
+
+import (
+	"context"
+	"github.com/dobyte/due/session/redis/v2"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/session"
+	"testing"
+)
+
+var store = redis.NewStore(
+	redis.WithAddrs("127.0.0.1:6379"),
+	redis.WithPrefix("due:test"),
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+
+	if err := store.Save(ctx, "1", map[string]string{session.StoreKeyGID: "gate-1", "nickname": "due"}); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Delete(ctx, "1")
+
+	values, err := store.Load(ctx, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if values[session.StoreKeyGID] != "gate-1" || values["nickname"] != "due" {
+		t.Fatalf("unexpected values: %v", values)
+	}
+
+	found := false
+	if err = store.Iterate(ctx, func(id string, values map[string]string) bool {
+		found = found || id == "1"
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !found {
+		t.Fatal("session not found while iterating")
+	}
+
+	if err = store.Delete(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = store.Load(ctx, "1"); !errors.Is(err, errors.ErrNotFoundSession) {
+		t.Fatalf("expect not found session, got: %v", err)
+	}
+}
@@ -204,6 +204,14 @@ func (s *Session) Send(kind Kind, target int64, msg []byte) error {
 	return conn.Send(msg)
 }
 
+// Conn 获取会话连接
+func (s *Session) Conn(kind Kind, target int64) (network.Conn, error) {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+
+	return s.conn(kind, target)
+}
+
 // Push 推送消息（异步）
 func (s *Session) Push(kind Kind, target int64, msg []byte) error {
 	s.rw.RLock()
@@ -275,6 +283,42 @@ func (s *Session) Broadcast(kind Kind, msg []byte) (n int64, err error) {
 	return
 }
 
+// BroadcastCodecs 按连接协商的编解码器推送广播消息（异步）
+// msgs以编解码器名称为键，未协商编解码器或无对应消息的连接推送键为空的消息
+func (s *Session) BroadcastCodecs(kind Kind, msgs map[string][]byte) (n int64, err error) {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+
+	var conns map[int64]network.Conn
+	switch kind {
+	case Conn:
+		conns = s.conns
+	case User:
+		conns = s.users
+	default:
+		err = errors.ErrInvalidSessionKind
+		return
+	}
+
+	for _, conn := range conns {
+		var codec string
+		if c, ok := conn.(network.CodecConn); ok {
+			codec = c.Codec()
+		}
+
+		msg, ok := msgs[codec]
+		if !ok {
+			msg = msgs[""]
+		}
+
+		if conn.Push(msg) == nil {
+			n++
+		}
+	}
+
+	return
+}
+
 // Stat 统计会话总数
 func (s *Session) Stat(kind Kind) (int64, error) {
 	s.rw.RLock()
@@ -290,6 +334,19 @@ func (s *Session) Stat(kind Kind) (int64, error) {
 	}
 }
 
+// UIDs 获取所有已绑定的用户ID
+func (s *Session) UIDs() []int64 {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+
+	uids := make([]int64, 0, len(s.users))
+	for uid := range s.users {
+		uids = append(uids, uid)
+	}
+
+	return uids
+}
+
 // 获取会话
 func (s *Session) conn(kind Kind, target int64) (network.Conn, error) {
 	switch kind {
@@ -0,0 +1,108 @@
+package session
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/errors"
+	"sort"
+	"sync"
+)
+
+const (
+	StoreKeyUID = "uid" // 用户ID
+	StoreKeyCID = "cid" // 连接ID
+	StoreKeyGID = "gid" // 网关ID
+)
+
+// StoreIterateFunc 会话存储遍历函数，返回false时停止遍历
+type StoreIterateFunc func(id string, values map[string]string) bool
+
+// Store 会话存储，用于持久化会话的用户绑定关系及任意键值数据，使会话在网关重启后得以恢复
+type Store interface {
+	// Save 保存会话数据，覆盖已有数据
+	Save(ctx context.Context, id string, values map[string]string) error
+	// Load 加载会话数据，会话不存在时返回errors.ErrNotFoundSession
+	Load(ctx context.Context, id string) (map[string]string, error)
+	// Delete 删除会话数据
+	Delete(ctx context.Context, id string) error
+	// Iterate 遍历会话数据
+	Iterate(ctx context.Context, fn StoreIterateFunc) error
+}
+
+type memoryStore struct {
+	rw       sync.RWMutex
+	sessions map[string]map[string]string
+}
+
+// NewMemoryStore 新建内存会话存储，进程重启后数据丢失，适用于单机部署或测试
+func NewMemoryStore() Store {
+	return &memoryStore{sessions: make(map[string]map[string]string)}
+}
+
+// Save 保存会话数据，覆盖已有数据
+func (s *memoryStore) Save(ctx context.Context, id string, values map[string]string) error {
+	s.rw.Lock()
+	s.sessions[id] = clone(values)
+	s.rw.Unlock()
+
+	return nil
+}
+
+// Load 加载会话数据
+func (s *memoryStore) Load(ctx context.Context, id string) (map[string]string, error) {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+
+	values, ok := s.sessions[id]
+	if !ok {
+		return nil, errors.ErrNotFoundSession
+	}
+
+	return clone(values), nil
+}
+
+// Delete 删除会话数据
+func (s *memoryStore) Delete(ctx context.Context, id string) error {
+	s.rw.Lock()
+	delete(s.sessions, id)
+	s.rw.Unlock()
+
+	return nil
+}
+
+// Iterate 按会话ID顺序遍历会话数据
+func (s *memoryStore) Iterate(ctx context.Context, fn StoreIterateFunc) error {
+	s.rw.RLock()
+	ids := make([]string, 0, len(s.sessions))
+	for id := range s.sessions {
+		ids = append(ids, id)
+	}
+	s.rw.RUnlock()
+
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		values, err := s.Load(ctx, id)
+		if err != nil {
+			continue
+		}
+
+		if !fn(id, values) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func clone(values map[string]string) map[string]string {
+	dst := make(map[string]string, len(values))
+	for k, v := range values {
+		dst[k] = v
+	}
+
+	return dst
+}
@@ -0,0 +1,50 @@
+package session_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/session"
+	"testing"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := session.NewMemoryStore()
+
+	for _, id := range []string{"2", "1", "3"} {
+		if err := store.Save(ctx, id, map[string]string{session.StoreKeyUID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	values, err := store.Load(ctx, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values[session.StoreKeyUID] = "changed"
+
+	if values, _ = store.Load(ctx, "1"); values[session.StoreKeyUID] != "1" {
+		t.Fatal("loaded values should be a copy")
+	}
+
+	if err = store.Delete(ctx, "2"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = store.Load(ctx, "2"); !errors.Is(err, errors.ErrNotFoundSession) {
+		t.Fatalf("expect not found session, got: %v", err)
+	}
+
+	var ids []string
+	if err = store.Iterate(ctx, func(id string, values map[string]string) bool {
+		ids = append(ids, id)
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ids) != 2 || ids[0] != "1" || ids[1] != "3" {
+		t.Fatalf("unexpected iterated ids: %v", ids)
+	}
+}
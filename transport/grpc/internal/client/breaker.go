@@ -0,0 +1,34 @@
+package client
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/core/breaker"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// 熔断拦截器，按连接目标隔离熔断器，直连模式下即按目标节点熔断
+// 仅连接不可用、超时及资源耗尽视为失败，业务错误及被取消的调用不计入失败统计
+func breakerInterceptor(group *breaker.Group) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		b := group.Get(cc.Target())
+
+		if err := b.Allow(); err != nil {
+			return err
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		switch status.Code(err) {
+		case codes.Canceled:
+			b.Done(context.Canceled)
+		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+			b.Done(err)
+		default:
+			b.Done(nil)
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/core/breaker"
+	"github.com/dobyte/due/v2/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestBreakerInterceptor(t *testing.T) {
+	cc, err := grpc.NewClient("passthrough:///127.0.0.1:1", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+
+	var (
+		group       = breaker.NewGroup(breaker.WithMinRequests(2), breaker.WithOpenTimeout(time.Minute))
+		interceptor = breakerInterceptor(group)
+		calls       int
+	)
+
+	invoke := func(code codes.Code) error {
+		return interceptor(context.Background(), "/test/Call", nil, nil, cc, func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			calls++
+			return status.Error(code, code.String())
+		})
+	}
+
+	// 业务错误及被取消的调用不计入失败统计
+	for _, code := range []codes.Code{codes.NotFound, codes.InvalidArgument, codes.Canceled} {
+		_ = invoke(code)
+	}
+
+	if state := group.Get(cc.Target()).State(); state != breaker.Closed {
+		t.Fatalf("unexpected state after business errors: %s", state)
+	}
+
+	group.Reset(cc.Target())
+
+	for i := 0; i < 2; i++ {
+		_ = invoke(codes.Unavailable)
+	}
+
+	calls = 0
+
+	if err = invoke(codes.OK); !errors.Is(err, errors.ErrCircuitOpen) || calls != 0 {
+		t.Fatalf("expected circuit open without invoking, got err: %v calls: %d", err, calls)
+	}
+}
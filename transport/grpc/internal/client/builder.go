@@ -3,6 +3,7 @@ package client
 import (
 	"github.com/dobyte/due/transport/grpc/v2/internal/resolver/direct"
 	"github.com/dobyte/due/transport/grpc/v2/internal/resolver/discovery"
+	"github.com/dobyte/due/v2/core/breaker"
 	"github.com/dobyte/due/v2/registry"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -23,6 +24,7 @@ type Options struct {
 	ServerName string
 	Discovery  registry.Discovery
 	DialOpts   []grpc.DialOption
+	Breaker    *breaker.Group
 }
 
 func NewBuilder(opts *Options) *Builder {
@@ -48,6 +50,10 @@ func NewBuilder(opts *Options) *Builder {
 	b.dialOpts = append(b.dialOpts, grpc.WithTransportCredentials(creds))
 	b.dialOpts = append(b.dialOpts, grpc.WithResolvers(resolvers...))
 
+	if opts.Breaker != nil {
+		b.dialOpts = append(b.dialOpts, grpc.WithChainUnaryInterceptor(breakerInterceptor(opts.Breaker)))
+	}
+
 	return b
 }
 
@@ -13,6 +13,8 @@ import (
 	"time"
 )
 
+const protocol = "grpc" // 传输协议，混合协议集群中仅解析端点协议一致的服务实例
+
 const scheme = "direct"
 
 const defaultTimeout = 10 * time.Second
@@ -119,6 +121,10 @@ func (b *Builder) updateInstances(instances []*registry.ServiceInstance) {
 			continue
 		}
 
+		if ep.Scheme() != protocol {
+			continue
+		}
+
 		addresses[instance.ID] = ep.Address()
 	}
 
@@ -9,6 +9,8 @@ import (
 	"google.golang.org/grpc/resolver"
 )
 
+const protocol = "grpc" // 传输协议，混合协议集群中仅解析端点协议一致的服务实例
+
 type Resolver struct {
 	builder     *Builder
 	cc          resolver.ClientConn
@@ -45,6 +47,10 @@ func (r *Resolver) updateInstances(instances []*registry.ServiceInstance) {
 			continue
 		}
 
+		if ep.Scheme() != protocol {
+			continue
+		}
+
 		state.Addresses = append(state.Addresses, resolver.Address{
 			Addr:       ep.Address(),
 			ServerName: r.serviceName,
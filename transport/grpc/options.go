@@ -3,6 +3,7 @@ package grpc
 import (
 	"github.com/dobyte/due/transport/grpc/v2/internal/client"
 	"github.com/dobyte/due/transport/grpc/v2/internal/server"
+	"github.com/dobyte/due/v2/core/breaker"
 	"github.com/dobyte/due/v2/etc"
 	"github.com/dobyte/due/v2/registry"
 	"google.golang.org/grpc"
@@ -65,6 +66,11 @@ func WithClientDiscovery(discovery registry.Discovery) Option {
 	return func(o *options) { o.client.Discovery = discovery }
 }
 
+// WithClientBreaker 设置客户端熔断器组，按连接目标进行熔断，直连模式下即按目标节点熔断
+func WithClientBreaker(b *breaker.Group) Option {
+	return func(o *options) { o.client.Breaker = b }
+}
+
 // WithClientDialOptions 设置客户端拨号选项
 func WithClientDialOptions(opts ...grpc.DialOption) Option {
 	return func(o *options) { o.client.DialOpts = opts }
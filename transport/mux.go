@@ -0,0 +1,283 @@
+package transport
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/core/endpoint"
+	"github.com/dobyte/due/v2/errors"
+	"github.com/dobyte/due/v2/registry"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	muxName           = "mux"
+	discoveryScheme   = "discovery"
+	defaultMuxTimeout = 10 * time.Second
+)
+
+// Mux 多协议传输器，按服务实例注册的端点协议选择传输器，使混合协议集群对调用方透明
+// 服务发现模式下监听服务实例变化，每次调用时按服务实例轮询选择其端点协议对应的传输器客户端；直连模式及未发现服务实例时使用默认传输器
+type Mux struct {
+	rw           sync.RWMutex
+	scheme       string                      // 默认协议
+	transporters map[string]Transporter      // 协议 -> 传输器
+	discovery    registry.Discovery          // 服务发现组件
+	watching     bool                        // 是否已开始监听服务实例
+	instances    []*registry.ServiceInstance // 服务实例列表
+	version      atomic.Uint64               // 服务实例列表版本
+}
+
+var _ Transporter = &Mux{}
+
+// NewMux 新建多协议传输器，scheme为默认传输器的端点协议，默认传输器用于创建服务器及直连模式的客户端
+func NewMux(scheme string, transporter Transporter) *Mux {
+	return &Mux{
+		scheme:       scheme,
+		transporters: map[string]Transporter{scheme: transporter},
+	}
+}
+
+// Register 注册传输协议，以服务实例的端点协议为键，相同协议重复注册时覆盖
+func (m *Mux) Register(scheme string, transporter Transporter) *Mux {
+	m.rw.Lock()
+	m.transporters[scheme] = transporter
+	discovery := m.discovery
+	m.rw.Unlock()
+
+	if discovery != nil {
+		transporter.SetDefaultDiscovery(discovery)
+	}
+
+	m.version.Add(1)
+
+	return m
+}
+
+// Name 获取传输器组件名
+func (m *Mux) Name() string {
+	return muxName
+}
+
+// NewServer 新建默认传输器的服务器
+func (m *Mux) NewServer() (Server, error) {
+	return m.transporter(m.scheme).NewServer()
+}
+
+// SetDefaultDiscovery 设置默认的服务发现组件，同时设置所有已注册的传输器
+func (m *Mux) SetDefaultDiscovery(discovery registry.Discovery) {
+	m.rw.Lock()
+	if m.discovery == nil {
+		m.discovery = discovery
+	}
+	transporters := make([]Transporter, 0, len(m.transporters))
+	for _, transporter := range m.transporters {
+		transporters = append(transporters, transporter)
+	}
+	m.rw.Unlock()
+
+	for _, transporter := range transporters {
+		transporter.SetDefaultDiscovery(discovery)
+	}
+}
+
+// NewClient 新建微服务客户端，target参数模式与Transporter.NewClient一致
+func (m *Mux) NewClient(target string) (Client, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != discoveryScheme {
+		return m.transporter(m.scheme).NewClient(target)
+	}
+
+	if err = m.watch(); err != nil {
+		return nil, err
+	}
+
+	return &muxClient{mux: m, target: target, service: u.Host, clients: make(map[string]Client)}, nil
+}
+
+// 开始监听服务实例变化，仅首次调用时执行
+func (m *Mux) watch() error {
+	m.rw.Lock()
+	defer m.rw.Unlock()
+
+	if m.watching {
+		return nil
+	}
+
+	if m.discovery == nil {
+		return errors.ErrMissDiscovery
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultMuxTimeout)
+	instances, err := m.discovery.Services(ctx, cluster.Mesh.String())
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), defaultMuxTimeout)
+	watcher, err := m.discovery.Watch(ctx, cluster.Mesh.String())
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	m.watching = true
+	m.instances = instances
+	m.version.Add(1)
+
+	go func() {
+		for {
+			instances, err := watcher.Next()
+			if err != nil {
+				continue
+			}
+
+			m.rw.Lock()
+			m.instances = instances
+			m.rw.Unlock()
+
+			m.version.Add(1)
+		}
+	}()
+
+	return nil
+}
+
+// 获取提供指定服务的服务实例中已注册传输器的端点协议，每个服务实例对应一项，按服务实例ID排序
+func (m *Mux) schemes(serviceName string) []string {
+	m.rw.RLock()
+	defer m.rw.RUnlock()
+
+	instances := make([]*registry.ServiceInstance, 0, len(m.instances))
+	for _, instance := range m.instances {
+		if hasService(instance, serviceName) {
+			instances = append(instances, instance)
+		}
+	}
+
+	sort.Slice(instances, func(i, j int) bool { return instances[i].ID < instances[j].ID })
+
+	schemes := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		ep, err := endpoint.ParseEndpoint(instance.Endpoint)
+		if err != nil {
+			continue
+		}
+
+		if _, ok := m.transporters[ep.Scheme()]; ok {
+			schemes = append(schemes, ep.Scheme())
+		}
+	}
+
+	return schemes
+}
+
+// 获取指定协议的传输器
+func (m *Mux) transporter(scheme string) Transporter {
+	m.rw.RLock()
+	defer m.rw.RUnlock()
+
+	return m.transporters[scheme]
+}
+
+// 检测服务实例是否提供指定服务
+func hasService(instance *registry.ServiceInstance, serviceName string) bool {
+	for _, service := range instance.Services {
+		if service == serviceName {
+			return true
+		}
+	}
+
+	return false
+}
+
+type muxClient struct {
+	mux     *Mux
+	target  string
+	service string
+	counter atomic.Uint64
+	rw      sync.RWMutex
+	version uint64            // 已缓存的服务实例列表版本
+	schemes []string          // 提供服务的服务实例的端点协议，每个服务实例对应一项
+	clients map[string]Client // 协议 -> 客户端
+}
+
+// Call 调用服务方法，按服务实例在各协议客户端间轮询
+func (c *muxClient) Call(ctx context.Context, service, method string, args interface{}, reply interface{}, opts ...interface{}) error {
+	client, err := c.next()
+	if err != nil {
+		return err
+	}
+
+	return client.Call(ctx, service, method, args, reply, opts...)
+}
+
+// Client 获取内部客户端，返回下一个轮询到的协议客户端的内部客户端
+func (c *muxClient) Client() interface{} {
+	client, err := c.next()
+	if err != nil {
+		return nil
+	}
+
+	return client.Client()
+}
+
+// 获取下一个协议客户端，服务实例变化后重新获取各服务实例的端点协议
+func (c *muxClient) next() (Client, error) {
+	version := c.mux.version.Load()
+
+	c.rw.RLock()
+	schemes := c.schemes
+	cached := c.version == version && schemes != nil
+	c.rw.RUnlock()
+
+	if !cached {
+		schemes = c.mux.schemes(c.service)
+
+		c.rw.Lock()
+		c.version, c.schemes = version, schemes
+		c.rw.Unlock()
+	}
+
+	scheme := c.mux.scheme
+	if len(schemes) > 0 {
+		scheme = schemes[(c.counter.Add(1)-1)%uint64(len(schemes))]
+	}
+
+	return c.client(scheme)
+}
+
+// 获取协议客户端，首次使用时创建
+func (c *muxClient) client(scheme string) (Client, error) {
+	c.rw.RLock()
+	client, ok := c.clients[scheme]
+	c.rw.RUnlock()
+
+	if ok {
+		return client, nil
+	}
+
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	if client, ok = c.clients[scheme]; ok {
+		return client, nil
+	}
+
+	client, err := c.mux.transporter(scheme).NewClient(c.target)
+	if err != nil {
+		return nil, err
+	}
+
+	c.clients[scheme] = client
+
+	return client, nil
+}
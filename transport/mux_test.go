@@ -0,0 +1,141 @@
+package transport_test
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/registry"
+	"github.com/dobyte/due/v2/transport"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeTransporter struct {
+	scheme string
+}
+
+func (t *fakeTransporter) Name() string { return t.scheme }
+
+func (t *fakeTransporter) NewServer() (transport.Server, error) { return nil, nil }
+
+func (t *fakeTransporter) NewClient(target string) (transport.Client, error) {
+	return &fakeClient{scheme: t.scheme}, nil
+}
+
+func (t *fakeTransporter) SetDefaultDiscovery(discovery registry.Discovery) {}
+
+type fakeClient struct {
+	scheme string
+}
+
+func (c *fakeClient) Call(ctx context.Context, service, method string, args interface{}, reply interface{}, opts ...interface{}) error {
+	*reply.(*string) = c.scheme
+	return nil
+}
+
+func (c *fakeClient) Client() interface{} { return c.scheme }
+
+type fakeDiscovery struct {
+	instances []*registry.ServiceInstance
+	updates   chan []*registry.ServiceInstance
+}
+
+func (d *fakeDiscovery) Watch(ctx context.Context, serviceName string) (registry.Watcher, error) {
+	return &fakeWatcher{updates: d.updates}, nil
+}
+
+func (d *fakeDiscovery) Services(ctx context.Context, serviceName string) ([]*registry.ServiceInstance, error) {
+	return d.instances, nil
+}
+
+type fakeWatcher struct {
+	updates chan []*registry.ServiceInstance
+}
+
+func (w *fakeWatcher) Next() ([]*registry.ServiceInstance, error) {
+	return <-w.updates, nil
+}
+
+func (w *fakeWatcher) Stop() error { return nil }
+
+func TestMux_NewClient(t *testing.T) {
+	mux := transport.NewMux("grpc", &fakeTransporter{scheme: "grpc"}).Register("rpcx", &fakeTransporter{scheme: "rpcx"})
+
+	discovery := &fakeDiscovery{
+		instances: []*registry.ServiceInstance{
+			{ID: "1", Services: []string{"greeter"}, Endpoint: "grpc://127.0.0.1:8001"},
+			{ID: "2", Services: []string{"greeter", "wallet"}, Endpoint: "rpcx://127.0.0.1:8002"},
+			{ID: "3", Services: []string{"wallet"}, Endpoint: "rpcx://127.0.0.1:8003"},
+			{ID: "4", Services: []string{"greeter"}, Endpoint: "rpcx://127.0.0.1:8004"},
+		},
+		updates: make(chan []*registry.ServiceInstance),
+	}
+
+	mux.SetDefaultDiscovery(discovery)
+
+	call := func(client transport.Client) string {
+		var reply string
+		if err := client.Call(context.Background(), "", "", nil, &reply); err != nil {
+			t.Fatal(err)
+		}
+		return reply
+	}
+
+	// 仅存在rpcx协议的服务实例
+	client, err := mux.NewClient("discovery://wallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if scheme := call(client); scheme != "rpcx" {
+		t.Fatalf("unexpected scheme: %s", scheme)
+	}
+
+	// 存在多种协议的服务实例时按服务实例轮询调用
+	greeter, err := mux.NewClient("discovery://greeter")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if schemes := strings.Join([]string{call(greeter), call(greeter), call(greeter)}, ","); schemes != "grpc,rpcx,rpcx" {
+		t.Fatalf("unexpected schemes: %s", schemes)
+	}
+
+	// 未发现服务实例时使用默认传输器，服务实例上线后按其端点协议调用
+	shop, err := mux.NewClient("discovery://shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if scheme := call(shop); scheme != "grpc" {
+		t.Fatalf("unexpected scheme: %s", scheme)
+	}
+
+	discovery.updates <- []*registry.ServiceInstance{
+		{ID: "2", Services: []string{"greeter", "shop"}, Endpoint: "rpcx://127.0.0.1:8002"},
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for call(shop) != "rpcx" {
+		if time.Now().After(deadline) {
+			t.Fatal("instance update not applied")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 下线的协议不再被调用
+	for i := 0; i < 3; i++ {
+		if scheme := call(greeter); scheme != "rpcx" {
+			t.Fatalf("unexpected scheme: %s", scheme)
+		}
+	}
+
+	// 直连模式使用默认传输器
+	client, err = mux.NewClient("direct://127.0.0.1:8003")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if scheme := call(client); scheme != "grpc" {
+		t.Fatalf("unexpected scheme: %s", scheme)
+	}
+}
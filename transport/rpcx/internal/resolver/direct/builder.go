@@ -15,6 +15,8 @@ import (
 	"time"
 )
 
+const protocol = "rpcx" // 传输协议，混合协议集群中仅解析端点协议一致的服务实例
+
 const scheme = "direct"
 
 const defaultTimeout = 10 * time.Second
@@ -120,6 +122,10 @@ func (b *Builder) updateInstances(instances []*registry.ServiceInstance) {
 			continue
 		}
 
+		if ep.Scheme() != protocol {
+			continue
+		}
+
 		addresses[instance.ID] = ep.Address()
 	}
 
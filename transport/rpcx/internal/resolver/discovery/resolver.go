@@ -9,6 +9,8 @@ import (
 	"time"
 )
 
+const protocol = "rpcx" // 传输协议，混合协议集群中仅解析端点协议一致的服务实例
+
 type Resolver struct {
 	builder     *Builder
 	serviceName string
@@ -102,6 +104,10 @@ func (r *Resolver) updateInstances(instances []*registry.ServiceInstance) {
 			continue
 		}
 
+		if ep.Scheme() != protocol {
+			continue
+		}
+
 		pair := &cli.KVPair{Key: "tcp@" + ep.Address()}
 		if r.filter != nil && !r.filter(pair) {
 			continue
@@ -60,3 +60,13 @@ func Long2IP(v uint32) string {
 	binary.BigEndian.PutUint32(ip, v)
 	return ip.String()
 }
+
+// SplitEndpoint 拆分服务端点，返回的主机地址不含方括号
+func SplitEndpoint(endpoint string) (scheme, host string, port int, err error) {
+	return innernet.SplitEndpoint(endpoint)
+}
+
+// JoinEndpoint 组装服务端点，IPv6地址将重新使用方括号包裹
+func JoinEndpoint(scheme, host string, port int) string {
+	return innernet.JoinEndpoint(scheme, host, port)
+}